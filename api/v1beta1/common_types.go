@@ -18,6 +18,7 @@
 package v1beta1
 
 import (
+	"sort"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -41,6 +42,15 @@ type StatefulSetOptions struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy appsv1.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
+
+	// PodNamePrefix overrides the name of the StatefulSet, which the names of its pods, and therefore the Solr Node names, start with.
+	// Defaults to "<name>-solrcloud". Use a distinct prefix to avoid Node name collisions, such as when migrating collections from another cluster using the same Zookeeper.
+	// This cannot be changed once the StatefulSet has been created.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=52
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	PodNamePrefix string `json:"podNamePrefix,omitempty"`
 }
 
 // DeploymentOptions defines custom options for Deployments
@@ -79,10 +89,12 @@ type PodOptions struct {
 	EnvVariables []corev1.EnvVar `json:"envVars,omitempty"`
 
 	// Annotations to be added for pods.
+	// For SolrClouds, values can reference the variables $(SOLR_CLOUD_NAME), $(NAMESPACE), $(POD_NAME), $(POD_ORDINAL) and $(ZONE).
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	// Labels to be added for pods.
+	// For SolrClouds, values can reference the variables $(SOLR_CLOUD_NAME), $(NAMESPACE), $(POD_NAME), $(POD_ORDINAL) and $(ZONE).
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
@@ -123,6 +135,12 @@ type PodOptions struct {
 	// +optional
 	InitContainers []corev1.Container `json:"initContainers,omitempty"`
 
+	// DefaultInitContainerResources are the resource requirements for the init containers that the Solr Operator generates,
+	// such as the ones that set up the "solr.xml" and Zookeeper, or the TLS configuration.
+	// These are not applied to the initContainers provided above.
+	// +optional
+	DefaultInitContainerResources corev1.ResourceRequirements `json:"defaultInitContainerResources,omitempty"`
+
 	// ImagePullSecrets to apply to the pod.
 	// These are for init/sidecarContainers in addition to the imagePullSecret defined for the
 	// solr image.
@@ -148,6 +166,12 @@ type ServiceOptions struct {
 	// Labels to be added for the Service.
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Whether the Service publishes the addresses of pods that are not ready.
+	// Only used for the headless and node Services of a SolrCloud, where it defaults to true, so that each Solr pod is reachable no matter its readiness.
+	// Some service meshes and stub DNS setups misbehave with the endpoints of pods that are not ready.
+	// +optional
+	PublishNotReadyAddresses *bool `json:"publishNotReadyAddresses,omitempty"`
 }
 
 // IngressOptions defines custom options for ingresses
@@ -260,6 +284,29 @@ type ZookeeperConnectionInfo struct {
 	ReadOnlyACL *ZookeeperACL `json:"readOnlyAcl,omitempty"`
 }
 
+// EnsembleKey identifies the Zookeeper ensemble that the connection string points to, regardless of the order of its hosts.
+// An empty string is returned if no connection string is given.
+func (ci *ZookeeperConnectionInfo) EnsembleKey() string {
+	if ci.InternalConnectionString == "" {
+		return ""
+	}
+	// A chroot may be appended to the connection string itself
+	hosts := strings.Split(strings.ToLower(strings.SplitN(ci.InternalConnectionString, "/", 2)[0]), ",")
+	for i := range hosts {
+		hosts[i] = strings.TrimSpace(hosts[i])
+	}
+	sort.Strings(hosts)
+	return strings.Join(hosts, ",")
+}
+
+// ZookeeperChRootsOverlap determines whether the two chroots are the same, or one is nested within the other.
+// SolrClouds with overlapping chroots in the same Zookeeper ensemble would overwrite each other's cluster state.
+func ZookeeperChRootsOverlap(chRoot string, otherChRoot string) bool {
+	chRoot = strings.TrimSuffix(chRoot, "/") + "/"
+	otherChRoot = strings.TrimSuffix(otherChRoot, "/") + "/"
+	return strings.HasPrefix(chRoot, otherChRoot) || strings.HasPrefix(otherChRoot, chRoot)
+}
+
 func (ci *ZookeeperConnectionInfo) withDefaults() (changed bool) {
 	if ci.InternalConnectionString == "" {
 		if ci.ExternalConnectionString != nil {
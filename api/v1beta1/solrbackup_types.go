@@ -31,6 +31,8 @@ const (
 	DefaultAWSCliImageRepo    = "infrastructureascode/aws-cli"
 	DefaultAWSCliImageVersion = "1.16.204"
 	DefaultS3Retries          = 5
+
+	DefaultMaxSavedBackups = 5
 )
 
 // SolrBackupSpec defines the desired state of SolrBackup
@@ -43,13 +45,63 @@ type SolrBackupSpec struct {
 	// +optional
 	RepositoryName string `json:"repositoryName,omitempty"`
 
+	// The names of additional repositories to back the collections up to, alongside the repository given by repositoryName.
+	// The progress of the backups to each additional repository is reported separately in the status,
+	// and the SolrBackup is only successful if the backups to every repository succeed.
+	// Persistence, verification and the retained backups of recurring backups only apply to the repository given by repositoryName,
+	// which must be provided when additional repositories are used.
+	// +optional
+	AdditionalRepositoryNames []string `json:"additionalRepositoryNames,omitempty"`
+
 	// The list of collections to backup. If empty, all collections in the cloud will be backed up.
+	//
+	// Entries may also be glob patterns, such as "logs-*", which are matched against the collections in the cloud
+	// when the backup is started. The resolved collections are listed in the status.
+	// Solr backs up whole collections, selecting individual shards is not supported.
 	// +optional
 	Collections []string `json:"collections,omitempty"`
 
 	// Persistence is the specification on how to persist the backup data.
 	// +optional
 	Persistence *PersistenceSource `json:"persistence,omitempty"`
+
+	// Take this backup recurrently, on a schedule, and only retain the latest backups.
+	// Recurring backups cannot be persisted, since every backup is kept in the backup repository.
+	// +optional
+	Recurrence *BackupRecurrence `json:"recurrence,omitempty"`
+
+	// Verify each collection's backup once it has been taken, by inspecting it in the backup repository through the Backup API.
+	// The result is reported in the "Verified" condition, and does not change whether the backup was successful.
+	// Verification requires Solr 8.9 or later.
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+
+	// Also export the cluster state that Solr does not include in collection backups into the backup repository, alongside the index data.
+	// This is the aliases and cluster properties of the SolrCloud, and the properties of each backed-up collection.
+	// The configsets of the collections are always backed up by Solr, along with each collection.
+	// The result is reported in the "ClusterStateExported" condition, and the backup is not successful unless the export succeeds.
+	// The exported cluster state can be restored by a SolrRestore with restoreClusterState enabled.
+	// Only supported for managed backup repositories.
+	// +optional
+	IncludeClusterState bool `json:"includeClusterState,omitempty"`
+
+	// Also export the security.json of the SolrCloud with the cluster state.
+	// The security.json holds credentials, such as password hashes, so it is only exported when requested,
+	// and anyone with access to the backup repository can read it.
+	// Requires includeClusterState.
+	// +optional
+	IncludeSecurityJson bool `json:"includeSecurityJson,omitempty"`
+
+	// Take the backup as CSI VolumeSnapshots of the data PersistentVolumeClaims of the SolrCloud, instead of through a backup repository.
+	// The collections are put into read-only mode, which commits any in-flight updates, until every VolumeSnapshot has been taken.
+	// Requires the SolrCloud to use persistent storage, and the VolumeSnapshot CRDs and a CSI snapshot controller in the Kubernetes cluster.
+	// Cannot be combined with additionalRepositoryNames, persistence, recurrence, verify, includeClusterState or throttling.
+	// +optional
+	VolumeSnapshot *VolumeSnapshotBackupOptions `json:"volumeSnapshot,omitempty"`
+
+	// Options to limit the load that the backup puts on the SolrCloud and its backup repositories.
+	// +optional
+	Throttling *BackupThrottlingOptions `json:"throttling,omitempty"`
 }
 
 func (spec *SolrBackupSpec) withDefaults(backupName string) (changed bool) {
@@ -57,6 +109,85 @@ func (spec *SolrBackupSpec) withDefaults(backupName string) (changed bool) {
 		changed = spec.Persistence.withDefaults(backupName) || changed
 	}
 
+	if spec.Recurrence != nil {
+		changed = spec.Recurrence.withDefaults() || changed
+	}
+
+	if spec.VolumeSnapshot != nil {
+		changed = spec.VolumeSnapshot.withDefaults() || changed
+	}
+
+	return changed
+}
+
+// VolumeSnapshotBackupOptions defines how the VolumeSnapshots of a SolrBackup are taken
+type VolumeSnapshotBackupOptions struct {
+	// The name of the VolumeSnapshotClass to take the VolumeSnapshots with.
+	// Defaults to the default VolumeSnapshotClass of the CSI driver of the PersistentVolumeClaims.
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+
+	// How long a VolumeSnapshot may take to be taken, after it is created, before it is failed.
+	// The collections stay read-only until every VolumeSnapshot has been taken, so this limits how long they are read-only for.
+	// Defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TakeTimeoutSeconds *int32 `json:"takeTimeoutSeconds,omitempty"`
+}
+
+func (opts *VolumeSnapshotBackupOptions) withDefaults() (changed bool) {
+	if opts.TakeTimeoutSeconds == nil {
+		changed = true
+		timeout := DefaultVolumeSnapshotTakeTimeoutSeconds
+		opts.TakeTimeoutSeconds = &timeout
+	}
+	return changed
+}
+
+// BackupThrottlingOptions limits the load that a SolrBackup puts on the SolrCloud and its backup repositories
+type BackupThrottlingOptions struct {
+	// The maximum number of collection backups of this SolrBackup that are in progress at the same time, across all of its backup repositories.
+	// Solr backs up the shards of a collection in parallel, on the Solr nodes hosting them, so this limits how many cores are backed up at once.
+	// If not provided, every collection backup is started at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentCollectionBackups *int32 `json:"maxConcurrentCollectionBackups,omitempty"`
+}
+
+// BackupRecurrence defines when a recurring backup is taken, and how many of its backups are retained.
+//
+// Every backup of a recurring SolrBackup is an incremental backup point of the same Solr backup,
+// therefore recurring backups require Solr 8.9 or later.
+type BackupRecurrence struct {
+	// Take a backup on the given schedule, in CRON format.
+	// The first backup is taken right away, the schedule determines when the following backups are taken.
+	//
+	// Multiple CRON syntaxes are supported
+	//   - Standard CRON (e.g. "CRON_TZ=Asia/Seoul 0 6 * * ?")
+	//   - Predefined Schedules (e.g. "@yearly", "@weekly", etc.)
+	//   - Intervals (e.g. "@every 10h30m")
+	//
+	// For more information please check this reference:
+	// https://pkg.go.dev/github.com/robfig/cron/v3?utm_source=godoc#hdr-CRON_Expression_Format
+	//
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// The maximum number of backups to retain for each collection.
+	// When a backup finishes, the oldest backups are deleted from the backup repository until only this many remain.
+	// Defaults to 5.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSaved int `json:"maxSaved,omitempty"`
+}
+
+func (recurrence *BackupRecurrence) withDefaults() (changed bool) {
+	if recurrence.MaxSaved == 0 {
+		recurrence.MaxSaved = DefaultMaxSavedBackups
+		changed = true
+	}
+
 	return changed
 }
 
@@ -108,8 +239,15 @@ type S3PersistenceSource struct {
 	// +optional
 	Retries *int32 `json:"retries,omitempty"`
 
-	// The secrets to use when configuring and authenticating s3 calls
-	Secrets S3Secrets `json:"secrets"`
+	// The secrets to use when configuring and authenticating s3 calls.
+	// If not provided, no credentials are given to the AWS CLI, which then authenticates through the identity of the
+	// persistence Job's ServiceAccount, such as with EKS IAM Roles for Service Accounts (IRSA) or GKE Workload Identity federation.
+	// +optional
+	Secrets *S3Secrets `json:"secrets,omitempty"`
+
+	// The ServiceAccount to run the persistence Job as, for authenticating s3 calls through the identity of the pod instead of secrets.
+	// +optional
+	ServiceAccount *PersistenceServiceAccount `json:"serviceAccount,omitempty"`
 
 	// Image containing the AWS Cli
 	// +optional
@@ -157,6 +295,19 @@ type S3Secrets struct {
 	SecretAccessKey string `json:"secretAccessKey,omitempty"`
 }
 
+// PersistenceServiceAccount defines the ServiceAccount that a persistence Job runs as
+type PersistenceServiceAccount struct {
+	// The name of an existing ServiceAccount, in the namespace of the SolrBackup, to run the persistence Job as.
+	// If not provided, the Solr Operator creates a ServiceAccount for the persistence Job, with the given annotations.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Annotations to set on the ServiceAccount created by the Solr Operator, such as "eks.amazonaws.com/role-arn" for IRSA.
+	// Ignored if the name of an existing ServiceAccount is provided.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 // UploadSpec defines the location and method of uploading the backup data
 type VolumePersistenceSource struct {
 	// The volume for persistence
@@ -197,10 +348,18 @@ type SolrBackupStatus struct {
 	// Version of the Solr being backed up
 	SolrVersion string `json:"solrVersion"`
 
+	// The collections that are backed up, resolved from spec.collections when the backup is started
+	// +optional
+	Collections []string `json:"collections,omitempty"`
+
 	// The status of each collection's backup progress
 	// +optional
 	CollectionBackupStatuses []CollectionBackupStatus `json:"collectionBackupStatuses,omitempty"`
 
+	// The status of the backups to each of the additional repositories
+	// +optional
+	AdditionalRepositoryStatuses []RepositoryBackupStatus `json:"additionalRepositoryStatuses,omitempty"`
+
 	// Whether the backups are in progress of being persisted
 	PersistenceStatus BackupPersistenceStatus `json:"persistenceStatus"`
 
@@ -214,6 +373,84 @@ type SolrBackupStatus struct {
 
 	// Whether the backup has finished
 	Finished bool `json:"finished,omitempty"`
+
+	// The time that the next backup is scheduled for, for recurring backups.
+	// +optional
+	NextScheduledTime *metav1.Time `json:"nextScheduledTimestamp,omitempty"`
+
+	// The ids of the backups that are retained in the backup repository for each collection, for recurring backups.
+	// +optional
+	RetainedBackups []RetainedCollectionBackups `json:"retainedBackups,omitempty"`
+
+	// The last time that the oldest backups were pruned from the backup repository, for recurring backups.
+	// +optional
+	LastPruneTime *metav1.Time `json:"lastPruneTimestamp,omitempty"`
+
+	// The VolumeSnapshots of the data PersistentVolumeClaims of each Solr Node, for backups taken as VolumeSnapshots.
+	// +optional
+	VolumeSnapshots []VolumeSnapshotBackupStatus `json:"volumeSnapshots,omitempty"`
+
+	// The collections that the backup puts into read-only mode, until every VolumeSnapshot has been taken.
+	// The collections are listed before they are made read-only, so that they are always made writable again, even if the SolrBackup is deleted.
+	// Collections that were already in read-only mode are not listed, and are left in read-only mode.
+	// +optional
+	ReadOnlyCollections []string `json:"readOnlyCollections,omitempty"`
+
+	// Conditions describe the latest observations of the SolrBackup.
+	// The "Verified" condition is only reported when verification is enabled. It is True once every collection's backup
+	// has been found complete in the backup repository, and False, with the reason and message, otherwise.
+	// The "ClusterStateExported" condition is only reported when includeClusterState is enabled, and lists the exported znodes.
+	// The "CollectionsReadOnly" condition is only reported for VolumeSnapshot backups, and is True once the readOnlyCollections have been made read-only.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// SolrBackupConfigurationValid is the condition type that reports whether the options of the SolrBackup can be used to take backups.
+	// Misconfigurations are reported with the reason of the error, and are not retried until the SolrBackup is changed.
+	SolrBackupConfigurationValid = "ConfigurationValid"
+
+	// SolrBackupVerified is the condition type that reports whether the backups of all collections of a SolrBackup were verified
+	SolrBackupVerified = "Verified"
+
+	// SolrBackupClusterStateExported is the condition type that reports whether the cluster state of the SolrCloud was exported alongside the collection backups
+	SolrBackupClusterStateExported = "ClusterStateExported"
+
+	// SolrBackupCollectionsReadOnly is the condition type that reports whether the readOnlyCollections of a VolumeSnapshot backup have been made read-only
+	SolrBackupCollectionsReadOnly = "CollectionsReadOnly"
+
+	// DefaultVolumeSnapshotTakeTimeoutSeconds is how long a VolumeSnapshot may take to be taken, by default
+	DefaultVolumeSnapshotTakeTimeoutSeconds = int32(600)
+)
+
+// RepositoryBackupStatus defines the progress of a SolrBackup's backups to an additional repository
+type RepositoryBackupStatus struct {
+	// The name of the backup repository
+	Repository string `json:"repository"`
+
+	// The status of each collection's backup progress to this repository
+	// +optional
+	CollectionBackupStatuses []CollectionBackupStatus `json:"collectionBackupStatuses,omitempty"`
+
+	// Whether the backups to this repository have finished
+	Finished bool `json:"finished,omitempty"`
+
+	// Whether every collection was successfully backed up to this repository
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+}
+
+// RetainedCollectionBackups lists the backups of a Solr Collection that are retained in the backup repository
+type RetainedCollectionBackups struct {
+	// Solr Collection name
+	Collection string `json:"collection"`
+
+	// The ids of the retained backups, oldest first.
+	// These can be restored by a SolrRestore, through its backupId.
+	// +optional
+	BackupIds []int32 `json:"backupIds,omitempty"`
 }
 
 // CollectionBackupStatus defines the progress of a Solr Collection's backup
@@ -245,6 +482,34 @@ type CollectionBackupStatus struct {
 	Successful *bool `json:"successful,omitempty"`
 }
 
+// VolumeSnapshotBackupStatus defines the progress of the VolumeSnapshot of a Solr Node's data PersistentVolumeClaim
+type VolumeSnapshotBackupStatus struct {
+	// The name of the Solr pod whose data is snapshotted
+	PodName string `json:"podName"`
+
+	// The name of the data PersistentVolumeClaim of the Solr pod
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// The name of the VolumeSnapshot
+	Name string `json:"name"`
+
+	// The handle of the snapshot in the storage system, once it has been taken
+	// +optional
+	SnapshotHandle string `json:"snapshotHandle,omitempty"`
+
+	// The time that the snapshot was taken, according to the storage system
+	// +optional
+	CreationTime *metav1.Time `json:"creationTimestamp,omitempty"`
+
+	// Whether the snapshot is ready to be used to restore the PersistentVolumeClaim
+	// +optional
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+
+	// The error that occurred while taking the snapshot, if any
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
 // BackupPersistenceStatus defines the status of persisting Solr backup data
 type BackupPersistenceStatus struct {
 	// Whether the collection is being backed up
@@ -289,6 +554,22 @@ func (sb *SolrBackup) PersistenceJobName() string {
 	return fmt.Sprintf("%s-solr-backup-persistence", sb.GetName())
 }
 
+// PersistenceServiceAccountName returns the name of the ServiceAccount that the persistence Job runs as, if one is configured
+func (sb *SolrBackup) PersistenceServiceAccountName() string {
+	if sb.Spec.Persistence == nil || sb.Spec.Persistence.S3 == nil || sb.Spec.Persistence.S3.ServiceAccount == nil {
+		return ""
+	}
+	if sb.Spec.Persistence.S3.ServiceAccount.Name != "" {
+		return sb.Spec.Persistence.S3.ServiceAccount.Name
+	}
+	return sb.PersistenceJobName()
+}
+
+// VolumeSnapshotName returns the name of the VolumeSnapshot of the data of the given Solr pod
+func (sb *SolrBackup) VolumeSnapshotName(podName string) string {
+	return fmt.Sprintf("%s-%s", sb.GetName(), podName)
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Namespaced
 //+kubebuilder:storageversion
@@ -297,6 +578,8 @@ func (sb *SolrBackup) PersistenceJobName() string {
 //+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
 //+kubebuilder:printcolumn:name="Finished",type="boolean",JSONPath=".status.finished",description="Whether the backup has finished"
 //+kubebuilder:printcolumn:name="Successful",type="boolean",JSONPath=".status.successful",description="Whether the backup was successful"
+//+kubebuilder:printcolumn:name="Verified",type="string",JSONPath=".status.conditions[?(@.type==\"Verified\")].status",description="Whether the backup was verified",priority=1
+//+kubebuilder:printcolumn:name="NextBackup",type="string",JSONPath=".status.nextScheduledTimestamp",description="Next scheduled time for a recurring backup",format="date-time"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SolrBackup is the Schema for the solrbackups API
@@ -18,13 +18,17 @@
 package v1beta1
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -50,6 +54,19 @@ const (
 	DefaultZkVersion                                 = ""
 	DefaultZkVolumeReclaimPolicy VolumeReclaimPolicy = "Retain"
 
+	DefaultZkConnectionTimeoutSeconds = int32(300)
+
+	DefaultZoneTopologyKey = "topology.kubernetes.io/zone"
+
+	DefaultInventoryRefreshIntervalSeconds = int32(60)
+
+	DefaultCollectionMetricsRefreshIntervalSeconds = int32(60)
+	DefaultNodeMetricsRefreshIntervalSeconds       = int32(30)
+
+	DefaultStartupProbePeriodSeconds    = int32(10)
+	DefaultStartupProbeTimeoutSeconds   = int32(30)
+	DefaultStartupProbeFailureThreshold = int32(60)
+
 	SolrTechnologyLabel      = "solr-cloud"
 	ZookeeperTechnologyLabel = "zookeeper"
 
@@ -58,6 +75,22 @@ const (
 	LegacyBackupRepositoryName = "legacy_local_repository"
 )
 
+// DefaultNodeInterruptionTaintKeys are the Node taints that signal an upcoming interruption of a Node
+var DefaultNodeInterruptionTaintKeys = []string{
+	"node.kubernetes.io/unschedulable",
+	"ToBeDeletedByClusterAutoscaler",
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/scheduled-maintenance",
+	"cloud.google.com/impending-node-termination",
+}
+
+// DefaultSpotNodeTolerations tolerate the taints that GKE and AKS put on their spot and preemptible Nodes
+var DefaultSpotNodeTolerations = []corev1.Toleration{
+	{Key: "cloud.google.com/gke-spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "cloud.google.com/gke-preemptible", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "kubernetes.azure.com/scalesetpriority", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
 // SolrCloudSpec defines the desired state of SolrCloud
 type SolrCloudSpec struct {
 	// The number of solr nodes to run
@@ -90,9 +123,105 @@ type SolrCloudSpec struct {
 	// +optional
 	UpdateStrategy SolrUpdateStrategy `json:"updateStrategy,omitempty"`
 
+	// Define how the Solr Operator assists with scaling the SolrCloud.
+	// +optional
+	Scaling SolrScalingOptions `json:"scaling,omitempty"`
+
+	// Put every collection in the SolrCloud into read-only mode, such as for maintenance or for disaster-recovery replicas.
+	// The operator sets the "readOnly" property on all collections, including those created later, via the Collections API.
+	// When this is switched off again, the operator returns the collections to read-write mode.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Run the SolrCloud as a warm standby of a primary SolrCloud, possibly in another Kubernetes cluster,
+	// by restoring the latest backup of the primary's collections on a schedule. The collections of a standby are read-only.
+	// Remove this to promote the standby: the collections are returned to read-write mode and are no longer replaced.
+	// +optional
+	StandbyOf *SolrStandbyOptions `json:"standbyOf,omitempty"`
+
+	// ConfigSetFiles syncs files, such as synonyms and stopwords, from ConfigMaps into configsets in Zookeeper.
+	// When the files change, the operator uploads them and reloads the collections that use the configset.
+	// +optional
+	// +listType=map
+	// +listMapKey=configSet
+	ConfigSetFiles []ConfigSetFiles `json:"configSetFiles,omitempty"`
+
+	// Options for how the Solr Operator reads the cluster state of the SolrCloud from Solr,
+	// which is used to make decisions such as which pods are safe to update.
+	// +optional
+	ClusterStateOptions *SolrClusterStateOptions `json:"clusterStateOptions,omitempty"`
+
+	// Export a machine-readable inventory of the SolrCloud's collections, shards and replicas, and the pods and PVCs that host them,
+	// to a ConfigMap. This can be consumed by capacity-planning and chargeback tooling without calling Solr directly.
+	// +optional
+	Inventory *SolrInventoryOptions `json:"inventory,omitempty"`
+
+	// Export Prometheus metrics for each collection in the SolrCloud, such as its document count, shard count and health,
+	// on the Solr Operator's metrics endpoint. This allows per-collection dashboards without running a Prometheus exporter for the SolrCloud.
+	// +optional
+	CollectionMetrics *SolrCollectionMetricsOptions `json:"collectionMetrics,omitempty"`
+
+	// Relay a small set of metrics from each Solr pod, such as heap usage, request counts and loaded cores, to the Solr Operator's metrics endpoint.
+	// This is a lightweight alternative to a SolrPrometheusExporter, meant for development and other small SolrClouds.
+	// +optional
+	NodeMetrics *SolrNodeMetricsOptions `json:"nodeMetrics,omitempty"`
+
+	// Move shard leaders, and optionally replicas, off of Solr pods whose Kubernetes Nodes are about to be interrupted, such as spot or preemptible
+	// Nodes that have received a termination notice, or Nodes that are being drained.
+	// +optional
+	NodeInterruption *SolrNodeInterruptionOptions `json:"nodeInterruption,omitempty"`
+
+	// Schedule the Solr pods onto spot or preemptible Nodes, to reduce the cost of the SolrCloud.
+	// Combine this with nodeInterruption, so that leaders and replicas are moved off of the Nodes when they receive a termination notice.
+	// +optional
+	SpotNodes *SolrSpotNodeOptions `json:"spotNodes,omitempty"`
+
+	// Generate the affinity and topology spread constraints that keep Solr pods apart, so that losing a single Node or zone
+	// does not take down multiple Solr pods. An affinity given in customSolrKubeOptions.podOptions takes precedence over the generated one.
+	// Also the number of Solr nodes that must be ready before the SolrCloud is considered formed after a cold start.
+	// +optional
+	Availability *SolrAvailabilityOptions `json:"availability,omitempty"`
+
+	// Tune the probes that the Solr Operator generates for the Solr container.
+	// Probe options given in customSolrKubeOptions.podOptions take precedence over these.
+	// +optional
+	Probes SolrProbesOptions `json:"probes,omitempty"`
+
+	// The number of seconds that a Solr pod keeps serving requests after it starts terminating, before Solr is stopped.
+	// Kubernetes removes a terminating pod from the endpoints of the common service right away, but ingress controllers and
+	// kube-proxy take some time to notice, so requests are still routed to the pod during this lameduck period.
+	// The terminationGracePeriodSeconds of the Solr pods is extended by this delay, unless one is provided in the podOptions.
+	// Not used when a custom lifecycle is provided in the podOptions.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LameduckSeconds int32 `json:"lameduckSeconds,omitempty"`
+
+	// Choose which of the child resources of the SolrCloud the Solr Operator manages, so that others can be owned by a different tool.
+	// Resources that are not managed are neither created nor updated by the Solr Operator.
+	// By default, all child resources are managed.
+	// +optional
+	ManagedResources *SolrManagedResourcesOptions `json:"managedResources,omitempty"`
+
+	// The security profile of the Solr pods that the Solr Operator generates.
+	// Use "Restricted" for namespaces that enforce the restricted Pod Security Standard.
+	// Defaults to "Default".
+	// +optional
+	PodSecurityProfile SolrPodSecurityProfile `json:"podSecurityProfile,omitempty"`
+
+	// Run the containers that the Solr Operator generates with a read-only root filesystem.
+	// The paths that Solr writes to outside of its data directory, such as /tmp and the log directories, are mounted as emptyDir volumes.
+	// +optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+
 	// +optional
 	BusyBoxImage *ContainerImage `json:"busyBoxImage,omitempty"`
 
+	// ZkSetupImage is the image of the init container that sets up Zookeeper for Solr, e.g. to upload the security.json.
+	// This allows using a smaller image than the Solr image, which must still provide the "solr" script and zkcli.sh from the official Solr image.
+	// Fields that are not provided default to those of the solrImage, which is used if this is not provided.
+	// +optional
+	ZkSetupImage *ContainerImage `json:"zkSetupImage,omitempty"`
+
 	// +optional
 	SolrJavaMem string `json:"solrJavaMem,omitempty"`
 
@@ -109,6 +238,19 @@ type SolrCloudSpec struct {
 	// +optional
 	SolrGCTune string `json:"solrGCTune,omitempty"`
 
+	// Configure how Solr accesses its index files.
+	// Memory-mapped index files are held in the page cache, which is charged to the memory limit of the Solr container,
+	// so these options are commonly tuned for SolrClouds that run with memory limits.
+	// Changing them will cause a rolling restart.
+	// +optional
+	IndexDirectory *SolrIndexDirectoryOptions `json:"indexDirectory,omitempty"`
+
+	// Configure the Java Security Manager of Solr, and the paths and URLs that Solr is allowed to access.
+	// These cannot also be set through solrOpts or the envVars of the podOptions.
+	// Changing them will cause a rolling restart.
+	// +optional
+	RuntimeRestrictions *SolrRuntimeRestrictionOptions `json:"runtimeRestrictions,omitempty"`
+
 	// Options to enable the server TLS certificate for Solr pods
 	// +optional
 	SolrTLS *SolrTLSOptions `json:"solrTLS,omitempty"`
@@ -121,10 +263,19 @@ type SolrCloudSpec struct {
 	// +optional
 	SolrSecurity *SolrSecurityOptions `json:"solrSecurity,omitempty"`
 
+	// Options for the requests that the Solr Operator sends to this SolrCloud, such as for managed updates and backups.
+	// +optional
+	OperatorClient *SolrOperatorClientOptions `json:"operatorClient,omitempty"`
+
+	// Options for a Secret containing the information client applications need to connect to this SolrCloud.
+	// The Secret is only created if this option is provided.
+	// +optional
+	ConnectionInfo *SolrConnectionInfoOptions `json:"connectionInfo,omitempty"`
+
 	// Allows specification of multiple different "repositories" for Solr to use when backing up data.
 	//+optional
-	//+listType:=map
-	//+listMapKey:=name
+	//+listType=map
+	//+listMapKey=name
 	BackupRepositories []SolrBackupRepository `json:"backupRepositories,omitempty"`
 }
 
@@ -171,6 +322,58 @@ func (spec *SolrCloudSpec) withDefaults() (changed bool) {
 
 	changed = spec.StorageOptions.withDefaults() || changed
 
+	if spec.Inventory != nil {
+		changed = spec.Inventory.withDefaults() || changed
+	}
+
+	if spec.CollectionMetrics != nil {
+		changed = spec.CollectionMetrics.withDefaults() || changed
+	}
+
+	if spec.NodeMetrics != nil {
+		changed = spec.NodeMetrics.withDefaults() || changed
+	}
+
+	if spec.NodeInterruption != nil {
+		changed = spec.NodeInterruption.withDefaults() || changed
+	}
+
+	if spec.SpotNodes != nil {
+		changed = spec.SpotNodes.withDefaults() || changed
+	}
+
+	if spec.ManagedResources != nil {
+		changed = spec.ManagedResources.withDefaults() || changed
+	}
+
+	if spec.Availability != nil {
+		changed = spec.Availability.withDefaults() || changed
+	}
+
+	changed = spec.Probes.withDefaults() || changed
+
+	for i := range spec.ConfigSetFiles {
+		if spec.ConfigSetFiles[i].DriftPolicy == "" {
+			spec.ConfigSetFiles[i].DriftPolicy = ReportConfigSetDrift
+			changed = true
+		}
+		for j := range spec.ConfigSetFiles[i].Files {
+			if spec.ConfigSetFiles[i].Files[j].Path == "" {
+				spec.ConfigSetFiles[i].Files[j].Path = spec.ConfigSetFiles[i].Files[j].Key
+				changed = true
+			}
+		}
+	}
+
+	for i := range spec.CustomSolrKubeOptions.ConfigMapFiles {
+		changed = spec.CustomSolrKubeOptions.ConfigMapFiles[i].withDefaults() || changed
+	}
+
+	if spec.PodSecurityProfile == "" {
+		changed = true
+		spec.PodSecurityProfile = DefaultPodSecurityProfile
+	}
+
 	if spec.BusyBoxImage == nil {
 		c := ContainerImage{}
 		spec.BusyBoxImage = &c
@@ -198,6 +401,10 @@ type CustomSolrKubeOptions struct {
 	// +optional
 	PodOptions *PodOptions `json:"podOptions,omitempty"`
 
+	// SolrContainerOptions defines the custom options for the Solr container in solrCloud pods.
+	// +optional
+	SolrContainerOptions *SolrContainerOptions `json:"solrContainerOptions,omitempty"`
+
 	// StatefulSetOptions defines the custom options for the solrCloud StatefulSet.
 	// +optional
 	StatefulSetOptions *StatefulSetOptions `json:"statefulSetOptions,omitempty"`
@@ -219,9 +426,235 @@ type CustomSolrKubeOptions struct {
 	// +optional
 	ConfigMapOptions *ConfigMapOptions `json:"configMapOptions,omitempty"`
 
+	// ConfigMapFiles are additional files, sourced from user provided ConfigMaps, to mount into the Solr container.
+	// Use these for files other than solr.xml and log4j2.xml, such as jetty xml includes, a customized web.xml or synonyms.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ConfigMapFiles []ConfigMapFile `json:"configMapFiles,omitempty"`
+
 	// IngressOptions defines the custom options for the solrCloud Ingress.
 	// +optional
 	IngressOptions *IngressOptions `json:"ingressOptions,omitempty"`
+
+	// PropagateLabels limits the labels of the SolrCloud that are copied to the resources created for it,
+	// such as the StatefulSet, pods, services and ingress, to those matching the policy.
+	// If not provided, every label of the SolrCloud is propagated, except to the data PVCs.
+	// +optional
+	PropagateLabels *MetadataPropagationPolicy `json:"propagateLabels,omitempty"`
+
+	// PropagateAnnotations selects the annotations of the SolrCloud that are copied to the StatefulSet, pods, services and ingress created for it.
+	// If not provided, no annotations of the SolrCloud are propagated.
+	// +optional
+	PropagateAnnotations *MetadataPropagationPolicy `json:"propagateAnnotations,omitempty"`
+}
+
+// MetadataPropagationPolicy selects the labels or annotations of a resource that are propagated to the resources created for it
+type MetadataPropagationPolicy struct {
+	// The prefixes of the keys that are propagated, such as "example.com/" or "cost-center".
+	// A key is propagated if it starts with any of the prefixes, so no keys are propagated if no prefixes are given.
+	// +optional
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// Also propagate the selected keys to the data PersistentVolumeClaims of the SolrCloud.
+	// The Solr Operator adds them to the existing PVCs as well, since the volumeClaimTemplates of a StatefulSet cannot be changed.
+	// Keys are never removed from existing PVCs.
+	// +optional
+	PersistentVolumeClaims bool `json:"persistentVolumeClaims,omitempty"`
+}
+
+// Propagates returns whether the given label or annotation key matches one of the prefixes of the policy
+func (policy *MetadataPropagationPolicy) Propagates(key string) bool {
+	for _, prefix := range policy.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SolrStandbyOptions defines how a standby SolrCloud follows the backups of its primary SolrCloud
+type SolrStandbyOptions struct {
+	// The name of the primary SolrCloud. It may run in another namespace or Kubernetes cluster, and is only used to describe the standby.
+	// +kubebuilder:validation:MinLength=1
+	SolrCloud string `json:"solrCloud"`
+
+	// The name of the backup repository, of this SolrCloud, that the primary's backups are read from.
+	// It must store its data in the same place as the repository that the primary backs up to.
+	// Defaults to the only repository of the SolrCloud, if it has one.
+	// +optional
+	RepositoryName string `json:"repositoryName,omitempty"`
+
+	// The name of the backup, within the backup repository, to restore. For backups taken by a SolrBackup, this is the name of the SolrBackup.
+	// The latest backup point is restored each time.
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName"`
+
+	// The collections of the primary to restore.
+	// +kubebuilder:validation:MinItems=1
+	Collections []string `json:"collections"`
+
+	// Restore the latest backup on the given schedule, in CRON format. The first restore is started right away.
+	// The same CRON syntaxes as the recurrence of SolrBackups are supported.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+}
+
+// ConfigSetFiles are files, from a user provided ConfigMap, that are synced into a configset in Zookeeper
+type ConfigSetFiles struct {
+	// The name of the configset in Zookeeper. The configset must already exist.
+	// +kubebuilder:validation:MinLength:=1
+	ConfigSet string `json:"configSet"`
+
+	// Name of a user provided ConfigMap, in the same namespace as the SolrCloud, that contains the files.
+	// +kubebuilder:validation:MinLength:=1
+	ConfigMap string `json:"configMap"`
+
+	// The files to sync from the ConfigMap into the configset.
+	// +kubebuilder:validation:MinItems:=1
+	Files []ConfigSetFile `json:"files"`
+
+	// What to do when the configset drifts from the ConfigMap, because the synced files were changed in Zookeeper,
+	// or the configset was changed through the Config API.
+	// "Report" lists the drifted files in the status and records an event, "Repair" also overwrites them and reloads the collections,
+	// "Ignore" does not check for drift.
+	// Defaults to "Report".
+	// +optional
+	DriftPolicy ConfigSetDriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// ConfigSetDriftPolicy is the action to take when a configset has drifted from its source
+// +kubebuilder:validation:Enum=Ignore;Report;Repair
+type ConfigSetDriftPolicy string
+
+const (
+	IgnoreConfigSetDrift ConfigSetDriftPolicy = "Ignore"
+	ReportConfigSetDrift ConfigSetDriftPolicy = "Report"
+	RepairConfigSetDrift ConfigSetDriftPolicy = "Repair"
+)
+
+// ConfigSetFile maps a key of a ConfigMap to a file in a configset
+type ConfigSetFile struct {
+	// Key of the ConfigMap whose value is the content of the file.
+	// +kubebuilder:validation:MinLength:=1
+	Key string `json:"key"`
+
+	// Path of the file within the configset, such as "lang/stopwords_en.txt".
+	// Defaults to the key.
+	// +kubebuilder:validation:Pattern:=`^[^/].*$`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ConfigMapFile is a file, sourced from a key of a user provided ConfigMap, that is mounted into the Solr container
+type ConfigMapFile struct {
+	// Name of the file, which is used to name its volume and the pod annotation that tracks its content.
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength:=40
+	Name string `json:"name"`
+
+	// Name of a user provided ConfigMap, in the same namespace as the SolrCloud, that contains the file.
+	// +kubebuilder:validation:MinLength:=1
+	ConfigMap string `json:"configMap"`
+
+	// Key of the ConfigMap whose value is the content of the file.
+	// +kubebuilder:validation:MinLength:=1
+	Key string `json:"key"`
+
+	// Absolute path that the file is mounted to in the Solr container.
+	// +kubebuilder:validation:Pattern:=`^/.+`
+	MountPath string `json:"mountPath"`
+
+	// Restart the Solr pods when the content of the file changes.
+	// The file is mounted through a subPath, so Kubernetes does not update it in running pods.
+	// If false, changes to the file only take effect when the Solr pods are restarted for another reason.
+	// Defaults to true.
+	// +optional
+	RestartOnUpdate *bool `json:"restartOnUpdate,omitempty"`
+}
+
+func (file *ConfigMapFile) withDefaults() (changed bool) {
+	if file.RestartOnUpdate == nil {
+		changed = true
+		restartOnUpdate := true
+		file.RestartOnUpdate = &restartOnUpdate
+	}
+	return changed
+}
+
+// SolrContainerOptions defines custom options for the Solr container
+type SolrContainerOptions struct {
+	// Override the entrypoint of the Solr container, e.g. to wrap "solr-foreground" in a custom script for profiling.
+	// The Solr Operator still provides its environment variables and lifecycle hooks to the container,
+	// so the command must start Solr using these, instead of overriding the Solr port, home or Zookeeper connection.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Override the arguments of the Solr container.
+	// The same restrictions apply as for the command.
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
+// SolrRuntimeRestrictionOptions defines the restrictions on what the Solr process may access
+type SolrRuntimeRestrictionOptions struct {
+	// Enable or disable the Java Security Manager, through the SOLR_SECURITY_MANAGER_ENABLED environment variable.
+	// If not provided, the default of the Solr version is used.
+	// +optional
+	SecurityManager *bool `json:"securityManager,omitempty"`
+
+	// Paths outside of the Solr home and data directories that Solr is allowed to access, such as for backups or core instanceDirs.
+	// Passed to Solr as the "solr.allowPaths" system property. Use "*" to allow all paths.
+	// +optional
+	AllowPaths []string `json:"allowPaths,omitempty"`
+
+	// The URLs that Solr is allowed to send requests to, for the shards and masterUrl parameters.
+	// Passed to Solr as the "solr.allowUrls" system property.
+	// +optional
+	AllowUrls []string `json:"allowUrls,omitempty"`
+
+	// Allow Solr to send requests to any URL, by setting the "solr.disable.allowUrls" system property.
+	// This cannot be used with allowUrls.
+	// +optional
+	DisableAllowUrls bool `json:"disableAllowUrls,omitempty"`
+}
+
+// SolrDirectoryFactory is the DirectoryFactory that Solr uses to access its index files
+// +kubebuilder:validation:Enum=MMap;NIOFS;NRTCaching
+type SolrDirectoryFactory string
+
+const (
+	// Memory-map the index files
+	MMapDirectoryFactory SolrDirectoryFactory = "MMap"
+
+	// Read the index files through the file system, without memory-mapping them
+	NIOFSDirectoryFactory SolrDirectoryFactory = "NIOFS"
+
+	// Memory-map the index files, and cache small newly flushed segments in the heap. This is the default of Solr.
+	NRTCachingDirectoryFactory SolrDirectoryFactory = "NRTCaching"
+)
+
+// SolrIndexDirectoryOptions defines how Solr accesses its index files
+type SolrIndexDirectoryOptions struct {
+	// The DirectoryFactory that Solr uses for its index files, passed to Solr as the "solr.directoryFactory" system property.
+	// This is only used by configsets that reference ${solr.directoryFactory}, as the default configsets do.
+	// If not provided, the DirectoryFactory of the configset is used.
+	// +optional
+	DirectoryFactory SolrDirectoryFactory `json:"directoryFactory,omitempty"`
+
+	// Load the memory-mapped index files into the page cache when they are opened, instead of on first access.
+	// This is passed to Solr as the "solr.mmap.preload" system property, which must be referenced by the directoryFactory of the configset,
+	// e.g. <bool name="preload">${solr.mmap.preload:false}</bool>.
+	// Requires the MMap directoryFactory.
+	// +optional
+	Preload bool `json:"preload,omitempty"`
+
+	// The minimum value of the vm.max_map_count kernel setting that the nodes running Solr pods must have.
+	// Memory-mapping large indexes requires many memory maps, and the limit cannot be raised from within a container.
+	// If provided, an init container checks this setting before Solr is started, and the pod fails to start if it is too low.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinMaxMapCount *int64 `json:"minMaxMapCount,omitempty"`
 }
 
 type SolrDataStorageOptions struct {
@@ -390,14 +823,44 @@ type GcsRepository struct {
 	// The name of the GCS bucket that all backup data will be stored in
 	Bucket string `json:"bucket"`
 
-	// The name & key of a Kubernetes secret holding a Google cloud service account key
-	GcsCredentialSecret corev1.SecretKeySelector `json:"gcsCredentialSecret"`
+	// The name & key of a Kubernetes secret holding a Google cloud service account key.
+	// Required unless workloadIdentity is provided.
+	// +optional
+	GcsCredentialSecret corev1.SecretKeySelector `json:"gcsCredentialSecret,omitempty"`
+
+	// Authenticate to GCS through Workload Identity Federation, using a projected Kubernetes service account token,
+	// instead of a long-lived service account key.
+	// If provided, gcsCredentialSecret is ignored.
+	// +optional
+	WorkloadIdentity *GcsWorkloadIdentity `json:"workloadIdentity,omitempty"`
 
 	// An already-created chroot within the bucket to store data in. Defaults to the root path "/" if not specified.
 	// +optional
 	BaseLocation string `json:"baseLocation,omitempty"`
 }
 
+// UsesWorkloadIdentity returns whether the GCS repository authenticates using a projected service account token.
+func (gcsRepo *GcsRepository) UsesWorkloadIdentity() bool {
+	return gcsRepo != nil && gcsRepo.WorkloadIdentity != nil
+}
+
+type GcsWorkloadIdentity struct {
+	// The audience of the projected service account token.
+	// This must match the audience of the Workload Identity Pool Provider that trusts the Kubernetes cluster.
+	Audience string `json:"audience"`
+
+	// The requested duration of validity of the projected service account token, which is refreshed by the kubelet.
+	// Defaults to 3600 (1 hour) if not provided.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+
+	// The name & key of a Kubernetes ConfigMap holding the Google "external_account" credential configuration.
+	// The credential configuration must read the subject token from the file:
+	// "/var/solr/data/backup-restore/<repository-name>/workload-identity/token"
+	CredentialConfig corev1.ConfigMapKeySelector `json:"credentialConfig"`
+}
+
 type ManagedRepository struct {
 	// This is a volumeSource for a volume that will be mounted to all solrNodes to store backups and load restores.
 	// The data within the volume will be namespaced for this instance, so feel free to use the same volume for multiple clouds.
@@ -420,11 +883,15 @@ type SolrAddressabilityOptions struct {
 
 	// PodPort defines the port to have the Solr Pod listen on.
 	// Defaults to 8983
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	PodPort int `json:"podPort,omitempty"`
 
 	// CommonServicePort defines the port to have the common Solr service listen on.
 	// Defaults to 80 (when not using TLS) or 443 (when using TLS)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	CommonServicePort int `json:"commonServicePort,omitempty"`
 
@@ -432,11 +899,44 @@ type SolrAddressabilityOptions struct {
 	// Only use this option if the Kubernetes cluster has been setup with a custom domain.
 	// +optional
 	KubeDomain string `json:"kubeDomain,omitempty"`
+
+	// HostNetwork runs the Solr pods in the network namespace of the Kubernetes nodes they are scheduled on.
+	// Each Solr node listens on, and advertises, the podPort of the Kubernetes node it is running on, instead of a Kubernetes service address.
+	// This is useful for environments that balance traffic at the node level (L4), such as bare-metal clusters.
+	//
+	// Since the podPort is also requested as a hostPort, Kubernetes will not schedule two Solr pods using the same port on the same node.
+	// The Solr pods of the SolrCloud are also given a required pod anti-affinity, so that each runs on a different node, even with a custom affinity.
+	// The SolrCloud therefore needs at least as many schedulable nodes as replicas.
+	// When enabled, the external address cannot be advertised, so useExternalAddress will be set to false.
+	// +optional
+	HostNetwork *SolrHostNetworkOptions `json:"hostNetwork,omitempty"`
+
+	// AdvertisedHostTemplate is a Go template used to override the host that each Solr Node advertises itself with (SOLR_HOST).
+	// Use this when the default hostnames, of the headless service or the external address, cannot be resolved by every client of the SolrCloud.
+	// The template is given the fields: PodName, PodIP, Namespace, CloudName and HeadlessServiceName.
+	// e.g. "{{.PodName}}.solr.nat.example.com" to advertise an external NAT name.
+	//
+	// Solr stores the advertised host in the names of the nodes that host each replica, so it must not change when a pod is recreated.
+	// The PodIP changes whenever a pod is recreated, after which Solr no longer finds the replicas of the pod, so it is not recommended.
+	// IPv6 pod IPs must be wrapped in brackets, e.g. "[{{.PodIP}}]".
+	//
+	// The PodName and PodIP must be used unmodified, since they are substituted in the pod at runtime.
+	// This option cannot be used with hostNetwork.
+	// +optional
+	AdvertisedHostTemplate string `json:"advertisedHostTemplate,omitempty"`
 }
 
 func (opts *SolrAddressabilityOptions) withDefaults(usesTLS bool) (changed bool) {
 	if opts.External != nil {
 		changed = opts.External.withDefaults(usesTLS)
+		// Solr nodes advertise the address of their Kubernetes node when using the host network
+		if opts.HostNetwork != nil && opts.External.UseExternalAddress {
+			changed = true
+			opts.External.UseExternalAddress = false
+		}
+	}
+	if opts.HostNetwork != nil {
+		changed = opts.HostNetwork.withDefaults() || changed
 	}
 	if opts.PodPort == 0 {
 		changed = true
@@ -453,6 +953,34 @@ func (opts *SolrAddressabilityOptions) withDefaults(usesTLS bool) (changed bool)
 	return changed
 }
 
+// HostNetworkAddressType is a string enumeration type that enumerates
+// the addresses of a Kubernetes node that a Solr node can advertise when running in the host network.
+// +kubebuilder:validation:Enum=IP;NodeName
+type HostNetworkAddressType string
+
+const (
+	// Advertise the IP address of the Kubernetes node
+	HostNetworkNodeIP HostNetworkAddressType = "IP"
+
+	// Advertise the name of the Kubernetes node, this must be resolvable via DNS
+	HostNetworkNodeName HostNetworkAddressType = "NodeName"
+)
+
+type SolrHostNetworkOptions struct {
+	// The address of the Kubernetes node that each Solr node will advertise itself with.
+	// Defaults to "IP".
+	// +optional
+	AdvertisedAddress HostNetworkAddressType `json:"advertisedAddress,omitempty"`
+}
+
+func (opts *SolrHostNetworkOptions) withDefaults() (changed bool) {
+	if opts.AdvertisedAddress == "" {
+		changed = true
+		opts.AdvertisedAddress = HostNetworkNodeIP
+	}
+	return changed
+}
+
 // ExternalAddressability defines the config for making Solr services available externally to kubernetes.
 // Be careful when using LoadBalanced and includeNodes, as many IP addresses could be created if you are running many large solrClouds.
 type ExternalAddressability struct {
@@ -469,6 +997,15 @@ type ExternalAddressability struct {
 	// +optional
 	UseExternalAddress bool `json:"useExternalAddress"`
 
+	// Do not let the Solr Operator create or update the resources that make the Solr service(s) externally addressable, such as Ingresses or ExternalDNS annotations.
+	// Use this when these resources are managed outside of the Solr Operator, e.g. by a different tool or through exposure methods that the Solr Operator does not support.
+	// The external addresses are still computed from the method and domainName, so that Solr Nodes can advertise them when useExternalAddress=true.
+	//
+	// Existing resources are left untouched when this option is enabled.
+	// Defaults to false.
+	// +optional
+	Unmanaged bool `json:"unmanaged,omitempty"`
+
 	// Do not expose the common Solr service externally. This affects a single service.
 	// Defaults to false.
 	// +optional
@@ -503,6 +1040,8 @@ type ExternalAddressability struct {
 	// If your ingress controller is not listening on the podPort, then this option is required for solr to be addressable via an Ingress.
 	//
 	// Defaults to 80 (without TLS) or 443 (with TLS) if HideNodes=false and method=Ingress, otherwise this is optional.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	NodePortOverride int `json:"nodePortOverride,omitempty"`
 
@@ -515,6 +1054,96 @@ type ExternalAddressability struct {
 	//
 	// +optional
 	IngressTLSTerminationSecret string `json:"ingressTLSTerminationSecret,omitempty"`
+
+	// AdvertisedPort defines the port that each Solr Node will advertise itself with, when useExternalAddress=true.
+	// Use this when the external address is reached through a different port than the one the node service(s) listen on,
+	// e.g. when Solr nodes are advertised on 443 behind an ingress controller that terminates TLS.
+	//
+	// With method=Ingress, this is also the port of the external addresses reported in the status.
+	//
+	// Defaults to the nodePortOverride if one is used, otherwise the podPort.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	AdvertisedPort int `json:"advertisedPort,omitempty"`
+
+	// AdvertisedScheme defines the URL scheme that each Solr Node will advertise itself with, when useExternalAddress=true.
+	// Setting this to "https" allows the external address to be advertised when using an ingressTLSTerminationSecret,
+	// however the Solr Nodes must then be able to reach each other through the ingress and trust its certificate.
+	//
+	// Defaults to the URL scheme that Solr is listening with.
+	// +kubebuilder:validation:Enum=http;https
+	// +optional
+	AdvertisedScheme string `json:"advertisedScheme,omitempty"`
+
+	// NodeNameTemplate is a Go template used to generate the external hostname of each Solr Node.
+	// The template is given the fields: PodName, Namespace, CloudName and Domain.
+	// e.g. "{{.PodName}}.search.{{.Domain}}"
+	//
+	// The PodName must be used unmodified, since the hostname of each Solr Node is generated from the name of its pod at runtime.
+	// Include the Domain, so that distinct hostnames are generated for the additionalDomains.
+	//
+	// This option is only available when Method=Ingress. Defaults to "{{.Namespace}}-{{.PodName}}.{{.Domain}}".
+	// +optional
+	NodeNameTemplate string `json:"nodeNameTemplate,omitempty"`
+
+	// Maintenance temporarily removes the common endpoint and/or specific Solr Nodes from the Ingress, without stopping any pods.
+	// This is useful during maintenance or bulk indexing windows.
+	//
+	// This option is only available when Method=Ingress.
+	// +optional
+	Maintenance *ExternalMaintenanceWindow `json:"maintenance,omitempty"`
+}
+
+// ExternalMaintenanceWindow defines the endpoints to remove from the Ingress during a maintenance window.
+type ExternalMaintenanceWindow struct {
+	// Remove the common endpoint from the Ingress during the maintenance window.
+	// +optional
+	HideCommon bool `json:"hideCommon,omitempty"`
+
+	// The names of the Solr pods to remove from the Ingress during the maintenance window.
+	// +optional
+	HideNodes []string `json:"hideNodes,omitempty"`
+
+	// The time when the maintenance window ends, and the removed endpoints are automatically restored to the Ingress.
+	// If not provided, the maintenance window lasts until this option is removed.
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+}
+
+// IsActive returns whether the maintenance window has not yet ended at the given time.
+func (mw *ExternalMaintenanceWindow) IsActive(now time.Time) bool {
+	return mw != nil && (mw.Until == nil || now.Before(mw.Until.Time))
+}
+
+// HidesNode returns whether the given Solr Node should be removed from the Ingress at the given time.
+func (mw *ExternalMaintenanceWindow) HidesNode(nodeName string, now time.Time) bool {
+	if !mw.IsActive(now) {
+		return false
+	}
+	for _, hiddenNode := range mw.HideNodes {
+		if hiddenNode == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeNameTemplateData is provided to the external.nodeNameTemplate when generating the external hostname of a Solr Node
+type NodeNameTemplateData struct {
+	PodName   string
+	Namespace string
+	CloudName string
+	Domain    string
+}
+
+// AdvertisedHostTemplateData is provided to the advertisedHostTemplate when generating the advertised host of a Solr Node
+type AdvertisedHostTemplateData struct {
+	PodName             string
+	PodIP               string
+	Namespace           string
+	CloudName           string
+	HeadlessServiceName string
 }
 
 // ExternalAddressability is a string enumeration type that enumerates
@@ -535,8 +1164,14 @@ const (
 )
 
 func (opts *ExternalAddressability) withDefaults(usesTLS bool) (changed bool) {
-	// You can't use an externalAddress for Solr Nodes if the Nodes are hidden externally
-	if opts.UseExternalAddress && (opts.HideNodes || opts.IngressTLSTerminationSecret != "") {
+	// Solr listens with TLS when it is enabled through spec.solrTLS, so the Solr Nodes cannot advertise http
+	if usesTLS && opts.AdvertisedScheme == "http" {
+		changed = true
+		opts.AdvertisedScheme = "https"
+	}
+	// You can't use an externalAddress for Solr Nodes if the Nodes are hidden externally.
+	// The same goes for ingress TLS termination, unless the Solr Nodes explicitly advertise https.
+	if opts.UseExternalAddress && (opts.HideNodes || (opts.IngressTLSTerminationSecret != "" && opts.AdvertisedScheme != "https")) {
 		changed = true
 		opts.UseExternalAddress = false
 	}
@@ -605,6 +1240,16 @@ func (opts *SolrUpdateStrategy) withDefaults() (changed bool) {
 		opts.Method = ManagedUpdate
 	}
 
+	if opts.ManagedUpdateOptions.MaxPodsUnavailablePerZone != nil && opts.ManagedUpdateOptions.ZoneTopologyKey == "" {
+		changed = true
+		opts.ManagedUpdateOptions.ZoneTopologyKey = DefaultZoneTopologyKey
+	}
+
+	if opts.Method == ManagedUpdate && opts.ManagedUpdateOptions.SingleReplicaShards == "" {
+		changed = true
+		opts.ManagedUpdateOptions.SingleReplicaShards = WarnSingleReplicaShards
+	}
+
 	return changed
 }
 
@@ -630,46 +1275,682 @@ type ManagedUpdateOptions struct {
 	//
 	// +optional
 	MaxShardReplicasUnavailable *intstr.IntOrString `json:"maxShardReplicasUnavailable,omitempty"`
-}
 
-// ZookeeperRef defines the zookeeper ensemble for solr to connect to
-// If no ConnectionString is provided, the solr-cloud controller will create and manage an internal ensemble
-type ZookeeperRef struct {
-	// A zookeeper ensemble that is run independently of the solr operator
-	// If an externalConnectionString is provided, but no internalConnectionString is, the external will be used as the internal
+	// The maximum number of pods within each zone that can be unavailable during the update.
+	// This limit is applied in addition to maxPodsUnavailable, so that both must be satisfied.
+	// Value can be an absolute number (ex: 1) or a percentage of the pods running in the zone (ex: 10%).
+	// Absolute number is calculated from percentage by rounding down.
+	// If the provided number is 0 or negative, then all pods in a zone will be allowed to be updated in unison.
+	//
+	// If not provided, pods will not be limited per zone.
+	//
 	// +optional
-	ConnectionInfo *ZookeeperConnectionInfo `json:"connectionInfo,omitempty"`
+	MaxPodsUnavailablePerZone *intstr.IntOrString `json:"maxPodsUnavailablePerZone,omitempty"`
 
-	// Create a new Zookeeper Ensemble with the following spec
-	// Note: This option will not allow the SolrCloud to run across kube-clusters.
-	// Note: Requires
-	//   - The zookeeperOperator flag to be provided to the Solr Operator
-	//   - A zookeeper operator to be running
+	// The label on Kubernetes Nodes that determines the zone of the Solr pods running on them.
+	// Only used when maxPodsUnavailablePerZone is provided.
+	//
+	// Defaults to "topology.kubernetes.io/zone".
+	//
 	// +optional
-	ProvidedZookeeper *ZookeeperSpec `json:"provided,omitempty"`
-}
+	ZoneTopologyKey string `json:"zoneTopologyKey,omitempty"`
 
-func (ref *ZookeeperRef) withDefaults() (changed bool) {
-	if ref.ProvidedZookeeper == nil && ref.ConnectionInfo == nil {
-		changed = true
-		ref.ProvidedZookeeper = &ZookeeperSpec{}
+	// The number of seconds that a pod is taken out of service before it is deleted for an update.
+	// When provided, Solr pods are given a readiness gate that the Solr Operator marks as failed for pods selected for an update,
+	// so that Service endpoints, service meshes and external load balancers can drain connections before Solr is stopped.
+	//
+	// Enabling or disabling this option changes the pod template, and will therefore cause a rolling restart.
+	//
+	// If not provided, pods are deleted as soon as they are selected for an update.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DrainSeconds *int32 `json:"drainSeconds,omitempty"`
+
+	// The number of seconds that a started pod is kept out of service, after its Solr container has become ready.
+	// This gives Solr time to warm its caches, e.g. through firstSearcher or newSearcher warming queries, before it receives traffic,
+	// smoothing the latency spike after each pod restart.
+	// The warm-up is managed through the same readiness gate as drainSeconds, and the Managed update waits for warming pods to become ready.
+	//
+	// Enabling or disabling this option changes the pod template, and will therefore cause a rolling restart.
+	//
+	// If not provided, pods are put into service as soon as their Solr container is ready.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WarmUpSeconds *int32 `json:"warmUpSeconds,omitempty"`
+
+	// What to do when a pod that is about to be restarted for an update hosts the only replica of a shard,
+	// such as shards of collections with a replicationFactor of 1. Such shards are unavailable while the pod restarts.
+	//
+	// Defaults to "Warn".
+	//
+	// +optional
+	SingleReplicaShards SingleReplicaShardsPolicy `json:"singleReplicaShards,omitempty"`
+
+	// Checks that must pass before the Managed update restarts the next batch of pods.
+	// Once pods have been updated, each check is run against every ready Solr node that is up to date,
+	// acting as a smoke test of the new pod spec. If any check fails, no further pods are restarted until it passes.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	VerificationChecks []UpdateVerificationCheck `json:"verificationChecks,omitempty"`
+}
+
+// UpdateVerificationCheck is an HTTP request to Solr that must succeed between the batches of a Managed update.
+type UpdateVerificationCheck struct {
+	// The name of the check, used when reporting failures.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The path of the request, relative to "/solr", including any query parameters.
+	// For example "/admin/info/health" or "/techproducts/select?q=*:*&rows=0".
+	// The check fails if Solr does not respond with a 200 status code.
+	//
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// The minimum number of documents that the query must find, in "response.numFound".
+	// Only provide this for query requests.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinHits *int64 `json:"minHits,omitempty"`
+}
+
+// SingleReplicaShardsPolicy is a string enumeration type that enumerates
+// the ways that a Managed update can treat pods that host the only replica of a shard.
+// +kubebuilder:validation:Enum=Warn;Block;AddReplica
+type SingleReplicaShardsPolicy string
+
+const (
+	// Restart the pod anyways, and create a Warning event listing the shards that become unavailable.
+	// This is the default option.
+	WarnSingleReplicaShards SingleReplicaShardsPolicy = "Warn"
+
+	// Do not restart the pod, until the shards are given more replicas or are moved off of the pod by the user.
+	// The update will not complete while this is the case.
+	BlockSingleReplicaShards SingleReplicaShardsPolicy = "Block"
+
+	// Add a temporary replica for each of the shards on another Solr node, preferably one that is already up to date,
+	// and restart the pod once the temporary replicas are active.
+	// The temporary replicas are deleted once the pod has been updated and its replicas are active again.
+	AddReplicaForSingleReplicaShards SingleReplicaShardsPolicy = "AddReplica"
+)
+
+// UsesServingReadinessGate returns whether Solr pods should be given the readiness gate used to drain them before updates,
+// and to warm them up after they start.
+func (opts *SolrUpdateStrategy) UsesServingReadinessGate() bool {
+	if opts.Method != ManagedUpdate {
+		return false
+	}
+	return (opts.ManagedUpdateOptions.DrainSeconds != nil && *opts.ManagedUpdateOptions.DrainSeconds > 0) ||
+		(opts.ManagedUpdateOptions.WarmUpSeconds != nil && *opts.ManagedUpdateOptions.WarmUpSeconds > 0)
+}
+
+// SolrOperatorClientOptions defines how the Solr Operator connects to a SolrCloud
+type SolrOperatorClientOptions struct {
+	// A key in a Secret containing PEM-encoded CA certificates, that the Solr Operator uses to verify the server certificate of this SolrCloud.
+	// This allows the Solr Operator to verify SolrClouds that use certificates issued by private CAs, without trusting those CAs for all SolrClouds.
+	// The certificate must be valid for the hostname of the SolrCloud's common service.
+	// +optional
+	CABundleSecret *corev1.SecretKeySelector `json:"caBundleSecret,omitempty"`
+
+	// The timeout, in seconds, for each request that the Solr Operator sends to this SolrCloud.
+	// Defaults to the timeout that the Solr Operator is started with, which is 30 seconds unless overridden.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// SolrScalingOptions defines how the Solr Operator assists with scaling a SolrCloud
+type SolrScalingOptions struct {
+	// Set the "controller.kubernetes.io/pod-deletion-cost" annotation on Solr pods, based on the shard leaders and replicas that each pod hosts.
+	// Pods hosting fewer leaders and replicas are given a lower cost, so that scale-down mechanisms that honor the annotation remove them first.
+	// The overseer leader is always given the highest cost.
+	//
+	// The costs are refreshed periodically, since changes to the Solr cluster state do not trigger a reconcile.
+	//
+	// +optional
+	PodDeletionCost bool `json:"podDeletionCost,omitempty"`
+}
+
+// SolrProbesOptions defines the probes that the Solr Operator generates for the Solr container
+// SolrPodSecurityProfile is the security profile of the Solr pods that the Solr Operator generates
+// +kubebuilder:validation:Enum=Default;Restricted
+type SolrPodSecurityProfile string
+
+const (
+	// The Solr pods run with an fsGroup, and the init container that prepares the volumes runs as the user of the BusyBox image,
+	// changing the ownership of managed backup repositories.
+	DefaultPodSecurityProfile SolrPodSecurityProfile = "Default"
+
+	// The Solr pods conform to the restricted Pod Security Standard.
+	// All generated containers run as the Solr user, without privilege escalation or capabilities, using the RuntimeDefault seccomp profile.
+	// The ownership of volumes is set through the fsGroup and supplementalGroups of the pod, instead of by the init container.
+	RestrictedPodSecurityProfile SolrPodSecurityProfile = "Restricted"
+)
+
+type SolrProbesOptions struct {
+	// The startupProbe holds off the liveness and readiness probes until Solr has started.
+	// Solr nodes with many or large cores can take a long time to load them, and would otherwise be killed by the livenessProbe.
+	// +optional
+	Startup SolrStartupProbeOptions `json:"startup,omitempty"`
+}
+
+func (opts *SolrProbesOptions) withDefaults() (changed bool) {
+	return opts.Startup.withDefaults()
+}
+
+// SolrStartupProbeOptions defines the startupProbe of the Solr container.
+// Solr is given PeriodSeconds * FailureThreshold seconds to start, 10 minutes by default.
+type SolrStartupProbeOptions struct {
+	// How often to probe whether Solr has started, in seconds.
+	// Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+
+	// The number of seconds after which a single probe times out.
+	// Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// The number of failed probes after which Solr is considered unable to start, and the container is restarted.
+	// Defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+func (opts *SolrStartupProbeOptions) withDefaults() (changed bool) {
+	if opts.PeriodSeconds == 0 {
+		changed = true
+		opts.PeriodSeconds = DefaultStartupProbePeriodSeconds
+	}
+	if opts.TimeoutSeconds == 0 {
+		changed = true
+		opts.TimeoutSeconds = DefaultStartupProbeTimeoutSeconds
+	}
+	if opts.FailureThreshold == 0 {
+		changed = true
+		opts.FailureThreshold = DefaultStartupProbeFailureThreshold
+	}
+	return changed
+}
+
+// SolrClusterStateOptions defines how the Solr Operator reads the cluster state of a SolrCloud
+type SolrClusterStateOptions struct {
+	// Fetch the cluster state one collection at a time, rather than through a single CLUSTERSTATUS request for the whole cluster,
+	// when the SolrCloud has more than this number of collections.
+	// This keeps each response small for SolrClouds with thousands of collections, so that they are not timed out or buffered in full by Solr.
+	// If not provided, the cluster state is always fetched through a single request.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PerCollectionThreshold *int32 `json:"perCollectionThreshold,omitempty"`
+}
+
+// SolrInventoryOptions defines how the Solr Operator exports the inventory of a SolrCloud
+type SolrInventoryOptions struct {
+	// How often the inventory is refreshed from the Solr cluster state, in seconds.
+	// Defaults to 60.
+	// +kubebuilder:validation:Minimum=10
+	// +optional
+	RefreshIntervalSeconds int32 `json:"refreshIntervalSeconds,omitempty"`
+}
+
+func (opts *SolrInventoryOptions) withDefaults() (changed bool) {
+	if opts.RefreshIntervalSeconds == 0 {
+		changed = true
+		opts.RefreshIntervalSeconds = DefaultInventoryRefreshIntervalSeconds
+	}
+	return changed
+}
+
+// SolrCollectionMetricsOptions defines how the Solr Operator exports the metrics of the collections in a SolrCloud
+type SolrCollectionMetricsOptions struct {
+	// How often the collection metrics are refreshed from the Solr cluster state, in seconds.
+	// Each refresh sends a query to every collection to count its documents.
+	// Defaults to 60.
+	// +kubebuilder:validation:Minimum=10
+	// +optional
+	RefreshIntervalSeconds int32 `json:"refreshIntervalSeconds,omitempty"`
+}
+
+func (opts *SolrCollectionMetricsOptions) withDefaults() (changed bool) {
+	if opts.RefreshIntervalSeconds == 0 {
+		changed = true
+		opts.RefreshIntervalSeconds = DefaultCollectionMetricsRefreshIntervalSeconds
+	}
+	return changed
+}
+
+// SolrNodeMetricsOptions defines how the Solr Operator relays the metrics of the Solr pods in a SolrCloud
+type SolrNodeMetricsOptions struct {
+	// How often the metrics are scraped from each Solr pod, in seconds.
+	// Defaults to 30.
+	// +kubebuilder:validation:Minimum=10
+	// +optional
+	RefreshIntervalSeconds int32 `json:"refreshIntervalSeconds,omitempty"`
+}
+
+func (opts *SolrNodeMetricsOptions) withDefaults() (changed bool) {
+	if opts.RefreshIntervalSeconds == 0 {
+		changed = true
+		opts.RefreshIntervalSeconds = DefaultNodeMetricsRefreshIntervalSeconds
+	}
+	return changed
+}
+
+// SolrNodeInterruptionOptions defines how the Solr Operator detects that a Kubernetes Node hosting Solr pods is about to be interrupted
+type SolrNodeInterruptionOptions struct {
+	// The keys of the Node taints that signal an upcoming interruption of the Node.
+	// When a Node has any of these taints, the leaders of shards hosted on its Solr pods are moved to replicas on other pods.
+	// Defaults to the taints used by the Kubernetes cordon, the cluster-autoscaler, the AWS Node Termination Handler and GKE.
+	// +optional
+	TaintKeys []string `json:"taintKeys,omitempty"`
+
+	// Add replicas on Solr pods that are not being interrupted, for the shards that would otherwise have no replica left outside of the interrupted Nodes,
+	// or no replica outside of them that can become the leader.
+	// The replicas on the interrupted Nodes are kept, since they return when their pods are rescheduled.
+	// +optional
+	EvacuateReplicas bool `json:"evacuateReplicas,omitempty"`
+}
+
+func (opts *SolrNodeInterruptionOptions) withDefaults() (changed bool) {
+	if len(opts.TaintKeys) == 0 {
+		changed = true
+		opts.TaintKeys = append([]string{}, DefaultNodeInterruptionTaintKeys...)
+	}
+	return changed
+}
+
+// SolrSpotNodeOptions defines how Solr pods are scheduled onto spot or preemptible Nodes
+type SolrSpotNodeOptions struct {
+	// The labels that identify spot Nodes, such as cloud.google.com/gke-spot=true or eks.amazonaws.com/capacityType=SPOT.
+	// Nodes must have all of the labels to be considered spot Nodes.
+	// +kubebuilder:validation:MinProperties=1
+	NodeLabels map[string]string `json:"nodeLabels"`
+
+	// Whether Solr pods must run on spot Nodes, or should preferably run on them and fall back to other Nodes when there is no spot capacity.
+	// Defaults to "preferred".
+	// +optional
+	Scheduling SpotNodeScheduling `json:"scheduling,omitempty"`
+
+	// The tolerations for the taints of the spot Nodes. These are added to the tolerations of the podOptions.
+	// Defaults to tolerating the spot and preemptible taints of GKE and AKS.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+func (opts *SolrSpotNodeOptions) withDefaults() (changed bool) {
+	if opts.Scheduling == "" {
+		changed = true
+		opts.Scheduling = PreferredSpotNodeScheduling
+	}
+	if len(opts.Tolerations) == 0 {
+		changed = true
+		opts.Tolerations = append([]corev1.Toleration{}, DefaultSpotNodeTolerations...)
+	}
+	return changed
+}
+
+// SpotNodeScheduling is how strictly Solr pods are kept on spot Nodes
+// +kubebuilder:validation:Enum=required;preferred
+type SpotNodeScheduling string
+
+const (
+	RequiredSpotNodeScheduling  SpotNodeScheduling = "required"
+	PreferredSpotNodeScheduling SpotNodeScheduling = "preferred"
+)
+
+// SolrAvailabilityOptions defines how Solr pods are spread across the Nodes and zones of the Kubernetes cluster
+type SolrAvailabilityOptions struct {
+	// Whether Solr pods of the SolrCloud must, or should preferably, run on different Nodes.
+	// "required" does not schedule a Solr pod on a Node that already runs one, so the SolrCloud cannot have more pods than there are Nodes.
+	// "preferred" schedules Solr pods on different Nodes when possible.
+	// Defaults to "none", which generates no pod anti-affinity.
+	// +optional
+	PodAntiAffinity PodAntiAffinityPreset `json:"podAntiAffinity,omitempty"`
+
+	// Spread the Solr pods of the SolrCloud evenly across zones, when possible.
+	// +optional
+	SpreadAcrossZones bool `json:"spreadAcrossZones,omitempty"`
+
+	// The label on Kubernetes Nodes that determines their zone, used when spreadAcrossZones is enabled.
+	// Defaults to "topology.kubernetes.io/zone".
+	// +optional
+	ZoneTopologyKey string `json:"zoneTopologyKey,omitempty"`
+
+	// The number of Solr nodes that must be ready before the SolrCloud is considered formed, after all of its nodes were down.
+	// Until then, the SolrCloud stays Pending and the common service has no endpoints, so that client traffic is not sent to the first node that starts.
+	// Once formed, the SolrCloud stays formed until none of its nodes are ready.
+	// Value can be an absolute number (ex: 2) or a percentage of the desired number of pods (ex: 50%).
+	// Absolute number is calculated from percentage by rounding up, and is capped at the desired number of pods.
+	//
+	// If not provided, or the number is 0 or negative, the common service always includes the ready nodes.
+	//
+	// +optional
+	MinReadyNodesForReady *intstr.IntOrString `json:"minReadyNodesForReady,omitempty"`
+}
+
+func (opts *SolrAvailabilityOptions) withDefaults() (changed bool) {
+	if opts.PodAntiAffinity == "" {
+		changed = true
+		opts.PodAntiAffinity = NoPodAntiAffinity
+	}
+	if opts.SpreadAcrossZones && opts.ZoneTopologyKey == "" {
+		changed = true
+		opts.ZoneTopologyKey = DefaultZoneTopologyKey
+	}
+	return changed
+}
+
+// SolrManagedResourcesOptions defines which child resources of a SolrCloud are managed by the Solr Operator
+type SolrManagedResourcesOptions struct {
+	// Manage the Ingress that exposes the SolrCloud, when the external addressability method is "Ingress".
+	// When false, the external addresses are still computed, so Solr nodes can advertise them, but the Ingress must be provided by the user.
+	// Defaults to true.
+	// +optional
+	ManageIngress *bool `json:"manageIngress,omitempty"`
+
+	// Manage the ConfigMap containing the solr.xml, unless a providedConfigMap is given in the customSolrKubeOptions.
+	// When false, a ConfigMap with the same name, "<cloud-name>-solrcloud-configmap", must be provided by the user,
+	// with a "solr.xml" that contains a placeholder for the 'hostPort' variable.
+	// Defaults to true.
+	// +optional
+	ManageConfigMap *bool `json:"manageConfigMap,omitempty"`
+
+	// Manage the common, headless and individual node Services of the SolrCloud.
+	// When false, Services with the same names must be provided by the user, so that Solr nodes can address each other.
+	// Defaults to true.
+	// +optional
+	ManageServices *bool `json:"manageServices,omitempty"`
+}
+
+func (opts *SolrManagedResourcesOptions) withDefaults() (changed bool) {
+	if opts.ManageIngress == nil {
+		changed = true
+		manageIngress := true
+		opts.ManageIngress = &manageIngress
+	}
+	if opts.ManageConfigMap == nil {
+		changed = true
+		manageConfigMap := true
+		opts.ManageConfigMap = &manageConfigMap
+	}
+	if opts.ManageServices == nil {
+		changed = true
+		manageServices := true
+		opts.ManageServices = &manageServices
+	}
+	return changed
+}
+
+// PodAntiAffinityPreset is how strictly Solr pods are kept on different Nodes
+// +kubebuilder:validation:Enum=required;preferred;none
+type PodAntiAffinityPreset string
+
+const (
+	RequiredPodAntiAffinity  PodAntiAffinityPreset = "required"
+	PreferredPodAntiAffinity PodAntiAffinityPreset = "preferred"
+	NoPodAntiAffinity        PodAntiAffinityPreset = "none"
+)
+
+// ZookeeperRef defines the zookeeper ensemble for solr to connect to
+// If no ConnectionString is provided, the solr-cloud controller will create and manage an internal ensemble
+type ZookeeperRef struct {
+	// A zookeeper ensemble that is run independently of the solr operator
+	// If an externalConnectionString is provided, but no internalConnectionString is, the external will be used as the internal
+	// +optional
+	ConnectionInfo *ZookeeperConnectionInfo `json:"connectionInfo,omitempty"`
+
+	// A Kubernetes Service in front of a zookeeper ensemble that is run independently of the solr operator,
+	// such as the headless Service of a Zookeeper StatefulSet.
+	// The operator resolves the Service into the connection string, and keeps it up to date as the ensemble changes.
+	// +optional
+	ServiceRef *ZookeeperServiceRef `json:"serviceRef,omitempty"`
+
+	// Create a new Zookeeper Ensemble with the following spec
+	// Note: This option will not allow the SolrCloud to run across kube-clusters.
+	// Note: Requires
+	//   - The zookeeperOperator flag to be provided to the Solr Operator
+	//   - A zookeeper operator to be running
+	// +optional
+	ProvidedZookeeper *ZookeeperSpec `json:"provided,omitempty"`
+
+	// Authenticate to the Zookeeper ensemble via SASL.
+	// The JAAS configuration for the ZK client is generated by the operator, unless one is provided through
+	// spec.solrSecurity.jaasConfigSecret, in which case it must contain a "Client" section.
+	// +optional
+	SASL *ZookeeperSASLOptions `json:"sasl,omitempty"`
+
+	// Connect to the secure client port of a TLS-enabled Zookeeper ensemble.
+	// The connection string must point to the secure client port of the ensemble.
+	// +optional
+	TLS *ZookeeperTLSOptions `json:"tls,omitempty"`
+
+	// The number of seconds that the setup-zk init container of each Solr pod waits for Zookeeper to become available, before failing.
+	// The reason for the failure is reported in the SolrCloud status and in events for the pod.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ConnectionTimeoutSeconds *int32 `json:"connectionTimeoutSeconds,omitempty"`
+
+	// Settings for the Zookeeper client of Solr, which are commonly raised for clusters with a very large cluster state.
+	// These are passed to Solr, and the setup-zk init container, as system properties.
+	// Changing them will cause a rolling restart.
+	// +optional
+	ClientOptions *ZookeeperClientOptions `json:"clientOptions,omitempty"`
+}
+
+// ZookeeperClientOptions defines settings for the Zookeeper client of Solr
+type ZookeeperClientOptions struct {
+	// The Zookeeper session timeout of Solr, in milliseconds.
+	// This is the "zkClientTimeout" value of solr.xml, and is only used if the solr.xml reads it from the
+	// "zkClientTimeout" system property, as the default solr.xml does.
+	// Solr defaults to 30000.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ClientTimeoutMillis *int32 `json:"clientTimeoutMillis,omitempty"`
+
+	// The maximum size, in bytes, of data that the Zookeeper client can read or write in a single znode, through "jute.maxbuffer".
+	// Collections with very many shards or replicas can have a state.json larger than the Zookeeper default of 1 MB (1048575 bytes).
+	// The Zookeeper servers must be configured with at least the same value.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	JuteMaxBufferBytes *int32 `json:"juteMaxBufferBytes,omitempty"`
+}
+
+func (ref *ZookeeperRef) withDefaults() (changed bool) {
+	if ref.ProvidedZookeeper == nil && ref.ConnectionInfo == nil && ref.ServiceRef == nil {
+		changed = true
+		ref.ProvidedZookeeper = &ZookeeperSpec{}
 	} else if ref.ConnectionInfo != nil {
 		if ref.ProvidedZookeeper != nil {
 			ref.ProvidedZookeeper = nil
 			changed = true
 		}
 		changed = ref.ConnectionInfo.withDefaults() || changed
+	} else if ref.ServiceRef != nil {
+		if ref.ProvidedZookeeper != nil {
+			ref.ProvidedZookeeper = nil
+			changed = true
+		}
+		changed = ref.ServiceRef.withDefaults() || changed
 	}
 	if ref.ProvidedZookeeper != nil {
 		changed = ref.ProvidedZookeeper.WithDefaults() || changed
 	}
+	if ref.SASL != nil {
+		changed = ref.SASL.withDefaults() || changed
+	}
+	if ref.ConnectionTimeoutSeconds == nil {
+		changed = true
+		ref.ConnectionTimeoutSeconds = new(int32)
+		*ref.ConnectionTimeoutSeconds = DefaultZkConnectionTimeoutSeconds
+	}
+	return changed
+}
+
+// ExternalEnsemble identifies the zookeeper ensemble, run independently of the solr operator, that is referenced
+// either through a connection string or a Service, and returns the chroot used within it.
+// An empty key is returned if no such ensemble is referenced.
+func (ref *ZookeeperRef) ExternalEnsemble(namespace string) (key string, chRoot string) {
+	if ref.ConnectionInfo != nil {
+		return ref.ConnectionInfo.EnsembleKey(), ref.ConnectionInfo.ChRoot
+	} else if ref.ServiceRef != nil {
+		return "service:" + ref.ServiceRef.NamespaceOr(namespace) + "/" + ref.ServiceRef.Name, ref.ServiceRef.ChRoot
+	}
+	return "", ""
+}
+
+// ZookeeperServiceRef references a Kubernetes Service in front of a zookeeper ensemble
+type ZookeeperServiceRef struct {
+	// The name of the Service
+	Name string `json:"name"`
+
+	// The namespace of the Service, defaults to the namespace of the SolrCloud.
+	// The Solr Operator must be able to watch this namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The name of the Service port that zookeeper clients connect to.
+	// Defaults to the port numbered 2181, or the only port of the Service.
+	// +optional
+	PortName string `json:"portName,omitempty"`
+
+	// The ChRoot to connect solr at
+	// +optional
+	ChRoot string `json:"chroot,omitempty"`
+
+	// ZooKeeper ACL to use when connecting with ZK.
+	// This ACL should have ALL permission in the given chRoot.
+	// +optional
+	AllACL *ZookeeperACL `json:"acl,omitempty"`
+
+	// ZooKeeper ACL to use when connecting with ZK for reading operations.
+	// This ACL should have READ permission in the given chRoot.
+	// +optional
+	ReadOnlyACL *ZookeeperACL `json:"readOnlyAcl,omitempty"`
+}
+
+func (ref *ZookeeperServiceRef) withDefaults() (changed bool) {
+	if ref.ChRoot == "" {
+		changed = true
+		ref.ChRoot = "/"
+	} else if !strings.HasPrefix(ref.ChRoot, "/") {
+		changed = true
+		ref.ChRoot = "/" + ref.ChRoot
+	}
+	return changed
+}
+
+// NamespaceOr returns the namespace of the Service, or the given namespace if none was specified
+func (ref *ZookeeperServiceRef) NamespaceOr(namespace string) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return namespace
+}
+
+// ZookeeperSASLMechanism is a string enumeration type that enumerates
+// the SASL mechanisms that Solr can use to authenticate with Zookeeper.
+// +kubebuilder:validation:Enum=DIGEST-MD5;GSSAPI
+type ZookeeperSASLMechanism string
+
+const (
+	// Authenticate with a username and password
+	ZookeeperSASLDigestMD5 ZookeeperSASLMechanism = "DIGEST-MD5"
+
+	// Authenticate with Kerberos
+	ZookeeperSASLKerberos ZookeeperSASLMechanism = "GSSAPI"
+)
+
+type ZookeeperSASLOptions struct {
+	// The SASL mechanism to use when authenticating with Zookeeper.
+	// Defaults to DIGEST-MD5.
+	// +optional
+	Mechanism ZookeeperSASLMechanism `json:"mechanism,omitempty"`
+
+	// The name of a Secret (kubernetes.io/basic-auth) containing the username and password to authenticate with.
+	// Required for the DIGEST-MD5 mechanism.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// The Kerberos principal that Solr authenticates to Zookeeper as.
+	// Required for the GSSAPI mechanism.
+	// +optional
+	Principal string `json:"principal,omitempty"`
+
+	// The secret key containing the Kerberos keytab for the principal.
+	// Required for the GSSAPI mechanism.
+	// +optional
+	KeytabSecret *corev1.SecretKeySelector `json:"keytabSecret,omitempty"`
+
+	// The configMap key containing the krb5.conf to use for the GSSAPI mechanism.
+	// If not provided, the krb5.conf provided in the Solr image is used.
+	// +optional
+	Krb5Config *corev1.ConfigMapKeySelector `json:"krb5Config,omitempty"`
+
+	// The primary of the Kerberos principal that the Zookeeper servers run as.
+	// Defaults to "zookeeper".
+	// +optional
+	ServerPrincipalName string `json:"serverPrincipalName,omitempty"`
+
+	// Secure the znodes that Solr creates with SASL ACLs, through the SaslZkACLProvider.
+	// Only the SASL-authenticated principal, and any client that can authenticate as it, can then modify these znodes.
+	// Cannot be combined with the digest ACLs of the zookeeperRef. Defaults to false.
+	// +optional
+	SASLACLs bool `json:"saslACLs,omitempty"`
+}
+
+func (opts *ZookeeperSASLOptions) withDefaults() (changed bool) {
+	if opts.Mechanism == "" {
+		changed = true
+		opts.Mechanism = ZookeeperSASLDigestMD5
+	}
+	if opts.Mechanism == ZookeeperSASLKerberos && opts.ServerPrincipalName == "" {
+		changed = true
+		opts.ServerPrincipalName = "zookeeper"
+	}
 	return changed
 }
 
+// ZookeeperTLSOptions defines how Solr connects to the secure client port of a Zookeeper ensemble
+type ZookeeperTLSOptions struct {
+	// The secret key containing the PKCS12 truststore used to verify the certificates of the Zookeeper servers.
+	TrustStoreSecret *corev1.SecretKeySelector `json:"trustStoreSecret"`
+
+	// The secret key containing the password for the truststore.
+	// +optional
+	TrustStorePasswordSecret *corev1.SecretKeySelector `json:"trustStorePasswordSecret,omitempty"`
+
+	// The secret key containing the PKCS12 keystore that Solr authenticates to Zookeeper with.
+	// Only required if the Zookeeper servers require client authentication.
+	// +optional
+	KeyStoreSecret *corev1.SecretKeySelector `json:"keyStoreSecret,omitempty"`
+
+	// The secret key containing the password for the keystore.
+	// +optional
+	KeyStorePasswordSecret *corev1.SecretKeySelector `json:"keyStorePasswordSecret,omitempty"`
+
+	// Do not verify that the hostnames of the Zookeeper servers match their certificates.
+	// Defaults to false.
+	// +optional
+	DisableHostnameVerification bool `json:"disableHostnameVerification,omitempty"`
+}
+
 func (ref *ZookeeperRef) GetACLs() (allACL *ZookeeperACL, readOnlyACL *ZookeeperACL) {
 	if ref.ConnectionInfo != nil {
 		allACL = ref.ConnectionInfo.AllACL
 		readOnlyACL = ref.ConnectionInfo.ReadOnlyACL
+	} else if ref.ServiceRef != nil {
+		allACL = ref.ServiceRef.AllACL
+		readOnlyACL = ref.ServiceRef.ReadOnlyACL
 	} else if ref.ProvidedZookeeper != nil {
 		allACL = ref.ProvidedZookeeper.AllACL
 		readOnlyACL = ref.ProvidedZookeeper.ReadOnlyACL
@@ -918,8 +2199,33 @@ type ZookeeperPodPolicy struct {
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 }
 
+// SolrCloudPhase is a summary of the state of a SolrCloud
+type SolrCloudPhase string
+
+const (
+	// No Solr nodes are ready yet
+	SolrCloudPending SolrCloudPhase = "Pending"
+
+	// Solr nodes are being updated to the latest pod spec or Solr version
+	SolrCloudUpdating SolrCloudPhase = "Updating"
+
+	// Some, but not all, of the desired Solr nodes are ready
+	SolrCloudDegraded SolrCloudPhase = "Degraded"
+
+	// All of the desired Solr nodes are ready and up to date
+	SolrCloudReady SolrCloudPhase = "Ready"
+)
+
 // SolrCloudStatus defines the observed state of SolrCloud
 type SolrCloudStatus struct {
+	// Phase is a summary of the state of the SolrCloud, computed from the other status fields.
+	// +optional
+	Phase SolrCloudPhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation of the SolrCloud spec that this status reflects.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// SolrNodes contain the statuses of each solr node running in this solr cloud.
 	SolrNodes []SolrNodeStatus `json:"solrNodes"`
 
@@ -957,6 +2263,303 @@ type SolrCloudStatus struct {
 	// BackupRestoreReady announces whether the solrCloud has the backupRestorePVC mounted to all pods
 	// and therefore is ready for backups and restores.
 	BackupRestoreReady bool `json:"backupRestoreReady"`
+
+	// ZookeeperError is the reason that Solr pods are not able to connect to Zookeeper, if any are currently failing to.
+	// +optional
+	ZookeeperError string `json:"zookeeperError,omitempty"`
+
+	// SharedZookeeperChRoots lists the chroots used by the other SolrClouds, managed by this Solr Operator,
+	// that connect to the same Zookeeper ensemble as this SolrCloud.
+	// +optional
+	SharedZookeeperChRoots []SharedZookeeperChRoot `json:"sharedZookeeperChRoots,omitempty"`
+
+	// ReadOnly announces whether the operator has put all collections of the SolrCloud into read-only mode.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Standby describes the restores of a standby SolrCloud, when spec.standbyOf is set.
+	// +optional
+	Standby *SolrStandbyStatus `json:"standby,omitempty"`
+
+	// ConfigSetFiles lists the configsets whose files, from spec.configSetFiles, have been synced into Zookeeper.
+	// +optional
+	// +listType=map
+	// +listMapKey=configSet
+	ConfigSetFiles []ConfigSetFilesStatus `json:"configSetFiles,omitempty"`
+
+	// SecurityJson describes the security.json that was last synced into Zookeeper, when the spec.solrSecurity.securityJsonMode is "Managed".
+	// +optional
+	SecurityJson *ManagedSecurityJsonStatus `json:"securityJson,omitempty"`
+
+	// TemporaryReplicas lists the replicas that were added to shards with a single replica, so that they stay available while
+	// the pod hosting that replica is restarted for a Managed update.
+	// Only used when spec.updateStrategy.managed.singleReplicaShards is "AddReplica".
+	// +optional
+	TemporaryReplicas []TemporaryReplicaStatus `json:"temporaryReplicas,omitempty"`
+
+	// Diagnostics describes the diagnostics that were last collected from the Solr pods,
+	// as requested through the "solr.apache.org/collectDiagnostics" annotation.
+	// +optional
+	Diagnostics *SolrDiagnosticsStatus `json:"diagnostics,omitempty"`
+
+	// Binding references the Secret containing the connection information for this SolrCloud.
+	// This implements the Provisioned Service duck-type of the Service Binding specification (servicebinding.io).
+	// Only provided when spec.connectionInfo is set.
+	// +optional
+	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
+
+	// Resources contains the names of the Kubernetes resources that are used by this SolrCloud,
+	// so that they can be referenced without knowing the naming conventions of the Solr Operator.
+	// +optional
+	Resources SolrCloudResourceNames `json:"resources,omitempty"`
+
+	// History lists the latest significant operations on the SolrCloud, oldest first, with their outcomes:
+	// restarts and scaling of the Solr pods, and backups and restores of its collections.
+	// The reasons of the last restart are also set on each Solr pod, in the "solr.apache.org/restartReason" annotation.
+	// Only the last 20 operations are kept.
+	// +optional
+	History []SolrCloudOperation `json:"history,omitempty"`
+
+	// Conditions describe the latest observations of the SolrCloud.
+	// The "ConfigurationValid" condition is False, with the reason and message of the problem, when the SolrCloud
+	// or a resource that it references is misconfigured. Such SolrClouds are not reconciled again until they are changed.
+	// The "BackupsInProgress" condition is True while SolrBackups or SolrRestores of the SolrCloud are in progress, and its reason explains
+	// whether a deletion of the SolrCloud, or a removal of a backup repository, is being blocked by them.
+	// The "ClusterFormed" condition is True once spec.availability.minReadyNodesForReady Solr nodes are ready.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// SolrCloudConfigurationValid is the condition type that reports whether the SolrCloud, and the resources that it references,
+	// are configured correctly
+	SolrCloudConfigurationValid = "ConfigurationValid"
+
+	// SolrCloudBackupsInProgress is the condition type that reports whether SolrBackups or SolrRestores of the SolrCloud are in progress.
+	// While it is True, the SolrCloud cannot be deleted, and backup repositories that are in use cannot be removed.
+	SolrCloudBackupsInProgress = "BackupsInProgress"
+
+	// SolrCloudClusterFormed is the condition type that reports whether enough Solr nodes are ready for the SolrCloud to be formed,
+	// when spec.availability.minReadyNodesForReady is provided. The common service has no endpoints until it is True.
+	SolrCloudClusterFormed = "ClusterFormed"
+)
+
+// SolrConnectionInfoOptions defines the Secret that is generated for client applications to connect to a SolrCloud.
+type SolrConnectionInfoOptions struct {
+	// The name of the Secret to create.
+	// Defaults to "<cloud-name>-solrcloud-connection-info".
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// The name of a Secret of type kubernetes.io/basic-auth, containing the credentials of a Solr user for applications to use.
+	// If provided, the username and password will be included in the connection info Secret.
+	// +optional
+	AppUserSecret string `json:"appUserSecret,omitempty"`
+}
+
+// TemporaryReplicaStatus is a replica that was added to a shard with a single replica, while the pod hosting that replica is updated
+type TemporaryReplicaStatus struct {
+	// The collection of the shard
+	Collection string `json:"collection"`
+
+	// The shard that the replica was added to
+	Shard string `json:"shard"`
+
+	// The Solr node that hosts the temporary replica
+	Node string `json:"node"`
+
+	// The pod hosting the original replica of the shard, the temporary replica is deleted once this pod is up to date
+	Pod string `json:"pod"`
+}
+
+// ManagedSecurityJsonStatus is the state of the security.json that the Solr Operator manages in Zookeeper
+type ManagedSecurityJsonStatus struct {
+	// The hash of the security.json in the securityJsonSecret, as of the last sync
+	ContentHash string `json:"contentHash"`
+
+	// When the security.json was last uploaded to Zookeeper, because it changed in the Secret or had drifted in Zookeeper
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// When the security.json in Zookeeper was last compared with the Secret
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// SolrStandbyStatus is the state of the restores of a standby SolrCloud
+type SolrStandbyStatus struct {
+	// The generation of the restored collections that the aliases of the standby currently point to.
+	// Each restore creates a new generation, since collections cannot be restored into existing collections.
+	// +optional
+	Generation int32 `json:"generation,omitempty"`
+
+	// The name of the SolrRestore that is restoring the next generation, if one is in progress
+	// +optional
+	SolrRestore string `json:"solrRestore,omitempty"`
+
+	// When the last successful restore finished
+	// +optional
+	LastRestoreTime *metav1.Time `json:"lastRestoreTime,omitempty"`
+
+	// When the next restore is started
+	// +optional
+	NextRestoreTime *metav1.Time `json:"nextRestoreTime,omitempty"`
+}
+
+// ConfigSetFilesStatus is the state of the files synced into a configset
+type ConfigSetFilesStatus struct {
+	// The name of the configset
+	ConfigSet string `json:"configSet"`
+
+	// The hash of the contents of all synced files
+	ContentHash string `json:"contentHash"`
+
+	// The collections that were reloaded after the files were last synced
+	// +optional
+	ReloadedCollections []string `json:"reloadedCollections,omitempty"`
+
+	// When the files were last synced
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+
+	// The files of the configset that have drifted from the ConfigMap, as of the last drift check.
+	// This includes "configoverlay.json" if the configset has been changed through the Config API.
+	// +optional
+	DriftedFiles []string `json:"driftedFiles,omitempty"`
+
+	// When the configset was last checked for drift
+	// +optional
+	LastDriftCheckTime *metav1.Time `json:"lastDriftCheckTime,omitempty"`
+}
+
+// SolrCloudOperationType is the kind of an operation in the history of a SolrCloud
+// +kubebuilder:validation:Enum=Restart;Scale;Backup;Restore
+type SolrCloudOperationType string
+
+const (
+	// SolrCloudRestartOperation is a restart of the Solr pods, caused by a change to their pod template
+	SolrCloudRestartOperation SolrCloudOperationType = "Restart"
+
+	// SolrCloudScaleOperation is a change to the number of Solr pods
+	SolrCloudScaleOperation SolrCloudOperationType = "Scale"
+
+	// SolrCloudBackupOperation is a SolrBackup of the SolrCloud that finished
+	SolrCloudBackupOperation SolrCloudOperationType = "Backup"
+
+	// SolrCloudRestoreOperation is a SolrRestore into the SolrCloud that finished
+	SolrCloudRestoreOperation SolrCloudOperationType = "Restore"
+)
+
+const (
+	// SolrCloudOperationStarted is the outcome of restarts and scaling, which are carried out by the update strategy once recorded
+	SolrCloudOperationStarted = "Started"
+
+	// SolrCloudOperationSucceeded is the outcome of backups and restores that succeeded
+	SolrCloudOperationSucceeded = "Succeeded"
+
+	// SolrCloudOperationFailed is the outcome of backups and restores that failed
+	SolrCloudOperationFailed = "Failed"
+)
+
+// SolrCloudOperation is a significant operation on a SolrCloud, as recorded in its history
+type SolrCloudOperation struct {
+	// The kind of operation
+	Type SolrCloudOperationType `json:"type"`
+
+	// When the operation began, for restarts and scaling, or finished, for backups and restores
+	Time metav1.Time `json:"time"`
+
+	// The outcome of the operation, either "Started", "Succeeded" or "Failed".
+	// Restarts and scaling are always "Started", since they are carried out by the update strategy of the SolrCloud.
+	Outcome string `json:"outcome"`
+
+	// A description of the operation, such as the SolrBackup that was taken or the change in the number of Solr pods
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Why the Solr pods were restarted, only provided for restarts.
+	// One or more of: SolrXmlChanged, LogXmlChanged, ConfigFileChanged, TLSRotated, CredentialsRotated, ZookeeperConnectionChanged,
+	// ImageChanged, ScheduledRestart, UserRequested and PodSpecChanged.
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// SharedZookeeperChRoot is the chroot used by another SolrCloud in the same Zookeeper ensemble
+type SharedZookeeperChRoot struct {
+	// The namespace and name of the SolrCloud, in the form "namespace/name"
+	SolrCloud string `json:"solrCloud"`
+
+	// The chroot used by the SolrCloud
+	ChRoot string `json:"chroot"`
+}
+
+// SolrCloudResourceNames contains the names of the Kubernetes resources used by a SolrCloud.
+// Resources that are not used by the SolrCloud are omitted.
+type SolrCloudResourceNames struct {
+	// The StatefulSet running the Solr pods
+	// +optional
+	StatefulSet string `json:"statefulSet,omitempty"`
+
+	// The ConfigMap containing the solr.xml used by the Solr pods, either generated or provided by the user
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// The Service that load balances across all Solr pods
+	// +optional
+	CommonService string `json:"commonService,omitempty"`
+
+	// The headless Service used to address individual Solr pods
+	// +optional
+	HeadlessService string `json:"headlessService,omitempty"`
+
+	// The Services used to address individual Solr pods, when a headless Service is not used
+	// +optional
+	NodeServices []string `json:"nodeServices,omitempty"`
+
+	// The Ingress exposing Solr outside of the Kubernetes cluster
+	// +optional
+	Ingress string `json:"ingress,omitempty"`
+
+	// The Secret containing the basic auth credentials that the Solr Operator uses to connect to Solr
+	// +optional
+	BasicAuthSecret string `json:"basicAuthSecret,omitempty"`
+
+	// The Secret containing the security.json used to bootstrap Solr security
+	// +optional
+	SecurityBootstrapSecret string `json:"securityBootstrapSecret,omitempty"`
+
+	// The ZookeeperCluster created for this SolrCloud
+	// +optional
+	ProvidedZookeeper string `json:"providedZookeeper,omitempty"`
+
+	// The Secret containing the information client applications need to connect to this SolrCloud
+	// +optional
+	ConnectionInfoSecret string `json:"connectionInfoSecret,omitempty"`
+
+	// The ConfigMap containing the inventory of collections, shards and replicas in this SolrCloud
+	// +optional
+	InventoryConfigMap string `json:"inventoryConfigMap,omitempty"`
+
+	// The ConfigMap containing the diagnostics that were last collected from the Solr pods
+	// +optional
+	DiagnosticsConfigMap string `json:"diagnosticsConfigMap,omitempty"`
+}
+
+// SolrDiagnosticsStatus describes the diagnostics that were collected from the Solr pods
+type SolrDiagnosticsStatus struct {
+	// The value of the "solr.apache.org/collectDiagnostics" annotation that the diagnostics were collected for
+	RequestId string `json:"requestId"`
+
+	// The time that the diagnostics were collected
+	CollectionTime metav1.Time `json:"collectionTimestamp"`
+
+	// The pods that diagnostics were collected from
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+
+	// The diagnostics that could not be collected, and why
+	// +optional
+	Errors []string `json:"errors,omitempty"`
 }
 
 // SolrNodeStatus is the status of a solrNode in the cloud, with readiness status
@@ -993,6 +2596,7 @@ type SolrNodeStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.readyReplicas,selectorpath=.status.podSelector
 //+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Summary of the state of the cloud"
 //+kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",description="Solr Version of the cloud"
 //+kubebuilder:printcolumn:name="TargetVersion",type="string",JSONPath=".status.targetVersion",description="Target Solr Version of the cloud"
 //+kubebuilder:printcolumn:name="DesiredNodes",type="integer",JSONPath=".spec.replicas",description="Number of solr nodes configured to run in the cloud"
@@ -1036,17 +2640,68 @@ func (sc *SolrCloud) BasicAuthSecretName() string {
 	}
 }
 
+// UsesBasicAuth returns whether Solr requires basic auth credentials, which the operator uses for its own requests to Solr
+func (sc *SolrCloud) UsesBasicAuth() bool {
+	return sc.Spec.SolrSecurity != nil && sc.Spec.SolrSecurity.AuthenticationType != Kerberos
+}
+
+// ZkSetupContainerImage returns the image of the init container that sets up Zookeeper for Solr.
+// Fields that the zkSetupImage does not provide are taken from the solrImage when the container is generated, rather than defaulted in the spec,
+// so that the zkSetupImage follows the Solr version when the solrImage is upgraded.
+func (sc *SolrCloud) ZkSetupContainerImage() ContainerImage {
+	image := *sc.Spec.SolrImage
+	if sc.Spec.ZkSetupImage != nil {
+		image = *sc.Spec.ZkSetupImage
+		image.withDefaults(sc.Spec.SolrImage.Repository, sc.Spec.SolrImage.Tag, sc.Spec.SolrImage.PullPolicy)
+	}
+	return image
+}
+
+// UsesKerberos returns whether Solr authenticates requests through Kerberos
+func (sc *SolrCloud) UsesKerberos() bool {
+	return sc.Spec.SolrSecurity != nil && sc.Spec.SolrSecurity.AuthenticationType == Kerberos
+}
+
+// OperatorUsername returns the name of the user that the operator uses for API requests to Solr, when bootstrapping security
+func (sc *SolrCloud) OperatorUsername() string {
+	if sc.Spec.SolrSecurity != nil && sc.Spec.SolrSecurity.OperatorUsername != "" {
+		return sc.Spec.SolrSecurity.OperatorUsername
+	}
+	return DefaultBasicAuthUsername
+}
+
 func (sc *SolrCloud) SecurityBootstrapSecretName() string {
 	return fmt.Sprintf("%s-solrcloud-security-bootstrap", sc.Name)
 }
 
+// ConnectionInfoSecretName returns the name of the Secret containing the connection information for client applications
+func (sc *SolrCloud) ConnectionInfoSecretName() string {
+	if sc.Spec.ConnectionInfo != nil && sc.Spec.ConnectionInfo.SecretName != "" {
+		return sc.Spec.ConnectionInfo.SecretName
+	}
+	return fmt.Sprintf("%s-solrcloud-connection-info", sc.GetName())
+}
+
+// InventoryConfigMapName returns the name of the ConfigMap containing the inventory of collections, shards and replicas
+func (sc *SolrCloud) InventoryConfigMapName() string {
+	return fmt.Sprintf("%s-solrcloud-inventory", sc.GetName())
+}
+
+// DiagnosticsConfigMapName returns the name of the ConfigMap containing the diagnostics collected from the Solr pods
+func (sc *SolrCloud) DiagnosticsConfigMapName() string {
+	return fmt.Sprintf("%s-solrcloud-diagnostics", sc.GetName())
+}
+
 // ConfigMapName returns the name of the cloud config-map
 func (sc *SolrCloud) ConfigMapName() string {
 	return fmt.Sprintf("%s-solrcloud-configmap", sc.GetName())
 }
 
-// StatefulSetName returns the name of the statefulset for the cloud
+// StatefulSetName returns the name of the statefulset for the cloud, which is also the prefix of the names of its pods
 func (sc *SolrCloud) StatefulSetName() string {
+	if sc.Spec.CustomSolrKubeOptions.StatefulSetOptions != nil && sc.Spec.CustomSolrKubeOptions.StatefulSetOptions.PodNamePrefix != "" {
+		return sc.Spec.CustomSolrKubeOptions.StatefulSetOptions.PodNamePrefix
+	}
 	return fmt.Sprintf("%s-solrcloud", sc.GetName())
 }
 
@@ -1084,6 +2739,42 @@ func (sc *SolrCloud) ProvidedZookeeperAddress() string {
 func (sc *SolrCloud) ZkConnectionString() string {
 	return sc.Status.ZkConnectionString()
 }
+
+// MinReadyNodesForReady returns the number of Solr nodes that must be ready for the SolrCloud to be formed, or 0 if there is no such minimum
+func (sc *SolrCloud) MinReadyNodesForReady() int32 {
+	if sc.Spec.Availability == nil || sc.Spec.Availability.MinReadyNodesForReady == nil || sc.Spec.Replicas == nil {
+		return 0
+	}
+	desiredReplicas := int(*sc.Spec.Replicas)
+	minReady, err := intstr.GetScaledValueFromIntOrPercent(intstr.ValueOrDefault(sc.Spec.Availability.MinReadyNodesForReady, intstr.FromInt(0)), desiredReplicas, true)
+	if err != nil || minReady < 0 {
+		return 0
+	}
+	if minReady > desiredReplicas {
+		minReady = desiredReplicas
+	}
+	return int32(minReady)
+}
+
+// CalculatePhase summarizes the status of a SolrCloud that is meant to be running the given number of Solr nodes
+func (scs SolrCloudStatus) CalculatePhase(desiredReplicas int32) SolrCloudPhase {
+	switch {
+	case scs.ReadyReplicas >= desiredReplicas && scs.UpToDateNodes >= desiredReplicas && scs.TargetVersion == "":
+		return SolrCloudReady
+	case scs.ReadyReplicas == 0:
+		return SolrCloudPending
+	case scs.UpToDateNodes < scs.Replicas || scs.TargetVersion != "":
+		return SolrCloudUpdating
+	default:
+		return SolrCloudDegraded
+	}
+}
+
+// IsClusterFormed returns whether enough Solr nodes have been ready for the SolrCloud to be formed, as last reported in the ClusterFormed condition
+func (scs SolrCloudStatus) IsClusterFormed() bool {
+	return meta.IsStatusConditionTrue(scs.Conditions, SolrCloudClusterFormed)
+}
+
 func (scs SolrCloudStatus) ZkConnectionString() string {
 	return scs.ZookeeperConnectionInfo.ZkConnectionString()
 }
@@ -1112,6 +2803,29 @@ func (sc *SolrCloud) UsesIndividualNodeServices() bool {
 	return sc.Spec.SolrAddressability.External.UsesIndividualNodeServices()
 }
 
+// ManagesIngress returns whether the Solr Operator manages the Ingress of the SolrCloud, if it uses one
+func (sc *SolrCloud) ManagesIngress() bool {
+	opts := sc.Spec.ManagedResources
+	return opts == nil || opts.ManageIngress == nil || *opts.ManageIngress
+}
+
+// ManagesConfigMap returns whether the Solr Operator manages the ConfigMap containing the solr.xml of the SolrCloud
+func (sc *SolrCloud) ManagesConfigMap() bool {
+	opts := sc.Spec.ManagedResources
+	return opts == nil || opts.ManageConfigMap == nil || *opts.ManageConfigMap
+}
+
+// ManagesServices returns whether the Solr Operator manages the common, headless and node Services of the SolrCloud
+func (sc *SolrCloud) ManagesServices() bool {
+	opts := sc.Spec.ManagedResources
+	return opts == nil || opts.ManageServices == nil || *opts.ManageServices
+}
+
+// IsManaged returns whether the Solr Operator manages the resources that make the SolrCloud externally addressable.
+func (extOpts *ExternalAddressability) IsManaged() bool {
+	return extOpts != nil && !extOpts.Unmanaged
+}
+
 func (extOpts *ExternalAddressability) UsesIndividualNodeServices() bool {
 	// LoadBalancer and Ingress will not work with headless services if each pod needs to be exposed externally.
 	return extOpts != nil && !extOpts.HideNodes && (extOpts.Method == Ingress || extOpts.Method == LoadBalancer)
@@ -1165,7 +2879,8 @@ func (sc *SolrCloud) NodePort() int {
 	port := sc.Spec.SolrAddressability.PodPort
 	external := sc.Spec.SolrAddressability.External
 	// The nodePort is different than the podPort ONLY if the nodes are exposed externally and a nodePortOverride has been set.
-	if external.UsesIndividualNodeServices() && external.NodePortOverride > 0 {
+	// When using the host network, Solr nodes are always addressed through the podPort on their Kubernetes node.
+	if !sc.UsesHostNetwork() && external.UsesIndividualNodeServices() && external.NodePortOverride > 0 {
 		port = sc.Spec.SolrAddressability.External.NodePortOverride
 	}
 	return port
@@ -1205,7 +2920,11 @@ func (sc *SolrCloud) InternalCommonUrl(withPort bool) (url string) {
 
 func (sc *SolrCloud) ExternalNodeUrl(nodeName string, domainName string, withPort bool) (url string) {
 	if sc.Spec.SolrAddressability.External.Method == Ingress {
-		url = fmt.Sprintf("%s.%s", sc.NodeIngressPrefix(nodeName), domainName)
+		var err error
+		if url, err = sc.TemplatedNodeHost(nodeName, domainName); err != nil || url == "" {
+			// Invalid templates are reported by the SolrCloud controller, so fall back to the default naming
+			url = fmt.Sprintf("%s.%s", sc.NodeIngressPrefix(nodeName), domainName)
+		}
 	} else if sc.Spec.SolrAddressability.External.Method == ExternalDNS {
 		url = fmt.Sprintf("%s.%s", nodeName, sc.ExternalDnsDomain(domainName))
 	}
@@ -1214,10 +2933,50 @@ func (sc *SolrCloud) ExternalNodeUrl(nodeName string, domainName string, withPor
 	if withPort && sc.Spec.SolrAddressability.External.Method != Ingress {
 		// Ingress does not require a port, since the port is whatever the ingress is listening on (80 and 443)
 		url += sc.NodePortSuffix(true)
+	} else if withPort {
+		url += sc.ingressPortSuffix()
 	}
 	return url
 }
 
+// TemplatedNodeHost returns the external hostname for the given Solr Node generated by the nodeNameTemplate.
+// An empty string is returned if no template is used.
+func (sc *SolrCloud) TemplatedNodeHost(nodeName string, domainName string) (string, error) {
+	nodeNameTemplate := sc.Spec.SolrAddressability.External.NodeNameTemplate
+	if nodeNameTemplate == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("nodeNameTemplate").Option("missingkey=error").Parse(nodeNameTemplate)
+	if err != nil {
+		return "", err
+	}
+	var host bytes.Buffer
+	err = tmpl.Execute(&host, NodeNameTemplateData{
+		PodName:   nodeName,
+		Namespace: sc.Namespace,
+		CloudName: sc.Name,
+		Domain:    domainName,
+	})
+	return host.String(), err
+}
+
+// TemplatedAdvertisedHost returns the host for the given Solr Node generated by the advertisedHostTemplate.
+func (sc *SolrCloud) TemplatedAdvertisedHost(nodeName string, podIP string) (string, error) {
+	tmpl, err := template.New("advertisedHostTemplate").Option("missingkey=error").Parse(sc.Spec.SolrAddressability.AdvertisedHostTemplate)
+	if err != nil {
+		return "", err
+	}
+	var host bytes.Buffer
+	err = tmpl.Execute(&host, AdvertisedHostTemplateData{
+		PodName:             nodeName,
+		PodIP:               podIP,
+		Namespace:           sc.Namespace,
+		CloudName:           sc.Name,
+		HeadlessServiceName: sc.HeadlessServiceName(),
+	})
+	return host.String(), err
+}
+
 func (sc *SolrCloud) ExternalCommonUrl(domainName string, withPort bool) (url string) {
 	if sc.Spec.SolrAddressability.External.Method == Ingress {
 		url = fmt.Sprintf("%s.%s", sc.CommonExternalPrefix(), domainName)
@@ -1229,10 +2988,22 @@ func (sc *SolrCloud) ExternalCommonUrl(domainName string, withPort bool) (url st
 	if withPort && sc.Spec.SolrAddressability.External.Method != Ingress {
 		// Ingress does not require a port, since the port is whatever the ingress is listening on (80 and 443)
 		url += sc.CommonPortSuffix(true)
+	} else if withPort {
+		url += sc.ingressPortSuffix()
 	}
 	return url
 }
 
+// ingressPortSuffix returns the port suffix of the external addresses served by the Ingress.
+// The Ingress is reached through the default port of its scheme, unless the Solr Nodes advertise the port that it is reached through.
+func (sc *SolrCloud) ingressPortSuffix() string {
+	external := sc.Spec.SolrAddressability.External
+	if external.UseExternalAddress && external.AdvertisedPort > 0 {
+		return sc.PortToSuffix(external.AdvertisedPort, true)
+	}
+	return ""
+}
+
 func (sc *SolrCloud) UrlScheme(external bool) string {
 	urlScheme := "http"
 	if sc.Spec.SolrTLS != nil {
@@ -1243,8 +3014,40 @@ func (sc *SolrCloud) UrlScheme(external bool) string {
 	return urlScheme
 }
 
+// AdvertisedNodePort returns the port that the Solr Nodes advertise themselves with in live_nodes.
+func (sc *SolrCloud) AdvertisedNodePort() int {
+	external := sc.Spec.SolrAddressability.External
+	if external != nil && external.UseExternalAddress && external.AdvertisedPort > 0 {
+		return external.AdvertisedPort
+	}
+	return sc.NodePort()
+}
+
+// AdvertisedUrlScheme returns the URL scheme that the Solr Nodes use to address each other.
+func (sc *SolrCloud) AdvertisedUrlScheme() string {
+	external := sc.Spec.SolrAddressability.External
+	if external != nil && external.UseExternalAddress && external.AdvertisedScheme != "" {
+		return external.AdvertisedScheme
+	}
+	return sc.UrlScheme(false)
+}
+
+// AdvertisedNodeHost returns the host that the given Solr Node advertises itself with.
+// When using the advertisedHostTemplate, the PodIP is given as the "$(POD_IP)" environment variable of the Solr pod.
 func (sc *SolrCloud) AdvertisedNodeHost(nodeName string) string {
+	return sc.AdvertisedPodHost(nodeName, "$(POD_IP)")
+}
+
+// AdvertisedPodHost returns the host that the Solr Node in the given pod advertises itself with, using the IP of the pod if necessary.
+func (sc *SolrCloud) AdvertisedPodHost(nodeName string, podIP string) string {
 	external := sc.Spec.SolrAddressability.External
+	if sc.UsesAdvertisedHostTemplate() {
+		host, err := sc.TemplatedAdvertisedHost(nodeName, podIP)
+		if err == nil {
+			return host
+		}
+		// Invalid templates are reported by the SolrCloud controller, so fall back to the default host
+	}
 	if external != nil && external.UseExternalAddress {
 		return sc.ExternalNodeUrl(nodeName, sc.Spec.SolrAddressability.External.DomainName, false)
 	} else {
@@ -1252,10 +3055,81 @@ func (sc *SolrCloud) AdvertisedNodeHost(nodeName string) string {
 	}
 }
 
+// UsesAdvertisedHostTemplate returns whether the host that each Solr Node advertises itself with is overridden by the advertisedHostTemplate.
+func (sc *SolrCloud) UsesAdvertisedHostTemplate() bool {
+	return sc.Spec.SolrAddressability.AdvertisedHostTemplate != ""
+}
+
+// UsesHostNetwork returns whether the Solr pods run in the network of their Kubernetes nodes, and advertise the node's address.
+func (sc *SolrCloud) UsesHostNetwork() bool {
+	return sc.Spec.SolrAddressability.HostNetwork != nil
+}
+
+// CollectionsReadOnly returns whether every collection of the SolrCloud should be in read-only mode, which is the case for standby SolrClouds
+func (sc *SolrCloud) CollectionsReadOnly() bool {
+	return sc.Spec.ReadOnly || sc.Spec.StandbyOf != nil
+}
+
 func (sc *SolrCloud) UsesPersistentStorage() bool {
 	return sc.Spec.StorageOptions.PersistentStorage != nil
 }
 
+// PropagatedLabels returns the labels of the SolrCloud that should be copied to the resources created for it, per the propagateLabels policy
+func (sc *SolrCloud) PropagatedLabels() map[string]string {
+	policy := sc.Spec.CustomSolrKubeOptions.PropagateLabels
+	if policy == nil {
+		return sc.GetLabels()
+	}
+	return propagatedMetadata(sc.GetLabels(), policy)
+}
+
+// PropagatedAnnotations returns the annotations of the SolrCloud that should be copied to the resources created for it, per the propagateAnnotations policy
+func (sc *SolrCloud) PropagatedAnnotations() map[string]string {
+	policy := sc.Spec.CustomSolrKubeOptions.PropagateAnnotations
+	if policy == nil {
+		return nil
+	}
+	return propagatedMetadata(sc.GetAnnotations(), policy)
+}
+
+// PropagatedPVCLabels returns the labels of the SolrCloud that should be copied to its data PVCs, which requires opting in through the propagateLabels policy
+func (sc *SolrCloud) PropagatedPVCLabels() map[string]string {
+	policy := sc.Spec.CustomSolrKubeOptions.PropagateLabels
+	if policy == nil || !policy.PersistentVolumeClaims {
+		return nil
+	}
+	return propagatedMetadata(sc.GetLabels(), policy)
+}
+
+// PropagatedPVCAnnotations returns the annotations of the SolrCloud that should be copied to its data PVCs, which requires opting in through the propagateAnnotations policy
+func (sc *SolrCloud) PropagatedPVCAnnotations() map[string]string {
+	policy := sc.Spec.CustomSolrKubeOptions.PropagateAnnotations
+	if policy == nil || !policy.PersistentVolumeClaims {
+		return nil
+	}
+	return propagatedMetadata(sc.GetAnnotations(), policy)
+}
+
+// PropagatesPVCMetadata returns whether labels or annotations of the SolrCloud are propagated to its data PVCs
+func (sc *SolrCloud) PropagatesPVCMetadata() bool {
+	opts := sc.Spec.CustomSolrKubeOptions
+	return (opts.PropagateLabels != nil && opts.PropagateLabels.PersistentVolumeClaims) ||
+		(opts.PropagateAnnotations != nil && opts.PropagateAnnotations.PersistentVolumeClaims)
+}
+
+func propagatedMetadata(metadata map[string]string, policy *MetadataPropagationPolicy) map[string]string {
+	var propagated map[string]string
+	for key, value := range metadata {
+		if policy.Propagates(key) {
+			if propagated == nil {
+				propagated = map[string]string{}
+			}
+			propagated[key] = value
+		}
+	}
+	return propagated
+}
+
 func (sc *SolrCloud) SharedLabels() map[string]string {
 	return sc.SharedLabelsWith(map[string]string{})
 }
@@ -1295,6 +3169,16 @@ const (
 	Need ClientAuthType = "Need"
 )
 
+// +kubebuilder:validation:Enum=PKCS12;JKS;BCFKS
+type KeyStoreType string
+
+const (
+	PKCS12KeyStore KeyStoreType = "PKCS12"
+	JKSKeyStore    KeyStoreType = "JKS"
+	// The keystore type of the Bouncy Castle FIPS provider, which is approved for use in FIPS environments
+	BCFKSKeyStore KeyStoreType = "BCFKS"
+)
+
 type MountedTLSDirectory struct {
 	// The path on the main Solr container where the TLS files are mounted by some external agent or CSI Driver
 	Path string `json:"path"`
@@ -1318,6 +3202,36 @@ type MountedTLSDirectory struct {
 	TruststorePasswordFile string `json:"truststorePasswordFile,omitempty"`
 }
 
+// Options for issuing a certificate to each Solr pod, through the cert-manager CSI driver
+type PerPodCertificateOptions struct {
+	// The cert-manager Issuer, or ClusterIssuer, that signs the certificate of each Solr pod
+	IssuerRef CertificateIssuerReference `json:"issuerRef"`
+
+	// The requested lifetime of the certificates, in the Go duration format used by cert-manager, e.g. 2160h; defaults to 720h, the default of the cert-manager CSI driver
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// How long before the certificates expire that the cert-manager CSI driver renews them, in the Go duration format; defaults to a third of the duration.
+	// Unless a `restartSchedule` is given in the `updateStrategy`, the Solr pods are restarted every `duration` minus `renewBefore`, so that they use their renewed certificates before the old ones expire.
+	// +optional
+	RenewBefore string `json:"renewBefore,omitempty"`
+}
+
+type CertificateIssuerReference struct {
+	// The name of the Issuer, or ClusterIssuer
+	Name string `json:"name"`
+
+	// The kind of the issuer, either Issuer or ClusterIssuer
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// The API group of the issuer, which is only different for external issuers
+	// +kubebuilder:default=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
 type SolrTLSOptions struct {
 	// TLS Secret containing a pkcs12 keystore; required for Solr pods unless mountedTLSDir is used
 	// +optional
@@ -1336,6 +3250,13 @@ type SolrTLSOptions struct {
 	// +optional
 	TrustStorePasswordSecret *corev1.SecretKeySelector `json:"trustStorePasswordSecret,omitempty"`
 
+	// ConfigMap containing a bundle of PEM-encoded CA certificates to trust, such as a bundle distributed by trust-manager.
+	// The bundle is converted into a pkcs12 truststore by an initContainer whenever a pod starts, using the trustStorePasswordSecret,
+	// or the keyStorePasswordSecret if not provided, as the truststore password.
+	// This option cannot be used with trustStoreSecret or mountedTLSDir.
+	// +optional
+	TrustBundleConfigMap *corev1.ConfigMapKeySelector `json:"trustBundleConfigMap,omitempty"`
+
 	// Determines the client authentication method, either None, Want, or Need;
 	// this affects K8s ability to call liveness / readiness probes so use cautiously.
 	// Only applies for server certificates, has no effect on client certificates
@@ -1361,20 +3282,57 @@ type SolrTLSOptions struct {
 	// This option is typically used with `spec.updateStrategy.restartSchedule` to restart Solr pods before the mounted TLS cert expires.
 	// +optional
 	MountedTLSDir *MountedTLSDirectory `json:"mountedTLSDir,omitempty"`
+
+	// Issue a certificate to each Solr pod, through the cert-manager CSI driver, that contains the hostnames of that pod, and of the common service, but not those of any other pod.
+	// This allows for strict hostname verification, e.g. with `checkPeerName` enabled and `clientAuth` set to `Need`, since each pod also uses its certificate as its client certificate.
+	// Only supported for `spec.solrTLS`, and cannot be combined with `pkcs12Secret` or `mountedTLSDir`. The `keyStorePasswordSecret` is required to protect the generated keystores.
+	// +optional
+	PerPodCertificates *PerPodCertificateOptions `json:"perPodCertificates,omitempty"`
+
+	// The type of the keystore and truststore, either PKCS12, JKS or BCFKS; defaults to PKCS12.
+	// Keystores and truststores that are provided, through a Secret or the mountedTLSDir, must already be of this type.
+	// Those generated by initContainers, from a TLS cert, a per-pod certificate or the trustBundleConfigMap, are converted to this type with the keytool of the Solr image,
+	// so the BCFKS type requires the Bouncy Castle FIPS provider to be registered in the JVM of that image.
+	// +optional
+	KeyStoreType KeyStoreType `json:"keyStoreType,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=Basic
+// StoreType returns the type of the keystore and truststore, which is PKCS12 unless another type is given
+func (opts *SolrTLSOptions) StoreType() KeyStoreType {
+	if opts.KeyStoreType == "" {
+		return PKCS12KeyStore
+	}
+	return opts.KeyStoreType
+}
+
+// +kubebuilder:validation:Enum=Basic;Kerberos
 type AuthenticationType string
 
 const (
-	Basic AuthenticationType = "Basic"
+	Basic    AuthenticationType = "Basic"
+	Kerberos AuthenticationType = "Kerberos"
+)
+
+type SecurityJsonMode string
+
+const (
+	// BootstrapSecurityJson creates a security.json in Zookeeper, only if there is none
+	BootstrapSecurityJson SecurityJsonMode = "Bootstrap"
+
+	// ManagedSecurityJson keeps the security.json in Zookeeper in sync with a user-provided Secret
+	ManagedSecurityJson SecurityJsonMode = "Managed"
 )
 
 type SolrSecurityOptions struct {
-	// Indicates the authentication plugin type that is being used by Solr; for now only "Basic" is supported by the
-	// Solr operator but support for other authentication plugins may be added in the future.
+	// Indicates the authentication plugin type that is being used by Solr, either "Basic" or "Kerberos".
+	// The basic auth options below only apply to "Basic" authentication, and the kerberos options only to "Kerberos" authentication.
 	AuthenticationType AuthenticationType `json:"authenticationType,omitempty"`
 
+	// Options for Kerberos authentication, which are required if the authenticationType is "Kerberos".
+	// The Solr pods are configured to authenticate requests, and each other, through SPNEGO, and the probes authenticate the same way.
+	// +optional
+	Kerberos *SolrKerberosOptions `json:"kerberos,omitempty"`
+
 	// Secret (kubernetes.io/basic-auth) containing credentials the operator should use for API requests to secure Solr pods.
 	// If you provide this secret, then the operator assumes you've also configured your own security.json file and
 	// uploaded it to Solr. If you change the password for this user using the Solr security API, then you *must* update
@@ -1390,9 +3348,71 @@ type SolrSecurityOptions struct {
 	// +optional
 	BasicAuthSecret string `json:"basicAuthSecret,omitempty"`
 
+	// Name of the user that the operator makes its own API requests to Solr as, when the operator bootstraps the security.json.
+	// This user is granted only the "k8s" role, which covers the minimal set of endpoints the operator needs, and is kept separate
+	// from the bootstrapped 'admin' and 'solr' users. Defaults to "k8s-oper". Ignored if a 'basicAuthSecret' is provided,
+	// since the username is taken from that secret.
+	// +kubebuilder:validation:Pattern:=`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`
+	// +kubebuilder:validation:MaxLength:=63
+	// +optional
+	OperatorUsername string `json:"operatorUsername,omitempty"`
+
+	// Name of a pre-existing Secret containing the passwords of the users that the operator bootstraps in the security.json,
+	// such as a Secret synced from HashiCorp Vault by the External Secrets Operator. The Secret must have a key for each user:
+	// "admin", "solr" and the operatorUsername ("k8s-oper" by default). These passwords are used instead of random ones.
+	// Like the rest of the bootstrapped security.json, the passwords are only read when the security.json is bootstrapped,
+	// so later changes to the Secret are not applied to Solr. Cannot be combined with a 'basicAuthSecret'.
+	// +optional
+	BootstrapCredentialsSecret string `json:"bootstrapCredentialsSecret,omitempty"`
+
 	// Flag to indicate if the configured HTTP endpoint(s) used for the probes require authentication; defaults
 	// to false. If you set to true, then probes will use a local command on the main container to hit the secured
 	// endpoints with credentials sourced from an env var instead of HTTP directly.
 	// +optional
 	ProbesRequireAuth bool `json:"probesRequireAuth,omitempty"`
+
+	// How the Solr Operator manages the security.json in Zookeeper.
+	// "Bootstrap", the default, only creates a security.json if there is none, as described for the basicAuthSecret.
+	// "Managed" continuously reconciles the security.json in Zookeeper with the securityJsonSecret,
+	// so that changes to users, roles and permissions are applied by updating the Secret, and changes made through Solr's Security APIs are reverted.
+	// +kubebuilder:validation:Enum=Bootstrap;Managed
+	// +optional
+	SecurityJsonMode SecurityJsonMode `json:"securityJsonMode,omitempty"`
+
+	// Secret key containing the security.json that is kept in Zookeeper, when the securityJsonMode is "Managed".
+	// +optional
+	SecurityJsonSecret *corev1.SecretKeySelector `json:"securityJsonSecret,omitempty"`
+
+	// Secret key containing a JAAS configuration file, that will be mounted into the Solr pods and used as the
+	// "java.security.auth.login.config" for Solr and the ZK setup init container.
+	// This is necessary for SASL or Kerberos authentication to Zookeeper, independent of the authentication type used by Solr itself.
+	// +optional
+	JaasConfigSecret *corev1.SecretKeySelector `json:"jaasConfigSecret,omitempty"`
+}
+
+// SolrKerberosOptions defines how the Solr pods authenticate requests through Kerberos
+type SolrKerberosOptions struct {
+	// Secret key containing the keytab for both the server and client principals of every Solr pod.
+	KeytabSecret corev1.SecretKeySelector `json:"keytabSecret"`
+
+	// ConfigMap key containing the krb5.conf that describes the Kerberos realm.
+	// If not provided, the krb5.conf must be available at the JVM's default location in the Solr image.
+	// +optional
+	Krb5Config *corev1.ConfigMapKeySelector `json:"krb5Config,omitempty"`
+
+	// The service principal that Solr authenticates SPNEGO requests with, such as "HTTP/_HOST@EXAMPLE.COM".
+	// "_HOST" is replaced with the host name of each Solr pod.
+	// +kubebuilder:validation:MinLength=1
+	ServerPrincipal string `json:"serverPrincipal"`
+
+	// The principal that Solr uses for requests to other Solr nodes, and that the probes use, such as "solr/_HOST@EXAMPLE.COM".
+	// "_HOST" is replaced with the host name of each Solr pod.
+	// +kubebuilder:validation:MinLength=1
+	ClientPrincipal string `json:"clientPrincipal"`
+
+	// The rules that map Kerberos principals to the user names used by Solr's authorization plugin.
+	// The rules are passed to Solr as a system property, so they cannot contain whitespace. Defaults to "DEFAULT".
+	// +kubebuilder:validation:Pattern:=`^\S+$`
+	// +optional
+	NameRules string `json:"nameRules,omitempty"`
 }
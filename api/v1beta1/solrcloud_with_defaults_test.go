@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"testing"
 )
 
@@ -103,3 +104,254 @@ func assertLegacyBackupRepo(t *testing.T, repository SolrBackupRepository, volum
 	assert.EqualValuesf(t, volume, repository.Managed.Volume, "Volume incorrectly copied over for legacy backup repo")
 	assert.Equal(t, dir, repository.Managed.Directory, "Directory incorrectly copied over for legacy backup repo")
 }
+
+func TestAdvertisedPortAndScheme(t *testing.T) {
+	solrCloud := &SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: SolrCloudSpec{
+			SolrAddressability: SolrAddressabilityOptions{
+				External: &ExternalAddressability{
+					Method:                      Ingress,
+					DomainName:                  "example.com",
+					UseExternalAddress:          true,
+					IngressTLSTerminationSecret: "ingress-tls",
+				},
+			},
+		},
+	}
+
+	solrCloudTest := solrCloud.DeepCopy()
+	solrCloudTest.WithDefaults()
+	assert.False(t, solrCloudTest.Spec.SolrAddressability.External.UseExternalAddress, "The external address cannot be advertised with ingress TLS termination, unless https is advertised")
+	assert.Equal(t, 80, solrCloudTest.AdvertisedNodePort(), "The nodePortOverride should be advertised by default")
+	assert.Equal(t, "http", solrCloudTest.AdvertisedUrlScheme(), "The listening scheme should be advertised by default")
+
+	solrCloudTest = solrCloud.DeepCopy()
+	solrCloudTest.Spec.SolrAddressability.External.AdvertisedPort = 443
+	solrCloudTest.Spec.SolrAddressability.External.AdvertisedScheme = "https"
+	solrCloudTest.WithDefaults()
+	assert.True(t, solrCloudTest.Spec.SolrAddressability.External.UseExternalAddress, "The external address should be advertised with ingress TLS termination when https is advertised")
+	assert.Equal(t, 443, solrCloudTest.AdvertisedNodePort(), "The advertisedPort was not used")
+	assert.Equal(t, 80, solrCloudTest.NodePort(), "The advertisedPort should not change the node service port")
+	assert.Equal(t, "https", solrCloudTest.AdvertisedUrlScheme(), "The advertisedScheme was not used")
+	assert.Equal(t, "http", solrCloudTest.UrlScheme(false), "The advertisedScheme should not change the scheme Solr listens with")
+}
+
+func TestAddressabilityPorts(t *testing.T) {
+	solrCloud := &SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: SolrCloudSpec{
+			SolrAddressability: SolrAddressabilityOptions{
+				External: &ExternalAddressability{
+					Method:                      Ingress,
+					DomainName:                  "example.com",
+					UseExternalAddress:          true,
+					IngressTLSTerminationSecret: "ingress-tls",
+					AdvertisedScheme:            "https",
+					AdvertisedPort:              8443,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, "default-foo-solrcloud-0.example.com:8443", solrCloud.ExternalNodeUrl("foo-solrcloud-0", "example.com", true), "The advertisedPort should be used for the external node address of an Ingress")
+	assert.Equal(t, "default-foo-solrcloud.example.com:8443", solrCloud.ExternalCommonUrl("example.com", true), "The advertisedPort should be used for the external common address of an Ingress")
+	assert.Equal(t, "default-foo-solrcloud.example.com", solrCloud.ExternalCommonUrl("example.com", false), "No port should be used for the Ingress host")
+}
+
+func TestNodeNameTemplate(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: SolrCloudSpec{
+			Replicas: &replicas,
+			SolrAddressability: SolrAddressabilityOptions{
+				External: &ExternalAddressability{
+					Method:             Ingress,
+					DomainName:         "example.com",
+					UseExternalAddress: true,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, "default-foo-solrcloud-0.example.com", solrCloud.ExternalNodeUrl("foo-solrcloud-0", "example.com", false), "Wrong default external node hostname")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{.PodName}}.search.{{.Domain}}"
+	assert.Equal(t, "foo-solrcloud-0.search.example.com", solrCloud.ExternalNodeUrl("foo-solrcloud-0", "example.com", false), "The nodeNameTemplate was not used for the external node hostname")
+	assert.Equal(t, "$(POD_HOSTNAME).search.example.com", solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)"), "The nodeNameTemplate was not used for the advertised node host")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{.PodName}.{{.Domain}}"
+	assert.Equal(t, "default-foo-solrcloud-0.example.com", solrCloud.ExternalNodeUrl("foo-solrcloud-0", "example.com", false), "An invalid nodeNameTemplate should fall back to the default external node hostname")
+}
+
+func TestAdvertisedHostTemplate(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: SolrCloudSpec{
+			Replicas: &replicas,
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, "$(POD_HOSTNAME).foo-solrcloud-headless.default", solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)"), "Wrong default advertised node host")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodIP}}"
+	assert.Equal(t, "$(POD_IP)", solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)"), "The advertisedHostTemplate was not used for the advertised node host")
+	assert.Equal(t, "10.1.2.3", solrCloud.AdvertisedPodHost("foo-solrcloud-0", "10.1.2.3"), "The IP of the pod was not used for the advertised pod host")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodName}}.{{.Namespace}}.nat.example.com"
+	assert.Equal(t, "foo-solrcloud-0.default.nat.example.com", solrCloud.AdvertisedPodHost("foo-solrcloud-0", "10.1.2.3"), "The advertisedHostTemplate was not used for the advertised pod host")
+}
+
+func TestCalculatePhase(t *testing.T) {
+	status := SolrCloudStatus{}
+	assert.Equal(t, SolrCloudPending, status.CalculatePhase(3), "A SolrCloud without ready nodes should be pending")
+
+	status = SolrCloudStatus{Replicas: 3, ReadyReplicas: 3, UpToDateNodes: 3}
+	assert.Equal(t, SolrCloudReady, status.CalculatePhase(3), "A SolrCloud with all nodes ready and up to date should be ready")
+
+	status = SolrCloudStatus{Replicas: 3, ReadyReplicas: 3, UpToDateNodes: 1}
+	assert.Equal(t, SolrCloudUpdating, status.CalculatePhase(3), "A SolrCloud with out of date nodes should be updating")
+
+	status = SolrCloudStatus{Replicas: 3, ReadyReplicas: 3, UpToDateNodes: 3, TargetVersion: "8.11"}
+	assert.Equal(t, SolrCloudUpdating, status.CalculatePhase(3), "A SolrCloud migrating between versions should be updating")
+
+	status = SolrCloudStatus{Replicas: 3, ReadyReplicas: 2, UpToDateNodes: 3}
+	assert.Equal(t, SolrCloudDegraded, status.CalculatePhase(3), "A SolrCloud with up to date nodes that are not ready should be degraded")
+
+	status = SolrCloudStatus{}
+	assert.Equal(t, SolrCloudReady, status.CalculatePhase(0), "A SolrCloud scaled down to 0 nodes should be ready")
+}
+
+func TestMinReadyNodesForReady(t *testing.T) {
+	replicas := int32(5)
+	solrCloud := &SolrCloud{Spec: SolrCloudSpec{Replicas: &replicas}}
+	assert.Equal(t, int32(0), solrCloud.MinReadyNodesForReady(), "There should be no minimum without availability options")
+
+	minReady := intstr.FromString("50%")
+	solrCloud.Spec.Availability = &SolrAvailabilityOptions{MinReadyNodesForReady: &minReady}
+	assert.Equal(t, int32(3), solrCloud.MinReadyNodesForReady(), "Percentages should be rounded up")
+
+	minReady = intstr.FromInt(7)
+	assert.Equal(t, int32(5), solrCloud.MinReadyNodesForReady(), "The minimum should be capped at the desired number of pods")
+
+	minReady = intstr.FromInt(-1)
+	assert.Equal(t, int32(0), solrCloud.MinReadyNodesForReady(), "A negative minimum should be ignored")
+}
+
+func TestManagedResources(t *testing.T) {
+	solrCloud := &SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	solrCloud.WithDefaults()
+	assert.Nil(t, solrCloud.Spec.ManagedResources, "The managedResources should not be defaulted when not provided")
+	assert.True(t, solrCloud.ManagesIngress(), "The Ingress should be managed by default")
+	assert.True(t, solrCloud.ManagesConfigMap(), "The ConfigMap should be managed by default")
+	assert.True(t, solrCloud.ManagesServices(), "The Services should be managed by default")
+
+	manageServices := false
+	solrCloud.Spec.ManagedResources = &SolrManagedResourcesOptions{ManageServices: &manageServices}
+	assert.True(t, solrCloud.WithDefaults(), "The missing managedResources toggles should be defaulted")
+	assert.True(t, *solrCloud.Spec.ManagedResources.ManageIngress, "Wrong default for manageIngress")
+	assert.True(t, *solrCloud.Spec.ManagedResources.ManageConfigMap, "Wrong default for manageConfigMap")
+	assert.True(t, solrCloud.ManagesIngress())
+	assert.True(t, solrCloud.ManagesConfigMap())
+	assert.False(t, solrCloud.ManagesServices(), "The Services should not be managed when manageServices is false")
+}
+
+func TestZookeeperEnsembleSharing(t *testing.T) {
+	zkA := &ZookeeperConnectionInfo{InternalConnectionString: "zk-1:2181,ZK-0:2181/solr", ChRoot: "/solr/a"}
+	zkB := &ZookeeperConnectionInfo{InternalConnectionString: "zk-0:2181, zk-1:2181", ChRoot: "/solr/b"}
+	assert.Equal(t, "zk-0:2181,zk-1:2181", zkA.EnsembleKey(), "The ensemble key should not depend on the order of hosts, or the chroot in the connection string")
+	assert.Equal(t, zkA.EnsembleKey(), zkB.EnsembleKey(), "Both connection strings point to the same ensemble")
+	assert.Empty(t, (&ZookeeperConnectionInfo{}).EnsembleKey(), "There is no ensemble without a connection string")
+
+	assert.False(t, ZookeeperChRootsOverlap(zkA.ChRoot, zkB.ChRoot), "Sibling chroots do not overlap")
+	assert.False(t, ZookeeperChRootsOverlap(zkA.ChRoot, "/solr/ab"), "Chroots that only share a name prefix do not overlap")
+	assert.True(t, ZookeeperChRootsOverlap(zkA.ChRoot, "/solr/a/"), "The same chroots overlap")
+	assert.True(t, ZookeeperChRootsOverlap(zkA.ChRoot, "/solr"), "A parent chroot overlaps")
+	assert.True(t, ZookeeperChRootsOverlap("/", zkB.ChRoot), "The root chroot overlaps with every chroot")
+
+	ref := &ZookeeperRef{ServiceRef: &ZookeeperServiceRef{Name: "zk-headless", ChRoot: "solr"}}
+	assert.True(t, ref.withDefaults(), "The zookeeperRef should have been defaulted")
+	assert.Nil(t, ref.ProvidedZookeeper, "No Zookeeper should be provided when a Service is referenced")
+	key, chRoot := ref.ExternalEnsemble("solr-ns")
+	assert.Equal(t, "service:solr-ns/zk-headless", key, "A referenced Service should default to the namespace of the SolrCloud")
+	assert.Equal(t, "/solr", chRoot, "The chroot of the referenced Service should be prefixed with a '/'")
+}
+
+func TestOperatorConfigDefaults(t *testing.T) {
+	config := &SolrOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultSolrOperatorConfigName, Namespace: "default"},
+		Spec: SolrOperatorConfigSpec{
+			DefaultImages: SolrOperatorConfigImages{
+				Solr:    &ContainerImage{Repository: "registry.example.com/solr", Tag: "8.11"},
+				BusyBox: &ContainerImage{Repository: "registry.example.com/busybox"},
+			},
+			DefaultSolrTLS: &SolrTLSOptions{MountedTLSDir: &MountedTLSDirectory{Path: "/mounted-tls"}},
+		},
+	}
+
+	solrCloud := &SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: SolrCloudSpec{
+			BusyBoxImage: &ContainerImage{Repository: "custom/busybox"},
+		},
+	}
+	assert.True(t, solrCloud.WithOperatorConfigDefaults(config), "The SolrOperatorConfig defaults should change the SolrCloud")
+	solrCloud.WithDefaults()
+	assert.Equal(t, "registry.example.com/solr", solrCloud.Spec.SolrImage.Repository, "The Solr image should be taken from the SolrOperatorConfig")
+	assert.Equal(t, "8.11", solrCloud.Spec.SolrImage.Tag, "The Solr image tag should be taken from the SolrOperatorConfig")
+	assert.Equal(t, "custom/busybox", solrCloud.Spec.BusyBoxImage.Repository, "The BusyBox image of the SolrCloud should not be overridden")
+	assert.Equal(t, DefaultBusyBoxImageVersion, solrCloud.Spec.BusyBoxImage.Tag, "The BusyBox image tag should use the operator default")
+	if assert.NotNil(t, solrCloud.Spec.SolrTLS, "The TLS options should be taken from the SolrOperatorConfig") {
+		assert.Equal(t, "/mounted-tls", solrCloud.Spec.SolrTLS.MountedTLSDir.Path, "Wrong TLS options taken from the SolrOperatorConfig")
+	}
+	assert.Equal(t, "8.11", config.Spec.DefaultImages.Solr.Tag, "The SolrOperatorConfig should not be modified by the SolrCloud defaults")
+
+	assert.False(t, solrCloud.WithOperatorConfigDefaults(config), "The SolrOperatorConfig defaults should only be applied once")
+	assert.False(t, solrCloud.WithOperatorConfigDefaults(nil), "A missing SolrOperatorConfig should not change the SolrCloud")
+}
+
+func TestOperatorConfigSelects(t *testing.T) {
+	var config *SolrOperatorConfig
+	selected, err := config.Selects(map[string]string{"team": "a"})
+	assert.NoError(t, err)
+	assert.True(t, selected, "A missing SolrOperatorConfig should select all resources")
+
+	config = &SolrOperatorConfig{}
+	selected, err = config.Selects(nil)
+	assert.NoError(t, err)
+	assert.True(t, selected, "A SolrOperatorConfig without a resourceSelector should select all resources")
+
+	config.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	selected, err = config.Selects(map[string]string{"team": "a", "other": "label"})
+	assert.NoError(t, err)
+	assert.True(t, selected, "Resources matching the resourceSelector should be selected")
+	selected, err = config.Selects(map[string]string{"team": "b"})
+	assert.NoError(t, err)
+	assert.False(t, selected, "Resources not matching the resourceSelector should not be selected")
+
+	config.Spec.ResourceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Bogus"}}}
+	_, err = config.Selects(map[string]string{"team": "a"})
+	assert.Error(t, err, "An invalid resourceSelector should return an error")
+}
+
+func TestZookeeperRefGetACLs(t *testing.T) {
+	allACL := &ZookeeperACL{SecretRef: "zk-acl", UsernameKey: "user", PasswordKey: "pass"}
+	readOnlyACL := &ZookeeperACL{SecretRef: "zk-acl-read", UsernameKey: "user", PasswordKey: "pass"}
+
+	ref := &ZookeeperRef{ConnectionInfo: &ZookeeperConnectionInfo{AllACL: allACL, ReadOnlyACL: readOnlyACL}}
+	foundAllACL, foundReadOnlyACL := ref.GetACLs()
+	assert.Equal(t, allACL, foundAllACL, "The ACL of the connectionInfo should be used")
+	assert.Equal(t, readOnlyACL, foundReadOnlyACL, "The read-only ACL of the connectionInfo should be used")
+
+	ref = &ZookeeperRef{ServiceRef: &ZookeeperServiceRef{Name: "zk-client", AllACL: allACL, ReadOnlyACL: readOnlyACL}}
+	foundAllACL, foundReadOnlyACL = ref.GetACLs()
+	assert.Equal(t, allACL, foundAllACL, "The ACL of the serviceRef should be used")
+	assert.Equal(t, readOnlyACL, foundReadOnlyACL, "The read-only ACL of the serviceRef should be used")
+
+	ref = &ZookeeperRef{ProvidedZookeeper: &ZookeeperSpec{AllACL: allACL}}
+	foundAllACL, foundReadOnlyACL = ref.GetACLs()
+	assert.Equal(t, allACL, foundAllACL, "The ACL of the provided zookeeper should be used")
+	assert.Nil(t, foundReadOnlyACL, "No read-only ACL was given for the provided zookeeper")
+}
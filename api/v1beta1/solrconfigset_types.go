@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SolrConfigSetSpec defines the desired state of SolrConfigSet
+type SolrConfigSetSpec struct {
+	// The name of the SolrCloud, in the same namespace, to upload the configset to
+	// +kubebuilder:validation:MinLength=1
+	SolrCloud string `json:"solrCloud"`
+
+	// The name of the configset in Zookeeper.
+	// Defaults to the name of the SolrConfigSet.
+	// +optional
+	ConfigSetName string `json:"configSetName,omitempty"`
+
+	// Where the files of the configset are read from
+	Source SolrConfigSetSource `json:"source"`
+}
+
+func (spec *SolrConfigSetSpec) withDefaults(configSetName string) (changed bool) {
+	if spec.ConfigSetName == "" {
+		changed = true
+		spec.ConfigSetName = configSetName
+	}
+
+	return changed
+}
+
+// SolrConfigSetSource defines the ConfigMap or Secret that contains the files of a configset.
+// Exactly one of configMap and secret must be provided.
+type SolrConfigSetSource struct {
+	// Name of a user provided ConfigMap, in the same namespace, that contains the files.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Name of a user provided Secret, in the same namespace, that contains the files.
+	// Use a Secret for configsets that contain sensitive settings, such as credentials of external systems.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// The files of the configset, mapped from the keys of the ConfigMap or Secret.
+	// Since keys cannot contain "/", this is needed for files in sub-directories, such as "lang/stopwords_en.txt".
+	// If not provided, every key is uploaded as a file in the root of the configset.
+	// +optional
+	Files []ConfigSetFile `json:"files,omitempty"`
+}
+
+// SolrConfigSetStatus defines the observed state of SolrConfigSet
+type SolrConfigSetStatus struct {
+	// The generation of the SolrConfigSet that was last uploaded
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Whether the configset in Zookeeper is up to date with its source
+	Synced bool `json:"synced"`
+
+	// The hash of the files that were last uploaded to the configset
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// When the configset was last uploaded
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// The collections that were reloaded after the configset was last uploaded
+	// +optional
+	ReloadedCollections []string `json:"reloadedCollections,omitempty"`
+
+	// Why the configset could not be synced, if it is not up to date
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:resource:shortName=solrconfigset
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="ConfigSet",type="string",JSONPath=".spec.configSetName",description="The name of the configset in Zookeeper"
+//+kubebuilder:printcolumn:name="Synced",type="boolean",JSONPath=".status.synced",description="Whether the configset is up to date"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrConfigSet is the Schema for the solrconfigsets API
+type SolrConfigSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrConfigSetSpec   `json:"spec,omitempty"`
+	Status SolrConfigSetStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (configSet *SolrConfigSet) WithDefaults() bool {
+	return configSet.Spec.withDefaults(configSet.Name)
+}
+
+//+kubebuilder:object:root=true
+
+// SolrConfigSetList contains a list of SolrConfigSet
+type SolrConfigSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrConfigSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrConfigSet{}, &SolrConfigSetList{})
+}
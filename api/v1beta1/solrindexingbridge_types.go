@@ -0,0 +1,206 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package v1beta1
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SolrIndexingBridgeTechnologyLabel = "solr-indexing-bridge"
+
+	DefaultIndexingBridgeBatchSize = 500
+)
+
+// SolrIndexingBridgeSpec defines the desired state of SolrIndexingBridge
+type SolrIndexingBridgeSpec struct {
+	// Reference of the Solr instance to index documents into
+	SolrReference SolrReference `json:"solrReference"`
+
+	// The Solr collection to index documents into
+	// +kubebuilder:validation:MinLength=1
+	Collection string `json:"collection"`
+
+	// The Kafka topics to consume documents from
+	Kafka KafkaSourceOptions `json:"kafka"`
+
+	// Image of the indexing bridge to run.
+	// The image must read its configuration from the environment variables described in the SolrIndexingBridge documentation.
+	// The repository is required, the tag defaults to "latest".
+	Image *ContainerImage `json:"image"`
+
+	// The number of bridge pods to run. Kafka balances the topic partitions across all pods in the consumer group.
+	// Defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// The maximum number of documents to send to Solr in a single update request
+	// Defaults to 500
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// Send the documents with a commitWithin (in ms), so that Solr commits them within the given time.
+	// If not provided, the bridge relies on the autoCommit settings of the collection.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CommitWithinMs *int32 `json:"commitWithinMs,omitempty"`
+
+	// Provide custom options for kubernetes objects created for the SolrIndexingBridge.
+	// +optional
+	CustomKubeOptions CustomIndexingBridgeKubeOptions `json:"customKubeOptions,omitempty"`
+}
+
+func (ibs *SolrIndexingBridgeSpec) withDefaults(name string, namespace string) (changed bool) {
+	changed = ibs.SolrReference.withDefaults(namespace) || changed
+
+	if ibs.Image != nil {
+		changed = ibs.Image.withDefaults("", "latest", DefaultPullPolicy) || changed
+	}
+
+	if ibs.Replicas == nil {
+		one := int32(1)
+		ibs.Replicas = &one
+		changed = true
+	}
+
+	if ibs.BatchSize == 0 {
+		ibs.BatchSize = DefaultIndexingBridgeBatchSize
+		changed = true
+	}
+
+	changed = ibs.Kafka.withDefaults(name) || changed
+
+	return changed
+}
+
+// KafkaSourceOptions defines the Kafka cluster and topics that an indexing bridge consumes documents from
+type KafkaSourceOptions struct {
+	// A comma-separated list of host:port pairs used to bootstrap the connection to the Kafka cluster
+	// +kubebuilder:validation:MinLength=1
+	BootstrapServers string `json:"bootstrapServers"`
+
+	// The Kafka topics to consume documents from
+	// +kubebuilder:validation:MinItems=1
+	Topics []string `json:"topics"`
+
+	// The Kafka consumer group used by the bridge pods. Offsets are committed for this group,
+	// so changing it will cause the bridge to start consuming from a different position.
+	// Defaults to "<name>-solr-indexing-bridge"
+	// +optional
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+
+	// A secret key containing additional Kafka consumer properties, in the Java properties format.
+	// This is where SASL and TLS settings for the Kafka connection should be provided.
+	// The properties are mounted as a file into the bridge pods.
+	// +optional
+	ConsumerPropertiesSecret *corev1.SecretKeySelector `json:"consumerPropertiesSecret,omitempty"`
+}
+
+func (kso *KafkaSourceOptions) withDefaults(name string) (changed bool) {
+	if kso.ConsumerGroup == "" {
+		kso.ConsumerGroup = fmt.Sprintf("%s-%s", name, SolrIndexingBridgeTechnologyLabel)
+		changed = true
+	}
+	return changed
+}
+
+type CustomIndexingBridgeKubeOptions struct {
+	// PodOptions defines the custom options for the solrIndexingBridge pods.
+	// +optional
+	PodOptions *PodOptions `json:"podOptions,omitempty"`
+
+	// DeploymentOptions defines the custom options for the solrIndexingBridge Deployment.
+	// +optional
+	DeploymentOptions *DeploymentOptions `json:"deploymentOptions,omitempty"`
+}
+
+// SolrIndexingBridgeStatus defines the observed state of SolrIndexingBridge
+type SolrIndexingBridgeStatus struct {
+	// Is the indexing bridge up and running
+	Ready bool `json:"ready"`
+
+	// The number of bridge pods that are ready
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// The name of the Deployment running the indexing bridge
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:resource:shortName=solrbridge
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Collection",type="string",JSONPath=".spec.collection",description="The collection that documents are indexed into"
+//+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Whether the indexing bridge is ready"
+//+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".spec.replicas",description="Number of bridge pods requested"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrIndexingBridge is the Schema for the solrindexingbridges API
+type SolrIndexingBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrIndexingBridgeSpec   `json:"spec,omitempty"`
+	Status SolrIndexingBridgeStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (sib *SolrIndexingBridge) WithDefaults() bool {
+	return sib.Spec.withDefaults(sib.Name, sib.Namespace)
+}
+
+func (sib *SolrIndexingBridge) SharedLabels() map[string]string {
+	return sib.SharedLabelsWith(map[string]string{})
+}
+
+func (sib *SolrIndexingBridge) SharedLabelsWith(labels map[string]string) map[string]string {
+	newLabels := map[string]string{}
+
+	if labels != nil {
+		for k, v := range labels {
+			newLabels[k] = v
+		}
+	}
+
+	newLabels[SolrIndexingBridgeTechnologyLabel] = sib.Name
+	return newLabels
+}
+
+// DeploymentName returns the name of the deployment running the indexing bridge
+func (sib *SolrIndexingBridge) DeploymentName() string {
+	return fmt.Sprintf("%s-%s", sib.GetName(), SolrIndexingBridgeTechnologyLabel)
+}
+
+//+kubebuilder:object:root=true
+
+// SolrIndexingBridgeList contains a list of SolrIndexingBridge
+type SolrIndexingBridgeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrIndexingBridge `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrIndexingBridge{}, &SolrIndexingBridgeList{})
+}
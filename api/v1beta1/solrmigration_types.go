@@ -0,0 +1,249 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SolrMigrationSpec defines the desired state of SolrMigration
+type SolrMigrationSpec struct {
+	// The name of the SolrCloud, in the same namespace, to migrate the collections into.
+	// +kubebuilder:validation:MinLength=1
+	SolrCloud string `json:"solrCloud"`
+
+	// The Solr cluster to migrate the collections out of, which is usually running in a different Kubernetes cluster.
+	Source SolrMigrationSource `json:"source"`
+
+	// The name of the backup repository, of the SolrCloud, that the collections are exported to and restored from.
+	// The repository must be a GCS repository, and the source repository must store its data in the same bucket.
+	// Defaults to the only repository of the SolrCloud, if it has one.
+	// +optional
+	RepositoryName string `json:"repositoryName,omitempty"`
+
+	// The collections to migrate.
+	// +kubebuilder:validation:MinItems=1
+	Collections []SolrMigrationCollection `json:"collections"`
+
+	// Delete the collections from the source Solr cluster, once they have been restored into the SolrCloud and their aliases have been created. A source collection is only deleted if the migrated collection has the same number of documents.
+	// +optional
+	DecommissionSource bool `json:"decommissionSource,omitempty"`
+}
+
+func (spec *SolrMigrationSpec) withDefaults() (changed bool) {
+	for i := range spec.Collections {
+		if spec.Collections[i].Target == "" {
+			changed = true
+			spec.Collections[i].Target = spec.Collections[i].Name
+		}
+	}
+
+	return changed
+}
+
+// SolrMigrationSource defines the Solr cluster that collections are migrated out of
+type SolrMigrationSource struct {
+	// The base URL of the source Solr cluster, as reachable from the Solr Operator, e.g. "https://solr.old-cluster.example.com".
+	// Requests are sent to the Collections API at "<url>/solr/admin/collections".
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// The name of a kubernetes.io/basic-auth Secret, in the same namespace, with the credentials for the source Solr cluster.
+	// +optional
+	BasicAuthSecret string `json:"basicAuthSecret,omitempty"`
+
+	// The name of the backup repository, configured in the solr.xml of the source Solr cluster, that the collections are exported to.
+	// It must store its data in the same GCS bucket as the repository of the SolrCloud.
+	// +kubebuilder:validation:MinLength=1
+	RepositoryName string `json:"repositoryName"`
+}
+
+// SolrMigrationCollection defines a collection to migrate
+type SolrMigrationCollection struct {
+	// The name of the collection in the source Solr cluster
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The name of the collection to create in the SolrCloud. The collection must not exist yet.
+	// Defaults to the name of the collection in the source Solr cluster.
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// The name of an alias to create, in the SolrCloud, for the target collection once it has been restored.
+	// Clients that use the alias can be pointed at the SolrCloud without knowing the name of the target collection.
+	// +optional
+	Alias string `json:"alias,omitempty"`
+}
+
+// SolrMigrationPhase is the step that a SolrMigration is at
+type SolrMigrationPhase string
+
+const (
+	// SolrMigrationExporting means that the collections are being backed up by the source Solr cluster
+	SolrMigrationExporting SolrMigrationPhase = "Exporting"
+
+	// SolrMigrationRestoring means that the exported collections are being restored into the SolrCloud
+	SolrMigrationRestoring SolrMigrationPhase = "Restoring"
+
+	// SolrMigrationCuttingOver means that the aliases of the migrated collections are being created in the SolrCloud
+	SolrMigrationCuttingOver SolrMigrationPhase = "CuttingOver"
+
+	// SolrMigrationDecommissioning means that the migrated collections are being deleted from the source Solr cluster
+	SolrMigrationDecommissioning SolrMigrationPhase = "Decommissioning"
+
+	// SolrMigrationComplete means that every step of the SolrMigration has succeeded
+	SolrMigrationComplete SolrMigrationPhase = "Complete"
+
+	// SolrMigrationFailed means that a step of the SolrMigration failed, and it was stopped
+	SolrMigrationFailed SolrMigrationPhase = "Failed"
+)
+
+// SolrMigrationStatus defines the observed state of SolrMigration
+type SolrMigrationStatus struct {
+	// The step that the migration is at
+	// +optional
+	Phase SolrMigrationPhase `json:"phase,omitempty"`
+
+	// The status of each collection's migration progress
+	// +optional
+	CollectionMigrationStatuses []CollectionMigrationStatus `json:"collectionMigrationStatuses,omitempty"`
+
+	// The name of the SolrRestore that restores the exported collections into the SolrCloud
+	// +optional
+	SolrRestore string `json:"solrRestore,omitempty"`
+
+	// Time that the migration started at
+	// +optional
+	StartTime *metav1.Time `json:"startTimestamp,omitempty"`
+
+	// Time that the migration finished at
+	// +optional
+	FinishTime *metav1.Time `json:"finishTimestamp,omitempty"`
+
+	// Whether the migration was successful
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+
+	// Whether the migration has finished
+	Finished bool `json:"finished,omitempty"`
+
+	// Conditions describe the latest observations of the SolrMigration.
+	// The "Complete" condition is True once every step of the migration has succeeded, and False, with the reason and message,
+	// while the migration is waiting, in progress, or has failed.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// SolrMigrationCompleteCondition is the condition type that reports whether every step of a SolrMigration has succeeded
+	SolrMigrationCompleteCondition = "Complete"
+)
+
+// CollectionMigrationStatus defines the progress of a Solr Collection's migration
+type CollectionMigrationStatus struct {
+	// The name of the collection in the source Solr cluster
+	Collection string `json:"collection"`
+
+	// The name of the collection in the SolrCloud
+	Target string `json:"target"`
+
+	// Whether the collection has been made read-only in the source Solr cluster, so that it does not change after it has been exported.
+	// It is made writable again if the migration fails before the cutover.
+	// +optional
+	SourceReadOnly bool `json:"sourceReadOnly,omitempty"`
+
+	// Whether the collection is being exported by the source Solr cluster
+	// +optional
+	ExportInProgress bool `json:"exportInProgress,omitempty"`
+
+	// The status of the asynchronous backup call to the source Solr cluster
+	// +optional
+	AsyncExportStatus string `json:"asyncExportStatus,omitempty"`
+
+	// Whether the collection has been exported to the backup repository
+	// +optional
+	Exported bool `json:"exported,omitempty"`
+
+	// Whether the collection has been restored into the SolrCloud
+	// +optional
+	Restored bool `json:"restored,omitempty"`
+
+	// Whether the alias of the collection has been created in the SolrCloud
+	// +optional
+	Aliased bool `json:"aliased,omitempty"`
+
+	// Whether the collection has been deleted from the source Solr cluster
+	// +optional
+	Decommissioned bool `json:"decommissioned,omitempty"`
+
+	// Why the collection could not be migrated, if it failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AsyncId returns the ID of the asynchronous Collections API request, in the source Solr cluster, that exports the collection
+func (sm *SolrMigration) AsyncId(collection string) string {
+	return fmt.Sprintf("%s-migrate-%s", sm.Name, collection)
+}
+
+// SolrRestoreName returns the name of the SolrRestore that restores the exported collections
+func (sm *SolrMigration) SolrRestoreName() string {
+	return fmt.Sprintf("%s-migration", sm.Name)
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:storageversion
+//+kubebuilder:categories=all
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.source.url",description="The Solr cluster that the collections are migrated out of"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The step that the migration is at"
+//+kubebuilder:printcolumn:name="Finished",type="boolean",JSONPath=".status.finished",description="Whether the migration has finished"
+//+kubebuilder:printcolumn:name="Successful",type="boolean",JSONPath=".status.successful",description="Whether the migration was successful"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrMigration is the Schema for the solrmigrations API
+type SolrMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrMigrationSpec   `json:"spec,omitempty"`
+	Status SolrMigrationStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (sm *SolrMigration) WithDefaults() bool {
+	return sm.Spec.withDefaults()
+}
+
+//+kubebuilder:object:root=true
+
+// SolrMigrationList contains a list of SolrMigration
+type SolrMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrMigration{}, &SolrMigrationList{})
+}
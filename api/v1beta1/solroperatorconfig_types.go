@@ -0,0 +1,119 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// DefaultSolrOperatorConfigName is the default name of the SolrOperatorConfig that the Solr Operator uses in each namespace.
+	// The name can be changed through the operator-config-name flag of the Solr Operator. SolrOperatorConfigs with any other name are ignored.
+	DefaultSolrOperatorConfigName = "default"
+)
+
+// SolrOperatorConfigSpec defines the configuration of the Solr Operator for the Solr resources in a single namespace
+type SolrOperatorConfigSpec struct {
+	// The images used by SolrClouds in this namespace that do not specify their own.
+	// +optional
+	DefaultImages SolrOperatorConfigImages `json:"defaultImages,omitempty"`
+
+	// The TLS options used by SolrClouds in this namespace that do not specify spec.solrTLS.
+	// +optional
+	DefaultSolrTLS *SolrTLSOptions `json:"defaultSolrTLS,omitempty"`
+
+	// Only the Solr resources in this namespace that match this label selector are reconciled by the Solr Operator.
+	// This allows multiple Solr Operators to manage separate Solr resources in the same namespace.
+	// If not provided, all Solr resources in the namespace are reconciled.
+	// +optional
+	ResourceSelector *metav1.LabelSelector `json:"resourceSelector,omitempty"`
+}
+
+// SolrOperatorConfigImages defines the default images for the SolrClouds in a namespace
+type SolrOperatorConfigImages struct {
+	// The image used to run Solr.
+	// +optional
+	Solr *ContainerImage `json:"solr,omitempty"`
+
+	// The image used for the utility init containers of Solr pods.
+	// +optional
+	BusyBox *ContainerImage `json:"busyBox,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:resource:shortName=solrconfig
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrOperatorConfig is the Schema for the solroperatorconfigs API
+type SolrOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SolrOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+// Selects returns whether the Solr resource with the given labels should be reconciled under this configuration.
+// A nil SolrOperatorConfig, or one without a resourceSelector, selects every resource.
+// An error is returned if the resourceSelector is invalid.
+func (config *SolrOperatorConfig) Selects(resourceLabels map[string]string) (bool, error) {
+	if config == nil || config.Spec.ResourceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(config.Spec.ResourceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(resourceLabels)), nil
+}
+
+// WithOperatorConfigDefaults sets the values of the SolrOperatorConfig for the namespace, when they are not defined in the spec.
+// This must be applied before WithDefaults, which sets the defaults of the Solr Operator for all remaining values.
+func (sc *SolrCloud) WithOperatorConfigDefaults(config *SolrOperatorConfig) (changed bool) {
+	if config == nil {
+		return false
+	}
+	if sc.Spec.SolrImage == nil && config.Spec.DefaultImages.Solr != nil {
+		sc.Spec.SolrImage = config.Spec.DefaultImages.Solr.DeepCopy()
+		changed = true
+	}
+	if sc.Spec.BusyBoxImage == nil && config.Spec.DefaultImages.BusyBox != nil {
+		sc.Spec.BusyBoxImage = config.Spec.DefaultImages.BusyBox.DeepCopy()
+		changed = true
+	}
+	if sc.Spec.SolrTLS == nil && config.Spec.DefaultSolrTLS != nil {
+		sc.Spec.SolrTLS = config.Spec.DefaultSolrTLS.DeepCopy()
+		changed = true
+	}
+	return changed
+}
+
+//+kubebuilder:object:root=true
+
+// SolrOperatorConfigList contains a list of SolrOperatorConfig
+type SolrOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrOperatorConfig{}, &SolrOperatorConfigList{})
+}
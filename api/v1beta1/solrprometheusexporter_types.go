@@ -190,6 +190,10 @@ type SolrPrometheusExporterStatus struct {
 
 	// Is the prometheus exporter up and running
 	Ready bool `json:"ready"`
+
+	// The name of the Deployment running the prometheus exporter
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
 }
 
 //+kubebuilder:object:root=true
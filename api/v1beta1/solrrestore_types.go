@@ -0,0 +1,241 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SolrRestoreSpec defines the desired state of SolrRestore
+type SolrRestoreSpec struct {
+	// The name of the SolrCloud, in the same namespace, to restore the collections into.
+	// The SolrCloud must define the backup repository that the backup is stored in.
+	// +kubebuilder:validation:MinLength=1
+	SolrCloud string `json:"solrCloud"`
+
+	// The name of a completed SolrBackup, in the same namespace, to restore.
+	// Either solrBackup, or repositoryName and backupName, must be provided.
+	//
+	// SolrBackups taken as VolumeSnapshots are restored by creating the data PersistentVolumeClaims of the SolrCloud from the snapshots,
+	// for the Solr Nodes whose PersistentVolumeClaims do not exist yet. The collections cannot be selected for these backups.
+	// +optional
+	SolrBackup string `json:"solrBackup,omitempty"`
+
+	// The name of the backup repository, of the SolrCloud, that contains a backup that is not managed by a SolrBackup.
+	// Defaults to the only repository of the SolrCloud, if it has one, when backupName is provided.
+	// +optional
+	RepositoryName string `json:"repositoryName,omitempty"`
+
+	// The definition of the backup repository that the backup was taken in, for backups taken by another SolrCloud,
+	// possibly in a different namespace or Kubernetes cluster, that may no longer exist.
+	// The SolrCloud must define a backup repository that stores its data in the same place, under any name:
+	// the same bucket and baseLocation for GCS repositories, or the same volume and directory for managed repositories.
+	// Cannot be combined with solrBackup or repositoryName.
+	// +optional
+	Repository *SolrBackupRepository `json:"repository,omitempty"`
+
+	// The name of the backup to restore, within the backup repository.
+	// For backups taken by a SolrBackup, this is the name of the SolrBackup.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// The ID of the backup point to restore, for incremental backups.
+	// Defaults to the latest backup point.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackupId *int32 `json:"backupId,omitempty"`
+
+	// The collections to restore.
+	// If empty, every collection that the SolrBackup successfully backed up is restored, under its original name.
+	// Must be provided when restoring a backup that is not managed by a SolrBackup.
+	// +optional
+	Collections []SolrRestoreCollection `json:"collections,omitempty"`
+
+	// Also restore the cluster state that was exported with the backup, through includeClusterState, once every collection has been restored.
+	// The properties of each restored collection are restored under its target name.
+	// Aliases whose collections were all restored are added, pointing to the target collections, unless an alias with the same name already exists.
+	// Cluster properties are added, unless they are already set.
+	// The result is reported in the "ClusterStateRestored" condition, and the restore is not successful unless the cluster state is restored.
+	// Only supported for backups in managed backup repositories.
+	// +optional
+	RestoreClusterState bool `json:"restoreClusterState,omitempty"`
+
+	// Also restore the security.json that was exported with the backup, through includeSecurityJson, replacing the security.json of the SolrCloud.
+	// Requires restoreClusterState.
+	// +optional
+	RestoreSecurityJson bool `json:"restoreSecurityJson,omitempty"`
+}
+
+func (spec *SolrRestoreSpec) withDefaults() (changed bool) {
+	for i := range spec.Collections {
+		if spec.Collections[i].Target == "" {
+			changed = true
+			spec.Collections[i].Target = spec.Collections[i].Name
+		}
+	}
+
+	return changed
+}
+
+// SolrRestoreCollection defines a collection to restore from a backup
+type SolrRestoreCollection struct {
+	// The name of the collection in the backup
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The name of the collection to restore the backup into. The collection must not exist yet.
+	// Defaults to the name of the collection in the backup.
+	// +optional
+	Target string `json:"target,omitempty"`
+}
+
+// SolrRestoreStatus defines the observed state of SolrRestore
+type SolrRestoreStatus struct {
+	// The status of each collection's restore progress
+	// +optional
+	CollectionRestoreStatuses []CollectionRestoreStatus `json:"collectionRestoreStatuses,omitempty"`
+
+	// Time that the restore started at
+	// +optional
+	StartTime *metav1.Time `json:"startTimestamp,omitempty"`
+
+	// Time that the restore finished at
+	// +optional
+	FinishTime *metav1.Time `json:"finishTimestamp,omitempty"`
+
+	// Whether the restore was successful
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+
+	// Whether the restore has finished
+	Finished bool `json:"finished,omitempty"`
+
+	// The status of each data PersistentVolumeClaim that is restored from a VolumeSnapshot, for SolrBackups taken as VolumeSnapshots
+	// +optional
+	VolumeRestoreStatuses []VolumeRestoreStatus `json:"volumeRestoreStatuses,omitempty"`
+
+	// Conditions describe the latest observations of the SolrRestore.
+	// The "Complete" condition is True once every collection has been restored, and False, with the reason and message,
+	// while the restore is waiting, in progress, or has failed.
+	// The "ClusterStateRestored" condition is only reported when restoreClusterState is enabled, and lists the restored znodes.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// SolrRestoreComplete is the condition type that reports whether all collections of a SolrRestore have been restored
+	SolrRestoreComplete = "Complete"
+
+	// SolrRestoreClusterStateRestored is the condition type that reports whether the cluster state exported with the backup has been restored
+	SolrRestoreClusterStateRestored = "ClusterStateRestored"
+)
+
+// CollectionRestoreStatus defines the progress of a Solr Collection's restore
+type CollectionRestoreStatus struct {
+	// The name of the collection in the backup
+	Collection string `json:"collection"`
+
+	// The name of the collection that is restored
+	Target string `json:"target"`
+
+	// Whether the collection is being restored
+	// +optional
+	InProgress bool `json:"inProgress,omitempty"`
+
+	// Time that the collection restore started at
+	// +optional
+	StartTime *metav1.Time `json:"startTimestamp,omitempty"`
+
+	// The status of the asynchronous restore call to solr
+	// +optional
+	AsyncRestoreStatus string `json:"asyncRestoreStatus,omitempty"`
+
+	// Whether the restore has finished
+	Finished bool `json:"finished,omitempty"`
+
+	// Time that the collection restore finished at
+	// +optional
+	FinishTime *metav1.Time `json:"finishTimestamp,omitempty"`
+
+	// Whether the restore was successful
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+}
+
+// VolumeRestoreStatus defines the restore of a Solr Node's data PersistentVolumeClaim from a VolumeSnapshot
+type VolumeRestoreStatus struct {
+	// The name of the data PersistentVolumeClaim that is restored
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// The name of the VolumeSnapshot that the PersistentVolumeClaim is restored from
+	VolumeSnapshot string `json:"volumeSnapshot"`
+
+	// Whether the PersistentVolumeClaim was created from the VolumeSnapshot
+	// +optional
+	Restored bool `json:"restored,omitempty"`
+
+	// Why the PersistentVolumeClaim could not be restored, if it was not
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AsyncId returns the ID of the asynchronous Collections API request that restores the collection
+func (sr *SolrRestore) AsyncId(target string) string {
+	return fmt.Sprintf("%s-restore-%s", sr.Name, target)
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:storageversion
+//+kubebuilder:categories=all
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.solrBackup",description="The SolrBackup being restored"
+//+kubebuilder:printcolumn:name="Finished",type="boolean",JSONPath=".status.finished",description="Whether the restore has finished"
+//+kubebuilder:printcolumn:name="Successful",type="boolean",JSONPath=".status.successful",description="Whether the restore was successful"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrRestore is the Schema for the solrrestores API
+type SolrRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrRestoreSpec   `json:"spec,omitempty"`
+	Status SolrRestoreStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (sr *SolrRestore) WithDefaults() bool {
+	return sr.Spec.withDefaults()
+}
+
+//+kubebuilder:object:root=true
+
+// SolrRestoreList contains a list of SolrRestore
+type SolrRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrRestore{}, &SolrRestoreList{})
+}
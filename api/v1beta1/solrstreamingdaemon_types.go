@@ -0,0 +1,132 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultStreamingDaemonRunIntervalMillis = 2000
+)
+
+// SolrStreamingDaemonSpec defines the desired state of SolrStreamingDaemon
+type SolrStreamingDaemonSpec struct {
+	// The name of the SolrCloud, in the same namespace, to run the daemon in
+	// +kubebuilder:validation:MinLength=1
+	SolrCloud string `json:"solrCloud"`
+
+	// The collection whose /stream handler runs the daemon
+	// +kubebuilder:validation:MinLength=1
+	Collection string `json:"collection"`
+
+	// The streaming expression that the daemon runs repeatedly, such as a topic() wrapped in an update() or a commit().
+	// The operator wraps this expression in the daemon() function, so it must not be included here.
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+
+	// How often the daemon runs the expression, in milliseconds.
+	// Defaults to 2000
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RunIntervalMillis int32 `json:"runIntervalMillis,omitempty"`
+
+	// Stop the daemon, without deleting the SolrStreamingDaemon.
+	// The daemon is submitted again when this is switched off.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+func (spec *SolrStreamingDaemonSpec) withDefaults() (changed bool) {
+	if spec.RunIntervalMillis == 0 {
+		changed = true
+		spec.RunIntervalMillis = DefaultStreamingDaemonRunIntervalMillis
+	}
+
+	return changed
+}
+
+// SolrStreamingDaemonStatus defines the observed state of SolrStreamingDaemon
+type SolrStreamingDaemonStatus struct {
+	// The Solr pod that the daemon was submitted to.
+	// Daemons only live in the memory of a single Solr node, so they are lost when that pod restarts.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// Whether the daemon is running in Solr
+	Running bool `json:"running"`
+
+	// The hash of the daemon expression that was last submitted
+	// +optional
+	ExpressionHash string `json:"expressionHash,omitempty"`
+
+	// The number of times that the daemon has been submitted, including resubmissions after Solr pods restarted
+	// +optional
+	Submissions int32 `json:"submissions,omitempty"`
+
+	// When the daemon was last submitted
+	// +optional
+	LastSubmitTime *metav1.Time `json:"lastSubmitTime,omitempty"`
+
+	// The number of times the daemon has run the expression since it was last submitted, as reported by Solr
+	// +optional
+	Iterations int64 `json:"iterations,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:resource:shortName=solrdaemon
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="Collection",type="string",JSONPath=".spec.collection",description="The collection that runs the daemon"
+//+kubebuilder:printcolumn:name="Running",type="boolean",JSONPath=".status.running",description="Whether the daemon is running"
+//+kubebuilder:printcolumn:name="Node",type="string",JSONPath=".status.node",description="The Solr pod running the daemon"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrStreamingDaemon is the Schema for the solrstreamingdaemons API
+type SolrStreamingDaemon struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrStreamingDaemonSpec   `json:"spec,omitempty"`
+	Status SolrStreamingDaemonStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (daemon *SolrStreamingDaemon) WithDefaults() bool {
+	return daemon.Spec.withDefaults()
+}
+
+// DaemonId returns the id that the daemon is registered with in Solr
+func (daemon *SolrStreamingDaemon) DaemonId() string {
+	return daemon.Name
+}
+
+//+kubebuilder:object:root=true
+
+// SolrStreamingDaemonList contains a list of SolrStreamingDaemon
+type SolrStreamingDaemonList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrStreamingDaemon `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrStreamingDaemon{}, &SolrStreamingDaemonList{})
+}
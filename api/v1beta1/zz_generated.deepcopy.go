@@ -23,6 +23,7 @@ package v1beta1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -76,6 +77,56 @@ func (in *BackupPersistenceStatus) DeepCopy() *BackupPersistenceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRecurrence) DeepCopyInto(out *BackupRecurrence) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRecurrence.
+func (in *BackupRecurrence) DeepCopy() *BackupRecurrence {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRecurrence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupThrottlingOptions) DeepCopyInto(out *BackupThrottlingOptions) {
+	*out = *in
+	if in.MaxConcurrentCollectionBackups != nil {
+		in, out := &in.MaxConcurrentCollectionBackups, &out.MaxConcurrentCollectionBackups
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupThrottlingOptions.
+func (in *BackupThrottlingOptions) DeepCopy() *BackupThrottlingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupThrottlingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateIssuerReference) DeepCopyInto(out *CertificateIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIssuerReference.
+func (in *CertificateIssuerReference) DeepCopy() *CertificateIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CollectionBackupStatus) DeepCopyInto(out *CollectionBackupStatus) {
 	*out = *in
@@ -104,6 +155,69 @@ func (in *CollectionBackupStatus) DeepCopy() *CollectionBackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionMigrationStatus) DeepCopyInto(out *CollectionMigrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionMigrationStatus.
+func (in *CollectionMigrationStatus) DeepCopy() *CollectionMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionRestoreStatus) DeepCopyInto(out *CollectionRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionRestoreStatus.
+func (in *CollectionRestoreStatus) DeepCopy() *CollectionRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapFile) DeepCopyInto(out *ConfigMapFile) {
+	*out = *in
+	if in.RestartOnUpdate != nil {
+		in, out := &in.RestartOnUpdate, &out.RestartOnUpdate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapFile.
+func (in *ConfigMapFile) DeepCopy() *ConfigMapFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapOptions) DeepCopyInto(out *ConfigMapOptions) {
 	*out = *in
@@ -133,6 +247,71 @@ func (in *ConfigMapOptions) DeepCopy() *ConfigMapOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigSetFile) DeepCopyInto(out *ConfigSetFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigSetFile.
+func (in *ConfigSetFile) DeepCopy() *ConfigSetFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigSetFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigSetFiles) DeepCopyInto(out *ConfigSetFiles) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]ConfigSetFile, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigSetFiles.
+func (in *ConfigSetFiles) DeepCopy() *ConfigSetFiles {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigSetFiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigSetFilesStatus) DeepCopyInto(out *ConfigSetFilesStatus) {
+	*out = *in
+	if in.ReloadedCollections != nil {
+		in, out := &in.ReloadedCollections, &out.ReloadedCollections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.DriftedFiles != nil {
+		in, out := &in.DriftedFiles, &out.DriftedFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastDriftCheckTime != nil {
+		in, out := &in.LastDriftCheckTime, &out.LastDriftCheckTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigSetFilesStatus.
+func (in *ConfigSetFilesStatus) DeepCopy() *ConfigSetFilesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigSetFilesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerImage) DeepCopyInto(out *ContainerImage) {
 	*out = *in
@@ -183,6 +362,31 @@ func (in *CustomExporterKubeOptions) DeepCopy() *CustomExporterKubeOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomIndexingBridgeKubeOptions) DeepCopyInto(out *CustomIndexingBridgeKubeOptions) {
+	*out = *in
+	if in.PodOptions != nil {
+		in, out := &in.PodOptions, &out.PodOptions
+		*out = new(PodOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeploymentOptions != nil {
+		in, out := &in.DeploymentOptions, &out.DeploymentOptions
+		*out = new(DeploymentOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomIndexingBridgeKubeOptions.
+func (in *CustomIndexingBridgeKubeOptions) DeepCopy() *CustomIndexingBridgeKubeOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomIndexingBridgeKubeOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomSolrKubeOptions) DeepCopyInto(out *CustomSolrKubeOptions) {
 	*out = *in
@@ -191,6 +395,11 @@ func (in *CustomSolrKubeOptions) DeepCopyInto(out *CustomSolrKubeOptions) {
 		*out = new(PodOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SolrContainerOptions != nil {
+		in, out := &in.SolrContainerOptions, &out.SolrContainerOptions
+		*out = new(SolrContainerOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StatefulSetOptions != nil {
 		in, out := &in.StatefulSetOptions, &out.StatefulSetOptions
 		*out = new(StatefulSetOptions)
@@ -216,11 +425,28 @@ func (in *CustomSolrKubeOptions) DeepCopyInto(out *CustomSolrKubeOptions) {
 		*out = new(ConfigMapOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapFiles != nil {
+		in, out := &in.ConfigMapFiles, &out.ConfigMapFiles
+		*out = make([]ConfigMapFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.IngressOptions != nil {
 		in, out := &in.IngressOptions, &out.IngressOptions
 		*out = new(IngressOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PropagateLabels != nil {
+		in, out := &in.PropagateLabels, &out.PropagateLabels
+		*out = new(MetadataPropagationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropagateAnnotations != nil {
+		in, out := &in.PropagateAnnotations, &out.PropagateAnnotations
+		*out = new(MetadataPropagationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomSolrKubeOptions.
@@ -270,6 +496,11 @@ func (in *ExternalAddressability) DeepCopyInto(out *ExternalAddressability) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(ExternalMaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAddressability.
@@ -282,10 +513,39 @@ func (in *ExternalAddressability) DeepCopy() *ExternalAddressability {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalMaintenanceWindow) DeepCopyInto(out *ExternalMaintenanceWindow) {
+	*out = *in
+	if in.HideNodes != nil {
+		in, out := &in.HideNodes, &out.HideNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalMaintenanceWindow.
+func (in *ExternalMaintenanceWindow) DeepCopy() *ExternalMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GcsRepository) DeepCopyInto(out *GcsRepository) {
 	*out = *in
 	in.GcsCredentialSecret.DeepCopyInto(&out.GcsCredentialSecret)
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(GcsWorkloadIdentity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GcsRepository.
@@ -298,6 +558,27 @@ func (in *GcsRepository) DeepCopy() *GcsRepository {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GcsWorkloadIdentity) DeepCopyInto(out *GcsWorkloadIdentity) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.CredentialConfig.DeepCopyInto(&out.CredentialConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GcsWorkloadIdentity.
+func (in *GcsWorkloadIdentity) DeepCopy() *GcsWorkloadIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(GcsWorkloadIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressOptions) DeepCopyInto(out *IngressOptions) {
 	*out = *in
@@ -327,6 +608,31 @@ func (in *IngressOptions) DeepCopy() *IngressOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSourceOptions) DeepCopyInto(out *KafkaSourceOptions) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConsumerPropertiesSecret != nil {
+		in, out := &in.ConsumerPropertiesSecret, &out.ConsumerPropertiesSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSourceOptions.
+func (in *KafkaSourceOptions) DeepCopy() *KafkaSourceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSourceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedRepository) DeepCopyInto(out *ManagedRepository) {
 	*out = *in
@@ -343,6 +649,26 @@ func (in *ManagedRepository) DeepCopy() *ManagedRepository {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedSecurityJsonStatus) DeepCopyInto(out *ManagedSecurityJsonStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedSecurityJsonStatus.
+func (in *ManagedSecurityJsonStatus) DeepCopy() *ManagedSecurityJsonStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedSecurityJsonStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedUpdateOptions) DeepCopyInto(out *ManagedUpdateOptions) {
 	*out = *in
@@ -356,6 +682,28 @@ func (in *ManagedUpdateOptions) DeepCopyInto(out *ManagedUpdateOptions) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MaxPodsUnavailablePerZone != nil {
+		in, out := &in.MaxPodsUnavailablePerZone, &out.MaxPodsUnavailablePerZone
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.DrainSeconds != nil {
+		in, out := &in.DrainSeconds, &out.DrainSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WarmUpSeconds != nil {
+		in, out := &in.WarmUpSeconds, &out.WarmUpSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VerificationChecks != nil {
+		in, out := &in.VerificationChecks, &out.VerificationChecks
+		*out = make([]UpdateVerificationCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedUpdateOptions.
@@ -368,6 +716,26 @@ func (in *ManagedUpdateOptions) DeepCopy() *ManagedUpdateOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPropagationPolicy) DeepCopyInto(out *MetadataPropagationPolicy) {
+	*out = *in
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPropagationPolicy.
+func (in *MetadataPropagationPolicy) DeepCopy() *MetadataPropagationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPropagationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MountedTLSDirectory) DeepCopyInto(out *MountedTLSDirectory) {
 	*out = *in
@@ -384,13 +752,66 @@ func (in *MountedTLSDirectory) DeepCopy() *MountedTLSDirectory {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PersistenceSource) DeepCopyInto(out *PersistenceSource) {
+func (in *NodeNameTemplateData) DeepCopyInto(out *NodeNameTemplateData) {
 	*out = *in
-	if in.S3 != nil {
-		in, out := &in.S3, &out.S3
-		*out = new(S3PersistenceSource)
-		(*in).DeepCopyInto(*out)
-	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNameTemplateData.
+func (in *NodeNameTemplateData) DeepCopy() *NodeNameTemplateData {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNameTemplateData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerPodCertificateOptions) DeepCopyInto(out *PerPodCertificateOptions) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerPodCertificateOptions.
+func (in *PerPodCertificateOptions) DeepCopy() *PerPodCertificateOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PerPodCertificateOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistenceServiceAccount) DeepCopyInto(out *PersistenceServiceAccount) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistenceServiceAccount.
+func (in *PersistenceServiceAccount) DeepCopy() *PersistenceServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistenceServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistenceSource) DeepCopyInto(out *PersistenceSource) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3PersistenceSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Volume != nil {
 		in, out := &in.Volume, &out.Volume
 		*out = new(VolumePersistenceSource)
@@ -515,6 +936,7 @@ func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.DefaultInitContainerResources.DeepCopyInto(&out.DefaultInitContainerResources)
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]v1.LocalObjectReference, len(*in))
@@ -537,6 +959,53 @@ func (in *PodOptions) DeepCopy() *PodOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryBackupStatus) DeepCopyInto(out *RepositoryBackupStatus) {
+	*out = *in
+	if in.CollectionBackupStatuses != nil {
+		in, out := &in.CollectionBackupStatuses, &out.CollectionBackupStatuses
+		*out = make([]CollectionBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryBackupStatus.
+func (in *RepositoryBackupStatus) DeepCopy() *RepositoryBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedCollectionBackups) DeepCopyInto(out *RetainedCollectionBackups) {
+	*out = *in
+	if in.BackupIds != nil {
+		in, out := &in.BackupIds, &out.BackupIds
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedCollectionBackups.
+func (in *RetainedCollectionBackups) DeepCopy() *RetainedCollectionBackups {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedCollectionBackups)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3PersistenceSource) DeepCopyInto(out *S3PersistenceSource) {
 	*out = *in
@@ -545,7 +1014,16 @@ func (in *S3PersistenceSource) DeepCopyInto(out *S3PersistenceSource) {
 		*out = new(int32)
 		**out = **in
 	}
-	out.Secrets = in.Secrets
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = new(S3Secrets)
+		**out = **in
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(PersistenceServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
 	out.AWSCliImage = in.AWSCliImage
 }
 
@@ -591,6 +1069,11 @@ func (in *ServiceOptions) DeepCopyInto(out *ServiceOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.PublishNotReadyAddresses != nil {
+		in, out := &in.PublishNotReadyAddresses, &out.PublishNotReadyAddresses
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceOptions.
@@ -603,6 +1086,21 @@ func (in *ServiceOptions) DeepCopy() *ServiceOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedZookeeperChRoot) DeepCopyInto(out *SharedZookeeperChRoot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedZookeeperChRoot.
+func (in *SharedZookeeperChRoot) DeepCopy() *SharedZookeeperChRoot {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedZookeeperChRoot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrAddressabilityOptions) DeepCopyInto(out *SolrAddressabilityOptions) {
 	*out = *in
@@ -611,6 +1109,11 @@ func (in *SolrAddressabilityOptions) DeepCopyInto(out *SolrAddressabilityOptions
 		*out = new(ExternalAddressability)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HostNetwork != nil {
+		in, out := &in.HostNetwork, &out.HostNetwork
+		*out = new(SolrHostNetworkOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrAddressabilityOptions.
@@ -623,6 +1126,26 @@ func (in *SolrAddressabilityOptions) DeepCopy() *SolrAddressabilityOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrAvailabilityOptions) DeepCopyInto(out *SolrAvailabilityOptions) {
+	*out = *in
+	if in.MinReadyNodesForReady != nil {
+		in, out := &in.MinReadyNodesForReady, &out.MinReadyNodesForReady
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrAvailabilityOptions.
+func (in *SolrAvailabilityOptions) DeepCopy() *SolrAvailabilityOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrAvailabilityOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrBackup) DeepCopyInto(out *SolrBackup) {
 	*out = *in
@@ -726,6 +1249,11 @@ func (in *SolrBackupRestoreOptions) DeepCopy() *SolrBackupRestoreOptions {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrBackupSpec) DeepCopyInto(out *SolrBackupSpec) {
 	*out = *in
+	if in.AdditionalRepositoryNames != nil {
+		in, out := &in.AdditionalRepositoryNames, &out.AdditionalRepositoryNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Collections != nil {
 		in, out := &in.Collections, &out.Collections
 		*out = make([]string, len(*in))
@@ -736,6 +1264,21 @@ func (in *SolrBackupSpec) DeepCopyInto(out *SolrBackupSpec) {
 		*out = new(PersistenceSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Recurrence != nil {
+		in, out := &in.Recurrence, &out.Recurrence
+		*out = new(BackupRecurrence)
+		**out = **in
+	}
+	if in.VolumeSnapshot != nil {
+		in, out := &in.VolumeSnapshot, &out.VolumeSnapshot
+		*out = new(VolumeSnapshotBackupOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Throttling != nil {
+		in, out := &in.Throttling, &out.Throttling
+		*out = new(BackupThrottlingOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupSpec.
@@ -751,6 +1294,11 @@ func (in *SolrBackupSpec) DeepCopy() *SolrBackupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrBackupStatus) DeepCopyInto(out *SolrBackupStatus) {
 	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.CollectionBackupStatuses != nil {
 		in, out := &in.CollectionBackupStatuses, &out.CollectionBackupStatuses
 		*out = make([]CollectionBackupStatus, len(*in))
@@ -758,6 +1306,13 @@ func (in *SolrBackupStatus) DeepCopyInto(out *SolrBackupStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AdditionalRepositoryStatuses != nil {
+		in, out := &in.AdditionalRepositoryStatuses, &out.AdditionalRepositoryStatuses
+		*out = make([]RepositoryBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.PersistenceStatus.DeepCopyInto(&out.PersistenceStatus)
 	if in.FinishTime != nil {
 		in, out := &in.FinishTime, &out.FinishTime
@@ -768,6 +1323,40 @@ func (in *SolrBackupStatus) DeepCopyInto(out *SolrBackupStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NextScheduledTime != nil {
+		in, out := &in.NextScheduledTime, &out.NextScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RetainedBackups != nil {
+		in, out := &in.RetainedBackups, &out.RetainedBackups
+		*out = make([]RetainedCollectionBackups, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastPruneTime != nil {
+		in, out := &in.LastPruneTime, &out.LastPruneTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]VolumeSnapshotBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadOnlyCollections != nil {
+		in, out := &in.ReadOnlyCollections, &out.ReadOnlyCollections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupStatus.
@@ -839,6 +1428,27 @@ func (in *SolrCloudList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudOperation) DeepCopyInto(out *SolrCloudOperation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudOperation.
+func (in *SolrCloudOperation) DeepCopy() *SolrCloudOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrCloudReference) DeepCopyInto(out *SolrCloudReference) {
 	*out = *in
@@ -859,6 +1469,26 @@ func (in *SolrCloudReference) DeepCopy() *SolrCloudReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudResourceNames) DeepCopyInto(out *SolrCloudResourceNames) {
+	*out = *in
+	if in.NodeServices != nil {
+		in, out := &in.NodeServices, &out.NodeServices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudResourceNames.
+func (in *SolrCloudResourceNames) DeepCopy() *SolrCloudResourceNames {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudResourceNames)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrCloudSpec) DeepCopyInto(out *SolrCloudSpec) {
 	*out = *in
@@ -881,11 +1511,80 @@ func (in *SolrCloudSpec) DeepCopyInto(out *SolrCloudSpec) {
 	in.CustomSolrKubeOptions.DeepCopyInto(&out.CustomSolrKubeOptions)
 	in.SolrAddressability.DeepCopyInto(&out.SolrAddressability)
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	out.Scaling = in.Scaling
+	if in.StandbyOf != nil {
+		in, out := &in.StandbyOf, &out.StandbyOf
+		*out = new(SolrStandbyOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigSetFiles != nil {
+		in, out := &in.ConfigSetFiles, &out.ConfigSetFiles
+		*out = make([]ConfigSetFiles, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterStateOptions != nil {
+		in, out := &in.ClusterStateOptions, &out.ClusterStateOptions
+		*out = new(SolrClusterStateOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(SolrInventoryOptions)
+		**out = **in
+	}
+	if in.CollectionMetrics != nil {
+		in, out := &in.CollectionMetrics, &out.CollectionMetrics
+		*out = new(SolrCollectionMetricsOptions)
+		**out = **in
+	}
+	if in.NodeMetrics != nil {
+		in, out := &in.NodeMetrics, &out.NodeMetrics
+		*out = new(SolrNodeMetricsOptions)
+		**out = **in
+	}
+	if in.NodeInterruption != nil {
+		in, out := &in.NodeInterruption, &out.NodeInterruption
+		*out = new(SolrNodeInterruptionOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpotNodes != nil {
+		in, out := &in.SpotNodes, &out.SpotNodes
+		*out = new(SolrSpotNodeOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Availability != nil {
+		in, out := &in.Availability, &out.Availability
+		*out = new(SolrAvailabilityOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Probes = in.Probes
+	if in.ManagedResources != nil {
+		in, out := &in.ManagedResources, &out.ManagedResources
+		*out = new(SolrManagedResourcesOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BusyBoxImage != nil {
 		in, out := &in.BusyBoxImage, &out.BusyBoxImage
 		*out = new(ContainerImage)
 		**out = **in
 	}
+	if in.ZkSetupImage != nil {
+		in, out := &in.ZkSetupImage, &out.ZkSetupImage
+		*out = new(ContainerImage)
+		**out = **in
+	}
+	if in.IndexDirectory != nil {
+		in, out := &in.IndexDirectory, &out.IndexDirectory
+		*out = new(SolrIndexDirectoryOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeRestrictions != nil {
+		in, out := &in.RuntimeRestrictions, &out.RuntimeRestrictions
+		*out = new(SolrRuntimeRestrictionOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SolrTLS != nil {
 		in, out := &in.SolrTLS, &out.SolrTLS
 		*out = new(SolrTLSOptions)
@@ -899,6 +1598,16 @@ func (in *SolrCloudSpec) DeepCopyInto(out *SolrCloudSpec) {
 	if in.SolrSecurity != nil {
 		in, out := &in.SolrSecurity, &out.SolrSecurity
 		*out = new(SolrSecurityOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperatorClient != nil {
+		in, out := &in.OperatorClient, &out.OperatorClient
+		*out = new(SolrOperatorClientOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionInfo != nil {
+		in, out := &in.ConnectionInfo, &out.ConnectionInfo
+		*out = new(SolrConnectionInfoOptions)
 		**out = **in
 	}
 	if in.BackupRepositories != nil {
@@ -934,6 +1643,58 @@ func (in *SolrCloudStatus) DeepCopyInto(out *SolrCloudStatus) {
 		**out = **in
 	}
 	in.ZookeeperConnectionInfo.DeepCopyInto(&out.ZookeeperConnectionInfo)
+	if in.SharedZookeeperChRoots != nil {
+		in, out := &in.SharedZookeeperChRoots, &out.SharedZookeeperChRoots
+		*out = make([]SharedZookeeperChRoot, len(*in))
+		copy(*out, *in)
+	}
+	if in.Standby != nil {
+		in, out := &in.Standby, &out.Standby
+		*out = new(SolrStandbyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigSetFiles != nil {
+		in, out := &in.ConfigSetFiles, &out.ConfigSetFiles
+		*out = make([]ConfigSetFilesStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityJson != nil {
+		in, out := &in.SecurityJson, &out.SecurityJson
+		*out = new(ManagedSecurityJsonStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemporaryReplicas != nil {
+		in, out := &in.TemporaryReplicas, &out.TemporaryReplicas
+		*out = make([]TemporaryReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(SolrDiagnosticsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]SolrCloudOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudStatus.
@@ -947,17 +1708,211 @@ func (in *SolrCloudStatus) DeepCopy() *SolrCloudStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrDataStorageOptions) DeepCopyInto(out *SolrDataStorageOptions) {
+func (in *SolrClusterStateOptions) DeepCopyInto(out *SolrClusterStateOptions) {
 	*out = *in
-	if in.PersistentStorage != nil {
-		in, out := &in.PersistentStorage, &out.PersistentStorage
-		*out = new(SolrPersistentDataStorageOptions)
-		(*in).DeepCopyInto(*out)
+	if in.PerCollectionThreshold != nil {
+		in, out := &in.PerCollectionThreshold, &out.PerCollectionThreshold
+		*out = new(int32)
+		**out = **in
 	}
-	if in.EphemeralStorage != nil {
-		in, out := &in.EphemeralStorage, &out.EphemeralStorage
-		*out = new(SolrEphemeralDataStorageOptions)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrClusterStateOptions.
+func (in *SolrClusterStateOptions) DeepCopy() *SolrClusterStateOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrClusterStateOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionMetricsOptions) DeepCopyInto(out *SolrCollectionMetricsOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionMetricsOptions.
+func (in *SolrCollectionMetricsOptions) DeepCopy() *SolrCollectionMetricsOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionMetricsOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConfigSet) DeepCopyInto(out *SolrConfigSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConfigSet.
+func (in *SolrConfigSet) DeepCopy() *SolrConfigSet {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConfigSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrConfigSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConfigSetList) DeepCopyInto(out *SolrConfigSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrConfigSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConfigSetList.
+func (in *SolrConfigSetList) DeepCopy() *SolrConfigSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConfigSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrConfigSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConfigSetSource) DeepCopyInto(out *SolrConfigSetSource) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]ConfigSetFile, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConfigSetSource.
+func (in *SolrConfigSetSource) DeepCopy() *SolrConfigSetSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConfigSetSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConfigSetSpec) DeepCopyInto(out *SolrConfigSetSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConfigSetSpec.
+func (in *SolrConfigSetSpec) DeepCopy() *SolrConfigSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConfigSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConfigSetStatus) DeepCopyInto(out *SolrConfigSetStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReloadedCollections != nil {
+		in, out := &in.ReloadedCollections, &out.ReloadedCollections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConfigSetStatus.
+func (in *SolrConfigSetStatus) DeepCopy() *SolrConfigSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConfigSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrConnectionInfoOptions) DeepCopyInto(out *SolrConnectionInfoOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrConnectionInfoOptions.
+func (in *SolrConnectionInfoOptions) DeepCopy() *SolrConnectionInfoOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrConnectionInfoOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrContainerOptions) DeepCopyInto(out *SolrContainerOptions) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrContainerOptions.
+func (in *SolrContainerOptions) DeepCopy() *SolrContainerOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrContainerOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrDataStorageOptions) DeepCopyInto(out *SolrDataStorageOptions) {
+	*out = *in
+	if in.PersistentStorage != nil {
+		in, out := &in.PersistentStorage, &out.PersistentStorage
+		*out = new(SolrPersistentDataStorageOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(SolrEphemeralDataStorageOptions)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.BackupRestoreOptions != nil {
 		in, out := &in.BackupRestoreOptions, &out.BackupRestoreOptions
@@ -976,6 +1931,32 @@ func (in *SolrDataStorageOptions) DeepCopy() *SolrDataStorageOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrDiagnosticsStatus) DeepCopyInto(out *SolrDiagnosticsStatus) {
+	*out = *in
+	in.CollectionTime.DeepCopyInto(&out.CollectionTime)
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrDiagnosticsStatus.
+func (in *SolrDiagnosticsStatus) DeepCopy() *SolrDiagnosticsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrDiagnosticsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrEphemeralDataStorageOptions) DeepCopyInto(out *SolrEphemeralDataStorageOptions) {
 	*out = *in
@@ -991,68 +1972,1055 @@ func (in *SolrEphemeralDataStorageOptions) DeepCopyInto(out *SolrEphemeralDataSt
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrEphemeralDataStorageOptions.
-func (in *SolrEphemeralDataStorageOptions) DeepCopy() *SolrEphemeralDataStorageOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrEphemeralDataStorageOptions.
+func (in *SolrEphemeralDataStorageOptions) DeepCopy() *SolrEphemeralDataStorageOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrEphemeralDataStorageOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrHostNetworkOptions) DeepCopyInto(out *SolrHostNetworkOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrHostNetworkOptions.
+func (in *SolrHostNetworkOptions) DeepCopy() *SolrHostNetworkOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrHostNetworkOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIndexDirectoryOptions) DeepCopyInto(out *SolrIndexDirectoryOptions) {
+	*out = *in
+	if in.MinMaxMapCount != nil {
+		in, out := &in.MinMaxMapCount, &out.MinMaxMapCount
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIndexDirectoryOptions.
+func (in *SolrIndexDirectoryOptions) DeepCopy() *SolrIndexDirectoryOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIndexDirectoryOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIndexingBridge) DeepCopyInto(out *SolrIndexingBridge) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIndexingBridge.
+func (in *SolrIndexingBridge) DeepCopy() *SolrIndexingBridge {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIndexingBridge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrIndexingBridge) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIndexingBridgeList) DeepCopyInto(out *SolrIndexingBridgeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrIndexingBridge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIndexingBridgeList.
+func (in *SolrIndexingBridgeList) DeepCopy() *SolrIndexingBridgeList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIndexingBridgeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrIndexingBridgeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIndexingBridgeSpec) DeepCopyInto(out *SolrIndexingBridgeSpec) {
+	*out = *in
+	in.SolrReference.DeepCopyInto(&out.SolrReference)
+	in.Kafka.DeepCopyInto(&out.Kafka)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ContainerImage)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CommitWithinMs != nil {
+		in, out := &in.CommitWithinMs, &out.CommitWithinMs
+		*out = new(int32)
+		**out = **in
+	}
+	in.CustomKubeOptions.DeepCopyInto(&out.CustomKubeOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIndexingBridgeSpec.
+func (in *SolrIndexingBridgeSpec) DeepCopy() *SolrIndexingBridgeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIndexingBridgeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIndexingBridgeStatus) DeepCopyInto(out *SolrIndexingBridgeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIndexingBridgeStatus.
+func (in *SolrIndexingBridgeStatus) DeepCopy() *SolrIndexingBridgeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIndexingBridgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrInventoryOptions) DeepCopyInto(out *SolrInventoryOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrInventoryOptions.
+func (in *SolrInventoryOptions) DeepCopy() *SolrInventoryOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrInventoryOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrKerberosOptions) DeepCopyInto(out *SolrKerberosOptions) {
+	*out = *in
+	in.KeytabSecret.DeepCopyInto(&out.KeytabSecret)
+	if in.Krb5Config != nil {
+		in, out := &in.Krb5Config, &out.Krb5Config
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrKerberosOptions.
+func (in *SolrKerberosOptions) DeepCopy() *SolrKerberosOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrKerberosOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrManagedResourcesOptions) DeepCopyInto(out *SolrManagedResourcesOptions) {
+	*out = *in
+	if in.ManageIngress != nil {
+		in, out := &in.ManageIngress, &out.ManageIngress
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ManageConfigMap != nil {
+		in, out := &in.ManageConfigMap, &out.ManageConfigMap
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ManageServices != nil {
+		in, out := &in.ManageServices, &out.ManageServices
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrManagedResourcesOptions.
+func (in *SolrManagedResourcesOptions) DeepCopy() *SolrManagedResourcesOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrManagedResourcesOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigration) DeepCopyInto(out *SolrMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigration.
+func (in *SolrMigration) DeepCopy() *SolrMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigrationCollection) DeepCopyInto(out *SolrMigrationCollection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigrationCollection.
+func (in *SolrMigrationCollection) DeepCopy() *SolrMigrationCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigrationCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigrationList) DeepCopyInto(out *SolrMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigrationList.
+func (in *SolrMigrationList) DeepCopy() *SolrMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigrationSource) DeepCopyInto(out *SolrMigrationSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigrationSource.
+func (in *SolrMigrationSource) DeepCopy() *SolrMigrationSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigrationSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigrationSpec) DeepCopyInto(out *SolrMigrationSpec) {
+	*out = *in
+	out.Source = in.Source
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]SolrMigrationCollection, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigrationSpec.
+func (in *SolrMigrationSpec) DeepCopy() *SolrMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrMigrationStatus) DeepCopyInto(out *SolrMigrationStatus) {
+	*out = *in
+	if in.CollectionMigrationStatuses != nil {
+		in, out := &in.CollectionMigrationStatuses, &out.CollectionMigrationStatuses
+		*out = make([]CollectionMigrationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrMigrationStatus.
+func (in *SolrMigrationStatus) DeepCopy() *SolrMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrNodeInterruptionOptions) DeepCopyInto(out *SolrNodeInterruptionOptions) {
+	*out = *in
+	if in.TaintKeys != nil {
+		in, out := &in.TaintKeys, &out.TaintKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrNodeInterruptionOptions.
+func (in *SolrNodeInterruptionOptions) DeepCopy() *SolrNodeInterruptionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrNodeInterruptionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrNodeMetricsOptions) DeepCopyInto(out *SolrNodeMetricsOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrNodeMetricsOptions.
+func (in *SolrNodeMetricsOptions) DeepCopy() *SolrNodeMetricsOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrNodeMetricsOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrNodeStatus) DeepCopyInto(out *SolrNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrNodeStatus.
+func (in *SolrNodeStatus) DeepCopy() *SolrNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrOperatorClientOptions) DeepCopyInto(out *SolrOperatorClientOptions) {
+	*out = *in
+	if in.CABundleSecret != nil {
+		in, out := &in.CABundleSecret, &out.CABundleSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrOperatorClientOptions.
+func (in *SolrOperatorClientOptions) DeepCopy() *SolrOperatorClientOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrOperatorClientOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrOperatorConfig) DeepCopyInto(out *SolrOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrOperatorConfig.
+func (in *SolrOperatorConfig) DeepCopy() *SolrOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrOperatorConfigImages) DeepCopyInto(out *SolrOperatorConfigImages) {
+	*out = *in
+	if in.Solr != nil {
+		in, out := &in.Solr, &out.Solr
+		*out = new(ContainerImage)
+		**out = **in
+	}
+	if in.BusyBox != nil {
+		in, out := &in.BusyBox, &out.BusyBox
+		*out = new(ContainerImage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrOperatorConfigImages.
+func (in *SolrOperatorConfigImages) DeepCopy() *SolrOperatorConfigImages {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrOperatorConfigImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrOperatorConfigList) DeepCopyInto(out *SolrOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrOperatorConfigList.
+func (in *SolrOperatorConfigList) DeepCopy() *SolrOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrOperatorConfigSpec) DeepCopyInto(out *SolrOperatorConfigSpec) {
+	*out = *in
+	in.DefaultImages.DeepCopyInto(&out.DefaultImages)
+	if in.DefaultSolrTLS != nil {
+		in, out := &in.DefaultSolrTLS, &out.DefaultSolrTLS
+		*out = new(SolrTLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceSelector != nil {
+		in, out := &in.ResourceSelector, &out.ResourceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrOperatorConfigSpec.
+func (in *SolrOperatorConfigSpec) DeepCopy() *SolrOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrPersistentDataStorageOptions) DeepCopyInto(out *SolrPersistentDataStorageOptions) {
+	*out = *in
+	in.PersistentVolumeClaimTemplate.DeepCopyInto(&out.PersistentVolumeClaimTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPersistentDataStorageOptions.
+func (in *SolrPersistentDataStorageOptions) DeepCopy() *SolrPersistentDataStorageOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrPersistentDataStorageOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrProbesOptions) DeepCopyInto(out *SolrProbesOptions) {
+	*out = *in
+	out.Startup = in.Startup
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrProbesOptions.
+func (in *SolrProbesOptions) DeepCopy() *SolrProbesOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrProbesOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrPrometheusExporter) DeepCopyInto(out *SolrPrometheusExporter) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporter.
+func (in *SolrPrometheusExporter) DeepCopy() *SolrPrometheusExporter {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrPrometheusExporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrPrometheusExporter) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrPrometheusExporterList) DeepCopyInto(out *SolrPrometheusExporterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrPrometheusExporter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterList.
+func (in *SolrPrometheusExporterList) DeepCopy() *SolrPrometheusExporterList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrPrometheusExporterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrPrometheusExporterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrPrometheusExporterSpec) DeepCopyInto(out *SolrPrometheusExporterSpec) {
+	*out = *in
+	in.SolrReference.DeepCopyInto(&out.SolrReference)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ContainerImage)
+		**out = **in
+	}
+	in.CustomKubeOptions.DeepCopyInto(&out.CustomKubeOptions)
+	if in.BusyBoxImage != nil {
+		in, out := &in.BusyBoxImage, &out.BusyBoxImage
+		*out = new(ContainerImage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterSpec.
+func (in *SolrPrometheusExporterSpec) DeepCopy() *SolrPrometheusExporterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrPrometheusExporterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrPrometheusExporterStatus) DeepCopyInto(out *SolrPrometheusExporterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterStatus.
+func (in *SolrPrometheusExporterStatus) DeepCopy() *SolrPrometheusExporterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrPrometheusExporterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrReference) DeepCopyInto(out *SolrReference) {
+	*out = *in
+	if in.Cloud != nil {
+		in, out := &in.Cloud, &out.Cloud
+		*out = new(SolrCloudReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Standalone != nil {
+		in, out := &in.Standalone, &out.Standalone
+		*out = new(StandaloneSolrReference)
+		**out = **in
+	}
+	if in.SolrTLS != nil {
+		in, out := &in.SolrTLS, &out.SolrTLS
+		*out = new(SolrTLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrReference.
+func (in *SolrReference) DeepCopy() *SolrReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRestore) DeepCopyInto(out *SolrRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRestore.
+func (in *SolrRestore) DeepCopy() *SolrRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRestoreCollection) DeepCopyInto(out *SolrRestoreCollection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRestoreCollection.
+func (in *SolrRestoreCollection) DeepCopy() *SolrRestoreCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRestoreCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRestoreList) DeepCopyInto(out *SolrRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRestoreList.
+func (in *SolrRestoreList) DeepCopy() *SolrRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRestoreSpec) DeepCopyInto(out *SolrRestoreSpec) {
+	*out = *in
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(SolrBackupRepository)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupId != nil {
+		in, out := &in.BackupId, &out.BackupId
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]SolrRestoreCollection, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRestoreSpec.
+func (in *SolrRestoreSpec) DeepCopy() *SolrRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRestoreStatus) DeepCopyInto(out *SolrRestoreStatus) {
+	*out = *in
+	if in.CollectionRestoreStatuses != nil {
+		in, out := &in.CollectionRestoreStatuses, &out.CollectionRestoreStatuses
+		*out = make([]CollectionRestoreStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VolumeRestoreStatuses != nil {
+		in, out := &in.VolumeRestoreStatuses, &out.VolumeRestoreStatuses
+		*out = make([]VolumeRestoreStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRestoreStatus.
+func (in *SolrRestoreStatus) DeepCopy() *SolrRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrRuntimeRestrictionOptions) DeepCopyInto(out *SolrRuntimeRestrictionOptions) {
+	*out = *in
+	if in.SecurityManager != nil {
+		in, out := &in.SecurityManager, &out.SecurityManager
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowPaths != nil {
+		in, out := &in.AllowPaths, &out.AllowPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowUrls != nil {
+		in, out := &in.AllowUrls, &out.AllowUrls
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrRuntimeRestrictionOptions.
+func (in *SolrRuntimeRestrictionOptions) DeepCopy() *SolrRuntimeRestrictionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrRuntimeRestrictionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrScalingOptions) DeepCopyInto(out *SolrScalingOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrScalingOptions.
+func (in *SolrScalingOptions) DeepCopy() *SolrScalingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrScalingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrSecurityOptions) DeepCopyInto(out *SolrSecurityOptions) {
+	*out = *in
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(SolrKerberosOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityJsonSecret != nil {
+		in, out := &in.SecurityJsonSecret, &out.SecurityJsonSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JaasConfigSecret != nil {
+		in, out := &in.JaasConfigSecret, &out.JaasConfigSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrSecurityOptions.
+func (in *SolrSecurityOptions) DeepCopy() *SolrSecurityOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrSecurityOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrSpotNodeOptions) DeepCopyInto(out *SolrSpotNodeOptions) {
+	*out = *in
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrSpotNodeOptions.
+func (in *SolrSpotNodeOptions) DeepCopy() *SolrSpotNodeOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrSpotNodeOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrStandbyOptions) DeepCopyInto(out *SolrStandbyOptions) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStandbyOptions.
+func (in *SolrStandbyOptions) DeepCopy() *SolrStandbyOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrEphemeralDataStorageOptions)
+	out := new(SolrStandbyOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrNodeStatus) DeepCopyInto(out *SolrNodeStatus) {
+func (in *SolrStandbyStatus) DeepCopyInto(out *SolrStandbyStatus) {
 	*out = *in
+	if in.LastRestoreTime != nil {
+		in, out := &in.LastRestoreTime, &out.LastRestoreTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRestoreTime != nil {
+		in, out := &in.NextRestoreTime, &out.NextRestoreTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrNodeStatus.
-func (in *SolrNodeStatus) DeepCopy() *SolrNodeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStandbyStatus.
+func (in *SolrStandbyStatus) DeepCopy() *SolrStandbyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrNodeStatus)
+	out := new(SolrStandbyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrPersistentDataStorageOptions) DeepCopyInto(out *SolrPersistentDataStorageOptions) {
+func (in *SolrStartupProbeOptions) DeepCopyInto(out *SolrStartupProbeOptions) {
 	*out = *in
-	in.PersistentVolumeClaimTemplate.DeepCopyInto(&out.PersistentVolumeClaimTemplate)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPersistentDataStorageOptions.
-func (in *SolrPersistentDataStorageOptions) DeepCopy() *SolrPersistentDataStorageOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStartupProbeOptions.
+func (in *SolrStartupProbeOptions) DeepCopy() *SolrStartupProbeOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrPersistentDataStorageOptions)
+	out := new(SolrStartupProbeOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrPrometheusExporter) DeepCopyInto(out *SolrPrometheusExporter) {
+func (in *SolrStreamingDaemon) DeepCopyInto(out *SolrStreamingDaemon) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporter.
-func (in *SolrPrometheusExporter) DeepCopy() *SolrPrometheusExporter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStreamingDaemon.
+func (in *SolrStreamingDaemon) DeepCopy() *SolrStreamingDaemon {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrPrometheusExporter)
+	out := new(SolrStreamingDaemon)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SolrPrometheusExporter) DeepCopyObject() runtime.Object {
+func (in *SolrStreamingDaemon) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1060,31 +3028,31 @@ func (in *SolrPrometheusExporter) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrPrometheusExporterList) DeepCopyInto(out *SolrPrometheusExporterList) {
+func (in *SolrStreamingDaemonList) DeepCopyInto(out *SolrStreamingDaemonList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SolrPrometheusExporter, len(*in))
+		*out = make([]SolrStreamingDaemon, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterList.
-func (in *SolrPrometheusExporterList) DeepCopy() *SolrPrometheusExporterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStreamingDaemonList.
+func (in *SolrStreamingDaemonList) DeepCopy() *SolrStreamingDaemonList {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrPrometheusExporterList)
+	out := new(SolrStreamingDaemonList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SolrPrometheusExporterList) DeepCopyObject() runtime.Object {
+func (in *SolrStreamingDaemonList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1092,88 +3060,35 @@ func (in *SolrPrometheusExporterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrPrometheusExporterSpec) DeepCopyInto(out *SolrPrometheusExporterSpec) {
-	*out = *in
-	in.SolrReference.DeepCopyInto(&out.SolrReference)
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(ContainerImage)
-		**out = **in
-	}
-	in.CustomKubeOptions.DeepCopyInto(&out.CustomKubeOptions)
-	if in.BusyBoxImage != nil {
-		in, out := &in.BusyBoxImage, &out.BusyBoxImage
-		*out = new(ContainerImage)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterSpec.
-func (in *SolrPrometheusExporterSpec) DeepCopy() *SolrPrometheusExporterSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(SolrPrometheusExporterSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrPrometheusExporterStatus) DeepCopyInto(out *SolrPrometheusExporterStatus) {
+func (in *SolrStreamingDaemonSpec) DeepCopyInto(out *SolrStreamingDaemonSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterStatus.
-func (in *SolrPrometheusExporterStatus) DeepCopy() *SolrPrometheusExporterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStreamingDaemonSpec.
+func (in *SolrStreamingDaemonSpec) DeepCopy() *SolrStreamingDaemonSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrPrometheusExporterStatus)
+	out := new(SolrStreamingDaemonSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrReference) DeepCopyInto(out *SolrReference) {
+func (in *SolrStreamingDaemonStatus) DeepCopyInto(out *SolrStreamingDaemonStatus) {
 	*out = *in
-	if in.Cloud != nil {
-		in, out := &in.Cloud, &out.Cloud
-		*out = new(SolrCloudReference)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Standalone != nil {
-		in, out := &in.Standalone, &out.Standalone
-		*out = new(StandaloneSolrReference)
-		**out = **in
-	}
-	if in.SolrTLS != nil {
-		in, out := &in.SolrTLS, &out.SolrTLS
-		*out = new(SolrTLSOptions)
-		(*in).DeepCopyInto(*out)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrReference.
-func (in *SolrReference) DeepCopy() *SolrReference {
-	if in == nil {
-		return nil
+	if in.LastSubmitTime != nil {
+		in, out := &in.LastSubmitTime, &out.LastSubmitTime
+		*out = (*in).DeepCopy()
 	}
-	out := new(SolrReference)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrSecurityOptions) DeepCopyInto(out *SolrSecurityOptions) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrSecurityOptions.
-func (in *SolrSecurityOptions) DeepCopy() *SolrSecurityOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrStreamingDaemonStatus.
+func (in *SolrStreamingDaemonStatus) DeepCopy() *SolrStreamingDaemonStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrSecurityOptions)
+	out := new(SolrStreamingDaemonStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1201,11 +3116,21 @@ func (in *SolrTLSOptions) DeepCopyInto(out *SolrTLSOptions) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TrustBundleConfigMap != nil {
+		in, out := &in.TrustBundleConfigMap, &out.TrustBundleConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MountedTLSDir != nil {
 		in, out := &in.MountedTLSDir, &out.MountedTLSDir
 		*out = new(MountedTLSDirectory)
 		**out = **in
 	}
+	if in.PerPodCertificates != nil {
+		in, out := &in.PerPodCertificates, &out.PerPodCertificates
+		*out = new(PerPodCertificateOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrTLSOptions.
@@ -1307,6 +3232,41 @@ func (in *TemplateMeta) DeepCopy() *TemplateMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporaryReplicaStatus) DeepCopyInto(out *TemporaryReplicaStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporaryReplicaStatus.
+func (in *TemporaryReplicaStatus) DeepCopy() *TemporaryReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporaryReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateVerificationCheck) DeepCopyInto(out *UpdateVerificationCheck) {
+	*out = *in
+	if in.MinHits != nil {
+		in, out := &in.MinHits, &out.MinHits
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateVerificationCheck.
+func (in *UpdateVerificationCheck) DeepCopy() *UpdateVerificationCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateVerificationCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumePersistenceSource) DeepCopyInto(out *VolumePersistenceSource) {
 	*out = *in
@@ -1324,6 +3284,60 @@ func (in *VolumePersistenceSource) DeepCopy() *VolumePersistenceSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeRestoreStatus) DeepCopyInto(out *VolumeRestoreStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeRestoreStatus.
+func (in *VolumeRestoreStatus) DeepCopy() *VolumeRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotBackupOptions) DeepCopyInto(out *VolumeSnapshotBackupOptions) {
+	*out = *in
+	if in.TakeTimeoutSeconds != nil {
+		in, out := &in.TakeTimeoutSeconds, &out.TakeTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotBackupOptions.
+func (in *VolumeSnapshotBackupOptions) DeepCopy() *VolumeSnapshotBackupOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotBackupOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotBackupStatus) DeepCopyInto(out *VolumeSnapshotBackupStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotBackupStatus.
+func (in *VolumeSnapshotBackupStatus) DeepCopy() *VolumeSnapshotBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZKEphemeral) DeepCopyInto(out *ZKEphemeral) {
 	*out = *in
@@ -1378,6 +3392,31 @@ func (in *ZookeeperACL) DeepCopy() *ZookeeperACL {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperClientOptions) DeepCopyInto(out *ZookeeperClientOptions) {
+	*out = *in
+	if in.ClientTimeoutMillis != nil {
+		in, out := &in.ClientTimeoutMillis, &out.ClientTimeoutMillis
+		*out = new(int32)
+		**out = **in
+	}
+	if in.JuteMaxBufferBytes != nil {
+		in, out := &in.JuteMaxBufferBytes, &out.JuteMaxBufferBytes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperClientOptions.
+func (in *ZookeeperClientOptions) DeepCopy() *ZookeeperClientOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperClientOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZookeeperConfig) DeepCopyInto(out *ZookeeperConfig) {
 	*out = *in
@@ -1480,11 +3519,36 @@ func (in *ZookeeperRef) DeepCopyInto(out *ZookeeperRef) {
 		*out = new(ZookeeperConnectionInfo)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = new(ZookeeperServiceRef)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ProvidedZookeeper != nil {
 		in, out := &in.ProvidedZookeeper, &out.ProvidedZookeeper
 		*out = new(ZookeeperSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(ZookeeperSASLOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ZookeeperTLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionTimeoutSeconds != nil {
+		in, out := &in.ConnectionTimeoutSeconds, &out.ConnectionTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ClientOptions != nil {
+		in, out := &in.ClientOptions, &out.ClientOptions
+		*out = new(ZookeeperClientOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperRef.
@@ -1497,6 +3561,56 @@ func (in *ZookeeperRef) DeepCopy() *ZookeeperRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperSASLOptions) DeepCopyInto(out *ZookeeperSASLOptions) {
+	*out = *in
+	if in.KeytabSecret != nil {
+		in, out := &in.KeytabSecret, &out.KeytabSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Krb5Config != nil {
+		in, out := &in.Krb5Config, &out.Krb5Config
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperSASLOptions.
+func (in *ZookeeperSASLOptions) DeepCopy() *ZookeeperSASLOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperSASLOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperServiceRef) DeepCopyInto(out *ZookeeperServiceRef) {
+	*out = *in
+	if in.AllACL != nil {
+		in, out := &in.AllACL, &out.AllACL
+		*out = new(ZookeeperACL)
+		**out = **in
+	}
+	if in.ReadOnlyACL != nil {
+		in, out := &in.ReadOnlyACL, &out.ReadOnlyACL
+		*out = new(ZookeeperACL)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperServiceRef.
+func (in *ZookeeperServiceRef) DeepCopy() *ZookeeperServiceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperServiceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZookeeperSpec) DeepCopyInto(out *ZookeeperSpec) {
 	*out = *in
@@ -1543,3 +3657,38 @@ func (in *ZookeeperSpec) DeepCopy() *ZookeeperSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperTLSOptions) DeepCopyInto(out *ZookeeperTLSOptions) {
+	*out = *in
+	if in.TrustStoreSecret != nil {
+		in, out := &in.TrustStoreSecret, &out.TrustStoreSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustStorePasswordSecret != nil {
+		in, out := &in.TrustStorePasswordSecret, &out.TrustStorePasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyStoreSecret != nil {
+		in, out := &in.KeyStoreSecret, &out.KeyStoreSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyStorePasswordSecret != nil {
+		in, out := &in.KeyStorePasswordSecret, &out.KeyStorePasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperTLSOptions.
+func (in *ZookeeperTLSOptions) DeepCopy() *ZookeeperTLSOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperTLSOptions)
+	in.DeepCopyInto(out)
+	return out
+}
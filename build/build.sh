@@ -32,7 +32,18 @@ export CGO_ENABLED=0
 export GOARCH="${ARCH}"
 export GOOS="${GOOS:-}"
 
+EXTRA_LDFLAGS=""
+BUILD_TAGS=""
+# FIPS builds use the BoringCrypto module, which requires cgo. Link statically so that the binary still runs on distroless/static.
+if [ "${FIPS:-false}" = "true" ]; then
+    export GOEXPERIMENT=boringcrypto
+    export CGO_ENABLED=1
+    EXTRA_LDFLAGS="-linkmode=external -extldflags '-static'"
+    BUILD_TAGS="netgo,osusergo"
+fi
+
 go build \
-    -ldflags "-X 'github.com/apache/solr-operator/version.GitSHA=${GIT_SHA}' -X 'github.com/apache/solr-operator/version.BuildTime=$(date)'" \
+    -tags "${BUILD_TAGS}" \
+    -ldflags "-X 'github.com/apache/solr-operator/version.GitSHA=${GIT_SHA}' -X 'github.com/apache/solr-operator/version.BuildTime=$(date)' ${EXTRA_LDFLAGS}" \
     -o "./bin/${BIN}" \
     .
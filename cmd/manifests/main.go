@@ -0,0 +1,505 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// The manifests command prints all resources needed to install the Solr Operator as plain YAML, for users that cannot use Helm.
+// The CRDs, RBAC rules and webhook configuration are embedded from the files generated by "make manifests", so they always match the Go API types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/apache/solr-operator/config"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/apache/solr-operator/version"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	name             string
+	namespace        string
+	watchNamespaces  string
+	image            string
+	useZookeeperCRD  bool
+	includeSolrCRDs  bool
+	includeZkCRD     bool
+	createNamespace  bool
+	imagePullPolicy  string
+	operatorReplicas int
+	enableWebhooks   bool
+)
+
+const (
+	// The port that the webhook server of the operator listens on, and the directory it reads its serving certificate from
+	webhookPort     = 9443
+	webhookCertsDir = "/tmp/k8s-webhook-server/serving-certs"
+)
+
+func init() {
+	fullVersion := version.Version
+	if version.VersionSuffix != "" {
+		fullVersion += "-" + version.VersionSuffix
+	}
+
+	flag.StringVar(&name, "name", "solr-operator", "The name used for the Solr Operator Deployment, ServiceAccount and RBAC resources.")
+	flag.StringVar(&namespace, "namespace", "solr-operator", "The namespace to install the Solr Operator into.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "The comma-separated list of namespaces the operator should watch. If empty (default), the operator will watch the entire cluster and use cluster-wide RBAC.")
+	flag.StringVar(&image, "image", "apache/solr-operator:"+fullVersion, "The Solr Operator image to run.")
+	flag.StringVar(&imagePullPolicy, "image-pull-policy", string(corev1.PullIfNotPresent), "The pull policy for the Solr Operator image.")
+	flag.IntVar(&operatorReplicas, "replicas", 1, "The number of Solr Operator pods to run.")
+	flag.BoolVar(&useZookeeperCRD, "zk-operator", true, "Whether the operator should use the Zookeeper Operator & ZookeeperCluster CRD to create Zookeeper clusters for SolrClouds.")
+	flag.BoolVar(&includeSolrCRDs, "crds", true, "Include the Solr CRDs in the output.")
+	flag.BoolVar(&includeZkCRD, "zk-crd", false, "Include the ZookeeperCluster CRD, used by the Zookeeper Operator, in the output.")
+	flag.BoolVar(&createNamespace, "create-namespace", true, "Include the namespace that the operator is installed into in the output.")
+	flag.BoolVar(&enableWebhooks, "webhooks", false, "Run the admission webhooks of the operator, such as the one that protects SolrClouds from deletion while backups or restores are in progress. Requires cert-manager to issue the serving certificate.")
+}
+
+func main() {
+	flag.Parse()
+
+	documents, err := generateManifests()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to generate Solr Operator manifests: %v\n", err)
+		os.Exit(1)
+	}
+	for _, document := range documents {
+		fmt.Println("---")
+		fmt.Print(strings.TrimSpace(document) + "\n")
+	}
+}
+
+func generateManifests() (documents []string, err error) {
+	var objects []runtime.Object
+	if createNamespace {
+		objects = append(objects, &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: operatorLabels()},
+		})
+	}
+
+	if includeSolrCRDs {
+		var crdFiles []string
+		if crdFiles, err = fs.Glob(config.Manifests, path.Join(config.SolrCRDsDirectory, "*.yaml")); err != nil {
+			return documents, err
+		}
+		sort.Strings(crdFiles)
+		for _, crdFile := range crdFiles {
+			if documents, err = appendEmbeddedManifest(documents, crdFile); err != nil {
+				return documents, err
+			}
+		}
+	}
+	if includeZkCRD {
+		if documents, err = appendEmbeddedManifest(documents, config.ZookeeperCRDFile); err != nil {
+			return documents, err
+		}
+	}
+
+	rbacObjects, err := generateRBAC()
+	if err != nil {
+		return documents, err
+	}
+	objects = append(objects, rbacObjects...)
+	objects = append(objects, generateDeployment())
+	if enableWebhooks {
+		var webhookObjects []runtime.Object
+		if webhookObjects, err = generateWebhooks(); err != nil {
+			return documents, err
+		}
+		objects = append(objects, webhookObjects...)
+	}
+
+	// The namespace needs to be created before everything else
+	offset := 0
+	if createNamespace {
+		var namespaceYaml string
+		if namespaceYaml, err = toYaml(objects[0]); err != nil {
+			return documents, err
+		}
+		documents = append([]string{namespaceYaml}, documents...)
+		offset = 1
+	}
+	for _, object := range objects[offset:] {
+		var objectYaml string
+		if objectYaml, err = toYaml(object); err != nil {
+			return documents, err
+		}
+		documents = append(documents, objectYaml)
+	}
+	return documents, nil
+}
+
+// appendEmbeddedManifest adds the YAML documents found in an embedded manifest file, without the license header.
+func appendEmbeddedManifest(documents []string, file string) ([]string, error) {
+	content, err := config.Manifests.ReadFile(file)
+	if err != nil {
+		return documents, err
+	}
+	for _, document := range strings.Split(string(content), "\n---\n") {
+		if hasContent(document) {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+// hasContent returns true if the given YAML document has anything other than comments.
+func hasContent(document string) bool {
+	for _, line := range strings.Split(document, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "---" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterScopedResources are the cluster-scoped resources that the operator may be granted access to.
+// Roles cannot grant access to them, so they are granted through a ClusterRole, even when the operator only watches some namespaces.
+var clusterScopedResources = map[string]bool{
+	"namespaces":                true,
+	"nodes":                     true,
+	"persistentvolumes":         true,
+	"storageclasses":            true,
+	"customresourcedefinitions": true,
+	"volumesnapshotclasses":     true,
+	"volumesnapshotcontents":    true,
+}
+
+func generateRBAC() (objects []runtime.Object, err error) {
+	operatorRules, err := readEmbeddedRules(config.OperatorRoleFile)
+	if err != nil {
+		return objects, err
+	}
+	leaderElectionRules, err := readEmbeddedRules(config.LeaderElectionRoleFile)
+	if err != nil {
+		return objects, err
+	}
+
+	objects = append(objects, &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: operatorLabels()},
+	})
+
+	roleName := name + "-role"
+	if watchNamespaces == "" {
+		objects = append(objects, clusterRoleWithBinding(roleName, operatorRules)...)
+	} else {
+		namespacedRules, clusterScopedRules := splitClusterScopedRules(operatorRules)
+		for _, watchNamespace := range splitNamespaces(watchNamespaces) {
+			objects = append(objects, roleWithBinding(roleName, watchNamespace, namespacedRules)...)
+		}
+		if len(clusterScopedRules) > 0 {
+			objects = append(objects, clusterRoleWithBinding(name+"-cluster-role", clusterScopedRules)...)
+		}
+	}
+
+	// The leader election lock is kept in the namespace that the operator runs in, which it may not watch
+	objects = append(objects, roleWithBinding(name+"-leader-election-role", namespace, leaderElectionRules)...)
+	return objects, nil
+}
+
+// readEmbeddedRules reads the rules of the Role or ClusterRole in an embedded manifest file.
+func readEmbeddedRules(file string) (rules []rbacv1.PolicyRule, err error) {
+	roleContent, err := config.Manifests.ReadFile(file)
+	if err != nil {
+		return rules, err
+	}
+	generatedRole := &rbacv1.ClusterRole{}
+	for _, document := range strings.Split(string(roleContent), "\n---\n") {
+		if hasContent(document) {
+			if err = yaml.Unmarshal([]byte(document), generatedRole); err != nil {
+				return rules, err
+			}
+		}
+	}
+	return generatedRole.Rules, nil
+}
+
+// splitClusterScopedRules separates the rules for cluster-scoped resources, including their subresources, from the rules for namespaced resources.
+func splitClusterScopedRules(rules []rbacv1.PolicyRule) (namespacedRules []rbacv1.PolicyRule, clusterScopedRules []rbacv1.PolicyRule) {
+	for _, rule := range rules {
+		var namespacedResources, clusterScopedResourceNames []string
+		for _, resource := range rule.Resources {
+			if clusterScopedResources[strings.SplitN(resource, "/", 2)[0]] {
+				clusterScopedResourceNames = append(clusterScopedResourceNames, resource)
+			} else {
+				namespacedResources = append(namespacedResources, resource)
+			}
+		}
+		if len(namespacedResources) > 0 {
+			namespacedRule := *rule.DeepCopy()
+			namespacedRule.Resources = namespacedResources
+			namespacedRules = append(namespacedRules, namespacedRule)
+		}
+		if len(clusterScopedResourceNames) > 0 {
+			clusterScopedRule := *rule.DeepCopy()
+			clusterScopedRule.Resources = clusterScopedResourceNames
+			clusterScopedRules = append(clusterScopedRules, clusterScopedRule)
+		}
+	}
+	return namespacedRules, clusterScopedRules
+}
+
+func clusterRoleWithBinding(roleName string, rules []rbacv1.PolicyRule) []runtime.Object {
+	return []runtime.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: roleName, Labels: operatorLabels()},
+			Rules:      rules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: roleName + "binding", Labels: operatorLabels()},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: roleName},
+			Subjects:   operatorSubjects(),
+		},
+	}
+}
+
+func roleWithBinding(roleName string, roleNamespace string, rules []rbacv1.PolicyRule) []runtime.Object {
+	return []runtime.Object{
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: roleNamespace, Labels: operatorLabels()},
+			Rules:      rules,
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: roleName + "binding", Namespace: roleNamespace, Labels: operatorLabels()},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: roleName},
+			Subjects:   operatorSubjects(),
+		},
+	}
+}
+
+func operatorSubjects() []rbacv1.Subject {
+	return []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}}
+}
+
+func generateDeployment() *appsv1.Deployment {
+	replicas := int32(operatorReplicas)
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	terminationGracePeriod := int64(10)
+
+	// Leader election makes sure that only one of the operator pods is active, such as during rolling updates of the Deployment
+	args := []string{fmt.Sprintf("-zk-operator=%t", useZookeeperCRD), "--leader-elect"}
+	if watchNamespaces != "" {
+		args = append(args, "--watch-namespaces="+strings.Join(splitNamespaces(watchNamespaces), ","))
+	}
+
+	var ports []corev1.ContainerPort
+	var volumeMounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+	if enableWebhooks {
+		args = append(args, "--enable-webhooks=true")
+		ports = append(ports, corev1.ContainerPort{Name: "webhook", ContainerPort: webhookPort, Protocol: corev1.ProtocolTCP})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "webhook-cert", MountPath: webhookCertsDir, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{
+			Name:         "webhook-cert",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: webhookCertSecret()}},
+		})
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    operatorLabels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: operatorLabels()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      operatorLabels(),
+					Annotations: map[string]string{"prometheus.io/scrape": "true"},
+				},
+				Spec: corev1.PodSpec{
+					SecurityContext:    &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+					ServiceAccountName: name,
+					Containers: []corev1.Container{
+						{
+							Name:            "solr-operator",
+							Image:           image,
+							ImagePullPolicy: corev1.PullPolicy(imagePullPolicy),
+							Args:            args,
+							Env: []corev1.EnvVar{
+								{
+									Name:      "POD_NAMESPACE",
+									ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+								},
+								{
+									Name:      "POD_NAME",
+									ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: &allowPrivilegeEscalation},
+							LivenessProbe: &corev1.Probe{
+								Handler:             corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8081)}},
+								InitialDelaySeconds: 15,
+								PeriodSeconds:       20,
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler:             corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/readyz", Port: intstr.FromInt(8081)}},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       10,
+							},
+							Ports:        ports,
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes:                       volumes,
+					TerminationGracePeriodSeconds: &terminationGracePeriod,
+				},
+			},
+		},
+	}
+}
+
+// generateWebhooks returns the webhook Service, the cert-manager Issuer and Certificate for its serving certificate,
+// and the generated ValidatingWebhookConfiguration, pointed at the Service, with the CA injected by cert-manager.
+func generateWebhooks() (objects []runtime.Object, err error) {
+	webhookName := name + "-webhook"
+	objects = append(objects, &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: webhookName, Namespace: namespace, Labels: operatorLabels()},
+		Spec: corev1.ServiceSpec{
+			Ports:    []corev1.ServicePort{{Name: "webhook", Port: 443, TargetPort: intstr.FromString("webhook")}},
+			Selector: operatorLabels(),
+		},
+	})
+
+	// cert-manager is not a dependency of the operator, so its resources are built without its Go types
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Issuer",
+		"metadata":   map[string]interface{}{"name": webhookName, "namespace": namespace},
+		"spec":       map[string]interface{}{"selfSigned": map[string]interface{}{}},
+	}}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": webhookName, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"secretName": webhookCertSecret(),
+			"dnsNames": []interface{}{
+				webhookName + "." + namespace + ".svc",
+				webhookName + "." + namespace + ".svc.cluster.local",
+			},
+			"issuerRef": map[string]interface{}{"kind": "Issuer", "name": webhookName},
+		},
+	}}
+	objects = append(objects, issuer, certificate)
+
+	webhookContent, err := config.Manifests.ReadFile(config.WebhookFile)
+	if err != nil {
+		return objects, err
+	}
+	for _, document := range strings.Split(string(webhookContent), "\n---\n") {
+		if !hasContent(document) {
+			continue
+		}
+		webhookConfig := &admissionv1.ValidatingWebhookConfiguration{}
+		if err = yaml.Unmarshal([]byte(document), webhookConfig); err != nil {
+			return objects, err
+		}
+		// Webhook configurations are cluster-scoped, so they are named after the installation, like in the Helm chart
+		webhookConfig.TypeMeta = metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"}
+		webhookConfig.ObjectMeta = metav1.ObjectMeta{
+			Name:        namespace + "-" + name + "-validating-webhook-configuration",
+			Labels:      operatorLabels(),
+			Annotations: map[string]string{"cert-manager.io/inject-ca-from": namespace + "/" + webhookName},
+		}
+		for i := range webhookConfig.Webhooks {
+			if service := webhookConfig.Webhooks[i].ClientConfig.Service; service != nil {
+				service.Name = webhookName
+				service.Namespace = namespace
+			}
+		}
+		objects = append(objects, webhookConfig)
+	}
+	return objects, nil
+}
+
+func webhookCertSecret() string {
+	return name + "-webhook-cert"
+}
+
+func operatorLabels() map[string]string {
+	return map[string]string{util.OperatorPodLabelKey: util.OperatorPodLabelValue}
+}
+
+func splitNamespaces(namespaces string) (split []string) {
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			split = append(split, ns)
+		}
+	}
+	return split
+}
+
+// toYaml converts the object to YAML, without the empty fields (e.g. status and creationTimestamp) that Kubernetes types always serialize.
+func toYaml(object runtime.Object) (string, error) {
+	// Unstructured objects are built by hand, so empty fields in them, such as "selfSigned: {}", are intentional
+	if u, isUnstructured := object.(*unstructured.Unstructured); isUnstructured {
+		out, err := yaml.Marshal(u.Object)
+		return string(out), err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return "", err
+	}
+	delete(content, "status")
+	removeEmptyFields(content)
+	out, err := yaml.Marshal(content)
+	return string(out), err
+}
+
+func removeEmptyFields(content map[string]interface{}) {
+	for key, value := range content {
+		switch v := value.(type) {
+		case nil:
+			delete(content, key)
+		case map[string]interface{}:
+			if removeEmptyFields(v); len(v) == 0 {
+				delete(content, key)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if itemMap, isMap := item.(map[string]interface{}); isMap {
+					removeEmptyFields(itemMap)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,507 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// The support-bundle command gathers everything needed to investigate a problem with the Solr Operator into a single archive,
+// that can be attached when filing an issue: the Solr resources of a namespace, the Kubernetes resources created for each SolrCloud,
+// the Solr cluster status, the recent Events of the namespace and the logs of the Solr Operator.
+// Secrets, as well as environment variables and system properties that look like credentials, are redacted.
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	zk_api "github.com/apache/solr-operator/controllers/zk_api"
+	"github.com/apache/solr-operator/version"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	redacted = "REDACTED"
+)
+
+var (
+	namespace         string
+	solrCloudName     string
+	operatorNamespace string
+	output            string
+	logLines          int64
+	requestTimeout    time.Duration
+
+	scheme = runtime.NewScheme()
+
+	// Environment variables with names like these are likely to contain credentials
+	sensitiveNamePattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|apikey|api_key|access_key|private_key)`)
+	// System properties with names like these, such as "-Dsolr.ssl.key.store.password=...", are likely to contain credentials
+	sensitiveSystemPropertyPattern = regexp.MustCompile(`(?i)(-D[^=\s]*(password|passwd|secret|token|credential)[^=\s]*=)(\S+)`)
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(solrv1beta1.AddToScheme(scheme))
+	utilruntime.Must(zk_api.AddToScheme(scheme))
+
+	flag.StringVar(&namespace, "namespace", "default", "The namespace of the Solr resources to gather.")
+	flag.StringVar(&solrCloudName, "solrcloud", "", "Only gather the given SolrCloud, instead of all SolrClouds in the namespace.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", "", "The namespace that the Solr Operator runs in. If empty (default), Solr Operator pods are looked for in all namespaces.")
+	flag.StringVar(&output, "output", "", "The file to write the archive to. Defaults to solr-support-bundle-<timestamp>.tar.gz in the current directory.")
+	flag.Int64Var(&logLines, "log-lines", 2000, "The number of the most recent log lines to gather from each Solr Operator pod.")
+	flag.DurationVar(&requestTimeout, "request-timeout", time.Second*30, "The timeout for requests to Solr.")
+}
+
+func main() {
+	flag.Parse()
+
+	if output == "" {
+		output = fmt.Sprintf("solr-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to find a Kubernetes configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	b := &bundle{files: map[string][]byte{}}
+	if err = b.gather(context.Background(), config); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to gather the support bundle: %v\n", err)
+		os.Exit(1)
+	}
+	if err = b.write(output); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write the support bundle to %s: %v\n", output, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote the support bundle to %s\n", output)
+	if len(b.errors) > 0 {
+		fmt.Printf("Some information could not be gathered, see errors.txt in the bundle:\n  %s\n", strings.Join(b.errors, "\n  "))
+	}
+	fmt.Println("Please review the bundle for sensitive information before sharing it.")
+}
+
+// bundle holds the files of the support bundle, keyed by their path within the archive.
+// Information that cannot be gathered is recorded as an error, rather than failing the whole bundle,
+// since a support bundle is most needed when things are broken.
+type bundle struct {
+	client    client.Client
+	clientset *kubernetes.Clientset
+	config    *rest.Config
+
+	files  map[string][]byte
+	errors []string
+}
+
+func (b *bundle) gather(ctx context.Context, config *rest.Config) (err error) {
+	b.config = config
+	if b.client, err = client.New(config, client.Options{Scheme: scheme}); err != nil {
+		return err
+	}
+	if b.clientset, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+
+	solrClouds := &solrv1beta1.SolrCloudList{}
+	if err = b.client.List(ctx, solrClouds, client.InNamespace(namespace)); err != nil {
+		// Without access to the SolrClouds, there is nothing useful to gather
+		return err
+	}
+	found := false
+	for i := range solrClouds.Items {
+		solrCloud := &solrClouds.Items[i]
+		if solrCloudName == "" || solrCloud.Name == solrCloudName {
+			found = true
+			b.gatherSolrCloud(ctx, solrCloud)
+		}
+	}
+	if solrCloudName != "" && !found {
+		b.addError("SolrCloud %s/%s does not exist", namespace, solrCloudName)
+	}
+
+	b.gatherSolrResources(ctx)
+	b.gatherEvents(ctx)
+	b.gatherOperator(ctx)
+
+	b.files["bundle.txt"] = []byte(fmt.Sprintf("Created: %s\nNamespace: %s\nSolrCloud: %s\nSupport bundle version: %s\n",
+		time.Now().UTC().Format(time.RFC3339), namespace, solrCloudName, fullVersion()))
+	if len(b.errors) > 0 {
+		b.files["errors.txt"] = []byte(strings.Join(b.errors, "\n") + "\n")
+	}
+	return nil
+}
+
+// gatherSolrCloud gathers the SolrCloud, the Kubernetes resources that the Solr Operator created for it, and its Solr cluster status
+func (b *bundle) gatherSolrCloud(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) {
+	dir := path.Join("solrclouds", solrCloud.Name)
+	b.addObjects(path.Join(dir, "solrcloud.yaml"), solrCloud)
+
+	selector := client.MatchingLabels(solrCloud.SharedLabels())
+	children := map[string]client.ObjectList{
+		"statefulsets.yaml":           &appsv1.StatefulSetList{},
+		"pods.yaml":                   &corev1.PodList{},
+		"services.yaml":               &corev1.ServiceList{},
+		"configmaps.yaml":             &corev1.ConfigMapList{},
+		"secrets.yaml":                &corev1.SecretList{},
+		"persistentvolumeclaims.yaml": &corev1.PersistentVolumeClaimList{},
+		"ingresses.yaml":              &netv1.IngressList{},
+	}
+	for file, list := range children {
+		if err := b.client.List(ctx, list, client.InNamespace(solrCloud.Namespace), selector); err != nil {
+			b.addError("could not list the %s of SolrCloud %s: %s", strings.TrimSuffix(file, ".yaml"), solrCloud.Name, err)
+			continue
+		}
+		b.addObjects(path.Join(dir, file), list)
+	}
+
+	if zkName := solrCloud.Status.Resources.ProvidedZookeeper; zkName != "" {
+		zkCluster := &zk_api.ZookeeperCluster{}
+		if err := b.client.Get(ctx, types.NamespacedName{Namespace: solrCloud.Namespace, Name: zkName}, zkCluster); err != nil {
+			b.addError("could not get the ZookeeperCluster %s of SolrCloud %s: %s", zkName, solrCloud.Name, err)
+		} else {
+			b.addObjects(path.Join(dir, "zookeepercluster.yaml"), zkCluster)
+		}
+	}
+
+	if clusterStatus, err := b.fetchClusterStatus(ctx, solrCloud); err != nil {
+		b.addError("could not fetch the cluster status of SolrCloud %s: %s", solrCloud.Name, err)
+	} else {
+		b.files[path.Join(dir, "clusterstatus.json")] = clusterStatus
+	}
+}
+
+// gatherSolrResources gathers the Solr resources, other than SolrClouds, in the namespace
+func (b *bundle) gatherSolrResources(ctx context.Context) {
+	resources := map[string]client.ObjectList{
+		"solrbackups.yaml":             &solrv1beta1.SolrBackupList{},
+		"solrrestores.yaml":            &solrv1beta1.SolrRestoreList{},
+		"solrconfigsets.yaml":          &solrv1beta1.SolrConfigSetList{},
+		"solrprometheusexporters.yaml": &solrv1beta1.SolrPrometheusExporterList{},
+		"solrindexingbridges.yaml":     &solrv1beta1.SolrIndexingBridgeList{},
+		"solrstreamingdaemons.yaml":    &solrv1beta1.SolrStreamingDaemonList{},
+		"solroperatorconfigs.yaml":     &solrv1beta1.SolrOperatorConfigList{},
+	}
+	for file, list := range resources {
+		if err := b.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			b.addError("could not list the %s: %s", strings.TrimSuffix(file, ".yaml"), err)
+			continue
+		}
+		b.addObjects(path.Join("resources", file), list)
+	}
+}
+
+// gatherEvents gathers the Events of the namespace, oldest first. Kubernetes only keeps Events for a short time, an hour by default.
+func (b *bundle) gatherEvents(ctx context.Context) {
+	events := &corev1.EventList{}
+	if err := b.client.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		b.addError("could not list the events: %s", err)
+		return
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return events.Items[i].LastTimestamp.Before(&events.Items[j].LastTimestamp)
+	})
+	b.addObjects("events.yaml", events)
+}
+
+// gatherOperator gathers the Solr Operator pods and their recent logs
+func (b *bundle) gatherOperator(ctx context.Context) {
+	operatorPods := &corev1.PodList{}
+	if err := b.client.List(ctx, operatorPods, client.InNamespace(operatorNamespace), client.MatchingLabels{util.OperatorPodLabelKey: util.OperatorPodLabelValue}); err != nil {
+		b.addError("could not list the Solr Operator pods: %s", err)
+		return
+	}
+	if len(operatorPods.Items) == 0 {
+		b.addError("no Solr Operator pods were found, with the label %s=%s", util.OperatorPodLabelKey, util.OperatorPodLabelValue)
+		return
+	}
+	b.addObjects(path.Join("operator", "pods.yaml"), operatorPods)
+
+	for _, pod := range operatorPods.Items {
+		for _, container := range pod.Spec.Containers {
+			logs, err := b.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: container.Name,
+				TailLines: &logLines,
+			}).DoRaw(ctx)
+			if err != nil {
+				b.addError("could not fetch the logs of Solr Operator pod %s/%s: %s", pod.Namespace, pod.Name, err)
+				continue
+			}
+			b.files[path.Join("operator", fmt.Sprintf("%s_%s_%s.log", pod.Namespace, pod.Name, container.Name))] = logs
+		}
+	}
+}
+
+// fetchClusterStatus fetches the cluster status from a ready Solr pod, through a port-forward,
+// so that the bundle can be gathered from outside of the Kubernetes cluster.
+func (b *bundle) fetchClusterStatus(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) ([]byte, error) {
+	readyNode := ""
+	for _, node := range solrCloud.Status.SolrNodes {
+		if node.Ready {
+			readyNode = node.Name
+			break
+		}
+	}
+	if readyNode == "" {
+		return nil, fmt.Errorf("no Solr pods are ready")
+	}
+
+	var authorization string
+	if solrCloud.UsesBasicAuth() {
+		basicAuthSecret := &corev1.Secret{}
+		if err := b.client.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+			return nil, err
+		}
+		authorization = util.BasicAuthHeader(basicAuthSecret)
+	}
+
+	localPort, stop, err := b.portForward(solrCloud.Namespace, readyNode, solrCloud.Spec.SolrAddressability.PodPort)
+	if err != nil {
+		return nil, err
+	}
+	defer close(stop)
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	clusterStatusUrl := fmt.Sprintf("%s://localhost:%d/solr/admin/collections?action=CLUSTERSTATUS&wt=json", solrCloud.UrlScheme(false), localPort)
+	req, err := http.NewRequestWithContext(ctx, "GET", clusterStatusUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	// The certificate of the Solr pod cannot match "localhost", and only the cluster status is read through this connection
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err == nil && resp.StatusCode != 200 {
+		err = fmt.Errorf("received bad response code of %d from solr with response: %s", resp.StatusCode, string(body))
+	}
+	return body, err
+}
+
+// portForward forwards a random local port to the given port of a pod. Closing the returned channel stops the port-forward.
+func (b *bundle) portForward(podNamespace string, podName string, podPort int) (localPort uint16, stop chan struct{}, err error) {
+	transport, upgrader, err := spdy.RoundTripperFor(b.config)
+	if err != nil {
+		return 0, nil, err
+	}
+	req := b.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(podNamespace).
+		Name(podName).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	stop = make(chan struct{})
+	ready := make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("0:%d", podPort)}, stop, ready, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		forwardErr <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err = <-forwardErr:
+		return 0, nil, fmt.Errorf("could not port-forward to pod %s: %w", podName, err)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		close(stop)
+		return 0, nil, err
+	}
+	return ports[0].Local, stop, nil
+}
+
+// addObjects adds the given object, or the items of the given list, to the bundle as YAML documents, with credentials redacted
+func (b *bundle) addObjects(file string, obj runtime.Object) {
+	objects := []runtime.Object{obj}
+	if meta.IsListType(obj) {
+		var err error
+		if objects, err = meta.ExtractList(obj); err != nil {
+			b.addError("could not read the objects for %s: %s", file, err)
+			return
+		}
+	}
+
+	var documents []string
+	for _, object := range objects {
+		document, err := redactedYaml(object)
+		if err != nil {
+			b.addError("could not convert an object to YAML for %s: %s", file, err)
+			continue
+		}
+		documents = append(documents, document)
+	}
+	b.files[file] = []byte(strings.Join(documents, "---\n"))
+}
+
+func (b *bundle) addError(format string, args ...interface{}) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// write writes the files of the bundle to a gzipped tar archive, in a directory named after the archive
+func (b *bundle) write(archivePath string) (err error) {
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	rootDir := strings.TrimSuffix(path.Base(archivePath), ".tar.gz")
+	fileNames := make([]string, 0, len(b.files))
+	for name := range b.files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+
+	now := time.Now()
+	for _, name := range fileNames {
+		content := b.files[name]
+		header := &tar.Header{
+			Name:    path.Join(rootDir, name),
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err = tarWriter.Write(content); err != nil {
+			return err
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// redactedYaml converts the object to YAML, without its managed fields, and with credentials redacted
+func redactedYaml(object runtime.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(object, scheme)
+	if err != nil {
+		return "", err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return "", err
+	}
+	content["apiVersion"], content["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	if metadata, hasMetadata := content["metadata"].(map[string]interface{}); hasMetadata {
+		delete(metadata, "managedFields")
+	}
+
+	if gvk.Kind == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, hasData := content[field].(map[string]interface{}); hasData {
+				for key := range data {
+					data[key] = redacted
+				}
+			}
+		}
+		// The last applied configuration contains the data of the Secret
+		if metadata, hasMetadata := content["metadata"].(map[string]interface{}); hasMetadata {
+			if annotations, hasAnnotations := metadata["annotations"].(map[string]interface{}); hasAnnotations {
+				if _, hasLastApplied := annotations[corev1.LastAppliedConfigAnnotation]; hasLastApplied {
+					annotations[corev1.LastAppliedConfigAnnotation] = redacted
+				}
+			}
+		}
+	}
+	redactCredentials(content)
+
+	out, err := yaml.Marshal(content)
+	return string(out), err
+}
+
+// redactCredentials redacts the values of environment variables whose names look like credentials,
+// as well as the values of system properties that look like credentials, wherever they occur.
+func redactCredentials(content interface{}) {
+	switch v := content.(type) {
+	case map[string]interface{}:
+		if name, hasName := v["name"].(string); hasName && sensitiveNamePattern.MatchString(name) {
+			if _, hasValue := v["value"].(string); hasValue {
+				v["value"] = redacted
+			}
+		}
+		for key, value := range v {
+			if s, isString := value.(string); isString {
+				v[key] = sensitiveSystemPropertyPattern.ReplaceAllString(s, "${1}"+redacted)
+			} else {
+				redactCredentials(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			if s, isString := value.(string); isString {
+				v[i] = sensitiveSystemPropertyPattern.ReplaceAllString(s, "${1}"+redacted)
+			} else {
+				redactCredentials(value)
+			}
+		}
+	}
+}
+
+func fullVersion() string {
+	if version.VersionSuffix != "" {
+		return version.Version + "-" + version.VersionSuffix
+	}
+	return version.Version
+}
@@ -0,0 +1,35 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config gives Go access to the manifests generated from the Solr Operator's API types by "make manifests".
+package config
+
+import "embed"
+
+const (
+	SolrCRDsDirectory = "crd/bases"
+	ZookeeperCRDFile  = "dependencies/zookeeper_cluster_crd.yaml"
+	OperatorRoleFile  = "rbac/role.yaml"
+
+	LeaderElectionRoleFile = "rbac/leader_election_role.yaml"
+	WebhookFile            = "webhook/manifests.yaml"
+)
+
+// Manifests contains the generated Solr CRDs, operator RBAC rules and webhook configuration, the rules needed for leader election, as well as the ZookeeperCluster CRD dependency.
+//
+//go:embed crd/bases/*.yaml dependencies/zookeeper_cluster_crd.yaml rbac/role.yaml rbac/leader_election_role.yaml webhook/manifests.yaml
+var Manifests embed.FS
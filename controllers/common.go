@@ -18,6 +18,14 @@
 package controllers
 
 import (
+	"context"
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"time"
 )
@@ -28,3 +36,54 @@ func updateRequeueAfter(requeueOrNot *reconcile.Result, newWait time.Duration) {
 		requeueOrNot.RequeueAfter = newWait
 	}
 }
+
+// Fetch the SolrOperatorConfig for the namespace of a Solr resource, and determine whether the resource should be reconciled under it.
+// Resources that are not selected should be ignored, since they are managed by a different Solr Operator.
+func getSolrOperatorConfig(ctx context.Context, reader client.Reader, obj client.Object) (config *solrv1beta1.SolrOperatorConfig, selected bool, err error) {
+	if config, err = util.GetSolrOperatorConfig(ctx, reader, obj.GetNamespace()); err != nil {
+		return nil, false, err
+	}
+	if selected, err = config.Selects(obj.GetLabels()); err != nil {
+		return config, false, util.TerminalErrorf(util.InvalidSpecReason, "invalid resourceSelector in SolrOperatorConfig %s/%s: %s", obj.GetNamespace(), util.SolrOperatorConfigName(), err)
+	}
+	return config, selected, nil
+}
+
+// reportTerminalError emits a Warning event on the resource for a terminal error, instead of returning it, so that the resource is not requeued.
+// The resource is reconciled again once it, or the resources it references, change.
+// Errors that are not terminal are returned as-is, so that they are retried.
+func reportTerminalError(recorder record.EventRecorder, obj client.Object, err error, logger logr.Logger) error {
+	terminalErr, isTerminal := util.AsTerminalError(err)
+	if !isTerminal {
+		return err
+	}
+	logger.Error(terminalErr, "The resource is misconfigured, it will be reconciled again once it or the resources it references change", "reason", terminalErr.Reason)
+	recorder.Event(obj, corev1.EventTypeWarning, terminalErr.Reason, terminalErr.Error())
+	return nil
+}
+
+// Resolve the connection information for a referenced Solr instance, looking up the SolrCloud status if it is referenced by name
+func getSolrConnectionInfo(ctx context.Context, reader client.Reader, solrReference solrv1beta1.SolrReference, namespace string) (solrConnectionInfo util.SolrConnectionInfo, err error) {
+	solrConnectionInfo = util.SolrConnectionInfo{}
+
+	if solrReference.Standalone != nil {
+		solrConnectionInfo.StandaloneAddress = solrReference.Standalone.Address
+	}
+	if solrReference.Cloud != nil {
+		cloudRef := solrReference.Cloud
+		if cloudRef.ZookeeperConnectionInfo != nil {
+			solrConnectionInfo.CloudZkConnnectionInfo = cloudRef.ZookeeperConnectionInfo
+		} else if cloudRef.Name != "" {
+			solrCloud := &solrv1beta1.SolrCloud{}
+			solrNamespace := cloudRef.Namespace
+			if solrNamespace == "" {
+				solrNamespace = namespace
+			}
+			err = reader.Get(ctx, types.NamespacedName{Name: cloudRef.Name, Namespace: solrNamespace}, solrCloud)
+			if err == nil {
+				solrConnectionInfo.CloudZkConnnectionInfo = &solrCloud.Status.ZookeeperConnectionInfo
+			}
+		}
+	}
+	return solrConnectionInfo, err
+}
@@ -29,6 +29,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -134,6 +135,122 @@ func expectSolrPrometheusExporterWithConsistentChecks(ctx context.Context, solrP
 	return foundSolrPrometheusExporter
 }
 
+func expectSolrIndexingBridge(ctx context.Context, solrIndexingBridge *solrv1beta1.SolrIndexingBridge, additionalOffset ...int) *solrv1beta1.SolrIndexingBridge {
+	return expectSolrIndexingBridgeWithChecks(ctx, solrIndexingBridge, nil, resolveOffset(additionalOffset))
+}
+
+func expectSolrIndexingBridgeWithChecks(ctx context.Context, solrIndexingBridge *solrv1beta1.SolrIndexingBridge, additionalChecks func(Gomega, *solrv1beta1.SolrIndexingBridge), additionalOffset ...int) *solrv1beta1.SolrIndexingBridge {
+	foundSolrIndexingBridge := &solrv1beta1.SolrIndexingBridge{}
+	EventuallyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrIndexingBridge, solrIndexingBridge.Name), foundSolrIndexingBridge)).To(Succeed(), "Expected SolrIndexingBridge does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrIndexingBridge)
+		}
+	}).Should(Succeed())
+
+	return foundSolrIndexingBridge
+}
+
+func expectSolrStreamingDaemon(ctx context.Context, solrStreamingDaemon *solrv1beta1.SolrStreamingDaemon, additionalOffset ...int) *solrv1beta1.SolrStreamingDaemon {
+	return expectSolrStreamingDaemonWithChecks(ctx, solrStreamingDaemon, nil, resolveOffset(additionalOffset))
+}
+
+func expectSolrStreamingDaemonWithChecks(ctx context.Context, solrStreamingDaemon *solrv1beta1.SolrStreamingDaemon, additionalChecks func(Gomega, *solrv1beta1.SolrStreamingDaemon), additionalOffset ...int) *solrv1beta1.SolrStreamingDaemon {
+	foundSolrStreamingDaemon := &solrv1beta1.SolrStreamingDaemon{}
+	EventuallyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrStreamingDaemon, solrStreamingDaemon.Name), foundSolrStreamingDaemon)).To(Succeed(), "Expected SolrStreamingDaemon does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrStreamingDaemon)
+		}
+	}).Should(Succeed())
+
+	return foundSolrStreamingDaemon
+}
+
+func expectSolrStreamingDaemonWithConsistentChecks(ctx context.Context, solrStreamingDaemon *solrv1beta1.SolrStreamingDaemon, additionalChecks func(Gomega, *solrv1beta1.SolrStreamingDaemon), additionalOffset ...int) *solrv1beta1.SolrStreamingDaemon {
+	foundSolrStreamingDaemon := &solrv1beta1.SolrStreamingDaemon{}
+	ConsistentlyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrStreamingDaemon, solrStreamingDaemon.Name), foundSolrStreamingDaemon)).To(Succeed(), "Expected SolrStreamingDaemon does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrStreamingDaemon)
+		}
+	}).Should(Succeed())
+
+	return foundSolrStreamingDaemon
+}
+
+func expectSolrConfigSet(ctx context.Context, solrConfigSet *solrv1beta1.SolrConfigSet, additionalOffset ...int) *solrv1beta1.SolrConfigSet {
+	return expectSolrConfigSetWithChecks(ctx, solrConfigSet, nil, resolveOffset(additionalOffset))
+}
+
+func expectSolrConfigSetWithChecks(ctx context.Context, solrConfigSet *solrv1beta1.SolrConfigSet, additionalChecks func(Gomega, *solrv1beta1.SolrConfigSet), additionalOffset ...int) *solrv1beta1.SolrConfigSet {
+	foundSolrConfigSet := &solrv1beta1.SolrConfigSet{}
+	EventuallyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrConfigSet, solrConfigSet.Name), foundSolrConfigSet)).To(Succeed(), "Expected SolrConfigSet does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrConfigSet)
+		}
+	}).Should(Succeed())
+
+	return foundSolrConfigSet
+}
+
+func expectSolrRestore(ctx context.Context, solrRestore *solrv1beta1.SolrRestore, additionalOffset ...int) *solrv1beta1.SolrRestore {
+	return expectSolrRestoreWithChecks(ctx, solrRestore, nil, resolveOffset(additionalOffset))
+}
+
+func expectSolrRestoreWithChecks(ctx context.Context, solrRestore *solrv1beta1.SolrRestore, additionalChecks func(Gomega, *solrv1beta1.SolrRestore), additionalOffset ...int) *solrv1beta1.SolrRestore {
+	foundSolrRestore := &solrv1beta1.SolrRestore{}
+	EventuallyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrRestore, solrRestore.Name), foundSolrRestore)).To(Succeed(), "Expected SolrRestore does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrRestore)
+		}
+	}).Should(Succeed())
+
+	return foundSolrRestore
+}
+
+// expectSolrRestoreCondition waits for the Complete condition of the SolrRestore to be false, with the given reason and a message containing the given text
+func expectSolrRestoreCondition(ctx context.Context, solrRestore *solrv1beta1.SolrRestore, reason string, message string, additionalOffset ...int) *solrv1beta1.SolrRestore {
+	return expectSolrRestoreWithChecks(ctx, solrRestore, func(g Gomega, found *solrv1beta1.SolrRestore) {
+		condition := meta.FindStatusCondition(found.Status.Conditions, solrv1beta1.SolrRestoreComplete)
+		g.Expect(condition).ToNot(BeNil(), "The SolrRestore should have a Complete condition")
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse), "The SolrRestore should not be complete")
+		g.Expect(condition.Reason).To(Equal(reason), "Wrong reason for the Complete condition")
+		g.Expect(condition.Message).To(ContainSubstring(message), "Wrong message for the Complete condition")
+		g.Expect(found.Status.Finished).To(BeFalse(), "The SolrRestore should not be finished")
+	}, resolveOffset(additionalOffset))
+}
+
+func expectSolrMigration(ctx context.Context, solrMigration *solrv1beta1.SolrMigration, additionalOffset ...int) *solrv1beta1.SolrMigration {
+	return expectSolrMigrationWithChecks(ctx, solrMigration, nil, resolveOffset(additionalOffset))
+}
+
+func expectSolrMigrationWithChecks(ctx context.Context, solrMigration *solrv1beta1.SolrMigration, additionalChecks func(Gomega, *solrv1beta1.SolrMigration), additionalOffset ...int) *solrv1beta1.SolrMigration {
+	foundSolrMigration := &solrv1beta1.SolrMigration{}
+	EventuallyWithOffset(resolveOffset(additionalOffset), func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, resourceKey(solrMigration, solrMigration.Name), foundSolrMigration)).To(Succeed(), "Expected SolrMigration does not exist")
+		if additionalChecks != nil {
+			additionalChecks(g, foundSolrMigration)
+		}
+	}).Should(Succeed())
+
+	return foundSolrMigration
+}
+
+// expectSolrMigrationCondition waits for the Complete condition of the SolrMigration to be false, with the given reason and a message containing the given text
+func expectSolrMigrationCondition(ctx context.Context, solrMigration *solrv1beta1.SolrMigration, reason string, message string, additionalOffset ...int) *solrv1beta1.SolrMigration {
+	return expectSolrMigrationWithChecks(ctx, solrMigration, func(g Gomega, found *solrv1beta1.SolrMigration) {
+		condition := meta.FindStatusCondition(found.Status.Conditions, solrv1beta1.SolrMigrationCompleteCondition)
+		g.Expect(condition).ToNot(BeNil(), "The SolrMigration should have a Complete condition")
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse), "The SolrMigration should not be complete")
+		g.Expect(condition.Reason).To(Equal(reason), "Wrong reason for the Complete condition")
+		g.Expect(condition.Message).To(ContainSubstring(message), "Wrong message for the Complete condition")
+		g.Expect(found.Status.Finished).To(BeFalse(), "The SolrMigration should not be finished")
+	}, resolveOffset(additionalOffset))
+}
+
 func expectSecret(ctx context.Context, parentResource client.Object, secretName string, additionalOffset ...int) *corev1.Secret {
 	return expectSecretWithChecks(ctx, parentResource, secretName, nil, resolveOffset(additionalOffset))
 }
@@ -635,6 +752,8 @@ func cleanupTest(ctx context.Context, parentResource client.Object) {
 	cleanupObjects := []client.Object{
 		// Solr Operator CRDs, modify this list whenever CRDs are added/deleted
 		&solrv1beta1.SolrCloud{}, &solrv1beta1.SolrBackup{}, &solrv1beta1.SolrPrometheusExporter{},
+		&solrv1beta1.SolrIndexingBridge{}, &solrv1beta1.SolrStreamingDaemon{}, &solrv1beta1.SolrConfigSet{},
+		&solrv1beta1.SolrRestore{}, &solrv1beta1.SolrMigration{},
 		&zk_api.ZookeeperCluster{},
 
 		// All dependent Kubernetes types, in order of dependence (deployment then replicaSet then pod)
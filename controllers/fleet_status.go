@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apache/solr-operator/controllers/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// FleetStatusPath is the path that the fleet status is served on, alongside the metrics of the Solr Operator
+const FleetStatusPath = "/fleet-status"
+
+// FleetStatusHandler serves a JSON summary of all SolrClouds that this Solr Operator manages, across all watched namespaces.
+// The summary can be limited to a single namespace through the "namespace" query parameter.
+type FleetStatusHandler struct {
+	Reader client.Reader
+}
+
+func (h *FleetStatusHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.FromContext(ctx).WithName("FleetStatus")
+
+	var listOpts []client.ListOption
+	if namespace := req.URL.Query().Get("namespace"); namespace != "" {
+		listOpts = append(listOpts, client.InNamespace(namespace))
+	}
+
+	solrClouds := &solrv1beta1.SolrCloudList{}
+	if err := h.Reader.List(ctx, solrClouds, listOpts...); err != nil {
+		logger.Error(err, "Could not list SolrClouds for the fleet status")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	backups := &solrv1beta1.SolrBackupList{}
+	if err := h.Reader.List(ctx, backups, listOpts...); err != nil {
+		logger.Error(err, "Could not list SolrBackups for the fleet status")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// SolrClouds that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+	var managedClouds []solrv1beta1.SolrCloud
+	for _, solrCloud := range solrClouds.Items {
+		if _, selected, err := getSolrOperatorConfig(ctx, h.Reader, &solrCloud); err != nil {
+			logger.Error(err, "Could not determine whether the SolrCloud is managed by this Solr Operator", "namespace", solrCloud.Namespace, "solrCloud", solrCloud.Name)
+		} else if selected {
+			managedClouds = append(managedClouds, solrCloud)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(util.GenerateFleetStatus(managedClouds, backups.Items, metav1.Now())); err != nil {
+		logger.Error(err, "Could not write the fleet status")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/apache/solr-operator/controllers/util"
@@ -28,10 +29,12 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -44,16 +47,21 @@ import (
 // SolrBackupReconciler reconciles a SolrBackup object
 type SolrBackupReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	config *rest.Config
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	config   *rest.Config
 }
 
 //+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;create
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotcontents,verbs=get
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
-//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get;update
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups/finalizers,verbs=update
@@ -73,12 +81,21 @@ func (r *SolrBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			util.RemoveSolrBackupMetrics(req.Namespace, req.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the req.
 		return reconcile.Result{}, err
 	}
 
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, backup); err != nil || !selected {
+		// SolrBackups that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return reconcile.Result{}, r.reportTerminalError(ctx, backup, err, logger)
+	}
+
+	// The metrics of backups that finished before the Solr Operator started are not recorded otherwise
+	util.SeedSolrBackupMetrics(backup)
+
 	oldStatus := backup.Status.DeepCopy()
 
 	changed := backup.WithDefaults()
@@ -90,9 +107,55 @@ func (r *SolrBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	// When working with the collection backups, auto-requeue after 5 seconds
+	if backup.Spec.VolumeSnapshot != nil {
+		if handled, err := r.reconcileReadOnlyCollectionsFinalizer(ctx, backup, logger); handled || err != nil {
+			return reconcile.Result{Requeue: backup.ObjectMeta.DeletionTimestamp.IsZero()}, err
+		}
+		requeue, err := r.reconcileVolumeSnapshotBackup(ctx, backup, logger)
+		if err != nil {
+			logger.Error(err, "Error while taking VolumeSnapshot backup")
+		}
+		err = nil
+		if !reflect.DeepEqual(oldStatus, &backup.Status) {
+			logger.Info("Updating status for solr-backup")
+			err = r.Status().Update(ctx, backup)
+		}
+		if err == nil && backup.Status.Finished && !oldStatus.Finished {
+			recordFinishedBackup(backup)
+			r.recordBackupInSolrCloudHistory(ctx, backup, logger)
+		}
+		if requeue {
+			return reconcile.Result{RequeueAfter: util.RequeueAfter(util.RequeueBackupStatus)}, err
+		}
+		return reconcile.Result{}, err
+	}
+
+	if err := validateSolrBackup(backup); err != nil {
+		return reconcile.Result{}, r.reportTerminalError(ctx, backup, err, logger)
+	}
+	untilNextBackup, err := reconcileBackupRecurrence(backup, logger)
+	if err != nil {
+		// An invalid schedule cannot be fixed until the SolrBackup is changed
+		return reconcile.Result{}, r.reportTerminalError(ctx, backup, err, logger)
+	}
+	meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{
+		Type:               solrv1beta1.SolrBackupConfigurationValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Valid",
+		ObservedGeneration: backup.Generation,
+	})
+
+	if untilNextBackup > 0 {
+		if !reflect.DeepEqual(oldStatus, &backup.Status) {
+			logger.Info("Updating status for solr-backup")
+			err = r.Status().Update(ctx, backup)
+		}
+		return reconcile.Result{RequeueAfter: untilNextBackup}, err
+	}
+
+	// When working with the collection backups, auto-requeue
 	// to check on the status of the async solr backup calls
-	requeueOrNot := reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}
+	requeueOrNot := reconcile.Result{Requeue: true, RequeueAfter: util.RequeueAfter(util.RequeueBackupStatus)}
 
 	solrCloud, allCollectionsComplete, collectionActionTaken, err := r.reconcileSolrCloudBackup(ctx, backup, logger)
 	if err != nil {
@@ -110,6 +173,14 @@ func (r *SolrBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// and the collection backups are all complete (not necessarily successful)
 		// Do not do this right after the collectionsBackup have been complete, wait till the next cycle
 		if allCollectionsComplete && !backup.Status.Finished {
+			// Verify the backups before they are persisted, since persisting removes them from the backup repository
+			if backup.Spec.Verify && meta.FindStatusCondition(backup.Status.Conditions, solrv1beta1.SolrBackupVerified) == nil {
+				meta.SetStatusCondition(&backup.Status.Conditions, r.verifySolrCloudBackup(ctx, backup, solrCloud, logger))
+			}
+			// Export the cluster state before persisting as well, so that it is persisted along with the collection backups
+			if backup.Spec.IncludeClusterState && meta.FindStatusCondition(backup.Status.Conditions, solrv1beta1.SolrBackupClusterStateExported) == nil {
+				meta.SetStatusCondition(&backup.Status.Conditions, r.exportSolrCloudClusterState(ctx, backup, solrCloud, logger))
+			}
 			if backup.Spec.Persistence != nil {
 				// We will count on the Job updates to be notified
 				requeueOrNot = reconcile.Result{}
@@ -134,18 +205,281 @@ func (r *SolrBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		backup.Status.Successful = backup.Status.PersistenceStatus.Successful
 	}
 
+	// The backup is only successful if the backups to every additional repository succeeded as well
+	if backup.Status.Finished && !oldStatus.Finished && !util.AdditionalRepositoryBackupsSuccessful(backup) {
+		fals := false
+		backup.Status.Successful = &fals
+	}
+
+	// A backup that cannot be used to rebuild the cluster state, when requested, is not successful
+	if backup.Status.Finished && !oldStatus.Finished && backup.Spec.IncludeClusterState && !meta.IsStatusConditionTrue(backup.Status.Conditions, solrv1beta1.SolrBackupClusterStateExported) {
+		fals := false
+		backup.Status.Successful = &fals
+	}
+
+	if backup.Status.Finished && !oldStatus.Finished && backup.Spec.Recurrence != nil && backup.Status.Successful != nil && *backup.Status.Successful {
+		if pruneErr := r.pruneSolrCloudBackups(ctx, backup, logger); pruneErr != nil {
+			// The backups will be pruned again once the next backup finishes
+			logger.Error(pruneErr, "Error while pruning SolrCloud backups")
+		}
+	}
+
 	if !reflect.DeepEqual(oldStatus, backup.Status) {
 		logger.Info("Updating status for solr-backup")
 		err = r.Status().Update(ctx, backup)
 	}
+	if err == nil && backup.Status.Finished && !oldStatus.Finished {
+		recordFinishedBackup(backup)
+		r.recordBackupInSolrCloudHistory(ctx, backup, logger)
+	}
 
 	if backup.Status.Finished {
 		requeueOrNot = reconcile.Result{}
+		if backup.Spec.Recurrence != nil {
+			// Requeue to schedule the next backup
+			requeueOrNot = reconcile.Result{Requeue: true}
+		}
 	}
 
 	return requeueOrNot, err
 }
 
+// validateSolrBackup checks the options of a SolrBackup that cannot be combined, which retrying the backup cannot fix
+func validateSolrBackup(backup *solrv1beta1.SolrBackup) error {
+	if backup.Spec.Recurrence != nil && backup.Spec.Persistence != nil {
+		// Persisting a backup removes it from the backup repository, so there would be no backups to retain
+		return util.TerminalErrorf(util.InvalidSpecReason, "recurring backups cannot be persisted")
+	}
+	if err := util.ValidateAdditionalRepositories(backup); err != nil {
+		return err
+	}
+	return util.ValidateClusterStateExport(backup)
+}
+
+// reportTerminalError marks the configuration of the SolrBackup as invalid, with the reason and message of the terminal error.
+// The SolrBackup is not requeued, it is reconciled again once its spec is changed.
+// Errors that are not terminal are returned as-is, so that they are retried.
+func (r *SolrBackupReconciler) reportTerminalError(ctx context.Context, backup *solrv1beta1.SolrBackup, err error, logger logr.Logger) error {
+	terminalErr, isTerminal := util.AsTerminalError(err)
+	if !isTerminal {
+		return err
+	}
+	logger.Error(terminalErr, "The SolrBackup is misconfigured, it will be reconciled again once it is changed", "reason", terminalErr.Reason)
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrBackupConfigurationValid,
+		Status:             metav1.ConditionFalse,
+		Reason:             terminalErr.Reason,
+		Message:            terminalErr.Error(),
+		ObservedGeneration: backup.Generation,
+	}
+	if existing := meta.FindStatusCondition(backup.Status.Conditions, condition.Type); existing != nil &&
+		existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message && existing.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	r.Recorder.Event(backup, corev1.EventTypeWarning, terminalErr.Reason, terminalErr.Error())
+	meta.SetStatusCondition(&backup.Status.Conditions, condition)
+	return r.Status().Update(ctx, backup)
+}
+
+// recordFinishedBackup records the metrics of a SolrBackup that has just finished, and publishes its completion.
+// This is only done once the status of the SolrBackup has been saved, so that the backup is only counted and published once.
+func recordFinishedBackup(backup *solrv1beta1.SolrBackup) {
+	util.RecordSolrBackupMetrics(backup)
+	util.PublishCloudEvent(util.SolrBackupCompletedEvent, "solrbackups", backup, map[string]interface{}{
+		"solrCloud":  backup.Spec.SolrCloud,
+		"successful": backup.Status.Successful != nil && *backup.Status.Successful,
+	})
+}
+
+// recordBackupInSolrCloudHistory adds a SolrBackup that has just finished to the history of its SolrCloud.
+// This is only done once the status of the SolrBackup has been saved, so that the backup is recorded once.
+func (r *SolrBackupReconciler) recordBackupInSolrCloudHistory(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) {
+	if err := util.RecordSolrCloudOperation(ctx, r.Client, backup.Namespace, backup.Spec.SolrCloud, util.BackupOperation(backup)); err != nil {
+		logger.Error(err, "Could not record the backup in the history of the SolrCloud", "solrCloud", backup.Spec.SolrCloud)
+	}
+}
+
+// reconcileBackupRecurrence schedules the next backup of a finished recurring SolrBackup, based on when its last backup finished.
+// Once the scheduled time has passed, the status of the last backup is reset, so that the next backup is started.
+// If the next backup is not due yet, the time until it is due is returned.
+func reconcileBackupRecurrence(backup *solrv1beta1.SolrBackup, logger logr.Logger) (untilNextBackup time.Duration, err error) {
+	if backup.Spec.Recurrence == nil {
+		backup.Status.NextScheduledTime = nil
+		return 0, nil
+	}
+	if !backup.Status.Finished {
+		return 0, nil
+	}
+
+	lastFinished := time.Now()
+	if backup.Status.FinishTime != nil {
+		lastFinished = backup.Status.FinishTime.Time
+	}
+	nextBackup, err := util.NextScheduledBackupTime(backup.Spec.Recurrence, lastFinished)
+	if err != nil {
+		return 0, err
+	}
+
+	if untilNextBackup = time.Until(nextBackup); untilNextBackup > 0 {
+		nextScheduledTime := metav1.NewTime(nextBackup).Rfc3339Copy()
+		backup.Status.NextScheduledTime = &nextScheduledTime
+		return untilNextBackup, nil
+	}
+
+	logger.Info("Starting scheduled backup", "scheduledTime", nextBackup)
+	util.ResetBackupForRecurrence(backup)
+	return 0, nil
+}
+
+// pruneSolrCloudBackups deletes the oldest backups of a recurring SolrBackup from the backup repository,
+// for each collection that was successfully backed up, and records the backups that are retained.
+func (r *SolrBackupReconciler) pruneSolrCloudBackups(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) (err error) {
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.SolrCloud}, solrCloud); err != nil {
+		return err
+	}
+
+	httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		return err
+	}
+
+	backupRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, backup.Spec.RepositoryName)
+	if backupRepository == nil {
+		return fmt.Errorf("Unable to find backup repository to prune backups of [%s] (which specified the repository"+
+			" [%s]).  solrcloud must define a repository matching that name (or have only 1 repository defined).",
+			backup.Name, backup.Spec.RepositoryName)
+	}
+
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		if collectionStatus.Successful == nil || !*collectionStatus.Successful {
+			continue
+		}
+		retainedIds, err := util.PruneBackupForCollection(solrCloud, backupRepository, backup, collectionStatus.Collection, httpHeaders, logger)
+		if err != nil {
+			return err
+		}
+		util.SetRetainedBackupsForCollection(backup, collectionStatus.Collection, retainedIds)
+	}
+
+	// Only the backups retained in the main repository are recorded, since only those can be restored by a SolrRestore
+	for _, repositoryStatus := range backup.Status.AdditionalRepositoryStatuses {
+		additionalRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, repositoryStatus.Repository)
+		if additionalRepository == nil {
+			return fmt.Errorf("Unable to find backup repository [%s] to prune backups of [%s]", repositoryStatus.Repository, backup.Name)
+		}
+		for _, collectionStatus := range repositoryStatus.CollectionBackupStatuses {
+			if collectionStatus.Successful == nil || !*collectionStatus.Successful {
+				continue
+			}
+			if _, err = util.PruneBackupForCollection(solrCloud, additionalRepository, backup, collectionStatus.Collection, httpHeaders, logger); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := metav1.Now()
+	backup.Status.LastPruneTime = &now
+	return nil
+}
+
+// verifySolrCloudBackup inspects the backup of each collection in the backup repository, and returns the resulting "Verified" condition.
+// Collections whose backups were not successful cannot be verified.
+func (r *SolrBackupReconciler) verifySolrCloudBackup(ctx context.Context, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, logger logr.Logger) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrBackupVerified,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: backup.Generation,
+	}
+
+	httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		condition.Reason = "Error"
+		condition.Message = err.Error()
+		return condition
+	}
+	backupRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, backup.Spec.RepositoryName)
+	if backupRepository == nil {
+		condition.Reason = "Error"
+		condition.Message = fmt.Sprintf("Unable to find backup repository [%s] to verify the backup", backup.Spec.RepositoryName)
+		return condition
+	}
+
+	var verified, problems []string
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		collection := collectionStatus.Collection
+		if collectionStatus.Successful == nil || !*collectionStatus.Successful {
+			problems = append(problems, collection+": backup was not successful")
+		} else if problem, err := util.VerifyBackupForCollection(solrCloud, backupRepository, backup, collection, httpHeaders, logger); err != nil {
+			problems = append(problems, collection+": "+err.Error())
+		} else if problem != "" {
+			problems = append(problems, collection+": "+problem)
+		} else {
+			verified = append(verified, collection)
+		}
+	}
+
+	if len(problems) > 0 {
+		logger.Info("Backup verification failed", "problems", problems)
+		condition.Reason = "VerificationFailed"
+		condition.Message = "Could not verify backups of collections: " + strings.Join(problems, "; ")
+	} else {
+		logger.Info("Verified backup", "collections", verified)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Verified"
+		condition.Message = "Verified backups of collections: " + strings.Join(verified, ", ")
+	}
+	return condition
+}
+
+// exportSolrCloudClusterState exports the cluster state of the SolrCloud into the location of the backup, and returns the resulting "ClusterStateExported" condition.
+func (r *SolrBackupReconciler) exportSolrCloudClusterState(ctx context.Context, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, logger logr.Logger) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrBackupClusterStateExported,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Error",
+		ObservedGeneration: backup.Generation,
+	}
+
+	httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		condition.Message = err.Error()
+		return condition
+	}
+	backupRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, backup.Spec.RepositoryName)
+	if backupRepository == nil {
+		condition.Message = fmt.Sprintf("Unable to find backup repository [%s] to export the cluster state to", backup.Spec.RepositoryName)
+		return condition
+	}
+
+	exported, err := util.ExportClusterStateForBackup(solrCloud, backupRepository, backup, httpHeaders, r.config)
+	if err != nil {
+		logger.Error(err, "Could not export the cluster state for the backup")
+		condition.Message = "Could not export the cluster state: " + err.Error()
+		return condition
+	}
+	logger.Info("Exported cluster state for the backup", "znodes", exported)
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Exported"
+	condition.Message = "Exported znodes: " + strings.Join(exported, ", ")
+	return condition
+}
+
+// solrCloudHttpHeaders returns the headers needed to authenticate with the SolrCloud
+func (r *SolrBackupReconciler) solrCloudHttpHeaders(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) (httpHeaders map[string]string, err error) {
+	if err = util.ValidateOperatorCanCallSolr(solrCloud); err != nil {
+		return nil, err
+	}
+	if solrCloud.UsesBasicAuth() {
+		basicAuthSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+			return nil, err
+		}
+		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	}
+	return httpHeaders, nil
+}
+
 func (r *SolrBackupReconciler) reconcileSolrCloudBackup(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) (solrCloud *solrv1beta1.SolrCloud, collectionBackupsFinished bool, actionTaken bool, err error) {
 	// Get the solrCloud that this backup is for.
 	solrCloud = &solrv1beta1.SolrCloud{}
@@ -158,13 +492,9 @@ func (r *SolrBackupReconciler) reconcileSolrCloudBackup(ctx context.Context, bac
 		return nil, collectionBackupsFinished, actionTaken, err
 	}
 
-	var httpHeaders map[string]string
-	if solrCloud.Spec.SolrSecurity != nil {
-		basicAuthSecret := &corev1.Secret{}
-		if err := r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
-			return nil, collectionBackupsFinished, actionTaken, err
-		}
-		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		return nil, collectionBackupsFinished, actionTaken, err
 	}
 
 	// First check if the collection backups have been completed
@@ -186,11 +516,28 @@ func (r *SolrBackupReconciler) reconcileSolrCloudBackup(ctx context.Context, bac
 
 	// This should only occur before the backup processes have been started
 	if backup.Status.SolrVersion == "" {
+		// New backups are not started for a SolrCloud that is being deleted, since they would block its deletion
+		if !solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+			logger.Info("Not starting backup, the SolrCloud is being deleted", "solrCloud", solrCloud.Name)
+			return solrCloud, collectionBackupsFinished, actionTaken, errors.NewServiceUnavailable("Cloud is being deleted, backups cannot be started")
+		}
+
 		// Prep the backup directory in the persistentVolume
-		err := util.EnsureDirectoryForBackup(solrCloud, backupRepository, backup.Name, r.config)
+		err := util.EnsureDirectoryForBackup(solrCloud, backupRepository, backup, r.config)
 		if err != nil {
 			return solrCloud, collectionBackupsFinished, actionTaken, err
 		}
+		for _, repositoryName := range backup.Spec.AdditionalRepositoryNames {
+			additionalRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, repositoryName)
+			if additionalRepository == nil {
+				err = fmt.Errorf("Unable to find additional backup repository [%s] to use for backup [%s]. solrcloud must define a repository matching that name.",
+					repositoryName, backup.Name)
+				return solrCloud, collectionBackupsFinished, actionTaken, err
+			}
+			if err = util.EnsureDirectoryForBackup(solrCloud, additionalRepository, backup, r.config); err != nil {
+				return solrCloud, collectionBackupsFinished, actionTaken, err
+			}
+		}
 
 		// Make sure that all solr nodes are active and have the backupRestore shared volume mounted
 		cloudReady := solrCloud.Status.BackupRestoreReady && (solrCloud.Status.Replicas == solrCloud.Status.ReadyReplicas)
@@ -199,36 +546,73 @@ func (r *SolrBackupReconciler) reconcileSolrCloudBackup(ctx context.Context, bac
 			return solrCloud, collectionBackupsFinished, actionTaken, errors.NewServiceUnavailable("Cloud is not ready for backups or restores")
 		}
 
+		// Resolve the collections to back up, since they may be patterns, or empty to back up every collection
+		clusterCollections, err := util.NewSolrClusterState(solrCloud, httpHeaders).CollectionNames()
+		if err != nil {
+			return solrCloud, collectionBackupsFinished, actionTaken, err
+		}
+		if backup.Status.Collections, err = util.MatchCollectionsForBackup(backup.Spec.Collections, clusterCollections); err != nil {
+			return solrCloud, collectionBackupsFinished, actionTaken, err
+		} else if len(backup.Status.Collections) == 0 {
+			logger.Info("Not starting backup, no collections match", "solrCloud", solrCloud.Name, "collections", backup.Spec.Collections)
+			return solrCloud, collectionBackupsFinished, actionTaken, errors.NewServiceUnavailable("No collections to back up")
+		}
+		logger.Info("Resolved collections to back up", "solrCloud", solrCloud.Name, "collections", backup.Status.Collections)
+
 		// Only set the solr version at the start of the backup. This shouldn't change throughout the backup.
 		backup.Status.SolrVersion = solrCloud.Status.Version
+	} else if len(backup.Status.Collections) == 0 {
+		// Backups started by earlier versions of the Solr Operator did not resolve their collections
+		backup.Status.Collections = backup.Spec.Collections
 	}
 
+	// Collection backups are started in order, the primary repository first, as long as the throttling options allow it
+	startsAllowed := util.CollectionBackupsAllowedToStart(backup)
+
 	// Go through each collection specified and reconcile the backup.
-	for _, collection := range backup.Spec.Collections {
-		_, err = reconcileSolrCollectionBackup(backup, solrCloud, backupRepository, collection, httpHeaders, logger)
+	for _, collection := range backup.Status.Collections {
+		_, err = reconcileSolrCollectionBackup(backup, solrCloud, backupRepository, collection, &backup.Status.CollectionBackupStatuses, &startsAllowed, httpHeaders, logger)
 	}
 
-	// First check if the collection backups have been completed
+	// Back the same collections up to each of the additional repositories
+	for _, repositoryName := range backup.Spec.AdditionalRepositoryNames {
+		additionalRepository := util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, repositoryName)
+		if additionalRepository == nil {
+			err = fmt.Errorf("Unable to find additional backup repository [%s] to use for backup [%s]. solrcloud must define a repository matching that name.",
+				repositoryName, backup.Name)
+			continue
+		}
+		repositoryStatus := util.AdditionalRepositoryBackupStatus(backup, repositoryName)
+		for _, collection := range backup.Status.Collections {
+			_, err = reconcileSolrCollectionBackup(backup, solrCloud, additionalRepository, collection, &repositoryStatus.CollectionBackupStatuses, &startsAllowed, httpHeaders, logger)
+		}
+		util.CheckStatusOfRepositoryBackup(repositoryStatus)
+	}
+
+	// Check again whether the collection backups to every repository have been completed
 	collectionBackupsFinished = util.CheckStatusOfCollectionBackups(backup)
 
 	return solrCloud, collectionBackupsFinished, actionTaken, err
 }
 
-func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, backupRepository *solrv1beta1.SolrBackupRepository, collection string, httpHeaders map[string]string, logger logr.Logger) (finished bool, err error) {
+// reconcileSolrCollectionBackup reconciles the backup of a collection to the given repository, recording its progress in the given collection backup statuses.
+// The backup is only started if startsAllowed is not 0, and startsAllowed is decremented if it is positive and the backup is started.
+func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, backupRepository *solrv1beta1.SolrBackupRepository, collection string, collectionBackupStatuses *[]solrv1beta1.CollectionBackupStatus, startsAllowed *int, httpHeaders map[string]string, logger logr.Logger) (finished bool, err error) {
 	now := metav1.Now()
+	asyncName := util.BackupAsyncName(backup, backupRepository.Name)
 	collectionBackupStatus := solrv1beta1.CollectionBackupStatus{}
 	collectionBackupStatus.Collection = collection
 	backupIndex := -1
 	// Get the backup status for this collection, if one exists
-	for i, status := range backup.Status.CollectionBackupStatuses {
+	for i, status := range *collectionBackupStatuses {
 		if status.Collection == collection {
 			collectionBackupStatus = status
 			backupIndex = i
 		}
 	}
 
-	// If the collection backup hasn't started, start it
-	if !collectionBackupStatus.InProgress && !collectionBackupStatus.Finished {
+	// If the collection backup hasn't started, start it, unless too many collection backups are already in progress
+	if !collectionBackupStatus.InProgress && !collectionBackupStatus.Finished && *startsAllowed != 0 {
 		// Start the backup by calling solr
 		started, err := util.StartBackupForCollection(solrCloud, backupRepository, backup, collection, httpHeaders, logger)
 		if err != nil {
@@ -238,9 +622,12 @@ func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *so
 		if started && collectionBackupStatus.StartTime == nil {
 			collectionBackupStatus.StartTime = &now
 		}
+		if started && *startsAllowed > 0 {
+			*startsAllowed--
+		}
 	} else if collectionBackupStatus.InProgress {
 		// Check the state of the backup, when it is in progress, and update the state accordingly
-		finished, successful, asyncStatus, error := util.CheckBackupForCollection(solrCloud, collection, backup.Name, httpHeaders, logger)
+		finished, successful, asyncStatus, error := util.CheckBackupForCollection(solrCloud, collection, asyncName, httpHeaders, logger)
 		if error != nil {
 			return false, error
 		}
@@ -255,16 +642,16 @@ func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *so
 				collectionBackupStatus.FinishTime = &now
 			}
 
-			err = util.DeleteAsyncInfoForBackup(solrCloud, collection, backup.Name, httpHeaders, logger)
+			err = util.DeleteAsyncInfoForBackup(solrCloud, collection, asyncName, httpHeaders, logger)
 		} else {
 			collectionBackupStatus.AsyncBackupStatus = asyncStatus
 		}
 	}
 
 	if backupIndex < 0 {
-		backup.Status.CollectionBackupStatuses = append(backup.Status.CollectionBackupStatuses, collectionBackupStatus)
+		*collectionBackupStatuses = append(*collectionBackupStatuses, collectionBackupStatus)
 	} else {
-		backup.Status.CollectionBackupStatuses[backupIndex] = collectionBackupStatus
+		(*collectionBackupStatuses)[backupIndex] = collectionBackupStatus
 	}
 
 	return collectionBackupStatus.Finished, err
@@ -285,6 +672,10 @@ func (r *SolrBackupReconciler) persistSolrCloudBackups(ctx context.Context, back
 	}
 
 	if util.IsRepoManaged(backupRepository) {
+		if err = r.reconcilePersistenceServiceAccount(ctx, backup, logger); err != nil {
+			return err
+		}
+
 		persistenceJob := util.GenerateBackupPersistenceJobForCloud(backupRepository, backup, solrCloud)
 		if err := controllerutil.SetControllerReference(backup, persistenceJob, r.Scheme); err != nil {
 			return err
@@ -330,6 +721,33 @@ func (r *SolrBackupReconciler) persistSolrCloudBackups(ctx context.Context, back
 	}
 }
 
+// reconcilePersistenceServiceAccount creates or updates the ServiceAccount that the persistence Job runs as, if the Solr Operator manages it
+func (r *SolrBackupReconciler) reconcilePersistenceServiceAccount(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) (err error) {
+	serviceAccount := util.GeneratePersistenceServiceAccount(backup)
+	if serviceAccount == nil {
+		return nil
+	}
+	serviceAccountLogger := logger.WithValues("serviceAccount", serviceAccount.Name)
+	foundServiceAccount := &corev1.ServiceAccount{}
+	err = r.Get(ctx, types.NamespacedName{Name: serviceAccount.Name, Namespace: serviceAccount.Namespace}, foundServiceAccount)
+	if err != nil && errors.IsNotFound(err) {
+		serviceAccountLogger.Info("Creating Persistence ServiceAccount")
+		if err = controllerutil.SetControllerReference(backup, serviceAccount, r.Scheme); err == nil {
+			err = r.Create(ctx, serviceAccount)
+		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(backup, foundServiceAccount, r.Scheme)
+		needsUpdate = util.CopyLabelsAndAnnotations(&serviceAccount.ObjectMeta, &foundServiceAccount.ObjectMeta, serviceAccountLogger) || needsUpdate
+
+		if needsUpdate && err == nil {
+			serviceAccountLogger.Info("Updating Persistence ServiceAccount")
+			err = r.Update(ctx, foundServiceAccount)
+		}
+	}
+	return err
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SolrBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.config = mgr.GetConfig()
@@ -337,5 +755,6 @@ func (r *SolrBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&solrv1beta1.SolrBackup{}).
 		Owns(&batchv1.Job{}).
+		Owns(&corev1.ServiceAccount{}).
 		Complete(r)
 }
@@ -0,0 +1,224 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcileVolumeSnapshotBackup takes the backup of the SolrCloud as VolumeSnapshots of the data PersistentVolumeClaims of its Solr Nodes.
+//
+// The collections are put into read-only mode before the VolumeSnapshots are created, so that every update is committed to disk,
+// and they are made writable again once every VolumeSnapshot has been taken. The backup finishes once every VolumeSnapshot is ready to use, or has failed.
+// The collections to make read-only are recorded in the status, in a reconcile of their own, before any of them is made read-only,
+// so that they are made writable again even if a status update fails.
+// The VolumeSnapshots are not watched, since their CRDs may not be installed, so the returned requeue reports whether their status needs to be checked again.
+func (r *SolrBackupReconciler) reconcileVolumeSnapshotBackup(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) (requeue bool, err error) {
+	if backup.Status.Finished {
+		return false, nil
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.SolrCloud}, solrCloud); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Error(err, "Could not find cloud to backup", "solrCloud", backup.Spec.SolrCloud)
+		}
+		return false, err
+	}
+	if err = util.ValidateVolumeSnapshotBackup(backup, solrCloud); err != nil {
+		logger.Error(err, "Cannot take VolumeSnapshot backup")
+		return false, nil
+	}
+
+	httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		return true, err
+	}
+
+	// This should only occur before the VolumeSnapshots have been started
+	if backup.Status.SolrVersion == "" {
+		if !solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+			logger.Info("Not starting backup, the SolrCloud is being deleted", "solrCloud", solrCloud.Name)
+			return true, errors.NewServiceUnavailable("Cloud is being deleted, backups cannot be started")
+		}
+		if solrCloud.Status.Replicas != solrCloud.Status.ReadyReplicas {
+			logger.Info("Cloud not ready for VolumeSnapshot backup", "solrCloud", solrCloud.Name)
+			return true, errors.NewServiceUnavailable("Cloud is not ready for backups")
+		}
+
+		clusterState := util.NewSolrClusterState(solrCloud, httpHeaders)
+		clusterCollections, err := clusterState.CollectionNames()
+		if err != nil {
+			return true, err
+		}
+		if backup.Status.Collections, err = util.MatchCollectionsForBackup(backup.Spec.Collections, clusterCollections); err != nil {
+			return true, err
+		}
+
+		if backup.Status.VolumeSnapshots = util.VolumeSnapshotBackupStatuses(backup, solrCloud); len(backup.Status.VolumeSnapshots) == 0 {
+			logger.Info("Not starting backup, the SolrCloud has no Solr Nodes", "solrCloud", solrCloud.Name)
+			return true, errors.NewServiceUnavailable("No Solr Nodes to snapshot")
+		}
+
+		if backup.Status.ReadOnlyCollections, err = util.CollectionsToMakeReadOnlyForSnapshot(backup.Status.Collections, clusterState); err != nil {
+			return true, err
+		}
+
+		// Only set the solr version at the start of the backup. This shouldn't change throughout the backup.
+		backup.Status.SolrVersion = solrCloud.Status.Version
+		return true, nil
+	}
+
+	if !meta.IsStatusConditionTrue(backup.Status.Conditions, solrv1beta1.SolrBackupCollectionsReadOnly) {
+		if err = util.SetCollectionsReadOnlyForSnapshot(solrCloud, backup.Status.ReadOnlyCollections, httpHeaders, logger); err != nil {
+			for i := range backup.Status.VolumeSnapshots {
+				backup.Status.VolumeSnapshots[i].Error = "Could not make the collections read-only: " + err.Error()
+			}
+		} else {
+			meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{
+				Type:    solrv1beta1.SolrBackupCollectionsReadOnly,
+				Status:  metav1.ConditionTrue,
+				Reason:  "CollectionsReadOnly",
+				Message: "The collections are read-only until every VolumeSnapshot has been taken",
+			})
+		}
+	}
+
+	for i := range backup.Status.VolumeSnapshots {
+		snapshotStatus := &backup.Status.VolumeSnapshots[i]
+		if snapshotStatus.Error != "" || snapshotStatus.ReadyToUse {
+			continue
+		}
+		if snapshotErr := r.reconcileVolumeSnapshot(ctx, backup, snapshotStatus, logger); snapshotErr != nil {
+			err = snapshotErr
+		}
+	}
+
+	allTaken, allFinished, allSuccessful := util.CheckStatusOfVolumeSnapshots(backup)
+	if allTaken && len(backup.Status.ReadOnlyCollections) > 0 {
+		var writableErr error
+		if backup.Status.ReadOnlyCollections, writableErr = util.SetCollectionsWritableAfterSnapshot(solrCloud, backup.Status.ReadOnlyCollections, httpHeaders, logger); writableErr != nil {
+			err = writableErr
+		} else {
+			meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{
+				Type:    solrv1beta1.SolrBackupCollectionsReadOnly,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CollectionsWritable",
+				Message: "The collections were made writable again, since every VolumeSnapshot has been taken",
+			})
+		}
+	}
+
+	// The backup does not finish until its collections are writable again
+	if allFinished && len(backup.Status.ReadOnlyCollections) == 0 {
+		now := metav1.Now()
+		backup.Status.Finished = true
+		backup.Status.FinishTime = &now
+		backup.Status.Successful = &allSuccessful
+		return false, err
+	}
+	return true, err
+}
+
+// reconcileVolumeSnapshot creates the VolumeSnapshot of a Solr Node's data PersistentVolumeClaim if it does not exist, and records its progress
+func (r *SolrBackupReconciler) reconcileVolumeSnapshot(ctx context.Context, backup *solrv1beta1.SolrBackup, snapshotStatus *solrv1beta1.VolumeSnapshotBackupStatus, logger logr.Logger) (err error) {
+	snapshotLogger := logger.WithValues("volumeSnapshot", snapshotStatus.Name)
+
+	foundSnapshot := &unstructured.Unstructured{}
+	foundSnapshot.SetGroupVersionKind(util.VolumeSnapshotGVK)
+	err = r.Get(ctx, types.NamespacedName{Name: snapshotStatus.Name, Namespace: backup.Namespace}, foundSnapshot)
+	if err != nil && meta.IsNoMatchError(err) {
+		// The snapshot can never be taken, so fail it, which lets the collections be made writable again
+		snapshotStatus.Error = "The VolumeSnapshot CRDs are not installed in the Kubernetes cluster"
+		return err
+	} else if err != nil && errors.IsNotFound(err) {
+		snapshotLogger.Info("Creating VolumeSnapshot", "persistentVolumeClaim", snapshotStatus.PersistentVolumeClaim)
+		return r.Create(ctx, util.GenerateVolumeSnapshot(backup, snapshotStatus))
+	} else if err != nil {
+		return err
+	}
+
+	contentName := util.UpdateVolumeSnapshotBackupStatus(snapshotStatus, foundSnapshot, time.Duration(*backup.Spec.VolumeSnapshot.TakeTimeoutSeconds)*time.Second, time.Now())
+	if contentName != "" && snapshotStatus.SnapshotHandle == "" {
+		foundContent := &unstructured.Unstructured{}
+		foundContent.SetGroupVersionKind(util.VolumeSnapshotContentGVK)
+		if err = r.Get(ctx, types.NamespacedName{Name: contentName}, foundContent); err == nil {
+			snapshotStatus.SnapshotHandle = util.VolumeSnapshotHandle(foundContent)
+		} else if errors.IsNotFound(err) {
+			err = nil
+		}
+	}
+	if snapshotStatus.Error != "" {
+		snapshotLogger.Info("VolumeSnapshot failed", "error", snapshotStatus.Error)
+	}
+	return err
+}
+
+// reconcileReadOnlyCollectionsFinalizer makes sure that the collections that a VolumeSnapshot backup makes read-only are made writable again, even if the SolrBackup is deleted.
+// The finalizer is kept until the backup has finished and none of its collections are read-only anymore.
+// When handled is returned, the finalizer has been added, removed or is blocking the deletion, and nothing else should be reconciled.
+func (r *SolrBackupReconciler) reconcileReadOnlyCollectionsFinalizer(ctx context.Context, backup *solrv1beta1.SolrBackup, logger logr.Logger) (handled bool, err error) {
+	hasFinalizer := util.ContainsString(backup.ObjectMeta.Finalizers, util.SolrBackupReadOnlyCollectionsFinalizer)
+	if backup.ObjectMeta.DeletionTimestamp.IsZero() {
+		needsFinalizer := !backup.Status.Finished || len(backup.Status.ReadOnlyCollections) > 0
+		if needsFinalizer == hasFinalizer {
+			return false, nil
+		}
+		if needsFinalizer {
+			backup.ObjectMeta.Finalizers = append(backup.ObjectMeta.Finalizers, util.SolrBackupReadOnlyCollectionsFinalizer)
+		} else {
+			backup.ObjectMeta.Finalizers = util.RemoveString(backup.ObjectMeta.Finalizers, util.SolrBackupReadOnlyCollectionsFinalizer)
+		}
+		return true, r.Update(ctx, backup)
+	}
+
+	if !hasFinalizer {
+		return true, nil
+	}
+	if len(backup.Status.ReadOnlyCollections) > 0 {
+		solrCloud := &solrv1beta1.SolrCloud{}
+		if err = r.Get(ctx, types.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.SolrCloud}, solrCloud); err != nil && !errors.IsNotFound(err) {
+			return true, err
+		} else if err == nil {
+			httpHeaders, err := r.solrCloudHttpHeaders(ctx, solrCloud)
+			if err != nil {
+				return true, err
+			}
+			logger.Info("Making the collections of the deleted SolrBackup writable again", "collections", backup.Status.ReadOnlyCollections)
+			if backup.Status.ReadOnlyCollections, err = util.SetCollectionsWritableAfterSnapshot(solrCloud, backup.Status.ReadOnlyCollections, httpHeaders, logger); err != nil {
+				if statusErr := r.Status().Update(ctx, backup); statusErr != nil {
+					logger.Error(statusErr, "Could not record the collections that are still read-only")
+				}
+				return true, err
+			}
+		}
+		// Without the SolrCloud, there are no collections left to make writable
+	}
+	backup.ObjectMeta.Finalizers = util.RemoveString(backup.ObjectMeta.Finalizers, util.SolrBackupReadOnlyCollectionsFinalizer)
+	return true, r.Update(ctx, backup)
+}
@@ -0,0 +1,123 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// externalAddressabilityProvider reconciles the resources that make a SolrCloud addressable from outside of the Kubernetes cluster,
+// for a single ExternalAddressabilityMethod.
+//
+// New methods of exposing a SolrCloud can be supported by registering a provider in externalAddressabilityProviders.
+// Providers are only used when the operator manages the external addressability of the SolrCloud.
+type externalAddressabilityProvider interface {
+	// Reconcile creates, updates or removes the resources of the provider, and records them in the status of the SolrCloud.
+	// A positive requeueAfter is returned if the resources have to be reconciled again after some time, even if nothing changes.
+	Reconcile(ctx context.Context, r *SolrCloudReconciler, instance *solrv1beta1.SolrCloud, solrNodeNames []string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (requeueAfter time.Duration, err error)
+}
+
+var externalAddressabilityProviders = map[solrv1beta1.ExternalAddressabilityMethod]externalAddressabilityProvider{
+	solrv1beta1.Ingress:     ingressAddressabilityProvider{},
+	solrv1beta1.ExternalDNS: externalDnsAddressabilityProvider{},
+}
+
+// reconcileExternalAddressability reconciles the resources of the provider for the external addressability method of the SolrCloud.
+func (r *SolrCloudReconciler) reconcileExternalAddressability(ctx context.Context, instance *solrv1beta1.SolrCloud, solrNodeNames []string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (requeueAfter time.Duration, err error) {
+	extOpts := instance.Spec.SolrAddressability.External
+	if !extOpts.IsManaged() {
+		return 0, nil
+	}
+	provider, supported := externalAddressabilityProviders[extOpts.Method]
+	if !supported {
+		return 0, util.TerminalErrorf(util.InvalidSpecReason, "external addressability method %s is not supported by the Solr Operator, use unmanaged external addressability instead", extOpts.Method)
+	}
+	return provider.Reconcile(ctx, r, instance, solrNodeNames, newStatus, logger)
+}
+
+// ingressAddressabilityProvider exposes the common and node services of a SolrCloud through a single Ingress.
+type ingressAddressabilityProvider struct{}
+
+func (ingressAddressabilityProvider) Reconcile(ctx context.Context, r *SolrCloudReconciler, instance *solrv1beta1.SolrCloud, solrNodeNames []string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (requeueAfter time.Duration, err error) {
+	// The user manages the Ingress themselves, so it is no longer owned by the SolrCloud
+	if !instance.ManagesIngress() {
+		return 0, r.releaseUnmanagedResource(ctx, instance, instance.CommonIngressName(), &netv1.Ingress{}, logger)
+	}
+
+	// Generate Ingress
+	ingress := util.GenerateIngress(instance, solrNodeNames)
+
+	// Restore the endpoints removed for a maintenance window once it ends
+	if maintenance := instance.Spec.SolrAddressability.External.Maintenance; maintenance.IsActive(time.Now()) && maintenance.Until != nil {
+		requeueAfter = time.Until(maintenance.Until.Time)
+	}
+
+	// Check if the Ingress already exists
+	ingressLogger := logger.WithValues("ingress", ingress.Name)
+	foundIngress := &netv1.Ingress{}
+	err = r.Get(ctx, types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}, foundIngress)
+	if len(ingress.Spec.Rules) == 0 {
+		// An Ingress must have at least one rule, so remove it while every endpoint is hidden
+		if err == nil {
+			ingressLogger.Info("Deleting Ingress, since no endpoints are exposed")
+			err = r.Delete(ctx, foundIngress)
+		}
+		if errors.IsNotFound(err) {
+			err = nil
+		}
+	} else if err != nil && errors.IsNotFound(err) {
+		ingressLogger.Info("Creating Ingress")
+		if err = controllerutil.SetControllerReference(instance, ingress, r.Scheme); err == nil {
+			err = r.Create(ctx, ingress)
+		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(instance, foundIngress, r.Scheme)
+		needsUpdate = util.CopyIngressFields(ingress, foundIngress, ingressLogger) || needsUpdate
+
+		// Update the found Ingress and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			ingressLogger.Info("Updating Ingress")
+			err = r.Update(ctx, foundIngress)
+		}
+	}
+	if err != nil {
+		return requeueAfter, err
+	}
+	if len(ingress.Spec.Rules) > 0 {
+		newStatus.Resources.Ingress = ingress.Name
+	}
+	return requeueAfter, nil
+}
+
+// externalDnsAddressabilityProvider exposes the common and headless services of a SolrCloud through ExternalDNS.
+// ExternalDNS creates the DNS records from the annotations that are added when the services are generated, so there are no other resources to reconcile.
+type externalDnsAddressabilityProvider struct{}
+
+func (externalDnsAddressabilityProvider) Reconcile(ctx context.Context, r *SolrCloudReconciler, instance *solrv1beta1.SolrCloud, solrNodeNames []string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (requeueAfter time.Duration, err error) {
+	return 0, nil
+}
@@ -0,0 +1,217 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// inProgressOperationsForCloud lists the SolrBackups and SolrRestores that are in progress for the SolrCloud,
+// as well as the names of the backup repositories that they use, but that the SolrCloud no longer defines.
+func inProgressOperationsForCloud(ctx context.Context, reader client.Reader, cloud *solrv1beta1.SolrCloud) (inProgress []string, removedRepositories []string, err error) {
+	backups := &solrv1beta1.SolrBackupList{}
+	if err = reader.List(ctx, backups, client.InNamespace(cloud.Namespace)); err != nil {
+		return nil, nil, err
+	}
+	restores := &solrv1beta1.SolrRestoreList{}
+	if err = reader.List(ctx, restores, client.InNamespace(cloud.Namespace)); err != nil {
+		return nil, nil, err
+	}
+	inProgressBackups, removedBackupRepositories := util.InProgressBackupsForCloud(cloud, backups.Items)
+	inProgressRestores, removedRestoreRepositories := util.InProgressRestoresForCloud(cloud, restores.Items, backups.Items)
+
+	for _, backup := range inProgressBackups {
+		inProgress = append(inProgress, "SolrBackup "+backup)
+	}
+	for _, restore := range inProgressRestores {
+		inProgress = append(inProgress, "SolrRestore "+restore)
+	}
+	removedRepositories = removedBackupRepositories
+	for _, repositoryName := range removedRestoreRepositories {
+		if !util.ContainsString(removedRepositories, repositoryName) {
+			removedRepositories = append(removedRepositories, repositoryName)
+		}
+	}
+	sort.Strings(removedRepositories)
+	return inProgress, removedRepositories, nil
+}
+
+// reconcileBackupProtection protects the SolrCloud from changes that would break the SolrBackups and SolrRestores that are in progress for it.
+// It is run before anything else is reconciled, so that the SolrCloud is protected even while it is being deleted, or cannot be reconciled otherwise.
+//
+// While backups or restores are in progress, the SolrCloud keeps a finalizer, so that it is not removed until they finish.
+// If a backup repository that they use is removed from the SolrCloud, blockStatefulSet is returned as true,
+// so that the Solr pods keep the repository until they finish.
+// The returned condition explains whether, and why, the SolrCloud is being protected.
+func (r *SolrCloudReconciler) reconcileBackupProtection(ctx context.Context, instance *solrv1beta1.SolrCloud, logger logr.Logger) (condition metav1.Condition, blockStatefulSet bool, err error) {
+	inProgress, removedRepositories, err := inProgressOperationsForCloud(ctx, r.Client, instance)
+	if err != nil {
+		return condition, false, err
+	}
+
+	condition = metav1.Condition{
+		Type:               solrv1beta1.SolrCloudBackupsInProgress,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoBackupsInProgress",
+		ObservedGeneration: instance.Generation,
+	}
+
+	hasFinalizer := util.ContainsString(instance.ObjectMeta.Finalizers, util.SolrBackupsFinalizer)
+	if len(inProgress) == 0 {
+		if hasFinalizer {
+			logger.Info("Removing backups finalizer for SolrCloud, no backups or restores are in progress")
+			instance.ObjectMeta.Finalizers = util.RemoveString(instance.ObjectMeta.Finalizers, util.SolrBackupsFinalizer)
+			err = r.Update(ctx, instance)
+		}
+		return condition, false, err
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "BackupsInProgress"
+	condition.Message = fmt.Sprintf("Backups and restores in progress: %s", strings.Join(inProgress, ", "))
+
+	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		condition.Reason = "DeletionBlocked"
+		condition.Message = fmt.Sprintf("The SolrCloud will not be deleted until these finish: %s", strings.Join(inProgress, ", "))
+	} else if !hasFinalizer {
+		// A finalizer cannot be added once the SolrCloud is being deleted
+		instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, util.SolrBackupsFinalizer)
+		if err = r.Update(ctx, instance); err != nil {
+			return condition, false, err
+		}
+	}
+
+	if len(removedRepositories) > 0 && instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		blockStatefulSet = true
+		condition.Reason = "RepositoryRemovalBlocked"
+		condition.Message = fmt.Sprintf("The removal of backup repositories [%s] will not be applied to the Solr pods until these finish: %s", strings.Join(removedRepositories, ", "), strings.Join(inProgress, ", "))
+	}
+
+	if existing := meta.FindStatusCondition(instance.Status.Conditions, condition.Type); existing == nil || existing.Reason != condition.Reason {
+		if condition.Reason != "BackupsInProgress" {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+	return condition, blockStatefulSet, nil
+}
+
+// reconcileDeletion finishes the deletion of a SolrCloud, instead of reconciling its resources.
+// While backups or restores are in progress, the deletion is blocked by the backups finalizer, and the reason is reported in the status.
+// Afterwards, the data PVCs are deleted if the storage finalizer asks for it. The other resources are garbage collected.
+func (r *SolrCloudReconciler) reconcileDeletion(ctx context.Context, instance *solrv1beta1.SolrCloud, backupsCondition metav1.Condition, logger logr.Logger) error {
+	if backupsCondition.Status == metav1.ConditionTrue {
+		if existing := meta.FindStatusCondition(instance.Status.Conditions, backupsCondition.Type); existing == nil || existing.Reason != backupsCondition.Reason || existing.Message != backupsCondition.Message {
+			meta.SetStatusCondition(&instance.Status.Conditions, backupsCondition)
+			return r.Status().Update(ctx, instance)
+		}
+		return nil
+	}
+
+	if !util.ContainsString(instance.ObjectMeta.Finalizers, util.SolrStorageFinalizer) {
+		return nil
+	}
+	// Use the selector of the StatefulSet to find the PVCs, falling back to the selector that the StatefulSet is generated with
+	pvcLabelSelector := instance.SharedLabels()
+	pvcLabelSelector["technology"] = solrv1beta1.SolrTechnologyLabel
+	foundStatefulSet := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: instance.StatefulSetName(), Namespace: instance.Namespace}, foundStatefulSet); err == nil {
+		pvcLabelSelector = foundStatefulSet.Spec.Selector.MatchLabels
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return r.reconcileStorageFinalizer(ctx, instance, pvcLabelSelector, logger)
+}
+
+// watchSolrBackups reconciles a SolrCloud whenever one of its SolrBackups starts, finishes or is deleted,
+// so that the protection of the SolrCloud for in-progress backups is kept up to date.
+func (r *SolrCloudReconciler) watchSolrBackups(ctrlBuilder *builder.Builder) *builder.Builder {
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrBackup{}},
+		handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				backup, isBackup := obj.(*solrv1beta1.SolrBackup)
+				if !isBackup || backup.Spec.SolrCloud == "" {
+					return []reconcile.Request{}
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: backup.Spec.SolrCloud, Namespace: backup.Namespace}}}
+			}),
+		builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				backup, isBackup := e.Object.(*solrv1beta1.SolrBackup)
+				return isBackup && util.IsBackupInProgress(backup)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldBackup, oldIsBackup := e.ObjectOld.(*solrv1beta1.SolrBackup)
+				newBackup, newIsBackup := e.ObjectNew.(*solrv1beta1.SolrBackup)
+				if !oldIsBackup || !newIsBackup {
+					return false
+				}
+				return util.IsBackupInProgress(oldBackup) != util.IsBackupInProgress(newBackup) ||
+					oldBackup.Spec.RepositoryName != newBackup.Spec.RepositoryName
+			},
+		}))
+}
+
+// watchSolrRestores reconciles a SolrCloud whenever one of its SolrRestores starts, finishes or is deleted,
+// so that the protection of the SolrCloud for in-progress restores is kept up to date.
+func (r *SolrCloudReconciler) watchSolrRestores(ctrlBuilder *builder.Builder) *builder.Builder {
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrRestore{}},
+		handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				restore, isRestore := obj.(*solrv1beta1.SolrRestore)
+				if !isRestore || restore.Spec.SolrCloud == "" {
+					return []reconcile.Request{}
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: restore.Spec.SolrCloud, Namespace: restore.Namespace}}}
+			}),
+		builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				restore, isRestore := e.Object.(*solrv1beta1.SolrRestore)
+				return isRestore && util.IsRestoreInProgress(restore)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldRestore, oldIsRestore := e.ObjectOld.(*solrv1beta1.SolrRestore)
+				newRestore, newIsRestore := e.ObjectNew.(*solrv1beta1.SolrRestore)
+				if !oldIsRestore || !newIsRestore {
+					return false
+				}
+				return util.IsRestoreInProgress(oldRestore) != util.IsRestoreInProgress(newRestore)
+			},
+		}))
+}
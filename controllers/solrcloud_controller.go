@@ -19,30 +19,35 @@ package controllers
 
 import (
 	"context"
-	"crypto/md5"
 	"fmt"
 	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers/util"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
 	"github.com/apache/solr-operator/controllers/zk_api"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -53,32 +58,66 @@ import (
 // SolrCloudReconciler reconciles a SolrCloud object
 type SolrCloudReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	config   *rest.Config
+
+	// The inputs that each SolrCloud's StatefulSet was last generated from, keyed by the SolrCloud's NamespacedName
+	statefulSetInputs sync.Map
+
+	// The verification checks of each SolrCloud's Managed update that last failed, keyed by the SolrCloud's NamespacedName
+	updateVerificationFailures sync.Map
+
+	// The failed vm.max_map_count checks that have been reported for the pods of each SolrCloud, keyed by the SolrCloud's NamespacedName.
+	// The values map pod names to the util.MaxMapCountCheckFailureKey of the reported failure.
+	maxMapCountFailures sync.Map
+
+	// The SOLR_STOP_WAIT warning that was last reported for each SolrCloud, keyed by the SolrCloud's NamespacedName
+	solrStopWaitWarnings sync.Map
+}
+
+// statefulSetInputs records the hash of the inputs that a StatefulSet was generated from, and the generation of the StatefulSet afterwards
+type statefulSetInputs struct {
+	hash       string
+	generation int64
 }
 
 var useZkCRD bool
 
+const (
+	zkEnsembleField = ".spec.zookeeperRef.ensemble"
+)
+
 func UseZkCRD(useCRD bool) {
 	useZkCRD = useCRD
 }
 
-//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
-//+kubebuilder:rbac:groups="",resources=pods/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps/status,verbs=get
-//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete;patch
 //+kubebuilder:rbac:groups=zookeeper.pravega.io,resources=zookeeperclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=zookeeper.pravega.io,resources=zookeeperclusters/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/finalizers,verbs=update
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrrestores,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solroperatorconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,13 +133,40 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			r.statefulSetInputs.Delete(req.NamespacedName)
+			r.updateVerificationFailures.Delete(req.NamespacedName)
+			r.maxMapCountFailures.Delete(req.NamespacedName)
+			r.solrStopWaitWarnings.Delete(req.NamespacedName)
+			solr_api.RemoveCloudCABundle(req.Namespace, req.Name)
+			util.RemoveSolrCollectionMetrics(req.Namespace, req.Name)
+			util.RemoveSolrNodeMetrics(req.Namespace, req.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the req.
 		return reconcile.Result{}, err
 	}
 
-	changed := instance.WithDefaults()
+	// SolrBackups and SolrRestores that are in progress protect the SolrCloud, even when it is being deleted or cannot be reconciled otherwise
+	backupsCondition, blockReconciliationOfStatefulSet, err := r.reconcileBackupProtection(ctx, instance, logger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, r.reconcileDeletion(ctx, instance, backupsCondition, logger)
+	}
+
+	operatorConfig, selected, err := getSolrOperatorConfig(ctx, r.Client, instance)
+	if terminalErr, isTerminal := util.AsTerminalError(err); isTerminal {
+		return reconcile.Result{}, r.reportTerminalError(ctx, instance, terminalErr, logger)
+	} else if err != nil {
+		return reconcile.Result{}, err
+	} else if !selected {
+		logger.V(1).Info("Ignoring SolrCloud, since it is not selected by the SolrOperatorConfig of its namespace")
+		return reconcile.Result{}, nil
+	}
+
+	changed := instance.WithOperatorConfigDefaults(operatorConfig)
+	changed = instance.WithDefaults() || changed
 	if changed {
 		logger.Info("Setting default settings for SolrCloud")
 		if err := r.Update(ctx, instance); err != nil {
@@ -109,40 +175,127 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	requeueOrNot, err := r.reconcileSolrCloud(ctx, logger, instance, backupsCondition, blockReconciliationOfStatefulSet)
+	if terminalErr, isTerminal := util.AsTerminalError(err); isTerminal {
+		// Retrying cannot fix a misconfiguration, so the SolrCloud is not requeued.
+		// It is reconciled again once it, or one of the watched resources that it references, changes.
+		return reconcile.Result{}, r.reportTerminalError(ctx, instance, terminalErr, logger)
+	}
+	return requeueOrNot, err
+}
+
+// reconcileSolrCloud reconciles the resources of a defaulted SolrCloud, that is not being deleted, and its status.
+// The backups condition and blockReconciliationOfStatefulSet are the result of reconcileBackupProtection.
+// Errors caused by misconfigurations are returned as util.TerminalError.
+func (r *SolrCloudReconciler) reconcileSolrCloud(ctx context.Context, logger logr.Logger, instance *solrv1beta1.SolrCloud, backupsCondition metav1.Condition, blockReconciliationOfStatefulSet bool) (reconcile.Result, error) {
+	cloudName := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+
+	err := util.ValidateFIPSCompliance(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateNodeNameTemplate(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateAdvertisedHostTemplate(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateAddressabilityPorts(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateSolrContainerOptions(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidatePodSecurityProfile(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateRuntimeRestrictions(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateStandbyOptions(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateIndexDirectoryOptions(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err = util.ValidateZookeeperSASLOptions(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// A SOLR_STOP_WAIT or lameduck period that is too long for the terminationGracePeriodSeconds is not fatal, but Solr will not be able to stop gracefully.
+	// The warning is only reported when it first occurs or changes, not on every reconcile.
+	if err = util.ValidateSolrStopWait(instance); err != nil {
+		if reported, found := r.solrStopWaitWarnings.Load(cloudName); !found || reported.(string) != err.Error() {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "InvalidSolrStopWait", err.Error())
+			r.solrStopWaitWarnings.Store(cloudName, err.Error())
+		}
+	} else {
+		r.solrStopWaitWarnings.Delete(cloudName)
+	}
+
 	// When working with the clouds, some actions outside of kube may need to be retried after a few seconds
 	requeueOrNot := reconcile.Result{}
 
 	newStatus := solrv1beta1.SolrCloudStatus{}
 
-	blockReconciliationOfStatefulSet := false
 	if err := r.reconcileZk(ctx, logger, instance, &newStatus); err != nil {
 		return requeueOrNot, err
 	}
 
-	// Generate Common Service
-	commonService := util.GenerateCommonService(instance)
+	// Multiple SolrClouds can share a Zookeeper ensemble, but only if their chroots do not overlap
+	if err := r.reconcileSharedZookeeperChRoots(ctx, instance, &newStatus); err != nil {
+		return requeueOrNot, err
+	}
 
-	// Check if the Common Service already exists
-	commonServiceLogger := logger.WithValues("service", commonService.Name)
-	foundCommonService := &corev1.Service{}
-	err = r.Get(ctx, types.NamespacedName{Name: commonService.Name, Namespace: commonService.Namespace}, foundCommonService)
-	if err != nil && errors.IsNotFound(err) {
-		commonServiceLogger.Info("Creating Common Service")
-		if err = controllerutil.SetControllerReference(instance, commonService, r.Scheme); err == nil {
-			err = r.Create(ctx, commonService)
+	// Generate Common Service, unless the user manages the Services of the SolrCloud themselves
+	if instance.ManagesServices() {
+		commonService := util.GenerateCommonService(instance)
+		// Keep client traffic away from the first Solr nodes that start, until enough of them are ready to form the cluster.
+		// The formation is determined from the pods that are ready now, since the status is only updated after the service.
+		if instance.MinReadyNodesForReady() > 0 {
+			readyPods, err := r.countReadySolrPods(ctx, instance)
+			if err != nil {
+				return requeueOrNot, err
+			}
+			if formationCondition := util.ClusterFormationCondition(instance, readyPods); formationCondition.Status != metav1.ConditionTrue {
+				util.ExcludeAllPodsFromService(commonService)
+			}
 		}
-	} else if err == nil {
-		var needsUpdate bool
-		needsUpdate, err = util.OvertakeControllerRef(instance, foundCommonService, r.Scheme)
-		needsUpdate = util.CopyServiceFields(commonService, foundCommonService, commonServiceLogger) || needsUpdate
 
-		// Update the found Service and write the result back if there are any changes
-		if needsUpdate && err == nil {
-			commonServiceLogger.Info("Updating Common Service")
-			err = r.Update(ctx, foundCommonService)
+		// Check if the Common Service already exists
+		commonServiceLogger := logger.WithValues("service", commonService.Name)
+		foundCommonService := &corev1.Service{}
+		err = r.Get(ctx, types.NamespacedName{Name: commonService.Name, Namespace: commonService.Namespace}, foundCommonService)
+		if err != nil && errors.IsNotFound(err) {
+			commonServiceLogger.Info("Creating Common Service")
+			if err = controllerutil.SetControllerReference(instance, commonService, r.Scheme); err == nil {
+				err = r.Create(ctx, commonService)
+			}
+		} else if err == nil {
+			var needsUpdate bool
+			needsUpdate, err = util.OvertakeControllerRef(instance, foundCommonService, r.Scheme)
+			needsUpdate = util.CopyServiceFields(commonService, foundCommonService, commonServiceLogger) || needsUpdate
+
+			// Update the found Service and write the result back if there are any changes
+			if needsUpdate && err == nil {
+				commonServiceLogger.Info("Updating Common Service")
+				err = r.Update(ctx, foundCommonService)
+			}
 		}
-	}
-	if err != nil {
+		if err != nil {
+			return requeueOrNot, err
+		}
+		newStatus.Resources.CommonService = commonService.Name
+	} else if err = r.releaseUnmanagedResource(ctx, instance, instance.CommonServiceName(), &corev1.Service{}, logger); err != nil {
 		return requeueOrNot, err
 	}
 
@@ -156,8 +309,13 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			if err != nil {
 				return requeueOrNot, err
 			}
+			if instance.ManagesServices() {
+				newStatus.Resources.NodeServices = append(newStatus.Resources.NodeServices, nodeName)
+			}
 			// This IP Address only needs to be used in the hostname map if the SolrCloud is advertising the external address.
-			if instance.Spec.SolrAddressability.External.UseExternalAddress {
+			// If Solr advertises a different port or scheme than the node service provides, then the external address must be resolved normally.
+			// An overridden advertised host is also resolved normally.
+			if instance.Spec.SolrAddressability.External.UseExternalAddress && !instance.UsesAdvertisedHostTemplate() && instance.AdvertisedNodePort() == instance.NodePort() && instance.AdvertisedUrlScheme() == instance.UrlScheme(false) {
 				if ip == "" {
 					// If we are using this IP in the hostAliases of the statefulSet, it needs to be set for every service before trying to update the statefulSet
 					blockReconciliationOfStatefulSet = true
@@ -169,7 +327,7 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	// Generate HeadlessService
-	if instance.UsesHeadlessService() {
+	if instance.UsesHeadlessService() && instance.ManagesServices() {
 		headless := util.GenerateHeadlessService(instance)
 
 		// Check if the HeadlessService already exists
@@ -195,21 +353,38 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if err != nil {
 			return requeueOrNot, err
 		}
+		newStatus.Resources.HeadlessService = headless.Name
+	} else if instance.UsesHeadlessService() {
+		if err = r.releaseUnmanagedResource(ctx, instance, instance.HeadlessServiceName(), &corev1.Service{}, logger); err != nil {
+			return requeueOrNot, err
+		}
 	}
 
 	// Use a map to hold additional config info that gets determined during reconcile
 	// needed for creating the STS and supporting objects (secrets, config maps, and so on)
 	reconcileConfigInfo := make(map[string]string)
 
-	// Generate ConfigMap unless the user supplied a custom ConfigMap for solr.xml
+	// Generate ConfigMap unless the user supplied a custom ConfigMap for solr.xml, or manages the ConfigMap of the SolrCloud themselves
+	providedConfigMapName := ""
 	if instance.Spec.CustomSolrKubeOptions.ConfigMapOptions != nil && instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap != "" {
-		providedConfigMapName := instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap
+		providedConfigMapName = instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap
+	} else if !instance.ManagesConfigMap() {
+		providedConfigMapName = instance.ConfigMapName()
+	}
+	if providedConfigMapName != "" {
 		foundConfigMap := &corev1.ConfigMap{}
 		nn := types.NamespacedName{Name: providedConfigMapName, Namespace: instance.Namespace}
 		err = r.Get(ctx, nn, foundConfigMap)
 		if err != nil {
 			return requeueOrNot, err // if they passed a providedConfigMap name, then it must exist
 		}
+		// A ConfigMap that the operator created before the user took over its management is no longer owned by the SolrCloud
+		if !instance.ManagesConfigMap() && util.ReleaseOwnerRef(instance, foundConfigMap) {
+			logger.Info("Removing the SolrCloud owner reference from the unmanaged ConfigMap", "configMap", foundConfigMap.Name)
+			if err = r.Update(ctx, foundConfigMap); err != nil {
+				return requeueOrNot, err
+			}
+		}
 
 		if foundConfigMap.Data != nil {
 			logXml, hasLogXml := foundConfigMap.Data[util.LogXmlFile]
@@ -217,8 +392,7 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 			// if there's a user-provided config, it must have one of the expected keys
 			if !hasLogXml && !hasSolrXml {
-				// TODO: Create event for the CRD.
-				return requeueOrNot, fmt.Errorf("user provided ConfigMap %s must have one of 'solr.xml' and/or 'log4j2.xml'",
+				return requeueOrNot, util.TerminalErrorf(util.InvalidSpecReason, "user provided ConfigMap %s must have one of 'solr.xml' and/or 'log4j2.xml'",
 					providedConfigMapName)
 			}
 
@@ -226,32 +400,75 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				// make sure the user-provided solr.xml is valid
 				if !strings.Contains(solrXml, "${hostPort:") {
 					return requeueOrNot,
-						fmt.Errorf("custom solr.xml in ConfigMap %s must contain a placeholder for the 'hostPort' variable, such as <int name=\"hostPort\">${hostPort:80}</int>",
+						util.TerminalErrorf(util.InvalidSpecReason, "custom solr.xml in ConfigMap %s must contain a placeholder for the 'hostPort' variable, such as <int name=\"hostPort\">${hostPort:80}</int>",
 							providedConfigMapName)
 				}
 				// stored in the pod spec annotations on the statefulset so that we get a restart when solr.xml changes
-				reconcileConfigInfo[util.SolrXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(solrXml)))
+				reconcileConfigInfo[util.SolrXmlMd5Annotation] = util.ContentHash(foundConfigMap, util.SolrXmlFile, func() []byte { return []byte(solrXml) })
 				reconcileConfigInfo[util.SolrXmlFile] = foundConfigMap.Name
 			}
 
 			if hasLogXml {
 				if !strings.Contains(logXml, "monitorInterval=") {
 					// stored in the pod spec annotations on the statefulset so that we get a restart when the log config changes
-					reconcileConfigInfo[util.LogXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(logXml)))
+					reconcileConfigInfo[util.LogXmlMd5Annotation] = util.ContentHash(foundConfigMap, util.LogXmlFile, func() []byte { return []byte(logXml) })
 				} // else log4j will automatically refresh for us, so no restart needed
 				reconcileConfigInfo[util.LogXmlFile] = foundConfigMap.Name
 			}
 
 		} else {
-			return requeueOrNot, fmt.Errorf("provided ConfigMap %s has no data", providedConfigMapName)
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSpecReason, "provided ConfigMap %s has no data", providedConfigMapName)
+		}
+	}
+
+	for _, file := range instance.Spec.CustomSolrKubeOptions.ConfigMapFiles {
+		foundConfigMap := &corev1.ConfigMap{}
+		if err = r.Get(ctx, types.NamespacedName{Name: file.ConfigMap, Namespace: instance.Namespace}, foundConfigMap); err != nil {
+			return requeueOrNot, err // the ConfigMaps for the additional files must exist
+		}
+		content, hasContent := foundConfigMap.Data[file.Key]
+		if !hasContent {
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSpecReason, "user provided ConfigMap %s must have the key '%s' for the file %s",
+				file.ConfigMap, file.Key, file.Name)
+		}
+		if file.RestartOnUpdate == nil || *file.RestartOnUpdate {
+			// stored in the pod spec annotations on the statefulset so that we get a restart when the file changes
+			reconcileConfigInfo[util.ConfigMapFileMd5AnnotationPrefix+file.Name] = util.ContentHash(foundConfigMap, file.Key, func() []byte { return []byte(content) })
+		}
+	}
+
+	// Solr only reads the credentials of its backup repositories on startup, so the pods are restarted when they are rotated
+	var repoCredentials []byte
+	for _, repo := range instance.Spec.BackupRepositories {
+		credentialSecret := util.RepoCredentialSecret(&repo)
+		if credentialSecret == nil {
+			continue
+		}
+		foundSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: credentialSecret.Name, Namespace: instance.Namespace}, foundSecret); err != nil && errors.IsNotFound(err) {
+			// The pods cannot start without the secret, and are restarted once it is created
+			err = nil
+			continue
+		} else if err != nil {
+			return requeueOrNot, err
 		}
+		repoCredentials = append(repoCredentials, []byte(repo.Name+"\n")...)
+		repoCredentials = append(repoCredentials, foundSecret.Data[credentialSecret.Key]...)
+	}
+	if len(repoCredentials) > 0 {
+		reconcileConfigInfo[util.BackupRepoCredentialsAnnotation] = util.HashContent(repoCredentials)
 	}
 
 	if reconcileConfigInfo[util.SolrXmlFile] == "" {
+		if !instance.ManagesConfigMap() {
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSpecReason, "ConfigMap %s must have a 'solr.xml', since 'managedResources.manageConfigMap' is false",
+				providedConfigMapName)
+		}
+
 		// no user provided solr.xml, so create the default
 		configMap := util.GenerateConfigMap(instance)
 
-		reconcileConfigInfo[util.SolrXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(configMap.Data[util.SolrXmlFile])))
+		reconcileConfigInfo[util.SolrXmlMd5Annotation] = util.HashContent([]byte(configMap.Data[util.SolrXmlFile]))
 		reconcileConfigInfo[util.SolrXmlFile] = configMap.Name
 
 		// Check if the ConfigMap already exists
@@ -278,27 +495,47 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			return requeueOrNot, err
 		}
 	}
+	newStatus.Resources.ConfigMap = reconcileConfigInfo[util.SolrXmlFile]
+
+	if err = util.ValidateManagedSecurityJsonOptions(instance); err != nil {
+		return requeueOrNot, err
+	}
 
 	basicAuthHeader := ""
-	if instance.Spec.SolrSecurity != nil {
+	if instance.UsesKerberos() {
+		// The operator cannot authenticate through Kerberos, so its requests to Solr are sent without credentials
+		if err = util.ValidateKerberosOptions(instance); err != nil {
+			return requeueOrNot, err
+		}
+	} else if instance.Spec.SolrSecurity != nil {
 		sec := instance.Spec.SolrSecurity
 
 		if sec.AuthenticationType != solrv1beta1.Basic {
-			return requeueOrNot, fmt.Errorf("%s not supported! Only 'Basic' authentication is supported by the Solr operator",
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSecurityConfigReason, "%s not supported! Only 'Basic' and 'Kerberos' authentication are supported by the Solr operator",
 				instance.Spec.SolrSecurity.AuthenticationType)
 		}
 
+		// the operator's user must be distinct from the other users bootstrapped in the security.json
+		if sec.BasicAuthSecret == "" && (instance.OperatorUsername() == "admin" || instance.OperatorUsername() == "solr") {
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSecurityConfigReason, "invalid 'solrSecurity.operatorUsername' %s, the operator's user cannot be the bootstrapped 'admin' or 'solr' user",
+				instance.OperatorUsername())
+		}
+
 		// for now, we don't support 'solrSecurity.probesRequireAuth=true' and custom probe paths,
 		// so make the user fix that so there are no surprises later
 		if sec.ProbesRequireAuth && instance.Spec.CustomSolrKubeOptions.PodOptions != nil {
 			for _, path := range util.GetCustomProbePaths(instance) {
 				if path != util.DefaultProbePath {
-					return requeueOrNot, fmt.Errorf(
+					return requeueOrNot, util.TerminalErrorf(util.InvalidSecurityConfigReason,
 						"custom probe path %s not supported when 'solrSecurity.probesRequireAuth=true'; must use 'solrSecurity.probesRequireAuth=false' when using custom probe endpoints", path)
 				}
 			}
 		}
 
+		if sec.BasicAuthSecret != "" && sec.BootstrapCredentialsSecret != "" {
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSecurityConfigReason, "'solrSecurity.bootstrapCredentialsSecret' cannot be combined with 'solrSecurity.basicAuthSecret', since the operator does not bootstrap the security.json when a basicAuthSecret is provided")
+		}
+
 		basicAuthSecret := &corev1.Secret{}
 
 		// user has the option of providing a secret with credentials the operator should use to make requests to Solr
@@ -317,7 +554,18 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			// since we randomly generate the passwords, we need to lookup the secret first and only create if not exist
 			err = r.Get(ctx, types.NamespacedName{Name: instance.BasicAuthSecretName(), Namespace: instance.Namespace}, basicAuthSecret)
 			if err != nil && errors.IsNotFound(err) {
-				authSecret, bootstrapSecret := util.GenerateBasicAuthSecretWithBootstrap(instance)
+				// the passwords can be sourced from a secret that the user provides, such as one synced from an external secret store
+				var credentialsSecret *corev1.Secret
+				if sec.BootstrapCredentialsSecret != "" {
+					credentialsSecret = &corev1.Secret{}
+					if err := r.Get(ctx, types.NamespacedName{Name: sec.BootstrapCredentialsSecret, Namespace: instance.Namespace}, credentialsSecret); err != nil {
+						return requeueOrNot, err
+					}
+					if err := util.ValidateBootstrapCredentialsSecret(instance, credentialsSecret); err != nil {
+						return requeueOrNot, err
+					}
+				}
+				authSecret, bootstrapSecret := util.GenerateBasicAuthSecretWithBootstrap(instance, credentialsSecret)
 				if err := controllerutil.SetControllerReference(instance, authSecret, r.Scheme); err != nil {
 					return requeueOrNot, err
 				}
@@ -356,6 +604,10 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 
 		reconcileConfigInfo[corev1.BasicAuthUsernameKey] = string(basicAuthSecret.Data[corev1.BasicAuthUsernameKey])
+		newStatus.Resources.BasicAuthSecret = instance.BasicAuthSecretName()
+		if reconcileConfigInfo[util.SecurityJsonFile] != "" {
+			newStatus.Resources.SecurityBootstrapSecret = instance.SecurityBootstrapSecretName()
+		}
 
 		// need the creds below for getting CLUSTERSTATUS
 		basicAuthHeader = util.BasicAuthHeader(basicAuthSecret)
@@ -371,7 +623,7 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// can't have a solrClientTLS w/o solrTLS!
 	if instance.Spec.SolrTLS == nil && instance.Spec.SolrClientTLS != nil {
-		return requeueOrNot, fmt.Errorf("invalid TLS config, `spec.solrTLS` is not defined; `spec.solrClientTLS` can only be used in addition to `spec.solrTLS`")
+		return requeueOrNot, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, `spec.solrTLS` is not defined; `spec.solrClientTLS` can only be used in addition to `spec.solrTLS`")
 	}
 
 	// don't start reconciling TLS until we have ZK connectivity, avoids TLS code having to check for ZK
@@ -380,31 +632,63 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if err != nil {
 			return requeueOrNot, err
 		}
+
+	}
+
+	// The CA bundle that the Solr Operator uses to verify this SolrCloud's server certificate
+	if err = r.reconcileOperatorClientCABundle(ctx, instance); err != nil {
+		return requeueOrNot, err
 	}
 
 	pvcLabelSelector := make(map[string]string, 0)
 	var statefulSetStatus appsv1.StatefulSetStatus
+	// Set when the StatefulSet is scaled, to publish the scaling CloudEvent once the status has been saved
+	var scaledReplicas map[string]int32
+
+	// The restarts and scaling caused by updates to the StatefulSet are added to the history
+	newStatus.History = instance.Status.History
 
 	if !blockReconciliationOfStatefulSet {
-		// Generate StatefulSet
-		statefulSet := util.GenerateStatefulSet(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, tls)
+		// Hash everything that the StatefulSet is generated from, so that it is only re-generated and compared when an input has changed
+		var inputsHash string
+		if inputsHash, err = util.StatefulSetInputsHash(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, tls); err != nil {
+			return requeueOrNot, err
+		}
 
 		// Check if the StatefulSet already exists
-		statefulSetLogger := logger.WithValues("statefulSet", statefulSet.Name)
+		statefulSetName := instance.StatefulSetName()
+		// A new StatefulSet would be created next to the existing one, with pods that use different Solr Node names
+		existingStatefulSetName := instance.Status.Resources.StatefulSet
+		if existingStatefulSetName == "" {
+			// The StatefulSet is not recorded in the status yet, such as by older versions of the Solr Operator, so look for it instead
+			if existingStatefulSetName, err = r.findControlledStatefulSetName(ctx, instance); err != nil {
+				return requeueOrNot, err
+			}
+		}
+		if existingStatefulSetName != "" && existingStatefulSetName != statefulSetName {
+			return requeueOrNot, util.TerminalErrorf(util.InvalidSpecReason, "'customSolrKubeOptions.statefulSetOptions.podNamePrefix' cannot be changed from %s to %s once the StatefulSet has been created",
+				existingStatefulSetName, statefulSetName)
+		}
+		statefulSetLogger := logger.WithValues("statefulSet", statefulSetName)
 		foundStatefulSet := &appsv1.StatefulSet{}
-		err = r.Get(ctx, types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, foundStatefulSet)
-
-		// Set the annotation for a scheduled restart, if necessary.
-		if nextRestartAnnotation, reconcileWaitDuration, err := util.ScheduleNextRestart(instance.Spec.UpdateStrategy.RestartSchedule, foundStatefulSet.Spec.Template.Annotations); err != nil {
-			logger.Error(err, "Cannot parse restartSchedule cron: %s", instance.Spec.UpdateStrategy.RestartSchedule)
+		err = r.Get(ctx, types.NamespacedName{Name: statefulSetName, Namespace: instance.Namespace}, foundStatefulSet)
+
+		// Determine the annotation for a scheduled restart, if necessary.
+		restartAnnotation := ""
+		newRestartScheduled := false
+		// Solr only reads the per-pod certificates when it starts, so the pods are restarted before their certificates expire, unless there is a restartSchedule
+		restartSchedule := util.PodCertificateRestartSchedule(instance)
+		if nextRestartAnnotation, reconcileWaitDuration, err := util.ScheduleNextRestart(restartSchedule, foundStatefulSet.Spec.Template.Annotations); err != nil {
+			logger.Error(err, "Cannot parse restartSchedule cron: %s", restartSchedule)
 		} else {
 			if nextRestartAnnotation != "" {
 				// Set the new restart time annotation
-				statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = nextRestartAnnotation
+				restartAnnotation = nextRestartAnnotation
+				newRestartScheduled = true
 				// TODO: Create event for the CRD.
 			} else if existingRestartAnnotation, exists := foundStatefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation]; exists {
 				// Keep the existing nextRestart annotation if it exists and we aren't setting a new one.
-				statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = existingRestartAnnotation
+				restartAnnotation = existingRestartAnnotation
 			}
 			if reconcileWaitDuration != nil {
 				// Set the requeueAfter if it has not been set, or is greater than the time we need to wait to restart again
@@ -414,10 +698,14 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 		// Update or Create the StatefulSet
 		if err != nil && errors.IsNotFound(err) {
+			statefulSet := r.generateStatefulSet(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, tls, restartAnnotation)
 			statefulSetLogger.Info("Creating StatefulSet")
 			if err = controllerutil.SetControllerReference(instance, statefulSet, r.Scheme); err == nil {
 				err = r.Create(ctx, statefulSet)
 			}
+			if err == nil {
+				r.statefulSetInputs.Store(cloudName, statefulSetInputs{hash: inputsHash, generation: statefulSet.Generation})
+			}
 			// Find which labels the PVCs will be using, to use for the finalizer
 			pvcLabelSelector = statefulSet.Spec.Selector.MatchLabels
 		} else if err == nil {
@@ -428,22 +716,55 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			// Check to see if the StatefulSet needs an update
 			var needsUpdate bool
 			needsUpdate, err = util.OvertakeControllerRef(instance, foundStatefulSet, r.Scheme)
-			needsUpdate = util.CopyStatefulSetFields(statefulSet, foundStatefulSet, statefulSetLogger) || needsUpdate
+
+			// The StatefulSet only needs to be generated and compared if its inputs have changed, or it was modified by someone else
+			var operations []solrv1beta1.SolrCloudOperation
+			if newRestartScheduled || !r.statefulSetInputsUnchanged(cloudName, inputsHash, foundStatefulSet) {
+				statefulSet := r.generateStatefulSet(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, tls, restartAnnotation)
+				if restartReasons := util.SetPodRestartReason(&foundStatefulSet.Spec.Template, &statefulSet.Spec.Template); len(restartReasons) > 0 {
+					operations = append(operations, util.RestartOperation(restartReasons))
+				}
+
+				if foundStatefulSet.Spec.Replicas != nil && *foundStatefulSet.Spec.Replicas != *statefulSet.Spec.Replicas {
+					scaledReplicas = map[string]int32{
+						"fromReplicas": *foundStatefulSet.Spec.Replicas,
+						"toReplicas":   *statefulSet.Spec.Replicas,
+					}
+					operations = append(operations, util.ScaleOperation(*foundStatefulSet.Spec.Replicas, *statefulSet.Spec.Replicas))
+				}
+
+				needsUpdate = util.CopyStatefulSetFields(statefulSet, foundStatefulSet, statefulSetLogger) || needsUpdate
+			}
 
 			// Update the found StatefulSet and write the result back if there are any changes
 			if needsUpdate && err == nil {
 				statefulSetLogger.Info("Updating StatefulSet")
 				err = r.Update(ctx, foundStatefulSet)
 			}
+			if err == nil {
+				for _, operation := range operations {
+					if operation.Type == solrv1beta1.SolrCloudRestartOperation {
+						statefulSetLogger.Info("Restarting Solr pods", "reasons", operation.Reasons)
+						r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RestartingPods", "The Solr pods are being restarted because of: %s", strings.Join(operation.Reasons, ", "))
+					}
+					util.AddSolrCloudOperation(&newStatus, operation)
+				}
+			}
+			if err == nil {
+				r.statefulSetInputs.Store(cloudName, statefulSetInputs{hash: inputsHash, generation: foundStatefulSet.Generation})
+			}
 		}
 		if err != nil {
+			r.statefulSetInputs.Delete(cloudName)
 			return requeueOrNot, err
 		}
+		newStatus.Resources.StatefulSet = statefulSetName
 	} else {
 		// If we are blocking the reconciliation of the statefulSet, we still want to find information about it.
 		foundStatefulSet := &appsv1.StatefulSet{}
 		err = r.Get(ctx, types.NamespacedName{Name: instance.StatefulSetName(), Namespace: instance.Namespace}, foundStatefulSet)
 		if err == nil {
+			newStatus.Resources.StatefulSet = foundStatefulSet.Name
 			// Find the status
 			statefulSetStatus = foundStatefulSet.Status
 			// Find which labels the PVCs will be using, to use for the finalizer
@@ -458,17 +779,182 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if len(pvcLabelSelector) > 0 {
 		if err := r.reconcileStorageFinalizer(ctx, instance, pvcLabelSelector, logger); err != nil {
 			logger.Error(err, "Cannot delete PVCs while garbage collecting after deletion.")
-			updateRequeueAfter(&requeueOrNot, time.Second*15)
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		}
+		if instance.UsesPersistentStorage() && instance.PropagatesPVCMetadata() {
+			if err := r.reconcileDataPVCMetadata(ctx, instance, pvcLabelSelector, logger); err != nil {
+				logger.Error(err, "Could not propagate labels and annotations to the data PVCs, will retry later")
+				updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+			}
 		}
 	}
 
 	var outOfDatePods, outOfDatePodsNotStarted []corev1.Pod
 	var availableUpdatedPodCount int
-	outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err = r.reconcileCloudStatus(ctx, instance, logger, &newStatus, statefulSetStatus)
+	outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err = r.reconcileCloudStatus(ctx, instance, logger, &newStatus, statefulSetStatus, &requeueOrNot)
 	if err != nil {
 		return requeueOrNot, err
 	}
 
+	// If authn enabled on Solr, we need to pass the basic auth header
+	var httpHeaders map[string]string
+	if basicAuthHeader != "" {
+		httpHeaders = map[string]string{"Authorization": basicAuthHeader}
+	}
+	// All decisions below that depend on the Solr cluster state share a single fetch of it
+	clusterState := util.NewSolrClusterState(instance, httpHeaders)
+
+	// Keep the deletion cost of Solr pods up to date with the replicas that they host, so that scale-downs remove the cheapest pods first.
+	// Changes to the Solr cluster state do not trigger a reconcile, so the costs are refreshed periodically.
+	if instance.Spec.Scaling.PodDeletionCost && newStatus.ReadyReplicas > 0 {
+		if err = r.reconcilePodDeletionCosts(ctx, instance, clusterState, logger); err != nil {
+			logger.Error(err, "Could not set the deletion cost of Solr pods, will retry later")
+		}
+		updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueSteadyState))
+	}
+
+	// Export the inventory of collections, shards and replicas. Changes to the Solr cluster state do not trigger a reconcile,
+	// so the inventory is refreshed periodically.
+	if instance.Spec.Inventory != nil {
+		// the last exported inventory is kept while it cannot be refreshed
+		newStatus.Resources.InventoryConfigMap = instance.Status.Resources.InventoryConfigMap
+		if newStatus.ReadyReplicas > 0 {
+			if err = r.reconcileInventoryConfigMap(ctx, instance, clusterState, &newStatus, logger); err != nil {
+				logger.Error(err, "Could not export the SolrCloud inventory, will retry later")
+			}
+			updateRequeueAfter(&requeueOrNot, time.Second*time.Duration(instance.Spec.Inventory.RefreshIntervalSeconds))
+		}
+	}
+
+	// Export the metrics of each collection. Like the inventory, these are refreshed periodically.
+	if instance.Spec.CollectionMetrics == nil {
+		util.RemoveSolrCollectionMetrics(instance.Namespace, instance.Name)
+	} else if newStatus.ReadyReplicas > 0 {
+		nextRefresh, metricsErr := util.UpdateSolrCollectionMetrics(instance, clusterState, httpHeaders)
+		if metricsErr != nil {
+			logger.Error(metricsErr, "Could not export the collection metrics, will retry later")
+		}
+		updateRequeueAfter(&requeueOrNot, nextRefresh)
+	}
+
+	// Relay a small set of metrics from each Solr pod, as a lightweight alternative to a SolrPrometheusExporter
+	if instance.Spec.NodeMetrics == nil {
+		util.RemoveSolrNodeMetrics(instance.Namespace, instance.Name)
+	} else {
+		nextRefresh, metricsErr := util.UpdateSolrNodeMetrics(instance, newStatus.SolrNodes, httpHeaders)
+		if metricsErr != nil {
+			logger.Error(metricsErr, "Could not relay the metrics of the Solr pods, will retry later")
+		}
+		updateRequeueAfter(&requeueOrNot, nextRefresh)
+	}
+
+	// Collect diagnostics from the Solr pods, once for every new request through the collectDiagnostics annotation.
+	// Logs can be collected from pods that are not ready, so this does not wait for Solr to be available.
+	newStatus.Diagnostics = instance.Status.Diagnostics
+	newStatus.Resources.DiagnosticsConfigMap = instance.Status.Resources.DiagnosticsConfigMap
+	if requestId, pending := util.PendingDiagnosticsRequest(instance); pending && len(newStatus.SolrNodes) > 0 {
+		if err = r.reconcileDiagnostics(ctx, instance, requestId, httpHeaders, &newStatus, logger); err != nil {
+			logger.Error(err, "Could not collect diagnostics, will retry later", "diagnosticsRequest", requestId)
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		}
+	}
+
+	// Move shard leaders, and optionally replicas, off of pods whose Nodes are about to be interrupted, so that they are not lost along with the Nodes.
+	if instance.Spec.NodeInterruption != nil && newStatus.ReadyReplicas > 0 {
+		if inProgress, err := r.reconcileNodeInterruptions(ctx, instance, clusterState, httpHeaders, logger); err != nil {
+			logger.Error(err, "Could not move shard leaders or replicas off of interrupted Nodes, will retry later")
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueLeaderMovement))
+		} else if inProgress {
+			// Leader elections and added replicas complete asynchronously, so check back soon to make sure the leaders have moved
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueLeaderMovement))
+		}
+	}
+
+	// Restore the latest backup of the primary into a standby SolrCloud, on its schedule.
+	// Once the standby is promoted, by removing standbyOf, the restore in progress is stopped.
+	if instance.Spec.StandbyOf != nil {
+		if newStatus.ReadyReplicas > 0 {
+			if requeueAfter, err := r.reconcileStandby(ctx, instance, clusterState, httpHeaders, &newStatus, logger); err != nil {
+				if _, isTerminal := util.AsTerminalError(err); isTerminal {
+					return requeueOrNot, err
+				}
+				logger.Error(err, "Could not restore the standby collections, will retry later")
+				updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+			} else if requeueAfter > 0 {
+				updateRequeueAfter(&requeueOrNot, requeueAfter)
+			}
+		} else {
+			newStatus.Standby = instance.Status.Standby
+		}
+	} else if err = r.stopStandby(ctx, instance, logger); err != nil {
+		logger.Error(err, "Could not stop the standby restore of the promoted SolrCloud, will retry later")
+		updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		newStatus.Standby = instance.Status.Standby
+	}
+
+	// Put the collections into, or take them out of, read-only mode.
+	// New collections can be created at any time, so the read-only mode is re-applied periodically while it is enabled.
+	// Collections are not modified while a standby restore is in progress, since the collections being restored cannot be made read-only yet.
+	readOnly := instance.CollectionsReadOnly()
+	newStatus.ReadOnly = instance.Status.ReadOnly
+	standbyRestoring := newStatus.Standby != nil && newStatus.Standby.SolrRestore != ""
+	if (readOnly || instance.Status.ReadOnly) && newStatus.ReadyReplicas > 0 && !standbyRestoring {
+		if err = util.ReconcileCollectionsReadOnly(instance, readOnly, clusterState, httpHeaders, logger); err != nil {
+			logger.Error(err, "Could not set the read-only mode of collections, will retry later", "readOnly", readOnly)
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		} else {
+			newStatus.ReadOnly = readOnly
+		}
+		if readOnly {
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueSteadyState))
+		}
+	}
+
+	// Sync files, such as synonyms and stopwords, from ConfigMaps into configsets, and reload the collections that use them.
+	// The files are synced once Solr is available, until then the status of the last sync is kept.
+	newStatus.ConfigSetFiles = instance.Status.ConfigSetFiles
+	if (len(instance.Spec.ConfigSetFiles) > 0 || len(instance.Status.ConfigSetFiles) > 0) && newStatus.ReadyReplicas > 0 {
+		if err = r.reconcileConfigSetFiles(ctx, instance, clusterState, httpHeaders, &newStatus, logger); err != nil {
+			logger.Error(err, "Could not sync files into configsets, will retry later")
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		}
+		// Changes to the configsets in Zookeeper do not trigger a reconcile, so they are checked for drift periodically
+		for _, configSetFiles := range instance.Spec.ConfigSetFiles {
+			if configSetFiles.DriftPolicy != solrv1beta1.IgnoreConfigSetDrift {
+				updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueConfigSetDrift))
+				break
+			}
+		}
+	}
+
+	// Keep the security.json in Zookeeper in sync with the securityJsonSecret, once Solr is available.
+	// The status of the last sync is kept otherwise, so that an unchanged security.json is only compared again after the drift interval.
+	newStatus.SecurityJson = instance.Status.SecurityJson
+	if util.UsesManagedSecurityJson(instance) && newStatus.ReadyReplicas > 0 {
+		if err = r.reconcileManagedSecurityJson(ctx, instance, &newStatus, logger); err != nil {
+			logger.Error(err, "Could not sync the managed security.json, will retry later")
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		}
+		// Changes made to the security.json through Solr's APIs do not trigger a reconcile, so they are checked for periodically
+		updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueSecurityJsonDrift))
+	} else if !util.UsesManagedSecurityJson(instance) {
+		newStatus.SecurityJson = nil
+	}
+
+	// Delete the replicas that were temporarily added to shards with a single replica, once the pods hosting those shards have been updated.
+	newStatus.TemporaryReplicas = instance.Status.TemporaryReplicas
+	if len(instance.Status.TemporaryReplicas) > 0 && newStatus.ReadyReplicas > 0 {
+		outOfDatePodNames := map[string]bool{}
+		for _, pod := range append(append([]corev1.Pod(nil), outOfDatePods...), outOfDatePodsNotStarted...) {
+			outOfDatePodNames[pod.Name] = true
+		}
+		if newStatus.TemporaryReplicas, err = util.RemoveTemporaryReplicas(instance, instance.Status.TemporaryReplicas, outOfDatePodNames, clusterState, httpHeaders, logger); err != nil {
+			logger.Error(err, "Could not delete temporary replicas, will retry later")
+		}
+		// Changes to the Solr cluster state do not trigger a reconcile, so check back until the replicas of the updated pods are active
+		updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueManagedUpdate))
+	}
+
 	// Manage the updating of out-of-spec pods, if the Managed UpdateStrategy has been specified.
 	totalPodCount := int(*instance.Spec.Replicas)
 	if instance.Spec.UpdateStrategy.Method == solrv1beta1.ManagedUpdate && len(outOfDatePods)+len(outOfDatePodsNotStarted) > 0 {
@@ -481,16 +967,72 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			logger.Info("Pod killed for update.", "pod", pod.Name, "reason", "The solr container in the pod has not yet started, thus it is safe to update.")
 		}
 
-		// If authn enabled on Solr, we need to pass the basic auth header
-		var authHeader map[string]string
-		if basicAuthHeader != "" {
-			authHeader = map[string]string{"Authorization": basicAuthHeader}
+		// Find the zones of the Solr pods, if the number of pods that can be updated is limited per zone
+		var zoneState *util.ZoneUpdateState
+		if instance.Spec.UpdateStrategy.ManagedUpdateOptions.MaxPodsUnavailablePerZone != nil {
+			if zoneState, err = r.getZoneUpdateState(ctx, instance, newStatus.SolrNodes); err != nil {
+				return requeueOrNot, err
+			}
+		}
+
+		// Pods that have already been taken out of service are deleted once they have drained, they are not picked again.
+		// Since they are no longer passed as out of date pods, they count towards the unavailable pods for the update.
+		var drainPeriod time.Duration
+		if drainSeconds := instance.Spec.UpdateStrategy.ManagedUpdateOptions.DrainSeconds; drainSeconds != nil && *drainSeconds > 0 {
+			drainPeriod = time.Second * time.Duration(*drainSeconds)
+			var notDrainingPods []corev1.Pod
+			for _, pod := range outOfDatePods {
+				if draining, remaining := util.PodDrainTimeRemaining(&pod, drainPeriod, time.Now()); !draining {
+					notDrainingPods = append(notDrainingPods, pod)
+				} else if remaining > 0 {
+					updateRequeueAfter(&requeueOrNot, remaining)
+				} else {
+					logger.Info("Pod killed for update.", "pod", pod.Name, "reason", "The pod has been drained of connections.")
+					podsToUpdate = append(podsToUpdate, pod)
+				}
+			}
+			outOfDatePods = notDrainingPods
 		}
 
 		// Pick which pods should be deleted for an update.
 		// Don't exit on an error, which would only occur because of an HTTP Exception. Requeue later instead.
-		additionalPodsToUpdate, retryLater := util.DeterminePodsSafeToUpdate(instance, outOfDatePods, totalPodCount, int(newStatus.ReadyReplicas), availableUpdatedPodCount, len(outOfDatePodsNotStarted), updateLogger, authHeader)
-		podsToUpdate = append(podsToUpdate, additionalPodsToUpdate...)
+		singleReplicaState := util.NewSingleReplicaUpdateState()
+		additionalPodsToUpdate, retryLater := util.DeterminePodsSafeToUpdate(instance, outOfDatePods, totalPodCount, int(newStatus.ReadyReplicas), availableUpdatedPodCount, len(outOfDatePodsNotStarted), zoneState, singleReplicaState, updateLogger, clusterState)
+		if err = r.reconcileSingleReplicaShards(instance, singleReplicaState, append(outOfDatePods, outOfDatePodsNotStarted...), clusterState, httpHeaders, &newStatus, updateLogger); err != nil {
+			updateLogger.Error(err, "Could not add temporary replicas to shards with a single replica, will retry later")
+		}
+
+		// Only restart the next batch of pods once the pods that have already been updated pass the verification checks
+		if checks := instance.Spec.UpdateStrategy.ManagedUpdateOptions.VerificationChecks; len(checks) > 0 && len(additionalPodsToUpdate) > 0 {
+			failures, unverifiedNodes := util.RunUpdateVerificationChecks(instance, newStatus.SolrNodes, checks, httpHeaders)
+			r.reportUpdateVerificationFailures(instance, failures)
+			if len(failures) > 0 || len(unverifiedNodes) > 0 {
+				updateLogger.Info("Pod update selection canceled. The updated pods did not pass the verification checks.", "failures", failures, "notReady", unverifiedNodes)
+				additionalPodsToUpdate = nil
+				retryLater = true
+			}
+		}
+
+		// Take the picked pods out of service, so that connections are drained before the pods are deleted
+		if drainPeriod > 0 {
+			for _, pod := range additionalPodsToUpdate {
+				if !util.HasServingReadinessGate(&pod) {
+					// Pods created before the readiness gate was enabled cannot be drained
+					podsToUpdate = append(podsToUpdate, pod)
+					continue
+				}
+				updateLogger.Info("Taking pod out of service to drain connections before the update.", "pod", pod.Name, "drainSeconds", drainPeriod.Seconds())
+				util.SetPodServing(&pod, false, "PodUpdating", time.Now())
+				if err = r.Status().Update(ctx, &pod); err != nil {
+					updateLogger.Error(err, "Error while taking solr pod out of service for update", "pod", pod.Name)
+				}
+			}
+			if len(additionalPodsToUpdate) > 0 {
+				updateRequeueAfter(&requeueOrNot, drainPeriod)
+			}
+		} else {
+			podsToUpdate = append(podsToUpdate, additionalPodsToUpdate...)
+		}
 
 		for _, pod := range podsToUpdate {
 			err = r.Delete(ctx, &pod, client.Preconditions{
@@ -501,41 +1043,55 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 			// TODO: Create event for the CRD.
 		}
-		if err != nil || retryLater {
-			updateRequeueAfter(&requeueOrNot, time.Second*15)
+		if err != nil {
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueRetry))
+		} else if retryLater {
+			updateRequeueAfter(&requeueOrNot, util.RequeueAfter(util.RequeueManagedUpdate))
 		}
 	}
 
-	extAddressabilityOpts := instance.Spec.SolrAddressability.External
-	if extAddressabilityOpts != nil && extAddressabilityOpts.Method == solrv1beta1.Ingress {
-		// Generate Ingress
-		ingress := util.GenerateIngress(instance, solrNodeNames)
-
-		// Check if the Ingress already exists
-		ingressLogger := logger.WithValues("ingress", ingress.Name)
-		foundIngress := &netv1.Ingress{}
-		err = r.Get(ctx, types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}, foundIngress)
-		if err != nil && errors.IsNotFound(err) {
-			ingressLogger.Info("Creating Ingress")
-			if err = controllerutil.SetControllerReference(instance, ingress, r.Scheme); err == nil {
-				err = r.Create(ctx, ingress)
-			}
-		} else if err == nil {
-			var needsUpdate bool
-			needsUpdate, err = util.OvertakeControllerRef(instance, foundIngress, r.Scheme)
-			needsUpdate = util.CopyIngressFields(ingress, foundIngress, ingressLogger) || needsUpdate
-
-			// Update the found Ingress and write the result back if there are any changes
-			if needsUpdate && err == nil {
-				ingressLogger.Info("Updating Ingress")
-				err = r.Update(ctx, foundIngress)
-			}
+	if instance.Spec.SolrAddressability.External != nil {
+		var requeueAfter time.Duration
+		if requeueAfter, err = r.reconcileExternalAddressability(ctx, instance, solrNodeNames, &newStatus, logger); err != nil {
+			return requeueOrNot, err
+		} else if requeueAfter > 0 {
+			updateRequeueAfter(&requeueOrNot, requeueAfter)
 		}
-		if err != nil {
+	}
+
+	if instance.Spec.ConnectionInfo != nil {
+		if err = r.reconcileConnectionInfoSecret(ctx, logger, instance, &newStatus, tls); err != nil {
 			return requeueOrNot, err
 		}
 	}
 
+	// Only report when Zookeeper becomes unavailable or available again, the reason itself can change on every reconcile, e.g. the number of ready members
+	if newStatus.ZookeeperError != "" && instance.Status.ZookeeperError == "" {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ZookeeperUnavailable", newStatus.ZookeeperError)
+	} else if newStatus.ZookeeperError == "" && instance.Status.ZookeeperError != "" {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ZookeeperAvailable", "Zookeeper is available again")
+	}
+
+	newStatus.Phase = newStatus.CalculatePhase(*instance.Spec.Replicas)
+	newStatus.ObservedGeneration = instance.Generation
+	newStatus.Conditions = append([]metav1.Condition(nil), instance.Status.Conditions...)
+	meta.SetStatusCondition(&newStatus.Conditions, metav1.Condition{
+		Type:               solrv1beta1.SolrCloudConfigurationValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Valid",
+		ObservedGeneration: instance.Generation,
+	})
+	meta.SetStatusCondition(&newStatus.Conditions, backupsCondition)
+	if formationCondition := util.ClusterFormationCondition(instance, newStatus.ReadyReplicas); formationCondition != nil {
+		meta.SetStatusCondition(&newStatus.Conditions, *formationCondition)
+		if formationCondition.Status == metav1.ConditionFalse {
+			newStatus.Phase = solrv1beta1.SolrCloudPending
+		}
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solrv1beta1.SolrCloudClusterFormed)
+	}
+
+	oldPhase := instance.Status.Phase
 	if !reflect.DeepEqual(instance.Status, newStatus) {
 		instance.Status = newStatus
 		logger.Info("Updating SolrCloud Status", "status", instance.Status)
@@ -545,11 +1101,101 @@ func (r *SolrCloudReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	// CloudEvents are only published once the status that they describe has been saved, so that they are not published again if the update fails
+	if scaledReplicas != nil {
+		util.PublishCloudEvent(util.SolrCloudScaledEvent, "solrclouds", instance, scaledReplicas)
+	}
+	if oldPhase != newStatus.Phase {
+		publishPhaseChangeEvent(instance, oldPhase, newStatus.Phase)
+	}
+
 	return requeueOrNot, nil
 }
 
+// reportTerminalError marks the configuration of the SolrCloud as invalid, with the reason and message of the terminal error
+func (r *SolrCloudReconciler) reportTerminalError(ctx context.Context, instance *solrv1beta1.SolrCloud, terminalErr *util.TerminalError, logger logr.Logger) error {
+	logger.Error(terminalErr, "The SolrCloud is misconfigured, it will be reconciled again once it or the resources it references change", "reason", terminalErr.Reason)
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrCloudConfigurationValid,
+		Status:             metav1.ConditionFalse,
+		Reason:             terminalErr.Reason,
+		Message:            terminalErr.Error(),
+		ObservedGeneration: instance.Generation,
+	}
+	if existing := meta.FindStatusCondition(instance.Status.Conditions, condition.Type); existing != nil &&
+		existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message && existing.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	r.Recorder.Event(instance, corev1.EventTypeWarning, terminalErr.Reason, terminalErr.Error())
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+	return r.Status().Update(ctx, instance)
+}
+
+// reportUpdateVerificationFailures emits an event when the verification checks of a Managed update start failing, fail differently, or pass again.
+// Checks are re-run on every reconcile while the update is paused, so the same failures are only reported once.
+func (r *SolrCloudReconciler) reportUpdateVerificationFailures(instance *solrv1beta1.SolrCloud, failures []util.UpdateVerificationFailure) {
+	cloudName := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+	failuresKey := util.UpdateVerificationFailuresKey(failures)
+	lastFailuresKey := ""
+	if stored, found := r.updateVerificationFailures.Load(cloudName); found {
+		lastFailuresKey = stored.(string)
+	}
+	if failuresKey == lastFailuresKey {
+		return
+	}
+	if len(failures) > 0 {
+		problems := make([]string, len(failures))
+		for i, failure := range failures {
+			problems[i] = failure.String()
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UpdateVerificationFailed",
+			"The Managed update is paused until the updated pods pass the verification checks: %s", strings.Join(problems, "; "))
+		r.updateVerificationFailures.Store(cloudName, failuresKey)
+	} else {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "UpdateVerificationPassed", "The updated pods passed the verification checks, the Managed update continues")
+		r.updateVerificationFailures.Delete(cloudName)
+	}
+}
+
+// generateStatefulSet generates the StatefulSet for the SolrCloud, including the given scheduled restart annotation, if any
+func (r *SolrCloudReconciler) generateStatefulSet(instance *solrv1beta1.SolrCloud, newStatus *solrv1beta1.SolrCloudStatus, hostNameIpMap map[string]string, reconcileConfigInfo map[string]string, tls *util.TLSCerts, restartAnnotation string) *appsv1.StatefulSet {
+	statefulSet := util.GenerateStatefulSet(instance, newStatus, hostNameIpMap, reconcileConfigInfo, tls)
+	if restartAnnotation != "" {
+		statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = restartAnnotation
+	}
+	return statefulSet
+}
+
+// statefulSetInputsUnchanged determines whether the StatefulSet was last generated from the same inputs, and has not been modified since.
+// Any change to the StatefulSet spec, by the Solr Operator or anyone else, increments its generation.
+func (r *SolrCloudReconciler) statefulSetInputsUnchanged(cloud types.NamespacedName, inputsHash string, foundStatefulSet *appsv1.StatefulSet) bool {
+	lastInputs, found := r.statefulSetInputs.Load(cloud)
+	return found && lastInputs.(statefulSetInputs).hash == inputsHash && lastInputs.(statefulSetInputs).generation == foundStatefulSet.Generation
+}
+
+// getZoneUpdateState determines the zone of each Solr pod, using the zone label of the Kubernetes Node that it is running on.
+// Pods that have not been scheduled, or run on Nodes without the zone label, are treated as being in the same, unnamed, zone.
+func (r *SolrCloudReconciler) getZoneUpdateState(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, solrNodes []solrv1beta1.SolrNodeStatus) (*util.ZoneUpdateState, error) {
+	zoneLabel := solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.ZoneTopologyKey
+	zoneState := util.NewZoneUpdateState()
+	nodeZones := map[string]string{}
+	for _, solrNode := range solrNodes {
+		zone, found := nodeZones[solrNode.NodeName]
+		if !found && solrNode.NodeName != "" {
+			node := &corev1.Node{}
+			if err := r.Get(ctx, types.NamespacedName{Name: solrNode.NodeName}, node); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+			zone = node.Labels[zoneLabel]
+			nodeZones[solrNode.NodeName] = zone
+		}
+		zoneState.AddPod(solrNode.Name, zone, solrNode.Ready)
+	}
+	return zoneState, nil
+}
+
 func (r *SolrCloudReconciler) reconcileCloudStatus(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, logger logr.Logger,
-	newStatus *solrv1beta1.SolrCloudStatus, statefulSetStatus appsv1.StatefulSetStatus) (outOfDatePods []corev1.Pod, outOfDatePodsNotStarted []corev1.Pod, availableUpdatedPodCount int, err error) {
+	newStatus *solrv1beta1.SolrCloudStatus, statefulSetStatus appsv1.StatefulSetStatus, requeueOrNot *reconcile.Result) (outOfDatePods []corev1.Pod, outOfDatePodsNotStarted []corev1.Pod, availableUpdatedPodCount int, err error) {
 	foundPods := &corev1.PodList{}
 	selectorLabels := solrCloud.SharedLabels()
 	selectorLabels["technology"] = solrv1beta1.SolrTechnologyLabel
@@ -582,6 +1228,13 @@ func (r *SolrCloudReconciler) reconcileCloudStatus(ctx context.Context, solrClou
 		return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
 	}
 	newStatus.PodSelector = selector.String()
+	var warmUpPeriod time.Duration
+	if warmUpSeconds := solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.WarmUpSeconds; warmUpSeconds != nil {
+		warmUpPeriod = time.Second * time.Duration(*warmUpSeconds)
+	}
+	// Failures are reported for each run of the check, not on every reconcile
+	maxMapCountFailures, _ := r.maxMapCountFailures.LoadOrStore(types.NamespacedName{Name: solrCloud.Name, Namespace: solrCloud.Namespace}, map[string]string{})
+	reportedMaxMapCountFailures := maxMapCountFailures.(map[string]string)
 	allPodsBackupReady := true
 	for idx, p := range foundPods.Items {
 		nodeNames[idx] = p.Name
@@ -600,20 +1253,56 @@ func (r *SolrCloudReconciler) reconcileCloudStatus(ctx context.Context, solrClou
 			}
 		}
 
-		// Check whether the node is considered "ready" by kubernetes
-		nodeStatus.Ready = false
-		for _, condition := range p.Status.Conditions {
-			if condition.Type == corev1.PodReady {
-				nodeStatus.Ready = condition.Status == corev1.ConditionTrue
+		// Report pods that cannot connect to Zookeeper
+		if zkFailed, reason := util.ZkSetupFailure(&p); zkFailed {
+			reason = fmt.Sprintf("Pod %s cannot connect to Zookeeper: %s", p.Name, reason)
+			if newStatus.ZookeeperError == "" {
+				newStatus.ZookeeperError = reason
 			}
 		}
-		if nodeStatus.Ready {
-			newStatus.ReadyReplicas += 1
-		}
 
-		// Skip "backup-readiness" check for pod if we've already found a pod that's not ready
-		if allPodsBackupReady {
-			allPodsBackupReady = allPodsBackupReady && isPodReadyForBackup(&p, solrCloud)
+		// Report pods that were scheduled onto a node whose vm.max_map_count is too low
+		if checkFailed, reason := util.MaxMapCountCheckFailure(&p); !checkFailed {
+			delete(reportedMaxMapCountFailures, p.Name)
+		} else if failureKey := util.MaxMapCountCheckFailureKey(&p); reportedMaxMapCountFailures[p.Name] != failureKey {
+			r.Recorder.Event(&p, corev1.EventTypeWarning, "MaxMapCountTooLow", reason)
+			reportedMaxMapCountFailures[p.Name] = failureKey
+		}
+
+		// Pods with the serving readiness gate cannot become ready until the Solr Operator has set its condition.
+		// If a warm-up period is given, the pod is only put into service once its containers have been ready for that long.
+		// Pods whose containers are not yet ready are reconciled again once they become ready.
+		if util.NeedsServingCondition(&p) && p.DeletionTimestamp.IsZero() {
+			if warmUpPeriod <= 0 {
+				util.SetPodServing(&p, true, "PodStarted", time.Now())
+				if err = r.Status().Update(ctx, &p); err != nil {
+					return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+				}
+			} else if containersReady, remaining := util.PodWarmUpTimeRemaining(&p, warmUpPeriod, time.Now()); containersReady && remaining > 0 {
+				updateRequeueAfter(requeueOrNot, remaining)
+			} else if containersReady {
+				logger.Info("Putting pod into service after warming up.", "pod", p.Name, "warmUpSeconds", warmUpPeriod.Seconds())
+				util.SetPodServing(&p, true, "PodWarmedUp", time.Now())
+				if err = r.Status().Update(ctx, &p); err != nil {
+					return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+				}
+			}
+		}
+
+		// Custom labels and annotations with per-pod variables cannot be set through the StatefulSet pod template
+		if err = r.reconcilePerPodMetadata(ctx, solrCloud, &p, logger); err != nil {
+			return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+		}
+
+		// Check whether the node is considered "ready" by kubernetes
+		nodeStatus.Ready = isPodReady(&p)
+		if nodeStatus.Ready {
+			newStatus.ReadyReplicas += 1
+		}
+
+		// Skip "backup-readiness" check for pod if we've already found a pod that's not ready
+		if allPodsBackupReady {
+			allPodsBackupReady = allPodsBackupReady && isPodReadyForBackup(&p, solrCloud)
 		}
 
 		// A pod is out of date if it's revision label is not equal to the statefulSetStatus' updateRevision.
@@ -674,6 +1363,378 @@ func (r *SolrCloudReconciler) reconcileCloudStatus(ctx context.Context, solrClou
 	return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, nil
 }
 
+// reconcilePodDeletionCosts sets the deletion cost annotation on each Solr pod, using the shard leaders and replicas hosted by the pod
+func (r *SolrCloudReconciler) reconcilePodDeletionCosts(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, clusterState *util.SolrClusterState, logger logr.Logger) error {
+	foundPods := &corev1.PodList{}
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solrv1beta1.SolrTechnologyLabel
+	if err := r.List(ctx, foundPods, client.InNamespace(solrCloud.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+
+	podCosts, err := util.CalculatePodDeletionCosts(solrCloud, foundPods.Items, clusterState)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range foundPods.Items {
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		patchFrom := client.MergeFrom(pod.DeepCopy())
+		if util.SetPodDeletionCost(&pod, podCosts[pod.Name]) {
+			logger.V(1).Info("Setting pod deletion cost", "pod", pod.Name, "cost", podCosts[pod.Name])
+			if err = r.Patch(ctx, &pod, patchFrom); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileInventoryConfigMap creates or updates the ConfigMap containing the inventory of collections, shards and replicas in the SolrCloud
+func (r *SolrCloudReconciler) reconcileInventoryConfigMap(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, clusterState *util.SolrClusterState, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) error {
+	foundPods := &corev1.PodList{}
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solrv1beta1.SolrTechnologyLabel
+	if err := r.List(ctx, foundPods, client.InNamespace(solrCloud.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+
+	inventory, err := util.FetchSolrCloudInventory(solrCloud, foundPods.Items, clusterState)
+	if err != nil {
+		return err
+	}
+	configMap, err := util.GenerateInventoryConfigMap(solrCloud, inventory)
+	if err != nil {
+		return err
+	}
+
+	// Check if the ConfigMap already exists
+	configMapLogger := logger.WithValues("configMap", configMap.Name)
+	foundConfigMap := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, foundConfigMap)
+	if err != nil && errors.IsNotFound(err) {
+		configMapLogger.Info("Creating Inventory ConfigMap")
+		if err = controllerutil.SetControllerReference(solrCloud, configMap, r.Scheme); err == nil {
+			err = r.Create(ctx, configMap)
+		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(solrCloud, foundConfigMap, r.Scheme)
+		needsUpdate = util.CopyConfigMapFields(configMap, foundConfigMap, configMapLogger) || needsUpdate
+
+		// Update the found ConfigMap and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			configMapLogger.V(1).Info("Updating Inventory ConfigMap")
+			err = r.Update(ctx, foundConfigMap)
+		}
+	}
+	if err == nil {
+		newStatus.Resources.InventoryConfigMap = configMap.Name
+	}
+	return err
+}
+
+// reconcileConfigSetFiles uploads the files from spec.configSetFiles into their configsets whenever the files change,
+// and reloads the collections that use those configsets.
+// Unless drift is ignored, the configsets are also checked periodically for changes that were made to them outside of the ConfigMaps.
+// Configsets that fail to sync are retried on the next reconcile.
+func (r *SolrCloudReconciler) reconcileConfigSetFiles(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, clusterState *util.SolrClusterState, httpHeaders map[string]string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (err error) {
+	syncedConfigSets := make(map[string]solrv1beta1.ConfigSetFilesStatus, len(solrCloud.Status.ConfigSetFiles))
+	for _, configSetStatus := range solrCloud.Status.ConfigSetFiles {
+		syncedConfigSets[configSetStatus.ConfigSet] = configSetStatus
+	}
+
+	newStatus.ConfigSetFiles = nil
+	for i := range solrCloud.Spec.ConfigSetFiles {
+		configSetFiles := &solrCloud.Spec.ConfigSetFiles[i]
+		configSetStatus, synced := syncedConfigSets[configSetFiles.ConfigSet]
+
+		var syncErr error
+		foundConfigMap := &corev1.ConfigMap{}
+		if syncErr = r.Get(ctx, types.NamespacedName{Name: configSetFiles.ConfigMap, Namespace: solrCloud.Namespace}, foundConfigMap); syncErr == nil {
+			var files map[string][]byte
+			var contentHash string
+			if files, contentHash, syncErr = util.ConfigSetFilesContent(configSetFiles, foundConfigMap); syncErr == nil {
+				if !synced || configSetStatus.ContentHash != contentHash {
+					if reloadedCollections, uploadErr := util.SyncConfigSetFiles(solrCloud, configSetFiles.ConfigSet, files, clusterState, httpHeaders, logger); uploadErr != nil {
+						syncErr = uploadErr
+					} else {
+						r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "ConfigSetSynced",
+							"Synced %d files into configset %s and reloaded the collections: %v", len(files), configSetFiles.ConfigSet, reloadedCollections)
+						configSetStatus = solrv1beta1.ConfigSetFilesStatus{
+							ConfigSet:           configSetFiles.ConfigSet,
+							ContentHash:         contentHash,
+							ReloadedCollections: reloadedCollections,
+							LastSyncTime:        metav1.Now(),
+						}
+						synced = true
+					}
+				} else if configSetFiles.DriftPolicy != solrv1beta1.IgnoreConfigSetDrift &&
+					(configSetStatus.LastDriftCheckTime == nil || time.Since(configSetStatus.LastDriftCheckTime.Time) >= util.RequeueAfter(util.RequeueConfigSetDrift)) {
+					syncErr = r.reconcileConfigSetDrift(solrCloud, configSetFiles, &configSetStatus, files, clusterState, httpHeaders, logger)
+				}
+			}
+		}
+		if syncErr != nil {
+			r.Recorder.Event(solrCloud, corev1.EventTypeWarning, "ConfigSetSyncFailed", syncErr.Error())
+			err = syncErr
+		}
+		if configSetFiles.DriftPolicy == solrv1beta1.IgnoreConfigSetDrift {
+			configSetStatus.DriftedFiles = nil
+			configSetStatus.LastDriftCheckTime = nil
+		}
+		// Keep the status of the last successful sync, so that unchanged files are not uploaded again
+		if synced {
+			newStatus.ConfigSetFiles = append(newStatus.ConfigSetFiles, configSetStatus)
+		}
+	}
+	return err
+}
+
+// reconcileConfigSetDrift compares the synced files of a configset in Zookeeper with the ConfigMap, and looks for changes made through the Config API.
+// Drifted files are reported in the status, and repaired if the drift policy asks for it.
+func (r *SolrCloudReconciler) reconcileConfigSetDrift(solrCloud *solrv1beta1.SolrCloud, configSetFiles *solrv1beta1.ConfigSetFiles, configSetStatus *solrv1beta1.ConfigSetFilesStatus, files map[string][]byte, clusterState *util.SolrClusterState, httpHeaders map[string]string, logger logr.Logger) error {
+	driftedFiles, err := util.FindConfigSetDrift(solrCloud, configSetFiles.ConfigSet, files, httpHeaders)
+	if err != nil {
+		return err
+	}
+	now := metav1.Now()
+	configSetStatus.LastDriftCheckTime = &now
+	configSetStatus.DriftedFiles = driftedFiles
+	if len(driftedFiles) == 0 {
+		return nil
+	}
+
+	if configSetFiles.DriftPolicy != solrv1beta1.RepairConfigSetDrift {
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "ConfigSetDrifted",
+			"The files %v of configset %s no longer match the ConfigMap %s", driftedFiles, configSetFiles.ConfigSet, configSetFiles.ConfigMap)
+		return nil
+	}
+
+	logger.Info("Repairing drifted configset files", "configSet", configSetFiles.ConfigSet, "files", driftedFiles)
+	reloadedCollections, err := util.SyncConfigSetFiles(solrCloud, configSetFiles.ConfigSet, util.ConfigSetDriftRepairs(files, driftedFiles), clusterState, httpHeaders, logger)
+	if err != nil {
+		return err
+	}
+	r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "ConfigSetRepaired",
+		"Repaired the drifted files %v of configset %s and reloaded the collections: %v", driftedFiles, configSetFiles.ConfigSet, reloadedCollections)
+	configSetStatus.DriftedFiles = nil
+	configSetStatus.ReloadedCollections = reloadedCollections
+	return nil
+}
+
+// reconcileManagedSecurityJson uploads the security.json from the securityJsonSecret to Zookeeper, when it changes,
+// and periodically restores it if it was changed in Zookeeper, such as through Solr's authentication and authorization APIs.
+// The upload goes through a ready Solr pod, so that it does not depend on the operator being able to authenticate with Solr.
+func (r *SolrCloudReconciler) reconcileManagedSecurityJson(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (err error) {
+	selector := solrCloud.Spec.SolrSecurity.SecurityJsonSecret
+	secret := &corev1.Secret{}
+	if err = r.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: solrCloud.Namespace}, secret); err != nil {
+		return err
+	}
+	content, contentHash, err := util.ManagedSecurityJsonContent(selector, secret)
+	if err != nil {
+		r.Recorder.Event(solrCloud, corev1.EventTypeWarning, "SecurityJsonSyncFailed", err.Error())
+		return err
+	}
+
+	lastStatus := solrCloud.Status.SecurityJson
+	if lastStatus != nil && lastStatus.ContentHash == contentHash && time.Since(lastStatus.LastCheckTime.Time) < util.RequeueAfter(util.RequeueSecurityJsonDrift) {
+		return nil
+	}
+
+	readyPod := ""
+	for _, node := range newStatus.SolrNodes {
+		if node.Ready {
+			readyPod = node.Name
+			break
+		}
+	}
+	if readyPod == "" {
+		return nil
+	}
+
+	uploaded, err := util.SyncManagedSecurityJson(solrCloud, readyPod, content, r.config)
+	if err != nil {
+		r.Recorder.Event(solrCloud, corev1.EventTypeWarning, "SecurityJsonSyncFailed", err.Error())
+		return err
+	}
+	now := metav1.Now()
+	securityJsonStatus := &solrv1beta1.ManagedSecurityJsonStatus{
+		ContentHash:   contentHash,
+		LastCheckTime: now,
+	}
+	if lastStatus != nil {
+		securityJsonStatus.LastSyncTime = lastStatus.LastSyncTime
+	}
+	if uploaded {
+		logger.Info("Uploaded the managed security.json to Zookeeper", "secret", selector.Name)
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "SecurityJsonSynced",
+			"Uploaded the security.json from secret %s to Zookeeper", selector.Name)
+		securityJsonStatus.LastSyncTime = &now
+	}
+	newStatus.SecurityJson = securityJsonStatus
+	return nil
+}
+
+// reconcileSingleReplicaShards handles the pods that host the only replica of shards, which were picked for, or held back from, a Managed update.
+// Depending on the singleReplicaShards policy, either an event warns that the shards become unavailable, an event explains why the update is blocked,
+// or the shards are given temporary replicas on other Solr nodes so that the pods can be updated once those replicas are active.
+func (r *SolrCloudReconciler) reconcileSingleReplicaShards(solrCloud *solrv1beta1.SolrCloud, singleReplicaState *util.SingleReplicaUpdateState, outOfDatePods []corev1.Pod, clusterState *util.SolrClusterState, httpHeaders map[string]string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (err error) {
+	for _, podName := range sortedPodNames(singleReplicaState.UpdatedPods) {
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "SingleReplicaShardsUnavailable",
+			"Restarting pod %s for an update, shards with a single replica are unavailable until it is ready again: %s", podName, singleReplicaShardNames(singleReplicaState.UpdatedPods[podName]))
+	}
+	if len(singleReplicaState.BlockedPods) == 0 {
+		return nil
+	}
+	if solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.SingleReplicaShards != solrv1beta1.AddReplicaForSingleReplicaShards {
+		for _, podName := range sortedPodNames(singleReplicaState.BlockedPods) {
+			r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "UpdateBlocked",
+				"Pod %s is not updated, since it hosts the only replica of shards: %s", podName, singleReplicaShardNames(singleReplicaState.BlockedPods[podName]))
+		}
+		return nil
+	}
+
+	outOfDateNodes := make(map[string]bool, len(outOfDatePods))
+	for _, pod := range outOfDatePods {
+		outOfDateNodes[util.SolrNodeName(solrCloud, pod)] = true
+	}
+	for _, podName := range sortedPodNames(singleReplicaState.BlockedPods) {
+		added, addErr := util.AddTemporaryReplicas(solrCloud, podName, singleReplicaState.BlockedPods[podName], outOfDateNodes, newStatus.TemporaryReplicas, clusterState, httpHeaders, logger)
+		newStatus.TemporaryReplicas = append(newStatus.TemporaryReplicas, added...)
+		if len(added) > 0 {
+			r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "AddingTemporaryReplicas",
+				"Adding temporary replicas to %d shards with a single replica, before pod %s is updated", len(added), podName)
+		}
+		if addErr != nil {
+			return addErr
+		}
+	}
+	return nil
+}
+
+func sortedPodNames(podShards map[string][]util.SingleReplicaShard) []string {
+	podNames := make([]string, 0, len(podShards))
+	for podName := range podShards {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+	return podNames
+}
+
+func singleReplicaShardNames(shards []util.SingleReplicaShard) string {
+	names := make([]string, len(shards))
+	for i, shard := range shards {
+		names[i] = shard.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// reconcileNodeInterruptions moves shard leaders off of the Solr pods running on Nodes that are about to be interrupted.
+// If requested, replicas are first added on other pods for the shards that would otherwise be lost along with the Nodes.
+func (r *SolrCloudReconciler) reconcileNodeInterruptions(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, clusterState *util.SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (inProgress bool, err error) {
+	foundPods := &corev1.PodList{}
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solrv1beta1.SolrTechnologyLabel
+	if err = r.List(ctx, foundPods, client.InNamespace(solrCloud.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return false, err
+	}
+
+	interruptedSolrNodes := map[string]bool{}
+	var interruptedPods []string
+	nodeInterruptions := map[string]bool{}
+	for _, pod := range foundPods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		interrupted, found := nodeInterruptions[pod.Spec.NodeName]
+		if !found {
+			node := &corev1.Node{}
+			if err = r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil && !errors.IsNotFound(err) {
+				return false, err
+			}
+			interrupted = util.IsNodeInterrupted(node, solrCloud.Spec.NodeInterruption.TaintKeys)
+			nodeInterruptions[pod.Spec.NodeName] = interrupted
+		}
+		if interrupted {
+			interruptedSolrNodes[util.SolrNodeName(solrCloud, pod)] = true
+			interruptedPods = append(interruptedPods, pod.Name)
+		}
+	}
+	if len(interruptedSolrNodes) == 0 {
+		return false, nil
+	}
+
+	if solrCloud.Spec.NodeInterruption.EvacuateReplicas {
+		addedReplicas, err := util.EvacuateReplicasOffSolrNodes(solrCloud, interruptedSolrNodes, clusterState, httpHeaders, logger)
+		if addedReplicas > 0 {
+			inProgress = true
+			r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "EvacuatingReplicas",
+				"Adding %d replicas to evacuate shards off of pods on interrupted Nodes: %s", addedReplicas, strings.Join(interruptedPods, ", "))
+		}
+		if err != nil {
+			return inProgress, err
+		}
+	}
+
+	movingShards, err := util.MoveLeadersOffSolrNodes(solrCloud, interruptedSolrNodes, clusterState, httpHeaders, logger)
+	if err == nil && movingShards > 0 {
+		inProgress = true
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "MovingLeaders",
+			"Moving the leaders of %d shards off of pods on interrupted Nodes: %s", movingShards, strings.Join(interruptedPods, ", "))
+	}
+	return inProgress, err
+}
+
+// reconcileOperatorClientCABundle provides the CA bundle from the SolrCloud's operatorClient options to the http client used for the SolrCloud
+func (r *SolrCloudReconciler) reconcileOperatorClientCABundle(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) error {
+	var caBundle []byte
+	if solrCloud.Spec.OperatorClient != nil && solrCloud.Spec.OperatorClient.CABundleSecret != nil {
+		caBundleSecret := solrCloud.Spec.OperatorClient.CABundleSecret
+		foundSecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: caBundleSecret.Name, Namespace: solrCloud.Namespace}, foundSecret); err != nil {
+			return err
+		}
+		if caBundle = foundSecret.Data[caBundleSecret.Key]; len(caBundle) == 0 {
+			return fmt.Errorf("%s key not found in operatorClient CA bundle secret %s", caBundleSecret.Key, caBundleSecret.Name)
+		}
+	}
+	return solr_api.SetCloudCABundle(solrCloud, caBundle)
+}
+
+// reconcilePerPodMetadata sets the custom labels and annotations that use per-pod variables, such as $(POD_ORDINAL), on a Solr pod
+func (r *SolrCloudReconciler) reconcilePerPodMetadata(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, pod *corev1.Pod, logger logr.Logger) error {
+	zone := ""
+	if util.UsesZonePodTemplateVar(solrCloud) {
+		if pod.Spec.NodeName == "" {
+			// The zone is not known until the pod has been scheduled, the pod will be reconciled again once it is running
+			return nil
+		}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		zoneLabel := solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.ZoneTopologyKey
+		if zoneLabel == "" {
+			zoneLabel = solrv1beta1.DefaultZoneTopologyKey
+		}
+		zone = node.Labels[zoneLabel]
+	}
+
+	podLabels, podAnnotations := util.GeneratePerPodMetadata(solrCloud, pod.Name, zone)
+	if len(podLabels) == 0 && len(podAnnotations) == 0 {
+		return nil
+	}
+	patchFrom := client.MergeFrom(pod.DeepCopy())
+	if util.CopyLabelsAndAnnotations(&metav1.ObjectMeta{Labels: podLabels, Annotations: podAnnotations}, &pod.ObjectMeta, logger.WithValues("pod", pod.Name)) {
+		return r.Patch(ctx, pod, patchFrom)
+	}
+	return nil
+}
+
 func isPodReadyForBackup(pod *corev1.Pod, solrCloud *solrv1beta1.SolrCloud) bool {
 	// If solrcloud doesn't request backup support then everything is 'ready' implicitly
 	if len(solrCloud.Spec.BackupRepositories) == 0 {
@@ -697,6 +1758,21 @@ func (r *SolrCloudReconciler) reconcileNodeService(ctx context.Context, logger l
 	nodeServiceLogger := logger.WithValues("service", service.Name)
 	foundService := &corev1.Service{}
 	err = r.Get(ctx, types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, foundService)
+
+	// A Node Service that the user manages is only read, for its IP
+	if !instance.ManagesServices() {
+		if err == nil {
+			ip = foundService.Spec.ClusterIP
+			if util.ReleaseOwnerRef(instance, foundService) {
+				nodeServiceLogger.Info("Removing the SolrCloud owner reference from the unmanaged Node Service")
+				err = r.Update(ctx, foundService)
+			}
+		} else if errors.IsNotFound(err) {
+			err = nil
+		}
+		return err, ip
+	}
+
 	if err != nil && errors.IsNotFound(err) {
 		nodeServiceLogger.Info("Creating Node Service")
 		if err = controllerutil.SetControllerReference(instance, service, r.Scheme); err == nil {
@@ -722,18 +1798,173 @@ func (r *SolrCloudReconciler) reconcileNodeService(ctx context.Context, logger l
 
 	return nil, ip
 }
+
+// publishPhaseChangeEvent publishes the lifecycle CloudEvent, if any, that corresponds to a change in the phase of a SolrCloud
+func publishPhaseChangeEvent(instance *solrv1beta1.SolrCloud, oldPhase solrv1beta1.SolrCloudPhase, newPhase solrv1beta1.SolrCloudPhase) {
+	var eventType string
+	switch {
+	case newPhase == solrv1beta1.SolrCloudUpdating:
+		eventType = util.SolrCloudUpdateStartedEvent
+	case newPhase == solrv1beta1.SolrCloudReady && oldPhase == solrv1beta1.SolrCloudUpdating:
+		eventType = util.SolrCloudUpdateCompletedEvent
+	case newPhase == solrv1beta1.SolrCloudReady:
+		eventType = util.SolrCloudReadyEvent
+	case newPhase == solrv1beta1.SolrCloudDegraded:
+		eventType = util.SolrCloudDegradedEvent
+	default:
+		return
+	}
+	util.PublishCloudEvent(eventType, "solrclouds", instance, map[string]solrv1beta1.SolrCloudPhase{
+		"fromPhase": oldPhase,
+		"toPhase":   newPhase,
+	})
+}
+
+// findControlledStatefulSetName returns the name of the StatefulSet that is controlled by the SolrCloud, if there is one
+func (r *SolrCloudReconciler) findControlledStatefulSetName(ctx context.Context, instance *solrv1beta1.SolrCloud) (string, error) {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSets, client.InNamespace(instance.Namespace), client.MatchingLabels(instance.SharedLabels())); err != nil {
+		return "", err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if metav1.IsControlledBy(&statefulSet, instance) {
+			return statefulSet.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// reconcileConnectionInfoSecret creates or updates the Secret containing the information client applications need to connect to the SolrCloud
+func (r *SolrCloudReconciler) reconcileConnectionInfoSecret(ctx context.Context, logger logr.Logger, instance *solrv1beta1.SolrCloud, newStatus *solrv1beta1.SolrCloudStatus, tls *util.TLSCerts) (err error) {
+	// Include the CA of the server certificate, if it is available in the TLS secret
+	var caCert []byte
+	if tls != nil && tls.ServerConfig.Options.PKCS12Secret != nil {
+		tlsSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: tls.ServerConfig.Options.PKCS12Secret.Name, Namespace: instance.Namespace}, tlsSecret); err != nil {
+			return err
+		}
+		caCert = tlsSecret.Data[util.ConnectionInfoCACertKey]
+	}
+
+	var appUserSecret *corev1.Secret
+	if instance.Spec.ConnectionInfo.AppUserSecret != "" {
+		appUserSecret = &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: instance.Spec.ConnectionInfo.AppUserSecret, Namespace: instance.Namespace}, appUserSecret); err != nil {
+			return err
+		}
+		if err = util.ValidateBasicAuthSecret(appUserSecret); err != nil {
+			return err
+		}
+	}
+
+	secret := util.GenerateConnectionInfoSecret(instance, newStatus, caCert, appUserSecret)
+
+	// Check if the Secret already exists
+	secretLogger := logger.WithValues("secret", secret.Name)
+	foundSecret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, foundSecret)
+	if err != nil && errors.IsNotFound(err) {
+		secretLogger.Info("Creating Connection Info Secret")
+		if err = controllerutil.SetControllerReference(instance, secret, r.Scheme); err == nil {
+			err = r.Create(ctx, secret)
+		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(instance, foundSecret, r.Scheme)
+		needsUpdate = util.CopySecretFields(secret, foundSecret, secretLogger) || needsUpdate
+
+		// Update the found Secret and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			secretLogger.Info("Updating Connection Info Secret")
+			err = r.Update(ctx, foundSecret)
+		}
+	}
+	if err == nil {
+		newStatus.Resources.ConnectionInfoSecret = secret.Name
+		newStatus.Binding = &corev1.LocalObjectReference{Name: secret.Name}
+	}
+	return err
+}
+
+// reconcileSharedZookeeperChRoots finds the other SolrClouds that use the same external Zookeeper ensemble, and makes sure that
+// their chroots do not overlap with the chroot of this SolrCloud. When chroots overlap, the SolrCloud that was created first keeps running,
+// and a terminal error is returned for this SolrCloud. It is reconciled again when it, or another SolrCloud using the ensemble, changes.
+func (r *SolrCloudReconciler) reconcileSharedZookeeperChRoots(ctx context.Context, instance *solrv1beta1.SolrCloud, newStatus *solrv1beta1.SolrCloudStatus) error {
+	ensembleKey, chRoot := instance.Spec.ZookeeperRef.ExternalEnsemble(instance.Namespace)
+	if ensembleKey == "" {
+		return nil
+	}
+
+	foundClouds := &solrv1beta1.SolrCloudList{}
+	if err := r.List(ctx, foundClouds, client.MatchingFields{zkEnsembleField: ensembleKey}); err != nil {
+		return err
+	}
+	sort.Slice(foundClouds.Items, func(i, j int) bool {
+		return foundClouds.Items[i].Namespace+"/"+foundClouds.Items[i].Name < foundClouds.Items[j].Namespace+"/"+foundClouds.Items[j].Name
+	})
+	for _, other := range foundClouds.Items {
+		if other.Namespace == instance.Namespace && other.Name == instance.Name {
+			continue
+		}
+		other.WithDefaults()
+		otherName := other.Namespace + "/" + other.Name
+		_, otherChRoot := other.Spec.ZookeeperRef.ExternalEnsemble(other.Namespace)
+		newStatus.SharedZookeeperChRoots = append(newStatus.SharedZookeeperChRoots, solrv1beta1.SharedZookeeperChRoot{
+			SolrCloud: otherName,
+			ChRoot:    otherChRoot,
+		})
+
+		// The SolrCloud that was created first keeps the chroot
+		createdFirst := other.CreationTimestamp.Before(&instance.CreationTimestamp) ||
+			(other.CreationTimestamp.Equal(&instance.CreationTimestamp) && otherName < instance.Namespace+"/"+instance.Name)
+		if createdFirst && solrv1beta1.ZookeeperChRootsOverlap(chRoot, otherChRoot) {
+			return util.TerminalErrorf(util.ZookeeperChRootConflictReason, "the Zookeeper chroot %s overlaps with the chroot %s of SolrCloud %s, which uses the same Zookeeper ensemble; use a distinct chroot for each SolrCloud",
+				chRoot, otherChRoot, otherName)
+		}
+	}
+	return nil
+}
+
 func (r *SolrCloudReconciler) reconcileZk(ctx context.Context, logger logr.Logger, instance *solrv1beta1.SolrCloud, newStatus *solrv1beta1.SolrCloudStatus) error {
 	zkRef := instance.Spec.ZookeeperRef
 
 	if zkRef.ConnectionInfo != nil {
 		newStatus.ZookeeperConnectionInfo = *zkRef.ConnectionInfo
+	} else if zkRef.ServiceRef != nil {
+		serviceRef := zkRef.ServiceRef
+		serviceName := types.NamespacedName{Name: serviceRef.Name, Namespace: serviceRef.NamespaceOr(instance.Namespace)}
+		newStatus.ZookeeperConnectionInfo = solrv1beta1.ZookeeperConnectionInfo{
+			ChRoot:      serviceRef.ChRoot,
+			AllACL:      serviceRef.AllACL,
+			ReadOnlyACL: serviceRef.ReadOnlyACL,
+		}
+
+		// The SolrCloud is reconciled again whenever the Service or its Endpoints change
+		foundService := &corev1.Service{}
+		err := r.Get(ctx, serviceName, foundService)
+		if err != nil {
+			newStatus.ZookeeperError = fmt.Sprintf("Could not find the Zookeeper Service %s: %s", serviceName, err)
+			return err
+		}
+		foundEndpoints := &corev1.Endpoints{}
+		if err = r.Get(ctx, serviceName, foundEndpoints); err != nil && !errors.IsNotFound(err) {
+			return err
+		} else if err != nil {
+			foundEndpoints = nil
+		}
+		newStatus.ZookeeperConnectionInfo.InternalConnectionString, err = util.ZookeeperConnectionStringFromService(foundService, foundEndpoints, serviceRef.PortName, instance.Spec.SolrAddressability.KubeDomain)
+		if err != nil {
+			newStatus.ZookeeperError = fmt.Sprintf("Could not resolve the Zookeeper connection string: %s", err)
+		}
+		return err
 	} else if zkRef.ProvidedZookeeper != nil {
 		pzk := zkRef.ProvidedZookeeper
 		// Generate ZookeeperCluster
 		if !useZkCRD {
-			return errors.NewBadRequest("Cannot create a Zookeeper Cluster, as the Solr Operator is not configured to use the Zookeeper CRD")
+			return util.TerminalErrorf(util.InvalidSpecReason, "Cannot create a Zookeeper Cluster, as the Solr Operator is not configured to use the Zookeeper CRD")
 		}
 		zkCluster := util.GenerateZookeeperCluster(instance, pzk)
+		newStatus.Resources.ProvidedZookeeper = zkCluster.Name
 
 		// Check if the ZookeeperCluster already exists
 		zkLogger := logger.WithValues("zookeeperCluster", zkCluster.Name)
@@ -769,9 +2000,15 @@ func (r *SolrCloudReconciler) reconcileZk(ctx context.Context, logger logr.Logge
 			ExternalConnectionString: external,
 			ChRoot:                   pzk.ChRoot,
 		}
+
+		// Solr pods will wait in the setup-zk initContainer until the ensemble is available.
+		// The SolrCloud is reconciled again whenever the status of the ZookeeperCluster changes.
+		if foundZkCluster.Status.ReadyReplicas <= zkCluster.Spec.Replicas/2 {
+			newStatus.ZookeeperError = fmt.Sprintf("Waiting for the provided Zookeeper ensemble %s to have a quorum, %d of %d members are ready", zkCluster.Name, foundZkCluster.Status.ReadyReplicas, zkCluster.Spec.Replicas)
+		}
 		return err
 	} else {
-		return errors.NewBadRequest("No Zookeeper reference information provided.")
+		return util.TerminalErrorf(util.InvalidSpecReason, "No Zookeeper reference information provided.")
 	}
 	return nil
 }
@@ -821,6 +2058,27 @@ func (r *SolrCloudReconciler) reconcileStorageFinalizer(ctx context.Context, clo
 	return nil
 }
 
+// reconcileDataPVCMetadata adds the labels and annotations of the data volumeClaimTemplate, including those propagated from the SolrCloud, to the existing data PVCs.
+// The volumeClaimTemplates of a StatefulSet cannot be changed, so otherwise only the PVCs of new StatefulSets would get them.
+func (r *SolrCloudReconciler) reconcileDataPVCMetadata(ctx context.Context, cloud *solrv1beta1.SolrCloud, pvcLabelSelector map[string]string, logger logr.Logger) error {
+	pvcTemplate := util.GenerateDataPVCTemplate(cloud)
+	dataPVCSelector := util.MergeLabelsOrAnnotations(pvcLabelSelector, map[string]string{util.SolrPVCStorageLabel: util.SolrCloudPVCDataStorage})
+	pvcList, err := r.getPVCList(ctx, cloud, dataPVCSelector)
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		patchFrom := client.MergeFrom(pvc.DeepCopy())
+		if util.CopyLabelsAndAnnotations(&pvcTemplate.ObjectMeta, &pvc.ObjectMeta, logger.WithValues("pvc", pvc.Name)) {
+			if err = r.Patch(ctx, pvc, patchFrom); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (r *SolrCloudReconciler) getPVCCount(ctx context.Context, cloud *solrv1beta1.SolrCloud, pvcLabelSelector map[string]string) (pvcCount int, err error) {
 	pvcList, err := r.getPVCList(ctx, cloud, pvcLabelSelector)
 	if err != nil {
@@ -891,12 +2149,35 @@ func (r *SolrCloudReconciler) deletePVC(ctx context.Context, pvcItem corev1.Pers
 func (r *SolrCloudReconciler) reconcileTLSConfig(instance *solrv1beta1.SolrCloud) (*util.TLSCerts, error) {
 	tls := util.TLSCertsForSolrCloud(instance)
 
+	if tls.ClientConfig != nil && tls.ClientConfig.Options.PerPodCertificates != nil {
+		return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, 'perPodCertificates' is only supported for 'solrTLS', not 'solrClientTLS'")
+	}
+
 	// Has the user configured a secret containing the TLS cert files that we need to mount into the Solr pods?
 	serverCert := tls.ServerConfig.Options
-	if serverCert.PKCS12Secret != nil {
+	if serverCert.PerPodCertificates != nil {
+		// each pod is issued its own certificate through cert-manager, which also serves as the client cert of the pod
+		if err := util.ValidatePerPodCertificateOptions(instance); err != nil {
+			return nil, err
+		}
+
+		// the truststore is generated from the CA of the issuer, unless the user supplies their own
+		if serverCert.TrustBundleConfigMap != nil {
+			if err := tls.ServerConfig.VerifyTrustBundleConfig(&r.Client); err != nil {
+				return nil, err
+			}
+		} else if serverCert.TrustStoreSecret != nil {
+			if serverCert.TrustStorePasswordSecret == nil {
+				serverCert.TrustStorePasswordSecret = serverCert.KeyStorePasswordSecret
+			}
+			if err := tls.ServerConfig.VerifyTruststoreOnly(&r.Client); err != nil {
+				return nil, err
+			}
+		}
+	} else if serverCert.PKCS12Secret != nil {
 		// Ensure one or the other have been configured, but not both
 		if serverCert.MountedTLSDir != nil {
-			return nil, fmt.Errorf("invalid TLS config, either supply `solrTLS.pkcs12Secret` or `solrTLS.mountedTLSDir` but not both")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, either supply `solrTLS.pkcs12Secret` or `solrTLS.mountedTLSDir` but not both")
 		}
 
 		_, err := tls.ServerConfig.VerifyKeystoreAndTruststoreSecretConfig(&r.Client)
@@ -908,12 +2189,12 @@ func (r *SolrCloudReconciler) reconcileTLSConfig(instance *solrv1beta1.SolrCloud
 		if tls.ClientConfig != nil {
 			if tls.ClientConfig.Options.PKCS12Secret == nil {
 				// cannot mix options with the client cert, if the server cert comes from a secret, so too must the client, not a mountedTLSDir
-				return nil, fmt.Errorf("invalid TLS config, the 'solrClientTLS.pkcs12Secret' option is required when using a secret for server cert")
+				return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrClientTLS.pkcs12Secret' option is required when using a secret for server cert")
 			}
 
 			// shouldn't configure a client cert if it's the same as the server cert
 			if tls.ClientConfig.Options.PKCS12Secret == tls.ServerConfig.Options.PKCS12Secret {
-				return nil, fmt.Errorf("invalid TLS config, the 'solrClientTLS.pkcs12Secret' option should not be the same as the 'solrTLS.pkcs12Secret'")
+				return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrClientTLS.pkcs12Secret' option should not be the same as the 'solrTLS.pkcs12Secret'")
 			}
 
 			_, err := tls.ClientConfig.VerifyKeystoreAndTruststoreSecretConfig(&r.Client)
@@ -925,10 +2206,15 @@ func (r *SolrCloudReconciler) reconcileTLSConfig(instance *solrv1beta1.SolrCloud
 		// per-pod TLS files get mounted into a dir on the pod dynamically using some external agent / CSI driver type mechanism
 		// make sure the client cert, if configured, is also using the mounted dir option as mixing the two approaches is not supported
 		if tls.ClientConfig != nil && tls.ClientConfig.Options.MountedTLSDir == nil {
-			return nil, fmt.Errorf("invalid TLS config, client cert must also use 'mountedTLSDir' when using 'solrTLS.mountedTLSDir'")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, client cert must also use 'mountedTLSDir' when using 'solrTLS.mountedTLSDir'")
+		}
+
+		// the truststore is built from the bundle in a dir the operator manages, which cannot be combined with the mounted dir
+		if serverCert.TrustBundleConfigMap != nil || (tls.ClientConfig != nil && tls.ClientConfig.Options.TrustBundleConfigMap != nil) {
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'trustBundleConfigMap' option cannot be used with 'mountedTLSDir'")
 		}
 	} else {
-		return nil, fmt.Errorf("invalid TLS config, must supply either 'pkcs12Secret' or 'mountedTLSDir' for the server cert")
+		return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, must supply either 'pkcs12Secret', 'mountedTLSDir' or 'perPodCertificates' for the server cert")
 	}
 
 	return tls, nil
@@ -936,13 +2222,16 @@ func (r *SolrCloudReconciler) reconcileTLSConfig(instance *solrv1beta1.SolrCloud
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SolrCloudReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.config = mgr.GetConfig()
+
 	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&solrv1beta1.SolrCloud{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}). /* for authentication */
-		Owns(&netv1.Ingress{})
+		Owns(&netv1.Ingress{}).
+		Owns(&solrv1beta1.SolrRestore{}) /* for standby restores */
 
 	var err error
 	ctrlBuilder, err = r.indexAndWatchForProvidedConfigMaps(mgr, ctrlBuilder)
@@ -950,6 +2239,21 @@ func (r *SolrCloudReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	ctrlBuilder, err = r.indexAndWatchForConfigMapFiles(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForConfigSetFiles(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	if err = r.indexZookeeperEnsembles(mgr); err != nil {
+		return err
+	}
+	ctrlBuilder = r.watchZookeeperServices(ctrlBuilder)
+
 	ctrlBuilder, err = r.indexAndWatchForTLSSecret(mgr, ctrlBuilder)
 	if err != nil {
 		return err
@@ -960,6 +2264,56 @@ func (r *SolrCloudReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	ctrlBuilder, err = r.indexAndWatchForTLSStoreSecrets(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForTrustBundleConfigMaps(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForBasicAuthSecret(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForSecurityJsonSecret(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForBootstrapCredentialsSecret(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForOperatorClientCABundleSecret(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForBackupRepoCredentialSecrets(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder = r.watchSolrPods(ctrlBuilder)
+
+	ctrlBuilder = r.watchSolrBackups(ctrlBuilder)
+	ctrlBuilder = r.watchSolrRestores(ctrlBuilder)
+
+	ctrlBuilder, err = r.watchSolrOperatorConfigs(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexPodsAndWatchForNodeInterruptions(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
 	if useZkCRD {
 		ctrlBuilder = ctrlBuilder.Owns(&zk_api.ZookeeperCluster{})
 	}
@@ -967,18 +2321,192 @@ func (r *SolrCloudReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrlBuilder.Complete(r)
 }
 
+// watchSolrOperatorConfigs reconciles all SolrClouds in a namespace whenever its SolrOperatorConfig changes,
+// so that SolrClouds are picked up, or given defaults, by the new configuration.
+// SolrOperatorConfigs are not watched if they are not used, or their CRD is not installed.
+func (r *SolrCloudReconciler) watchSolrOperatorConfigs(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	if util.SolrOperatorConfigName() == "" {
+		return ctrlBuilder, nil
+	}
+	gvk := solrv1beta1.GroupVersion.WithKind("SolrOperatorConfig")
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); meta.IsNoMatchError(err) {
+		mgr.GetLogger().Info("Not watching SolrOperatorConfigs, since their CRD is not installed")
+		return ctrlBuilder, nil
+	} else if err != nil {
+		return ctrlBuilder, err
+	}
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrOperatorConfig{}},
+		handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				if obj.GetName() != util.SolrOperatorConfigName() {
+					return []reconcile.Request{}
+				}
+				foundClouds := &solrv1beta1.SolrCloudList{}
+				if err := r.List(context.Background(), foundClouds, client.InNamespace(obj.GetNamespace())); err != nil {
+					return []reconcile.Request{}
+				}
+				requests := make([]reconcile.Request, len(foundClouds.Items))
+				for i, cloud := range foundClouds.Items {
+					requests[i] = reconcile.Request{NamespacedName: types.NamespacedName{Name: cloud.Name, Namespace: cloud.Namespace}}
+				}
+				return requests
+			}),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{})), nil
+}
+
+// watchSolrPods reconciles a SolrCloud whenever one of its pods is created, deleted, or changes readiness.
+// Solr pods are owned by the StatefulSet, not the SolrCloud, so without this watch the status would only be updated
+// once the StatefulSet status changes, which can lag behind the pods considerably.
+func (r *SolrCloudReconciler) watchSolrPods(ctrlBuilder *builder.Builder) *builder.Builder {
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Pod{}},
+		handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				cloudName, isSolrPod := obj.GetLabels()["solr-cloud"]
+				if !isSolrPod || obj.GetLabels()["technology"] != solrv1beta1.SolrTechnologyLabel {
+					return []reconcile.Request{}
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: cloudName, Namespace: obj.GetNamespace()}}}
+			}),
+		builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldPod, oldIsPod := e.ObjectOld.(*corev1.Pod)
+				newPod, newIsPod := e.ObjectNew.(*corev1.Pod)
+				if !oldIsPod || !newIsPod {
+					return false
+				}
+				return isPodReady(oldPod) != isPodReady(newPod) ||
+					isPodContainersReady(oldPod) != isPodContainersReady(newPod) ||
+					oldPod.DeletionTimestamp.IsZero() != newPod.DeletionTimestamp.IsZero() ||
+					oldPod.Labels["controller-revision-hash"] != newPod.Labels["controller-revision-hash"]
+			},
+		}))
+}
+
+// indexPodsAndWatchForNodeInterruptions reconciles the SolrClouds with pods on a Node whenever the Node's taints change,
+// so that shard leaders can be moved off of the Node as soon as an interruption is signaled.
+func (r *SolrCloudReconciler) indexPodsAndWatchForNodeInterruptions(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := "spec.nodeName"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, field, func(rawObj client.Object) []string {
+		pod := rawObj.(*corev1.Pod)
+		if pod.Spec.NodeName == "" {
+			return nil
+		}
+		return []string{pod.Spec.NodeName}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Node{}},
+		handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				foundPods := &corev1.PodList{}
+				listOps := &client.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector(field, obj.GetName()),
+					LabelSelector: labels.SelectorFromSet(labels.Set{"technology": solrv1beta1.SolrTechnologyLabel}),
+				}
+				if err := r.List(context.Background(), foundPods, listOps); err != nil {
+					return []reconcile.Request{}
+				}
+				clouds := map[types.NamespacedName]bool{}
+				requests := make([]reconcile.Request, 0)
+				for _, pod := range foundPods.Items {
+					cloud := types.NamespacedName{Name: pod.Labels["solr-cloud"], Namespace: pod.Namespace}
+					if cloud.Name != "" && !clouds[cloud] {
+						clouds[cloud] = true
+						requests = append(requests, reconcile.Request{NamespacedName: cloud})
+					}
+				}
+				return requests
+			}),
+		builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return false
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldNode, oldIsNode := e.ObjectOld.(*corev1.Node)
+				newNode, newIsNode := e.ObjectNew.(*corev1.Node)
+				if !oldIsNode || !newIsNode {
+					return false
+				}
+				return !reflect.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints)
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		})), nil
+}
+
+// releaseUnmanagedResource removes the owner reference to the SolrCloud from a child resource whose management the user has turned off,
+// so that the resource is not deleted along with the SolrCloud. Resources that do not exist are ignored.
+func (r *SolrCloudReconciler) releaseUnmanagedResource(ctx context.Context, instance *solrv1beta1.SolrCloud, name string, obj client.Object, logger logr.Logger) error {
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: instance.Namespace}, obj)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if util.ReleaseOwnerRef(instance, obj) {
+		logger.Info("Removing the SolrCloud owner reference from an unmanaged resource", "resource", name)
+		err = r.Update(ctx, obj)
+	}
+	return err
+}
+
+// countReadySolrPods returns the number of Solr pods of the SolrCloud that are currently ready
+func (r *SolrCloudReconciler) countReadySolrPods(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) (readyPods int32, err error) {
+	foundPods := &corev1.PodList{}
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solrv1beta1.SolrTechnologyLabel
+	if err = r.List(ctx, foundPods, client.InNamespace(solrCloud.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return readyPods, err
+	}
+	for i := range foundPods.Items {
+		if isPodReady(&foundPods.Items[i]) {
+			readyPods++
+		}
+	}
+	return readyPods, nil
+}
+
+// isPodReady determines whether the given pod is considered "ready" by Kubernetes
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isPodContainersReady returns whether all containers of the pod are ready, which can be the case before the pod's readiness gates pass
+func isPodContainersReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.ContainersReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func (r *SolrCloudReconciler) indexAndWatchForProvidedConfigMaps(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, ".spec.customSolrKubeOptions.configMapOptions.providedConfigMap", func(rawObj client.Object) []string {
 		// grab the SolrCloud object, extract the used configMap...
 		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
-		if solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions == nil {
-			return nil
+		if solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions != nil && solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap != "" {
+			// ...and if so, return it
+			return []string{solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap}
 		}
-		if solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap == "" {
-			return nil
+		// A ConfigMap that the user manages under the default name is watched just like a provided ConfigMap
+		if !solrCloud.ManagesConfigMap() {
+			return []string{solrCloud.ConfigMapName()}
 		}
-		// ...and if so, return it
-		return []string{solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap}
+		return nil
 	}); err != nil {
 		return ctrlBuilder, err
 	}
@@ -989,6 +2517,91 @@ func (r *SolrCloudReconciler) indexAndWatchForProvidedConfigMaps(mgr ctrl.Manage
 		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
 }
 
+func (r *SolrCloudReconciler) indexAndWatchForConfigMapFiles(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, ".spec.customSolrKubeOptions.configMapFiles.configMap", func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		var configMaps []string
+		for _, file := range solrCloud.Spec.CustomSolrKubeOptions.ConfigMapFiles {
+			configMaps = append(configMaps, file.ConfigMap)
+		}
+		return configMaps
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.ConfigMap{}},
+		r.findSolrCloudByFieldValueFunc(".spec.customSolrKubeOptions.configMapFiles.configMap"),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrCloudReconciler) indexAndWatchForConfigSetFiles(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, ".spec.configSetFiles.configMap", func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		var configMaps []string
+		for _, configSetFiles := range solrCloud.Spec.ConfigSetFiles {
+			configMaps = append(configMaps, configSetFiles.ConfigMap)
+		}
+		return configMaps
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.ConfigMap{}},
+		r.findSolrCloudByFieldValueFunc(".spec.configSetFiles.configMap"),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// indexZookeeperEnsembles indexes SolrClouds by the external Zookeeper ensemble that they connect to
+func (r *SolrCloudReconciler) indexZookeeperEnsembles(mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, zkEnsembleField, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		if solrCloud.Spec.ZookeeperRef == nil {
+			return nil
+		}
+		if ensembleKey, _ := solrCloud.Spec.ZookeeperRef.ExternalEnsemble(solrCloud.Namespace); ensembleKey != "" {
+			return []string{ensembleKey}
+		}
+		return nil
+	})
+}
+
+// watchZookeeperServices reconciles the SolrClouds that reference a Zookeeper Service whenever that Service or its Endpoints change,
+// so that the connection string follows the ensemble as it scales.
+// Referenced Services can live in any namespace, so the SolrClouds are looked up through the Zookeeper ensemble index.
+func (r *SolrCloudReconciler) watchZookeeperServices(ctrlBuilder *builder.Builder) *builder.Builder {
+	findClouds := handler.EnqueueRequestsFromMapFunc(
+		func(obj client.Object) []reconcile.Request {
+			foundClouds := &solrv1beta1.SolrCloudList{}
+			ensembleKey := "service:" + obj.GetNamespace() + "/" + obj.GetName()
+			if err := r.List(context.Background(), foundClouds, client.MatchingFields{zkEnsembleField: ensembleKey}); err != nil {
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, len(foundClouds.Items))
+			for i, item := range foundClouds.Items {
+				requests[i] = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				}
+			}
+			return requests
+		})
+
+	return ctrlBuilder.
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			findClouds,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &corev1.Endpoints{}},
+			findClouds,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+}
+
 func (r *SolrCloudReconciler) indexAndWatchForTLSSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
 	field := ".spec.solrTLS.pkcs12Secret"
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
@@ -1029,6 +2642,155 @@ func (r *SolrCloudReconciler) indexAndWatchForClientTLSSecret(mgr ctrl.Manager,
 		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
 }
 
+// indexAndWatchForTLSStoreSecrets watches the password and truststore secrets of the server and client TLS options,
+// so that SolrClouds with invalid TLS configs are reconciled again once these secrets are fixed
+func (r *SolrCloudReconciler) indexAndWatchForTLSStoreSecrets(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.solrTLS.storeSecrets"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		var secrets []string
+		for _, tlsOptions := range []*solrv1beta1.SolrTLSOptions{solrCloud.Spec.SolrTLS, solrCloud.Spec.SolrClientTLS} {
+			if tlsOptions == nil {
+				continue
+			}
+			for _, secret := range []*corev1.SecretKeySelector{tlsOptions.KeyStorePasswordSecret, tlsOptions.TrustStoreSecret, tlsOptions.TrustStorePasswordSecret} {
+				if secret != nil {
+					secrets = append(secrets, secret.Name)
+				}
+			}
+		}
+		return secrets
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrCloudReconciler) indexAndWatchForTrustBundleConfigMaps(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.solrTLS.trustBundleConfigMap"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		// grab the SolrCloud object, extract the CA bundle configMaps used by the server and client certs...
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		var bundles []string
+		if solrCloud.Spec.SolrTLS != nil && solrCloud.Spec.SolrTLS.TrustBundleConfigMap != nil {
+			bundles = append(bundles, solrCloud.Spec.SolrTLS.TrustBundleConfigMap.Name)
+		}
+		if solrCloud.Spec.SolrClientTLS != nil && solrCloud.Spec.SolrClientTLS.TrustBundleConfigMap != nil {
+			bundles = append(bundles, solrCloud.Spec.SolrClientTLS.TrustBundleConfigMap.Name)
+		}
+		return bundles
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.ConfigMap{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// indexAndWatchForBasicAuthSecret watches the user-provided basic auth secret, so that SolrClouds are reconciled again once it is fixed
+func (r *SolrCloudReconciler) indexAndWatchForBasicAuthSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.solrSecurity.basicAuthSecret"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		if solrCloud.Spec.SolrSecurity == nil || solrCloud.Spec.SolrSecurity.BasicAuthSecret == "" {
+			return nil
+		}
+		return []string{solrCloud.Spec.SolrSecurity.BasicAuthSecret}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// indexAndWatchForBootstrapCredentialsSecret watches the secret with the passwords of the bootstrapped users,
+// so that SolrClouds waiting on it are reconciled once it is created, such as by an external secret store
+func (r *SolrCloudReconciler) indexAndWatchForBootstrapCredentialsSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.solrSecurity.bootstrapCredentialsSecret"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		if solrCloud.Spec.SolrSecurity == nil || solrCloud.Spec.SolrSecurity.BootstrapCredentialsSecret == "" {
+			return nil
+		}
+		return []string{solrCloud.Spec.SolrSecurity.BootstrapCredentialsSecret}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// indexAndWatchForSecurityJsonSecret watches the secret with the managed security.json, so that changes to it are synced to Zookeeper
+func (r *SolrCloudReconciler) indexAndWatchForSecurityJsonSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.solrSecurity.securityJsonSecret"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		if solrCloud.Spec.SolrSecurity == nil || solrCloud.Spec.SolrSecurity.SecurityJsonSecret == nil {
+			return nil
+		}
+		return []string{solrCloud.Spec.SolrSecurity.SecurityJsonSecret.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrCloudReconciler) indexAndWatchForOperatorClientCABundleSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.operatorClient.caBundleSecret"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		if solrCloud.Spec.OperatorClient == nil || solrCloud.Spec.OperatorClient.CABundleSecret == nil {
+			return nil
+		}
+		return []string{solrCloud.Spec.OperatorClient.CABundleSecret.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// indexAndWatchForBackupRepoCredentialSecrets watches the credential secrets of the backup repositories, so that the Solr pods are restarted when they are rotated
+func (r *SolrCloudReconciler) indexAndWatchForBackupRepoCredentialSecrets(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	field := ".spec.backupRepositories.credentialSecret"
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrCloud{}, field, func(rawObj client.Object) []string {
+		solrCloud := rawObj.(*solrv1beta1.SolrCloud)
+		var secrets []string
+		for i := range solrCloud.Spec.BackupRepositories {
+			if credentialSecret := util.RepoCredentialSecret(&solrCloud.Spec.BackupRepositories[i]); credentialSecret != nil {
+				secrets = append(secrets, credentialSecret.Name)
+			}
+		}
+		return secrets
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		r.findSolrCloudByFieldValueFunc(field),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
 func (r *SolrCloudReconciler) findSolrCloudByFieldValueFunc(field string) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(
 		func(obj client.Object) []reconcile.Request {
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileDiagnostics collects diagnostics from the Solr pods for the given request, into the diagnostics ConfigMap of the SolrCloud.
+//
+// A thread dump and a snapshot of the metrics are fetched from each selected pod that is ready, and the recent logs from every selected pod,
+// since logs are often needed the most when Solr is not ready. Diagnostics that cannot be collected are listed in the status,
+// rather than failing the request, so that a support bundle can still be created from an unhealthy SolrCloud.
+func (r *SolrCloudReconciler) reconcileDiagnostics(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, requestId string, httpHeaders map[string]string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (err error) {
+	diagnosticsLogger := logger.WithValues("diagnosticsRequest", requestId)
+	diagnosticsLogger.Info("Collecting diagnostics from Solr pods")
+
+	diagnosticsStatus := &solrv1beta1.SolrDiagnosticsStatus{
+		RequestId:      requestId,
+		CollectionTime: metav1.Now(),
+	}
+	files := map[string][]byte{}
+
+	selectedNodes, unknownPods := util.DiagnosticsNodes(solrCloud, newStatus.SolrNodes)
+	for _, pod := range unknownPods {
+		diagnosticsStatus.Errors = append(diagnosticsStatus.Errors, fmt.Sprintf("%s is not a Solr pod of the SolrCloud", pod))
+	}
+	for _, node := range selectedNodes {
+		diagnosticsStatus.Pods = append(diagnosticsStatus.Pods, node.Name)
+
+		if node.Ready {
+			threadDump, metrics, collectErr := util.CollectSolrNodeDiagnostics(solrCloud, node.Name, httpHeaders)
+			if threadDump != nil {
+				files[node.Name+util.DiagnosticsThreadDumpSuffix] = threadDump
+			}
+			if metrics != nil {
+				files[node.Name+util.DiagnosticsMetricsSuffix] = metrics
+			}
+			if collectErr != nil {
+				diagnosticsStatus.Errors = append(diagnosticsStatus.Errors, collectErr.Error())
+			}
+		} else {
+			diagnosticsStatus.Errors = append(diagnosticsStatus.Errors, fmt.Sprintf("%s is not ready, only its logs were collected", node.Name))
+		}
+
+		if logs, logsErr := util.GetSolrPodLogs(node.Name, solrCloud.Namespace, util.DiagnosticsLogTailLines, *r.config); logsErr != nil {
+			diagnosticsStatus.Errors = append(diagnosticsStatus.Errors, fmt.Sprintf("could not fetch the logs of %s: %s", node.Name, logsErr))
+		} else {
+			files[node.Name+util.DiagnosticsLogsSuffix] = logs
+		}
+	}
+
+	configMap := util.GenerateDiagnosticsConfigMap(solrCloud, requestId, files)
+	if err = controllerutil.SetControllerReference(solrCloud, configMap, r.Scheme); err != nil {
+		return err
+	}
+
+	// The diagnostics of a previous request are replaced as a whole
+	configMapLogger := diagnosticsLogger.WithValues("configMap", configMap.Name)
+	foundConfigMap := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, foundConfigMap)
+	if err != nil && errors.IsNotFound(err) {
+		configMapLogger.Info("Creating Diagnostics ConfigMap")
+		err = r.Create(ctx, configMap)
+	} else if err == nil {
+		configMapLogger.Info("Replacing Diagnostics ConfigMap")
+		foundConfigMap.Labels = configMap.Labels
+		foundConfigMap.Annotations = configMap.Annotations
+		foundConfigMap.OwnerReferences = configMap.OwnerReferences
+		foundConfigMap.Data = configMap.Data
+		foundConfigMap.BinaryData = nil
+		err = r.Update(ctx, foundConfigMap)
+	}
+	if err != nil {
+		return err
+	}
+
+	newStatus.Diagnostics = diagnosticsStatus
+	newStatus.Resources.DiagnosticsConfigMap = configMap.Name
+	if len(diagnosticsStatus.Errors) > 0 {
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "DiagnosticsIncomplete",
+			"Collected diagnostics for request %s into ConfigMap %s, with errors: %s", requestId, configMap.Name, strings.Join(diagnosticsStatus.Errors, "; "))
+	} else {
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "DiagnosticsCollected",
+			"Collected diagnostics for request %s into ConfigMap %s", requestId, configMap.Name)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SolrCloudProtectionWebhookPath is the path that the SolrCloud protection webhook is served on
+const SolrCloudProtectionWebhookPath = "/validate-solr-apache-org-v1beta1-solrcloud-protection"
+
+//+kubebuilder:webhook:path=/validate-solr-apache-org-v1beta1-solrcloud-protection,mutating=false,failurePolicy=ignore,sideEffects=None,groups=solr.apache.org,resources=solrclouds,verbs=update;delete,versions=v1beta1,name=protection.solrclouds.solr.apache.org,admissionReviewVersions=v1
+
+// SolrCloudProtectionWebhook rejects the deletion of a SolrCloud, and the removal of the backup repositories that it defines,
+// while SolrBackups or SolrRestores that depend on them are in progress.
+// The SolrCloud controller protects SolrClouds through a finalizer, and by not applying removed repositories to the Solr pods, regardless of this webhook.
+// The webhook only tells users right away why their change is not applied.
+type SolrCloudProtectionWebhook struct {
+	Reader  client.Reader
+	decoder *admission.Decoder
+}
+
+// Handle admits or rejects an update or deletion of a SolrCloud
+func (w *SolrCloudProtectionWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	oldCloud := &solrv1beta1.SolrCloud{}
+	if err := w.decoder.DecodeRaw(req.OldObject, oldCloud); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	inProgress, oldRemovedRepositories, err := inProgressOperationsForCloud(ctx, w.Reader, oldCloud)
+	if err != nil {
+		// Like an unreachable webhook, the change is admitted, and the SolrCloud is still protected by the SolrCloud controller
+		return admission.Allowed(fmt.Sprintf("the SolrBackups and SolrRestores of SolrCloud %s could not be listed: %v", req.Name, err))
+	}
+	if len(inProgress) == 0 {
+		return admission.Allowed("")
+	}
+
+	if req.Operation == admissionv1.Delete {
+		return admission.Denied(fmt.Sprintf("SolrCloud %s cannot be deleted until these finish: %s", oldCloud.Name, strings.Join(inProgress, ", ")))
+	}
+
+	newCloud := &solrv1beta1.SolrCloud{}
+	if err = w.decoder.DecodeRaw(req.Object, newCloud); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	_, newRemovedRepositories, err := inProgressOperationsForCloud(ctx, w.Reader, newCloud)
+	if err != nil {
+		// Like an unreachable webhook, the change is admitted, and the SolrCloud is still protected by the SolrCloud controller
+		return admission.Allowed(fmt.Sprintf("the SolrBackups and SolrRestores of SolrCloud %s could not be listed: %v", req.Name, err))
+	}
+	// Only reject updates that remove more repositories, so that SolrClouds whose repositories were already removed can still be updated
+	var removedRepositories []string
+	for _, repositoryName := range newRemovedRepositories {
+		if !util.ContainsString(oldRemovedRepositories, repositoryName) {
+			removedRepositories = append(removedRepositories, repositoryName)
+		}
+	}
+	if len(removedRepositories) > 0 {
+		return admission.Denied(fmt.Sprintf("backup repositories [%s] cannot be removed from SolrCloud %s until these finish: %s",
+			strings.Join(removedRepositories, ", "), newCloud.Name, strings.Join(inProgress, ", ")))
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder of admission requests into the webhook
+func (w *SolrCloudProtectionWebhook) InjectDecoder(d *admission.Decoder) error {
+	w.decoder = d
+	return nil
+}
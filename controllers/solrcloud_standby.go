@@ -0,0 +1,160 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileStandby restores the latest backup of the primary's collections into a standby SolrCloud, on the standby schedule.
+// Collections cannot be restored into existing collections, so each restore creates a new generation of the collections, through an owned SolrRestore.
+// Once a generation has been restored, the alias named after each collection is pointed at it, and the previous generation is deleted.
+// A positive requeueAfter is returned when the next restore is scheduled, the SolrRestore in progress is watched instead.
+func (r *SolrCloudReconciler) reconcileStandby(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, clusterState *util.SolrClusterState, httpHeaders map[string]string, newStatus *solrv1beta1.SolrCloudStatus, logger logr.Logger) (requeueAfter time.Duration, err error) {
+	standby := solrCloud.Spec.StandbyOf
+	standbyStatus := &solrv1beta1.SolrStandbyStatus{}
+	if solrCloud.Status.Standby != nil {
+		standbyStatus = solrCloud.Status.Standby.DeepCopy()
+	}
+	newStatus.Standby = standbyStatus
+
+	if standbyStatus.SolrRestore != "" {
+		return 0, r.reconcileStandbyRestore(ctx, solrCloud, standbyStatus, clusterState, httpHeaders, logger)
+	}
+
+	now := time.Now()
+	if standbyStatus.NextRestoreTime != nil && now.Before(standbyStatus.NextRestoreTime.Time) {
+		return standbyStatus.NextRestoreTime.Sub(now), nil
+	}
+
+	// The aliases may have been cut over to a generation that was never recorded, if the status update failed after the last restore
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return 0, err
+	}
+	if inUse := util.StandbyGenerationInUse(standby, clusterStatus); inUse > standbyStatus.Generation {
+		logger.Info("Recording the generation of the standby collections that the aliases point to", "generation", inUse)
+		standbyStatus.Generation = inUse
+	}
+
+	generation := standbyStatus.Generation + 1
+	restore := util.GenerateStandbyRestore(solrCloud, generation)
+	if err = controllerutil.SetControllerReference(solrCloud, restore, r.Scheme); err != nil {
+		return 0, err
+	}
+	logger.Info("Starting standby restore", "solrRestore", restore.Name, "primary", standby.SolrCloud, "generation", generation)
+	if err = r.Create(ctx, restore); err != nil && !errors.IsAlreadyExists(err) {
+		return 0, err
+	}
+	r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "StandbyRestoreStarted",
+		"Restoring the latest backup %s of SolrCloud %s into generation %d of the standby collections", standby.BackupName, standby.SolrCloud, generation)
+	standbyStatus.SolrRestore = restore.Name
+	standbyStatus.NextRestoreTime = nil
+	return 0, nil
+}
+
+// reconcileStandbyRestore checks on the SolrRestore of the next generation of standby collections, and cuts the aliases over to it once it has finished
+func (r *SolrCloudReconciler) reconcileStandbyRestore(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, standbyStatus *solrv1beta1.SolrStandbyStatus, clusterState *util.SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	standby := solrCloud.Spec.StandbyOf
+	generation := standbyStatus.Generation + 1
+
+	restore := &solrv1beta1.SolrRestore{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: solrCloud.Namespace, Name: standbyStatus.SolrRestore}, restore); err != nil {
+		if errors.IsNotFound(err) {
+			// The SolrRestore was deleted before it finished, so start a new one
+			standbyStatus.SolrRestore = ""
+			return nil
+		}
+		return err
+	}
+	if !restore.Status.Finished {
+		return nil
+	}
+
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return err
+	}
+
+	// The collections that the aliases point to are never deleted, see StandbyCollectionsToDelete,
+	// so a generation whose cutover was not recorded in the status is kept if its restore is retried
+	replacedGeneration := standbyStatus.Generation
+	if restore.Status.Successful != nil && *restore.Status.Successful {
+		for _, collection := range standby.Collections {
+			if err = util.CreateAliasForCollection(solrCloud, collection, util.StandbyCollectionName(collection, generation), httpHeaders, logger); err != nil {
+				return err
+			}
+		}
+		now := metav1.Now()
+		standbyStatus.Generation = generation
+		standbyStatus.LastRestoreTime = &now
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeNormal, "StandbyRestored",
+			"Restored generation %d of the standby collections from the latest backup of SolrCloud %s", generation, standby.SolrCloud)
+	} else {
+		// The collections of a failed restore are never used, so they are removed instead of the current generation
+		replacedGeneration = generation
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "StandbyRestoreFailed",
+			"Could not restore generation %d of the standby collections, see SolrRestore %s. It is retried on the next scheduled restore", generation, restore.Name)
+	}
+
+	if replacedGeneration > 0 {
+		for _, collection := range util.StandbyCollectionsToDelete(standby, replacedGeneration, clusterStatus) {
+			if err = util.DeleteCollection(solrCloud, collection, httpHeaders, logger); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = r.Delete(ctx, restore); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	next, err := util.NextStandbyRestoreTime(standby, time.Now())
+	if err != nil {
+		return err
+	}
+	standbyStatus.SolrRestore = ""
+	standbyStatus.NextRestoreTime = &metav1.Time{Time: next}
+	return nil
+}
+
+// stopStandby stops the restores of a SolrCloud that has been promoted from a standby, by deleting the SolrRestore in progress, if any.
+// The aliases keep pointing to the last restored generation of the collections.
+func (r *SolrCloudReconciler) stopStandby(ctx context.Context, solrCloud *solrv1beta1.SolrCloud, logger logr.Logger) (err error) {
+	if solrCloud.Status.Standby == nil || solrCloud.Status.Standby.SolrRestore == "" {
+		return nil
+	}
+	logger.Info("Stopping standby restore, since the SolrCloud has been promoted", "solrRestore", solrCloud.Status.Standby.SolrRestore)
+	restore := &solrv1beta1.SolrRestore{}
+	restore.Name = solrCloud.Status.Standby.SolrRestore
+	restore.Namespace = solrCloud.Namespace
+	if err = r.Delete(ctx, restore, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrConfigSetReconciler reconciles a SolrConfigSet object
+type SolrConfigSetReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrconfigsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrconfigsets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrconfigsets/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
+func (r *SolrConfigSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the SolrConfigSet instance
+	configSet := &solrv1beta1.SolrConfigSet{}
+	err := r.Get(ctx, req.NamespacedName, configSet)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, configSet); err != nil || !selected {
+		// SolrConfigSets that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return reconcile.Result{}, reportTerminalError(r.Recorder, configSet, err, logger)
+	}
+
+	if !configSet.ObjectMeta.DeletionTimestamp.IsZero() {
+		// Configsets are left in Zookeeper, since collections may still be using them
+		return reconcile.Result{}, nil
+	}
+
+	if changed := configSet.WithDefaults(); changed {
+		logger.Info("Setting default settings for solr-configset")
+		if err = r.Update(ctx, configSet); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	oldStatus := configSet.Status.DeepCopy()
+	requeueOrNot := reconcile.Result{}
+
+	err = util.ValidateSolrConfigSetSource(configSet)
+	if err == nil {
+		requeueOrNot.RequeueAfter, err = r.reconcileConfigSet(ctx, configSet, logger)
+	}
+	if err != nil {
+		configSet.Status.Synced = false
+		configSet.Status.Message = err.Error()
+		if _, isTerminal := util.AsTerminalError(err); isTerminal {
+			// The configset cannot be synced until the SolrConfigSet or its source is changed, which triggers a reconcile
+			logger.Error(err, "Cannot sync the configset", "configSet", configSet.Spec.ConfigSetName)
+			err = nil
+		} else {
+			requeueOrNot.RequeueAfter = util.RequeueAfter(util.RequeueRetry)
+		}
+	}
+
+	if !reflect.DeepEqual(oldStatus, &configSet.Status) {
+		logger.Info("Updating status for solr-configset")
+		if statusErr := r.Status().Update(ctx, configSet); err == nil {
+			err = statusErr
+		}
+	}
+
+	return requeueOrNot, err
+}
+
+// reconcileConfigSet uploads the files of the SolrConfigSet's source to Zookeeper whenever they change,
+// and then reloads the collections that use the configset.
+// While the SolrCloud is not available yet, the reason is recorded in the status and the time to wait before trying again is returned.
+func (r *SolrConfigSetReconciler) reconcileConfigSet(ctx context.Context, configSet *solrv1beta1.SolrConfigSet, logger logr.Logger) (requeueAfter time.Duration, err error) {
+	data, err := r.sourceData(ctx, configSet)
+	if err != nil {
+		return 0, err
+	}
+	files, contentHash, err := util.SolrConfigSetContent(configSet, data)
+	if err != nil {
+		return 0, err
+	}
+	if configSet.Status.Synced && configSet.Status.ContentHash == contentHash && configSet.Status.ObservedGeneration == configSet.Generation {
+		return 0, nil
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: configSet.Namespace, Name: configSet.Spec.SolrCloud}, solrCloud); err != nil {
+		if errors.IsNotFound(err) {
+			// The SolrCloud may not have been created yet
+			return waitForSolrCloud(configSet, fmt.Sprintf("waiting for the SolrCloud %s to be created", configSet.Spec.SolrCloud)), nil
+		}
+		return 0, err
+	}
+	if err = util.ValidateSolrConfigSetTarget(configSet, solrCloud); err != nil {
+		return 0, err
+	}
+	if solrCloud.Status.ReadyReplicas == 0 {
+		return waitForSolrCloud(configSet, fmt.Sprintf("waiting for the SolrCloud %s to have a ready Solr pod to upload the configset with", solrCloud.Name)), nil
+	}
+	httpHeaders, err := r.solrHttpHeaders(ctx, solrCloud)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("Uploading configset", "configSet", configSet.Spec.ConfigSetName, "solrCloud", solrCloud.Name, "files", len(files))
+	if err = util.UploadConfigSet(solrCloud, configSet.Spec.ConfigSetName, files, httpHeaders); err != nil {
+		return 0, err
+	}
+	clusterState := util.NewSolrClusterState(solrCloud, httpHeaders)
+	reloadedCollections, err := util.ReloadCollectionsUsingConfigSet(solrCloud, configSet.Spec.ConfigSetName, clusterState, httpHeaders, logger)
+	if err != nil {
+		// The content hash is not updated, so the configset is uploaded and the collections are reloaded again on the next try
+		return 0, err
+	}
+
+	now := metav1.Now()
+	configSet.Status.Synced = true
+	configSet.Status.ContentHash = contentHash
+	configSet.Status.LastSyncTime = &now
+	configSet.Status.ReloadedCollections = reloadedCollections
+	configSet.Status.ObservedGeneration = configSet.Generation
+	configSet.Status.Message = ""
+	return 0, nil
+}
+
+// waitForSolrCloud records why the configset cannot be uploaded yet, and returns how long to wait before checking the SolrCloud again.
+// The SolrCloud is watched as well, so the configset is usually uploaded as soon as it is available.
+func waitForSolrCloud(configSet *solrv1beta1.SolrConfigSet, message string) (requeueAfter time.Duration) {
+	configSet.Status.Synced = false
+	configSet.Status.Message = message
+	return util.RequeueAfter(util.RequeueRetry)
+}
+
+// sourceData returns the contents of the ConfigMap or Secret that the SolrConfigSet reads its files from
+func (r *SolrConfigSetReconciler) sourceData(ctx context.Context, configSet *solrv1beta1.SolrConfigSet) (data map[string][]byte, err error) {
+	source := configSet.Spec.Source
+	if source.Secret != "" {
+		secret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Namespace: configSet.Namespace, Name: source.Secret}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				err = util.TerminalErrorf(util.InvalidSpecReason, "could not find the Secret %s to read the configset from", source.Secret)
+			}
+			return nil, err
+		}
+		return secret.Data, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: configSet.Namespace, Name: source.ConfigMap}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			err = util.TerminalErrorf(util.InvalidSpecReason, "could not find the ConfigMap %s to read the configset from", source.ConfigMap)
+		}
+		return nil, err
+	}
+	data = make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData))
+	for key, content := range configMap.Data {
+		data[key] = []byte(content)
+	}
+	for key, content := range configMap.BinaryData {
+		data[key] = content
+	}
+	return data, nil
+}
+
+func (r *SolrConfigSetReconciler) solrHttpHeaders(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) (map[string]string, error) {
+	if err := util.ValidateOperatorCanCallSolr(solrCloud); err != nil {
+		return nil, err
+	}
+	if !solrCloud.UsesBasicAuth() {
+		return nil, nil
+	}
+	basicAuthSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+		return nil, err
+	}
+	return map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SolrConfigSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrConfigSet{})
+
+	var err error
+	ctrlBuilder, err = r.indexAndWatchForField(mgr, ctrlBuilder, ".spec.solrCloud", &solrv1beta1.SolrCloud{}, func(configSet *solrv1beta1.SolrConfigSet) string {
+		return configSet.Spec.SolrCloud
+	})
+	if err != nil {
+		return err
+	}
+	ctrlBuilder, err = r.indexAndWatchForField(mgr, ctrlBuilder, ".spec.source.configMap", &corev1.ConfigMap{}, func(configSet *solrv1beta1.SolrConfigSet) string {
+		return configSet.Spec.Source.ConfigMap
+	})
+	if err != nil {
+		return err
+	}
+	ctrlBuilder, err = r.indexAndWatchForField(mgr, ctrlBuilder, ".spec.source.secret", &corev1.Secret{}, func(configSet *solrv1beta1.SolrConfigSet) string {
+		return configSet.Spec.Source.Secret
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrlBuilder.Complete(r)
+}
+
+// Get notified when a SolrCloud, ConfigMap or Secret that SolrConfigSets reference changes.
+// The SolrCloud is watched so that configsets are uploaded as soon as it becomes ready.
+func (r *SolrConfigSetReconciler) indexAndWatchForField(mgr ctrl.Manager, ctrlBuilder *builder.Builder, field string, watchedType client.Object, fieldValue func(*solrv1beta1.SolrConfigSet) string) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrConfigSet{}, field, func(rawObj client.Object) []string {
+		value := fieldValue(rawObj.(*solrv1beta1.SolrConfigSet))
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: watchedType},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			foundConfigSets := &solrv1beta1.SolrConfigSetList{}
+			listOps := &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(field, obj.GetName()),
+				Namespace:     obj.GetNamespace(),
+			}
+			if err := r.List(context.Background(), foundConfigSets, listOps); err != nil {
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, len(foundConfigSets.Items))
+			for i, item := range foundConfigSets.Items {
+				requests[i] = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				}
+			}
+			return requests
+		}),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
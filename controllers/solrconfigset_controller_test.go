@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = FDescribe("SolrConfigSet controller - General", func() {
+
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 5
+		duration = time.Second * 1
+		interval = time.Millisecond * 250
+	)
+	SetDefaultConsistentlyDuration(duration)
+	SetDefaultConsistentlyPollingInterval(interval)
+	SetDefaultEventuallyTimeout(timeout)
+	SetDefaultEventuallyPollingInterval(interval)
+
+	var (
+		ctx context.Context
+
+		solrConfigSet *solrv1beta1.SolrConfigSet
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		solrConfigSet = &solrv1beta1.SolrConfigSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "products",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrConfigSetSpec{
+				SolrCloud: "foo",
+				Source: solrv1beta1.SolrConfigSetSource{
+					ConfigMap: "products-configset",
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		By("creating the SolrConfigSet")
+		Expect(k8sClient.Create(ctx, solrConfigSet)).To(Succeed())
+
+		By("defaulting the missing SolrConfigSet values")
+		expectSolrConfigSetWithChecks(ctx, solrConfigSet, func(g Gomega, found *solrv1beta1.SolrConfigSet) {
+			g.Expect(found.WithDefaults()).To(BeFalse(), "The SolrConfigSet spec should not need to be defaulted eventually")
+			g.Expect(found.Spec.ConfigSetName).To(Equal(solrConfigSet.Name), "The configset name should default to the name of the SolrConfigSet")
+		})
+	})
+
+	AfterEach(func() {
+		cleanupTest(ctx, solrConfigSet)
+	})
+
+	FContext("ConfigMap source", func() {
+		FIt("waits for its source and SolrCloud", func() {
+			By("testing the status without the source ConfigMap")
+			expectSolrConfigSetWithChecks(ctx, solrConfigSet, func(g Gomega, found *solrv1beta1.SolrConfigSet) {
+				g.Expect(found.Status.Synced).To(BeFalse(), "The configset cannot be synced without its source")
+				g.Expect(found.Status.Message).To(ContainSubstring("could not find the ConfigMap products-configset"), "Wrong status message for a missing source")
+			})
+
+			By("creating the source ConfigMap")
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "products-configset",
+					Namespace: solrConfigSet.Namespace,
+				},
+				Data: map[string]string{
+					"solrconfig.xml": "<config/>",
+					"managed-schema": "<schema/>",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			expectSolrConfigSetWithChecks(ctx, solrConfigSet, func(g Gomega, found *solrv1beta1.SolrConfigSet) {
+				g.Expect(found.Status.Synced).To(BeFalse(), "The configset cannot be synced without its SolrCloud")
+				g.Expect(found.Status.Message).To(Equal("waiting for the SolrCloud foo to be created"), "Wrong status message for a missing SolrCloud")
+			})
+
+			By("creating the SolrCloud")
+			solrCloud := &solrv1beta1.SolrCloud{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: solrConfigSet.Namespace,
+				},
+				Spec: solrv1beta1.SolrCloudSpec{
+					ZookeeperRef: &solrv1beta1.ZookeeperRef{
+						ConnectionInfo: &solrv1beta1.ZookeeperConnectionInfo{
+							InternalConnectionString: "host:7271",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+			expectSolrConfigSetWithChecks(ctx, solrConfigSet, func(g Gomega, found *solrv1beta1.SolrConfigSet) {
+				g.Expect(found.Status.Synced).To(BeFalse(), "The configset cannot be synced without a ready Solr pod")
+				g.Expect(found.Status.Message).To(Equal("waiting for the SolrCloud foo to have a ready Solr pod to upload the configset with"), "Wrong status message for a SolrCloud without ready pods")
+				g.Expect(found.Status.LastSyncTime).To(BeNil(), "The configset should not have been synced")
+			})
+		})
+	})
+
+	FContext("ConfigMap and Secret source", func() {
+		BeforeEach(func() {
+			solrConfigSet.Spec.Source.Secret = "products-configset"
+		})
+		FIt("reports the invalid source", func() {
+			expectSolrConfigSetWithChecks(ctx, solrConfigSet, func(g Gomega, found *solrv1beta1.SolrConfigSet) {
+				g.Expect(found.Status.Synced).To(BeFalse(), "The configset cannot be synced with an invalid source")
+				g.Expect(found.Status.Message).To(ContainSubstring("exactly one of configMap and secret must be provided"), "Wrong status message for an invalid source")
+			})
+		})
+	})
+})
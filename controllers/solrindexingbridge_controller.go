@@ -0,0 +1,309 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/apache/solr-operator/controllers/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrIndexingBridgeReconciler reconciles a SolrIndexingBridge object
+type SolrIndexingBridgeReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get
+//+kubebuilder:rbac:groups=,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrindexingbridges,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrindexingbridges/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrindexingbridges/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
+func (r *SolrIndexingBridgeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the SolrIndexingBridge instance
+	bridge := &solrv1beta1.SolrIndexingBridge{}
+	err := r.Get(ctx, req.NamespacedName, bridge)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return ctrl.Result{}, err
+	}
+
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, bridge); err != nil || !selected {
+		// SolrIndexingBridges that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return ctrl.Result{}, reportTerminalError(r.Recorder, bridge, err, logger)
+	}
+
+	changed := bridge.WithDefaults()
+	if changed {
+		logger.Info("Setting default settings for Solr IndexingBridge")
+		if err := r.Update(ctx, bridge); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	requeueOrNot := ctrl.Result{}
+
+	if bridge.Spec.Image == nil || bridge.Spec.Image.Repository == "" {
+		err = util.TerminalErrorf(util.InvalidSpecReason, "invalid SolrIndexingBridge, 'image.repository' is required")
+		return requeueOrNot, reportTerminalError(r.Recorder, bridge, err, logger)
+	}
+
+	if bridge.Spec.SolrReference.Cloud == nil && bridge.Spec.SolrReference.Standalone == nil {
+		err = util.TerminalErrorf(util.InvalidSpecReason, "invalid SolrIndexingBridge, either 'solrReference.cloud' or 'solrReference.standalone' must be provided")
+		return requeueOrNot, reportTerminalError(r.Recorder, bridge, err, logger)
+	}
+
+	// Get the ZkConnectionString to connect to
+	solrConnectionInfo := util.SolrConnectionInfo{}
+	if solrConnectionInfo, err = getSolrConnectionInfo(ctx, r.Client, bridge.Spec.SolrReference, bridge.Namespace); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure the TLS config is in order
+	var tls *util.TLSCerts = nil
+	if bridge.Spec.SolrReference.SolrTLS != nil {
+		tls, err = r.reconcileTLSConfig(bridge)
+		if err != nil {
+			return requeueOrNot, reportTerminalError(r.Recorder, bridge, err, logger)
+		}
+	}
+
+	basicAuthMd5 := ""
+	if bridge.Spec.SolrReference.BasicAuthSecret != "" {
+		basicAuthSecret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: bridge.Spec.SolrReference.BasicAuthSecret, Namespace: bridge.Namespace}, basicAuthSecret)
+		if err != nil {
+			return requeueOrNot, err
+		}
+
+		err = util.ValidateBasicAuthSecret(basicAuthSecret)
+		if err != nil {
+			return requeueOrNot, reportTerminalError(r.Recorder, bridge, err, logger)
+		}
+		basicAuthMd5 = util.ContentHash(basicAuthSecret, "basic-auth", func() []byte {
+			return []byte(fmt.Sprintf("%s:%s", basicAuthSecret.Data[corev1.BasicAuthUsernameKey], basicAuthSecret.Data[corev1.BasicAuthPasswordKey]))
+		})
+	}
+
+	deploy := util.GenerateSolrIndexingBridgeDeployment(bridge, solrConnectionInfo, tls, basicAuthMd5)
+
+	readyReplicas := int32(0)
+	// Check if the Bridge Deployment already exists
+	deploymentLogger := logger.WithValues("deployment", deploy.Name)
+	foundDeploy := &appsv1.Deployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: deploy.Name, Namespace: deploy.Namespace}, foundDeploy)
+	if err != nil && errors.IsNotFound(err) {
+		deploymentLogger.Info("Creating Deployment")
+		if err = controllerutil.SetControllerReference(bridge, deploy, r.Scheme); err == nil {
+			err = r.Create(ctx, deploy)
+		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(bridge, foundDeploy, r.Scheme)
+		needsUpdate = util.CopyDeploymentFields(deploy, foundDeploy, deploymentLogger) || needsUpdate
+
+		// Update the found Bridge Deployment and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			deploymentLogger.Info("Updating Deployment")
+			err = r.Update(ctx, foundDeploy)
+		}
+		readyReplicas = foundDeploy.Status.ReadyReplicas
+	}
+	if err != nil {
+		return requeueOrNot, err
+	}
+
+	ready := readyReplicas > 0 && readyReplicas >= *bridge.Spec.Replicas
+	if ready != bridge.Status.Ready || readyReplicas != bridge.Status.ReadyReplicas || deploy.Name != bridge.Status.DeploymentName {
+		bridge.Status.Ready = ready
+		bridge.Status.ReadyReplicas = readyReplicas
+		bridge.Status.DeploymentName = deploy.Name
+		logger.Info("Updating status for solr-indexing-bridge")
+		err = r.Status().Update(ctx, bridge)
+	}
+
+	return requeueOrNot, err
+}
+
+// reconcileTLSConfig Reconciles the options for configuring TLS for the indexing bridge
+// Like the exporter, the bridge is a client to Solr pods, but only TLS secrets are supported
+func (r *SolrIndexingBridgeReconciler) reconcileTLSConfig(bridge *solrv1beta1.SolrIndexingBridge) (*util.TLSCerts, error) {
+	tls := util.TLSCertsForIndexingBridge(bridge)
+	opts := tls.ClientConfig.Options
+
+	if opts.MountedTLSDir != nil {
+		return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrTLS.mountedTLSDir' option is not supported for the indexing bridge, supply a keystore and/or truststore secret")
+	}
+
+	// the bridge image is not guaranteed to have keytool, which is needed to build a truststore from the bundle
+	if opts.TrustBundleConfigMap != nil {
+		return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrTLS.trustBundleConfigMap' option is not supported for the indexing bridge, supply a truststore secret")
+	}
+
+	if opts.PKCS12Secret != nil {
+		// make sure the PKCS12Secret and corresponding keystore password exist and agree with the supplied config
+		_, err := tls.ClientConfig.VerifyKeystoreAndTruststoreSecretConfig(&r.Client)
+		if err != nil {
+			return nil, err
+		}
+	} else if opts.TrustStoreSecret != nil {
+		// make sure the TrustStoreSecret and corresponding password exist and agree with the supplied config
+		err := tls.ClientConfig.VerifyTruststoreOnly(&r.Client)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, either 'solrTLS.pkcs12Secret' or 'solrTLS.trustStoreSecret' is required for the indexing bridge")
+	}
+
+	return tls, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SolrIndexingBridgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrIndexingBridge{}).
+		Owns(&appsv1.Deployment{})
+
+	var err error
+	ctrlBuilder, err = r.indexAndWatchForSolrClouds(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	// Get notified when the TLS or basic auth secrets update, bridge pods must be restarted to pick up the changes.
+	ctrlBuilder, err = r.indexAndWatchForSecrets(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	return ctrlBuilder.Complete(r)
+}
+
+func (r *SolrIndexingBridgeReconciler) indexAndWatchForSolrClouds(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	solrCloudField := ".spec.solrReference.cloud.name"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrIndexingBridge{}, solrCloudField, func(rawObj client.Object) []string {
+		// grab the SolrIndexingBridge object, extract the referenced SolrCloud...
+		bridge := rawObj.(*solrv1beta1.SolrIndexingBridge)
+		if bridge.Spec.SolrReference.Cloud == nil || bridge.Spec.SolrReference.Cloud.Name == "" {
+			return nil
+		}
+		// ...and if so, return it
+		return []string{bridge.Spec.SolrReference.Cloud.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrCloud{}},
+		handler.EnqueueRequestsFromMapFunc(r.requestsForField(solrCloudField)),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrIndexingBridgeReconciler) indexAndWatchForSecrets(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	secretField := ".spec.referencedSecrets"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrIndexingBridge{}, secretField, func(rawObj client.Object) []string {
+		// grab the SolrIndexingBridge object, extract the referenced TLS and basic auth secrets
+		bridge := rawObj.(*solrv1beta1.SolrIndexingBridge)
+		var secrets []string
+		if bridge.Spec.SolrReference.BasicAuthSecret != "" {
+			secrets = append(secrets, bridge.Spec.SolrReference.BasicAuthSecret)
+		}
+		if tlsOpts := bridge.Spec.SolrReference.SolrTLS; tlsOpts != nil {
+			if tlsOpts.PKCS12Secret != nil {
+				secrets = append(secrets, tlsOpts.PKCS12Secret.Name)
+			}
+			if tlsOpts.TrustStoreSecret != nil {
+				secrets = append(secrets, tlsOpts.TrustStoreSecret.Name)
+			}
+		}
+		return secrets
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(r.requestsForField(secretField)),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrIndexingBridgeReconciler) requestsForField(field string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		foundBridges := &solrv1beta1.SolrIndexingBridgeList{}
+		listOps := &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector(field, obj.GetName()),
+			Namespace:     obj.GetNamespace(),
+		}
+		err := r.List(context.Background(), foundBridges, listOps)
+		if err != nil {
+			// if no bridges found, just no-op this
+			return []reconcile.Request{}
+		}
+
+		requests := make([]reconcile.Request, len(foundBridges.Items))
+		for i, item := range foundBridges.Items {
+			requests[i] = reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = FDescribe("SolrIndexingBridge controller - General", func() {
+
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 5
+		duration = time.Second * 1
+		interval = time.Millisecond * 250
+	)
+	SetDefaultConsistentlyDuration(duration)
+	SetDefaultConsistentlyPollingInterval(interval)
+	SetDefaultEventuallyTimeout(timeout)
+	SetDefaultEventuallyPollingInterval(interval)
+
+	var (
+		ctx context.Context
+
+		solrIndexingBridge *solrv1beta1.SolrIndexingBridge
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		solrIndexingBridge = &solrv1beta1.SolrIndexingBridge{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrIndexingBridgeSpec{
+				Collection: "products",
+				Kafka: solrv1beta1.KafkaSourceOptions{
+					BootstrapServers: "kafka:9092",
+					Topics:           []string{"products", "prices"},
+				},
+				Image: &solrv1beta1.ContainerImage{
+					Repository: "example/solr-kafka-bridge",
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		By("creating the SolrIndexingBridge")
+		Expect(k8sClient.Create(ctx, solrIndexingBridge)).To(Succeed())
+
+		By("defaulting the missing SolrIndexingBridge values")
+		expectSolrIndexingBridgeWithChecks(ctx, solrIndexingBridge, func(g Gomega, found *solrv1beta1.SolrIndexingBridge) {
+			g.Expect(found.WithDefaults()).To(BeFalse(), "The SolrIndexingBridge spec should not need to be defaulted eventually")
+		})
+	})
+
+	AfterEach(func() {
+		cleanupTest(ctx, solrIndexingBridge)
+	})
+
+	FContext("Use explicit ZK Connection Info", func() {
+		testZkCnxString := "host:2181"
+		testZKChroot := "/this/path"
+		BeforeEach(func() {
+			solrIndexingBridge.Spec.SolrReference = solrv1beta1.SolrReference{
+				Cloud: &solrv1beta1.SolrCloudReference{
+					ZookeeperConnectionInfo: &solrv1beta1.ZookeeperConnectionInfo{
+						InternalConnectionString: testZkCnxString,
+						ChRoot:                   testZKChroot,
+					},
+				},
+			}
+		})
+		FIt("has the correct resources", func() {
+			foundBridge := expectSolrIndexingBridge(ctx, solrIndexingBridge)
+			Expect(foundBridge.Spec.Kafka.ConsumerGroup).To(Equal("foo-solr-indexing-bridge"), "Wrong default Kafka consumer group")
+
+			By("testing the SolrIndexingBridge Deployment")
+			deployment := expectDeploymentWithChecks(ctx, solrIndexingBridge, solrIndexingBridge.DeploymentName(), func(g Gomega, found *appsv1.Deployment) {
+				g.Expect(metav1.IsControlledBy(found, foundBridge)).To(BeTrue(), "The Deployment should be controlled by the SolrIndexingBridge")
+			})
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)), "Wrong default number of replicas for the Deployment")
+			Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1), "Wrong number of containers for the Deployment")
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("example/solr-kafka-bridge:latest"), "Wrong image for the bridge container")
+			testGenericPodEnvVariables(
+				map[string]string{
+					"KAFKA_BOOTSTRAP_SERVERS": "kafka:9092",
+					"KAFKA_TOPICS":            "products,prices",
+					"KAFKA_CONSUMER_GROUP":    "foo-solr-indexing-bridge",
+					"SOLR_COLLECTION":         "products",
+					"SOLR_BATCH_SIZE":         "500",
+					"SOLR_ZK_HOST":            testZkCnxString + testZKChroot,
+				},
+				filterVarsByName(deployment.Spec.Template.Spec.Containers[0].Env, func(name string) bool {
+					return name != "JAVA_OPTS"
+				}),
+				"SOLR_ZK_HOST",
+			)
+
+			By("testing the SolrIndexingBridge status")
+			expectSolrIndexingBridgeWithChecks(ctx, solrIndexingBridge, func(g Gomega, found *solrv1beta1.SolrIndexingBridge) {
+				g.Expect(found.Status.DeploymentName).To(Equal(solrIndexingBridge.DeploymentName()), "Wrong Deployment name in the status")
+				g.Expect(found.Status.Ready).To(BeFalse(), "The bridge cannot be ready without ready pods")
+				g.Expect(found.Status.ReadyReplicas).To(BeZero(), "The bridge cannot have ready pods in the test environment")
+			})
+		})
+	})
+
+	FContext("Missing image repository", func() {
+		BeforeEach(func() {
+			solrIndexingBridge.Spec.Image = &solrv1beta1.ContainerImage{Tag: "1.0"}
+			solrIndexingBridge.Spec.SolrReference = solrv1beta1.SolrReference{
+				Standalone: &solrv1beta1.StandaloneSolrReference{
+					Address: "http://solr:8983/solr",
+				},
+			}
+		})
+		FIt("does not create a Deployment", func() {
+			foundBridge := expectSolrIndexingBridge(ctx, solrIndexingBridge)
+			expectNoDeployment(ctx, solrIndexingBridge, solrIndexingBridge.DeploymentName())
+
+			By("testing the event for the invalid spec")
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(solrIndexingBridge.Namespace))).To(Succeed())
+				var reasons []string
+				for _, event := range events.Items {
+					if event.InvolvedObject.UID == foundBridge.UID {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+				g.Expect(reasons).To(ContainElement(util.InvalidSpecReason), "An event should be recorded for the missing image repository")
+			}).Should(Succeed())
+		})
+	})
+})
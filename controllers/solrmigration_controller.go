@@ -0,0 +1,479 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrMigrationReconciler reconciles a SolrMigration object
+type SolrMigrationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrrestores,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrmigrations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrmigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrmigrations/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
+func (r *SolrMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the SolrMigration instance
+	migration := &solrv1beta1.SolrMigration{}
+	err := r.Get(ctx, req.NamespacedName, migration)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, migration); err != nil || !selected {
+		// SolrMigrations that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return reconcile.Result{}, reportTerminalError(r.Recorder, migration, err, logger)
+	}
+
+	if migration.Status.Finished {
+		// Migrations are only ever run once
+		return reconcile.Result{}, nil
+	}
+
+	if changed := migration.WithDefaults(); changed {
+		logger.Info("Setting default settings for solr-migration")
+		if err = r.Update(ctx, migration); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	oldStatus := migration.Status.DeepCopy()
+
+	// While collections are being exported, cut over or decommissioned, auto-requeue to continue the migration.
+	// The SolrRestore is owned by the SolrMigration, so the migration is reconciled once it changes.
+	requeueOrNot := reconcile.Result{}
+
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrMigrationCompleteCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: migration.Generation,
+	}
+	waitingMessage, err := r.reconcileSolrMigration(ctx, migration, logger)
+	if terminalErr, isTerminal := util.AsTerminalError(err); isTerminal {
+		// The SolrMigration will be reconciled again once it, or the SolrCloud it references, change
+		logger.Error(terminalErr, "The SolrMigration is misconfigured, it will be reconciled again once it or the resources it references change", "reason", terminalErr.Reason)
+		condition.Reason = terminalErr.Reason
+		condition.Message = terminalErr.Error()
+		err = nil
+	} else if err != nil {
+		logger.Error(err, "Error while migrating collections")
+		requeueOrNot.RequeueAfter = util.RequeueAfter(util.RequeueRetry)
+		condition.Reason = "Error"
+		condition.Message = err.Error()
+	} else if waitingMessage != "" {
+		// The SolrCloud is watched, so the SolrMigration is reconciled once it is ready
+		condition.Reason = "Waiting"
+		condition.Message = waitingMessage
+	} else if !migration.Status.Finished {
+		if migration.Status.Phase != solrv1beta1.SolrMigrationRestoring {
+			requeueOrNot.RequeueAfter = util.RequeueAfter(util.RequeueBackupStatus)
+		}
+		condition.Reason = "InProgress"
+		condition.Message = fmt.Sprintf("%s collections: %s", migration.Status.Phase, strings.Join(migratedCollections(migration, false), ", "))
+	} else if migration.Status.Successful != nil && *migration.Status.Successful {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Succeeded"
+		condition.Message = "Migrated collections: " + strings.Join(migratedCollections(migration, false), ", ")
+	} else {
+		condition.Reason = "Failed"
+		condition.Message = "Could not migrate collections: " + strings.Join(migratedCollections(migration, true), ", ")
+	}
+	meta.SetStatusCondition(&migration.Status.Conditions, condition)
+
+	if !reflect.DeepEqual(oldStatus, &migration.Status) {
+		logger.Info("Updating status for solr-migration")
+		statusErr := r.Status().Update(ctx, migration)
+		if err == nil {
+			err = statusErr
+		}
+		// The migration is only published once its status has been saved, so that it is published once
+		if statusErr == nil && migration.Status.Finished && !oldStatus.Finished {
+			util.PublishCloudEvent(util.SolrMigrationCompletedEvent, "solrmigrations", migration, map[string]interface{}{
+				"solrCloud":  migration.Spec.SolrCloud,
+				"successful": migration.Status.Successful != nil && *migration.Status.Successful,
+			})
+		}
+	}
+
+	return requeueOrNot, err
+}
+
+// reconcileSolrMigration moves the SolrMigration through its phases: exporting the collections from the source Solr cluster,
+// restoring them into the SolrCloud, creating their aliases and, optionally, deleting them from the source Solr cluster.
+// If the SolrMigration cannot start yet, because its SolrCloud is not ready, the reason is returned as a message.
+func (r *SolrMigrationReconciler) reconcileSolrMigration(ctx context.Context, migration *solrv1beta1.SolrMigration, logger logr.Logger) (waitingMessage string, err error) {
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: migration.Namespace, Name: migration.Spec.SolrCloud}, solrCloud); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("Waiting for SolrCloud %s to be created", migration.Spec.SolrCloud), nil
+		}
+		return "", err
+	}
+	backupRepository, err := util.MigrationBackupRepository(solrCloud, migration)
+	if err != nil {
+		return "", err
+	}
+
+	// This should only occur before the exports have been started
+	if migration.Status.Phase == "" {
+		cloudReady := solrCloud.Status.Replicas == solrCloud.Status.ReadyReplicas
+		if !cloudReady || !solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+			return fmt.Sprintf("Waiting for SolrCloud %s to be ready for migrations", solrCloud.Name), nil
+		}
+
+		now := metav1.Now()
+		migration.Status.StartTime = &now
+		migration.Status.Phase = solrv1beta1.SolrMigrationExporting
+		for _, collection := range migration.Spec.Collections {
+			migration.Status.CollectionMigrationStatuses = append(migration.Status.CollectionMigrationStatuses, solrv1beta1.CollectionMigrationStatus{
+				Collection: collection.Name,
+				Target:     collection.Target,
+			})
+		}
+	}
+
+	var sourceHeaders map[string]string
+	if migration.Spec.Source.BasicAuthSecret != "" {
+		basicAuthSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: migration.Spec.Source.BasicAuthSecret, Namespace: migration.Namespace}, basicAuthSecret); err != nil {
+			return "", err
+		}
+		sourceHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	}
+	if err = util.ValidateOperatorCanCallSolr(solrCloud); err != nil {
+		return "", err
+	}
+	var httpHeaders map[string]string
+	if solrCloud.UsesBasicAuth() {
+		basicAuthSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+			return "", err
+		}
+		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	}
+
+	switch migration.Status.Phase {
+	case solrv1beta1.SolrMigrationExporting:
+		for i := range migration.Status.CollectionMigrationStatuses {
+			if collectionErr := reconcileSolrCollectionExport(migration, &migration.Status.CollectionMigrationStatuses[i], solrCloud, backupRepository, sourceHeaders, logger); collectionErr != nil {
+				err = collectionErr
+			}
+		}
+		if allFinished, allSuccessful := util.CheckStatusOfCollectionExports(migration); allFinished {
+			if allSuccessful {
+				migration.Status.Phase = solrv1beta1.SolrMigrationRestoring
+			} else if writableErr := makeSourceCollectionsWritable(migration, solrCloud, sourceHeaders, logger); writableErr != nil {
+				err = writableErr
+			} else {
+				finishSolrMigration(migration, false)
+			}
+		}
+	case solrv1beta1.SolrMigrationRestoring:
+		var finished, successful bool
+		if finished, successful, err = r.reconcileMigrationRestore(ctx, migration, backupRepository, logger); err == nil && finished {
+			if successful {
+				migration.Status.Phase = solrv1beta1.SolrMigrationCuttingOver
+			} else if err = makeSourceCollectionsWritable(migration, solrCloud, sourceHeaders, logger); err == nil {
+				finishSolrMigration(migration, false)
+			}
+		}
+	case solrv1beta1.SolrMigrationCuttingOver:
+		for i := range migration.Status.CollectionMigrationStatuses {
+			collectionStatus := &migration.Status.CollectionMigrationStatuses[i]
+			alias := migrationAlias(migration, collectionStatus.Collection)
+			if alias == "" || collectionStatus.Aliased {
+				continue
+			}
+			if aliasErr := util.CreateAliasForCollection(solrCloud, alias, collectionStatus.Target, httpHeaders, logger); aliasErr != nil {
+				err = aliasErr
+			} else {
+				collectionStatus.Aliased = true
+			}
+		}
+		if err == nil {
+			if migration.Spec.DecommissionSource {
+				migration.Status.Phase = solrv1beta1.SolrMigrationDecommissioning
+			} else {
+				finishSolrMigration(migration, true)
+			}
+		}
+	case solrv1beta1.SolrMigrationDecommissioning:
+		allDecommissioned := true
+		for i := range migration.Status.CollectionMigrationStatuses {
+			collectionStatus := &migration.Status.CollectionMigrationStatuses[i]
+			if collectionStatus.Decommissioned || collectionStatus.Message != "" {
+				allDecommissioned = allDecommissioned && collectionStatus.Decommissioned
+				continue
+			}
+			// Only delete the source collection once the migrated collection is known to have every document
+			sourceCount, targetCount, countErr := util.CountMigratedDocuments(solrCloud, migration, collectionStatus, sourceHeaders, httpHeaders)
+			if countErr != nil {
+				err = countErr
+				continue
+			}
+			if sourceCount != targetCount {
+				logger.Info("Not deleting the collection from the source, since the migrated collection does not have the same number of documents",
+					"collection", collectionStatus.Collection, "sourceDocuments", sourceCount, "targetDocuments", targetCount)
+				collectionStatus.Message = fmt.Sprintf("The collection was not deleted from the source Solr cluster, since it has %d documents, but the migrated collection has %d", sourceCount, targetCount)
+				allDecommissioned = false
+				continue
+			}
+			if deleteErr := util.DecommissionSourceCollection(solrCloud, migration, collectionStatus.Collection, sourceHeaders, logger); deleteErr != nil {
+				err = deleteErr
+			} else {
+				collectionStatus.Decommissioned = true
+			}
+		}
+		if err == nil {
+			finishSolrMigration(migration, allDecommissioned)
+		}
+	}
+
+	return "", err
+}
+
+func reconcileSolrCollectionExport(migration *solrv1beta1.SolrMigration, collectionStatus *solrv1beta1.CollectionMigrationStatus, solrCloud *solrv1beta1.SolrCloud, backupRepository *solrv1beta1.SolrBackupRepository, sourceHeaders map[string]string, logger logr.Logger) (err error) {
+	if collectionStatus.Exported || collectionStatus.Message != "" {
+		return nil
+	}
+
+	// The collection is made read-only before it is exported, so that no updates are made to the source after the export
+	if !collectionStatus.SourceReadOnly {
+		if err = util.SetSourceCollectionReadOnly(solrCloud, migration, collectionStatus.Collection, true, sourceHeaders, logger); err != nil {
+			return failCollectionExportOnApiError(collectionStatus, "The collection could not be made read-only in the source Solr cluster", err)
+		}
+		collectionStatus.SourceReadOnly = true
+	}
+
+	// If the collection export hasn't started, start it
+	if !collectionStatus.ExportInProgress {
+		started, err := util.StartExportForCollection(solrCloud, backupRepository, migration, collectionStatus.Collection, sourceHeaders, logger)
+		if err != nil {
+			return failCollectionExportOnApiError(collectionStatus, "The source Solr cluster could not start the export of the collection", err)
+		}
+		collectionStatus.ExportInProgress = started
+		return nil
+	}
+
+	// Check the state of the export, when it is in progress, and update the state accordingly
+	finished, successful, asyncStatus, err := util.CheckExportForCollection(solrCloud, migration, collectionStatus.Collection, sourceHeaders, logger)
+	if err != nil {
+		return err
+	}
+	if !finished {
+		collectionStatus.AsyncExportStatus = asyncStatus
+		return nil
+	}
+	collectionStatus.ExportInProgress = false
+	collectionStatus.AsyncExportStatus = ""
+	collectionStatus.Exported = successful
+	if !successful {
+		collectionStatus.Message = "The source Solr cluster could not export the collection"
+	}
+	return util.DeleteAsyncInfoForExport(solrCloud, migration, collectionStatus.Collection, sourceHeaders, logger)
+}
+
+// failCollectionExportOnApiError fails the migration of a collection when the source Solr cluster refuses a Collections API call, since retrying it would fail again.
+// Other errors, such as connection errors, are returned so that the call is retried.
+func failCollectionExportOnApiError(collectionStatus *solrv1beta1.CollectionMigrationStatus, message string, err error) error {
+	if apiError, isApiError := err.(solr_api.APIError); isApiError {
+		collectionStatus.Message = message + ": " + apiError.Error()
+		return nil
+	}
+	return err
+}
+
+// makeSourceCollectionsWritable makes the collections that the SolrMigration made read-only in the source Solr cluster writable again,
+// since a migration that failed before the cutover leaves the source Solr cluster in use
+func makeSourceCollectionsWritable(migration *solrv1beta1.SolrMigration, solrCloud *solrv1beta1.SolrCloud, sourceHeaders map[string]string, logger logr.Logger) (err error) {
+	for i := range migration.Status.CollectionMigrationStatuses {
+		collectionStatus := &migration.Status.CollectionMigrationStatuses[i]
+		if !collectionStatus.SourceReadOnly {
+			continue
+		}
+		if writableErr := util.SetSourceCollectionReadOnly(solrCloud, migration, collectionStatus.Collection, false, sourceHeaders, logger); writableErr != nil {
+			err = writableErr
+		} else {
+			collectionStatus.SourceReadOnly = false
+		}
+	}
+	return err
+}
+
+// reconcileMigrationRestore creates the SolrRestore that restores the exported collections into the SolrCloud, and follows its progress.
+// Whether the SolrRestore has finished, and whether it restored every collection, is returned.
+func (r *SolrMigrationReconciler) reconcileMigrationRestore(ctx context.Context, migration *solrv1beta1.SolrMigration, backupRepository *solrv1beta1.SolrBackupRepository, logger logr.Logger) (finished bool, successful bool, err error) {
+	restore := &solrv1beta1.SolrRestore{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: migration.Namespace, Name: migration.SolrRestoreName()}, restore)
+	if errors.IsNotFound(err) {
+		restore = util.GenerateSolrRestoreForMigration(migration, backupRepository)
+		if err = controllerutil.SetControllerReference(migration, restore, r.Scheme); err != nil {
+			return false, false, err
+		}
+		logger.Info("Creating SolrRestore for migration", "solrRestore", restore.Name)
+		if err = r.Create(ctx, restore); err == nil {
+			migration.Status.SolrRestore = restore.Name
+		}
+		return false, false, err
+	} else if err != nil {
+		return false, false, err
+	}
+	migration.Status.SolrRestore = restore.Name
+
+	finished, successful = util.UpdateMigrationFromRestore(migration, restore)
+	return finished, successful, nil
+}
+
+// finishSolrMigration marks the SolrMigration as finished, so that it is never run again
+func finishSolrMigration(migration *solrv1beta1.SolrMigration, successful bool) {
+	now := metav1.Now()
+	migration.Status.Finished = true
+	migration.Status.Successful = &successful
+	migration.Status.FinishTime = &now
+	if successful {
+		migration.Status.Phase = solrv1beta1.SolrMigrationComplete
+	} else {
+		migration.Status.Phase = solrv1beta1.SolrMigrationFailed
+	}
+}
+
+// migrationAlias returns the alias to create for a collection of the SolrMigration, if any
+func migrationAlias(migration *solrv1beta1.SolrMigration, collection string) string {
+	for _, migrationCollection := range migration.Spec.Collections {
+		if migrationCollection.Name == collection {
+			return migrationCollection.Alias
+		}
+	}
+	return ""
+}
+
+// migratedCollections lists the collections of the SolrMigration, optionally only those that failed, with the reason that they failed
+func migratedCollections(migration *solrv1beta1.SolrMigration, onlyFailed bool) (collections []string) {
+	for _, collectionStatus := range migration.Status.CollectionMigrationStatuses {
+		if !onlyFailed {
+			collections = append(collections, collectionStatus.Collection)
+		} else if collectionStatus.Message != "" {
+			collections = append(collections, fmt.Sprintf("%s (%s)", collectionStatus.Collection, collectionStatus.Message))
+		}
+	}
+	return collections
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SolrMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrMigration{}).
+		Owns(&solrv1beta1.SolrRestore{})
+
+	var err error
+	ctrlBuilder, err = r.indexAndWatchForSolrCloud(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	return ctrlBuilder.Complete(r)
+}
+
+// Get notified when the SolrCloud of a SolrMigration changes, so that migrations waiting for it are started once it is ready
+func (r *SolrMigrationReconciler) indexAndWatchForSolrCloud(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	solrCloudField := ".spec.solrCloud"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrMigration{}, solrCloudField, func(rawObj client.Object) []string {
+		return []string{rawObj.(*solrv1beta1.SolrMigration).Spec.SolrCloud}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrCloud{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			foundMigrations := &solrv1beta1.SolrMigrationList{}
+			listOps := &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(solrCloudField, obj.GetName()),
+				Namespace:     obj.GetNamespace(),
+			}
+			if err := r.List(context.Background(), foundMigrations, listOps); err != nil {
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, 0, len(foundMigrations.Items))
+			for _, item := range foundMigrations.Items {
+				// Finished migrations are never run again
+				if item.Status.Finished {
+					continue
+				}
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				})
+			}
+			return requests
+		}),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
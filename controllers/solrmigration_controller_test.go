@@ -0,0 +1,168 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = FDescribe("SolrMigration controller - General", func() {
+
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 5
+		duration = time.Second * 1
+		interval = time.Millisecond * 250
+	)
+	SetDefaultConsistentlyDuration(duration)
+	SetDefaultConsistentlyPollingInterval(interval)
+	SetDefaultEventuallyTimeout(timeout)
+	SetDefaultEventuallyPollingInterval(interval)
+
+	var (
+		ctx context.Context
+
+		solrMigration *solrv1beta1.SolrMigration
+		solrCloud     *solrv1beta1.SolrCloud
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		solrMigration = &solrv1beta1.SolrMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "migration",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrMigrationSpec{
+				SolrCloud: "foo",
+				Source: solrv1beta1.SolrMigrationSource{
+					URL:            "http://solr.old-cluster.example.com",
+					RepositoryName: "gcs-backups",
+				},
+				Collections: []solrv1beta1.SolrMigrationCollection{
+					{Name: "products", Alias: "products-alias"},
+				},
+			},
+		}
+
+		solrCloud = &solrv1beta1.SolrCloud{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrCloudSpec{
+				ZookeeperRef: &solrv1beta1.ZookeeperRef{
+					ConnectionInfo: &solrv1beta1.ZookeeperConnectionInfo{
+						InternalConnectionString: "host:7271",
+					},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		By("creating the SolrMigration")
+		Expect(k8sClient.Create(ctx, solrMigration)).To(Succeed())
+
+		By("defaulting the missing SolrMigration values")
+		expectSolrMigrationWithChecks(ctx, solrMigration, func(g Gomega, found *solrv1beta1.SolrMigration) {
+			g.Expect(found.WithDefaults()).To(BeFalse(), "The SolrMigration spec should not need to be defaulted eventually")
+			g.Expect(found.Spec.Collections[0].Target).To(Equal("products"), "The target collection should default to the name of the collection")
+		})
+	})
+
+	AfterEach(func() {
+		cleanupTest(ctx, solrMigration)
+	})
+
+	FContext("GCS repository", func() {
+		BeforeEach(func() {
+			solrCloud.Spec.BackupRepositories = []solrv1beta1.SolrBackupRepository{
+				{
+					Name: "gcs",
+					GCS: &solrv1beta1.GcsRepository{
+						Bucket: "backups",
+						GcsCredentialSecret: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "gcs-credentials"},
+							Key:                  "service-account-key.json",
+						},
+					},
+				},
+			}
+		})
+		FIt("creates and owns the SolrRestore of the exported collections", func() {
+			expectSolrMigrationCondition(ctx, solrMigration, "Waiting", "Waiting for SolrCloud foo to be created")
+
+			// Exporting the collections requires a source Solr cluster, so the migration continues from a finished export
+			By("finishing the export of the collections")
+			Eventually(func() error {
+				foundMigration := expectSolrMigration(ctx, solrMigration)
+				now := metav1.Now()
+				foundMigration.Status.StartTime = &now
+				foundMigration.Status.Phase = solrv1beta1.SolrMigrationRestoring
+				foundMigration.Status.CollectionMigrationStatuses = []solrv1beta1.CollectionMigrationStatus{
+					{Collection: "products", Target: "products", SourceReadOnly: true, Exported: true},
+				}
+				return k8sClient.Status().Update(ctx, foundMigration)
+			}).Should(Succeed(), "Update the status of the SolrMigration")
+
+			By("creating the SolrCloud")
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+			foundMigration := expectSolrMigrationCondition(ctx, solrMigration, "InProgress", "Restoring collections: products")
+			Expect(foundMigration.Status.SolrRestore).To(Equal(solrMigration.SolrRestoreName()), "The SolrRestore should be recorded in the status")
+
+			By("testing the SolrRestore of the migration")
+			expectSolrRestoreWithChecks(ctx, &solrv1beta1.SolrRestore{ObjectMeta: metav1.ObjectMeta{Name: solrMigration.SolrRestoreName(), Namespace: solrMigration.Namespace}}, func(g Gomega, found *solrv1beta1.SolrRestore) {
+				g.Expect(metav1.IsControlledBy(found, foundMigration)).To(BeTrue(), "The SolrRestore should be controlled by the SolrMigration")
+				g.Expect(found.Spec.SolrCloud).To(Equal("foo"), "Wrong SolrCloud for the SolrRestore")
+				g.Expect(found.Spec.RepositoryName).To(Equal("gcs"), "The SolrRestore should read from the repository of the migration")
+				g.Expect(found.Spec.BackupName).To(Equal(solrMigration.Name), "The SolrRestore should restore the export of the migration")
+				g.Expect(found.Spec.Collections).To(Equal([]solrv1beta1.SolrRestoreCollection{{Name: "products", Target: "products"}}), "Wrong collections for the SolrRestore")
+			})
+		})
+	})
+
+	FContext("Managed repository", func() {
+		BeforeEach(func() {
+			solrCloud.Spec.BackupRepositories = []solrv1beta1.SolrBackupRepository{
+				{
+					Name: "local",
+					Managed: &solrv1beta1.ManagedRepository{
+						Volume: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+						},
+					},
+				},
+			}
+		})
+		FIt("reports the invalid repository", func() {
+			By("creating the SolrCloud")
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+			expectSolrMigrationCondition(ctx, solrMigration, util.InvalidSpecReason, "the backup repository \"local\" of SolrCloud foo must be a GCS repository")
+		})
+	})
+})
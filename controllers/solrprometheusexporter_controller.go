@@ -19,7 +19,6 @@ package controllers
 
 import (
 	"context"
-	"crypto/md5"
 	"fmt"
 	"github.com/apache/solr-operator/controllers/util"
 	appsv1 "k8s.io/api/apps/v1"
@@ -35,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -45,7 +45,8 @@ import (
 // SolrPrometheusExporterReconciler reconciles a SolrPrometheusExporter object
 type SolrPrometheusExporterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
@@ -59,6 +60,7 @@ type SolrPrometheusExporterReconciler struct {
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrprometheusexporters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrprometheusexporters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=solr.apache.org,resources=solrprometheusexporters/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -81,6 +83,11 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, prometheusExporter); err != nil || !selected {
+		// SolrPrometheusExporters that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return ctrl.Result{}, reportTerminalError(r.Recorder, prometheusExporter, err, logger)
+	}
+
 	changed := prometheusExporter.WithDefaults()
 	if changed {
 		logger.Info("Setting default settings for Solr PrometheusExporter")
@@ -104,14 +111,16 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 		if foundConfigMap.Data != nil {
 			configXml, ok := foundConfigMap.Data[configMapKey]
 			if ok {
-				configXmlMd5 = fmt.Sprintf("%x", md5.Sum([]byte(configXml)))
+				configXmlMd5 = util.ContentHash(foundConfigMap, configMapKey, func() []byte { return []byte(configXml) })
 			} else {
-				return requeueOrNot, fmt.Errorf("required '%s' key not found in provided ConfigMap %s",
+				err = util.TerminalErrorf(util.InvalidSpecReason, "required '%s' key not found in provided ConfigMap %s",
 					configMapKey, prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap)
+				return requeueOrNot, reportTerminalError(r.Recorder, prometheusExporter, err, logger)
 			}
 		} else {
-			return requeueOrNot, fmt.Errorf("provided ConfigMap %s has no data",
+			err = util.TerminalErrorf(util.InvalidSpecReason, "provided ConfigMap %s has no data",
 				prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap)
+			return requeueOrNot, reportTerminalError(r.Recorder, prometheusExporter, err, logger)
 		}
 	}
 
@@ -121,7 +130,7 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 
 		// capture the MD5 for the default config XML, otherwise we already computed it above
 		if configXmlMd5 == "" {
-			configXmlMd5 = fmt.Sprintf("%x", md5.Sum([]byte(configMap.Data[configMapKey])))
+			configXmlMd5 = util.HashContent([]byte(configMap.Data[configMapKey]))
 		}
 
 		// Check if the ConfigMap already exists
@@ -178,7 +187,7 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 
 	// Get the ZkConnectionString to connect to
 	solrConnectionInfo := util.SolrConnectionInfo{}
-	if solrConnectionInfo, err = getSolrConnectionInfo(ctx, r, prometheusExporter); err != nil {
+	if solrConnectionInfo, err = getSolrConnectionInfo(ctx, r.Client, prometheusExporter.Spec.SolrReference, prometheusExporter.Namespace); err != nil {
 		return requeueOrNot, err
 	}
 
@@ -187,7 +196,7 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 	if prometheusExporter.Spec.SolrReference.SolrTLS != nil {
 		tls, err = r.reconcileTLSConfig(prometheusExporter)
 		if err != nil {
-			return requeueOrNot, err
+			return requeueOrNot, reportTerminalError(r.Recorder, prometheusExporter, err, logger)
 		}
 	}
 
@@ -201,10 +210,11 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 
 		err = util.ValidateBasicAuthSecret(basicAuthSecret)
 		if err != nil {
-			return reconcile.Result{}, err
+			return reconcile.Result{}, reportTerminalError(r.Recorder, prometheusExporter, err, logger)
 		}
-		creds := fmt.Sprintf("%s:%s", basicAuthSecret.Data[corev1.BasicAuthUsernameKey], basicAuthSecret.Data[corev1.BasicAuthPasswordKey])
-		basicAuthMd5 = fmt.Sprintf("%x", md5.Sum([]byte(creds)))
+		basicAuthMd5 = util.ContentHash(basicAuthSecret, "basic-auth", func() []byte {
+			return []byte(fmt.Sprintf("%s:%s", basicAuthSecret.Data[corev1.BasicAuthUsernameKey], basicAuthSecret.Data[corev1.BasicAuthPasswordKey]))
+		})
 	}
 
 	deploy := util.GenerateSolrPrometheusExporterDeployment(prometheusExporter, solrConnectionInfo, configXmlMd5, tls, basicAuthMd5)
@@ -260,8 +270,9 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 		return requeueOrNot, err
 	}
 
-	if ready != prometheusExporter.Status.Ready {
+	if ready != prometheusExporter.Status.Ready || deploy.Name != prometheusExporter.Status.DeploymentName {
 		prometheusExporter.Status.Ready = ready
+		prometheusExporter.Status.DeploymentName = deploy.Name
 		logger.Info("Updating status for solr-prometheus-exporter")
 		err = r.Status().Update(ctx, prometheusExporter)
 	}
@@ -269,31 +280,6 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(ctx context.Context, req ct
 	return requeueOrNot, err
 }
 
-func getSolrConnectionInfo(ctx context.Context, r *SolrPrometheusExporterReconciler, prometheusExporter *solrv1beta1.SolrPrometheusExporter) (solrConnectionInfo util.SolrConnectionInfo, err error) {
-	solrConnectionInfo = util.SolrConnectionInfo{}
-
-	if prometheusExporter.Spec.SolrReference.Standalone != nil {
-		solrConnectionInfo.StandaloneAddress = prometheusExporter.Spec.SolrReference.Standalone.Address
-	}
-	if prometheusExporter.Spec.SolrReference.Cloud != nil {
-		cloudRef := prometheusExporter.Spec.SolrReference.Cloud
-		if cloudRef.ZookeeperConnectionInfo != nil {
-			solrConnectionInfo.CloudZkConnnectionInfo = cloudRef.ZookeeperConnectionInfo
-		} else if cloudRef.Name != "" {
-			solrCloud := &solrv1beta1.SolrCloud{}
-			solrNamespace := prometheusExporter.Spec.SolrReference.Cloud.Namespace
-			if solrNamespace == "" {
-				solrNamespace = prometheusExporter.Namespace
-			}
-			err = r.Get(ctx, types.NamespacedName{Name: prometheusExporter.Spec.SolrReference.Cloud.Name, Namespace: solrNamespace}, solrCloud)
-			if err == nil {
-				solrConnectionInfo.CloudZkConnnectionInfo = &solrCloud.Status.ZookeeperConnectionInfo
-			}
-		}
-	}
-	return solrConnectionInfo, err
-}
-
 // reconcileTLSConfig Reconciles the various options for configuring TLS for the exporter
 // The exporter is a client to Solr pods, so can either just have a truststore so it trusts Solr certs
 // Or it can have its own client auth cert when Solr mTLS is required
@@ -304,7 +290,7 @@ func (r *SolrPrometheusExporterReconciler) reconcileTLSConfig(prometheusExporter
 	if opts.PKCS12Secret != nil {
 		// Ensure one or the other have been configured, but not both
 		if opts.MountedTLSDir != nil {
-			return nil, fmt.Errorf("invalid TLS config, either supply `solrTLS.pkcs12Secret` or `solrTLS.mountedTLSDir` but not both")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, either supply `solrTLS.pkcs12Secret` or `solrTLS.mountedTLSDir` but not both")
 		}
 
 		// make sure the PKCS12Secret and corresponding keystore password exist and agree with the supplied config
@@ -312,11 +298,17 @@ func (r *SolrPrometheusExporterReconciler) reconcileTLSConfig(prometheusExporter
 		if err != nil {
 			return nil, err
 		}
+	} else if opts.TrustBundleConfigMap != nil {
+		// no client cert, but the exporter trusts the CAs in a bundle, the truststore gets generated from the bundle on startup
+		err := tls.ClientConfig.VerifyTrustBundleConfig(&r.Client)
+		if err != nil {
+			return nil, err
+		}
 	} else if opts.TrustStoreSecret != nil {
 		// no client cert, but we have truststore for the exporter, configure it ...
 		// Ensure one or the other have been configured, but not both
 		if opts.MountedTLSDir != nil {
-			return nil, fmt.Errorf("invalid TLS config, either supply `solrTLS.trustStoreSecret` or `solrTLS.mountedTLSDir` but not both")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, either supply `solrTLS.trustStoreSecret` or `solrTLS.mountedTLSDir` but not both")
 		}
 
 		// make sure the TrustStoreSecret and corresponding password exist and agree with the supplied config
@@ -327,11 +319,11 @@ func (r *SolrPrometheusExporterReconciler) reconcileTLSConfig(prometheusExporter
 	} else {
 		// per-pod TLS files get mounted into a dir on the pod dynamically using some external agent / CSI driver type mechanism
 		if opts.MountedTLSDir == nil {
-			return nil, fmt.Errorf("invalid TLS config, the 'solrTLS.mountedTLSDir' option is required unless you specify a keystore and/or truststore secret")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrTLS.mountedTLSDir' option is required unless you specify a keystore and/or truststore secret, or a trust bundle")
 		}
 
 		if opts.MountedTLSDir.KeystoreFile == "" && opts.MountedTLSDir.TruststoreFile == "" {
-			return nil, fmt.Errorf("invalid TLS config, the 'solrTLS.mountedTLSDir' option must specify a keystoreFile and/or truststoreFile")
+			return nil, util.TerminalErrorf(util.InvalidTLSConfigReason, "invalid TLS config, the 'solrTLS.mountedTLSDir' option must specify a keystoreFile and/or truststoreFile")
 		}
 	}
 
@@ -369,6 +361,11 @@ func (r *SolrPrometheusExporterReconciler) SetupWithManager(mgr ctrl.Manager) er
 		return err
 	}
 
+	ctrlBuilder, err = r.indexAndWatchForTrustBundleConfigMap(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
 	// Get notified when the basic auth secret updates; exporter pods must be restarted if the basic auth password
 	// changes b/c the credentials are loaded from a Java system property at startup and not watched for changes.
 	ctrlBuilder, err = r.indexAndWatchForBasicAuthSecret(mgr, ctrlBuilder)
@@ -507,6 +504,49 @@ func (r *SolrPrometheusExporterReconciler) indexAndWatchForTruststoreSecret(mgr
 	return r.buildSecretWatch(tlsSecretField, ctrlBuilder)
 }
 
+func (r *SolrPrometheusExporterReconciler) indexAndWatchForTrustBundleConfigMap(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	trustBundleField := ".spec.solrReference.solrTLS.trustBundleConfigMap"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrPrometheusExporter{}, trustBundleField, func(rawObj client.Object) []string {
+		// grab the SolrCloud object, extract the referenced trust bundle configMap...
+		exporter := rawObj.(*solrv1beta1.SolrPrometheusExporter)
+		if exporter.Spec.SolrReference.SolrTLS == nil || exporter.Spec.SolrReference.SolrTLS.TrustBundleConfigMap == nil {
+			return nil
+		}
+		// ...and if so, return it
+		return []string{exporter.Spec.SolrReference.SolrTLS.TrustBundleConfigMap.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.ConfigMap{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			foundExporters := &solrv1beta1.SolrPrometheusExporterList{}
+			listOps := &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(trustBundleField, obj.GetName()),
+				Namespace:     obj.GetNamespace(),
+			}
+			err := r.List(context.Background(), foundExporters, listOps)
+			if err != nil {
+				// if no exporters found, just no-op this
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, len(foundExporters.Items))
+			for i, item := range foundExporters.Items {
+				requests[i] = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				}
+			}
+			return requests
+		}),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
 func (r *SolrPrometheusExporterReconciler) indexAndWatchForBasicAuthSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
 	secretField := ".spec.solrReference.basicAuthSecret"
 
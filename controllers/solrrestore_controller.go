@@ -0,0 +1,498 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrRestoreReconciler reconciles a SolrRestore object
+type SolrRestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	config   *rest.Config
+}
+
+//+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;create
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get;update
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups/status,verbs=get
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrrestores,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrrestores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrrestores/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
+func (r *SolrRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the SolrRestore instance
+	restore := &solrv1beta1.SolrRestore{}
+	err := r.Get(ctx, req.NamespacedName, restore)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, restore); err != nil || !selected {
+		// SolrRestores that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return reconcile.Result{}, reportTerminalError(r.Recorder, restore, err, logger)
+	}
+
+	if restore.Status.Finished {
+		// Restores are only ever run once
+		return reconcile.Result{}, nil
+	}
+
+	if changed := restore.WithDefaults(); changed {
+		logger.Info("Setting default settings for solr-restore")
+		if err = r.Update(ctx, restore); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	oldStatus := restore.Status.DeepCopy()
+
+	// While collections are being restored, auto-requeue to check on the status of the async solr restore calls
+	requeueOrNot := reconcile.Result{}
+
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrRestoreComplete,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: restore.Generation,
+	}
+	waitingMessage, err := r.reconcileSolrRestore(ctx, restore, logger)
+	if terminalErr, isTerminal := util.AsTerminalError(err); isTerminal {
+		// The SolrRestore will be reconciled again once it, or the resources it references, change
+		logger.Error(terminalErr, "The SolrRestore is misconfigured, it will be reconciled again once it or the resources it references change", "reason", terminalErr.Reason)
+		condition.Reason = terminalErr.Reason
+		condition.Message = terminalErr.Error()
+		err = nil
+	} else if err != nil {
+		logger.Error(err, "Error while restoring SolrCloud backup")
+		requeueOrNot.RequeueAfter = util.RequeueAfter(util.RequeueRetry)
+		condition.Reason = "Error"
+		condition.Message = err.Error()
+	} else if waitingMessage != "" {
+		// The SolrBackup and SolrCloud are watched, so the SolrRestore is reconciled once they are ready
+		condition.Reason = "Waiting"
+		condition.Message = waitingMessage
+	} else if !restore.Status.Finished {
+		requeueOrNot.RequeueAfter = util.RequeueAfter(util.RequeueBackupStatus)
+		condition.Reason = "InProgress"
+		condition.Message = "Restoring " + restoreTargets(restore, nil)
+	} else if restore.Status.Successful != nil && *restore.Status.Successful {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Succeeded"
+		condition.Message = "Restored " + restoreTargets(restore, nil)
+	} else {
+		fals := false
+		condition.Reason = "Failed"
+		condition.Message = "Could not restore " + restoreTargets(restore, &fals)
+	}
+	meta.SetStatusCondition(&restore.Status.Conditions, condition)
+
+	if !reflect.DeepEqual(oldStatus, &restore.Status) {
+		logger.Info("Updating status for solr-restore")
+		statusErr := r.Status().Update(ctx, restore)
+		if err == nil {
+			err = statusErr
+		}
+		// The restore is only published, and recorded in the history of the SolrCloud, once its status has been saved, so that it is recorded once
+		if statusErr == nil && restore.Status.Finished && !oldStatus.Finished {
+			util.PublishCloudEvent(util.SolrRestoreCompletedEvent, "solrrestores", restore, map[string]interface{}{
+				"solrCloud":  restore.Spec.SolrCloud,
+				"successful": restore.Status.Successful != nil && *restore.Status.Successful,
+			})
+			if historyErr := util.RecordSolrCloudOperation(ctx, r.Client, restore.Namespace, restore.Spec.SolrCloud, util.RestoreOperation(restore)); historyErr != nil {
+				logger.Error(historyErr, "Could not record the restore in the history of the SolrCloud", "solrCloud", restore.Spec.SolrCloud)
+			}
+		}
+	}
+
+	return requeueOrNot, err
+}
+
+// reconcileSolrRestore starts the restores of the collections, and checks on them until they have all finished.
+// If the SolrRestore cannot start yet, because its SolrBackup or SolrCloud are not ready, the reason is returned as a message.
+func (r *SolrRestoreReconciler) reconcileSolrRestore(ctx context.Context, restore *solrv1beta1.SolrRestore, logger logr.Logger) (waitingMessage string, err error) {
+	if (restore.Spec.SolrBackup == "") == (restore.Spec.BackupName == "") {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "exactly one of solrBackup and backupName must be provided")
+	}
+	if restore.Spec.SolrBackup != "" && restore.Spec.RepositoryName != "" {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "repositoryName cannot be provided with solrBackup, the repository of the SolrBackup is used")
+	}
+	if restore.Spec.Repository != nil && (restore.Spec.SolrBackup != "" || restore.Spec.RepositoryName != "") {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "repository cannot be provided with solrBackup or repositoryName")
+	}
+
+	var backup *solrv1beta1.SolrBackup
+	backupName := restore.Spec.BackupName
+	repositoryName := restore.Spec.RepositoryName
+	if restore.Spec.SolrBackup != "" {
+		backup = &solrv1beta1.SolrBackup{}
+		if err = r.Get(ctx, types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.SolrBackup}, backup); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("Waiting for SolrBackup %s to be created", restore.Spec.SolrBackup), nil
+			}
+			return "", err
+		}
+		if !backup.Status.Finished {
+			return fmt.Sprintf("Waiting for SolrBackup %s to finish", backup.Name), nil
+		}
+		if backup.Status.Successful == nil || !*backup.Status.Successful {
+			return "", util.TerminalErrorf(util.InvalidSpecReason, "SolrBackup %s was not successful, it cannot be restored", backup.Name)
+		}
+		backupName = backup.Name
+		repositoryName = backup.Spec.RepositoryName
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.SolrCloud}, solrCloud); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("Waiting for SolrCloud %s to be created", restore.Spec.SolrCloud), nil
+		}
+		return "", err
+	}
+	if backup != nil && backup.Spec.VolumeSnapshot != nil {
+		return r.reconcileVolumeSnapshotRestore(ctx, restore, backup, solrCloud, logger)
+	}
+
+	var backupRepository *solrv1beta1.SolrBackupRepository
+	if restore.Spec.Repository != nil {
+		// The backup was taken by another SolrCloud, so find the repository of this SolrCloud that can read it
+		if backupRepository, err = util.BackupRepositoryForRestore(solrCloud, restore.Spec.Repository); err != nil {
+			return "", err
+		}
+	} else if backupRepository = util.GetBackupRepositoryByName(solrCloud.Spec.BackupRepositories, repositoryName); backupRepository == nil {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "SolrCloud %s must define the backup repository %q (or have only 1 repository defined) to restore from", solrCloud.Name, repositoryName)
+	}
+	if err = util.ValidateClusterStateRestore(restore, backupRepository); err != nil {
+		return "", err
+	}
+
+	if err = util.ValidateOperatorCanCallSolr(solrCloud); err != nil {
+		return "", err
+	}
+	var httpHeaders map[string]string
+	if solrCloud.UsesBasicAuth() {
+		basicAuthSecret := &corev1.Secret{}
+		if err = r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+			return "", err
+		}
+		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	}
+
+	// This should only occur before the restore processes have been started
+	if len(restore.Status.CollectionRestoreStatuses) == 0 {
+		collections, err := util.CollectionsToRestore(restore, backup)
+		if err != nil {
+			return "", err
+		}
+
+		// Make sure that all solr nodes are active and have the backupRestore shared volume mounted
+		cloudReady := solrCloud.Status.BackupRestoreReady && (solrCloud.Status.Replicas == solrCloud.Status.ReadyReplicas)
+		if !cloudReady || !solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+			return fmt.Sprintf("Waiting for SolrCloud %s to be ready for restores", solrCloud.Name), nil
+		}
+
+		// Fail before restoring any collection, if some of them cannot be restored
+		if err = util.CheckBackupsForRestore(solrCloud, backupRepository, restore, backupName, collections, httpHeaders, logger); err != nil {
+			return "", err
+		}
+
+		now := metav1.Now()
+		restore.Status.StartTime = &now
+		for _, collection := range collections {
+			restore.Status.CollectionRestoreStatuses = append(restore.Status.CollectionRestoreStatuses, solrv1beta1.CollectionRestoreStatus{
+				Collection: collection.Name,
+				Target:     collection.Target,
+			})
+		}
+	}
+
+	// Go through each collection and reconcile the restore.
+	for i := range restore.Status.CollectionRestoreStatuses {
+		if collectionErr := reconcileSolrCollectionRestore(restore, &restore.Status.CollectionRestoreStatuses[i], solrCloud, backupRepository, backupName, httpHeaders, logger); collectionErr != nil {
+			err = collectionErr
+		}
+	}
+
+	if allFinished, allSuccessful := util.CheckStatusOfCollectionRestores(restore); allFinished {
+		// The cluster state refers to the restored collections, so it is only restored once they have all been restored
+		if restore.Spec.RestoreClusterState && allSuccessful && meta.FindStatusCondition(restore.Status.Conditions, solrv1beta1.SolrRestoreClusterStateRestored) == nil {
+			meta.SetStatusCondition(&restore.Status.Conditions, restoreSolrCloudClusterState(restore, solrCloud, backupRepository, backupName, httpHeaders, r.config, logger))
+		}
+		// A restore that could not rebuild the cluster state, when requested, is not successful
+		if restore.Spec.RestoreClusterState && !meta.IsStatusConditionTrue(restore.Status.Conditions, solrv1beta1.SolrRestoreClusterStateRestored) {
+			allSuccessful = false
+		}
+		now := metav1.Now()
+		restore.Status.Finished = true
+		restore.Status.Successful = &allSuccessful
+		restore.Status.FinishTime = &now
+	}
+
+	return "", err
+}
+
+func reconcileSolrCollectionRestore(restore *solrv1beta1.SolrRestore, collectionStatus *solrv1beta1.CollectionRestoreStatus, solrCloud *solrv1beta1.SolrCloud, backupRepository *solrv1beta1.SolrBackupRepository, backupName string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	now := metav1.Now()
+	collection := solrv1beta1.SolrRestoreCollection{Name: collectionStatus.Collection, Target: collectionStatus.Target}
+
+	// If the collection restore hasn't started, start it
+	if !collectionStatus.InProgress && !collectionStatus.Finished {
+		// Start the restore by calling solr
+		started, err := util.StartRestoreForCollection(solrCloud, backupRepository, restore, backupName, collection, httpHeaders, logger)
+		if err != nil {
+			return err
+		}
+		collectionStatus.InProgress = started
+		if started && collectionStatus.StartTime == nil {
+			collectionStatus.StartTime = &now
+		}
+	} else if collectionStatus.InProgress {
+		// Check the state of the restore, when it is in progress, and update the state accordingly
+		finished, successful, asyncStatus, err := util.CheckRestoreForCollection(solrCloud, restore, collection.Target, httpHeaders, logger)
+		if err != nil {
+			return err
+		}
+		collectionStatus.Finished = finished
+		if finished {
+			collectionStatus.InProgress = false
+			if collectionStatus.Successful == nil {
+				collectionStatus.Successful = &successful
+			}
+			collectionStatus.AsyncRestoreStatus = ""
+			if collectionStatus.FinishTime == nil {
+				collectionStatus.FinishTime = &now
+			}
+
+			err = util.DeleteAsyncInfoForRestore(solrCloud, restore, collection.Target, httpHeaders, logger)
+		} else {
+			collectionStatus.AsyncRestoreStatus = asyncStatus
+		}
+		return err
+	}
+	return nil
+}
+
+// restoreSolrCloudClusterState restores the cluster state exported with the backup into the SolrCloud, and returns the resulting "ClusterStateRestored" condition.
+func restoreSolrCloudClusterState(restore *solrv1beta1.SolrRestore, solrCloud *solrv1beta1.SolrCloud, backupRepository *solrv1beta1.SolrBackupRepository, backupName string, httpHeaders map[string]string, config *rest.Config, logger logr.Logger) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               solrv1beta1.SolrRestoreClusterStateRestored,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Error",
+		ObservedGeneration: restore.Generation,
+	}
+
+	restored, err := util.RestoreClusterStateFromBackup(solrCloud, backupRepository, restore, backupName, httpHeaders, config)
+	if err != nil {
+		logger.Error(err, "Could not restore the cluster state of the backup")
+		condition.Message = "Could not restore the cluster state: " + err.Error()
+		return condition
+	}
+	logger.Info("Restored cluster state of the backup", "znodes", restored)
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Restored"
+	condition.Message = "Restored znodes: " + strings.Join(restored, ", ")
+	if len(restored) == 0 {
+		condition.Message = "The exported cluster state did not need to be restored"
+	}
+	return condition
+}
+
+// reconcileVolumeSnapshotRestore restores a SolrBackup taken as VolumeSnapshots, by creating the data PersistentVolumeClaims of the SolrCloud from the snapshots.
+// The Solr Node with the same ordinal as each snapshotted Solr Node uses the restored PersistentVolumeClaim, once the SolrCloud is scaled up to include it.
+// PersistentVolumeClaims that already exist are not restored, since their data cannot be replaced.
+func (r *SolrRestoreReconciler) reconcileVolumeSnapshotRestore(ctx context.Context, restore *solrv1beta1.SolrRestore, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, logger logr.Logger) (waitingMessage string, err error) {
+	if len(restore.Spec.Collections) > 0 || restore.Spec.BackupId != nil || restore.Spec.RestoreClusterState {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "collections, backupId and restoreClusterState cannot be provided to restore SolrBackup %s, which was taken as VolumeSnapshots", backup.Name)
+	}
+	if !solrCloud.UsesPersistentStorage() {
+		return "", util.TerminalErrorf(util.InvalidSpecReason, "SolrCloud %s must use persistent storage to restore SolrBackup %s, which was taken as VolumeSnapshots", solrCloud.Name, backup.Name)
+	}
+
+	// This should only occur before the PersistentVolumeClaims have been restored
+	if len(restore.Status.VolumeRestoreStatuses) == 0 {
+		if restore.Status.VolumeRestoreStatuses, err = util.VolumeRestoreStatuses(backup, solrCloud); err != nil {
+			return "", err
+		}
+		now := metav1.Now()
+		restore.Status.StartTime = &now
+	}
+
+	for i := range restore.Status.VolumeRestoreStatuses {
+		restoreStatus := &restore.Status.VolumeRestoreStatuses[i]
+		if restoreStatus.Restored || restoreStatus.Message != "" {
+			continue
+		}
+		foundPVC := &corev1.PersistentVolumeClaim{}
+		getErr := r.Get(ctx, types.NamespacedName{Name: restoreStatus.PersistentVolumeClaim, Namespace: solrCloud.Namespace}, foundPVC)
+		if getErr == nil {
+			restoreStatus.Message = "The PersistentVolumeClaim already exists, so it cannot be restored"
+		} else if errors.IsNotFound(getErr) {
+			logger.Info("Restoring PersistentVolumeClaim from VolumeSnapshot", "persistentVolumeClaim", restoreStatus.PersistentVolumeClaim, "volumeSnapshot", restoreStatus.VolumeSnapshot)
+			if createErr := r.Create(ctx, util.GenerateRestoredDataPVC(solrCloud, restoreStatus)); createErr != nil {
+				err = createErr
+			} else {
+				restoreStatus.Restored = true
+			}
+		} else {
+			err = getErr
+		}
+	}
+
+	if allFinished, allSuccessful := util.CheckStatusOfVolumeRestores(restore); allFinished {
+		now := metav1.Now()
+		restore.Status.Finished = true
+		restore.Status.Successful = &allSuccessful
+		restore.Status.FinishTime = &now
+	}
+
+	return "", err
+}
+
+// restoreTargets describes what the SolrRestore restores, optionally only those with the given outcome
+func restoreTargets(restore *solrv1beta1.SolrRestore, successful *bool) string {
+	if len(restore.Status.VolumeRestoreStatuses) == 0 {
+		return "collections: " + strings.Join(restoredCollections(restore, successful), ", ")
+	}
+	var claims []string
+	for _, restoreStatus := range restore.Status.VolumeRestoreStatuses {
+		if successful == nil || restoreStatus.Restored == *successful {
+			claims = append(claims, restoreStatus.PersistentVolumeClaim)
+		}
+	}
+	return "PersistentVolumeClaims: " + strings.Join(claims, ", ")
+}
+
+// restoredCollections lists the target collections of the SolrRestore, optionally only those with the given outcome
+func restoredCollections(restore *solrv1beta1.SolrRestore, successful *bool) (collections []string) {
+	for _, collectionStatus := range restore.Status.CollectionRestoreStatuses {
+		if successful == nil || (collectionStatus.Successful != nil && *collectionStatus.Successful == *successful) {
+			collections = append(collections, collectionStatus.Target)
+		}
+	}
+	return collections
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SolrRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.config = mgr.GetConfig()
+
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrRestore{})
+
+	var err error
+	ctrlBuilder, err = r.indexAndWatchForField(mgr, ctrlBuilder, ".spec.solrCloud", &solrv1beta1.SolrCloud{}, func(restore *solrv1beta1.SolrRestore) string {
+		return restore.Spec.SolrCloud
+	})
+	if err != nil {
+		return err
+	}
+	ctrlBuilder, err = r.indexAndWatchForField(mgr, ctrlBuilder, ".spec.solrBackup", &solrv1beta1.SolrBackup{}, func(restore *solrv1beta1.SolrRestore) string {
+		return restore.Spec.SolrBackup
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctrlBuilder.Complete(r)
+}
+
+// Get notified when the SolrCloud or SolrBackup of a SolrRestore changes, so that restores waiting for them are started once they are ready
+func (r *SolrRestoreReconciler) indexAndWatchForField(mgr ctrl.Manager, ctrlBuilder *builder.Builder, field string, watchedType client.Object, fieldValue func(*solrv1beta1.SolrRestore) string) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrRestore{}, field, func(rawObj client.Object) []string {
+		value := fieldValue(rawObj.(*solrv1beta1.SolrRestore))
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: watchedType},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			foundRestores := &solrv1beta1.SolrRestoreList{}
+			listOps := &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(field, obj.GetName()),
+				Namespace:     obj.GetNamespace(),
+			}
+			if err := r.List(context.Background(), foundRestores, listOps); err != nil {
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, 0, len(foundRestores.Items))
+			for _, item := range foundRestores.Items {
+				// Finished restores are never run again
+				if item.Status.Finished {
+					continue
+				}
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				})
+			}
+			return requests
+		}),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = FDescribe("SolrRestore controller - General", func() {
+
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 5
+		duration = time.Second * 1
+		interval = time.Millisecond * 250
+	)
+	SetDefaultConsistentlyDuration(duration)
+	SetDefaultConsistentlyPollingInterval(interval)
+	SetDefaultEventuallyTimeout(timeout)
+	SetDefaultEventuallyPollingInterval(interval)
+
+	var (
+		ctx context.Context
+
+		solrRestore *solrv1beta1.SolrRestore
+		solrCloud   *solrv1beta1.SolrCloud
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		solrRestore = &solrv1beta1.SolrRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restore",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrRestoreSpec{
+				SolrCloud:  "foo",
+				BackupName: "nightly",
+				Collections: []solrv1beta1.SolrRestoreCollection{
+					{Name: "products"},
+				},
+			},
+		}
+
+		solrCloud = &solrv1beta1.SolrCloud{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrCloudSpec{
+				ZookeeperRef: &solrv1beta1.ZookeeperRef{
+					ConnectionInfo: &solrv1beta1.ZookeeperConnectionInfo{
+						InternalConnectionString: "host:7271",
+					},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		By("creating the SolrRestore")
+		Expect(k8sClient.Create(ctx, solrRestore)).To(Succeed())
+
+		By("defaulting the missing SolrRestore values")
+		expectSolrRestoreWithChecks(ctx, solrRestore, func(g Gomega, found *solrv1beta1.SolrRestore) {
+			g.Expect(found.WithDefaults()).To(BeFalse(), "The SolrRestore spec should not need to be defaulted eventually")
+		})
+	})
+
+	AfterEach(func() {
+		cleanupTest(ctx, solrRestore)
+	})
+
+	FContext("Backup by name", func() {
+		FIt("waits for the SolrCloud", func() {
+			foundRestore := expectSolrRestoreCondition(ctx, solrRestore, "Waiting", "Waiting for SolrCloud foo to be created")
+			Expect(foundRestore.Spec.Collections[0].Target).To(Equal("products"), "The target collection should default to the name of the collection")
+			Expect(foundRestore.Status.CollectionRestoreStatuses).To(BeEmpty(), "No collections should be restored without the SolrCloud")
+
+			By("creating the SolrCloud without a backup repository")
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+			expectSolrRestoreCondition(ctx, solrRestore, util.InvalidSpecReason, "SolrCloud foo must define the backup repository")
+		})
+	})
+
+	FContext("SolrBackup", func() {
+		BeforeEach(func() {
+			solrRestore.Spec.BackupName = ""
+			solrRestore.Spec.SolrBackup = "nightly"
+			solrRestore.Spec.Collections = nil
+		})
+		FIt("waits for the SolrBackup", func() {
+			expectSolrRestoreCondition(ctx, solrRestore, "Waiting", "Waiting for SolrBackup nightly to be created")
+		})
+	})
+
+	FContext("Repository definition from another SolrCloud", func() {
+		backupVolume := corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+		}
+		BeforeEach(func() {
+			solrRestore.Spec.Repository = &solrv1beta1.SolrBackupRepository{
+				Name: "old-cloud-backups",
+				Managed: &solrv1beta1.ManagedRepository{
+					Volume:    backupVolume,
+					Directory: "shared",
+				},
+			}
+		})
+		FIt("restores through the matching repository of the SolrCloud", func() {
+			By("creating the SolrCloud with a repository in another directory")
+			solrCloud.Spec.BackupRepositories = []solrv1beta1.SolrBackupRepository{
+				{
+					Name:    "local",
+					Managed: &solrv1beta1.ManagedRepository{Volume: backupVolume},
+				},
+			}
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+			expectSolrRestoreCondition(ctx, solrRestore, util.InvalidSpecReason, "SolrCloud foo does not define a backup repository that stores its data in the same place as the repository \"old-cloud-backups\"")
+
+			By("moving the repository of the SolrCloud to the directory of the backup")
+			// The SolrCloud controller updates the SolrCloud as well, so retry on conflicts
+			Eventually(func() error {
+				foundCloud := expectSolrCloud(ctx, solrCloud)
+				foundCloud.Spec.BackupRepositories[0].Managed.Directory = "shared"
+				return k8sClient.Update(ctx, foundCloud)
+			}).Should(Succeed(), "Update the backup repository of the SolrCloud")
+			expectSolrRestoreCondition(ctx, solrRestore, "Waiting", "Waiting for SolrCloud foo to be ready for restores")
+		})
+	})
+
+	FContext("Repository definition with a repository name", func() {
+		BeforeEach(func() {
+			solrRestore.Spec.RepositoryName = "local"
+			solrRestore.Spec.Repository = &solrv1beta1.SolrBackupRepository{
+				Name: "old-cloud-backups",
+				GCS:  &solrv1beta1.GcsRepository{Bucket: "backups"},
+			}
+		})
+		FIt("reports the invalid spec", func() {
+			expectSolrRestoreCondition(ctx, solrRestore, util.InvalidSpecReason, "repository cannot be provided with solrBackup or repositoryName")
+		})
+	})
+
+	FContext("Both SolrBackup and backup name", func() {
+		BeforeEach(func() {
+			solrRestore.Spec.SolrBackup = "nightly"
+		})
+		FIt("reports the invalid spec", func() {
+			expectSolrRestoreCondition(ctx, solrRestore, util.InvalidSpecReason, "exactly one of solrBackup and backupName must be provided")
+		})
+	})
+})
@@ -0,0 +1,270 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// Daemons are lost without notice when their Solr pod restarts, so they are checked on periodically
+const streamingDaemonCheckInterval = time.Second * 30
+
+// SolrStreamingDaemonReconciler reconciles a SolrStreamingDaemon object
+type SolrStreamingDaemonReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrstreamingdaemons,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrstreamingdaemons/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=solr.apache.org,resources=solrstreamingdaemons/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
+func (r *SolrStreamingDaemonReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the SolrStreamingDaemon instance
+	daemon := &solrv1beta1.SolrStreamingDaemon{}
+	err := r.Get(ctx, req.NamespacedName, daemon)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if _, selected, err := getSolrOperatorConfig(ctx, r.Client, daemon); err != nil || !selected {
+		// SolrStreamingDaemons that are not selected by the SolrOperatorConfig of their namespace are managed by a different Solr Operator
+		return reconcile.Result{}, reportTerminalError(r.Recorder, daemon, err, logger)
+	}
+
+	if daemon.ObjectMeta.DeletionTimestamp.IsZero() {
+		changed := daemon.WithDefaults()
+		if !util.ContainsString(daemon.ObjectMeta.Finalizers, util.SolrStreamingDaemonFinalizer) {
+			daemon.ObjectMeta.Finalizers = append(daemon.ObjectMeta.Finalizers, util.SolrStreamingDaemonFinalizer)
+			changed = true
+		}
+		if changed {
+			logger.Info("Setting default settings for solr-streaming-daemon")
+			if err = r.Update(ctx, daemon); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true}, nil
+		}
+	}
+
+	oldStatus := daemon.Status.DeepCopy()
+	requeueOrNot := reconcile.Result{RequeueAfter: streamingDaemonCheckInterval}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	var httpHeaders map[string]string
+	if err = r.Get(ctx, types.NamespacedName{Namespace: daemon.Namespace, Name: daemon.Spec.SolrCloud}, solrCloud); err != nil {
+		if !errors.IsNotFound(err) {
+			return requeueOrNot, err
+		}
+		// Without the SolrCloud, there is nothing that the daemon can run in
+		logger.Info("Could not find the cloud to run the streaming daemon in", "solrCloud", daemon.Spec.SolrCloud)
+		solrCloud = nil
+		daemon.Status.Node = ""
+		daemon.Status.Running = false
+		err = nil
+	} else if httpHeaders, err = r.solrHttpHeaders(ctx, solrCloud); err != nil {
+		// The operator cannot call SolrClouds with unsupported authentication, the daemon is reconciled again once the SolrCloud changes
+		return reconcile.Result{}, reportTerminalError(r.Recorder, daemon, err, logger)
+	}
+
+	if !daemon.ObjectMeta.DeletionTimestamp.IsZero() {
+		if util.ContainsString(daemon.ObjectMeta.Finalizers, util.SolrStreamingDaemonFinalizer) {
+			if solrCloud != nil && daemon.Status.Node != "" {
+				// The daemon is gone anyway if its pod is gone, so this is best effort
+				if killErr := util.KillStreamingDaemon(solrCloud, daemon.Status.Node, daemon, httpHeaders); killErr != nil {
+					logger.Error(killErr, "Could not kill the streaming daemon, it will stop when its Solr pod restarts", "node", daemon.Status.Node)
+				}
+			}
+			daemon.ObjectMeta.Finalizers = util.RemoveString(daemon.ObjectMeta.Finalizers, util.SolrStreamingDaemonFinalizer)
+			err = r.Update(ctx, daemon)
+		}
+		return reconcile.Result{}, err
+	}
+
+	if solrCloud != nil {
+		err = r.reconcileStreamingDaemon(daemon, solrCloud, httpHeaders, logger)
+	}
+
+	if !reflect.DeepEqual(oldStatus, &daemon.Status) {
+		logger.Info("Updating status for solr-streaming-daemon")
+		if statusErr := r.Status().Update(ctx, daemon); err == nil {
+			err = statusErr
+		}
+	}
+
+	return requeueOrNot, err
+}
+
+// reconcileStreamingDaemon makes sure that the daemon runs on a ready Solr pod, with the latest expression.
+// Daemons are resubmitted when their pod has restarted, since Solr does not persist them.
+func (r *SolrStreamingDaemonReconciler) reconcileStreamingDaemon(daemon *solrv1beta1.SolrStreamingDaemon, solrCloud *solrv1beta1.SolrCloud, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	currentNode := daemon.Status.Node
+	if daemon.Spec.Suspend {
+		if currentNode != "" && daemon.Status.Running {
+			logger.Info("Killing suspended streaming daemon", "node", currentNode)
+			if err = util.KillStreamingDaemon(solrCloud, currentNode, daemon, httpHeaders); err != nil {
+				return err
+			}
+		}
+		daemon.Status.Node = ""
+		daemon.Status.Running = false
+		return nil
+	}
+
+	node := util.SolrNodeForStreamingDaemon(solrCloud, currentNode)
+	if node == "" {
+		logger.Info("No ready Solr pods to run the streaming daemon on", "solrCloud", solrCloud.Name)
+		daemon.Status.Running = false
+		return nil
+	}
+	if currentNode != "" && node != currentNode {
+		// The daemon may still be running on its old pod, if that pod is only temporarily not ready
+		if killErr := util.KillStreamingDaemon(solrCloud, currentNode, daemon, httpHeaders); killErr != nil {
+			logger.Info("Could not kill the streaming daemon on its previous Solr pod", "node", currentNode, "error", killErr.Error())
+		}
+	}
+
+	running := false
+	if node == currentNode {
+		if running, daemon.Status.Iterations, err = util.GetStreamingDaemonState(solrCloud, node, daemon, httpHeaders); err != nil {
+			return err
+		}
+	}
+
+	expressionHash := util.HashContent([]byte(daemon.Spec.Collection + "\n" + util.StreamingDaemonExpression(daemon)))
+	if !running || daemon.Status.ExpressionHash != expressionHash {
+		logger.Info("Submitting streaming daemon", "node", node, "collection", daemon.Spec.Collection)
+		if err = util.StartStreamingDaemon(solrCloud, node, daemon, httpHeaders); err != nil {
+			daemon.Status.Running = false
+			return err
+		}
+		now := metav1.Now()
+		daemon.Status.LastSubmitTime = &now
+		daemon.Status.Submissions += 1
+		daemon.Status.ExpressionHash = expressionHash
+		daemon.Status.Iterations = 0
+	}
+	daemon.Status.Node = node
+	daemon.Status.Running = true
+	return nil
+}
+
+func (r *SolrStreamingDaemonReconciler) solrHttpHeaders(ctx context.Context, solrCloud *solrv1beta1.SolrCloud) (map[string]string, error) {
+	if err := util.ValidateOperatorCanCallSolr(solrCloud); err != nil {
+		return nil, err
+	}
+	if !solrCloud.UsesBasicAuth() {
+		return nil, nil
+	}
+	basicAuthSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+		return nil, err
+	}
+	return map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SolrStreamingDaemonReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrStreamingDaemon{})
+
+	var err error
+	ctrlBuilder, err = r.indexAndWatchForSolrClouds(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	return ctrlBuilder.Complete(r)
+}
+
+// Get notified when the SolrCloud changes, so that daemons are resubmitted soon after their Solr pods restart
+func (r *SolrStreamingDaemonReconciler) indexAndWatchForSolrClouds(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	solrCloudField := ".spec.solrCloud"
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &solrv1beta1.SolrStreamingDaemon{}, solrCloudField, func(rawObj client.Object) []string {
+		daemon := rawObj.(*solrv1beta1.SolrStreamingDaemon)
+		return []string{daemon.Spec.SolrCloud}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrCloud{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			foundDaemons := &solrv1beta1.SolrStreamingDaemonList{}
+			listOps := &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(solrCloudField, obj.GetName()),
+				Namespace:     obj.GetNamespace(),
+			}
+			if err := r.List(context.Background(), foundDaemons, listOps); err != nil {
+				return []reconcile.Request{}
+			}
+
+			requests := make([]reconcile.Request, len(foundDaemons.Items))
+			for i, item := range foundDaemons.Items {
+				requests[i] = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				}
+			}
+			return requests
+		}),
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
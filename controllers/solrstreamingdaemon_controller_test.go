@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = FDescribe("SolrStreamingDaemon controller - General", func() {
+
+	// Define utility constants for object names and testing timeouts/durations and intervals.
+	const (
+		timeout  = time.Second * 5
+		duration = time.Second * 1
+		interval = time.Millisecond * 250
+	)
+	SetDefaultConsistentlyDuration(duration)
+	SetDefaultConsistentlyPollingInterval(interval)
+	SetDefaultEventuallyTimeout(timeout)
+	SetDefaultEventuallyPollingInterval(interval)
+
+	var (
+		ctx context.Context
+
+		solrStreamingDaemon *solrv1beta1.SolrStreamingDaemon
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		solrStreamingDaemon = &solrv1beta1.SolrStreamingDaemon{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: solrv1beta1.SolrStreamingDaemonSpec{
+				SolrCloud:  "foo",
+				Collection: "products",
+				Expression: "commit(products, update(products, topic(checkpoints, incoming, q=\"*:*\", fl=\"id\", id=\"foo\")))",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		By("creating the SolrStreamingDaemon")
+		Expect(k8sClient.Create(ctx, solrStreamingDaemon)).To(Succeed())
+
+		By("defaulting the missing SolrStreamingDaemon values")
+		expectSolrStreamingDaemonWithChecks(ctx, solrStreamingDaemon, func(g Gomega, found *solrv1beta1.SolrStreamingDaemon) {
+			g.Expect(found.WithDefaults()).To(BeFalse(), "The SolrStreamingDaemon spec should not need to be defaulted eventually")
+			g.Expect(found.Finalizers).To(ContainElement(util.SolrStreamingDaemonFinalizer), "The SolrStreamingDaemon should have a finalizer to kill the daemon")
+		})
+	})
+
+	AfterEach(func() {
+		cleanupTest(ctx, solrStreamingDaemon)
+	})
+
+	FContext("Missing SolrCloud", func() {
+		FIt("is not running and can be deleted", func() {
+			expectSolrStreamingDaemonWithConsistentChecks(ctx, solrStreamingDaemon, func(g Gomega, found *solrv1beta1.SolrStreamingDaemon) {
+				g.Expect(found.Status.Running).To(BeFalse(), "The daemon cannot run without a SolrCloud")
+				g.Expect(found.Status.Node).To(BeEmpty(), "The daemon cannot have a node without a SolrCloud")
+				g.Expect(found.Status.Submissions).To(BeZero(), "The daemon should not be submitted without a SolrCloud")
+			})
+
+			By("deleting the SolrStreamingDaemon")
+			Expect(k8sClient.Delete(ctx, solrStreamingDaemon)).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, resourceKey(solrStreamingDaemon, solrStreamingDaemon.Name), &solrv1beta1.SolrStreamingDaemon{}))
+			}).Should(BeTrue(), "The finalizer should be removed so that the SolrStreamingDaemon is deleted")
+		})
+	})
+
+	FContext("SolrCloud without ready pods", func() {
+		var solrCloud *solrv1beta1.SolrCloud
+		BeforeEach(func() {
+			solrCloud = &solrv1beta1.SolrCloud{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Spec: solrv1beta1.SolrCloudSpec{
+					ZookeeperRef: &solrv1beta1.ZookeeperRef{
+						ConnectionInfo: &solrv1beta1.ZookeeperConnectionInfo{
+							InternalConnectionString: "host:7271",
+						},
+					},
+				},
+			}
+			By("creating the SolrCloud that the daemon runs in")
+			Expect(k8sClient.Create(ctx, solrCloud)).To(Succeed())
+		})
+		FIt("waits for a ready Solr pod", func() {
+			expectSolrStreamingDaemonWithConsistentChecks(ctx, solrStreamingDaemon, func(g Gomega, found *solrv1beta1.SolrStreamingDaemon) {
+				g.Expect(found.Status.Running).To(BeFalse(), "The daemon cannot run without a ready Solr pod")
+				g.Expect(found.Status.Node).To(BeEmpty(), "The daemon cannot be placed without a ready Solr pod")
+				g.Expect(found.Status.Submissions).To(BeZero(), "The daemon should not be submitted without a ready Solr pod")
+			})
+
+			By("suspending the SolrStreamingDaemon")
+			foundDaemon := expectSolrStreamingDaemon(ctx, solrStreamingDaemon)
+			foundDaemon.Spec.Suspend = true
+			Expect(k8sClient.Update(ctx, foundDaemon)).To(Succeed())
+			expectSolrStreamingDaemonWithConsistentChecks(ctx, solrStreamingDaemon, func(g Gomega, found *solrv1beta1.SolrStreamingDaemon) {
+				g.Expect(found.Status.Running).To(BeFalse(), "A suspended daemon should not run")
+			})
+		})
+	})
+})
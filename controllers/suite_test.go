@@ -96,18 +96,51 @@ var _ = BeforeSuite(func() {
 	// Start up Reconcilers
 	By("starting the reconcilers")
 	Expect((&SolrCloudReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrcloud-controller"),
 	}).SetupWithManager(k8sManager)).To(Succeed())
 
 	Expect((&SolrPrometheusExporterReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrprometheusexporter-controller"),
 	}).SetupWithManager(k8sManager)).To(Succeed())
 
 	Expect((&SolrBackupReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrbackup-controller"),
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&SolrIndexingBridgeReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrindexingbridge-controller"),
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&SolrStreamingDaemonReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrstreamingdaemon-controller"),
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&SolrConfigSetReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrconfigset-controller"),
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&SolrRestoreReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrrestore-controller"),
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&SolrMigrationReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("solrmigration-controller"),
 	}).SetupWithManager(k8sManager)).To(Succeed())
 
 	go func() {
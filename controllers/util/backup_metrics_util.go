@@ -0,0 +1,183 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"time"
+)
+
+const (
+	// VolumeSnapshotMetricsRepository is the repository label of the metrics of SolrBackups taken as VolumeSnapshots
+	VolumeSnapshotMetricsRepository = "volumeSnapshot"
+)
+
+var (
+	backupMetricLabels = []string{"namespace", "solrbackup", "repository"}
+
+	solrBackupDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "solr_backup_duration_seconds",
+			Help:    "The time taken by finished SolrBackups, from the start of the first collection backup until the backup finished.",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+		},
+		backupMetricLabels,
+	)
+	solrBackupLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_backup_last_success_timestamp",
+			Help: "The unix time, in seconds, that the last successful SolrBackup finished.",
+		},
+		backupMetricLabels,
+	)
+	solrBackupFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "solr_backup_failures_total",
+			Help: "The number of SolrBackups that have failed.",
+		},
+		backupMetricLabels,
+	)
+
+	// exportedBackups keeps track of the repositories that metrics have been recorded for, by SolrBackup,
+	// so that the metrics of deleted SolrBackups can be removed
+	exportedBackups = newMetricSeries(deleteBackupMetrics)
+)
+
+func init() {
+	// The controller-runtime registry is served on the operator's metrics endpoint
+	metrics.Registry.MustRegister(solrBackupDuration, solrBackupLastSuccess, solrBackupFailures)
+}
+
+// RecordSolrBackupMetrics records the outcome of a finished SolrBackup, for its main repository and each additional repository.
+func RecordSolrBackupMetrics(backup *solr.SolrBackup) {
+	if !backup.Status.Finished || backup.Status.FinishTime == nil {
+		return
+	}
+
+	if backup.Spec.VolumeSnapshot != nil {
+		recordVolumeSnapshotBackupMetrics(backup)
+		exportedBackups.refreshed(backup.Namespace, backup.Name, map[string]bool{VolumeSnapshotMetricsRepository: true})
+		return
+	}
+
+	repositories := make(map[string]bool)
+	for _, repositoryStatus := range finishedRepositoryBackups(backup) {
+		recordRepositoryBackupMetrics(backup, repositoryStatus)
+		repositories[repositoryStatus.Repository] = true
+	}
+	exportedBackups.refreshed(backup.Namespace, backup.Name, repositories)
+}
+
+// SeedSolrBackupMetrics sets the time of the last success of a finished SolrBackup from its status, if no metrics have been recorded for it yet,
+// such as after the Solr Operator restarts. The durations and failures of earlier backups cannot be recovered from the status.
+func SeedSolrBackupMetrics(backup *solr.SolrBackup) {
+	if !backup.Status.Finished || backup.Status.FinishTime == nil || exportedBackups.tracked(backup.Namespace, backup.Name) {
+		return
+	}
+	finishTime := float64(backup.Status.FinishTime.Unix())
+
+	repositories := make(map[string]bool)
+	if backup.Spec.VolumeSnapshot != nil {
+		if backup.Status.Successful != nil && *backup.Status.Successful {
+			solrBackupLastSuccess.With(prometheus.Labels{"namespace": backup.Namespace, "solrbackup": backup.Name, "repository": VolumeSnapshotMetricsRepository}).Set(finishTime)
+			repositories[VolumeSnapshotMetricsRepository] = true
+		}
+	} else {
+		for _, repositoryStatus := range finishedRepositoryBackups(backup) {
+			if repositoryStatus.Successful != nil && *repositoryStatus.Successful {
+				solrBackupLastSuccess.With(prometheus.Labels{"namespace": backup.Namespace, "solrbackup": backup.Name, "repository": repositoryStatus.Repository}).Set(finishTime)
+				repositories[repositoryStatus.Repository] = true
+			}
+		}
+	}
+	exportedBackups.refreshed(backup.Namespace, backup.Name, repositories)
+}
+
+// RemoveSolrBackupMetrics removes the metrics of a SolrBackup, for SolrBackups that are deleted
+func RemoveSolrBackupMetrics(namespace string, backupName string) {
+	exportedBackups.remove(namespace, backupName)
+}
+
+func deleteBackupMetrics(namespace string, backupName string, repository string) {
+	labels := prometheus.Labels{"namespace": namespace, "solrbackup": backupName, "repository": repository}
+	solrBackupDuration.Delete(labels)
+	solrBackupLastSuccess.Delete(labels)
+	solrBackupFailures.Delete(labels)
+}
+
+// finishedRepositoryBackups returns the status of the backup to the main repository, and to each additional repository, of a finished SolrBackup
+func finishedRepositoryBackups(backup *solr.SolrBackup) []solr.RepositoryBackupStatus {
+	// The main repository's backup only succeeded if every collection was backed up, and persisted if persistence is configured
+	mainRepositoryStatus := &solr.RepositoryBackupStatus{
+		Repository:               backup.Spec.RepositoryName,
+		CollectionBackupStatuses: backup.Status.CollectionBackupStatuses,
+	}
+	CheckStatusOfRepositoryBackup(mainRepositoryStatus)
+	if backup.Spec.Persistence != nil && (backup.Status.PersistenceStatus.Successful == nil || !*backup.Status.PersistenceStatus.Successful) {
+		fals := false
+		mainRepositoryStatus.Successful = &fals
+	}
+	return append([]solr.RepositoryBackupStatus{*mainRepositoryStatus}, backup.Status.AdditionalRepositoryStatuses...)
+}
+
+func recordRepositoryBackupMetrics(backup *solr.SolrBackup, repositoryStatus solr.RepositoryBackupStatus) {
+	labels := prometheus.Labels{"namespace": backup.Namespace, "solrbackup": backup.Name, "repository": repositoryStatus.Repository}
+	finishTime := backup.Status.FinishTime.Time
+
+	// The duration is measured from the start of the first collection backup to the repository
+	var startTime *time.Time
+	for _, collectionStatus := range repositoryStatus.CollectionBackupStatuses {
+		if collectionStatus.StartTime != nil && (startTime == nil || collectionStatus.StartTime.Time.Before(*startTime)) {
+			startTime = &collectionStatus.StartTime.Time
+		}
+	}
+	if startTime != nil {
+		solrBackupDuration.With(labels).Observe(finishTime.Sub(*startTime).Seconds())
+	}
+
+	if repositoryStatus.Successful != nil && *repositoryStatus.Successful {
+		solrBackupLastSuccess.With(labels).Set(float64(finishTime.Unix()))
+	} else {
+		solrBackupFailures.With(labels).Inc()
+	}
+}
+
+// recordVolumeSnapshotBackupMetrics records the outcome of a SolrBackup taken as VolumeSnapshots, which is labeled with the "volumeSnapshot" repository.
+// The duration is measured from when the first VolumeSnapshot was taken, since the collections are read-only until then.
+func recordVolumeSnapshotBackupMetrics(backup *solr.SolrBackup) {
+	labels := prometheus.Labels{"namespace": backup.Namespace, "solrbackup": backup.Name, "repository": VolumeSnapshotMetricsRepository}
+	finishTime := backup.Status.FinishTime.Time
+
+	var startTime *time.Time
+	for _, snapshotStatus := range backup.Status.VolumeSnapshots {
+		if snapshotStatus.CreationTime != nil && (startTime == nil || snapshotStatus.CreationTime.Time.Before(*startTime)) {
+			startTime = &snapshotStatus.CreationTime.Time
+		}
+	}
+	if startTime != nil {
+		solrBackupDuration.With(labels).Observe(finishTime.Sub(*startTime).Seconds())
+	}
+
+	if backup.Status.Successful != nil && *backup.Status.Successful {
+		solrBackupLastSuccess.With(labels).Set(float64(finishTime.Unix()))
+	} else {
+		solrBackupFailures.With(labels).Inc()
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestRecordSolrBackupMetrics(t *testing.T) {
+	tru := true
+	fals := false
+	startTime := metav1.NewTime(time.Unix(1000, 0))
+	finishTime := metav1.NewTime(time.Unix(1600, 0))
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "metricsbackup", Namespace: "metricsnamespace"},
+		Spec: solr.SolrBackupSpec{
+			RepositoryName:            "repo1",
+			AdditionalRepositoryNames: []string{"repo2"},
+		},
+		Status: solr.SolrBackupStatus{
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", Finished: true, Successful: &tru, StartTime: &startTime},
+			},
+			AdditionalRepositoryStatuses: []solr.RepositoryBackupStatus{
+				{
+					Repository:               "repo2",
+					CollectionBackupStatuses: []solr.CollectionBackupStatus{{Collection: "col1", Finished: true, Successful: &fals, StartTime: &startTime}},
+					Finished:                 true,
+					Successful:               &fals,
+				},
+			},
+			Finished:   false,
+			FinishTime: &finishTime,
+			Successful: &fals,
+		},
+	}
+
+	RecordSolrBackupMetrics(backup)
+	assert.Equal(t, 0.0, testutil.ToFloat64(solrBackupFailures.WithLabelValues("metricsnamespace", "metricsbackup", "repo2")), "No metrics should be recorded for unfinished backups")
+
+	backup.Status.Finished = true
+	RecordSolrBackupMetrics(backup)
+	assert.Equal(t, float64(1600), testutil.ToFloat64(solrBackupLastSuccess.WithLabelValues("metricsnamespace", "metricsbackup", "repo1")), "The main repository backup succeeded")
+	assert.Equal(t, 0.0, testutil.ToFloat64(solrBackupFailures.WithLabelValues("metricsnamespace", "metricsbackup", "repo1")), "The main repository backup did not fail")
+	assert.Equal(t, 1.0, testutil.ToFloat64(solrBackupFailures.WithLabelValues("metricsnamespace", "metricsbackup", "repo2")), "The additional repository backup failed")
+	assert.Equal(t, 2, testutil.CollectAndCount(solrBackupDuration), "A duration should be recorded for each repository")
+
+	backup.Spec.Persistence = &solr.PersistenceSource{}
+	backup.Status.PersistenceStatus.Successful = &fals
+	RecordSolrBackupMetrics(backup)
+	assert.Equal(t, 1.0, testutil.ToFloat64(solrBackupFailures.WithLabelValues("metricsnamespace", "metricsbackup", "repo1")), "The main repository backup fails if it could not be persisted")
+}
+
+func TestSeedAndRemoveSolrBackupMetrics(t *testing.T) {
+	tru := true
+	fals := false
+	startTime := metav1.NewTime(time.Unix(1000, 0))
+	finishTime := metav1.NewTime(time.Unix(2400, 0))
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "seededbackup", Namespace: "metricsnamespace"},
+		Spec: solr.SolrBackupSpec{
+			RepositoryName:            "repo1",
+			AdditionalRepositoryNames: []string{"repo2"},
+		},
+		Status: solr.SolrBackupStatus{
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", Finished: true, Successful: &tru, StartTime: &startTime},
+			},
+			AdditionalRepositoryStatuses: []solr.RepositoryBackupStatus{
+				{
+					Repository:               "repo2",
+					CollectionBackupStatuses: []solr.CollectionBackupStatus{{Collection: "col1", Finished: true, Successful: &fals, StartTime: &startTime}},
+					Finished:                 true,
+					Successful:               &fals,
+				},
+			},
+			Finished:   true,
+			FinishTime: &finishTime,
+			Successful: &fals,
+		},
+	}
+
+	SeedSolrBackupMetrics(backup)
+	assert.Equal(t, float64(2400), testutil.ToFloat64(solrBackupLastSuccess.WithLabelValues("metricsnamespace", "seededbackup", "repo1")), "The last success should be seeded from the status")
+	assert.Equal(t, 0.0, testutil.ToFloat64(solrBackupFailures.WithLabelValues("metricsnamespace", "seededbackup", "repo2")), "Failures cannot be seeded from the status")
+
+	// Seeding only happens for backups that have no metrics yet
+	laterFinishTime := metav1.NewTime(time.Unix(3000, 0))
+	backup.Status.FinishTime = &laterFinishTime
+	SeedSolrBackupMetrics(backup)
+	assert.Equal(t, float64(2400), testutil.ToFloat64(solrBackupLastSuccess.WithLabelValues("metricsnamespace", "seededbackup", "repo1")), "Metrics should only be seeded once")
+
+	RecordSolrBackupMetrics(backup)
+	assert.Equal(t, float64(3000), testutil.ToFloat64(solrBackupLastSuccess.WithLabelValues("metricsnamespace", "seededbackup", "repo1")), "The recorded backup should update the last success")
+
+	RemoveSolrBackupMetrics("metricsnamespace", "seededbackup")
+	seriesLabels := prometheus.Labels{"namespace": "metricsnamespace", "solrbackup": "seededbackup", "repository": "repo2"}
+	assert.False(t, solrBackupFailures.Delete(seriesLabels), "The metrics of the deleted backup should be removed")
+	seriesLabels["repository"] = "repo1"
+	assert.False(t, solrBackupLastSuccess.Delete(seriesLabels), "The metrics of the deleted backup should be removed")
+}
@@ -18,19 +18,29 @@
 package util
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers/util/solr_api"
 	"github.com/go-logr/logr"
+	cron "github.com/robfig/cron/v3"
+	"io"
+	"io/ioutil"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -40,7 +50,18 @@ const (
 
 	AWSSecretDir = "/var/aws"
 
+	// ClusterStateBackupDirectory is the directory, within the backup location in a managed repository, that the cluster state of the SolrCloud is exported to
+	ClusterStateBackupDirectory = "cluster-state"
+
+	// The znodes, relative to the chroot of a SolrCloud, that hold the cluster-wide state that is exported alongside backups
+	AliasesZnode      = "/aliases.json"
+	ClusterPropsZnode = "/clusterprops.json"
+	SecurityJsonZnode = "/security.json"
+
 	JobTTLSeconds = int32(60)
+
+	// SolrBackupsFinalizer makes sure that a SolrCloud is not deleted while SolrBackups or SolrRestores of it are in progress
+	SolrBackupsFinalizer = "backups.finalizers.solr.apache.org"
 )
 
 func GetBackupRepositoryByName(backupRepos []solr.SolrBackupRepository, repositoryName string) *solr.SolrBackupRepository {
@@ -57,6 +78,231 @@ func GetBackupRepositoryByName(backupRepos []solr.SolrBackupRepository, reposito
 	return nil
 }
 
+// IsBackupInProgress returns whether the SolrBackup has been started, but has not yet finished.
+// Backups that have not been started yet do not depend on the SolrCloud or its backup repository.
+func IsBackupInProgress(backup *solr.SolrBackup) bool {
+	return backup.Status.SolrVersion != "" && !backup.Status.Finished
+}
+
+// InProgressBackupsForCloud returns the names of the given SolrBackups that are in progress for the SolrCloud,
+// as well as the names of the backup repositories that these backups use, but that the SolrCloud no longer defines.
+func InProgressBackupsForCloud(cloud *solr.SolrCloud, backups []solr.SolrBackup) (inProgressBackups []string, removedRepositories []string) {
+	removed := map[string]bool{}
+	for i := range backups {
+		backup := &backups[i]
+		if backup.Spec.SolrCloud != cloud.Name || !IsBackupInProgress(backup) {
+			continue
+		}
+		inProgressBackups = append(inProgressBackups, backup.Name)
+		if backup.Spec.VolumeSnapshot != nil {
+			// Backups taken as VolumeSnapshots do not use the backup repositories
+			continue
+		}
+		for _, repositoryName := range append([]string{backup.Spec.RepositoryName}, backup.Spec.AdditionalRepositoryNames...) {
+			if GetBackupRepositoryByName(cloud.Spec.BackupRepositories, repositoryName) == nil && !removed[repositoryName] {
+				removed[repositoryName] = true
+				removedRepositories = append(removedRepositories, repositoryName)
+			}
+		}
+	}
+	sort.Strings(inProgressBackups)
+	sort.Strings(removedRepositories)
+	return inProgressBackups, removedRepositories
+}
+
+// IsRestoreInProgress returns whether the SolrRestore has been started, but has not yet finished.
+func IsRestoreInProgress(restore *solr.SolrRestore) bool {
+	return restore.Status.StartTime != nil && !restore.Status.Finished
+}
+
+// InProgressRestoresForCloud returns the names of the given SolrRestores that are in progress for the SolrCloud,
+// as well as the names of the backup repositories that these restores read from, but that the SolrCloud no longer defines.
+// The SolrBackups are used to find the repositories of restores of SolrBackups.
+func InProgressRestoresForCloud(cloud *solr.SolrCloud, restores []solr.SolrRestore, backups []solr.SolrBackup) (inProgressRestores []string, removedRepositories []string) {
+	removed := map[string]bool{}
+	for i := range restores {
+		restore := &restores[i]
+		if restore.Spec.SolrCloud != cloud.Name || !IsRestoreInProgress(restore) {
+			continue
+		}
+		inProgressRestores = append(inProgressRestores, restore.Name)
+
+		repositoryName := restore.Spec.RepositoryName
+		if restore.Spec.Repository != nil {
+			if _, err := BackupRepositoryForRestore(cloud, restore.Spec.Repository); err == nil {
+				continue
+			}
+			repositoryName = restore.Spec.Repository.Name
+		} else if restore.Spec.SolrBackup != "" {
+			for j := range backups {
+				if backups[j].Name == restore.Spec.SolrBackup {
+					repositoryName = backups[j].Spec.RepositoryName
+					if backups[j].Spec.VolumeSnapshot != nil {
+						// Backups taken as VolumeSnapshots are restored without the backup repositories
+						repositoryName = ""
+					}
+				}
+			}
+			if repositoryName == "" {
+				continue
+			}
+		}
+		if GetBackupRepositoryByName(cloud.Spec.BackupRepositories, repositoryName) == nil && !removed[repositoryName] {
+			removed[repositoryName] = true
+			removedRepositories = append(removedRepositories, repositoryName)
+		}
+	}
+	sort.Strings(inProgressRestores)
+	sort.Strings(removedRepositories)
+	return inProgressRestores, removedRepositories
+}
+
+// ValidateAdditionalRepositories checks that the additional repositories of a SolrBackup can be told apart from each other,
+// and from its main repository. Invalid additional repositories are a terminal error, since they require the SolrBackup to be changed.
+func ValidateAdditionalRepositories(backup *solr.SolrBackup) error {
+	if len(backup.Spec.AdditionalRepositoryNames) == 0 {
+		return nil
+	}
+	if backup.Spec.RepositoryName == "" {
+		return TerminalErrorf(InvalidSpecReason, "repositoryName must be provided when backing up to additional repositories")
+	}
+	repositories := map[string]bool{backup.Spec.RepositoryName: true}
+	for _, repositoryName := range backup.Spec.AdditionalRepositoryNames {
+		if repositories[repositoryName] {
+			return TerminalErrorf(InvalidSpecReason, "the backup repository %q is used more than once", repositoryName)
+		}
+		repositories[repositoryName] = true
+	}
+	return nil
+}
+
+// ValidateClusterStateExport returns an error if the options to export the cluster state of the SolrBackup are inconsistent
+func ValidateClusterStateExport(backup *solr.SolrBackup) error {
+	if backup.Spec.IncludeSecurityJson && !backup.Spec.IncludeClusterState {
+		return TerminalErrorf(InvalidSpecReason, "includeSecurityJson requires includeClusterState")
+	}
+	return nil
+}
+
+// BackupAsyncName returns the name that the async requests for the collection backups of a SolrBackup, to the given repository, are tracked under.
+// Backups to additional repositories run alongside the backups to the main repository, so they need their own async ids.
+func BackupAsyncName(backup *solr.SolrBackup, repositoryName string) string {
+	for _, additionalRepositoryName := range backup.Spec.AdditionalRepositoryNames {
+		if additionalRepositoryName == repositoryName {
+			return backup.Name + "-" + repositoryName
+		}
+	}
+	return backup.Name
+}
+
+// AdditionalRepositoryBackupStatus returns the status of the backups to an additional repository, adding it if it does not exist yet
+func AdditionalRepositoryBackupStatus(backup *solr.SolrBackup, repositoryName string) *solr.RepositoryBackupStatus {
+	for i := range backup.Status.AdditionalRepositoryStatuses {
+		if backup.Status.AdditionalRepositoryStatuses[i].Repository == repositoryName {
+			return &backup.Status.AdditionalRepositoryStatuses[i]
+		}
+	}
+	backup.Status.AdditionalRepositoryStatuses = append(backup.Status.AdditionalRepositoryStatuses, solr.RepositoryBackupStatus{Repository: repositoryName})
+	return &backup.Status.AdditionalRepositoryStatuses[len(backup.Status.AdditionalRepositoryStatuses)-1]
+}
+
+// CheckStatusOfRepositoryBackup marks the backups to an additional repository as finished once every collection backup has finished.
+// The backups to the repository are only successful if every collection was successfully backed up.
+func CheckStatusOfRepositoryBackup(repositoryStatus *solr.RepositoryBackupStatus) {
+	finished := len(repositoryStatus.CollectionBackupStatuses) > 0
+	successful := true
+	for _, collectionStatus := range repositoryStatus.CollectionBackupStatuses {
+		finished = finished && collectionStatus.Finished
+		successful = successful && collectionStatus.Successful != nil && *collectionStatus.Successful
+	}
+	repositoryStatus.Finished = finished
+	if finished && repositoryStatus.Successful == nil {
+		repositoryStatus.Successful = &successful
+	}
+}
+
+// AdditionalRepositoryBackupsSuccessful returns whether the backups to every additional repository of a SolrBackup were successful
+func AdditionalRepositoryBackupsSuccessful(backup *solr.SolrBackup) bool {
+	for _, repositoryStatus := range backup.Status.AdditionalRepositoryStatuses {
+		if repositoryStatus.Successful == nil || !*repositoryStatus.Successful {
+			return false
+		}
+	}
+	return true
+}
+
+// NextScheduledBackupTime returns the time of the next backup of a recurring SolrBackup, after the given time.
+// An invalid schedule is a terminal error, since the SolrBackup cannot be scheduled until its spec is changed.
+func NextScheduledBackupTime(recurrence *solr.BackupRecurrence, after time.Time) (next time.Time, err error) {
+	schedule, err := cron.ParseStandard(recurrence.Schedule)
+	if err != nil {
+		return next, TerminalErrorf(InvalidSpecReason, "invalid recurrence schedule %q: %s", recurrence.Schedule, err)
+	}
+	return schedule.Next(after), nil
+}
+
+// ResetBackupForRecurrence clears the status of the last backup of a recurring SolrBackup, so that the next backup can be started.
+// The retained backups are kept, since they still exist in the backup repository.
+func ResetBackupForRecurrence(backup *solr.SolrBackup) {
+	meta.RemoveStatusCondition(&backup.Status.Conditions, solr.SolrBackupVerified)
+	meta.RemoveStatusCondition(&backup.Status.Conditions, solr.SolrBackupClusterStateExported)
+	backup.Status.SolrVersion = ""
+	backup.Status.Collections = nil
+	backup.Status.CollectionBackupStatuses = nil
+	backup.Status.AdditionalRepositoryStatuses = nil
+	backup.Status.PersistenceStatus = solr.BackupPersistenceStatus{}
+	backup.Status.FinishTime = nil
+	backup.Status.Successful = nil
+	backup.Status.Finished = false
+	backup.Status.NextScheduledTime = nil
+}
+
+// SetRetainedBackupsForCollection records the ids of the backups of a collection that are retained in the backup repository
+func SetRetainedBackupsForCollection(backup *solr.SolrBackup, collection string, backupIds []int32) {
+	for i := range backup.Status.RetainedBackups {
+		if backup.Status.RetainedBackups[i].Collection == collection {
+			backup.Status.RetainedBackups[i].BackupIds = backupIds
+			return
+		}
+	}
+	backup.Status.RetainedBackups = append(backup.Status.RetainedBackups, solr.RetainedCollectionBackups{
+		Collection: collection,
+		BackupIds:  backupIds,
+	})
+}
+
+// MatchCollectionsForBackup resolves the collections of a SolrBackup, which may be glob patterns, against the names of the collections in the cluster.
+// Collections that are not patterns are always included, so that a backup of a missing collection fails visibly.
+// If no collections are given, every collection in the cluster is backed up. The collections are returned sorted by name.
+func MatchCollectionsForBackup(patterns []string, clusterCollections []string) (collections []string, err error) {
+	matched := map[string]bool{}
+	for _, collection := range clusterCollections {
+		if len(patterns) == 0 {
+			matched[collection] = true
+		}
+	}
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[\\") {
+			matched[pattern] = true
+			continue
+		}
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, TerminalErrorf(InvalidSpecReason, "invalid collection pattern %q: %s", pattern, err)
+		}
+		for _, collection := range clusterCollections {
+			if isMatch, _ := path.Match(pattern, collection); isMatch {
+				matched[collection] = true
+			}
+		}
+	}
+
+	for collection := range matched {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
 func AsyncIdForCollectionBackup(collection string, backupName string) string {
 	return fmt.Sprintf("%s-%s", backupName, collection)
 }
@@ -74,6 +320,10 @@ func CheckStatusOfCollectionBackups(backup *solr.SolrBackup) (allFinished bool)
 		allFinished = allFinished && collectionStatus.Finished
 		anySuccessful = anySuccessful || (collectionStatus.Successful != nil && *collectionStatus.Successful)
 	}
+	// The backup is not finished while the backups to additional repositories are still in progress
+	for _, repositoryStatus := range backup.Status.AdditionalRepositoryStatuses {
+		allFinished = allFinished && repositoryStatus.Finished
+	}
 	if allFinished && !anySuccessful {
 		backup.Status.Finished = true
 		if backup.Status.Successful == nil {
@@ -83,6 +333,31 @@ func CheckStatusOfCollectionBackups(backup *solr.SolrBackup) (allFinished bool)
 	return
 }
 
+// CollectionBackupsAllowedToStart returns how many more collection backups the SolrBackup may start, given its throttling options.
+// Collection backups in progress to any of its backup repositories count towards the limit. A negative number means that there is no limit.
+func CollectionBackupsAllowedToStart(backup *solr.SolrBackup) int {
+	if backup.Spec.Throttling == nil || backup.Spec.Throttling.MaxConcurrentCollectionBackups == nil {
+		return -1
+	}
+	allowed := int(*backup.Spec.Throttling.MaxConcurrentCollectionBackups)
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		if collectionStatus.InProgress {
+			allowed--
+		}
+	}
+	for _, repositoryStatus := range backup.Status.AdditionalRepositoryStatuses {
+		for _, collectionStatus := range repositoryStatus.CollectionBackupStatuses {
+			if collectionStatus.InProgress {
+				allowed--
+			}
+		}
+	}
+	if allowed < 0 {
+		return 0
+	}
+	return allowed
+}
+
 func GenerateBackupPersistenceJobForCloud(managedBackupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, solrCloud *solr.SolrCloud) *batchv1.Job {
 	backupVolume, _ := RepoVolumeSourceAndMount(managedBackupRepository, solrCloud.Name)
 	solrCloudBackupDirectoryOverride := managedBackupRepository.Managed.Directory
@@ -156,7 +431,9 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 						RunAsGroup: &solrGroup,
 						FSGroup:    &solrGroup,
 					},
-					RestartPolicy: corev1.RestartPolicyNever,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ImagePullSecrets:   withImagePullSecrets(nil, &image),
+					ServiceAccountName: solrBackup.PersistenceServiceAccountName(),
 				},
 			},
 		},
@@ -164,6 +441,22 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 	return job
 }
 
+// GeneratePersistenceServiceAccount creates the ServiceAccount that the persistence Job of a SolrBackup runs as,
+// if the SolrBackup does not use an existing ServiceAccount. The annotations give the Job its identity, such as an IAM role with IRSA.
+func GeneratePersistenceServiceAccount(solrBackup *solr.SolrBackup) *corev1.ServiceAccount {
+	if solrBackup.PersistenceServiceAccountName() == "" || solrBackup.Spec.Persistence.S3.ServiceAccount.Name != "" {
+		return nil
+	}
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        solrBackup.PersistenceServiceAccountName(),
+			Namespace:   solrBackup.GetNamespace(),
+			Labels:      solrBackup.SharedLabelsWith(solrBackup.GetLabels()),
+			Annotations: solrBackup.Spec.Persistence.S3.ServiceAccount.Annotations,
+		},
+	}
+}
+
 // GeneratePersistenceOptions creates options for a Job that will persist backup data
 func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume *corev1.VolumeSource) (image solr.ContainerImage, envVars []corev1.EnvVar, command []string, volume *corev1.Volume, volumeMount *corev1.VolumeMount, numRetries *int32) {
 	// 'Persistence' expected to be non-nil
@@ -223,72 +516,75 @@ func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume *c
 				Value: s3.Region,
 			})
 		}
-		if s3.Secrets.AccessKeyId != "" {
-			envVars = append(envVars, corev1.EnvVar{
-				Name: "AWS_ACCESS_KEY_ID",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: s3.Secrets.Name,
+		// Without secrets, the AWS CLI authenticates through the identity of the Job's ServiceAccount, so no credentials are passed to it
+		if s3.Secrets != nil {
+			if s3.Secrets.AccessKeyId != "" {
+				envVars = append(envVars, corev1.EnvVar{
+					Name: "AWS_ACCESS_KEY_ID",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: s3.Secrets.Name,
+							},
+							Key: s3.Secrets.AccessKeyId,
 						},
-						Key: s3.Secrets.AccessKeyId,
 					},
-				},
-			})
-		}
-		if s3.Secrets.SecretAccessKey != "" {
-			envVars = append(envVars, corev1.EnvVar{
-				Name: "AWS_SECRET_ACCESS_KEY",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: s3.Secrets.Name,
+				})
+			}
+			if s3.Secrets.SecretAccessKey != "" {
+				envVars = append(envVars, corev1.EnvVar{
+					Name: "AWS_SECRET_ACCESS_KEY",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: s3.Secrets.Name,
+							},
+							Key: s3.Secrets.SecretAccessKey,
 						},
-						Key: s3.Secrets.SecretAccessKey,
 					},
-				},
-			})
-		}
-		if s3.Secrets.ConfigFile != "" {
-			envVars = append(envVars, corev1.EnvVar{
-				Name:  "AWS_CONFIG_FILE",
-				Value: AWSSecretDir + "/config",
-			})
-		}
-		if s3.Secrets.CredentialsFile != "" {
-			envVars = append(envVars, corev1.EnvVar{
-				Name:  "AWS_SHARED_CREDENTIALS_FILE",
-				Value: AWSSecretDir + "/credentials",
-			})
-		}
+				})
+			}
+			if s3.Secrets.ConfigFile != "" {
+				envVars = append(envVars, corev1.EnvVar{
+					Name:  "AWS_CONFIG_FILE",
+					Value: AWSSecretDir + "/config",
+				})
+			}
+			if s3.Secrets.CredentialsFile != "" {
+				envVars = append(envVars, corev1.EnvVar{
+					Name:  "AWS_SHARED_CREDENTIALS_FILE",
+					Value: AWSSecretDir + "/credentials",
+				})
+			}
 
-		// If a config or credentials file is provided in the secrets, load them up in a volume
-		if s3.Secrets.ConfigFile != "" || s3.Secrets.CredentialsFile != "" {
-			readonly := int32(400)
-			volume = &corev1.Volume{
-				Name: "awsSecrets",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: s3.Secrets.Name,
-						Items: []corev1.KeyToPath{
-							{
-								Key:  s3.Secrets.ConfigFile,
-								Path: "config",
-								Mode: &readonly,
-							},
-							{
-								Key:  s3.Secrets.CredentialsFile,
-								Path: "credentials",
-								Mode: &readonly,
+			// If a config or credentials file is provided in the secrets, load them up in a volume
+			if s3.Secrets.ConfigFile != "" || s3.Secrets.CredentialsFile != "" {
+				readonly := int32(400)
+				volume = &corev1.Volume{
+					Name: "awsSecrets",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: s3.Secrets.Name,
+							Items: []corev1.KeyToPath{
+								{
+									Key:  s3.Secrets.ConfigFile,
+									Path: "config",
+									Mode: &readonly,
+								},
+								{
+									Key:  s3.Secrets.CredentialsFile,
+									Path: "credentials",
+									Mode: &readonly,
+								},
 							},
 						},
 					},
-				},
-			}
-			volumeMount = &corev1.VolumeMount{
-				Name:      "awsSecrets",
-				ReadOnly:  true,
-				MountPath: AWSSecretDir,
+				}
+				volumeMount = &corev1.VolumeMount{
+					Name:      "awsSecrets",
+					ReadOnly:  true,
+					MountPath: AWSSecretDir,
+				}
 			}
 		}
 
@@ -310,12 +606,100 @@ func GenerateQueryParamsForBackup(backupRepository *solr.SolrBackupRepository, b
 	queryParams.Add("action", "BACKUP")
 	queryParams.Add("collection", collection)
 	queryParams.Add("name", collection)
-	queryParams.Add("async", AsyncIdForCollectionBackup(collection, backup.Name))
+	queryParams.Add("async", AsyncIdForCollectionBackup(collection, BackupAsyncName(backup, backupRepository.Name)))
+	queryParams.Add("location", BackupLocationPath(backupRepository, backup.Name))
+	queryParams.Add("repository", backupRepository.Name)
+	return queryParams
+}
+
+func GenerateQueryParamsForBackupPrune(backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETEBACKUP")
+	queryParams.Add("name", collection)
 	queryParams.Add("location", BackupLocationPath(backupRepository, backup.Name))
-	queryParams.Add("repository", backup.Spec.RepositoryName)
+	queryParams.Add("repository", backupRepository.Name)
+	queryParams.Add("maxNumBackupPoints", strconv.Itoa(backup.Spec.Recurrence.MaxSaved))
 	return queryParams
 }
 
+func GenerateQueryParamsForBackupList(backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "LISTBACKUP")
+	queryParams.Add("name", collection)
+	queryParams.Add("location", BackupLocationPath(backupRepository, backup.Name))
+	queryParams.Add("repository", backupRepository.Name)
+	return queryParams
+}
+
+// PruneBackupForCollection deletes the oldest backups of a collection, taken by a recurring SolrBackup, from the backup repository,
+// so that at most `recurrence.maxSaved` backups remain. The ids of the retained backups are returned, oldest first.
+func PruneBackupForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string, httpHeaders map[string]string, logger logr.Logger) (retainedIds []int32, err error) {
+	logger.Info("Calling to prune collection backups", "solrCloud", cloud.Name, "collection", collection, "maxSaved", backup.Spec.Recurrence.MaxSaved)
+	deleteResp := &solr_api.SolrDeleteBackupResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, GenerateQueryParamsForBackupPrune(backupRepository, backup, collection), httpHeaders, deleteResp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETEBACKUP", deleteResp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error pruning collection backups", "solrCloud", cloud.Name, "collection", collection)
+		return nil, err
+	}
+	for _, deleted := range deleteResp.Deleted {
+		logger.Info("Deleted collection backup", "solrCloud", cloud.Name, "collection", collection, "backupId", deleted.BackupId)
+	}
+
+	backupPoints, err := listBackupForCollection(cloud, backupRepository, backup, collection, httpHeaders, logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, backupPoint := range backupPoints {
+		retainedIds = append(retainedIds, backupPoint.BackupId)
+	}
+
+	return retainedIds, nil
+}
+
+// VerifyBackupForCollection inspects the latest backup of a collection in the backup repository.
+// If the backup is not complete, the problem with it is returned. An error is only returned if the backup could not be inspected.
+func VerifyBackupForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string, httpHeaders map[string]string, logger logr.Logger) (problem string, err error) {
+	backupPoints, err := listBackupForCollection(cloud, backupRepository, backup, collection, httpHeaders, logger)
+	if err != nil {
+		return "", err
+	}
+	return VerifyBackupPoints(backupPoints), nil
+}
+
+// VerifyBackupPoints checks that the latest of the given backup points, sorted by id, has been completely written to the backup repository.
+// If it has not, the problem with it is returned.
+func VerifyBackupPoints(backupPoints []solr_api.SolrBackupPoint) (problem string) {
+	if len(backupPoints) == 0 {
+		return "no backup found in the backup repository"
+	}
+	latest := backupPoints[len(backupPoints)-1]
+	if latest.EndTime == "" {
+		return fmt.Sprintf("backup %d was not completely written to the backup repository", latest.BackupId)
+	}
+	if len(latest.ShardBackupIds) == 0 {
+		return fmt.Sprintf("backup %d does not contain any shard backups", latest.BackupId)
+	}
+	return ""
+}
+
+// listBackupForCollection lists the backup points of a collection in the backup repository, sorted by id, oldest first.
+func listBackupForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string, httpHeaders map[string]string, logger logr.Logger) (backupPoints []solr_api.SolrBackupPoint, err error) {
+	listResp := &solr_api.SolrListBackupResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, GenerateQueryParamsForBackupList(backupRepository, backup, collection), httpHeaders, listResp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("LISTBACKUP", listResp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error listing collection backups", "solrCloud", cloud.Name, "collection", collection)
+		return nil, err
+	}
+	backupPoints = listResp.Backups
+	sort.Slice(backupPoints, func(i, j int) bool { return backupPoints[i].BackupId < backupPoints[j].BackupId })
+
+	return backupPoints, nil
+}
+
 func StartBackupForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, collection string, httpHeaders map[string]string, logger logr.Logger) (success bool, err error) {
 	queryParams := GenerateQueryParamsForBackup(backupRepository, backup, collection)
 	resp := &solr_api.SolrAsyncResponse{}
@@ -335,68 +719,228 @@ func StartBackupForCollection(cloud *solr.SolrCloud, backupRepository *solr.Solr
 }
 
 func CheckBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string, logger logr.Logger) (finished bool, success bool, asyncStatus string, err error) {
+	logger.Info("Calling to check on collection backup", "solrCloud", cloud.Name, "collection", collection)
+	finished, success, asyncStatus, err = checkAsyncRequest(cloud, AsyncIdForCollectionBackup(collection, backupName), httpHeaders)
+	if err != nil {
+		logger.Error(err, "Error checking on collection backup", "solrCloud", cloud.Name, "collection", collection)
+	}
+
+	return finished, success, asyncStatus, err
+}
+
+func DeleteAsyncInfoForBackup(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	logger.Info("Calling to delete async info for backup command.", "solrCloud", cloud.Name, "collection", collection)
+	err = deleteAsyncRequest(cloud, AsyncIdForCollectionBackup(collection, backupName), httpHeaders)
+	if err != nil {
+		logger.Error(err, "Error deleting async data for collection backup", "solrCloud", cloud.Name, "collection", collection)
+	}
+
+	return err
+}
+
+// checkAsyncRequest checks on the state of an asynchronous Collections API request
+func checkAsyncRequest(cloud *solr.SolrCloud, requestId string, httpHeaders map[string]string) (finished bool, success bool, asyncStatus string, err error) {
 	queryParams := url.Values{}
 	queryParams.Add("action", "REQUESTSTATUS")
-	queryParams.Add("requestid", AsyncIdForCollectionBackup(collection, backupName))
+	queryParams.Add("requestid", requestId)
 
 	resp := &solr_api.SolrAsyncResponse{}
 
-	logger.Info("Calling to check on collection backup", "solrCloud", cloud.Name, "collection", collection)
 	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
 
 	if err == nil {
-		if resp.ResponseHeader.Status == 0 {
-			asyncStatus = resp.Status.AsyncState
-			if resp.Status.AsyncState == "completed" {
-				finished = true
-				success = true
-			}
-			if resp.Status.AsyncState == "failed" {
-				finished = true
-				success = false
-			}
-		}
-	} else {
-		logger.Error(err, "Error checking on collection backup", "solrCloud", cloud.Name, "collection", collection)
+		finished, success, asyncStatus = asyncRequestState(resp)
 	}
 
 	return finished, success, asyncStatus, err
 }
 
-func DeleteAsyncInfoForBackup(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+// asyncRequestState interprets the response of a REQUESTSTATUS call for an asynchronous Collections API request
+func asyncRequestState(resp *solr_api.SolrAsyncResponse) (finished bool, success bool, asyncStatus string) {
+	if resp.ResponseHeader.Status == 0 {
+		asyncStatus = resp.Status.AsyncState
+		if resp.Status.AsyncState == "completed" {
+			finished = true
+			success = true
+		}
+		if resp.Status.AsyncState == "failed" {
+			finished = true
+			success = false
+		}
+	}
+	return finished, success, asyncStatus
+}
+
+// deleteAsyncRequest removes the stored state of a finished asynchronous Collections API request, so that its ID can be reused
+func deleteAsyncRequest(cloud *solr.SolrCloud, requestId string, httpHeaders map[string]string) (err error) {
 	queryParams := url.Values{}
 	queryParams.Add("action", "DELETESTATUS")
-	queryParams.Add("requestid", AsyncIdForCollectionBackup(collection, backupName))
+	queryParams.Add("requestid", requestId)
 
 	resp := &solr_api.SolrAsyncResponse{}
 
-	logger.Info("Calling to delete async info for backup command.", "solrCloud", cloud.Name, "collection", collection)
-	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
-	if err != nil {
-		logger.Error(err, "Error deleting async data for collection backup", "solrCloud", cloud.Name, "collection", collection)
-	}
-
-	return err
+	return solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
 }
 
-func EnsureDirectoryForBackup(solrCloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backupName string, config *rest.Config) (err error) {
+func EnsureDirectoryForBackup(solrCloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, config *rest.Config) (err error) {
 	// Directory creation only required/possible for managed (i.e. local) backups
 	if IsRepoManaged(backupRepository) {
-		backupPath := BackupLocationPath(backupRepository, backupName)
+		backupPath := ShellQuote(BackupLocationPath(backupRepository, backup.Name))
+		command := "mkdir -p " + backupPath
+		// Recurring backups add to the backups that were previously taken in the same directory
+		if backup.Spec.Recurrence == nil {
+			command = "rm -rf " + backupPath + " && " + command
+		}
+		podName, err := ReadySolrNodeName(solrCloud)
+		if err != nil {
+			return err
+		}
 		return RunExecForPod(
-			solrCloud.GetAllSolrNodeNames()[0],
+			podName,
 			solrCloud.Namespace,
-			[]string{"/bin/bash", "-c", "rm -rf " + backupPath + " && mkdir -p " + backupPath},
+			[]string{"/bin/bash", "-c", command},
 			*config,
 		)
 	}
 	return nil
 }
 
+// ReadySolrNodeName returns the name of a ready Solr pod of the SolrCloud, to run commands in, according to the status of the SolrCloud
+func ReadySolrNodeName(cloud *solr.SolrCloud) (string, error) {
+	for _, node := range cloud.Status.SolrNodes {
+		if node.Ready {
+			return node.Name, nil
+		}
+	}
+	return "", fmt.Errorf("SolrCloud %s has no ready Solr pods", cloud.Name)
+}
+
+// ClusterStateZnodes returns the znodes, relative to the chroot of the SolrCloud, that hold the cluster state that Solr does not include in collection backups:
+// the aliases and cluster properties of the SolrCloud, the properties of each of the given collections, and optionally the security.json of the SolrCloud.
+func ClusterStateZnodes(collections []string, includeSecurityJson bool) (znodes []string) {
+	znodes = []string{AliasesZnode, ClusterPropsZnode}
+	if includeSecurityJson {
+		znodes = append(znodes, SecurityJsonZnode)
+	}
+	for _, collection := range collections {
+		znodes = append(znodes, CollectionPropsZnode(collection))
+	}
+	return znodes
+}
+
+// CollectionPropsZnode returns the znode, relative to the chroot of the SolrCloud, that holds the properties of the collection
+func CollectionPropsZnode(collection string) string {
+	return "/collections/" + collection + "/collectionprops.json"
+}
+
+// ExportClusterStateForBackup reads the cluster state of the SolrCloud from ZooKeeper, through Solr, and writes it into the backup location of the SolrBackup,
+// under the ClusterStateBackupDirectory, keeping the paths of the znodes. Znodes that do not exist, or are empty, are skipped.
+// The exported znodes are returned.
+func ExportClusterStateForBackup(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, backup *solr.SolrBackup, httpHeaders map[string]string, config *rest.Config) (exported []string, err error) {
+	if !IsRepoManaged(backupRepository) {
+		return nil, TerminalErrorf(InvalidSpecReason, "the cluster state can only be exported to managed backup repositories, %q is not managed", backupRepository.Name)
+	}
+	podName, err := ReadySolrNodeName(cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	znodeData := make(map[string]string)
+	for _, znode := range ClusterStateZnodes(backup.Status.Collections, backup.Spec.IncludeSecurityJson) {
+		data, exists, err := solr_api.GetZookeeperData(cloud, znode, httpHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if exists && data != "" {
+			znodeData[znode] = data
+			exported = append(exported, znode)
+		}
+	}
+
+	archive, err := ClusterStateArchive(znodeData)
+	if err != nil {
+		return nil, err
+	}
+	_, err = RunExecForPodWithInput(
+		podName,
+		cloud.Namespace,
+		[]string{"/bin/bash", "-c", GenerateClusterStateExportCommand(BackupLocationPath(backupRepository, backup.Name))},
+		bytes.NewReader(archive),
+		*config,
+	)
+	return exported, err
+}
+
+// GenerateClusterStateExportCommand returns the shell command that replaces the exported cluster state in the given backup location
+// with the files of the tar archive read from stdin.
+func GenerateClusterStateExportCommand(backupLocation string) string {
+	directory := ShellQuote(backupLocation + "/" + ClusterStateBackupDirectory)
+	return "rm -rf " + directory + " && mkdir -p " + directory + " && tar -x -C " + directory
+}
+
+// ClusterStateArchive returns a tar archive with a file for each of the given znodes, at the path of the znode
+func ClusterStateArchive(znodeData map[string]string) ([]byte, error) {
+	znodes := make([]string, 0, len(znodeData))
+	for znode := range znodeData {
+		znodes = append(znodes, znode)
+	}
+	sort.Strings(znodes)
+
+	var archive bytes.Buffer
+	tarWriter := tar.NewWriter(&archive)
+	for _, znode := range znodes {
+		data := []byte(znodeData[znode])
+		if err := tarWriter.WriteHeader(&tar.Header{Name: strings.TrimPrefix(znode, "/"), Mode: 0644, Size: int64(len(data))}); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+// ReadClusterStateArchive returns the content of each file in the tar archive, by the path of its znode
+func ReadClusterStateArchive(archive []byte) (map[string]string, error) {
+	znodeData := make(map[string]string)
+	tarReader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return znodeData, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read the exported cluster state: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the exported cluster state: %w", err)
+		}
+		znodeData["/"+path.Clean(strings.TrimPrefix(header.Name, "./"))] = string(data)
+	}
+}
+
 func RunExecForPod(podName string, namespace string, command []string, config rest.Config) (err error) {
+	_, err = RunExecForPodWithOutput(podName, namespace, command, config)
+	return err
+}
+
+// RunExecForPodWithOutput runs the command in the Solr container of the pod, and returns what the command wrote to stdout
+func RunExecForPodWithOutput(podName string, namespace string, command []string, config rest.Config) (output string, err error) {
+	return RunExecForPodWithInput(podName, namespace, command, nil, config)
+}
+
+// RunExecForPodWithInput runs the command in the Solr container of the pod, with the given stdin if it is not nil, and returns what the command wrote to stdout.
+// Data passed through stdin is not limited in size, unlike the arguments of the command.
+func RunExecForPodWithInput(podName string, namespace string, command []string, stdin io.Reader, config rest.Config) (output string, err error) {
 	client := &kubernetes.Clientset{}
 	if client, err = kubernetes.NewForConfig(&config); err != nil {
-		return err
+		return "", err
 	}
 	req := client.CoreV1().RESTClient().Post().
 		Resource("pods").
@@ -405,14 +949,14 @@ func RunExecForPod(podName string, namespace string, command []string, config re
 		SubResource("exec")
 	scheme := runtime.NewScheme()
 	if err := corev1.AddToScheme(scheme); err != nil {
-		return fmt.Errorf("error adding to scheme: %v", err)
+		return "", fmt.Errorf("error adding to scheme: %v", err)
 	}
 
 	parameterCodec := runtime.NewParameterCodec(scheme)
 	req.VersionedParams(&corev1.PodExecOptions{
 		Command:   command,
 		Container: "solrcloud-node",
-		Stdin:     false,
+		Stdin:     stdin != nil,
 		Stdout:    true,
 		Stderr:    true,
 		TTY:       false,
@@ -420,19 +964,20 @@ func RunExecForPod(podName string, namespace string, command []string, config re
 
 	exec, err := remotecommand.NewSPDYExecutor(&config, "POST", req.URL())
 	if err != nil {
-		return fmt.Errorf("error while creating Executor: %v", err)
+		return "", fmt.Errorf("error while creating Executor: %v", err)
 	}
 
 	var stdout, stderr bytes.Buffer
 	err = exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
 		Stdout: &stdout,
 		Stderr: &stderr,
 		Tty:    false,
 	})
 
 	if err != nil {
-		return fmt.Errorf("error in Stream: %v", err)
+		return "", fmt.Errorf("error in Stream: %v", err)
 	}
 
-	return nil
+	return stdout.String(), nil
 }
@@ -19,10 +19,12 @@ package util
 
 import (
 	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"testing"
+	"time"
 )
 
 func TestSolrBackupApiParamsForManagedRepositoryBackup(t *testing.T) {
@@ -258,3 +260,452 @@ func TestRepositoryLookupFailsIfNoNameProvidedAndMultipleRepositoriesDefined(t *
 
 	assert.Nil(t, found, "Expected GetBackupRepositoryByName to report no match")
 }
+
+func TestInProgressBackupsForCloud(t *testing.T) {
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud"},
+		Spec: solr.SolrCloudSpec{
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "repo1", Managed: &solr.ManagedRepository{}},
+				{Name: "repo2", Managed: &solr.ManagedRepository{}},
+			},
+		},
+	}
+	backup := func(name string, cloudName string, repo string, started bool, finished bool) solr.SolrBackup {
+		b := solr.SolrBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       solr.SolrBackupSpec{SolrCloud: cloudName, RepositoryName: repo},
+		}
+		if started {
+			b.Status.SolrVersion = "8.11"
+		}
+		b.Status.Finished = finished
+		return b
+	}
+	backups := []solr.SolrBackup{
+		backup("notStarted", "cloud", "repo1", false, false),
+		backup("inProgress2", "cloud", "repo2", true, false),
+		backup("inProgress1", "cloud", "repo1", true, false),
+		backup("finished", "cloud", "repo1", true, true),
+		backup("otherCloud", "other", "repo3", true, false),
+	}
+
+	inProgress, removed := InProgressBackupsForCloud(cloud, backups)
+	assert.Equal(t, []string{"inProgress1", "inProgress2"}, inProgress, "Wrong in-progress backups for the SolrCloud")
+	assert.Empty(t, removed, "No repositories in use have been removed from the SolrCloud")
+
+	// Remove a repository that is in use
+	cloud.Spec.BackupRepositories = cloud.Spec.BackupRepositories[:1]
+	inProgress, removed = InProgressBackupsForCloud(cloud, backups)
+	assert.Equal(t, []string{"inProgress1", "inProgress2"}, inProgress, "Wrong in-progress backups for the SolrCloud")
+	assert.Equal(t, []string{"repo2"}, removed, "Wrong removed repositories that are in use")
+
+	// Finished and unstarted backups do not block anything
+	inProgress, removed = InProgressBackupsForCloud(cloud, backups[3:4])
+	assert.Empty(t, inProgress, "Finished backups are not in progress")
+	assert.Empty(t, removed, "Finished backups do not use repositories")
+}
+
+func TestInProgressRestoresForCloud(t *testing.T) {
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud"},
+		Spec: solr.SolrCloudSpec{
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "repo1", GCS: &solr.GcsRepository{Bucket: "bucket1"}},
+				{Name: "repo2", GCS: &solr.GcsRepository{Bucket: "bucket2"}},
+			},
+		},
+	}
+	now := metav1.Now()
+	restore := func(name string, cloudName string, started bool, finished bool) solr.SolrRestore {
+		r := solr.SolrRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       solr.SolrRestoreSpec{SolrCloud: cloudName},
+		}
+		if started {
+			r.Status.StartTime = &now
+		}
+		r.Status.Finished = finished
+		return r
+	}
+	backups := []solr.SolrBackup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "backup"}, Spec: solr.SolrBackupSpec{SolrCloud: "cloud", RepositoryName: "repo1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "snapshot"}, Spec: solr.SolrBackupSpec{SolrCloud: "cloud", VolumeSnapshot: &solr.VolumeSnapshotBackupOptions{}}},
+	}
+	fromBackup := restore("fromBackup", "cloud", true, false)
+	fromBackup.Spec.SolrBackup = "backup"
+	fromSnapshot := restore("fromSnapshot", "cloud", true, false)
+	fromSnapshot.Spec.SolrBackup = "snapshot"
+	fromRepository := restore("fromRepository", "cloud", true, false)
+	fromRepository.Spec.RepositoryName = "repo2"
+	fromDefinition := restore("fromDefinition", "cloud", true, false)
+	fromDefinition.Spec.Repository = &solr.SolrBackupRepository{Name: "other", GCS: &solr.GcsRepository{Bucket: "bucket2"}}
+	notStarted := restore("notStarted", "cloud", false, false)
+	notStarted.Spec.RepositoryName = "repo3"
+	finished := restore("finished", "cloud", true, true)
+	finished.Spec.RepositoryName = "repo3"
+	otherCloud := restore("otherCloud", "other", true, false)
+	otherCloud.Spec.RepositoryName = "repo3"
+	restores := []solr.SolrRestore{fromBackup, fromSnapshot, fromRepository, fromDefinition, notStarted, finished, otherCloud}
+
+	inProgress, removed := InProgressRestoresForCloud(cloud, restores, backups)
+	assert.Equal(t, []string{"fromBackup", "fromDefinition", "fromRepository", "fromSnapshot"}, inProgress, "Wrong in-progress restores for the SolrCloud")
+	assert.Empty(t, removed, "No repositories in use have been removed from the SolrCloud")
+
+	// Remove both repositories that are in use
+	cloud.Spec.BackupRepositories = nil
+	inProgress, removed = InProgressRestoresForCloud(cloud, restores, backups)
+	assert.Len(t, inProgress, 4, "Wrong in-progress restores for the SolrCloud")
+	assert.Equal(t, []string{"other", "repo1", "repo2"}, removed, "Wrong removed repositories that are in use, restores of VolumeSnapshots do not use repositories")
+}
+
+func TestSolrBackupApiParamsForPruningRecurringBackup(t *testing.T) {
+	managedRepository := &solr.SolrBackupRepository{
+		Name: "somemanagedrepository",
+		Managed: &solr.ManagedRepository{
+			Volume:    corev1.VolumeSource{}, // Actual volume info doesn't matter here
+			Directory: "/somedirectory",
+		},
+	}
+	backupConfig := solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "somebackupname",
+		},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:      "solrcloudcluster",
+			RepositoryName: "somemanagedrepository",
+			Collections:    []string{"col1", "col2"},
+			Recurrence: &solr.BackupRecurrence{
+				Schedule: "@daily",
+				MaxSaved: 3,
+			},
+		},
+	}
+
+	queryParams := GenerateQueryParamsForBackupPrune(managedRepository, &backupConfig, "col2")
+
+	assert.Equalf(t, "DELETEBACKUP", queryParams.Get("action"), "Wrong %s for Collections API Call", "action")
+	assert.Equalf(t, "col2", queryParams.Get("name"), "Wrong %s for Collections API Call", "backup name")
+	assert.Equalf(t, "/var/solr/data/backup-restore/somemanagedrepository/backups/somebackupname", queryParams.Get("location"), "Wrong %s for Collections API Call", "backup location")
+	assert.Equalf(t, "somemanagedrepository", queryParams.Get("repository"), "Wrong %s for Collections API Call", "repository")
+	assert.Equalf(t, "3", queryParams.Get("maxNumBackupPoints"), "Wrong %s for Collections API Call", "number of backups to retain")
+
+	queryParams = GenerateQueryParamsForBackupList(managedRepository, &backupConfig, "col2")
+
+	assert.Equalf(t, "LISTBACKUP", queryParams.Get("action"), "Wrong %s for Collections API Call", "action")
+	assert.Equalf(t, "col2", queryParams.Get("name"), "Wrong %s for Collections API Call", "backup name")
+	assert.Equalf(t, "/var/solr/data/backup-restore/somemanagedrepository/backups/somebackupname", queryParams.Get("location"), "Wrong %s for Collections API Call", "backup location")
+	assert.Equalf(t, "somemanagedrepository", queryParams.Get("repository"), "Wrong %s for Collections API Call", "repository")
+}
+
+func TestNextScheduledBackupTime(t *testing.T) {
+	lastFinished := time.Date(2021, 9, 16, 11, 48, 0, 0, time.UTC)
+
+	next, err := NextScheduledBackupTime(&solr.BackupRecurrence{Schedule: "0 6 * * *"}, lastFinished)
+	assert.NoError(t, err, "A standard CRON schedule should be valid")
+	assert.Equal(t, time.Date(2021, 9, 17, 6, 0, 0, 0, time.UTC), next, "Wrong next backup time for a standard CRON schedule")
+
+	next, err = NextScheduledBackupTime(&solr.BackupRecurrence{Schedule: "@every 2h"}, lastFinished)
+	assert.NoError(t, err, "An interval schedule should be valid")
+	assert.Equal(t, lastFinished.Add(time.Hour*2), next, "Wrong next backup time for an interval schedule")
+
+	_, err = NextScheduledBackupTime(&solr.BackupRecurrence{Schedule: "not a schedule"}, lastFinished)
+	assert.Error(t, err, "An invalid schedule should not be accepted")
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "An invalid schedule should be a terminal error, it cannot be fixed without changing the SolrBackup")
+}
+
+func TestResetBackupForRecurrence(t *testing.T) {
+	tru := true
+	now := metav1.Now()
+	backup := &solr.SolrBackup{
+		Status: solr.SolrBackupStatus{
+			SolrVersion: "8.11",
+			Collections: []string{"col1"},
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", Finished: true, Successful: &tru, StartTime: &now, FinishTime: &now},
+			},
+			FinishTime:        &now,
+			Successful:        &tru,
+			Finished:          true,
+			NextScheduledTime: &now,
+			RetainedBackups: []solr.RetainedCollectionBackups{
+				{Collection: "col1", BackupIds: []int32{3, 4}},
+			},
+			LastPruneTime: &now,
+			Conditions: []metav1.Condition{
+				{Type: solr.SolrBackupVerified, Status: metav1.ConditionTrue, Reason: "Verified"},
+				{Type: solr.SolrBackupClusterStateExported, Status: metav1.ConditionTrue, Reason: "Exported"},
+			},
+			AdditionalRepositoryStatuses: []solr.RepositoryBackupStatus{
+				{Repository: "repo2", Finished: true, Successful: &tru},
+			},
+		},
+	}
+
+	ResetBackupForRecurrence(backup)
+
+	assert.Empty(t, backup.Status.SolrVersion, "The Solr version should be reset, so that the next backup is started")
+	assert.Empty(t, backup.Status.Collections, "The collections should be resolved again for the next backup")
+	assert.Empty(t, backup.Status.CollectionBackupStatuses, "The collection backup statuses should be reset")
+	assert.False(t, backup.Status.Finished, "The backup should no longer be finished")
+	assert.Nil(t, backup.Status.Successful, "The backup should no longer be successful")
+	assert.Nil(t, backup.Status.FinishTime, "The backup should no longer have a finish time")
+	assert.Nil(t, backup.Status.NextScheduledTime, "The next scheduled time should be reset, it is scheduled once the backup finishes")
+	assert.Equal(t, []solr.RetainedCollectionBackups{{Collection: "col1", BackupIds: []int32{3, 4}}}, backup.Status.RetainedBackups, "The retained backups still exist, they should be kept")
+	assert.Equal(t, &now, backup.Status.LastPruneTime, "The last prune time should be kept")
+	assert.Empty(t, backup.Status.Conditions, "The verified and cluster state conditions should be reset, they describe the last backup")
+	assert.Empty(t, backup.Status.AdditionalRepositoryStatuses, "The additional repository statuses should be reset")
+}
+
+func TestSetRetainedBackupsForCollection(t *testing.T) {
+	backup := &solr.SolrBackup{}
+
+	SetRetainedBackupsForCollection(backup, "col1", []int32{0})
+	SetRetainedBackupsForCollection(backup, "col2", []int32{0})
+	SetRetainedBackupsForCollection(backup, "col1", []int32{0, 1})
+
+	assert.Equal(t, []solr.RetainedCollectionBackups{
+		{Collection: "col1", BackupIds: []int32{0, 1}},
+		{Collection: "col2", BackupIds: []int32{0}},
+	}, backup.Status.RetainedBackups, "Wrong retained backups, each collection should be listed once")
+}
+
+func TestVerifyBackupPoints(t *testing.T) {
+	assert.NotEmpty(t, VerifyBackupPoints(nil), "A backup that is not in the backup repository cannot be verified")
+
+	complete := solr_api.SolrBackupPoint{
+		BackupId:       0,
+		StartTime:      "2021-09-01T10:00:00Z",
+		EndTime:        "2021-09-01T10:05:00Z",
+		IndexFileCount: 12,
+		ShardBackupIds: map[string]string{"shard1": "md_shard1_0.json"},
+	}
+	assert.Empty(t, VerifyBackupPoints([]solr_api.SolrBackupPoint{complete}), "A complete backup should be verified")
+
+	incomplete := solr_api.SolrBackupPoint{
+		BackupId:       1,
+		StartTime:      "2021-09-02T10:00:00Z",
+		ShardBackupIds: map[string]string{"shard1": "md_shard1_1.json"},
+	}
+	assert.Contains(t, VerifyBackupPoints([]solr_api.SolrBackupPoint{complete, incomplete}), "backup 1", "Only the latest backup should be verified, and it has not finished")
+
+	noShards := solr_api.SolrBackupPoint{
+		BackupId:  1,
+		StartTime: "2021-09-02T10:00:00Z",
+		EndTime:   "2021-09-02T10:05:00Z",
+	}
+	assert.Contains(t, VerifyBackupPoints([]solr_api.SolrBackupPoint{complete, noShards}), "shard backups", "A backup without any shard backups should not be verified")
+}
+
+func TestClusterStateZnodes(t *testing.T) {
+	assert.Equal(t, []string{"/aliases.json", "/clusterprops.json"}, ClusterStateZnodes(nil, false), "The cluster-wide znodes should always be exported")
+	assert.Equal(t, []string{"/aliases.json", "/clusterprops.json", "/security.json"}, ClusterStateZnodes(nil, true), "The security.json should only be exported when requested")
+	assert.Equal(t, []string{"/aliases.json", "/clusterprops.json", "/collections/col1/collectionprops.json"}, ClusterStateZnodes([]string{"col1"}, false), "The properties of each backed-up collection should be exported")
+}
+
+func TestGenerateClusterStateExportCommand(t *testing.T) {
+	assert.Equal(t, "rm -rf '/var/solr/data/backup-restore/managed/backups/foo/cluster-state' && mkdir -p '/var/solr/data/backup-restore/managed/backups/foo/cluster-state'"+
+		" && tar -x -C '/var/solr/data/backup-restore/managed/backups/foo/cluster-state'",
+		GenerateClusterStateExportCommand("/var/solr/data/backup-restore/managed/backups/foo"), "The archive from stdin should replace any previous export")
+}
+
+func TestClusterStateArchive(t *testing.T) {
+	znodeData := map[string]string{
+		"/collections/col1/collectionprops.json": "{}",
+		"/aliases.json":                          `{"collection":{"alias":"col1"}}`,
+	}
+	archive, err := ClusterStateArchive(znodeData)
+	assert.NoError(t, err, "Creating the archive should not fail")
+
+	read, err := ReadClusterStateArchive(archive)
+	assert.NoError(t, err, "Reading the archive should not fail")
+	assert.Equal(t, znodeData, read, "Every znode should be read back from the archive, by its path")
+
+	read, err = ReadClusterStateArchive(nil)
+	assert.NoError(t, err, "Reading an empty archive should not fail")
+	assert.Empty(t, read, "An empty archive should not contain any znodes")
+}
+
+func TestReadySolrNodeName(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	_, err := ReadySolrNodeName(cloud)
+	assert.Error(t, err, "A SolrCloud without ready pods should not have a pod to run commands in")
+
+	cloud.Status.SolrNodes = []solr.SolrNodeStatus{{Name: "foo-solrcloud-0", Ready: false}, {Name: "foo-solrcloud-1", Ready: true}}
+	podName, err := ReadySolrNodeName(cloud)
+	assert.NoError(t, err, "A SolrCloud with a ready pod should not fail")
+	assert.Equal(t, "foo-solrcloud-1", podName, "Only ready pods should be used")
+}
+
+func TestMatchCollectionsForBackup(t *testing.T) {
+	clusterCollections := []string{"logs-2021-09", "logs-2021-10", "products"}
+
+	collections, err := MatchCollectionsForBackup(nil, clusterCollections)
+	assert.NoError(t, err, "Backing up all collections should not fail")
+	assert.Equal(t, []string{"logs-2021-09", "logs-2021-10", "products"}, collections, "Every collection should be backed up when none are given")
+
+	collections, err = MatchCollectionsForBackup([]string{"logs-*", "logs-2021-10"}, clusterCollections)
+	assert.NoError(t, err, "Valid patterns should not fail")
+	assert.Equal(t, []string{"logs-2021-09", "logs-2021-10"}, collections, "Collections matched by multiple entries should only be backed up once")
+
+	collections, err = MatchCollectionsForBackup([]string{"orders", "products", "books-*"}, clusterCollections)
+	assert.NoError(t, err, "Patterns that match no collections should not fail")
+	assert.Equal(t, []string{"orders", "products"}, collections, "Collections that are not patterns should always be backed up, even if they do not exist")
+
+	_, err = MatchCollectionsForBackup([]string{"logs-[2021"}, clusterCollections)
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "An invalid pattern should be a terminal error, it cannot be fixed without changing the SolrBackup")
+}
+
+func TestSolrBackupApiParamsForAdditionalRepositoryBackup(t *testing.T) {
+	additionalRepository := &solr.SolrBackupRepository{
+		Name: "secondrepository",
+		Managed: &solr.ManagedRepository{
+			Volume:    corev1.VolumeSource{}, // Actual volume info doesn't matter here
+			Directory: "/somedirectory",
+		},
+	}
+	backupConfig := solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "somebackupname",
+		},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:                 "solrcloudcluster",
+			RepositoryName:            "firstrepository",
+			AdditionalRepositoryNames: []string{"secondrepository"},
+			Collections:               []string{"col1", "col2"},
+		},
+	}
+
+	queryParams := GenerateQueryParamsForBackup(additionalRepository, &backupConfig, "col2")
+
+	assert.Equalf(t, "somebackupname-secondrepository-col2", queryParams.Get("async"), "Wrong %s for Collections API Call", "async id")
+	assert.Equalf(t, "/var/solr/data/backup-restore/secondrepository/backups/somebackupname", queryParams.Get("location"), "Wrong %s for Collections API Call", "backup location")
+	assert.Equalf(t, "secondrepository", queryParams.Get("repository"), "Wrong %s for Collections API Call", "repository")
+
+	assert.Equal(t, "somebackupname", BackupAsyncName(&backupConfig, "firstrepository"), "The main repository should use the backup name for async ids")
+	assert.Equal(t, "somebackupname-secondrepository", BackupAsyncName(&backupConfig, "secondrepository"), "Additional repositories should use their own async ids")
+}
+
+func TestValidateAdditionalRepositories(t *testing.T) {
+	backup := &solr.SolrBackup{
+		Spec: solr.SolrBackupSpec{RepositoryName: "repo1"},
+	}
+	assert.NoError(t, ValidateAdditionalRepositories(backup), "No additional repositories is valid")
+
+	backup.Spec.AdditionalRepositoryNames = []string{"repo2", "repo3"}
+	assert.NoError(t, ValidateAdditionalRepositories(backup), "Distinct additional repositories are valid")
+
+	backup.Spec.AdditionalRepositoryNames = []string{"repo2", "repo1"}
+	err := ValidateAdditionalRepositories(backup)
+	assert.Error(t, err, "The main repository cannot also be an additional repository")
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "Invalid additional repositories should be a terminal error")
+
+	backup.Spec.AdditionalRepositoryNames = []string{"repo2", "repo2"}
+	assert.Error(t, ValidateAdditionalRepositories(backup), "An additional repository cannot be used twice")
+
+	backup.Spec.RepositoryName = ""
+	backup.Spec.AdditionalRepositoryNames = []string{"repo2"}
+	assert.Error(t, ValidateAdditionalRepositories(backup), "The main repository must be named when using additional repositories")
+}
+
+func TestCheckStatusOfAdditionalRepositoryBackups(t *testing.T) {
+	tru := true
+	fals := false
+	backup := &solr.SolrBackup{}
+
+	repositoryStatus := AdditionalRepositoryBackupStatus(backup, "repo2")
+	assert.Equal(t, "repo2", repositoryStatus.Repository, "Wrong repository for the new status")
+	assert.Len(t, backup.Status.AdditionalRepositoryStatuses, 1, "The repository status should be added to the backup")
+	assert.Same(t, repositoryStatus, AdditionalRepositoryBackupStatus(backup, "repo2"), "The existing repository status should be returned")
+
+	CheckStatusOfRepositoryBackup(repositoryStatus)
+	assert.False(t, repositoryStatus.Finished, "A repository without collection backups is not finished")
+
+	repositoryStatus.CollectionBackupStatuses = []solr.CollectionBackupStatus{
+		{Collection: "col1", Finished: true, Successful: &tru},
+		{Collection: "col2", InProgress: true},
+	}
+	CheckStatusOfRepositoryBackup(repositoryStatus)
+	assert.False(t, repositoryStatus.Finished, "The repository backup is not finished while a collection is in progress")
+	assert.Nil(t, repositoryStatus.Successful, "An unfinished repository backup has no result")
+	assert.False(t, AdditionalRepositoryBackupsSuccessful(backup), "Unfinished repository backups are not successful")
+
+	repositoryStatus.CollectionBackupStatuses[1] = solr.CollectionBackupStatus{Collection: "col2", Finished: true, Successful: &fals}
+	CheckStatusOfRepositoryBackup(repositoryStatus)
+	assert.True(t, repositoryStatus.Finished, "The repository backup should be finished")
+	assert.Equal(t, &fals, repositoryStatus.Successful, "The repository backup failed for a collection")
+	assert.False(t, AdditionalRepositoryBackupsSuccessful(backup), "A failed repository backup is not successful")
+
+	repositoryStatus.Successful = nil
+	repositoryStatus.CollectionBackupStatuses[1].Successful = &tru
+	CheckStatusOfRepositoryBackup(repositoryStatus)
+	assert.Equal(t, &tru, repositoryStatus.Successful, "The repository backup succeeded for every collection")
+	assert.True(t, AdditionalRepositoryBackupsSuccessful(backup), "Every repository backup succeeded")
+}
+
+func TestCollectionBackupsAllowedToStart(t *testing.T) {
+	backup := &solr.SolrBackup{
+		Status: solr.SolrBackupStatus{
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", InProgress: true},
+				{Collection: "col2", Finished: true},
+				{Collection: "col3"},
+			},
+			AdditionalRepositoryStatuses: []solr.RepositoryBackupStatus{
+				{Repository: "repo2", CollectionBackupStatuses: []solr.CollectionBackupStatus{{Collection: "col1", InProgress: true}}},
+			},
+		},
+	}
+	assert.Equal(t, -1, CollectionBackupsAllowedToStart(backup), "Collection backups should not be limited without throttling options")
+
+	maxConcurrent := int32(3)
+	backup.Spec.Throttling = &solr.BackupThrottlingOptions{MaxConcurrentCollectionBackups: &maxConcurrent}
+	assert.Equal(t, 1, CollectionBackupsAllowedToStart(backup), "Collection backups in progress to any repository should count towards the limit")
+
+	maxConcurrent = 1
+	assert.Equal(t, 0, CollectionBackupsAllowedToStart(backup), "No collection backups should be started while the limit is exceeded")
+}
+
+func TestS3PersistenceWithoutSecrets(t *testing.T) {
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "somebackupname",
+			Namespace: "somenamespace",
+		},
+		Spec: solr.SolrBackupSpec{
+			Persistence: &solr.PersistenceSource{
+				S3: &solr.S3PersistenceSource{
+					Region: "us-west-2",
+					Bucket: "somebucket",
+				},
+			},
+		},
+	}
+
+	_, envVars, _, volume, volumeMount, _ := GeneratePersistenceOptions(backup, &corev1.VolumeSource{})
+	for _, envVar := range envVars {
+		assert.NotContains(t, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE"}, envVar.Name, "No credential env vars should be generated without secrets")
+	}
+	assert.Nil(t, volume, "No secrets volume should be generated without secrets")
+	assert.Nil(t, volumeMount, "No secrets volumeMount should be generated without secrets")
+	assert.Empty(t, backup.PersistenceServiceAccountName(), "No ServiceAccount should be used if none is configured")
+	assert.Nil(t, GeneratePersistenceServiceAccount(backup), "No ServiceAccount should be generated if none is configured")
+
+	backup.Spec.Persistence.S3.ServiceAccount = &solr.PersistenceServiceAccount{
+		Annotations: map[string]string{"eks.amazonaws.com/role-arn": "somerole"},
+	}
+	serviceAccount := GeneratePersistenceServiceAccount(backup)
+	if assert.NotNil(t, serviceAccount, "A ServiceAccount should be generated for the given annotations") {
+		assert.Equal(t, "somebackupname-solr-backup-persistence", serviceAccount.Name, "Wrong name for the generated ServiceAccount")
+		assert.Equal(t, "somenamespace", serviceAccount.Namespace, "Wrong namespace for the generated ServiceAccount")
+		assert.Equal(t, map[string]string{"eks.amazonaws.com/role-arn": "somerole"}, serviceAccount.Annotations, "Wrong annotations for the generated ServiceAccount")
+	}
+	assert.Equal(t, "somebackupname-solr-backup-persistence", backup.PersistenceServiceAccountName(), "The persistence Job should use the generated ServiceAccount")
+
+	backup.Spec.Persistence.S3.ServiceAccount.Name = "existing-sa"
+	assert.Nil(t, GeneratePersistenceServiceAccount(backup), "No ServiceAccount should be generated when an existing one is given")
+	assert.Equal(t, "existing-sa", backup.PersistenceServiceAccountName(), "The persistence Job should use the existing ServiceAccount")
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// Lifecycle events published for SolrClouds
+	SolrCloudUpdateStartedEvent   = "org.apache.solr.solrcloud.update.started"
+	SolrCloudUpdateCompletedEvent = "org.apache.solr.solrcloud.update.completed"
+	SolrCloudScaledEvent          = "org.apache.solr.solrcloud.scaled"
+	SolrCloudDegradedEvent        = "org.apache.solr.solrcloud.degraded"
+	SolrCloudReadyEvent           = "org.apache.solr.solrcloud.ready"
+
+	// Lifecycle events published for SolrBackups
+	SolrBackupCompletedEvent = "org.apache.solr.solrbackup.completed"
+
+	// Lifecycle events published for SolrRestores
+	SolrRestoreCompletedEvent = "org.apache.solr.solrrestore.completed"
+
+	// Lifecycle events published for SolrMigrations
+	SolrMigrationCompletedEvent = "org.apache.solr.solrmigration.completed"
+
+	cloudEventsTimeout = time.Second * 10
+)
+
+var (
+	cloudEventsSink   string
+	cloudEventsClient = &http.Client{Timeout: cloudEventsTimeout}
+)
+
+// SetCloudEventsSink sets the HTTP endpoint that lifecycle events are published to as CloudEvents.
+// If the sink is empty, no events are published.
+func SetCloudEventsSink(sink string) {
+	cloudEventsSink = sink
+}
+
+// CloudEvent is a CloudEvent (https://cloudevents.io) in the JSON structured content mode
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype,omitempty"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// NewCloudEvent creates a CloudEvent of the given type for a Solr resource.
+// The source identifies the resource through its API path, so that events can be correlated across the fleet.
+func NewCloudEvent(eventType string, resource string, obj client.Object, data interface{}) CloudEvent {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		ID:          hex.EncodeToString(id),
+		Source:      fmt.Sprintf("/apis/solr.apache.org/v1beta1/namespaces/%s/%s/%s", obj.GetNamespace(), resource, obj.GetName()),
+		Type:        eventType,
+		Subject:     obj.GetName(),
+		Time:        time.Now().UTC().Format(time.RFC3339),
+		Data:        data,
+	}
+	if data != nil {
+		event.DataContentType = "application/json"
+	}
+	return event
+}
+
+// PublishCloudEvent sends a CloudEvent to the configured sink, if there is one.
+// Events are sent asynchronously, so that reconciliation is never blocked by the sink; failures are only logged.
+func PublishCloudEvent(eventType string, resource string, obj client.Object, data interface{}) {
+	if cloudEventsSink == "" {
+		return
+	}
+	event := NewCloudEvent(eventType, resource, obj, data)
+	go func(sink string) {
+		if err := sendCloudEvent(context.Background(), sink, event); err != nil {
+			ctrl.Log.WithName("cloudevents").Error(err, "Could not publish CloudEvent", "type", event.Type, "source", event.Source)
+		}
+	}(cloudEventsSink)
+}
+
+func sendCloudEvent(ctx context.Context, sink string, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", CloudEventsContentType)
+	resp, err := cloudEventsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("CloudEvents sink %s responded with status %s", sink, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendCloudEvent(t *testing.T) {
+	solrCloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	event := NewCloudEvent(SolrCloudScaledEvent, "solrclouds", solrCloud, map[string]int32{"fromReplicas": 3, "toReplicas": 5})
+	assert.Equal(t, "/apis/solr.apache.org/v1beta1/namespaces/default/solrclouds/foo", event.Source, "Wrong CloudEvent source")
+	assert.Equal(t, "foo", event.Subject, "Wrong CloudEvent subject")
+	assert.NotEmpty(t, event.ID, "A CloudEvent must have an ID")
+	assert.Equal(t, "application/json", event.DataContentType, "Wrong CloudEvent data content type")
+
+	var received map[string]interface{}
+	var contentType string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	assert.NoError(t, sendCloudEvent(context.Background(), sink.URL, event), "The CloudEvent should be accepted by the sink")
+	assert.Equal(t, CloudEventsContentType, contentType, "CloudEvents should be sent in the structured content mode")
+	assert.Equal(t, CloudEventsSpecVersion, received["specversion"], "Wrong CloudEvent spec version")
+	assert.Equal(t, SolrCloudScaledEvent, received["type"], "Wrong CloudEvent type")
+	assert.Equal(t, map[string]interface{}{"fromReplicas": float64(3), "toReplicas": float64(5)}, received["data"], "Wrong CloudEvent data")
+
+	failingSink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingSink.Close()
+	assert.Error(t, sendCloudEvent(context.Background(), failingSink.URL, event), "An error should be returned when the sink does not accept the CloudEvent")
+}
@@ -0,0 +1,242 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// CollectionHealthy is the health of a collection whose replicas are all active
+	CollectionHealthy = 2
+	// CollectionDegraded is the health of a collection whose shards all have an active leader, but have replicas that are not active
+	CollectionDegraded = 1
+	// CollectionDown is the health of a collection that has a shard without an active leader
+	CollectionDown = 0
+
+	// maxConcurrentDocumentCounts is the maximum number of collections whose documents are counted at the same time, for each SolrCloud
+	maxConcurrentDocumentCounts = 4
+)
+
+var (
+	collectionMetricLabels = []string{"namespace", "solrcloud", "collection"}
+
+	solrCollectionDocuments = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_collection_documents",
+			Help: "The number of documents in the collection, as counted by a query for all documents.",
+		},
+		collectionMetricLabels,
+	)
+	solrCollectionShards = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_collection_shards",
+			Help: "The number of active shards in the collection.",
+		},
+		collectionMetricLabels,
+	)
+	solrCollectionHealth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_collection_health",
+			Help: "The health of the collection. 2 if all replicas are active, 1 if every shard has an active leader but some replicas are not active, 0 if a shard has no active leader.",
+		},
+		collectionMetricLabels,
+	)
+	solrCollectionLastReload = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_collection_last_reload_timestamp",
+			Help: "The unix time, in seconds, that the Solr Operator last reloaded the collection after syncing its configset files.",
+		},
+		collectionMetricLabels,
+	)
+
+	// exportedCollections keeps track of the collections that metrics have been exported for, by SolrCloud,
+	// so that the metrics of deleted collections can be removed.
+	exportedCollections = newMetricSeries(deleteCollectionMetrics)
+)
+
+func init() {
+	// The controller-runtime registry is served on the operator's metrics endpoint
+	metrics.Registry.MustRegister(solrCollectionDocuments, solrCollectionShards, solrCollectionHealth, solrCollectionLastReload)
+}
+
+// UpdateSolrCollectionMetrics refreshes the metrics of every collection in the SolrCloud, and removes the metrics of collections that no longer exist.
+// The metrics are refreshed at most once every refreshIntervalSeconds, so reconciles in between do not query Solr.
+// The time until the next refresh is due is returned, so that the caller can requeue for it.
+// A collection whose documents cannot be counted has its other metrics refreshed, and the first such error is returned.
+func UpdateSolrCollectionMetrics(cloud *solr.SolrCloud, clusterState *SolrClusterState, httpHeaders map[string]string) (nextRefresh time.Duration, err error) {
+	refreshInterval := time.Second * time.Duration(cloud.Spec.CollectionMetrics.RefreshIntervalSeconds)
+	if untilRefresh := exportedCollections.untilRefresh(cloud.Namespace, cloud.Name, refreshInterval); untilRefresh > 0 {
+		return untilRefresh, nil
+	}
+
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return refreshInterval, err
+	}
+	liveNodes := make(map[string]bool, len(clusterStatus.LiveNodes))
+	for _, node := range clusterStatus.LiveNodes {
+		liveNodes[node] = true
+	}
+	lastReloads := collectionLastReloadTimes(cloud)
+
+	collections := make(map[string]bool, len(clusterStatus.Collections))
+	for collection, collectionStatus := range clusterStatus.Collections {
+		collections[collection] = true
+		labels := prometheus.Labels{"namespace": cloud.Namespace, "solrcloud": cloud.Name, "collection": collection}
+
+		solrCollectionShards.With(labels).Set(float64(activeShardCount(collectionStatus)))
+		solrCollectionHealth.With(labels).Set(float64(CollectionHealth(collectionStatus, liveNodes)))
+		if lastReload, hasReloaded := lastReloads[collection]; hasReloaded {
+			solrCollectionLastReload.With(labels).Set(float64(lastReload))
+		}
+	}
+	err = updateCollectionDocumentCounts(cloud, collections, httpHeaders)
+
+	// A failed refresh is retried at the next interval as well, so that an unhealthy SolrCloud is not queried on every reconcile
+	exportedCollections.refreshed(cloud.Namespace, cloud.Name, collections)
+	return refreshInterval, err
+}
+
+// updateCollectionDocumentCounts counts the documents of the given collections, with at most maxConcurrentDocumentCounts queries at a time.
+// The count of a collection that cannot be queried is removed, and the first such error is returned.
+func updateCollectionDocumentCounts(cloud *solr.SolrCloud, collections map[string]bool, httpHeaders map[string]string) (err error) {
+	var errLock sync.Mutex
+	var wg sync.WaitGroup
+	queries := make(chan struct{}, maxConcurrentDocumentCounts)
+	for collection := range collections {
+		wg.Add(1)
+		queries <- struct{}{}
+		go func(collection string) {
+			defer func() {
+				<-queries
+				wg.Done()
+			}()
+			labels := prometheus.Labels{"namespace": cloud.Namespace, "solrcloud": cloud.Name, "collection": collection}
+			if documents, countErr := countCollectionDocuments(cloud, collection, httpHeaders); countErr != nil {
+				solrCollectionDocuments.Delete(labels)
+				errLock.Lock()
+				if err == nil {
+					err = fmt.Errorf("error counting the documents of collection %s: %w", collection, countErr)
+				}
+				errLock.Unlock()
+			} else {
+				solrCollectionDocuments.With(labels).Set(float64(documents))
+			}
+		}(collection)
+	}
+	wg.Wait()
+	return err
+}
+
+// RemoveSolrCollectionMetrics removes the metrics of every collection in the SolrCloud, for SolrClouds that are deleted or no longer export collection metrics
+func RemoveSolrCollectionMetrics(namespace string, solrCloudName string) {
+	exportedCollections.remove(namespace, solrCloudName)
+}
+
+func deleteCollectionMetrics(namespace string, solrCloudName string, collection string) {
+	labels := prometheus.Labels{"namespace": namespace, "solrcloud": solrCloudName, "collection": collection}
+	solrCollectionDocuments.Delete(labels)
+	solrCollectionShards.Delete(labels)
+	solrCollectionHealth.Delete(labels)
+	solrCollectionLastReload.Delete(labels)
+}
+
+// CollectionHealth returns whether the collection is healthy, degraded or down, given the Solr nodes that are live.
+// Only active shards are considered, since inactive shards, such as the parents of split shards, do not serve requests.
+func CollectionHealth(collectionStatus solr_api.SolrCollectionStatus, liveNodes map[string]bool) int {
+	health := CollectionHealthy
+	for _, shardStatus := range collectionStatus.Shards {
+		if shardStatus.State != solr_api.ShardActive {
+			continue
+		}
+		hasActiveLeader := false
+		for _, replicaStatus := range shardStatus.Replicas {
+			// Replicas on nodes that are not live can still be listed as active in the cluster state
+			active := replicaStatus.State == solr_api.ReplicaActive && liveNodes[replicaStatus.NodeName]
+			if active && replicaStatus.Leader {
+				hasActiveLeader = true
+			} else if !active {
+				health = CollectionDegraded
+			}
+		}
+		if !hasActiveLeader {
+			return CollectionDown
+		}
+	}
+	return health
+}
+
+func activeShardCount(collectionStatus solr_api.SolrCollectionStatus) (count int) {
+	for _, shardStatus := range collectionStatus.Shards {
+		if shardStatus.State == solr_api.ShardActive {
+			count++
+		}
+	}
+	return count
+}
+
+// collectionLastReloadTimes returns the unix time that each collection was last reloaded by the Solr Operator, after the files of its configset were synced
+func collectionLastReloadTimes(cloud *solr.SolrCloud) map[string]int64 {
+	lastReloads := map[string]int64{}
+	for _, configSetStatus := range cloud.Status.ConfigSetFiles {
+		for _, collection := range configSetStatus.ReloadedCollections {
+			if reloadTime := configSetStatus.LastSyncTime.Unix(); reloadTime > lastReloads[collection] {
+				lastReloads[collection] = reloadTime
+			}
+		}
+	}
+	return lastReloads
+}
+
+// countCollectionDocuments counts the documents in the collection with a distributed query, that does not return any documents
+func countCollectionDocuments(cloud *solr.SolrCloud, collection string, httpHeaders map[string]string) (int64, error) {
+	return countDocumentsOnNode(cloud, solr.InternalURLForCloud(cloud), collection, httpHeaders)
+}
+
+// countDocumentsOnNode counts the documents in the collection with a distributed query, sent to the Solr node with the given base URL
+func countDocumentsOnNode(cloud *solr.SolrCloud, nodeUrl string, collection string, httpHeaders map[string]string) (int64, error) {
+	queryParams := url.Values{}
+	queryParams.Set("q", "*:*")
+	queryParams.Set("rows", "0")
+	body, err := solr_api.CallNodeAdminApi(cloud, nodeUrl, "/solr/"+url.PathEscape(collection)+"/select", queryParams, httpHeaders)
+	if err != nil {
+		return 0, err
+	}
+	queryResponse := &struct {
+		Response *struct {
+			NumFound int64 `json:"numFound"`
+		} `json:"response"`
+	}{}
+	if err = json.Unmarshal(body, queryResponse); err != nil {
+		return 0, fmt.Errorf("could not parse the query response: %w", err)
+	}
+	if queryResponse.Response == nil {
+		return 0, fmt.Errorf("the response is not a query response")
+	}
+	return queryResponse.Response.NumFound, nil
+}
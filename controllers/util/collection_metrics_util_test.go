@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectionHealth(t *testing.T) {
+	liveNodes := map[string]bool{"node1": true, "node2": true}
+	collectionStatus := solr_api.SolrCollectionStatus{
+		Shards: map[string]solr_api.SolrShardStatus{
+			"shard1": {
+				State: solr_api.ShardActive,
+				Replicas: map[string]solr_api.SolrReplicaStatus{
+					"core_node1": {State: solr_api.ReplicaActive, NodeName: "node1", Leader: true},
+					"core_node2": {State: solr_api.ReplicaActive, NodeName: "node2"},
+				},
+			},
+			// Inactive shards, such as the parents of split shards, do not count towards the health
+			"shard2": {
+				State: "inactive",
+				Replicas: map[string]solr_api.SolrReplicaStatus{
+					"core_node3": {State: solr_api.ReplicaDown, NodeName: "node1", Leader: true},
+				},
+			},
+		},
+	}
+	assert.Equal(t, CollectionHealthy, CollectionHealth(collectionStatus, liveNodes), "All replicas of the active shards are active")
+	assert.Equal(t, 1, activeShardCount(collectionStatus))
+
+	delete(liveNodes, "node2")
+	assert.Equal(t, CollectionDegraded, CollectionHealth(collectionStatus, liveNodes), "A replica on a node that is not live is not active")
+
+	delete(liveNodes, "node1")
+	assert.Equal(t, CollectionDown, CollectionHealth(collectionStatus, liveNodes), "A shard without an active leader takes the collection down")
+}
+
+func TestCollectionLastReloadTimes(t *testing.T) {
+	firstSync := metav1.NewTime(time.Unix(1631847600, 0))
+	secondSync := metav1.NewTime(time.Unix(1631851200, 0))
+	cloud := &solr.SolrCloud{
+		Status: solr.SolrCloudStatus{
+			ConfigSetFiles: []solr.ConfigSetFilesStatus{
+				{ConfigSet: "products", ReloadedCollections: []string{"products", "shared"}, LastSyncTime: secondSync},
+				{ConfigSet: "logs", ReloadedCollections: []string{"shared"}, LastSyncTime: firstSync},
+				{ConfigSet: "empty", LastSyncTime: secondSync},
+			},
+		},
+	}
+	assert.Equal(t, map[string]int64{"products": secondSync.Unix(), "shared": secondSync.Unix()}, collectionLastReloadTimes(cloud), "The latest reload of each collection should be used")
+}
+
+func TestUpdateSolrCollectionMetricsWaitsForRefreshInterval(t *testing.T) {
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "refresh", Namespace: "default"},
+		Spec:       solr.SolrCloudSpec{CollectionMetrics: &solr.SolrCollectionMetricsOptions{RefreshIntervalSeconds: 60}},
+	}
+	exportedCollections.resources["default/refresh"] = &exportedSeries{keys: map[string]bool{}, lastRefresh: time.Now().Add(-time.Second * 20)}
+	defer RemoveSolrCollectionMetrics("default", "refresh")
+
+	// A nil cluster state would fail the refresh, so no error means that Solr was not queried
+	nextRefresh, err := UpdateSolrCollectionMetrics(cloud, nil, nil)
+	assert.NoError(t, err, "The metrics should not be refreshed before the refresh interval has passed")
+	assert.InDelta(t, (time.Second * 40).Seconds(), nextRefresh.Seconds(), 1, "The next refresh should be due when the interval has passed")
+}
@@ -23,13 +23,16 @@ import (
 	"strings"
 	"time"
 
+	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -147,6 +150,23 @@ func IsPVCOrphan(pvcName string, replicas int32) bool {
 	return int32(ordinal) >= replicas
 }
 
+// setDefaultInitContainerResources sets the defaultInitContainerResources from the podOptions on the init containers that the Solr Operator generates.
+// The init containers provided through the podOptions keep their own resource requirements.
+func setDefaultInitContainerResources(podSpec *corev1.PodSpec, customPodOptions *solr.PodOptions) {
+	if customPodOptions == nil || (customPodOptions.DefaultInitContainerResources.Limits == nil && customPodOptions.DefaultInitContainerResources.Requests == nil) {
+		return
+	}
+	customInitContainers := make(map[string]bool, len(customPodOptions.InitContainers))
+	for _, initContainer := range customPodOptions.InitContainers {
+		customInitContainers[initContainer.Name] = true
+	}
+	for i := range podSpec.InitContainers {
+		if !customInitContainers[podSpec.InitContainers[i].Name] {
+			podSpec.InitContainers[i].Resources = *customPodOptions.DefaultInitContainerResources.DeepCopy()
+		}
+	}
+}
+
 // customizeProbe builds the probe logic used for pod liveness, readiness, startup checks
 func customizeProbe(initialProbe *corev1.Probe, customProbe corev1.Probe) *corev1.Probe {
 	if customProbe.InitialDelaySeconds != 0 {
@@ -192,6 +212,21 @@ func CopyConfigMapFields(from, to *corev1.ConfigMap, logger logr.Logger) bool {
 	return requireUpdate
 }
 
+// CopySecretFields copies the owned fields from one Secret to another
+func CopySecretFields(from, to *corev1.Secret, logger logr.Logger) bool {
+	logger = logger.WithValues("kind", "secret")
+	requireUpdate := CopyLabelsAndAnnotations(&from.ObjectMeta, &to.ObjectMeta, logger)
+
+	if !DeepEqualWithNils(to.Data, from.Data) {
+		requireUpdate = true
+		// Do not log the values, as they may contain credentials
+		logger.Info("Update required because field changed", "field", "Data")
+	}
+	to.Data = from.Data
+
+	return requireUpdate
+}
+
 // CopyServiceFields copies the owned fields from one Service to another
 func CopyServiceFields(from, to *corev1.Service, logger logr.Logger) bool {
 	logger = logger.WithValues("kind", "service")
@@ -412,6 +447,7 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 		to.Labels = from.Labels
 	}
 
+	PreserveLegacyContentHashes(from.Annotations, to.Annotations)
 	if !DeepEqualWithNils(to.Annotations, from.Annotations) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", basePath+"Annotations", "from", to.Annotations, "to", from.Annotations)
@@ -436,12 +472,37 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 		to.Spec.ImagePullSecrets = from.Spec.ImagePullSecrets
 	}
 
+	if !DeepEqualWithNils(to.Spec.HostNetwork, from.Spec.HostNetwork) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.HostNetwork", "from", to.Spec.HostNetwork, "to", from.Spec.HostNetwork)
+		to.Spec.HostNetwork = from.Spec.HostNetwork
+	}
+
+	if !DeepEqualWithNils(to.Spec.ReadinessGates, from.Spec.ReadinessGates) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.ReadinessGates", "from", to.Spec.ReadinessGates, "to", from.Spec.ReadinessGates)
+		to.Spec.ReadinessGates = from.Spec.ReadinessGates
+	}
+
+	// Kubernetes sets a default DNSPolicy, so only update it if one is requested
+	if from.Spec.DNSPolicy != "" && !DeepEqualWithNils(to.Spec.DNSPolicy, from.Spec.DNSPolicy) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.DNSPolicy", "from", to.Spec.DNSPolicy, "to", from.Spec.DNSPolicy)
+		to.Spec.DNSPolicy = from.Spec.DNSPolicy
+	}
+
 	if !DeepEqualWithNils(to.Spec.Affinity, from.Spec.Affinity) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", basePath+"Spec.Affinity", "from", to.Spec.Affinity, "to", from.Spec.Affinity)
 		to.Spec.Affinity = from.Spec.Affinity
 	}
 
+	if !DeepEqualWithNils(to.Spec.TopologySpreadConstraints, from.Spec.TopologySpreadConstraints) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.TopologySpreadConstraints", "from", to.Spec.TopologySpreadConstraints, "to", from.Spec.TopologySpreadConstraints)
+		to.Spec.TopologySpreadConstraints = from.Spec.TopologySpreadConstraints
+	}
+
 	if !DeepEqualWithNils(to.Spec.SecurityContext, from.Spec.SecurityContext) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", basePath+"Spec.SecurityContext", "from", to.Spec.SecurityContext, "to", from.Spec.SecurityContext)
@@ -535,6 +596,12 @@ func CopyPodContainers(fromPtr, toPtr *[]corev1.Container, basePath string, logg
 				to[i].Resources = from[i].Resources
 			}
 
+			if !DeepEqualWithNils(to[i].SecurityContext, from[i].SecurityContext) {
+				requireUpdate = true
+				logger.Info("Update required because field changed", "field", containerBasePath+"SecurityContext", "from", to[i].SecurityContext, "to", from[i].SecurityContext)
+				to[i].SecurityContext = from[i].SecurityContext
+			}
+
 			if !DeepEqualWithNils(to[i].VolumeMounts, from[i].VolumeMounts) {
 				requireUpdate = true
 				logger.Info("Update required because field changed", "field", containerBasePath+"VolumeMounts", "from", to[i].VolumeMounts, "to", from[i].VolumeMounts)
@@ -620,10 +687,20 @@ func CopyPodVolumes(fromPtr, toPtr *[]corev1.Volume, basePath string, logger log
 }
 
 // OvertakeControllerRef makes sure that the controlled object has the owner as the controller ref.
-// If the object has a different controller, then that ref will be downgraded to an "owner" and the new controller ref will be added
+// If the object has a different controller, then that ref will be downgraded to an "owner" and the new controller ref will be added.
+// Objects that are controlled by another object of the same kind as the owner are never taken over, since that object would then lose them,
+// e.g. when a custom name of one SolrCloud's resource is the default name of another SolrCloud's resource.
 func OvertakeControllerRef(owner metav1.Object, controlled metav1.Object, scheme *runtime.Scheme) (needsUpdate bool, err error) {
 	if !metav1.IsControlledBy(controlled, owner) {
 		if otherController := metav1.GetControllerOfNoCopy(controlled); otherController != nil {
+			if ownerObject, isRuntimeObject := owner.(runtime.Object); isRuntimeObject {
+				ownerGVK, gvkErr := apiutil.GVKForObject(ownerObject, scheme)
+				otherGV, gvErr := schema.ParseGroupVersion(otherController.APIVersion)
+				if gvkErr == nil && gvErr == nil && otherController.Kind == ownerGVK.Kind && otherGV.Group == ownerGVK.Group {
+					return false, TerminalErrorf(InvalidSpecReason, "%s is already controlled by %s %s, so it cannot be used by %s %s",
+						controlled.GetName(), otherController.Kind, otherController.Name, ownerGVK.Kind, owner.GetName())
+				}
+			}
 			otherController.Controller = pointer.BoolPtr(false)
 			otherController.BlockOwnerDeletion = pointer.BoolPtr(false)
 		}
@@ -632,3 +709,20 @@ func OvertakeControllerRef(owner metav1.Object, controlled metav1.Object, scheme
 	}
 	return needsUpdate, err
 }
+
+// ReleaseOwnerRef removes the references to the owner from the owned object, so that the object is no longer deleted along with the owner.
+// This is used for resources that the user manages themselves, after the Solr Operator created them.
+func ReleaseOwnerRef(owner metav1.Object, owned metav1.Object) (needsUpdate bool) {
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range owned.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			needsUpdate = true
+		} else {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	if needsUpdate {
+		owned.SetOwnerReferences(ownerRefs)
+	}
+	return needsUpdate
+}
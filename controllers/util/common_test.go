@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestOvertakeControllerRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, solr.AddToScheme(scheme))
+
+	foo := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: types.UID("foo-uid")}}
+	bar := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default", UID: types.UID("bar-uid")}}
+	exporter := &solr.SolrPrometheusExporter{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: types.UID("exporter-uid")}}
+
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud", Namespace: "default"}}
+	needsUpdate, err := OvertakeControllerRef(foo, statefulSet, scheme)
+	assert.NoError(t, err)
+	assert.True(t, needsUpdate, "An object without a controller should be taken over")
+	assert.True(t, metav1.IsControlledBy(statefulSet, foo))
+
+	needsUpdate, err = OvertakeControllerRef(foo, statefulSet, scheme)
+	assert.NoError(t, err)
+	assert.False(t, needsUpdate, "An object that is already controlled by the owner should not be updated")
+
+	needsUpdate, err = OvertakeControllerRef(bar, statefulSet, scheme)
+	assert.Error(t, err, "An object controlled by another SolrCloud should not be taken over")
+	assert.False(t, needsUpdate)
+	assert.True(t, metav1.IsControlledBy(statefulSet, foo), "The controller of the object should not be changed")
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	assert.NoError(t, controllerutil.SetControllerReference(exporter, deployment, scheme))
+	needsUpdate, err = OvertakeControllerRef(foo, deployment, scheme)
+	assert.NoError(t, err, "An object controlled by another kind of owner should be taken over")
+	assert.True(t, needsUpdate)
+	assert.True(t, metav1.IsControlledBy(deployment, foo))
+	assert.Len(t, deployment.OwnerReferences, 2, "The previous controller should be kept as an owner")
+}
+
+func TestReleaseOwnerRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, solr.AddToScheme(scheme))
+
+	foo := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: types.UID("foo-uid")}}
+	exporter := &solr.SolrPrometheusExporter{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: types.UID("exporter-uid")}}
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	assert.NoError(t, controllerutil.SetControllerReference(foo, deployment, scheme))
+	assert.NoError(t, controllerutil.SetOwnerReference(exporter, deployment, scheme))
+
+	assert.True(t, ReleaseOwnerRef(foo, deployment), "An object owned by the owner should be released")
+	assert.False(t, metav1.IsControlledBy(deployment, foo), "The owner should no longer control the object")
+	assert.Len(t, deployment.OwnerReferences, 1, "The references to other owners should be kept")
+	assert.Equal(t, exporter.UID, deployment.OwnerReferences[0].UID)
+
+	assert.False(t, ReleaseOwnerRef(foo, deployment), "An object that is not owned by the owner should not be updated")
+}
@@ -0,0 +1,182 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"strings"
+	"sync"
+)
+
+var useSHA256ContentHashes bool
+
+// SetSHA256ContentHashes switches the hashes of config contents, that are used to restart pods when the contents change, from MD5 to SHA-256.
+func SetSHA256ContentHashes(enabled bool) {
+	useSHA256ContentHashes = enabled
+}
+
+// SHA256ContentHashes returns whether config contents are hashed with SHA-256.
+// This is always the case in FIPS mode.
+func SHA256ContentHashes() bool {
+	return useSHA256ContentHashes || fipsMode
+}
+
+// maxLegacyContentHashes bounds the number of SHA-256 hashes whose MD5 hash is remembered.
+// Only the hashes of the current config contents are needed, so the oldest hashes are forgotten first.
+const maxLegacyContentHashes = 4096
+
+// legacyContentHashes maps the SHA-256 hashes of config contents to the MD5 hashes of the same contents.
+// Pods that were started with the MD5 hash of a config are not restarted until the config actually changes.
+var legacyContentHashes = struct {
+	sync.Mutex
+	md5Hashes map[string]string
+	// The SHA-256 hashes, in the order they were added
+	order []string
+}{md5Hashes: map[string]string{}}
+
+// contentHashAnnotations are the pod annotations that hold the hashes of config contents, along with the annotations with the ConfigMapFileMd5AnnotationPrefix
+var contentHashAnnotations = map[string]bool{
+	SolrXmlMd5Annotation:                     true,
+	LogXmlMd5Annotation:                      true,
+	BasicAuthMd5Annotation:                   true,
+	BackupRepoCredentialsAnnotation:          true,
+	SolrTlsCertMd5Annotation:                 true,
+	SolrClientTlsCertMd5Annotation:           true,
+	SolrTlsTrustBundleMd5Annotation:          true,
+	SolrClientTlsTrustBundleMd5Annotation:    true,
+	PrometheusExporterConfigXmlMd5Annotation: true,
+}
+
+// HashContent returns the hash of config content that is stored in a pod annotation, so that the pods are restarted when the content changes.
+func HashContent(content []byte) string {
+	md5Hash := fmt.Sprintf("%x", md5.Sum(content))
+	if !SHA256ContentHashes() {
+		return md5Hash
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	rememberLegacyContentHash(hash, md5Hash)
+	return hash
+}
+
+func rememberLegacyContentHash(hash string, md5Hash string) {
+	legacyContentHashes.Lock()
+	defer legacyContentHashes.Unlock()
+	if _, remembered := legacyContentHashes.md5Hashes[hash]; remembered {
+		return
+	}
+	if len(legacyContentHashes.order) >= maxLegacyContentHashes {
+		delete(legacyContentHashes.md5Hashes, legacyContentHashes.order[0])
+		legacyContentHashes.order = legacyContentHashes.order[1:]
+	}
+	legacyContentHashes.md5Hashes[hash] = md5Hash
+	legacyContentHashes.order = append(legacyContentHashes.order, hash)
+}
+
+func legacyContentHash(hash string) (md5Hash string, remembered bool) {
+	legacyContentHashes.Lock()
+	defer legacyContentHashes.Unlock()
+	md5Hash, remembered = legacyContentHashes.md5Hashes[hash]
+	return md5Hash, remembered
+}
+
+// PreserveLegacyContentHashes keeps the MD5 content hashes of the existing pod annotations, if the new SHA-256 content hash is of the same content.
+// This way, enabling SHA-256 content hashes does not trigger a rolling restart of every pod managed by the operator.
+// The SHA-256 hash replaces the MD5 hash the next time the content changes, and the pods are restarted anyways.
+// Only the annotations that hold content hashes are considered.
+func PreserveLegacyContentHashes(newAnnotations, existingAnnotations map[string]string) {
+	for key, hash := range newAnnotations {
+		if !contentHashAnnotations[key] && !strings.HasPrefix(key, ConfigMapFileMd5AnnotationPrefix) {
+			continue
+		}
+		if legacyHash, hasLegacyHash := legacyContentHash(hash); hasLegacyHash && existingAnnotations[key] == legacyHash {
+			newAnnotations[key] = existingAnnotations[key]
+		}
+	}
+}
+
+// contentHashes caches the hashes of the contents of ConfigMaps and Secrets, that are used to restart pods when the contents change.
+// The objects are read from the informer cache of the manager, and their resourceVersion changes along with their contents,
+// so each hash only needs to be computed once for every version of an object.
+var contentHashes sync.Map
+
+type contentHashKey struct {
+	uid types.UID
+	key string
+}
+
+type contentHash struct {
+	resourceVersion string
+	sha256          bool
+	hash            string
+}
+
+// ContentHash returns the hash of the content stored under the given key in a ConfigMap or Secret, see HashContent.
+// The content is only read and hashed if the object has changed since the hash was last computed.
+// Objects that have not been read from Kubernetes, and therefore have no UID, are always hashed.
+func ContentHash(obj metav1.Object, key string, content func() []byte) string {
+	if obj.GetUID() == "" || obj.GetResourceVersion() == "" {
+		return HashContent(content())
+	}
+	cacheKey := contentHashKey{uid: obj.GetUID(), key: key}
+	if cached, found := contentHashes.Load(cacheKey); found && cached.(contentHash).resourceVersion == obj.GetResourceVersion() && cached.(contentHash).sha256 == SHA256ContentHashes() {
+		return cached.(contentHash).hash
+	}
+	hash := HashContent(content())
+	contentHashes.Store(cacheKey, contentHash{resourceVersion: obj.GetResourceVersion(), sha256: SHA256ContentHashes(), hash: hash})
+	return hash
+}
+
+// evictContentHashes removes the cached hashes of the object with the given UID
+func evictContentHashes(uid types.UID) {
+	contentHashes.Range(func(cacheKey, _ interface{}) bool {
+		if cacheKey.(contentHashKey).uid == uid {
+			contentHashes.Delete(cacheKey)
+		}
+		return true
+	})
+}
+
+// SetupContentHashCache evicts the cached content hashes of ConfigMaps and Secrets once the informers of the manager observe their deletion
+func SetupContentHashCache(mgr manager.Manager) error {
+	for _, obj := range []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}} {
+		informer, err := mgr.GetCache().GetInformer(context.Background(), obj)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(deleted interface{}) {
+				if tombstone, isTombstone := deleted.(toolscache.DeletedFinalStateUnknown); isTombstone {
+					deleted = tombstone.Obj
+				}
+				if deletedObj, isObj := deleted.(metav1.Object); isObj {
+					evictContentHashes(deletedObj.GetUID())
+				}
+			},
+		})
+	}
+	return nil
+}
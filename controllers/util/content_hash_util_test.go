@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestContentHashCaching(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-config", UID: "content-hash-test", ResourceVersion: "1"},
+		Data:       map[string]string{SolrXmlFile: "<solr/>"},
+	}
+	reads := 0
+	content := func() []byte {
+		reads++
+		return []byte(configMap.Data[SolrXmlFile])
+	}
+	defer evictContentHashes(configMap.UID)
+
+	expected := fmt.Sprintf("%x", md5.Sum([]byte("<solr/>")))
+	assert.Equal(t, expected, ContentHash(configMap, SolrXmlFile, content), "Wrong hash for the ConfigMap content")
+	assert.Equal(t, expected, ContentHash(configMap, SolrXmlFile, content), "Wrong cached hash for the ConfigMap content")
+	assert.Equal(t, 1, reads, "The content should only be hashed once for the same resourceVersion")
+
+	configMap.Data[SolrXmlFile] = "<solr></solr>"
+	configMap.ResourceVersion = "2"
+	assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("<solr></solr>"))), ContentHash(configMap, SolrXmlFile, content), "The hash should be recomputed when the resourceVersion changes")
+	assert.Equal(t, 2, reads, "The content should be hashed again for a new resourceVersion")
+
+	evictContentHashes(configMap.UID)
+	ContentHash(configMap, SolrXmlFile, content)
+	assert.Equal(t, 3, reads, "The content should be hashed again after the cached hashes are evicted")
+
+	uncached := &corev1.ConfigMap{Data: map[string]string{SolrXmlFile: "<solr/>"}}
+	uncachedReads := 0
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, expected, ContentHash(uncached, SolrXmlFile, func() []byte {
+			uncachedReads++
+			return []byte(uncached.Data[SolrXmlFile])
+		}), "Wrong hash for an object without a UID")
+	}
+	assert.Equal(t, 2, uncachedReads, "Objects without a UID should never be cached")
+}
+
+func TestSHA256ContentHashMigration(t *testing.T) {
+	content := []byte("<solr/>")
+	md5Hash := fmt.Sprintf("%x", md5.Sum(content))
+	sha256Hash := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	assert.Equal(t, md5Hash, HashContent(content), "Config contents should be hashed with MD5 by default")
+
+	SetSHA256ContentHashes(true)
+	defer SetSHA256ContentHashes(false)
+	assert.Equal(t, sha256Hash, HashContent(content), "Config contents should be hashed with SHA-256 when enabled")
+
+	annotations := map[string]string{SolrXmlMd5Annotation: sha256Hash, LogXmlMd5Annotation: HashContent([]byte("<Configuration/>"))}
+	PreserveLegacyContentHashes(annotations, map[string]string{SolrXmlMd5Annotation: md5Hash, LogXmlMd5Annotation: fmt.Sprintf("%x", md5.Sum([]byte("<Configuration></Configuration>")))})
+	assert.Equal(t, md5Hash, annotations[SolrXmlMd5Annotation], "The MD5 hash of unchanged content should be kept, to not restart pods")
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte("<Configuration/>"))), annotations[LogXmlMd5Annotation], "The SHA-256 hash of changed content should replace the MD5 hash")
+
+	annotations = map[string]string{SolrXmlMd5Annotation: sha256Hash}
+	PreserveLegacyContentHashes(annotations, map[string]string{})
+	assert.Equal(t, sha256Hash, annotations[SolrXmlMd5Annotation], "The SHA-256 hash should be used when there is no existing hash")
+
+	annotations = map[string]string{ConfigMapFileMd5AnnotationPrefix + "custom": sha256Hash, "custom-annotation": sha256Hash}
+	PreserveLegacyContentHashes(annotations, map[string]string{ConfigMapFileMd5AnnotationPrefix + "custom": md5Hash, "custom-annotation": md5Hash})
+	assert.Equal(t, md5Hash, annotations[ConfigMapFileMd5AnnotationPrefix+"custom"], "The MD5 hash of unchanged config files should be kept")
+	assert.Equal(t, sha256Hash, annotations["custom-annotation"], "Annotations that do not hold content hashes should never be replaced")
+}
+
+func TestLegacyContentHashesAreBounded(t *testing.T) {
+	SetSHA256ContentHashes(true)
+	defer SetSHA256ContentHashes(false)
+
+	first := HashContent([]byte("first"))
+	for i := 0; i < maxLegacyContentHashes; i++ {
+		HashContent([]byte(fmt.Sprintf("content-%d", i)))
+	}
+	_, remembered := legacyContentHash(first)
+	assert.False(t, remembered, "The oldest hashes should be forgotten")
+	_, remembered = legacyContentHash(HashContent([]byte(fmt.Sprintf("content-%d", maxLegacyContentHashes-1))))
+	assert.True(t, remembered, "The latest hashes should be remembered")
+	assert.LessOrEqual(t, len(legacyContentHashes.md5Hashes), maxLegacyContentHashes, "The number of remembered hashes should be bounded")
+}
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Feature is the name of a capability of the Solr Operator that can be enabled or disabled for an entire installation,
+// through a feature gate.
+type Feature string
+
+// FeatureStage describes the maturity of a Feature.
+type FeatureStage string
+
+const (
+	// Alpha features are disabled by default, and may change or be removed in any release.
+	Alpha FeatureStage = "Alpha"
+	// Beta features are well tested, and may be enabled by default.
+	Beta FeatureStage = "Beta"
+
+	// FeatureGatesEnvVar is the environment variable that feature gates can be provided through, as an alternative to the command-line flag.
+	FeatureGatesEnvVar = "SOLR_OPERATOR_FEATURE_GATES"
+
+	// FeatureGatesConfigMapKey is the key of the feature gates, in the ConfigMap that feature gates can be provided through.
+	FeatureGatesConfigMapKey = "featureGates"
+)
+
+// FeatureSpec describes the default and maturity of a Feature.
+type FeatureSpec struct {
+	Default bool
+	Stage   FeatureStage
+}
+
+// knownFeatures contains every Feature that can be gated.
+// New capabilities that are risky, or likely to change, should be added here as Alpha, disabled by default,
+// and only be used when IsFeatureEnabled returns true.
+var knownFeatures = map[Feature]FeatureSpec{}
+
+// featureGates contains the features that have been explicitly enabled or disabled for the operator
+var featureGates = map[Feature]bool{}
+
+// SetFeatureGates enables or disables features, given a comma-separated list of "<Feature>=<true|false>" pairs.
+// Features that were set by earlier calls are overridden, so sources should be applied from least to most specific.
+// An error is returned, and no features are changed, if the list is malformed or contains an unknown feature.
+func SetFeatureGates(gates string) error {
+	parsed := map[Feature]bool{}
+	for _, gate := range strings.Split(gates, ",") {
+		gate = strings.TrimSpace(gate)
+		if gate == "" {
+			continue
+		}
+		parts := strings.SplitN(gate, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("feature gate %q must be of the form <Feature>=<true|false>", gate)
+		}
+		feature := Feature(strings.TrimSpace(parts[0]))
+		if _, known := knownFeatures[feature]; !known {
+			return fmt.Errorf("unknown feature gate %q, known feature gates are: %s", feature, strings.Join(KnownFeatures(), ", "))
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value for feature gate %q: %w", feature, err)
+		}
+		parsed[feature] = enabled
+	}
+	for feature, enabled := range parsed {
+		featureGates[feature] = enabled
+	}
+	return nil
+}
+
+// LoadFeatureGatesConfigMap sets the feature gates found in the "featureGates" key of the given ConfigMap.
+// A ConfigMap that does not exist is ignored, so that it can be created only when it is needed.
+func LoadFeatureGatesConfigMap(ctx context.Context, reader client.Reader, namespace string, name string) error {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := SetFeatureGates(strings.ReplaceAll(configMap.Data[FeatureGatesConfigMapKey], "\n", ",")); err != nil {
+		return fmt.Errorf("invalid feature gates in ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// IsFeatureEnabled returns whether the Feature is enabled for the operator, either explicitly or by default.
+func IsFeatureEnabled(feature Feature) bool {
+	if enabled, set := featureGates[feature]; set {
+		return enabled
+	}
+	return knownFeatures[feature].Default
+}
+
+// KnownFeatures returns the sorted names of all features that can be gated.
+func KnownFeatures() []string {
+	features := make([]string, 0, len(knownFeatures))
+	for feature := range knownFeatures {
+		features = append(features, string(feature))
+	}
+	sort.Strings(features)
+	return features
+}
+
+// FeatureGatesSummary describes whether each known feature is enabled, for logging.
+func FeatureGatesSummary() string {
+	features := KnownFeatures()
+	for i, feature := range features {
+		features[i] = fmt.Sprintf("%s=%t (%s)", feature, IsFeatureEnabled(Feature(feature)), knownFeatures[Feature(feature)].Stage)
+	}
+	return strings.Join(features, ", ")
+}
@@ -0,0 +1,53 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFeatureGates(t *testing.T) {
+	knownFeatures["TestAlphaFeature"] = FeatureSpec{Default: false, Stage: Alpha}
+	knownFeatures["TestBetaFeature"] = FeatureSpec{Default: true, Stage: Beta}
+	defer func() {
+		delete(knownFeatures, "TestAlphaFeature")
+		delete(knownFeatures, "TestBetaFeature")
+		featureGates = map[Feature]bool{}
+	}()
+
+	assert.False(t, IsFeatureEnabled("TestAlphaFeature"), "Alpha feature should be disabled by default")
+	assert.True(t, IsFeatureEnabled("TestBetaFeature"), "Beta feature should use its default")
+	assert.False(t, IsFeatureEnabled("UnknownFeature"), "Unknown features should never be enabled")
+
+	assert.NoError(t, SetFeatureGates(" TestAlphaFeature=true, TestBetaFeature=false,"), "Valid feature gates should be accepted")
+	assert.True(t, IsFeatureEnabled("TestAlphaFeature"), "Feature should be enabled by the feature gate")
+	assert.False(t, IsFeatureEnabled("TestBetaFeature"), "Feature should be disabled by the feature gate")
+	assert.Equal(t, "TestAlphaFeature=true (Alpha), TestBetaFeature=false (Beta)", FeatureGatesSummary(), "Wrong feature gates summary")
+
+	// Later sources override earlier sources, only for the features that they set
+	assert.NoError(t, SetFeatureGates("TestBetaFeature=true"), "Valid feature gates should be accepted")
+	assert.True(t, IsFeatureEnabled("TestAlphaFeature"), "Feature should keep the value of the earlier feature gate")
+	assert.True(t, IsFeatureEnabled("TestBetaFeature"), "Feature should be overridden by the later feature gate")
+
+	// Invalid feature gates do not change any features
+	assert.Error(t, SetFeatureGates("TestAlphaFeature=false,UnknownFeature=true"), "Unknown features should be rejected")
+	assert.Error(t, SetFeatureGates("TestAlphaFeature=false,TestBetaFeature"), "Feature gates without values should be rejected")
+	assert.Error(t, SetFeatureGates("TestAlphaFeature=maybe"), "Feature gates with non-boolean values should be rejected")
+	assert.True(t, IsFeatureEnabled("TestAlphaFeature"), "Invalid feature gates should not change any features")
+}
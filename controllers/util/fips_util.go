@@ -0,0 +1,75 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/tls"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+)
+
+var fipsMode bool
+
+// SetFIPSMode restricts everything the operator generates to FIPS-approved cryptography.
+func SetFIPSMode(enabled bool) {
+	fipsMode = enabled
+}
+
+// FIPSMode returns whether the operator is running in FIPS mode.
+func FIPSMode() bool {
+	return fipsMode
+}
+
+var (
+	// FIPSCipherSuites are the FIPS-approved TLS 1.2 cipher suites supported by Go
+	FIPSCipherSuites = []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+
+	// FIPSCurvePreferences are the FIPS-approved elliptic curves supported by Go
+	FIPSCurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+)
+
+// RestrictTLSConfigForFIPS limits the given TLS config to FIPS-approved versions, cipher suites and curves.
+// TLS 1.3 is disabled, since Go does not allow its cipher suites to be configured.
+func RestrictTLSConfigForFIPS(config *tls.Config) {
+	config.MinVersion = tls.VersionTLS12
+	config.MaxVersion = tls.VersionTLS12
+	config.CipherSuites = FIPSCipherSuites
+	config.CurvePreferences = FIPSCurvePreferences
+}
+
+// ValidateFIPSCompliance returns an error if the SolrCloud is configured in a way that cannot be FIPS compliant.
+// When FIPS mode is disabled, every SolrCloud is valid.
+func ValidateFIPSCompliance(solrCloud *solr.SolrCloud) error {
+	if !fipsMode {
+		return nil
+	}
+	if solrCloud.Spec.SolrTLS == nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "SolrCloud %s must enable TLS, through spec.solrTLS, when the Solr Operator is running in FIPS mode", solrCloud.Name)
+	}
+	// JKS stores are protected with a proprietary algorithm, which is not FIPS-approved
+	if solrCloud.Spec.SolrTLS.StoreType() == solr.JKSKeyStore ||
+		(solrCloud.Spec.SolrClientTLS != nil && solrCloud.Spec.SolrClientTLS.StoreType() == solr.JKSKeyStore) {
+		return TerminalErrorf(InvalidTLSConfigReason, "SolrCloud %s cannot use the %s keyStoreType when the Solr Operator is running in FIPS mode", solrCloud.Name, solr.JKSKeyStore)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+)
+
+// FleetStatus summarizes all SolrClouds managed by the Solr Operator, across namespaces, for platform dashboards
+type FleetStatus struct {
+	// The time that the summary was generated at
+	GeneratedTime metav1.Time `json:"generatedTimestamp"`
+
+	// The number of SolrClouds in each phase
+	Phases map[solr.SolrCloudPhase]int `json:"phases"`
+
+	// The total number of Solr pods that still need to be updated, across all SolrClouds
+	PodsPendingUpdate int32 `json:"podsPendingUpdate"`
+
+	// The summary of each SolrCloud, sorted by namespace and name
+	SolrClouds []SolrCloudFleetStatus `json:"solrClouds"`
+}
+
+// SolrCloudFleetStatus summarizes the status of a single SolrCloud
+type SolrCloudFleetStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	Phase         solr.SolrCloudPhase `json:"phase"`
+	Version       string              `json:"version"`
+	TargetVersion string              `json:"targetVersion,omitempty"`
+
+	DesiredNodes      int32 `json:"desiredNodes"`
+	Nodes             int32 `json:"nodes"`
+	ReadyNodes        int32 `json:"readyNodes"`
+	PodsPendingUpdate int32 `json:"podsPendingUpdate"`
+
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Problems that the Solr Operator has observed with the SolrCloud, such as an invalid configuration or an unavailable Zookeeper
+	Problems []string `json:"problems,omitempty"`
+
+	// The most recently finished SolrBackup of the SolrCloud
+	LastBackup *BackupFleetStatus `json:"lastBackup,omitempty"`
+
+	// The time that the most recent successful SolrBackup of the SolrCloud finished at
+	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTimestamp,omitempty"`
+}
+
+// BackupFleetStatus summarizes a finished SolrBackup
+type BackupFleetStatus struct {
+	Name       string       `json:"name"`
+	FinishTime *metav1.Time `json:"finishTimestamp,omitempty"`
+	Successful bool         `json:"successful"`
+}
+
+// GenerateFleetStatus summarizes the given SolrClouds, and the SolrBackups taken of them
+func GenerateFleetStatus(solrClouds []solr.SolrCloud, backups []solr.SolrBackup, now metav1.Time) FleetStatus {
+	fleetStatus := FleetStatus{
+		GeneratedTime: now,
+		Phases:        map[solr.SolrCloudPhase]int{},
+		SolrClouds:    make([]SolrCloudFleetStatus, 0, len(solrClouds)),
+	}
+
+	for i := range solrClouds {
+		cloudStatus := solrCloudFleetStatus(&solrClouds[i], backups)
+		fleetStatus.Phases[cloudStatus.Phase] += 1
+		fleetStatus.PodsPendingUpdate += cloudStatus.PodsPendingUpdate
+		fleetStatus.SolrClouds = append(fleetStatus.SolrClouds, cloudStatus)
+	}
+	sort.Slice(fleetStatus.SolrClouds, func(i, j int) bool {
+		if fleetStatus.SolrClouds[i].Namespace != fleetStatus.SolrClouds[j].Namespace {
+			return fleetStatus.SolrClouds[i].Namespace < fleetStatus.SolrClouds[j].Namespace
+		}
+		return fleetStatus.SolrClouds[i].Name < fleetStatus.SolrClouds[j].Name
+	})
+
+	return fleetStatus
+}
+
+func solrCloudFleetStatus(solrCloud *solr.SolrCloud, backups []solr.SolrBackup) (cloudStatus SolrCloudFleetStatus) {
+	status := solrCloud.Status
+	cloudStatus = SolrCloudFleetStatus{
+		Namespace:     solrCloud.Namespace,
+		Name:          solrCloud.Name,
+		Phase:         status.Phase,
+		Version:       status.Version,
+		TargetVersion: status.TargetVersion,
+		Nodes:         status.Replicas,
+		ReadyNodes:    status.ReadyReplicas,
+		ReadOnly:      status.ReadOnly,
+	}
+	if solrCloud.Spec.Replicas != nil {
+		cloudStatus.DesiredNodes = *solrCloud.Spec.Replicas
+	}
+	if cloudStatus.Phase == "" {
+		cloudStatus.Phase = status.CalculatePhase(cloudStatus.DesiredNodes)
+	}
+	if status.UpToDateNodes < status.Replicas {
+		cloudStatus.PodsPendingUpdate = status.Replicas - status.UpToDateNodes
+	}
+
+	if condition := meta.FindStatusCondition(status.Conditions, solr.SolrCloudConfigurationValid); condition != nil && condition.Status == metav1.ConditionFalse {
+		cloudStatus.Problems = append(cloudStatus.Problems, condition.Message)
+	}
+	if status.ZookeeperError != "" {
+		cloudStatus.Problems = append(cloudStatus.Problems, status.ZookeeperError)
+	}
+
+	for _, backup := range backups {
+		if backup.Namespace != solrCloud.Namespace || backup.Spec.SolrCloud != solrCloud.Name || !backup.Status.Finished || backup.Status.FinishTime == nil {
+			continue
+		}
+		successful := backup.Status.Successful != nil && *backup.Status.Successful
+		if cloudStatus.LastBackup == nil || cloudStatus.LastBackup.FinishTime.Before(backup.Status.FinishTime) {
+			cloudStatus.LastBackup = &BackupFleetStatus{
+				Name:       backup.Name,
+				FinishTime: backup.Status.FinishTime,
+				Successful: successful,
+			}
+		}
+		if successful && (cloudStatus.LastSuccessfulBackupTime == nil || cloudStatus.LastSuccessfulBackupTime.Before(backup.Status.FinishTime)) {
+			cloudStatus.LastSuccessfulBackupTime = backup.Status.FinishTime
+		}
+	}
+
+	return cloudStatus
+}
@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestGenerateFleetStatus(t *testing.T) {
+	replicas := int32(3)
+	tru := true
+	fals := false
+	now := metav1.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	earliest := metav1.NewTime(now.Add(-time.Hour * 2))
+
+	solrClouds := []solr.SolrCloud{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "search", Name: "updating"},
+			Spec:       solr.SolrCloudSpec{Replicas: &replicas},
+			Status: solr.SolrCloudStatus{
+				Phase:          solr.SolrCloudUpdating,
+				Version:        "8.11",
+				Replicas:       3,
+				ReadyReplicas:  3,
+				UpToDateNodes:  1,
+				ZookeeperError: "Zookeeper is unavailable",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "logs", Name: "ready"},
+			Spec:       solr.SolrCloudSpec{Replicas: &replicas},
+			Status: solr.SolrCloudStatus{
+				Phase:         solr.SolrCloudReady,
+				Version:       "8.11",
+				Replicas:      3,
+				ReadyReplicas: 3,
+				UpToDateNodes: 3,
+			},
+		},
+	}
+	backups := []solr.SolrBackup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "logs", Name: "old"},
+			Spec:       solr.SolrBackupSpec{SolrCloud: "ready"},
+			Status:     solr.SolrBackupStatus{Finished: true, Successful: &tru, FinishTime: &earliest},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "logs", Name: "failed"},
+			Spec:       solr.SolrBackupSpec{SolrCloud: "ready"},
+			Status:     solr.SolrBackupStatus{Finished: true, Successful: &fals, FinishTime: &earlier},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "logs", Name: "in-progress"},
+			Spec:       solr.SolrBackupSpec{SolrCloud: "ready"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "other-namespace"},
+			Spec:       solr.SolrBackupSpec{SolrCloud: "ready"},
+			Status:     solr.SolrBackupStatus{Finished: true, Successful: &tru, FinishTime: &now},
+		},
+	}
+
+	fleetStatus := GenerateFleetStatus(solrClouds, backups, now)
+
+	assert.Equal(t, now, fleetStatus.GeneratedTime, "Wrong generated time")
+	assert.Equal(t, map[solr.SolrCloudPhase]int{solr.SolrCloudUpdating: 1, solr.SolrCloudReady: 1}, fleetStatus.Phases, "Wrong number of SolrClouds per phase")
+	assert.EqualValues(t, 2, fleetStatus.PodsPendingUpdate, "Wrong total number of pods pending update")
+	if assert.Len(t, fleetStatus.SolrClouds, 2, "Every SolrCloud should be summarized") {
+		ready := fleetStatus.SolrClouds[0]
+		assert.Equal(t, "ready", ready.Name, "The SolrClouds should be sorted by namespace")
+		assert.EqualValues(t, 0, ready.PodsPendingUpdate, "No pods of a ready SolrCloud are pending update")
+		assert.Empty(t, ready.Problems, "A ready SolrCloud has no problems")
+		if assert.NotNil(t, ready.LastBackup, "The last backup should be found") {
+			assert.Equal(t, "failed", ready.LastBackup.Name, "The last backup should be the most recently finished backup of the SolrCloud, from its namespace")
+			assert.False(t, ready.LastBackup.Successful, "The last backup failed")
+		}
+		assert.Equal(t, &earliest, ready.LastSuccessfulBackupTime, "Wrong last successful backup time")
+
+		updating := fleetStatus.SolrClouds[1]
+		assert.Equal(t, "updating", updating.Name, "The SolrClouds should be sorted by namespace")
+		assert.EqualValues(t, 3, updating.DesiredNodes, "Wrong desired nodes")
+		assert.EqualValues(t, 2, updating.PodsPendingUpdate, "Wrong number of pods pending update")
+		assert.Equal(t, []string{"Zookeeper is unavailable"}, updating.Problems, "The Zookeeper error should be listed as a problem")
+		assert.Nil(t, updating.LastBackup, "The SolrCloud has no backups")
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var defaultImagePullSecrets []corev1.LocalObjectReference
+
+// SetDefaultImagePullSecrets sets the names of the pull secrets that are added to every workload the operator generates,
+// for environments where all images are pulled from a private registry.
+func SetDefaultImagePullSecrets(secretNames []string) {
+	defaultImagePullSecrets = nil
+	for _, secretName := range secretNames {
+		if secretName != "" {
+			defaultImagePullSecrets = append(defaultImagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+		}
+	}
+}
+
+// withImagePullSecrets adds the pull secrets of the given images, and the operator-wide default pull secrets,
+// to the pull secrets of a pod. Pull secrets that the pod already uses are not added again.
+func withImagePullSecrets(imagePullSecrets []corev1.LocalObjectReference, images ...*solr.ContainerImage) []corev1.LocalObjectReference {
+	additionalSecrets := make([]corev1.LocalObjectReference, 0, len(images)+len(defaultImagePullSecrets))
+	for _, image := range images {
+		if image != nil && image.ImagePullSecret != "" {
+			additionalSecrets = append(additionalSecrets, corev1.LocalObjectReference{Name: image.ImagePullSecret})
+		}
+	}
+	additionalSecrets = append(additionalSecrets, defaultImagePullSecrets...)
+
+	for _, secret := range additionalSecrets {
+		found := false
+		for _, existing := range imagePullSecrets {
+			if existing.Name == secret.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			imagePullSecrets = append(imagePullSecrets, secret)
+		}
+	}
+	return imagePullSecrets
+}
@@ -0,0 +1,272 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package util
+
+import (
+	"strconv"
+	"strings"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IndexingBridgeContainerName = "solr-indexing-bridge"
+
+	IndexingBridgeConsumerPropertiesPath = "/etc/solr-indexing-bridge"
+	IndexingBridgeConsumerPropertiesFile = "consumer.properties"
+)
+
+// GenerateSolrIndexingBridgeDeployment returns a new appsv1.Deployment pointer generated for the SolrIndexingBridge instance
+// The bridge container is configured entirely through environment variables, so that any bridge image following the documented contract can be used.
+func GenerateSolrIndexingBridgeDeployment(bridge *solr.SolrIndexingBridge, solrConnectionInfo SolrConnectionInfo, tls *TLSCerts, basicAuthMd5 string) *appsv1.Deployment {
+	labels := bridge.SharedLabelsWith(bridge.GetLabels())
+	var annotations map[string]string
+	selectorLabels := bridge.SharedLabels()
+
+	labels["technology"] = solr.SolrIndexingBridgeTechnologyLabel
+	selectorLabels["technology"] = solr.SolrIndexingBridgeTechnologyLabel
+
+	podLabels := labels
+	var podAnnotations map[string]string
+	var imagePullSecrets []corev1.LocalObjectReference
+
+	customDeploymentOptions := bridge.Spec.CustomKubeOptions.DeploymentOptions
+	if nil != customDeploymentOptions {
+		labels = MergeLabelsOrAnnotations(labels, customDeploymentOptions.Labels)
+		annotations = customDeploymentOptions.Annotations
+	}
+
+	customPodOptions := bridge.Spec.CustomKubeOptions.PodOptions
+	if nil != customPodOptions {
+		podLabels = MergeLabelsOrAnnotations(podLabels, customPodOptions.Labels)
+		podAnnotations = customPodOptions.Annotations
+		imagePullSecrets = customPodOptions.ImagePullSecrets
+	}
+
+	var allJavaOpts []string
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+
+	kafka := bridge.Spec.Kafka
+	envVars := []corev1.EnvVar{
+		{Name: "KAFKA_BOOTSTRAP_SERVERS", Value: kafka.BootstrapServers},
+		{Name: "KAFKA_TOPICS", Value: strings.Join(kafka.Topics, ",")},
+		{Name: "KAFKA_CONSUMER_GROUP", Value: kafka.ConsumerGroup},
+		{Name: "SOLR_COLLECTION", Value: bridge.Spec.Collection},
+		{Name: "SOLR_BATCH_SIZE", Value: strconv.Itoa(int(bridge.Spec.BatchSize))},
+	}
+	if bridge.Spec.CommitWithinMs != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_COMMIT_WITHIN_MS", Value: strconv.Itoa(int(*bridge.Spec.CommitWithinMs))})
+	}
+
+	// Mount the additional consumer properties (e.g. SASL settings) as a file, since they often contain multi-line JAAS configs
+	if kafka.ConsumerPropertiesSecret != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "kafka-consumer-properties",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: kafka.ConsumerPropertiesSecret.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  kafka.ConsumerPropertiesSecret.Key,
+							Path: IndexingBridgeConsumerPropertiesFile,
+						},
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "kafka-consumer-properties", MountPath: IndexingBridgeConsumerPropertiesPath, ReadOnly: true})
+		envVars = append(envVars, corev1.EnvVar{Name: "KAFKA_CONSUMER_PROPERTIES_FILE", Value: IndexingBridgeConsumerPropertiesPath + "/" + IndexingBridgeConsumerPropertiesFile})
+	}
+
+	// Setup the solrConnectionInfo
+	if solrConnectionInfo.CloudZkConnnectionInfo != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_ZK_HOST", Value: solrConnectionInfo.CloudZkConnnectionInfo.ZkConnectionString()})
+
+		// Add ACL information, if given, through Env Vars
+		if hasACLs, aclEnvs := AddACLsToEnv(solrConnectionInfo.CloudZkConnnectionInfo.AllACL, solrConnectionInfo.CloudZkConnnectionInfo.ReadOnlyACL); hasACLs {
+			envVars = append(envVars, aclEnvs...)
+			allJavaOpts = append(allJavaOpts, "$(SOLR_ZK_CREDS_AND_ACLS)")
+		}
+	} else if solrConnectionInfo.StandaloneAddress != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_URL", Value: solrConnectionInfo.StandaloneAddress})
+	}
+
+	// Add Custom EnvironmentVariables to the bridge container
+	if nil != customPodOptions {
+		envVars = append(envVars, customPodOptions.EnvVariables...)
+
+		// Add Custom Volumes to pod
+		for _, volume := range customPodOptions.Volumes {
+			// Only add the container mount if one has been provided.
+			if volume.DefaultContainerMount != nil {
+				volume.DefaultContainerMount.Name = volume.Name
+				volumeMounts = append(volumeMounts, *volume.DefaultContainerMount)
+			}
+
+			volumes = append(volumes, corev1.Volume{
+				Name:         volume.Name,
+				VolumeSource: volume.Source,
+			})
+		}
+	}
+
+	// basic auth enabled?
+	if bridge.Spec.SolrReference.BasicAuthSecret != "" {
+		lor := corev1.LocalObjectReference{Name: bridge.Spec.SolrReference.BasicAuthSecret}
+		usernameRef := &corev1.SecretKeySelector{LocalObjectReference: lor, Key: corev1.BasicAuthUsernameKey}
+		passwordRef := &corev1.SecretKeySelector{LocalObjectReference: lor, Key: corev1.BasicAuthPasswordKey}
+		envVars = append(envVars, corev1.EnvVar{Name: "BASIC_AUTH_USER", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: usernameRef}})
+		envVars = append(envVars, corev1.EnvVar{Name: "BASIC_AUTH_PASS", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordRef}})
+		allJavaOpts = append(allJavaOpts, "-Dbasicauth=$(BASIC_AUTH_USER):$(BASIC_AUTH_PASS)")
+		allJavaOpts = append(allJavaOpts, "-Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.PreemptiveBasicAuthClientBuilderFactory")
+	}
+
+	// the order of env vars in the array is important for the $(var) syntax to work
+	if len(allJavaOpts) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "JAVA_OPTS", Value: strings.Join(allJavaOpts, " ")})
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            IndexingBridgeContainerName,
+			Image:           bridge.Spec.Image.ToImageName(),
+			ImagePullPolicy: bridge.Spec.Image.PullPolicy,
+			VolumeMounts:    volumeMounts,
+			Env:             envVars,
+		},
+	}
+
+	var initContainers []corev1.Container
+	if customPodOptions != nil {
+		if len(customPodOptions.SidecarContainers) > 0 {
+			containers = append(containers, customPodOptions.SidecarContainers...)
+		}
+
+		if len(customPodOptions.InitContainers) > 0 {
+			initContainers = customPodOptions.InitContainers
+		}
+	}
+
+	// if the basic-auth secret changes, we want to restart the deployment pods
+	if basicAuthMd5 != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[BasicAuthMd5Annotation] = basicAuthMd5
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        bridge.DeploymentName(),
+			Namespace:   bridge.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+			Replicas: bridge.Spec.Replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: podAnnotations,
+				},
+				Spec: corev1.PodSpec{
+					Volumes:        volumes,
+					InitContainers: initContainers,
+					Containers:     containers,
+				},
+			},
+		},
+	}
+
+	if bridge.Spec.Image.ImagePullSecret != "" {
+		imagePullSecrets = append(
+			imagePullSecrets,
+			corev1.LocalObjectReference{Name: bridge.Spec.Image.ImagePullSecret},
+		)
+	}
+
+	deployment.Spec.Template.Spec.ImagePullSecrets = withImagePullSecrets(imagePullSecrets)
+
+	if nil != customPodOptions {
+		bridgeContainer := &deployment.Spec.Template.Spec.Containers[0]
+		if customPodOptions.ServiceAccountName != "" {
+			deployment.Spec.Template.Spec.ServiceAccountName = customPodOptions.ServiceAccountName
+		}
+
+		if customPodOptions.Affinity != nil {
+			deployment.Spec.Template.Spec.Affinity = customPodOptions.Affinity
+		}
+
+		if customPodOptions.Resources.Limits != nil || customPodOptions.Resources.Requests != nil {
+			bridgeContainer.Resources = customPodOptions.Resources
+		}
+
+		if customPodOptions.PodSecurityContext != nil {
+			deployment.Spec.Template.Spec.SecurityContext = customPodOptions.PodSecurityContext
+		}
+
+		if customPodOptions.Tolerations != nil {
+			deployment.Spec.Template.Spec.Tolerations = customPodOptions.Tolerations
+		}
+
+		if customPodOptions.NodeSelector != nil {
+			deployment.Spec.Template.Spec.NodeSelector = customPodOptions.NodeSelector
+		}
+
+		if customPodOptions.PriorityClassName != "" {
+			deployment.Spec.Template.Spec.PriorityClassName = customPodOptions.PriorityClassName
+		}
+
+		if customPodOptions.TerminationGracePeriodSeconds != nil {
+			deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = customPodOptions.TerminationGracePeriodSeconds
+		}
+
+		// The bridge image is provided by the user, so there are no default probes to customize
+		if customPodOptions.ReadinessProbe != nil {
+			bridgeContainer.ReadinessProbe = customizeProbe(&corev1.Probe{}, *customPodOptions.ReadinessProbe)
+		}
+
+		if customPodOptions.StartupProbe != nil {
+			bridgeContainer.StartupProbe = customizeProbe(&corev1.Probe{}, *customPodOptions.StartupProbe)
+		}
+
+		if customPodOptions.LivenessProbe != nil {
+			bridgeContainer.LivenessProbe = customizeProbe(&corev1.Probe{}, *customPodOptions.LivenessProbe)
+		}
+
+		if customPodOptions.Lifecycle != nil {
+			bridgeContainer.Lifecycle = customPodOptions.Lifecycle
+		}
+	}
+
+	// Enrich the deployment definition to allow the bridge to make requests to TLS enabled Solr pods
+	if tls != nil && tls.ClientConfig != nil {
+		tls.enableTLSOnIndexingBridgeDeployment(deployment)
+	}
+
+	// Set after TLS is enabled, since that can add more init containers
+	setDefaultInitContainerResources(&deployment.Spec.Template.Spec, customPodOptions)
+
+	return deployment
+}
@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestGenerateSolrIndexingBridgeDeployment(t *testing.T) {
+	bridge := &solr.SolrIndexingBridge{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "products",
+			Namespace: "default",
+		},
+		Spec: solr.SolrIndexingBridgeSpec{
+			SolrReference: solr.SolrReference{
+				Cloud:           &solr.SolrCloudReference{Name: "foo"},
+				BasicAuthSecret: "foo-solrcloud-basic-auth",
+			},
+			Collection: "products",
+			Kafka: solr.KafkaSourceOptions{
+				BootstrapServers: "kafka:9092",
+				Topics:           []string{"products", "prices"},
+				ConsumerPropertiesSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "kafka-client"},
+					Key:                  "consumer.properties",
+				},
+			},
+			Image: &solr.ContainerImage{Repository: "example/solr-kafka-bridge"},
+		},
+	}
+	assert.True(t, bridge.WithDefaults(), "Defaults should have been applied")
+
+	zkInfo := &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/foo"}
+	deployment := GenerateSolrIndexingBridgeDeployment(bridge, SolrConnectionInfo{CloudZkConnnectionInfo: zkInfo}, nil, "abc")
+
+	assert.Equal(t, "products-solr-indexing-bridge", deployment.Name, "Wrong deployment name")
+	assert.EqualValues(t, 1, *deployment.Spec.Replicas, "Wrong default number of replicas")
+	assert.Equal(t, "abc", deployment.Spec.Template.Annotations[BasicAuthMd5Annotation], "The basic auth md5 should be tracked on the pod template")
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "example/solr-kafka-bridge:latest", container.Image, "Wrong bridge image")
+
+	envVars := make(map[string]corev1.EnvVar, len(container.Env))
+	for _, envVar := range container.Env {
+		envVars[envVar.Name] = envVar
+	}
+	assert.Equal(t, "kafka:9092", envVars["KAFKA_BOOTSTRAP_SERVERS"].Value, "Wrong Kafka bootstrap servers")
+	assert.Equal(t, "products,prices", envVars["KAFKA_TOPICS"].Value, "Wrong Kafka topics")
+	assert.Equal(t, "products-solr-indexing-bridge", envVars["KAFKA_CONSUMER_GROUP"].Value, "Wrong default consumer group")
+	assert.Equal(t, "/etc/solr-indexing-bridge/consumer.properties", envVars["KAFKA_CONSUMER_PROPERTIES_FILE"].Value, "Wrong consumer properties file")
+	assert.Equal(t, "zk:2181/foo", envVars["SOLR_ZK_HOST"].Value, "Wrong ZK connection string")
+	assert.Equal(t, "products", envVars["SOLR_COLLECTION"].Value, "Wrong collection")
+	assert.Equal(t, "500", envVars["SOLR_BATCH_SIZE"].Value, "Wrong default batch size")
+	assert.NotContains(t, envVars, "SOLR_COMMIT_WITHIN_MS", "No commitWithin should be passed unless provided")
+	assert.NotNil(t, envVars["BASIC_AUTH_PASS"].ValueFrom, "The basic auth password should be read from the secret")
+	assert.Equal(t, "JAVA_OPTS", container.Env[len(container.Env)-1].Name, "JAVA_OPTS must be the last env var")
+	assert.Len(t, deployment.Spec.Template.Spec.Volumes, 1, "The consumer properties secret should be mounted")
+}
@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// metricSeries keeps track of the metric series that the operator has exported for each resource, such as the collections of a SolrCloud,
+// so that the series that are no longer exported, or that belong to deleted resources, can be removed.
+// It also keeps the time of the last refresh of each resource, for metrics that are refreshed periodically.
+type metricSeries struct {
+	lock      sync.Mutex
+	resources map[string]*exportedSeries
+	// deleteSeries removes the metrics of a single series, given the resource and the key of the series
+	deleteSeries func(namespace string, name string, key string)
+}
+
+type exportedSeries struct {
+	keys        map[string]bool
+	lastRefresh time.Time
+}
+
+func newMetricSeries(deleteSeries func(namespace string, name string, key string)) *metricSeries {
+	return &metricSeries{
+		resources:    map[string]*exportedSeries{},
+		deleteSeries: deleteSeries,
+	}
+}
+
+// untilRefresh returns how long to wait until the series of the resource should be refreshed, or 0 if a refresh is due
+func (s *metricSeries) untilRefresh(namespace string, name string, refreshInterval time.Duration) time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if exported, hasExported := s.resources[namespace+"/"+name]; hasExported {
+		if sinceRefresh := time.Since(exported.lastRefresh); sinceRefresh < refreshInterval {
+			return refreshInterval - sinceRefresh
+		}
+	}
+	return 0
+}
+
+// tracked returns whether any series have been exported for the resource
+func (s *metricSeries) tracked(namespace string, name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, hasExported := s.resources[namespace+"/"+name]
+	return hasExported
+}
+
+// refreshed records the series that were exported for the resource at a refresh, and removes the series that were not
+func (s *metricSeries) refreshed(namespace string, name string, keys map[string]bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resourceKey := namespace + "/" + name
+	if exported, hasExported := s.resources[resourceKey]; hasExported {
+		for key := range exported.keys {
+			if !keys[key] {
+				s.deleteSeries(namespace, name, key)
+			}
+		}
+	}
+	s.resources[resourceKey] = &exportedSeries{keys: keys, lastRefresh: time.Now()}
+}
+
+// remove removes every series of the resource, for resources that are deleted or no longer export metrics
+func (s *metricSeries) remove(namespace string, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resourceKey := namespace + "/" + name
+	if exported, hasExported := s.resources[resourceKey]; hasExported {
+		for key := range exported.keys {
+			s.deleteSeries(namespace, name, key)
+		}
+	}
+	delete(s.resources, resourceKey)
+}
@@ -0,0 +1,44 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricSeries(t *testing.T) {
+	var deleted []string
+	series := newMetricSeries(func(namespace string, name string, key string) {
+		deleted = append(deleted, namespace+"/"+name+"/"+key)
+	})
+
+	assert.Zero(t, series.untilRefresh("default", "foo", time.Minute), "Series that were never exported should be refreshed")
+	series.refreshed("default", "foo", map[string]bool{"a": true, "b": true})
+	assert.InDelta(t, time.Minute.Seconds(), series.untilRefresh("default", "foo", time.Minute).Seconds(), 1, "The next refresh should wait for the interval")
+	assert.Empty(t, deleted)
+
+	series.refreshed("default", "foo", map[string]bool{"b": true})
+	assert.Equal(t, []string{"default/foo/a"}, deleted, "Series that are no longer exported should be removed")
+
+	series.remove("default", "foo")
+	assert.Equal(t, []string{"default/foo/a", "default/foo/b"}, deleted, "Every series of a removed resource should be removed")
+	assert.Zero(t, series.untilRefresh("default", "foo", time.Minute), "A removed resource should be refreshed again")
+}
@@ -0,0 +1,247 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MigrationBackupRepository returns the backup repository of the SolrCloud that a SolrMigration exports the collections to.
+// Only GCS repositories can be shared with a Solr cluster running in another Kubernetes cluster.
+func MigrationBackupRepository(cloud *solr.SolrCloud, migration *solr.SolrMigration) (*solr.SolrBackupRepository, error) {
+	backupRepository := GetBackupRepositoryByName(cloud.Spec.BackupRepositories, migration.Spec.RepositoryName)
+	if backupRepository == nil {
+		return nil, TerminalErrorf(InvalidSpecReason, "SolrCloud %s must define the backup repository %q (or have only 1 repository defined) to migrate collections through", cloud.Name, migration.Spec.RepositoryName)
+	}
+	if backupRepository.GCS == nil {
+		return nil, TerminalErrorf(InvalidSpecReason, "the backup repository %q of SolrCloud %s must be a GCS repository, so that the source Solr cluster can export collections to it", backupRepository.Name, cloud.Name)
+	}
+	return backupRepository, nil
+}
+
+// GenerateQueryParamsForMigrationExport returns the parameters of the BACKUP call, to the source Solr cluster, that exports a collection.
+// The backup is written to the location that the SolrRestore of the migration reads it from.
+func GenerateQueryParamsForMigrationExport(backupRepository *solr.SolrBackupRepository, migration *solr.SolrMigration, collection string) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "BACKUP")
+	queryParams.Add("collection", collection)
+	// Restores of backups taken by the Solr Operator expect the backup to be named after the collection
+	queryParams.Add("name", collection)
+	queryParams.Add("async", migration.AsyncId(collection))
+	queryParams.Add("location", BackupLocationPath(backupRepository, migration.Name))
+	queryParams.Add("repository", migration.Spec.Source.RepositoryName)
+	return queryParams
+}
+
+func StartExportForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, migration *solr.SolrMigration, collection string, httpHeaders map[string]string, logger logr.Logger) (success bool, err error) {
+	queryParams := GenerateQueryParamsForMigrationExport(backupRepository, migration, collection)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	logger.Info("Calling to start collection export", "source", migration.Spec.Source.URL, "collection", collection)
+	err = callSourceCollectionsApi(cloud, migration, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		if resp.ResponseHeader.Status == 0 {
+			success = true
+		} else {
+			_, err = solr_api.CheckForCollectionsApiError("BACKUP", resp.ResponseHeader)
+		}
+	} else {
+		logger.Error(err, "Error starting collection export", "source", migration.Spec.Source.URL, "collection", collection)
+	}
+
+	return success, err
+}
+
+func CheckExportForCollection(cloud *solr.SolrCloud, migration *solr.SolrMigration, collection string, httpHeaders map[string]string, logger logr.Logger) (finished bool, success bool, asyncStatus string, err error) {
+	logger.Info("Calling to check on collection export", "source", migration.Spec.Source.URL, "collection", collection)
+	queryParams := url.Values{}
+	queryParams.Add("action", "REQUESTSTATUS")
+	queryParams.Add("requestid", migration.AsyncId(collection))
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = callSourceCollectionsApi(cloud, migration, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("REQUESTSTATUS", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error checking on collection export", "source", migration.Spec.Source.URL, "collection", collection)
+		return false, false, "", err
+	}
+	finished, success, asyncStatus = asyncRequestState(resp)
+
+	return finished, success, asyncStatus, nil
+}
+
+func DeleteAsyncInfoForExport(cloud *solr.SolrCloud, migration *solr.SolrMigration, collection string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	logger.Info("Calling to delete async info for export command.", "source", migration.Spec.Source.URL, "collection", collection)
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETESTATUS")
+	queryParams.Add("requestid", migration.AsyncId(collection))
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = callSourceCollectionsApi(cloud, migration, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETESTATUS", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error deleting async data for collection export", "source", migration.Spec.Source.URL, "collection", collection)
+	}
+
+	return err
+}
+
+// DecommissionSourceCollection deletes a migrated collection from the source Solr cluster
+func DecommissionSourceCollection(cloud *solr.SolrCloud, migration *solr.SolrMigration, collection string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETE")
+	queryParams.Add("name", collection)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	logger.Info("Calling to delete migrated collection from the source", "source", migration.Spec.Source.URL, "collection", collection)
+	if err = callSourceCollectionsApi(cloud, migration, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETE", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error deleting migrated collection from the source", "source", migration.Spec.Source.URL, "collection", collection)
+	}
+
+	return err
+}
+
+// SetSourceCollectionReadOnly sets the read-only mode of a collection in the source Solr cluster, through the MODIFYCOLLECTION Collections API action.
+// Collections are made read-only before they are exported, so that no updates are lost between the export and the cutover.
+func SetSourceCollectionReadOnly(cloud *solr.SolrCloud, migration *solr.SolrMigration, collection string, readOnly bool, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "MODIFYCOLLECTION")
+	queryParams.Add("collection", collection)
+	queryParams.Add("readOnly", strconv.FormatBool(readOnly))
+
+	resp := &solr_api.SolrAsyncResponse{}
+	logger.Info("Setting read-only mode of the collection in the source", "source", migration.Spec.Source.URL, "collection", collection, "readOnly", readOnly)
+	if err = callSourceCollectionsApi(cloud, migration, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("MODIFYCOLLECTION", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error setting read-only mode of the collection in the source", "source", migration.Spec.Source.URL, "collection", collection, "readOnly", readOnly)
+	}
+
+	return err
+}
+
+// CountMigratedDocuments counts the documents of a collection in the source Solr cluster, and of the collection that it was migrated to in the SolrCloud.
+// The source collection is read-only from before its export, so both counts are expected to be equal.
+func CountMigratedDocuments(cloud *solr.SolrCloud, migration *solr.SolrMigration, collectionStatus *solr.CollectionMigrationStatus, sourceHeaders map[string]string, httpHeaders map[string]string) (sourceCount int64, targetCount int64, err error) {
+	if sourceCount, err = countDocumentsOnNode(cloud, strings.TrimSuffix(migration.Spec.Source.URL, "/"), collectionStatus.Collection, sourceHeaders); err != nil {
+		return 0, 0, err
+	}
+	targetCount, err = countCollectionDocuments(cloud, collectionStatus.Target, httpHeaders)
+	return sourceCount, targetCount, err
+}
+
+// callSourceCollectionsApi sends a request to the Collections API of the source Solr cluster of a SolrMigration.
+// The HTTP client of the target SolrCloud is used, so the source must trust the same client certificate, if it requires one.
+// Errors reported by the Collections API are returned in the response header of the response, rather than as an error, so that they can be told apart from connection errors.
+func callSourceCollectionsApi(cloud *solr.SolrCloud, migration *solr.SolrMigration, urlParams url.Values, httpHeaders map[string]string, response interface{}) error {
+	body, err := solr_api.CallNodeAdminApi(cloud, strings.TrimSuffix(migration.Spec.Source.URL, "/"), "/solr/admin/collections", urlParams, httpHeaders)
+	if err != nil {
+		// Solr responds to failed Collections API calls with an error code, but the body still contains the response header
+		header := &solr_api.SolrAsyncResponse{}
+		if json.Unmarshal(body, header) != nil || header.ResponseHeader.Status == 0 {
+			return err
+		}
+	}
+	return json.Unmarshal(body, response)
+}
+
+// CreateAliasForCollection creates, or repoints, an alias in the SolrCloud to a migrated collection
+func CreateAliasForCollection(cloud *solr.SolrCloud, alias string, collection string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "CREATEALIAS")
+	queryParams.Add("name", alias)
+	queryParams.Add("collections", collection)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	logger.Info("Calling to create alias for migrated collection", "solrCloud", cloud.Name, "alias", alias, "collection", collection)
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("CREATEALIAS", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error creating alias for migrated collection", "solrCloud", cloud.Name, "alias", alias, "collection", collection)
+	}
+
+	return err
+}
+
+// CheckStatusOfCollectionExports returns whether the exports of all collections have finished, and whether they were all successful
+func CheckStatusOfCollectionExports(migration *solr.SolrMigration) (allFinished bool, allSuccessful bool) {
+	allFinished = len(migration.Status.CollectionMigrationStatuses) > 0
+	allSuccessful = allFinished
+	for _, collectionStatus := range migration.Status.CollectionMigrationStatuses {
+		failed := !collectionStatus.ExportInProgress && collectionStatus.Message != ""
+		allFinished = allFinished && (collectionStatus.Exported || failed)
+		allSuccessful = allSuccessful && collectionStatus.Exported
+	}
+	return allFinished, allFinished && allSuccessful
+}
+
+// GenerateSolrRestoreForMigration returns the SolrRestore that restores the collections exported by a SolrMigration into its SolrCloud
+func GenerateSolrRestoreForMigration(migration *solr.SolrMigration, backupRepository *solr.SolrBackupRepository) *solr.SolrRestore {
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migration.SolrRestoreName(),
+			Namespace: migration.Namespace,
+			Labels:    migration.GetLabels(),
+		},
+		Spec: solr.SolrRestoreSpec{
+			SolrCloud:      migration.Spec.SolrCloud,
+			RepositoryName: backupRepository.Name,
+			BackupName:     migration.Name,
+		},
+	}
+	for _, collectionStatus := range migration.Status.CollectionMigrationStatuses {
+		restore.Spec.Collections = append(restore.Spec.Collections, solr.SolrRestoreCollection{
+			Name:   collectionStatus.Collection,
+			Target: collectionStatus.Target,
+		})
+	}
+	return restore
+}
+
+// UpdateMigrationFromRestore records which collections the SolrRestore of a SolrMigration has restored.
+// Whether the SolrRestore has finished, and whether it restored every collection, is returned.
+func UpdateMigrationFromRestore(migration *solr.SolrMigration, restore *solr.SolrRestore) (finished bool, successful bool) {
+	for i := range migration.Status.CollectionMigrationStatuses {
+		collectionStatus := &migration.Status.CollectionMigrationStatuses[i]
+		for _, restoreStatus := range restore.Status.CollectionRestoreStatuses {
+			if restoreStatus.Target != collectionStatus.Target || !restoreStatus.Finished {
+				continue
+			}
+			collectionStatus.Restored = restoreStatus.Successful != nil && *restoreStatus.Successful
+			if !collectionStatus.Restored {
+				collectionStatus.Message = "The collection could not be restored into the SolrCloud, see SolrRestore " + restore.Name
+			}
+		}
+	}
+	return restore.Status.Finished, restore.Status.Successful != nil && *restore.Status.Successful
+}
@@ -0,0 +1,183 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func migrationTestSolrMigration() *solr.SolrMigration {
+	migration := &solr.SolrMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default"},
+		Spec: solr.SolrMigrationSpec{
+			SolrCloud: "target",
+			Source: solr.SolrMigrationSource{
+				URL:            "https://solr.old-cluster.example.com",
+				RepositoryName: "source-gcs",
+			},
+			Collections: []solr.SolrMigrationCollection{
+				{Name: "col1", Alias: "col1-alias"},
+				{Name: "col2", Target: "col2-v2"},
+			},
+		},
+	}
+	migration.WithDefaults()
+	for _, collection := range migration.Spec.Collections {
+		migration.Status.CollectionMigrationStatuses = append(migration.Status.CollectionMigrationStatuses, solr.CollectionMigrationStatus{
+			Collection: collection.Name,
+			Target:     collection.Target,
+		})
+	}
+	return migration
+}
+
+func TestMigrationBackupRepository(t *testing.T) {
+	migration := migrationTestSolrMigration()
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "target"},
+		Spec: solr.SolrCloudSpec{
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "gcs", GCS: &solr.GcsRepository{Bucket: "migrations", BaseLocation: "/moves"}},
+			},
+		},
+	}
+
+	repo, err := MigrationBackupRepository(cloud, migration)
+	if assert.NoError(t, err, "The only repository of the SolrCloud should be used by default") {
+		assert.Equal(t, "gcs", repo.Name)
+	}
+
+	migration.Spec.RepositoryName = "missing"
+	_, err = MigrationBackupRepository(cloud, migration)
+	assert.Error(t, err, "The repository must be defined by the SolrCloud")
+
+	migration.Spec.RepositoryName = "local"
+	cloud.Spec.BackupRepositories = append(cloud.Spec.BackupRepositories, solr.SolrBackupRepository{Name: "local", Managed: &solr.ManagedRepository{Volume: corev1.VolumeSource{}}})
+	_, err = MigrationBackupRepository(cloud, migration)
+	assert.Error(t, err, "Managed repositories cannot be shared with another Kubernetes cluster")
+}
+
+func TestSolrMigrationExportApiParams(t *testing.T) {
+	migration := migrationTestSolrMigration()
+	gcsRepository := &solr.SolrBackupRepository{Name: "gcs", GCS: &solr.GcsRepository{Bucket: "migrations", BaseLocation: "/moves"}}
+
+	queryParams := GenerateQueryParamsForMigrationExport(gcsRepository, migration, "col1")
+	assert.Equalf(t, "BACKUP", queryParams.Get("action"), "Wrong %s for Collections API Call", "action")
+	assert.Equalf(t, "col1", queryParams.Get("collection"), "Wrong %s for Collections API Call", "collection name")
+	assert.Equalf(t, "col1", queryParams.Get("name"), "Wrong %s for Collections API Call", "backup name")
+	assert.Equalf(t, "move-migrate-col1", queryParams.Get("async"), "Wrong %s for Collections API Call", "async id")
+	assert.Equalf(t, "/moves", queryParams.Get("location"), "Wrong %s for Collections API Call", "backup location")
+	assert.Equalf(t, "source-gcs", queryParams.Get("repository"), "The repository of the source Solr cluster should be used for the %s", "export")
+
+	// The SolrRestore must read the backups from where they were exported to
+	restore := GenerateSolrRestoreForMigration(migration, gcsRepository)
+	assert.Equal(t, "move-migration", restore.Name)
+	assert.Equal(t, "target", restore.Spec.SolrCloud)
+	assert.Equal(t, []solr.SolrRestoreCollection{{Name: "col1", Target: "col1"}, {Name: "col2", Target: "col2-v2"}}, restore.Spec.Collections)
+	restoreParams := GenerateQueryParamsForRestore(gcsRepository, restore, restore.Spec.BackupName, restore.Spec.Collections[0])
+	assert.Equal(t, queryParams.Get("location"), restoreParams.Get("location"), "The restore should read the exported backup")
+	assert.Equal(t, queryParams.Get("name"), restoreParams.Get("name"), "The restore should read the exported backup")
+}
+
+func TestCheckStatusOfCollectionExports(t *testing.T) {
+	migration := migrationTestSolrMigration()
+	allFinished, _ := CheckStatusOfCollectionExports(migration)
+	assert.False(t, allFinished, "No collections have been exported")
+
+	migration.Status.CollectionMigrationStatuses[0].Exported = true
+	migration.Status.CollectionMigrationStatuses[1].ExportInProgress = true
+	allFinished, _ = CheckStatusOfCollectionExports(migration)
+	assert.False(t, allFinished, "A collection is still being exported")
+
+	migration.Status.CollectionMigrationStatuses[1].ExportInProgress = false
+	migration.Status.CollectionMigrationStatuses[1].Message = "failed"
+	allFinished, allSuccessful := CheckStatusOfCollectionExports(migration)
+	assert.True(t, allFinished, "All exports have finished")
+	assert.False(t, allSuccessful, "A collection could not be exported")
+
+	migration.Status.CollectionMigrationStatuses[1].Message = ""
+	migration.Status.CollectionMigrationStatuses[1].Exported = true
+	allFinished, allSuccessful = CheckStatusOfCollectionExports(migration)
+	assert.True(t, allFinished && allSuccessful, "All collections have been exported")
+}
+
+func TestUpdateMigrationFromRestore(t *testing.T) {
+	migration := migrationTestSolrMigration()
+	tru, fals := true, false
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "move-migration"},
+		Status: solr.SolrRestoreStatus{
+			CollectionRestoreStatuses: []solr.CollectionRestoreStatus{
+				{Collection: "col1", Target: "col1", Finished: true, Successful: &tru},
+				{Collection: "col2", Target: "col2-v2", InProgress: true},
+			},
+		},
+	}
+
+	finished, _ := UpdateMigrationFromRestore(migration, restore)
+	assert.False(t, finished, "The restore has not finished")
+	assert.True(t, migration.Status.CollectionMigrationStatuses[0].Restored)
+	assert.False(t, migration.Status.CollectionMigrationStatuses[1].Restored)
+
+	restore.Status.CollectionRestoreStatuses[1] = solr.CollectionRestoreStatus{Collection: "col2", Target: "col2-v2", Finished: true, Successful: &fals}
+	restore.Status.Finished = true
+	restore.Status.Successful = &fals
+	finished, successful := UpdateMigrationFromRestore(migration, restore)
+	assert.True(t, finished, "The restore has finished")
+	assert.False(t, successful, "A collection could not be restored")
+	assert.NotEmpty(t, migration.Status.CollectionMigrationStatuses[1].Message, "The failed collection should explain why it failed")
+}
+
+func TestSourceCollectionsApiErrors(t *testing.T) {
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solr/admin/collections", r.URL.Path)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	migration := migrationTestSolrMigration()
+	migration.Spec.Source.URL = server.URL + "/"
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default"}}
+	logger := ctrl.Log.WithName("test")
+
+	status, body = http.StatusOK, `{"responseHeader":{"status":0}}`
+	assert.NoError(t, SetSourceCollectionReadOnly(cloud, migration, "col1", true, nil, logger))
+
+	status, body = http.StatusBadRequest, `{"responseHeader":{"status":400},"error":{"msg":"Collection: col1 not found"}}`
+	err := SetSourceCollectionReadOnly(cloud, migration, "col1", true, nil, logger)
+	assert.IsType(t, solr_api.APIError{}, err, "An error reported by the Collections API should be told apart from connection errors")
+
+	status, body = http.StatusServiceUnavailable, "<html>Service Unavailable</html>"
+	err = SetSourceCollectionReadOnly(cloud, migration, "col1", true, nil, logger)
+	if assert.Error(t, err) {
+		_, isApiError := err.(solr_api.APIError)
+		assert.False(t, isApiError, "An unavailable source Solr cluster should be retried")
+	}
+}
@@ -0,0 +1,286 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OperatorVersionAnnotation is set on each Solr Operator pod, so that other instances can detect version skew
+	OperatorVersionAnnotation = "solr.apache.org/operatorVersion"
+
+	OperatorPodLabelKey   = "control-plane"
+	OperatorPodLabelValue = "solr-operator"
+)
+
+var (
+	crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+	// The schema of each managed CRD must contain every field of these types, otherwise the CRDs were not upgraded with the operator.
+	managedCRDTypes = map[string]map[string]reflect.Type{
+		"solrclouds." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrCloudSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrCloudStatus{}),
+		},
+		"solrbackups." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrBackupSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrBackupStatus{}),
+		},
+		"solrindexingbridges." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrIndexingBridgeSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrIndexingBridgeStatus{}),
+		},
+		"solrmigrations." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrMigrationSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrMigrationStatus{}),
+		},
+		"solrprometheusexporters." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrPrometheusExporterSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrPrometheusExporterStatus{}),
+		},
+		"solrrestores." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrRestoreSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrRestoreStatus{}),
+		},
+		"solrstreamingdaemons." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrStreamingDaemonSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrStreamingDaemonStatus{}),
+		},
+		"solrconfigsets." + solrv1beta1.GroupVersion.Group: {
+			"spec":   reflect.TypeOf(solrv1beta1.SolrConfigSetSpec{}),
+			"status": reflect.TypeOf(solrv1beta1.SolrConfigSetStatus{}),
+		},
+		"solroperatorconfigs." + solrv1beta1.GroupVersion.Group: {
+			"spec": reflect.TypeOf(solrv1beta1.SolrOperatorConfigSpec{}),
+		},
+	}
+)
+
+// CheckCustomResourceDefinitions makes sure that the installed Solr CRDs serve the API version that this operator uses,
+// and that their schemas know about every field that this operator reads and writes.
+// A list of problems is returned, which will be empty if the installed CRDs are compatible.
+//
+// If the operator is not allowed to read CRDs, which is likely for namespaced installations, then the check is skipped.
+func CheckCustomResourceDefinitions(ctx context.Context, reader client.Reader, logger logr.Logger) (problems []string, err error) {
+	crdNames := make([]string, 0, len(managedCRDTypes))
+	for crdName := range managedCRDTypes {
+		crdNames = append(crdNames, crdName)
+	}
+	sort.Strings(crdNames)
+
+	for _, crdName := range crdNames {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		if err = reader.Get(ctx, types.NamespacedName{Name: crdName}, crd); err != nil {
+			if errors.IsForbidden(err) {
+				logger.Info("Not permitted to read CustomResourceDefinitions, skipping CRD version checks")
+				return nil, nil
+			} else if errors.IsNotFound(err) {
+				problems = append(problems, fmt.Sprintf("CRD %s is not installed", crdName))
+				err = nil
+				continue
+			}
+			return problems, err
+		}
+		problems = append(problems, checkCRDVersion(crd, managedCRDTypes[crdName])...)
+	}
+	return problems, err
+}
+
+func checkCRDVersion(crd *unstructured.Unstructured, expectedTypes map[string]reflect.Type) (problems []string) {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, isMap := v.(map[string]interface{})
+		if !isMap || version["name"] != solrv1beta1.GroupVersion.Version {
+			continue
+		}
+		if served, _ := version["served"].(bool); !served {
+			return []string{fmt.Sprintf("CRD %s does not serve version %s", crd.GetName(), solrv1beta1.GroupVersion.Version)}
+		}
+		for _, section := range []string{"spec", "status"} {
+			if expectedTypes[section] == nil {
+				continue
+			}
+			properties, _, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema", "properties", section, "properties")
+			if missing := missingSchemaFields(properties, expectedTypes[section], ""); len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("CRD %s is out of date, the %s schema is missing fields: %s", crd.GetName(), section, strings.Join(missing, ", ")))
+			}
+		}
+		return problems
+	}
+	return []string{fmt.Sprintf("CRD %s does not contain version %s", crd.GetName(), solrv1beta1.GroupVersion.Version)}
+}
+
+// missingSchemaFields returns the json fields of the given struct type that are not defined in the CRD schema properties.
+// The fields of nested Solr API types are checked as well, and returned with their full path, e.g. "customSolrKubeOptions.podOptions.sidecarContainers".
+func missingSchemaFields(properties map[string]interface{}, structType reflect.Type, path string) (missing []string) {
+	for _, field := range jsonFields(structType) {
+		fieldSchema, found := properties[field.Name]
+		if !found {
+			missing = append(missing, path+field.Name)
+			continue
+		}
+		if nestedType, nestedProperties := nestedSchemaProperties(field.Type, fieldSchema); nestedType != nil {
+			missing = append(missing, missingSchemaFields(nestedProperties, nestedType, path+field.Name+".")...)
+		}
+	}
+	return missing
+}
+
+// nestedSchemaProperties returns the struct type of a field, and the schema properties defined for it, if the field holds one of the Solr API types.
+// Arrays and maps of these types are followed to the schema of their items.
+// Fields of other types, such as Kubernetes types, and fields without a structural schema are not checked.
+func nestedSchemaProperties(fieldType reflect.Type, fieldSchema interface{}) (reflect.Type, map[string]interface{}) {
+	for {
+		schemaMap, isMap := fieldSchema.(map[string]interface{})
+		if !isMap {
+			return nil, nil
+		}
+		switch fieldType.Kind() {
+		case reflect.Ptr:
+			fieldType = fieldType.Elem()
+		case reflect.Slice, reflect.Array:
+			if fieldType.Elem().Kind() == reflect.Uint8 {
+				// []byte is a base64 encoded string
+				return nil, nil
+			}
+			fieldType, fieldSchema = fieldType.Elem(), schemaMap["items"]
+		case reflect.Map:
+			fieldType, fieldSchema = fieldType.Elem(), schemaMap["additionalProperties"]
+		case reflect.Struct:
+			properties, hasProperties := schemaMap["properties"].(map[string]interface{})
+			if !hasProperties || fieldType.PkgPath() != apiPackagePath {
+				return nil, nil
+			}
+			return fieldType, properties
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// The package of the Solr API types, whose schemas change with the operator
+var apiPackagePath = reflect.TypeOf(solrv1beta1.SolrCloud{}).PkgPath()
+
+// jsonFields returns the fields of a struct type as they are serialized to json, with the json name of each field instead of the Go name
+func jsonFields(structType reflect.Type) (fields []reflect.StructField) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+		if jsonName != "" {
+			field.Name = jsonName
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// RecordOperatorVersion annotates the pod running this operator with the operator's version.
+// This allows other operator instances to find out if a different version is running in the cluster.
+func RecordOperatorVersion(ctx context.Context, c client.Client, namespace string, podName string, operatorVersion string) error {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, pod); err != nil {
+		return err
+	}
+	if pod.Annotations[OperatorVersionAnnotation] == operatorVersion {
+		return nil
+	}
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string, 1)
+	}
+	pod.Annotations[OperatorVersionAnnotation] = operatorVersion
+	return c.Patch(ctx, pod, patch)
+}
+
+// FindOperatorVersionSkew returns a description of each running Solr Operator pod, other than this one, that has recorded a different version.
+// An empty namespace list means that the entire cluster is searched.
+func FindOperatorVersionSkew(ctx context.Context, reader client.Reader, namespaces []string, selfNamespace string, selfName string, operatorVersion string) (skewed []string, err error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	for _, namespace := range namespaces {
+		podList := &corev1.PodList{}
+		if err = reader.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabels{OperatorPodLabelKey: OperatorPodLabelValue}); err != nil {
+			return skewed, err
+		}
+		for _, pod := range podList.Items {
+			if pod.Namespace == selfNamespace && pod.Name == selfName {
+				continue
+			}
+			if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			if otherVersion, hasVersion := pod.Annotations[OperatorVersionAnnotation]; hasVersion && otherVersion != operatorVersion {
+				skewed = append(skewed, fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, otherVersion))
+			}
+		}
+	}
+	return skewed, err
+}
+
+var solrOperatorConfigName = solrv1beta1.DefaultSolrOperatorConfigName
+
+// SetSolrOperatorConfigName sets the name of the SolrOperatorConfig that is used in each namespace.
+// If the name is empty, SolrOperatorConfigs are not used at all.
+func SetSolrOperatorConfigName(name string) {
+	solrOperatorConfigName = name
+}
+
+// SolrOperatorConfigName returns the name of the SolrOperatorConfig that is used in each namespace, or an empty string if SolrOperatorConfigs are not used.
+func SolrOperatorConfigName() string {
+	return solrOperatorConfigName
+}
+
+// GetSolrOperatorConfig returns the SolrOperatorConfig for the given namespace, or nil if the namespace does not have one.
+// Nil is also returned if SolrOperatorConfigs are not used, or their CRD is not installed.
+func GetSolrOperatorConfig(ctx context.Context, reader client.Reader, namespace string) (*solrv1beta1.SolrOperatorConfig, error) {
+	if solrOperatorConfigName == "" {
+		return nil, nil
+	}
+	config := &solrv1beta1.SolrOperatorConfig{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: solrOperatorConfigName}, config); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,183 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCRDVersionCheck(t *testing.T) {
+	expectedTypes := map[string]reflect.Type{
+		"spec":   reflect.TypeOf(solrv1beta1.SolrBackupSpec{}),
+		"status": reflect.TypeOf(solrv1beta1.SolrBackupStatus{}),
+	}
+	specProperties := map[string]interface{}{}
+	for _, field := range jsonFields(expectedTypes["spec"]) {
+		specProperties[field.Name] = map[string]interface{}{}
+	}
+	statusProperties := map[string]interface{}{}
+	for _, field := range jsonFields(expectedTypes["status"]) {
+		statusProperties[field.Name] = map[string]interface{}{}
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "solrbackups.solr.apache.org"},
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name":   solrv1beta1.GroupVersion.Version,
+					"served": true,
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"spec":   map[string]interface{}{"properties": specProperties},
+								"status": map[string]interface{}{"properties": statusProperties},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	assert.Empty(t, checkCRDVersion(crd, expectedTypes), "No problems should be found when the CRD schema contains every field")
+
+	delete(specProperties, "repositoryName")
+	problems := checkCRDVersion(crd, expectedTypes)
+	assert.Len(t, problems, 1, "A missing spec field should be reported as a problem")
+	assert.Contains(t, problems[0], "repositoryName", "The problem should list the missing field")
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	versions[0].(map[string]interface{})["served"] = false
+	assert.NoError(t, unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions"))
+	problems = checkCRDVersion(crd, expectedTypes)
+	assert.Len(t, problems, 1, "An unserved version should be reported as a single problem")
+	assert.Contains(t, problems[0], "does not serve version", "Wrong problem found for an unserved version")
+
+	assert.NoError(t, unstructured.SetNestedSlice(crd.Object, []interface{}{}, "spec", "versions"))
+	problems = checkCRDVersion(crd, expectedTypes)
+	assert.Len(t, problems, 1, "A missing version should be reported as a single problem")
+	assert.Contains(t, problems[0], "does not contain version", "Wrong problem found for a missing version")
+}
+
+func TestGeneratedCRDsMatchTypes(t *testing.T) {
+	for crdName, expectedTypes := range managedCRDTypes {
+		crdYaml, err := config.Manifests.ReadFile(config.SolrCRDsDirectory + "/" + solrv1beta1.GroupVersion.Group + "_" + strings.Split(crdName, ".")[0] + ".yaml")
+		if !assert.NoError(t, err, "Could not read the generated CRD %s", crdName) {
+			continue
+		}
+		crd := &unstructured.Unstructured{}
+		if assert.NoError(t, yaml.Unmarshal(crdYaml, &crd.Object), "Could not parse the generated CRD %s", crdName) {
+			assert.Empty(t, checkCRDVersion(crd, expectedTypes), "The generated CRD %s should contain every field of the API types, including nested fields", crdName)
+		}
+	}
+}
+
+func TestCRDVersionCheckNestedFields(t *testing.T) {
+	expectedTypes := map[string]reflect.Type{
+		"spec": reflect.TypeOf(solrv1beta1.SolrOperatorConfigSpec{}),
+	}
+	specProperties := map[string]interface{}{}
+	for _, field := range jsonFields(expectedTypes["spec"]) {
+		specProperties[field.Name] = map[string]interface{}{}
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "solroperatorconfigs.solr.apache.org"},
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name":   solrv1beta1.GroupVersion.Version,
+					"served": true,
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"spec": map[string]interface{}{"properties": specProperties},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	assert.Empty(t, checkCRDVersion(crd, expectedTypes), "Nested fields without a structural schema should not be checked")
+
+	nestedField := jsonFields(expectedTypes["spec"])[0]
+	nestedType, _ := nestedSchemaProperties(nestedField.Type, map[string]interface{}{"properties": map[string]interface{}{}, "items": map[string]interface{}{"properties": map[string]interface{}{}}, "additionalProperties": map[string]interface{}{"properties": map[string]interface{}{}}})
+	if !assert.NotNil(t, nestedType, "The first field of the SolrOperatorConfigSpec should be a Solr API type") {
+		return
+	}
+	specProperties[nestedField.Name] = map[string]interface{}{"properties": map[string]interface{}{}}
+	problems := checkCRDVersion(crd, expectedTypes)
+	assert.Len(t, problems, 1, "Missing nested fields should be reported as a single problem")
+	for _, field := range jsonFields(nestedType) {
+		assert.Contains(t, problems[0], nestedField.Name+"."+field.Name, "The problem should list the full path of each missing nested field")
+	}
+}
+
+// noKindMatchReader acts like a client for a cluster that does not have the CRD of the requested objects installed
+type noKindMatchReader struct {
+	client.Reader
+}
+
+func (noKindMatchReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return &meta.NoKindMatchError{GroupKind: solrv1beta1.GroupVersion.WithKind("SolrOperatorConfig").GroupKind()}
+}
+
+func TestGetSolrOperatorConfig(t *testing.T) {
+	defer SetSolrOperatorConfigName(solrv1beta1.DefaultSolrOperatorConfigName)
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, solrv1beta1.AddToScheme(scheme))
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&solrv1beta1.SolrOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "ns"}},
+	).Build()
+	ctx := context.Background()
+
+	config, err := GetSolrOperatorConfig(ctx, reader, "ns")
+	assert.NoError(t, err)
+	assert.Nil(t, config, "A SolrOperatorConfig with a different name than the configured one should be ignored")
+
+	SetSolrOperatorConfigName("tenant-a")
+	config, err = GetSolrOperatorConfig(ctx, reader, "ns")
+	assert.NoError(t, err)
+	if assert.NotNil(t, config, "The SolrOperatorConfig with the configured name should be used") {
+		assert.Equal(t, "tenant-a", config.Name)
+	}
+
+	config, err = GetSolrOperatorConfig(ctx, noKindMatchReader{reader}, "ns")
+	assert.NoError(t, err, "A missing SolrOperatorConfig CRD should not be an error")
+	assert.Nil(t, config, "No SolrOperatorConfig should be used without its CRD")
+
+	SetSolrOperatorConfigName("")
+	config, err = GetSolrOperatorConfig(ctx, reader, "ns")
+	assert.NoError(t, err)
+	assert.Nil(t, config, "No SolrOperatorConfig should be used when their name is empty")
+}
@@ -272,7 +272,8 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 		)
 	}
 
-	deployment.Spec.Template.Spec.ImagePullSecrets = imagePullSecrets
+	// The BusyBox image is used by the init containers for TLS
+	deployment.Spec.Template.Spec.ImagePullSecrets = withImagePullSecrets(imagePullSecrets, solrPrometheusExporter.Spec.BusyBoxImage)
 
 	if nil != customPodOptions {
 		metricsContainer := &deployment.Spec.Template.Spec.Containers[0]
@@ -334,6 +335,9 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 		tls.enableTLSOnExporterDeployment(deployment)
 	}
 
+	// Set after TLS is enabled, since that can add more init containers
+	setDefaultInitContainerResources(&deployment.Spec.Template.Spec, customPodOptions)
+
 	return deployment
 }
 
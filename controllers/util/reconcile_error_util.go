@@ -0,0 +1,73 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// InvalidTLSConfigReason is the reason for terminal errors in the TLS options, or in the TLS secrets that they reference
+	InvalidTLSConfigReason = "InvalidTLSConfig"
+
+	// InvalidSecurityConfigReason is the reason for terminal errors in the security options, or in the secrets that they reference
+	InvalidSecurityConfigReason = "InvalidSecurityConfig"
+
+	// InvalidSpecReason is the reason for terminal errors in the spec of a resource
+	InvalidSpecReason = "InvalidSpec"
+
+	// IncompatibleBackupReason is the reason for terminal errors caused by backups that cannot be restored into a SolrCloud
+	IncompatibleBackupReason = "IncompatibleBackup"
+
+	// ConfigSetConflictReason is the reason for terminal errors caused by SolrConfigSets that upload a configset whose files are also synced through the SolrCloud's configSetFiles
+	ConfigSetConflictReason = "ConfigSetConflict"
+
+	// ZookeeperChRootConflictReason is the reason for terminal errors caused by SolrClouds that use overlapping chroots in the same Zookeeper ensemble
+	ZookeeperChRootConflictReason = "ZookeeperChRootConflict"
+)
+
+// TerminalError is a reconcile error caused by a misconfiguration, which retrying the reconcile cannot fix.
+// Only a change to the resource, or to a resource that it references, can resolve it.
+// Controllers report terminal errors through a status condition, or a Warning event for resources without one, instead of requeuing the resource with a backoff.
+// All other errors are considered transient, such as failed API requests, and are retried with a backoff.
+type TerminalError struct {
+	// Reason is a CamelCase reason for the error, used for status conditions and events
+	Reason string
+
+	Err error
+}
+
+func (e *TerminalError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TerminalError) Unwrap() error {
+	return e.Err
+}
+
+// TerminalErrorf creates a terminal error with the given reason and formatted message
+func TerminalErrorf(reason string, format string, a ...interface{}) error {
+	return &TerminalError{Reason: reason, Err: fmt.Errorf(format, a...)}
+}
+
+// AsTerminalError returns the terminal error in the error's chain, if there is one
+func AsTerminalError(err error) (terminalErr *TerminalError, isTerminal bool) {
+	isTerminal = errors.As(err, &terminalErr)
+	return terminalErr, isTerminal
+}
@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func TestTerminalErrorClassification(t *testing.T) {
+	err := ValidateBasicAuthSecret(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-auth"},
+		Type:       corev1.SecretTypeOpaque,
+	})
+	terminalErr, isTerminal := AsTerminalError(err)
+	if assert.True(t, isTerminal, "A basic auth secret of the wrong type is a terminal error") {
+		assert.Equal(t, InvalidSecurityConfigReason, terminalErr.Reason, "Wrong reason for an invalid basic auth secret")
+	}
+
+	_, isTerminal = AsTerminalError(fmt.Errorf("while reconciling: %w", err))
+	assert.True(t, isTerminal, "A wrapped terminal error should still be terminal")
+
+	_, isTerminal = AsTerminalError(errors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "basic-auth"))
+	assert.False(t, isTerminal, "API errors are transient")
+
+	_, isTerminal = AsTerminalError(nil)
+	assert.False(t, isTerminal, "No error is not a terminal error")
+}
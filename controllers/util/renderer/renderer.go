@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package renderer renders the Kubernetes resources that the Solr Operator manages for a SolrCloud, without needing a Kubernetes cluster.
+// The resources are generated by the same functions of the util package that the Solr Operator reconciles them with,
+// so platforms that embed the renderer, e.g. to preview the resources of a SolrCloud, get the same resources that the Solr Operator would create.
+package renderer
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// SolrCloudOptions are the inputs, other than the SolrCloud itself, that the resources of a SolrCloud are rendered from.
+// The Solr Operator gathers these while reconciling the SolrCloud, platforms embedding the renderer provide them directly.
+type SolrCloudOptions struct {
+	// The status of the SolrCloud, which is required. Only the Zookeeper connection information is used, and it must be set.
+	Status *solr.SolrCloudStatus
+
+	// The IP addresses of the hostnames that the Solr pods must be able to resolve, which are added as hostAliases.
+	// The Solr Operator uses this to let Solr Nodes resolve the external addresses that they advertise.
+	HostNameIPs map[string]string
+
+	// The names and hashes of the user-provided configuration, keyed by e.g. util.SolrXmlFile and util.SolrXmlMd5Annotation.
+	// If no solr.xml is provided, then the one from the rendered ConfigMap is used.
+	ReconcileConfigInfo map[string]string
+
+	// The TLS configuration of the Solr pods, if TLS is enabled.
+	TLS *util.TLSCerts
+}
+
+// SolrCloudResources are all resources that are rendered for a SolrCloud.
+type SolrCloudResources struct {
+	// The ConfigMap with the default solr.xml, only rendered when no solr.xml is provided.
+	ConfigMap *corev1.ConfigMap
+
+	StatefulSet   *appsv1.StatefulSet
+	CommonService *corev1.Service
+
+	// The headless service, only rendered when the SolrCloud does not use individual node services.
+	HeadlessService *corev1.Service
+
+	// The individual node services, only rendered when the SolrCloud uses them.
+	NodeServices []*corev1.Service
+
+	// The Ingress, only rendered when the SolrCloud is made externally addressable through a managed Ingress that exposes any endpoint.
+	Ingress *netv1.Ingress
+}
+
+// RenderSolrCloud renders all resources of the SolrCloud, which does not need to have its defaults applied.
+// Neither the SolrCloud nor the options are modified.
+func RenderSolrCloud(solrCloud *solr.SolrCloud, opts SolrCloudOptions) (resources SolrCloudResources) {
+	solrCloud = solrCloud.DeepCopy()
+	solrCloud.WithDefaults()
+	reconcileConfigInfo := make(map[string]string, len(opts.ReconcileConfigInfo)+2)
+	for key, value := range opts.ReconcileConfigInfo {
+		reconcileConfigInfo[key] = value
+	}
+	if reconcileConfigInfo[util.SolrXmlFile] == "" {
+		// no user provided solr.xml, so use the default
+		resources.ConfigMap = util.GenerateConfigMap(solrCloud)
+		reconcileConfigInfo[util.SolrXmlMd5Annotation] = util.HashContent([]byte(resources.ConfigMap.Data[util.SolrXmlFile]))
+		reconcileConfigInfo[util.SolrXmlFile] = resources.ConfigMap.Name
+	}
+	opts.ReconcileConfigInfo = reconcileConfigInfo
+
+	resources.StatefulSet = util.GenerateStatefulSet(solrCloud, opts.Status, opts.HostNameIPs, opts.ReconcileConfigInfo, opts.TLS)
+	resources.CommonService = util.GenerateCommonService(solrCloud)
+	if solrCloud.UsesHeadlessService() {
+		resources.HeadlessService = util.GenerateHeadlessService(solrCloud)
+	}
+	nodeNames := solrCloud.GetAllSolrNodeNames()
+	if solrCloud.UsesIndividualNodeServices() {
+		for _, nodeName := range nodeNames {
+			resources.NodeServices = append(resources.NodeServices, util.GenerateNodeService(solrCloud, nodeName))
+		}
+	}
+	if extOpts := solrCloud.Spec.SolrAddressability.External; extOpts.IsManaged() && extOpts.Method == solr.Ingress {
+		// An Ingress must have at least one rule, so it is not rendered while every endpoint is hidden
+		if ingress := util.GenerateIngress(solrCloud, nodeNames); len(ingress.Spec.Rules) > 0 {
+			resources.Ingress = ingress
+		}
+	}
+	return resources
+}
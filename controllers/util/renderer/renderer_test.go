@@ -0,0 +1,65 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package renderer
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestRenderSolrCloud(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "example.com",
+				},
+			},
+		},
+	}
+
+	status := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+	}
+
+	resources := RenderSolrCloud(solrCloud, SolrCloudOptions{Status: status})
+	assert.Empty(t, solrCloud.Spec.SolrImage, "The given SolrCloud should not be defaulted")
+
+	if assert.NotNil(t, resources.ConfigMap, "The default solr.xml should be rendered when none is provided") {
+		assert.Equal(t, resources.ConfigMap.Name, resources.StatefulSet.Spec.Template.Spec.Volumes[0].ConfigMap.Name, "The StatefulSet should use the rendered solr.xml")
+		assert.Contains(t, resources.StatefulSet.Spec.Template.Annotations, util.SolrXmlMd5Annotation, "The pods should restart when the rendered solr.xml changes")
+	}
+	assert.Equal(t, "foo-solrcloud", resources.StatefulSet.Name, "Wrong StatefulSet rendered")
+	assert.NotNil(t, resources.CommonService, "The common service should always be rendered")
+	assert.Nil(t, resources.HeadlessService, "No headless service should be rendered when using individual node services")
+	assert.Len(t, resources.NodeServices, 2, "A service should be rendered for each Solr Node")
+	assert.NotNil(t, resources.Ingress, "The Ingress should be rendered when it is managed by the operator")
+
+	solrCloud.Spec.SolrAddressability.External.Unmanaged = true
+	resources = RenderSolrCloud(solrCloud, SolrCloudOptions{Status: status, ReconcileConfigInfo: map[string]string{util.SolrXmlFile: "custom-config"}})
+	assert.Nil(t, resources.ConfigMap, "No default solr.xml should be rendered when one is provided")
+	assert.Equal(t, "custom-config", resources.StatefulSet.Spec.Template.Spec.Volumes[0].ConfigMap.Name, "The StatefulSet should use the provided solr.xml")
+	assert.Nil(t, resources.Ingress, "No Ingress should be rendered when it is not managed by the operator")
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequeueReason describes why a resource is reconciled again after some time, even if nothing changes.
+// How long the operator waits can be tuned per reason, since polling Solr too often can overload large clusters,
+// while polling too rarely makes the operator slow to react.
+type RequeueReason string
+
+const (
+	// RequeueRetry is used to retry after a request to Solr or Kubernetes failed.
+	RequeueRetry RequeueReason = "retry"
+	// RequeueManagedUpdate is used to check whether more pods can be updated, during a managed update.
+	RequeueManagedUpdate RequeueReason = "managed-update"
+	// RequeueLeaderMovement is used to check whether shard leaders have moved off of interrupted Nodes.
+	RequeueLeaderMovement RequeueReason = "leader-movement"
+	// RequeueBackupStatus is used to check the status of the asynchronous collection backups of a SolrBackup,
+	// or the collection restores of a SolrRestore.
+	RequeueBackupStatus RequeueReason = "backup-status"
+	// RequeueSteadyState is used to periodically refresh state that Solr does not notify the operator about,
+	// such as the pod deletion costs and the read-only mode of collections.
+	RequeueSteadyState RequeueReason = "steady-state"
+	// RequeueConfigSetDrift is used to periodically check operator-managed configset files for drift.
+	RequeueConfigSetDrift RequeueReason = "configset-drift"
+	// RequeueSecurityJsonDrift is used to periodically check the operator-managed security.json in Zookeeper for drift.
+	RequeueSecurityJsonDrift RequeueReason = "security-json-drift"
+)
+
+var defaultRequeueDurations = map[RequeueReason]time.Duration{
+	RequeueRetry:             time.Second * 15,
+	RequeueManagedUpdate:     time.Second * 15,
+	RequeueLeaderMovement:    time.Second * 5,
+	RequeueBackupStatus:      time.Second * 5,
+	RequeueSteadyState:       time.Minute,
+	RequeueConfigSetDrift:    time.Minute * 5,
+	RequeueSecurityJsonDrift: time.Minute * 5,
+}
+
+var requeueDurations = map[RequeueReason]time.Duration{}
+
+// SetRequeueDurations overrides the requeue durations, given a comma-separated list of "<reason>=<duration>" pairs,
+// such as "managed-update=30s,steady-state=5m". Reasons that are not given keep their default duration.
+// An error is returned, and no durations are changed, if the list is malformed or contains an unknown reason.
+func SetRequeueDurations(durations string) error {
+	parsed := map[RequeueReason]time.Duration{}
+	for _, entry := range strings.Split(durations, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("requeue duration %q must be of the form <reason>=<duration>", entry)
+		}
+		reason := RequeueReason(strings.TrimSpace(parts[0]))
+		if _, known := defaultRequeueDurations[reason]; !known {
+			return fmt.Errorf("unknown requeue reason %q, known reasons are: %s", reason, strings.Join(RequeueReasons(), ", "))
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("invalid duration for requeue reason %q: %w", reason, err)
+		}
+		if duration < time.Second {
+			return fmt.Errorf("the duration for requeue reason %q must be at least 1s, not %s", reason, duration)
+		}
+		parsed[reason] = duration
+	}
+	requeueDurations = parsed
+	return nil
+}
+
+// RequeueAfter returns how long to wait before reconciling a resource again, for the given reason.
+func RequeueAfter(reason RequeueReason) time.Duration {
+	if duration, set := requeueDurations[reason]; set {
+		return duration
+	}
+	return defaultRequeueDurations[reason]
+}
+
+// RequeueReasons returns the sorted names of all reasons whose requeue duration can be tuned.
+func RequeueReasons() []string {
+	reasons := make([]string, 0, len(defaultRequeueDurations))
+	for reason := range defaultRequeueDurations {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+	return reasons
+}
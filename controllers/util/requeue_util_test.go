@@ -0,0 +1,49 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRequeueDurations(t *testing.T) {
+	defer func() {
+		requeueDurations = map[RequeueReason]time.Duration{}
+	}()
+
+	assert.Equal(t, time.Second*15, RequeueAfter(RequeueManagedUpdate), "Wrong default requeue duration for managed updates")
+	assert.Equal(t, time.Minute, RequeueAfter(RequeueSteadyState), "Wrong default requeue duration for the steady state")
+
+	assert.NoError(t, SetRequeueDurations(" managed-update=30s, steady-state=5m,"), "Valid requeue durations should be accepted")
+	assert.Equal(t, time.Second*30, RequeueAfter(RequeueManagedUpdate), "The requeue duration for managed updates should be overridden")
+	assert.Equal(t, time.Minute*5, RequeueAfter(RequeueSteadyState), "The requeue duration for the steady state should be overridden")
+	assert.Equal(t, time.Second*5, RequeueAfter(RequeueBackupStatus), "Requeue durations that are not overridden should keep their default")
+
+	// Invalid requeue durations do not change any durations
+	assert.Error(t, SetRequeueDurations("managed-update=1m,unknown=1m"), "Unknown requeue reasons should be rejected")
+	assert.Error(t, SetRequeueDurations("managed-update"), "Requeue reasons without durations should be rejected")
+	assert.Error(t, SetRequeueDurations("managed-update=often"), "Invalid durations should be rejected")
+	assert.Error(t, SetRequeueDurations("managed-update=100ms"), "Durations below 1s should be rejected")
+	assert.Equal(t, time.Second*30, RequeueAfter(RequeueManagedUpdate), "Invalid requeue durations should not change any durations")
+
+	// Setting the requeue durations again resets the reasons that are not given
+	assert.NoError(t, SetRequeueDurations("backup-status=10s"), "Valid requeue durations should be accepted")
+	assert.Equal(t, time.Second*15, RequeueAfter(RequeueManagedUpdate), "Requeue durations that are no longer given should use their default")
+}
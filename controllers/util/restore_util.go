@@ -0,0 +1,438 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/rest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CollectionsToRestore returns the collections that a SolrRestore restores.
+// If the SolrRestore does not list any collections, every collection that the SolrBackup successfully backed up is restored under its original name.
+func CollectionsToRestore(restore *solr.SolrRestore, backup *solr.SolrBackup) (collections []solr.SolrRestoreCollection, err error) {
+	if len(restore.Spec.Collections) > 0 {
+		return restore.Spec.Collections, nil
+	}
+	if backup == nil {
+		return nil, TerminalErrorf(InvalidSpecReason, "collections must be provided to restore a backup that is not managed by a SolrBackup")
+	}
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		if collectionStatus.Successful != nil && *collectionStatus.Successful {
+			collections = append(collections, solr.SolrRestoreCollection{Name: collectionStatus.Collection, Target: collectionStatus.Collection})
+		}
+	}
+	if len(collections) == 0 {
+		return nil, TerminalErrorf(InvalidSpecReason, "SolrBackup %s does not contain any successfully backed up collections", backup.Name)
+	}
+	return collections, nil
+}
+
+// BackupRepositoryForRestore finds the backup repository of the SolrCloud that stores its data in the same place as the given repository definition,
+// so that backups taken by another SolrCloud can be restored without that SolrCloud, or its repository names.
+// Managed repositories store their data in a directory named after the SolrCloud by default, so the directory must be given for them to be matched.
+func BackupRepositoryForRestore(cloud *solr.SolrCloud, source *solr.SolrBackupRepository) (*solr.SolrBackupRepository, error) {
+	if source.Managed != nil && source.Managed.Directory == "" {
+		return nil, TerminalErrorf(InvalidSpecReason, "the directory of the managed repository %q must be provided to restore from it", source.Name)
+	}
+	for i, repo := range cloud.Spec.BackupRepositories {
+		if source.GCS != nil && repo.GCS != nil {
+			if repo.GCS.Bucket == source.GCS.Bucket && BackupLocationPath(&repo, "") == BackupLocationPath(source, "") {
+				return &cloud.Spec.BackupRepositories[i], nil
+			}
+		} else if source.Managed != nil && repo.Managed != nil {
+			if reflect.DeepEqual(repo.Managed.Volume, source.Managed.Volume) &&
+				BackupRestoreSubPathForCloud(repo.Managed.Directory, cloud.Name) == BackupRestoreSubPathForCloud(source.Managed.Directory, "") {
+				return &cloud.Spec.BackupRepositories[i], nil
+			}
+		}
+	}
+	return nil, TerminalErrorf(InvalidSpecReason, "SolrCloud %s does not define a backup repository that stores its data in the same place as the repository %q", cloud.Name, source.Name)
+}
+
+func GenerateQueryParamsForRestore(backupRepository *solr.SolrBackupRepository, restore *solr.SolrRestore, backupName string, collection solr.SolrRestoreCollection) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "RESTORE")
+	queryParams.Add("collection", collection.Target)
+	// Backups taken by the Solr Operator are named after the collection that they back up
+	queryParams.Add("name", collection.Name)
+	queryParams.Add("async", restore.AsyncId(collection.Target))
+	queryParams.Add("location", BackupLocationPath(backupRepository, backupName))
+	queryParams.Add("repository", backupRepository.Name)
+	if restore.Spec.BackupId != nil {
+		queryParams.Add("backupId", strconv.Itoa(int(*restore.Spec.BackupId)))
+	}
+	return queryParams
+}
+
+func StartRestoreForCollection(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, restore *solr.SolrRestore, backupName string, collection solr.SolrRestoreCollection, httpHeaders map[string]string, logger logr.Logger) (success bool, err error) {
+	queryParams := GenerateQueryParamsForRestore(backupRepository, restore, backupName, collection)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	logger.Info("Calling to start collection restore", "solrCloud", cloud.Name, "collection", collection.Name, "target", collection.Target)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		if resp.ResponseHeader.Status == 0 {
+			success = true
+		} else {
+			_, err = solr_api.CheckForCollectionsApiError("RESTORE", resp.ResponseHeader)
+		}
+	} else {
+		logger.Error(err, "Error starting collection restore", "solrCloud", cloud.Name, "collection", collection.Name, "target", collection.Target)
+	}
+
+	return success, err
+}
+
+func CheckRestoreForCollection(cloud *solr.SolrCloud, restore *solr.SolrRestore, target string, httpHeaders map[string]string, logger logr.Logger) (finished bool, success bool, asyncStatus string, err error) {
+	logger.Info("Calling to check on collection restore", "solrCloud", cloud.Name, "target", target)
+	finished, success, asyncStatus, err = checkAsyncRequest(cloud, restore.AsyncId(target), httpHeaders)
+	if err != nil {
+		logger.Error(err, "Error checking on collection restore", "solrCloud", cloud.Name, "target", target)
+	}
+
+	return finished, success, asyncStatus, err
+}
+
+func DeleteAsyncInfoForRestore(cloud *solr.SolrCloud, restore *solr.SolrRestore, target string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	logger.Info("Calling to delete async info for restore command.", "solrCloud", cloud.Name, "target", target)
+	err = deleteAsyncRequest(cloud, restore.AsyncId(target), httpHeaders)
+	if err != nil {
+		logger.Error(err, "Error deleting async data for collection restore", "solrCloud", cloud.Name, "target", target)
+	}
+
+	return err
+}
+
+// CheckStatusOfCollectionRestores returns whether the restores of all collections have finished, and whether they were all successful
+func CheckStatusOfCollectionRestores(restore *solr.SolrRestore) (allFinished bool, allSuccessful bool) {
+	allFinished = len(restore.Status.CollectionRestoreStatuses) > 0
+	allSuccessful = allFinished
+	for _, collectionStatus := range restore.Status.CollectionRestoreStatuses {
+		allFinished = allFinished && collectionStatus.Finished
+		allSuccessful = allSuccessful && collectionStatus.Successful != nil && *collectionStatus.Successful
+	}
+	return allFinished, allFinished && allSuccessful
+}
+
+// ValidateClusterStateRestore returns an error if the SolrRestore cannot restore the cluster state exported with the backup, from the given repository
+func ValidateClusterStateRestore(restore *solr.SolrRestore, backupRepository *solr.SolrBackupRepository) error {
+	if restore.Spec.RestoreSecurityJson && !restore.Spec.RestoreClusterState {
+		return TerminalErrorf(InvalidSpecReason, "restoreSecurityJson requires restoreClusterState")
+	}
+	if restore.Spec.RestoreClusterState && !IsRepoManaged(backupRepository) {
+		return TerminalErrorf(InvalidSpecReason, "the cluster state can only be restored from managed backup repositories, %q is not managed", backupRepository.Name)
+	}
+	return nil
+}
+
+// RestoreClusterStateFromBackup reads the cluster state that was exported with the backup, and writes the parts of it that apply to the restored collections
+// into the Zookeeper of the SolrCloud, through Solr. The restored znodes are returned.
+func RestoreClusterStateFromBackup(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, restore *solr.SolrRestore, backupName string, httpHeaders map[string]string, config *rest.Config) (restored []string, err error) {
+	if err = ValidateClusterStateRestore(restore, backupRepository); err != nil {
+		return nil, err
+	}
+	podName, err := ReadySolrNodeName(cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := RunExecForPodWithOutput(
+		podName,
+		cloud.Namespace,
+		[]string{"/bin/bash", "-c", GenerateClusterStateReadCommand(BackupLocationPath(backupRepository, backupName))},
+		*config,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if archive == "" {
+		return nil, TerminalErrorf(InvalidSpecReason, "the backup %q does not contain an exported cluster state, it must be taken with includeClusterState", backupName)
+	}
+	exported, err := ReadClusterStateArchive([]byte(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]string)
+	for _, znode := range []string{AliasesZnode, ClusterPropsZnode} {
+		if current[znode], _, err = solr_api.GetZookeeperData(cloud, znode, httpHeaders); err != nil {
+			return nil, err
+		}
+	}
+
+	znodeData, err := ClusterStateToRestore(exported, current, restore.Status.CollectionRestoreStatuses, restore.Spec.RestoreSecurityJson)
+	if err != nil || len(znodeData) == 0 {
+		return nil, err
+	}
+	for znode := range znodeData {
+		restored = append(restored, znode)
+	}
+	sort.Strings(restored)
+
+	restoreArchive, err := ClusterStateArchive(znodeData)
+	if err != nil {
+		return nil, err
+	}
+	_, err = RunExecForPodWithInput(
+		podName,
+		cloud.Namespace,
+		[]string{"/bin/bash", "-c", GenerateClusterStateRestoreCommand(restored)},
+		bytes.NewReader(restoreArchive),
+		*config,
+	)
+	return restored, err
+}
+
+// GenerateClusterStateReadCommand returns the shell command that writes the cluster state exported in the given backup location to stdout, as a tar archive.
+// Nothing is written if no cluster state was exported.
+func GenerateClusterStateReadCommand(backupLocation string) string {
+	directory := ShellQuote(backupLocation + "/" + ClusterStateBackupDirectory)
+	return "if [ -d " + directory + " ]; then tar -c -C " + directory + " .; fi"
+}
+
+// GenerateClusterStateRestoreCommand returns the shell command that writes the given znodes into Zookeeper, from the files of the tar archive read from stdin.
+// The files are extracted into a temporary directory, that is removed however the command exits.
+func GenerateClusterStateRestoreCommand(znodes []string) string {
+	commands := []string{"tar -x -C \"$dir\""}
+	for _, znode := range znodes {
+		commands = append(commands, fmt.Sprintf("solr zk cp \"file:$dir\"%s %s -z ${ZK_HOST} > /dev/null", ShellQuote(znode), ShellQuote("zk:"+znode)))
+	}
+	return "dir=$(mktemp -d) && trap 'rm -rf \"$dir\"' EXIT && " + strings.Join(commands, " && ")
+}
+
+// ShellQuote returns the given value as a single-quoted shell word, so that it is never expanded or split by the shell.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// ClusterStateToRestore returns the data of the znodes to write into Zookeeper, given the cluster state exported with the backup,
+// the current aliases and cluster properties of the SolrCloud, and the collections that were restored.
+//   - The properties of each successfully restored collection are restored under its target name.
+//   - Aliases whose collections were all restored are added, pointing to the target collections, unless an alias with the same name already exists.
+//   - Cluster properties are added, unless they are already set.
+//   - The security.json is only restored when requested.
+//
+// Znodes that would not change are not returned.
+func ClusterStateToRestore(exported map[string]string, current map[string]string, collections []solr.CollectionRestoreStatus, includeSecurityJson bool) (znodeData map[string]string, err error) {
+	znodeData = make(map[string]string)
+	targets := make(map[string]string)
+	for _, collectionStatus := range collections {
+		if collectionStatus.Successful == nil || !*collectionStatus.Successful {
+			continue
+		}
+		targets[collectionStatus.Collection] = collectionStatus.Target
+		if props, hasProps := exported[CollectionPropsZnode(collectionStatus.Collection)]; hasProps {
+			znodeData[CollectionPropsZnode(collectionStatus.Target)] = props
+		}
+	}
+
+	if aliases, hasAliases := exported[AliasesZnode]; hasAliases {
+		if merged, changed, err := mergeRestoredAliases(aliases, current[AliasesZnode], targets); err != nil {
+			return nil, err
+		} else if changed {
+			znodeData[AliasesZnode] = merged
+		}
+	}
+
+	if clusterProps, hasClusterProps := exported[ClusterPropsZnode]; hasClusterProps {
+		if merged, changed, err := mergeRestoredClusterProps(clusterProps, current[ClusterPropsZnode]); err != nil {
+			return nil, err
+		} else if changed {
+			znodeData[ClusterPropsZnode] = merged
+		}
+	}
+
+	if securityJson, hasSecurityJson := exported[SecurityJsonZnode]; hasSecurityJson && includeSecurityJson {
+		znodeData[SecurityJsonZnode] = securityJson
+	}
+	return znodeData, nil
+}
+
+// solrAliases is the content of the aliases.json znode, that maps each alias to a comma-separated list of collections, and holds the metadata of the aliases
+type solrAliases struct {
+	Collection         map[string]string                 `json:"collection,omitempty"`
+	CollectionMetadata map[string]map[string]interface{} `json:"collection_metadata,omitempty"`
+}
+
+func mergeRestoredAliases(exported string, current string, targets map[string]string) (merged string, changed bool, err error) {
+	exportedAliases := solrAliases{}
+	if err = json.Unmarshal([]byte(exported), &exportedAliases); err != nil {
+		return "", false, fmt.Errorf("could not parse the exported aliases: %w", err)
+	}
+	mergedAliases := solrAliases{}
+	if current != "" {
+		if err = json.Unmarshal([]byte(current), &mergedAliases); err != nil {
+			return "", false, fmt.Errorf("could not parse the current aliases: %w", err)
+		}
+	}
+	if mergedAliases.Collection == nil {
+		mergedAliases.Collection = make(map[string]string)
+	}
+
+	for alias, aliasCollections := range exportedAliases.Collection {
+		if _, exists := mergedAliases.Collection[alias]; exists {
+			continue
+		}
+		collections := strings.Split(aliasCollections, ",")
+		allRestored := true
+		for i, collection := range collections {
+			target, restored := targets[strings.TrimSpace(collection)]
+			allRestored = allRestored && restored
+			collections[i] = target
+		}
+		if !allRestored {
+			continue
+		}
+		changed = true
+		mergedAliases.Collection[alias] = strings.Join(collections, ",")
+		if metadata, hasMetadata := exportedAliases.CollectionMetadata[alias]; hasMetadata {
+			if mergedAliases.CollectionMetadata == nil {
+				mergedAliases.CollectionMetadata = make(map[string]map[string]interface{})
+			}
+			mergedAliases.CollectionMetadata[alias] = metadata
+		}
+	}
+	if !changed {
+		return "", false, nil
+	}
+	mergedBytes, err := json.Marshal(mergedAliases)
+	return string(mergedBytes), true, err
+}
+
+func mergeRestoredClusterProps(exported string, current string) (merged string, changed bool, err error) {
+	exportedProps := make(map[string]interface{})
+	if err = json.Unmarshal([]byte(exported), &exportedProps); err != nil {
+		return "", false, fmt.Errorf("could not parse the exported cluster properties: %w", err)
+	}
+	mergedProps := make(map[string]interface{})
+	if current != "" {
+		if err = json.Unmarshal([]byte(current), &mergedProps); err != nil {
+			return "", false, fmt.Errorf("could not parse the current cluster properties: %w", err)
+		}
+	}
+	for prop, value := range exportedProps {
+		if _, isSet := mergedProps[prop]; !isSet {
+			changed = true
+			mergedProps[prop] = value
+		}
+	}
+	if !changed {
+		return "", false, nil
+	}
+	mergedBytes, err := json.Marshal(mergedProps)
+	return string(mergedBytes), true, err
+}
+
+// CheckBackupsForRestore checks, before any collection is restored, that the backup of each collection can be restored into the SolrCloud,
+// so that a SolrRestore fails early with the reasons, rather than with errors from Solr once some collections have already been restored.
+// The backups are listed through the Backup API, which requires Solr 8.9 or later, so the check is skipped for older, or unknown, Solr versions.
+func CheckBackupsForRestore(cloud *solr.SolrCloud, backupRepository *solr.SolrBackupRepository, restore *solr.SolrRestore, backupName string, collections []solr.SolrRestoreCollection, httpHeaders map[string]string, logger logr.Logger) error {
+	solrVersion, err := version.ParseGeneric(cloud.Spec.SolrImage.Tag)
+	if err != nil || solrVersion.LessThan(version.MustParseGeneric("8.9")) {
+		logger.Info("Not checking the backups before restoring them, the Backup API requires Solr 8.9 or later", "solrVersion", cloud.Spec.SolrImage.Tag)
+		return nil
+	}
+
+	var problems []string
+	for _, collection := range collections {
+		listResp := &solr_api.SolrListBackupResponse{}
+		if err = solr_api.CallCollectionsApi(cloud, GenerateQueryParamsForRestoreBackupList(backupRepository, backupName, collection), httpHeaders, listResp); err == nil {
+			_, err = solr_api.CheckForCollectionsApiError("LISTBACKUP", listResp.ResponseHeader)
+		}
+		if err != nil {
+			logger.Error(err, "Error listing collection backups", "solrCloud", cloud.Name, "collection", collection.Name)
+			return err
+		}
+		if problem := CheckBackupPointForRestore(listResp.Backups, restore.Spec.BackupId, solrVersion); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s (%s)", collection.Name, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return TerminalErrorf(IncompatibleBackupReason, "the backups of these collections cannot be restored into SolrCloud %s: %s", cloud.Name, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// GenerateQueryParamsForRestoreBackupList returns the parameters that list the backup points of a collection's backup that is to be restored
+func GenerateQueryParamsForRestoreBackupList(backupRepository *solr.SolrBackupRepository, backupName string, collection solr.SolrRestoreCollection) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "LISTBACKUP")
+	// Backups taken by the Solr Operator are named after the collection that they back up
+	queryParams.Add("name", collection.Name)
+	queryParams.Add("location", BackupLocationPath(backupRepository, backupName))
+	queryParams.Add("repository", backupRepository.Name)
+	return queryParams
+}
+
+// CheckBackupPointForRestore checks that the backup point to restore, the given backupId or else the latest, can be restored into a SolrCloud running the given Solr version.
+// The backup point must have been completely written, must include the configset of the collection,
+// and its index must be readable by the Lucene version of the SolrCloud, which only reads indexes of the same, or the previous, major version.
+// Backups without any backup points, such as backups not taken in the incremental format, cannot be checked. If the backup point cannot be restored, the problem is returned.
+func CheckBackupPointForRestore(backupPoints []solr_api.SolrBackupPoint, backupId *int32, solrVersion *version.Version) (problem string) {
+	if len(backupPoints) == 0 {
+		return ""
+	}
+	sort.Slice(backupPoints, func(i, j int) bool { return backupPoints[i].BackupId < backupPoints[j].BackupId })
+	if backupId != nil {
+		found := false
+		for _, backupPoint := range backupPoints {
+			if backupPoint.BackupId == *backupId {
+				backupPoints = []solr_api.SolrBackupPoint{backupPoint}
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Sprintf("backup %d does not exist", *backupId)
+		}
+	}
+	if problem = VerifyBackupPoints(backupPoints); problem != "" {
+		return problem
+	}
+
+	backupPoint := backupPoints[len(backupPoints)-1]
+	if backupPoint.ConfigName == "" {
+		return fmt.Sprintf("backup %d does not include the configset of the collection", backupPoint.BackupId)
+	}
+	if backupPoint.IndexVersion == "" {
+		return ""
+	}
+	indexVersion, err := version.ParseGeneric(backupPoint.IndexVersion)
+	if err != nil {
+		return ""
+	}
+	// Solr releases use the Lucene release with the same major version
+	if indexVersion.Major() > solrVersion.Major() {
+		return fmt.Sprintf("backup %d was written by Lucene %s, which is newer than Solr %s", backupPoint.BackupId, backupPoint.IndexVersion, solrVersion)
+	}
+	if indexVersion.Major()+1 < solrVersion.Major() {
+		return fmt.Sprintf("backup %d was written by Lucene %s, which Solr %s cannot read", backupPoint.BackupId, backupPoint.IndexVersion, solrVersion)
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"testing"
+)
+
+func TestSolrRestoreApiParamsForManagedRepository(t *testing.T) {
+	managedRepository := &solr.SolrBackupRepository{
+		Name: "somemanagedrepository",
+		Managed: &solr.ManagedRepository{
+			Volume:    corev1.VolumeSource{}, // Actual volume info doesn't matter here
+			Directory: "/somedirectory",
+		},
+	}
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "somerestorename",
+		},
+		Spec: solr.SolrRestoreSpec{
+			SolrCloud:  "solrcloudcluster",
+			SolrBackup: "somebackupname",
+		},
+	}
+
+	queryParams := GenerateQueryParamsForRestore(managedRepository, restore, "somebackupname", solr.SolrRestoreCollection{Name: "col2", Target: "col2-restored"})
+
+	assert.Equalf(t, "RESTORE", queryParams.Get("action"), "Wrong %s for Collections API Call", "action")
+	assert.Equalf(t, "col2-restored", queryParams.Get("collection"), "Wrong %s for Collections API Call", "collection name")
+	assert.Equalf(t, "col2", queryParams.Get("name"), "Wrong %s for Collections API Call", "backup name")
+	assert.Equalf(t, "somerestorename-restore-col2-restored", queryParams.Get("async"), "Wrong %s for Collections API Call", "async id")
+	assert.Equalf(t, "/var/solr/data/backup-restore/somemanagedrepository/backups/somebackupname", queryParams.Get("location"), "Wrong %s for Collections API Call", "backup location")
+	assert.Equalf(t, "somemanagedrepository", queryParams.Get("repository"), "Wrong %s for Collections API Call", "repository")
+	assert.Emptyf(t, queryParams.Get("backupId"), "No %s should be given for Collections API Call", "backupId")
+
+	backupId := int32(3)
+	restore.Spec.BackupId = &backupId
+	queryParams = GenerateQueryParamsForRestore(managedRepository, restore, "somebackupname", solr.SolrRestoreCollection{Name: "col2", Target: "col2"})
+	assert.Equalf(t, "3", queryParams.Get("backupId"), "Wrong %s for Collections API Call", "backupId")
+}
+
+func TestCollectionsToRestore(t *testing.T) {
+	tru := true
+	fals := false
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "somebackupname"},
+		Status: solr.SolrBackupStatus{
+			Finished:   true,
+			Successful: &tru,
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", Finished: true, Successful: &tru},
+				{Collection: "col2", Finished: true, Successful: &fals},
+				{Collection: "col3", Finished: true, Successful: &tru},
+			},
+		},
+	}
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "somerestorename"},
+		Spec: solr.SolrRestoreSpec{
+			SolrCloud:  "solrcloudcluster",
+			SolrBackup: "somebackupname",
+		},
+	}
+
+	collections, err := CollectionsToRestore(restore, backup)
+	assert.NoError(t, err, "Unexpected error finding the collections to restore")
+	assert.Equal(t, []solr.SolrRestoreCollection{{Name: "col1", Target: "col1"}, {Name: "col3", Target: "col3"}}, collections,
+		"All successfully backed up collections should be restored when none are listed")
+
+	restore.Spec.Collections = []solr.SolrRestoreCollection{{Name: "col3", Target: "col3-restored"}}
+	collections, err = CollectionsToRestore(restore, backup)
+	assert.NoError(t, err, "Unexpected error finding the collections to restore")
+	assert.Equal(t, restore.Spec.Collections, collections, "The listed collections should be restored")
+
+	restore.Spec.Collections = nil
+	_, err = CollectionsToRestore(restore, nil)
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "Collections must be listed to restore a backup that is not managed by a SolrBackup")
+}
+
+func TestCheckStatusOfCollectionRestores(t *testing.T) {
+	tru := true
+	fals := false
+	restore := &solr.SolrRestore{}
+
+	allFinished, allSuccessful := CheckStatusOfCollectionRestores(restore)
+	assert.False(t, allFinished, "A restore without any collections has not finished")
+	assert.False(t, allSuccessful, "A restore without any collections has not succeeded")
+
+	restore.Status.CollectionRestoreStatuses = []solr.CollectionRestoreStatus{
+		{Collection: "col1", Target: "col1", Finished: true, Successful: &tru},
+		{Collection: "col2", Target: "col2", InProgress: true},
+	}
+	allFinished, allSuccessful = CheckStatusOfCollectionRestores(restore)
+	assert.False(t, allFinished, "The restore has not finished while a collection is in progress")
+	assert.False(t, allSuccessful, "The restore has not succeeded while a collection is in progress")
+
+	restore.Status.CollectionRestoreStatuses[1] = solr.CollectionRestoreStatus{Collection: "col2", Target: "col2", Finished: true, Successful: &fals}
+	allFinished, allSuccessful = CheckStatusOfCollectionRestores(restore)
+	assert.True(t, allFinished, "The restore has finished once all collections have finished")
+	assert.False(t, allSuccessful, "The restore has failed if any collection has failed")
+
+	restore.Status.CollectionRestoreStatuses[1].Successful = &tru
+	allFinished, allSuccessful = CheckStatusOfCollectionRestores(restore)
+	assert.True(t, allFinished, "The restore has finished once all collections have finished")
+	assert.True(t, allSuccessful, "The restore has succeeded once all collections have succeeded")
+}
+
+func TestBackupRepositoryForRestore(t *testing.T) {
+	volume := corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs-server", Path: "/backups"}}
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "dr-cloud"},
+		Spec: solr.SolrCloudSpec{
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "gcs-other", GCS: &solr.GcsRepository{Bucket: "other-bucket"}},
+				{Name: "gcs-dr", GCS: &solr.GcsRepository{Bucket: "backup-bucket", BaseLocation: "/solr"}},
+				{Name: "local-dr", Managed: &solr.ManagedRepository{Volume: volume, Directory: "primary"}},
+			},
+		},
+	}
+
+	repo, err := BackupRepositoryForRestore(cloud, &solr.SolrBackupRepository{Name: "gcs-primary", GCS: &solr.GcsRepository{Bucket: "backup-bucket", BaseLocation: "/solr"}})
+	assert.NoError(t, err, "Unexpected error finding the GCS repository to restore from")
+	assert.Equal(t, "gcs-dr", repo.Name, "The GCS repository with the same bucket and base location should be used, regardless of its name")
+
+	_, err = BackupRepositoryForRestore(cloud, &solr.SolrBackupRepository{Name: "gcs-primary", GCS: &solr.GcsRepository{Bucket: "backup-bucket"}})
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "A GCS repository with a different base location does not contain the backup")
+
+	repo, err = BackupRepositoryForRestore(cloud, &solr.SolrBackupRepository{Name: "local-primary", Managed: &solr.ManagedRepository{Volume: volume, Directory: "primary"}})
+	assert.NoError(t, err, "Unexpected error finding the managed repository to restore from")
+	assert.Equal(t, "local-dr", repo.Name, "The managed repository with the same volume and directory should be used, regardless of its name")
+
+	_, err = BackupRepositoryForRestore(cloud, &solr.SolrBackupRepository{Name: "local-primary", Managed: &solr.ManagedRepository{Volume: volume, Directory: "other"}})
+	_, isTerminal = AsTerminalError(err)
+	assert.True(t, isTerminal, "A managed repository with a different directory does not contain the backup")
+
+	_, err = BackupRepositoryForRestore(cloud, &solr.SolrBackupRepository{Name: "local-primary", Managed: &solr.ManagedRepository{Volume: volume}})
+	_, isTerminal = AsTerminalError(err)
+	assert.True(t, isTerminal, "The directory of a managed repository must be given, since it defaults to the name of the SolrCloud that took the backup")
+}
+
+func TestClusterStateToRestore(t *testing.T) {
+	tru := true
+	fals := false
+	collections := []solr.CollectionRestoreStatus{
+		{Collection: "col1", Target: "restored1", Successful: &tru},
+		{Collection: "col2", Target: "col2", Successful: &tru},
+		{Collection: "col3", Target: "col3", Successful: &fals},
+	}
+	exported := map[string]string{
+		"/collections/col1/collectionprops.json": `{"prop":"1"}`,
+		"/collections/col3/collectionprops.json": `{"prop":"3"}`,
+		"/aliases.json":                          `{"collection":{"both":"col1,col2","failed":"col1,col3","existing":"col2"},"collection_metadata":{"both":{"meta":"data"}}}`,
+		"/clusterprops.json":                     `{"urlScheme":"https","maxCoresPerNode":"2"}`,
+		"/security.json":                         `{"authentication":{}}`,
+	}
+	current := map[string]string{
+		"/aliases.json":      `{"collection":{"existing":"other"}}`,
+		"/clusterprops.json": `{"urlScheme":"http"}`,
+	}
+
+	znodeData, err := ClusterStateToRestore(exported, current, collections, false)
+	assert.NoError(t, err, "Restoring valid cluster state should not fail")
+	assert.Equal(t, map[string]string{
+		"/collections/restored1/collectionprops.json": `{"prop":"1"}`,
+		"/aliases.json":      `{"collection":{"both":"restored1,col2","existing":"other"},"collection_metadata":{"both":{"meta":"data"}}}`,
+		"/clusterprops.json": `{"maxCoresPerNode":"2","urlScheme":"http"}`,
+	}, znodeData, "Only the state of successfully restored collections, and aliases and cluster properties that do not exist yet, should be restored")
+
+	znodeData, err = ClusterStateToRestore(exported, current, collections, true)
+	assert.NoError(t, err, "Restoring valid cluster state should not fail")
+	assert.Equal(t, `{"authentication":{}}`, znodeData["/security.json"], "The security.json should be restored when requested")
+
+	znodeData, err = ClusterStateToRestore(map[string]string{"/clusterprops.json": `{"urlScheme":"http"}`}, current, collections, false)
+	assert.NoError(t, err, "Restoring valid cluster state should not fail")
+	assert.Empty(t, znodeData, "Znodes that would not change should not be restored")
+
+	_, err = ClusterStateToRestore(map[string]string{"/aliases.json": "not json"}, current, collections, false)
+	assert.Error(t, err, "Invalid exported aliases should fail")
+}
+
+func TestGenerateClusterStateRestoreCommand(t *testing.T) {
+	assert.Equal(t, "dir=$(mktemp -d) && trap 'rm -rf \"$dir\"' EXIT && tar -x -C \"$dir\""+
+		" && solr zk cp \"file:$dir\"'/aliases.json' 'zk:/aliases.json' -z ${ZK_HOST} > /dev/null"+
+		" && solr zk cp \"file:$dir\"'/collections/col1/collectionprops.json' 'zk:/collections/col1/collectionprops.json' -z ${ZK_HOST} > /dev/null",
+		GenerateClusterStateRestoreCommand([]string{"/aliases.json", "/collections/col1/collectionprops.json"}),
+		"Each znode should be copied from the extracted archive, which should be removed afterwards")
+	assert.Contains(t, GenerateClusterStateRestoreCommand([]string{"/collections/a'; rm -rf /; '/collectionprops.json"}),
+		`"file:$dir"'/collections/a'\''; rm -rf /; '\''/collectionprops.json'`,
+		"Znode names should never be interpreted by the shell")
+}
+
+func TestGenerateClusterStateReadCommand(t *testing.T) {
+	assert.Equal(t, "if [ -d '/var/solr/data/backup-restore/main/backups/b1/"+ClusterStateBackupDirectory+"' ]; then tar -c -C '/var/solr/data/backup-restore/main/backups/b1/"+ClusterStateBackupDirectory+"' .; fi",
+		GenerateClusterStateReadCommand("/var/solr/data/backup-restore/main/backups/b1"),
+		"The cluster state directory should be archived if it exists")
+	assert.Equal(t, "if [ -d '/backups/$(reboot)'\\'' `id`/"+ClusterStateBackupDirectory+"' ]; then tar -c -C '/backups/$(reboot)'\\'' `id`/"+ClusterStateBackupDirectory+"' .; fi",
+		GenerateClusterStateReadCommand("/backups/$(reboot)' `id`"),
+		"The backup location should never be interpreted by the shell")
+}
+
+func TestCheckBackupPointForRestore(t *testing.T) {
+	solr8 := version.MustParseGeneric("8.11.1")
+	complete := solr_api.SolrBackupPoint{BackupId: 0, EndTime: "2021-09-02T10:05:00Z", ShardBackupIds: map[string]string{"shard1": "md_shard1_0.json"}, ConfigName: "techproducts", IndexVersion: "8.9.0"}
+
+	assert.Empty(t, CheckBackupPointForRestore(nil, nil, solr8), "Backups without backup points cannot be checked")
+	assert.Empty(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{complete}, nil, solr8), "A complete backup of the same major version should be restorable")
+	assert.Empty(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{complete}, nil, version.MustParseGeneric("9.0.0")), "The previous major version of Lucene should be readable")
+	assert.Contains(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{complete}, nil, version.MustParseGeneric("10.0.0")), "cannot read", "Lucene indexes older than the previous major version should not be restorable")
+
+	newer := complete
+	newer.BackupId = 1
+	newer.IndexVersion = "9.0.0"
+	assert.Contains(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{newer, complete}, nil, solr8), "newer than Solr", "The latest backup point should be checked, and indexes from newer Lucene versions should not be restorable")
+	backupId := int32(0)
+	assert.Empty(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{newer, complete}, &backupId, solr8), "The requested backup point should be checked")
+	backupId = 2
+	assert.Contains(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{newer, complete}, &backupId, solr8), "does not exist", "A missing backup point should not be restorable")
+
+	noConfig := complete
+	noConfig.ConfigName = ""
+	assert.Contains(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{noConfig}, nil, solr8), "configset", "A backup without its configset should not be restorable")
+
+	incomplete := complete
+	incomplete.EndTime = ""
+	assert.Contains(t, CheckBackupPointForRestore([]solr_api.SolrBackupPoint{incomplete}, nil, solr8), "not completely written", "An incomplete backup should not be restorable")
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// securityJsonUploaded is printed by the sync command when it uploaded the security.json to Zookeeper
+	securityJsonUploaded = "uploaded"
+)
+
+// UsesManagedSecurityJson returns whether the operator keeps the security.json in Zookeeper in sync with the securityJsonSecret
+func UsesManagedSecurityJson(solrCloud *solr.SolrCloud) bool {
+	return solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.SecurityJsonMode == solr.ManagedSecurityJson
+}
+
+// ValidateManagedSecurityJsonOptions checks that the options needed to manage the security.json are provided
+func ValidateManagedSecurityJsonOptions(solrCloud *solr.SolrCloud) error {
+	if !UsesManagedSecurityJson(solrCloud) {
+		return nil
+	}
+	sec := solrCloud.Spec.SolrSecurity
+	if sec.SecurityJsonSecret == nil || sec.SecurityJsonSecret.Name == "" || sec.SecurityJsonSecret.Key == "" {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.securityJsonSecret' must provide the name and key of the security.json secret when the securityJsonMode is %s", solr.ManagedSecurityJson)
+	}
+	// The operator cannot bootstrap credentials for itself into a security.json that it does not own
+	if solrCloud.UsesBasicAuth() && sec.BasicAuthSecret == "" {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.basicAuthSecret' must be provided when the securityJsonMode is %s, with the credentials of a user in the managed security.json", solr.ManagedSecurityJson)
+	}
+	return nil
+}
+
+// ManagedSecurityJsonContent returns the security.json from the securityJsonSecret, and its hash.
+// An error is returned if the key is missing or does not contain a JSON object.
+func ManagedSecurityJsonContent(selector *corev1.SecretKeySelector, secret *corev1.Secret) (content []byte, contentHash string, err error) {
+	content, hasKey := secret.Data[selector.Key]
+	if !hasKey {
+		return nil, "", fmt.Errorf("key %s not found in security.json secret %s", selector.Key, secret.Name)
+	}
+	securityJson := map[string]interface{}{}
+	if err = json.Unmarshal(content, &securityJson); err != nil {
+		return nil, "", fmt.Errorf("the security.json in secret %s is not a valid JSON object: %w", secret.Name, err)
+	}
+	return content, HashContent(content), nil
+}
+
+// SyncManagedSecurityJson uploads the security.json to Zookeeper, through the given Solr pod, if it differs from the security.json already in Zookeeper.
+// Whether the security.json was uploaded is returned.
+func SyncManagedSecurityJson(solrCloud *solr.SolrCloud, podName string, content []byte, config *rest.Config) (uploaded bool, err error) {
+	output, err := RunExecForPodWithInput(
+		podName,
+		solrCloud.Namespace,
+		[]string{"/bin/bash", "-c", GenerateSecurityJsonSyncCommand()},
+		bytes.NewReader(content),
+		*config,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error syncing the security.json through pod %s: %w", podName, err)
+	}
+	return strings.TrimSpace(output) == securityJsonUploaded, nil
+}
+
+// GenerateSecurityJsonSyncCommand returns the shell command that compares the security.json in Zookeeper with the content read from stdin,
+// and uploads the content if they differ, or if there is no security.json in Zookeeper.
+// The files are written to a temporary directory, that is removed however the command exits.
+func GenerateSecurityJsonSyncCommand() string {
+	return "dir=$(mktemp -d) && trap 'rm -rf \"$dir\"' EXIT && cat > \"$dir/managed-security.json\" && " +
+		"if solr zk cp zk:/security.json \"$dir/current-security.json\" -z ${ZK_HOST} > /dev/null 2>&1 && cmp -s \"$dir/managed-security.json\" \"$dir/current-security.json\"; " +
+		"then echo unchanged; " +
+		"else solr zk cp \"file:$dir/managed-security.json\" zk:/security.json -z ${ZK_HOST} > /dev/null && echo " + securityJsonUploaded + "; fi"
+}
@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateManagedSecurityJsonOptions(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{
+				AuthenticationType: solr.Basic,
+				SecurityJsonMode:   solr.ManagedSecurityJson,
+			},
+		},
+	}
+	assert.Error(t, ValidateManagedSecurityJsonOptions(solrCloud), "The securityJsonSecret is required in the Managed mode")
+
+	solrCloud.Spec.SolrSecurity.SecurityJsonSecret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "security"}, Key: "security.json"}
+	assert.Error(t, ValidateManagedSecurityJsonOptions(solrCloud), "The operator's credentials cannot be generated in the Managed mode")
+
+	solrCloud.Spec.SolrSecurity.BasicAuthSecret = "operator-creds"
+	assert.NoError(t, ValidateManagedSecurityJsonOptions(solrCloud))
+
+	solrCloud.Spec.SolrSecurity = &solr.SolrSecurityOptions{AuthenticationType: solr.Basic}
+	assert.NoError(t, ValidateManagedSecurityJsonOptions(solrCloud), "Nothing is required in the Bootstrap mode")
+}
+
+func TestManagedSecurityJsonContent(t *testing.T) {
+	selector := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "security"}, Key: "security.json"}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "security"},
+		Data:       map[string][]byte{"security.json": []byte(`{"authentication":{"class":"solr.BasicAuthPlugin"}}`)},
+	}
+	content, contentHash, err := ManagedSecurityJsonContent(selector, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, secret.Data["security.json"], content)
+	assert.Equal(t, HashContent(content), contentHash)
+
+	secret.Data["security.json"] = []byte("not json")
+	_, _, err = ManagedSecurityJsonContent(selector, secret)
+	assert.Error(t, err, "Invalid JSON should not be synced")
+
+	delete(secret.Data, "security.json")
+	_, _, err = ManagedSecurityJsonContent(selector, secret)
+	assert.Error(t, err, "A missing key should be reported")
+}
+
+func TestGenerateSecurityJsonSyncCommand(t *testing.T) {
+	command := GenerateSecurityJsonSyncCommand()
+	assert.Contains(t, command, "cat > \"$dir/managed-security.json\"", "The content should be read from stdin")
+	assert.Contains(t, command, "trap 'rm -rf \"$dir\"' EXIT", "The temporary files should always be removed")
+	assert.Contains(t, command, "solr zk cp \"file:$dir/managed-security.json\" zk:/security.json -z ${ZK_HOST}", "The security.json should be uploaded to Zookeeper")
+	assert.Contains(t, command, "echo "+securityJsonUploaded, "The command should report when it uploaded the security.json")
+}
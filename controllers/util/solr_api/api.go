@@ -18,16 +18,26 @@
 package solr_api
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
+	"io"
 	"io/ioutil"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 )
 
+// The default timeout for requests to Solr, if the SolrCloud does not specify one
+const DefaultRequestTimeout = time.Second * 30
+
 // Used to call a Solr pod over https when using a self-signed cert
 // It's "insecure" but is only used for internal communication, such as getting cluster status
 // so if you're worried about this, don't use a self-signed cert
@@ -42,6 +52,82 @@ func SetMTLSHttpClient(client *http.Client) {
 	mTLSHttpClient = client
 }
 
+var requestTimeout = DefaultRequestTimeout
+
+// SetRequestTimeout sets the timeout for requests to SolrClouds that do not specify their own timeout
+func SetRequestTimeout(timeout time.Duration) {
+	requestTimeout = timeout
+}
+
+// Http clients that verify the server certificates of individual SolrClouds, using the CA bundle provided for each SolrCloud
+var cloudCAHttpClients sync.Map
+
+type cloudCAHttpClient struct {
+	caBundleHash string
+	client       *http.Client
+}
+
+// SetCloudCABundle sets the PEM-encoded CA certificates that are used to verify the server certificate of the SolrCloud.
+// If the CA bundle is empty, the default http client of the Solr Operator is used for the SolrCloud.
+func SetCloudCABundle(cloud *solr.SolrCloud, caBundle []byte) error {
+	key := cloud.Namespace + "/" + cloud.Name
+	if len(caBundle) == 0 {
+		RemoveCloudCABundle(cloud.Namespace, cloud.Name)
+		return nil
+	}
+	caBundleHash := fmt.Sprintf("%x", sha256.Sum256(caBundle))
+	if existing, found := cloudCAHttpClients.Load(key); found && existing.(*cloudCAHttpClient).caBundleHash == caBundleHash {
+		// Keep using the same client, and therefore its open connections, if the CA bundle has not changed
+		return nil
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("no PEM-encoded certificates found in the CA bundle for SolrCloud %s", key)
+	}
+	// Start from the default client, so that the client certificate and FIPS restrictions of the Solr Operator are kept
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if defaultTransport, isTransport := defaultHttpClient().Transport.(*http.Transport); isTransport {
+		transport = defaultTransport.Clone()
+	}
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	tlsConfig.InsecureSkipVerify = false
+	tlsConfig.RootCAs = caCertPool
+	transport.TLSClientConfig = tlsConfig
+
+	cloudCAHttpClients.Store(key, &cloudCAHttpClient{caBundleHash: caBundleHash, client: &http.Client{Transport: transport}})
+	return nil
+}
+
+// RemoveCloudCABundle stops using a custom CA bundle for the SolrCloud, e.g. once it has been deleted
+func RemoveCloudCABundle(namespace string, name string) {
+	cloudCAHttpClients.Delete(namespace + "/" + name)
+}
+
+func defaultHttpClient() *http.Client {
+	if mTLSHttpClient != nil {
+		return mTLSHttpClient
+	}
+	return noVerifyTLSHttpClient
+}
+
+func httpClientForCloud(cloud *solr.SolrCloud) *http.Client {
+	if cloudClient, found := cloudCAHttpClients.Load(cloud.Namespace + "/" + cloud.Name); found {
+		return cloudClient.(*cloudCAHttpClient).client
+	}
+	return defaultHttpClient()
+}
+
+func requestTimeoutForCloud(cloud *solr.SolrCloud) time.Duration {
+	if cloud.Spec.OperatorClient != nil && cloud.Spec.OperatorClient.TimeoutSeconds != nil {
+		return time.Second * time.Duration(*cloud.Spec.OperatorClient.TimeoutSeconds)
+	}
+	return requestTimeout
+}
+
 type SolrAsyncResponse struct {
 	ResponseHeader SolrResponseHeader `json:"responseHeader"`
 
@@ -66,26 +152,42 @@ type SolrAsyncStatus struct {
 }
 
 func CallCollectionsApi(cloud *solr.SolrCloud, urlParams url.Values, httpHeaders map[string]string, response interface{}) (err error) {
-	cloudUrl := solr.InternalURLForCloud(cloud)
+	urlParams.Set("wt", "json")
 
-	client := noVerifyTLSHttpClient
-	if mTLSHttpClient != nil {
-		client = mTLSHttpClient
-	}
+	cloudUrl := solr.InternalURLForCloud(cloud) + "/solr/admin/collections?" + urlParams.Encode()
+
+	return callSolrApi(cloud, "GET", cloudUrl, nil, "", httpHeaders, response)
+}
 
+// CallConfigSetsApi sends a POST request to the ConfigSets API, with the given body, such as the content of a file to upload
+func CallConfigSetsApi(cloud *solr.SolrCloud, urlParams url.Values, body []byte, httpHeaders map[string]string, response interface{}) (err error) {
 	urlParams.Set("wt", "json")
 
-	cloudUrl = cloudUrl + "/solr/admin/collections?" + urlParams.Encode()
+	cloudUrl := solr.InternalURLForCloud(cloud) + "/solr/admin/configs?" + urlParams.Encode()
+
+	return callSolrApi(cloud, "POST", cloudUrl, bytes.NewReader(body), "application/octet-stream", httpHeaders, response)
+}
+
+// callSolrApi sends a request to the given URL of a SolrCloud, and decodes the JSON response into the given response.
+// The body is sent with the given content type, if there is one. Responses with a status code other than 200 are returned as errors.
+func callSolrApi(cloud *solr.SolrCloud, method string, requestUrl string, body io.Reader, contentType string, httpHeaders map[string]string, response interface{}) (err error) {
+	client := httpClientForCloud(cloud)
 
 	resp := &http.Response{}
 
-	req, err := http.NewRequest("GET", cloudUrl, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutForCloud(cloud))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 
 	// mainly for doing basic-auth
-	if httpHeaders != nil {
-		for key, header := range httpHeaders {
-			req.Header.Add(key, header)
-		}
+	for key, header := range httpHeaders {
+		req.Header.Add(key, header)
 	}
 
 	if resp, err = client.Do(req); err != nil {
@@ -94,9 +196,9 @@ func CallCollectionsApi(cloud *solr.SolrCloud, urlParams url.Values, httpHeaders
 
 	defer resp.Body.Close()
 
-	if err == nil && resp.StatusCode != 200 {
+	if resp.StatusCode != 200 {
 		b, _ := ioutil.ReadAll(resp.Body)
-		err = errors.NewServiceUnavailable(fmt.Sprintf("Recieved bad response code of %d from solr with response: %s", resp.StatusCode, string(b)))
+		err = errors.NewServiceUnavailable(fmt.Sprintf("Received bad response code of %d from solr with response: %s", resp.StatusCode, string(b)))
 	}
 
 	if err == nil {
@@ -106,6 +208,44 @@ func CallCollectionsApi(cloud *solr.SolrCloud, urlParams url.Values, httpHeaders
 	return err
 }
 
+// CallNodeAdminApi sends a GET request to an admin handler of a specific Solr node, given by its base URL, such as "/solr/admin/info/threads".
+// The body of the response is returned as-is, rather than decoded, since it is meant to be passed on rather than interpreted.
+func CallNodeAdminApi(cloud *solr.SolrCloud, nodeUrl string, path string, urlParams url.Values, httpHeaders map[string]string) (body []byte, err error) {
+	client := httpClientForCloud(cloud)
+
+	urlParams.Set("wt", "json")
+
+	adminUrl := nodeUrl + path + "?" + urlParams.Encode()
+
+	resp := &http.Response{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutForCloud(cloud))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", adminUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// mainly for doing basic-auth
+	if httpHeaders != nil {
+		for key, header := range httpHeaders {
+			req.Header.Add(key, header)
+		}
+	}
+
+	if resp, err = client.Do(req); err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if body, err = ioutil.ReadAll(resp.Body); err == nil && resp.StatusCode != 200 {
+		err = errors.NewServiceUnavailable(fmt.Sprintf("Received bad response code of %d from solr with response: %s", resp.StatusCode, string(body)))
+	}
+
+	return body, err
+}
+
 func init() {
 	// setup an http client that can talk to Solr pods using untrusted, self-signed certs
 	customTransport := http.DefaultTransport.(*http.Transport).Clone()
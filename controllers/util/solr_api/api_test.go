@@ -0,0 +1,73 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package solr_api
+
+import (
+	"encoding/pem"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCloudCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	otherCloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"}}
+
+	assert.NoError(t, SetCloudCABundle(cloud, caBundle), "Could not set the CA bundle")
+	client := httpClientForCloud(cloud)
+	assert.NotSame(t, noVerifyTLSHttpClient, client, "The SolrCloud should have its own client")
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err, "The server cert should be verified with the CA bundle") {
+		resp.Body.Close()
+	}
+	assert.Same(t, noVerifyTLSHttpClient, httpClientForCloud(otherCloud), "Other SolrClouds should use the default client")
+
+	assert.NoError(t, SetCloudCABundle(cloud, caBundle), "Could not set the CA bundle again")
+	assert.Same(t, client, httpClientForCloud(cloud), "The client should be reused if the CA bundle has not changed")
+
+	assert.Error(t, SetCloudCABundle(cloud, []byte("not a cert")), "A CA bundle without certificates should be rejected")
+
+	RemoveCloudCABundle(cloud.Namespace, cloud.Name)
+	assert.Same(t, noVerifyTLSHttpClient, httpClientForCloud(cloud), "The default client should be used once the CA bundle is removed")
+}
+
+func TestRequestTimeoutForCloud(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	assert.Equal(t, DefaultRequestTimeout, requestTimeoutForCloud(cloud), "The default timeout should be used")
+
+	timeout := int32(90)
+	cloud.Spec.OperatorClient = &solr.SolrOperatorClientOptions{TimeoutSeconds: &timeout}
+	assert.Equal(t, time.Second*90, requestTimeoutForCloud(cloud), "The SolrCloud's timeout should be used")
+}
+
+func TestIsCollectionNotFound(t *testing.T) {
+	notFound := errors.NewServiceUnavailable(`Received bad response code of 400 from solr with response: {"error":{"msg":"Collection: products not found","code":400}}`)
+	assert.True(t, IsCollectionNotFound(notFound, "products"), "A CLUSTERSTATUS for a deleted collection should be recognized")
+	assert.False(t, IsCollectionNotFound(notFound, "prod"), "Only the requested collection should be matched")
+	assert.False(t, IsCollectionNotFound(errors.NewServiceUnavailable("connection refused"), "products"))
+	assert.False(t, IsCollectionNotFound(nil, "products"))
+}
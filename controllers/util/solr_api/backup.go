@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package solr_api
+
+// SolrListBackupResponse is the response of the LISTBACKUP Collections API action, listing the backup points of a backup
+type SolrListBackupResponse struct {
+	ResponseHeader SolrResponseHeader `json:"responseHeader"`
+
+	// +optional
+	Collection string `json:"collection,omitempty"`
+
+	// +optional
+	Backups []SolrBackupPoint `json:"backups,omitempty"`
+}
+
+// SolrDeleteBackupResponse is the response of the DELETEBACKUP Collections API action, listing the deleted backup points
+type SolrDeleteBackupResponse struct {
+	ResponseHeader SolrResponseHeader `json:"responseHeader"`
+
+	// +optional
+	Deleted []SolrBackupPoint `json:"deleted,omitempty"`
+}
+
+// SolrBackupPoint describes a single backup point of an incremental backup
+type SolrBackupPoint struct {
+	BackupId int32 `json:"backupId"`
+
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+
+	// Only set once the backup point has been completely written to the backup repository
+	// +optional
+	EndTime string `json:"endTime,omitempty"`
+
+	// +optional
+	IndexFileCount int `json:"indexFileCount,omitempty"`
+
+	// The Lucene version that the backed-up index was written with
+	// +optional
+	IndexVersion string `json:"indexVersion,omitempty"`
+
+	// The configset of the backed-up collection, which is stored with the backup
+	// +optional
+	ConfigName string `json:"collection.configName,omitempty"`
+
+	// The metadata file of each shard's backup, keyed by the shard name
+	// +optional
+	ShardBackupIds map[string]string `json:"shardBackupIds,omitempty"`
+}
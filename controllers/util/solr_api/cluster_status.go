@@ -40,6 +40,13 @@ type SolrClusterStatusResponse struct {
 	ClusterStatus SolrClusterStatus `json:"cluster"`
 }
 
+type SolrCollectionsListResponse struct {
+	ResponseHeader SolrResponseHeader `json:"responseHeader"`
+
+	// +optional
+	Collections []string `json:"collections"`
+}
+
 type SolrClusterStatus struct {
 	// +optional
 	Collections map[string]SolrCollectionStatus `json:"collections"`
@@ -84,6 +91,9 @@ type SolrCollectionStatus struct {
 
 	// +optional
 	Router SolrCollectionRouter `json:"router"`
+
+	// +optional
+	ReadOnly string `json:"readOnly"`
 }
 
 type SolrCollectionRouter struct {
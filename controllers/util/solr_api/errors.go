@@ -17,7 +17,10 @@
 
 package solr_api
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func CheckForCollectionsApiError(action string, header SolrResponseHeader) (hasError bool, err error) {
 	if header.Status > 0 {
@@ -30,6 +33,17 @@ func CheckForCollectionsApiError(action string, header SolrResponseHeader) (hasE
 	return hasError, err
 }
 
+func CheckForConfigSetsApiError(action string, header SolrResponseHeader) (hasError bool, err error) {
+	if header.Status > 0 {
+		hasError = true
+		err = APIError{
+			Detail: fmt.Sprintf("Error occured while calling the ConfigSets api for action=%s", action),
+			Status: header.Status,
+		}
+	}
+	return hasError, err
+}
+
 func CollectionsAPIError(action string, responseStatus int) error {
 	return APIError{
 		Detail: fmt.Sprintf("Error occured while calling the Collections api for action=%s", action),
@@ -48,3 +62,9 @@ func (e APIError) Error() string {
 	}
 	return fmt.Sprintf("Solr response status: %d. %s", e.Status, e.Detail)
 }
+
+// IsCollectionNotFound returns whether the error is Solr's response to a request for a collection that does not exist,
+// such as a CLUSTERSTATUS for a collection that was deleted after it was listed
+func IsCollectionNotFound(err error, collection string) bool {
+	return err != nil && strings.Contains(err.Error(), "Collection: "+collection+" not found")
+}
@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package solr_api
+
+import (
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"net/url"
+	"strings"
+)
+
+type SolrStreamResponse struct {
+	ResultSet SolrStreamResultSet `json:"result-set"`
+}
+
+type SolrStreamResultSet struct {
+	// The tuples returned by the stream. The last tuple is the EOF marker, and contains the exception if the stream failed.
+	Docs []SolrStreamTuple `json:"docs"`
+}
+
+type SolrStreamTuple struct {
+	// +optional
+	Id string `json:"id,omitempty"`
+
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// +optional
+	Iterations int64 `json:"iterations,omitempty"`
+
+	// +optional
+	DaemonOp string `json:"DaemonOp,omitempty"`
+
+	// +optional
+	EOF bool `json:"EOF,omitempty"`
+
+	// +optional
+	Exception string `json:"EXCEPTION,omitempty"`
+}
+
+// CheckForStreamError returns the exception of a stream, which Solr reports in the EOF tuple rather than through the response status
+func CheckForStreamError(action string, response *SolrStreamResponse) error {
+	for _, tuple := range response.ResultSet.Docs {
+		if tuple.Exception != "" {
+			return APIError{
+				Detail: fmt.Sprintf("Error occured while calling the Stream handler for %s: %s", action, tuple.Exception),
+			}
+		}
+	}
+	return nil
+}
+
+// CallStreamHandler sends a request to the /stream handler of a collection on a specific Solr node, given by its base URL.
+// Streaming daemons only live on the node that they were submitted to, so they cannot be managed through the common service.
+func CallStreamHandler(cloud *solr.SolrCloud, nodeUrl string, collection string, urlParams url.Values, httpHeaders map[string]string, response *SolrStreamResponse) (err error) {
+	urlParams.Set("wt", "json")
+
+	streamUrl := nodeUrl + "/solr/" + url.PathEscape(collection) + "/stream"
+
+	// Expressions can be long, so the parameters are sent in the body instead of the URL
+	return callSolrApi(cloud, "POST", streamUrl, strings.NewReader(urlParams.Encode()), "application/x-www-form-urlencoded", httpHeaders, response)
+}
@@ -0,0 +1,104 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package solr_api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"net/http"
+	"net/url"
+)
+
+type SolrZookeeperResponse struct {
+	// +optional
+	Znode *SolrZnode `json:"znode,omitempty"`
+
+	// Set, instead of the znode, when the path cannot be read
+	// +optional
+	Status int `json:"status,omitempty"`
+
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+type SolrZnode struct {
+	Path string `json:"path"`
+
+	// +optional
+	Data string `json:"data,omitempty"`
+}
+
+// GetZookeeperData reads the data of a znode, relative to the chroot of the SolrCloud, through the Zookeeper handler of Solr.
+// A missing znode is not an error, instead exists is false.
+func GetZookeeperData(cloud *solr.SolrCloud, path string, httpHeaders map[string]string) (data string, exists bool, err error) {
+	urlParams := url.Values{}
+	urlParams.Set("wt", "json")
+	urlParams.Set("detail", "true")
+	urlParams.Set("path", path)
+
+	cloudUrl := solr.InternalURLForCloud(cloud) + "/solr/admin/zookeeper?" + urlParams.Encode()
+
+	client := httpClientForCloud(cloud)
+
+	resp := &http.Response{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutForCloud(cloud))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", cloudUrl, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	// mainly for doing basic-auth
+	if httpHeaders != nil {
+		for key, header := range httpHeaders {
+			req.Header.Add(key, header)
+		}
+	}
+
+	if resp, err = client.Do(req); err != nil {
+		return "", false, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return "", false, nil
+	} else if resp.StatusCode != 200 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return "", false, errors.NewServiceUnavailable(fmt.Sprintf("Received bad response code of %d from solr with response: %s", resp.StatusCode, string(b)))
+	}
+
+	response := &SolrZookeeperResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return "", false, err
+	}
+	if response.Status == 404 {
+		return "", false, nil
+	} else if response.Znode == nil {
+		return "", false, APIError{
+			Detail: fmt.Sprintf("Error occured while reading the znode %s: %s", path, response.Error),
+			Status: response.Status,
+		}
+	}
+	return response.Znode.Data, true, nil
+}
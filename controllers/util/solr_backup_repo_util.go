@@ -28,6 +28,9 @@ const (
 
 	GCSCredentialSecretKey = "service-account-key.json"
 
+	WorkloadIdentityTokenKey            = "token"
+	WorkloadIdentityCredentialConfigKey = "credential-configuration.json"
+
 	DistLibs    = "/opt/solr/dist"
 	ContribLibs = "/opt/solr/contrib/%s/lib"
 )
@@ -55,6 +58,28 @@ func GcsRepoSecretMountPath(repo *solrv1beta1.SolrBackupRepository) string {
 	return fmt.Sprintf("%s/%s/%s", BaseBackupRestorePath, repo.Name, "gcscredential")
 }
 
+// GcsRepoCredentialPath returns the path of the credentials file that Solr should use for a GCS repository.
+// This is either a service account key, or an "external_account" credential configuration when using workload identity.
+func GcsRepoCredentialPath(repo *solrv1beta1.SolrBackupRepository) string {
+	if repo.GCS.UsesWorkloadIdentity() {
+		return fmt.Sprintf("%s/%s", WorkloadIdentityRepoMountPath(repo), WorkloadIdentityCredentialConfigKey)
+	}
+	return fmt.Sprintf("%s/%s", GcsRepoSecretMountPath(repo), GCSCredentialSecretKey)
+}
+
+// RepoCredentialSecret returns the secret that holds the credentials of the backup repository, if it uses one.
+// Repositories that use workload identity have no static credentials.
+func RepoCredentialSecret(repo *solrv1beta1.SolrBackupRepository) *corev1.SecretKeySelector {
+	if repo.GCS != nil && !repo.GCS.UsesWorkloadIdentity() && repo.GCS.GcsCredentialSecret.Name != "" {
+		return &repo.GCS.GcsCredentialSecret
+	}
+	return nil
+}
+
+func WorkloadIdentityRepoMountPath(repo *solrv1beta1.SolrBackupRepository) string {
+	return fmt.Sprintf("%s/%s/%s", BaseBackupRestorePath, repo.Name, "workload-identity")
+}
+
 func ManagedRepoVolumeMountPath(repo *solrv1beta1.SolrBackupRepository) string {
 	return fmt.Sprintf("%s/%s", BaseBackupRestorePath, repo.Name)
 }
@@ -68,6 +93,33 @@ func RepoVolumeSourceAndMount(repo *solrv1beta1.SolrBackupRepository, solrCloudN
 			SubPath:   BackupRestoreSubPathForCloud(repo.Managed.Directory, solrCloudName),
 			ReadOnly:  false,
 		}
+	} else if repo.GCS.UsesWorkloadIdentity() {
+		workloadIdentity := repo.GCS.WorkloadIdentity
+		source = &corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          workloadIdentity.Audience,
+							ExpirationSeconds: workloadIdentity.ExpirationSeconds,
+							Path:              WorkloadIdentityTokenKey,
+						},
+					},
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: workloadIdentity.CredentialConfig.LocalObjectReference,
+							Items:                []corev1.KeyToPath{{Key: workloadIdentity.CredentialConfig.Key, Path: WorkloadIdentityCredentialConfigKey}},
+							Optional:             &f,
+						},
+					},
+				},
+				DefaultMode: &SecretReadOnlyPermissions,
+			},
+		}
+		mount = &corev1.VolumeMount{
+			MountPath: WorkloadIdentityRepoMountPath(repo),
+			ReadOnly:  true,
+		}
 	} else if repo.GCS != nil {
 		source = &corev1.VolumeSource{
 			Secret: &corev1.SecretVolumeSource{
@@ -99,13 +151,19 @@ func RepoXML(repo *solrv1beta1.SolrBackupRepository) (xml string) {
 		xml = fmt.Sprintf(`
 <repository name="%s" class="org.apache.solr.gcs.GCSBackupRepository">
     <str name="gcsBucket">%s</str>
-    <str name="gcsCredentialPath">%s/%s</str>
-</repository>`, repo.Name, repo.GCS.Bucket, GcsRepoSecretMountPath(repo), GCSCredentialSecretKey)
+    <str name="gcsCredentialPath">%s</str>
+</repository>`, repo.Name, repo.GCS.Bucket, GcsRepoCredentialPath(repo))
 	}
 	return
 }
 
 func RepoEnvVars(repo *solrv1beta1.SolrBackupRepository) (envVars []corev1.EnvVar) {
+	if repo.GCS.UsesWorkloadIdentity() {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+			Value: GcsRepoCredentialPath(repo),
+		})
+	}
 	return envVars
 }
 
@@ -83,3 +83,71 @@ func TestManagedRepoAdditionalLibs(t *testing.T) {
 	}
 	assert.Empty(t, AdditionalRepoLibs(repo), "Managed Repos require no additional libraries for Solr")
 }
+
+func TestGCSRepoWorkloadIdentity(t *testing.T) {
+	expirationSeconds := int64(1200)
+	repo := &solr.SolrBackupRepository{
+		Name: "gcsrepository1",
+		GCS: &solr.GcsRepository{
+			Bucket: "some-bucket-name1",
+			WorkloadIdentity: &solr.GcsWorkloadIdentity{
+				Audience:          "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider",
+				ExpirationSeconds: &expirationSeconds,
+				CredentialConfig: corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "some-configmap-name1"},
+					Key:                  "some-config-key",
+				},
+			},
+		},
+	}
+	assert.EqualValuesf(t, `
+<repository name="gcsrepository1" class="org.apache.solr.gcs.GCSBackupRepository">
+    <str name="gcsBucket">some-bucket-name1</str>
+    <str name="gcsCredentialPath">/var/solr/data/backup-restore/gcsrepository1/workload-identity/credential-configuration.json</str>
+</repository>`, RepoXML(repo), "Wrong SolrXML entry for the GCS Repo using workload identity")
+
+	source, mount := RepoVolumeSourceAndMount(repo, "cloud")
+	if assert.NotNil(t, source.Projected, "A GCS Repo using workload identity should use a projected volume") {
+		assert.Len(t, source.Projected.Sources, 2, "Wrong number of projected sources for workload identity")
+		tokenSource := source.Projected.Sources[0].ServiceAccountToken
+		if assert.NotNil(t, tokenSource, "The first projected source should be the service account token") {
+			assert.Equal(t, repo.GCS.WorkloadIdentity.Audience, tokenSource.Audience, "Wrong audience for the projected service account token")
+			assert.Equal(t, &expirationSeconds, tokenSource.ExpirationSeconds, "Wrong expiration for the projected service account token")
+			assert.Equal(t, "token", tokenSource.Path, "Wrong path for the projected service account token")
+		}
+		configSource := source.Projected.Sources[1].ConfigMap
+		if assert.NotNil(t, configSource, "The second projected source should be the credential configuration") {
+			assert.Equal(t, "some-configmap-name1", configSource.Name, "Wrong ConfigMap for the credential configuration")
+			assert.Equal(t, []corev1.KeyToPath{{Key: "some-config-key", Path: "credential-configuration.json"}}, configSource.Items, "Wrong items for the credential configuration")
+		}
+	}
+	assert.Nil(t, source.Secret, "A GCS Repo using workload identity should not mount a credential secret")
+	assert.Equal(t, "/var/solr/data/backup-restore/gcsrepository1/workload-identity", mount.MountPath, "Wrong mount path for workload identity")
+	assert.True(t, mount.ReadOnly, "The workload identity volume should be mounted read-only")
+
+	assert.Equal(t, []corev1.EnvVar{{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/var/solr/data/backup-restore/gcsrepository1/workload-identity/credential-configuration.json"}}, RepoEnvVars(repo), "Wrong env vars for the GCS Repo using workload identity")
+
+	// Without workload identity, no env vars are necessary
+	repo.GCS.WorkloadIdentity = nil
+	assert.Empty(t, RepoEnvVars(repo), "GCS Repos using a credential secret require no env vars")
+}
+
+func TestRepoCredentialSecret(t *testing.T) {
+	repo := &solr.SolrBackupRepository{
+		Name: "gcsrepository1",
+		GCS: &solr.GcsRepository{
+			Bucket: "some-bucket-name1",
+			GcsCredentialSecret: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "some-secret-name1"},
+				Key:                  "some-secret-key",
+			},
+		},
+	}
+	assert.Equal(t, &repo.GCS.GcsCredentialSecret, RepoCredentialSecret(repo), "The credential secret of a GCS Repo should be tracked")
+
+	repo.GCS.WorkloadIdentity = &solr.GcsWorkloadIdentity{}
+	assert.Nil(t, RepoCredentialSecret(repo), "GCS Repos using workload identity have no credential secret")
+
+	managedRepo := &solr.SolrBackupRepository{Name: "managedrepository1", Managed: &solr.ManagedRepository{}}
+	assert.Nil(t, RepoCredentialSecret(managedRepo), "Managed Repos have no credential secret")
+}
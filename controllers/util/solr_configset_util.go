@@ -0,0 +1,286 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ConfigOverlayFile is where the Config API stores the changes that it makes to a configset
+const ConfigOverlayFile = "configoverlay.json"
+
+// ConfigSetFilesContent reads the files that should be synced into a configset from the ConfigMap, keyed by their path in the configset.
+// The hash of all files is returned as well, so that they are only synced again when one of them changes.
+func ConfigSetFilesContent(configSetFiles *solr.ConfigSetFiles, configMap *corev1.ConfigMap) (files map[string][]byte, contentHash string, err error) {
+	files = make(map[string][]byte, len(configSetFiles.Files))
+	for _, file := range configSetFiles.Files {
+		content, hasContent := configMap.Data[file.Key]
+		if !hasContent {
+			return nil, "", TerminalErrorf(InvalidSpecReason, "user provided ConfigMap %s must have the key '%s' for the configset %s",
+				configMap.Name, file.Key, configSetFiles.ConfigSet)
+		}
+		files[file.Path] = []byte(content)
+	}
+	return files, hashConfigSetFiles(files), nil
+}
+
+// ValidateSolrConfigSetSource checks that a SolrConfigSet reads its files from exactly one of a ConfigMap and a Secret
+func ValidateSolrConfigSetSource(configSet *solr.SolrConfigSet) error {
+	if (configSet.Spec.Source.ConfigMap == "") == (configSet.Spec.Source.Secret == "") {
+		return TerminalErrorf(InvalidSpecReason, "exactly one of configMap and secret must be provided as the source of SolrConfigSet %s", configSet.Name)
+	}
+	return nil
+}
+
+// ValidateSolrConfigSetTarget checks that the configset of a SolrConfigSet is not also managed through the configSetFiles of its SolrCloud.
+// The SolrConfigSet replaces the entire configset, so the two would keep overwriting each other's files.
+func ValidateSolrConfigSetTarget(configSet *solr.SolrConfigSet, cloud *solr.SolrCloud) error {
+	for _, configSetFiles := range cloud.Spec.ConfigSetFiles {
+		if configSetFiles.ConfigSet == configSet.Spec.ConfigSetName {
+			return TerminalErrorf(ConfigSetConflictReason, "the configset %s is already managed through the configSetFiles of the SolrCloud %s, it cannot be uploaded by SolrConfigSet %s as well",
+				configSet.Spec.ConfigSetName, cloud.Name, configSet.Name)
+		}
+	}
+	return nil
+}
+
+// SolrConfigSetContent reads the files of a SolrConfigSet from the data of its ConfigMap or Secret, keyed by their path in the configset.
+// If the SolrConfigSet does not list its files, every key is used as a file.
+// The hash of all files is returned as well, so that they are only uploaded again when one of them changes.
+func SolrConfigSetContent(configSet *solr.SolrConfigSet, data map[string][]byte) (files map[string][]byte, contentHash string, err error) {
+	source := configSet.Spec.Source
+	if len(source.Files) == 0 {
+		files = data
+	} else {
+		files = make(map[string][]byte, len(source.Files))
+		for _, file := range source.Files {
+			content, hasContent := data[file.Key]
+			if !hasContent {
+				return nil, "", TerminalErrorf(InvalidSpecReason, "the source of SolrConfigSet %s must have the key '%s'", configSet.Name, file.Key)
+			}
+			path := file.Path
+			if path == "" {
+				path = file.Key
+			}
+			files[path] = content
+		}
+	}
+	if len(files) == 0 {
+		return nil, "", TerminalErrorf(InvalidSpecReason, "the source of SolrConfigSet %s does not contain any files", configSet.Name)
+	}
+	return files, hashConfigSetFiles(files), nil
+}
+
+// hashConfigSetFiles hashes the paths and contents of all files of a configset
+func hashConfigSetFiles(files map[string][]byte) string {
+	var allContent bytes.Buffer
+	for _, path := range sortedConfigSetPaths(files) {
+		allContent.WriteString(path)
+		allContent.WriteByte(0)
+		allContent.Write(files[path])
+		allContent.WriteByte(0)
+	}
+	return HashContent(allContent.Bytes())
+}
+
+func sortedConfigSetPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// UploadConfigSet uploads the files as the entire configset, creating it if it does not exist yet.
+// Files of an existing configset that are not given are removed, so that the configset exactly matches its source.
+func UploadConfigSet(cloud *solr.SolrCloud, configSet string, files map[string][]byte, httpHeaders map[string]string) (err error) {
+	zipContent, err := configSetZip(files)
+	if err != nil {
+		return err
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("action", "UPLOAD")
+	queryParams.Add("name", configSet)
+	queryParams.Add("overwrite", "true")
+	queryParams.Add("cleanup", "true")
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallConfigSetsApi(cloud, queryParams, zipContent, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForConfigSetsApiError("UPLOAD", resp.ResponseHeader)
+	}
+	return err
+}
+
+// configSetZip packages the files of a configset into a zip file, as the ConfigSets API expects for uploads
+func configSetZip(files map[string][]byte) ([]byte, error) {
+	var zipContent bytes.Buffer
+	zipWriter := zip.NewWriter(&zipContent)
+	for _, path := range sortedConfigSetPaths(files) {
+		fileWriter, err := zipWriter.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = fileWriter.Write(files[path]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return zipContent.Bytes(), nil
+}
+
+// SyncConfigSetFiles uploads the files into the configset in Zookeeper, overwriting the existing files,
+// and then reloads every collection that uses the configset so that the new files take effect.
+func SyncConfigSetFiles(cloud *solr.SolrCloud, configSet string, files map[string][]byte, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (reloadedCollections []string, err error) {
+	for _, path := range sortedConfigSetPaths(files) {
+		logger.Info("Uploading file to configset", "configSet", configSet, "file", path)
+		if err = uploadConfigSetFile(cloud, configSet, path, files[path], httpHeaders); err != nil {
+			logger.Error(err, "Error uploading file to configset", "configSet", configSet, "file", path)
+			return nil, err
+		}
+	}
+
+	return ReloadCollectionsUsingConfigSet(cloud, configSet, clusterState, httpHeaders, logger)
+}
+
+// ReloadCollectionsUsingConfigSet reloads every collection that uses the configset, so that changes to the configset take effect.
+func ReloadCollectionsUsingConfigSet(cloud *solr.SolrCloud, configSet string, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (reloadedCollections []string, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return nil, err
+	}
+	for _, collection := range collectionsUsingConfigSet(clusterStatus, configSet) {
+		logger.Info("Reloading collection for updated configset", "configSet", configSet, "collection", collection)
+		if err = reloadCollection(cloud, collection, httpHeaders); err != nil {
+			logger.Error(err, "Error reloading collection for updated configset", "configSet", configSet, "collection", collection)
+			return reloadedCollections, err
+		}
+		reloadedCollections = append(reloadedCollections, collection)
+	}
+	return reloadedCollections, nil
+}
+
+// FindConfigSetDrift returns the paths, in order, of the files in the configset that no longer match the given files.
+// Changes made through the Config API are stored in the configoverlay.json of the configset,
+// so a non-empty overlay is reported as drift as well, unless the overlay is one of the given files.
+func FindConfigSetDrift(cloud *solr.SolrCloud, configSet string, files map[string][]byte, httpHeaders map[string]string) (driftedFiles []string, err error) {
+	for path, content := range files {
+		zkContent, exists, zkErr := solr_api.GetZookeeperData(cloud, "/configs/"+configSet+"/"+path, httpHeaders)
+		if zkErr != nil {
+			return nil, zkErr
+		}
+		if !exists || zkContent != string(content) {
+			driftedFiles = append(driftedFiles, path)
+		}
+	}
+	if _, isManaged := files[ConfigOverlayFile]; !isManaged {
+		overlay, exists, zkErr := solr_api.GetZookeeperData(cloud, "/configs/"+configSet+"/"+ConfigOverlayFile, httpHeaders)
+		if zkErr != nil {
+			return nil, zkErr
+		}
+		if exists && !isConfigOverlayEmpty(overlay) {
+			driftedFiles = append(driftedFiles, ConfigOverlayFile)
+		}
+	}
+	sort.Strings(driftedFiles)
+	return driftedFiles, nil
+}
+
+// ConfigSetDriftRepairs returns the files to upload to the configset to repair the drifted files.
+// Config API changes are reverted by replacing the configoverlay.json with an empty overlay.
+func ConfigSetDriftRepairs(files map[string][]byte, driftedFiles []string) (repairs map[string][]byte) {
+	repairs = make(map[string][]byte, len(driftedFiles))
+	for _, path := range driftedFiles {
+		if content, isManaged := files[path]; isManaged {
+			repairs[path] = content
+		} else if path == ConfigOverlayFile {
+			repairs[path] = []byte("{}")
+		}
+	}
+	return repairs
+}
+
+// isConfigOverlayEmpty returns whether the configoverlay.json does not override anything, only the znodeVersion is ignored
+func isConfigOverlayEmpty(overlay string) bool {
+	if strings.TrimSpace(overlay) == "" {
+		return true
+	}
+	overrides := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(overlay), &overrides); err != nil {
+		// An unreadable overlay is certainly not what the configset was synced with
+		return false
+	}
+	for key, value := range overrides {
+		if key == "znodeVersion" {
+			continue
+		}
+		if trimmed := bytes.TrimSpace(value); !bytes.Equal(trimmed, []byte("{}")) && !bytes.Equal(trimmed, []byte("null")) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectionsUsingConfigSet returns the collections, in order, that use the given configset
+func collectionsUsingConfigSet(clusterStatus solr_api.SolrClusterStatus, configSet string) (collections []string) {
+	for name, collection := range clusterStatus.Collections {
+		if collection.ConfigName == configSet {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+func uploadConfigSetFile(cloud *solr.SolrCloud, configSet string, path string, content []byte, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "UPLOAD")
+	queryParams.Add("name", configSet)
+	queryParams.Add("filePath", path)
+	queryParams.Add("overwrite", "true")
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallConfigSetsApi(cloud, queryParams, content, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForConfigSetsApiError("UPLOAD", resp.ResponseHeader)
+	}
+	return err
+}
+
+func reloadCollection(cloud *solr.SolrCloud, collection string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "RELOAD")
+	queryParams.Add("name", collection)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("RELOAD", resp.ResponseHeader)
+	}
+	return err
+}
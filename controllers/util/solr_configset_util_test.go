@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestConfigSetFilesContent(t *testing.T) {
+	configSetFiles := &solr.ConfigSetFiles{
+		ConfigSet: "products",
+		ConfigMap: "relevance",
+		Files: []solr.ConfigSetFile{
+			{Key: "synonyms.txt", Path: "synonyms.txt"},
+			{Key: "stopwords-en", Path: "lang/stopwords_en.txt"},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "relevance"},
+		Data: map[string]string{
+			"synonyms.txt": "tv,television",
+			"stopwords-en": "a\nan\nthe",
+			"unused":       "not synced",
+		},
+	}
+
+	files, contentHash, err := ConfigSetFilesContent(configSetFiles, configMap)
+	assert.NoError(t, err, "Unexpected error reading the configset files")
+	assert.Equal(t, map[string][]byte{
+		"synonyms.txt":          []byte("tv,television"),
+		"lang/stopwords_en.txt": []byte("a\nan\nthe"),
+	}, files, "The files should be keyed by their path in the configset")
+
+	configMap.Data["unused"] = "still not synced"
+	_, unchangedHash, _ := ConfigSetFilesContent(configSetFiles, configMap)
+	assert.Equal(t, contentHash, unchangedHash, "Changes to other keys of the ConfigMap should not change the hash")
+
+	configMap.Data["synonyms.txt"] = "tv,television,telly"
+	_, changedHash, _ := ConfigSetFilesContent(configSetFiles, configMap)
+	assert.NotEqual(t, contentHash, changedHash, "Changes to a synced file should change the hash")
+
+	delete(configMap.Data, "stopwords-en")
+	_, _, err = ConfigSetFilesContent(configSetFiles, configMap)
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "A missing key in the ConfigMap should be a terminal error")
+}
+
+func TestSolrConfigSetContent(t *testing.T) {
+	configSet := &solr.SolrConfigSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec: solr.SolrConfigSetSpec{
+			SolrCloud: "example",
+			Source:    solr.SolrConfigSetSource{ConfigMap: "products-configset"},
+		},
+	}
+	data := map[string][]byte{
+		"solrconfig.xml": []byte("<config/>"),
+		"stopwords-en":   []byte("a\nan\nthe"),
+	}
+
+	files, contentHash, err := SolrConfigSetContent(configSet, data)
+	assert.NoError(t, err, "Unexpected error reading the configset files")
+	assert.Equal(t, data, files, "Every key should be a file when no files are listed")
+
+	configSet.Spec.Source.Files = []solr.ConfigSetFile{
+		{Key: "solrconfig.xml"},
+		{Key: "stopwords-en", Path: "lang/stopwords_en.txt"},
+	}
+	files, movedHash, err := SolrConfigSetContent(configSet, data)
+	assert.NoError(t, err, "Unexpected error reading the configset files")
+	assert.Equal(t, map[string][]byte{
+		"solrconfig.xml":        []byte("<config/>"),
+		"lang/stopwords_en.txt": []byte("a\nan\nthe"),
+	}, files, "The listed files should be keyed by their path in the configset")
+	assert.NotEqual(t, contentHash, movedHash, "Moving a file should change the hash")
+
+	delete(data, "stopwords-en")
+	_, _, err = SolrConfigSetContent(configSet, data)
+	_, isTerminal := AsTerminalError(err)
+	assert.True(t, isTerminal, "A missing key in the source should be a terminal error")
+
+	configSet.Spec.Source.Files = nil
+	_, _, err = SolrConfigSetContent(configSet, map[string][]byte{})
+	_, isTerminal = AsTerminalError(err)
+	assert.True(t, isTerminal, "An empty source should be a terminal error")
+}
+
+func TestValidateSolrConfigSet(t *testing.T) {
+	configSet := &solr.SolrConfigSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec: solr.SolrConfigSetSpec{
+			SolrCloud:     "example",
+			ConfigSetName: "products",
+			Source:        solr.SolrConfigSetSource{ConfigMap: "products-configset"},
+		},
+	}
+	assert.NoError(t, ValidateSolrConfigSetSource(configSet), "A ConfigMap source should be valid")
+
+	configSet.Spec.Source.Secret = "products-secret"
+	_, isTerminal := AsTerminalError(ValidateSolrConfigSetSource(configSet))
+	assert.True(t, isTerminal, "Both a ConfigMap and a Secret source should be a terminal error")
+
+	configSet.Spec.Source = solr.SolrConfigSetSource{Files: []solr.ConfigSetFile{{Key: "solrconfig.xml"}}}
+	_, isTerminal = AsTerminalError(ValidateSolrConfigSetSource(configSet))
+	assert.True(t, isTerminal, "A source without a ConfigMap or Secret should be a terminal error")
+
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: solr.SolrCloudSpec{
+			ConfigSetFiles: []solr.ConfigSetFiles{{ConfigSet: "other", ConfigMap: "synonyms"}},
+		},
+	}
+	assert.NoError(t, ValidateSolrConfigSetTarget(configSet, cloud), "A configset that is not synced through configSetFiles should be valid")
+
+	cloud.Spec.ConfigSetFiles = append(cloud.Spec.ConfigSetFiles, solr.ConfigSetFiles{ConfigSet: "products", ConfigMap: "synonyms"})
+	terminalErr, isTerminal := AsTerminalError(ValidateSolrConfigSetTarget(configSet, cloud))
+	if assert.True(t, isTerminal, "A configset that is also synced through configSetFiles should be a terminal error") {
+		assert.Equal(t, ConfigSetConflictReason, terminalErr.Reason, "Wrong reason for the conflict")
+	}
+}
+
+func TestConfigSetZip(t *testing.T) {
+	zipContent, err := configSetZip(map[string][]byte{
+		"solrconfig.xml":        []byte("<config/>"),
+		"lang/stopwords_en.txt": []byte("a\nan\nthe"),
+	})
+	assert.NoError(t, err, "Unexpected error zipping the configset")
+
+	zipReader, err := zip.NewReader(bytes.NewReader(zipContent), int64(len(zipContent)))
+	assert.NoError(t, err, "The configset should be a valid zip file")
+	contents := map[string]string{}
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		assert.NoError(t, err, "Could not open file %s in the zip", file.Name)
+		content, err := ioutil.ReadAll(reader)
+		assert.NoError(t, err, "Could not read file %s in the zip", file.Name)
+		contents[file.Name] = string(content)
+	}
+	assert.Equal(t, map[string]string{
+		"solrconfig.xml":        "<config/>",
+		"lang/stopwords_en.txt": "a\nan\nthe",
+	}, contents, "Wrong files in the configset zip")
+}
+
+func TestCollectionsUsingConfigSet(t *testing.T) {
+	clusterStatus := solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"products-v2": {ConfigName: "products"},
+			"products-v1": {ConfigName: "products"},
+			"reviews":     {ConfigName: "_default"},
+		},
+	}
+	assert.Equal(t, []string{"products-v1", "products-v2"}, collectionsUsingConfigSet(clusterStatus, "products"), "Wrong collections using the configset")
+	assert.Empty(t, collectionsUsingConfigSet(clusterStatus, "unused"), "No collections should use an unused configset")
+}
+
+func TestConfigSetDriftRepairs(t *testing.T) {
+	files := map[string][]byte{
+		"synonyms.txt":          []byte("tv,television"),
+		"lang/stopwords_en.txt": []byte("a\nan\nthe"),
+	}
+	assert.Equal(t, map[string][]byte{
+		"synonyms.txt":    []byte("tv,television"),
+		ConfigOverlayFile: []byte("{}"),
+	}, ConfigSetDriftRepairs(files, []string{"synonyms.txt", ConfigOverlayFile}), "Drifted files should be restored and an unmanaged overlay emptied")
+
+	files[ConfigOverlayFile] = []byte("{\"props\":{}}")
+	assert.Equal(t, map[string][]byte{
+		ConfigOverlayFile: []byte("{\"props\":{}}"),
+	}, ConfigSetDriftRepairs(files, []string{ConfigOverlayFile}), "A managed overlay should be restored from the ConfigMap")
+}
+
+func TestIsConfigOverlayEmpty(t *testing.T) {
+	assert.True(t, isConfigOverlayEmpty(""), "A missing overlay should be empty")
+	assert.True(t, isConfigOverlayEmpty("{}"), "An empty overlay should be empty")
+	assert.True(t, isConfigOverlayEmpty("{\"znodeVersion\":3,\"props\":{},\"requestHandler\":null}"), "An overlay without any overrides should be empty")
+	assert.False(t, isConfigOverlayEmpty("{\"znodeVersion\":3,\"props\":{\"query\":{\"maxBooleanClauses\":2048}}}"), "An overlay with overridden properties should not be empty")
+	assert.False(t, isConfigOverlayEmpty("{\"requestHandler\":{\"/mysearch\":{\"class\":\"solr.SearchHandler\"}}}"), "An overlay with request handlers should not be empty")
+}
@@ -0,0 +1,156 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// SolrCollectDiagnosticsAnnotation requests diagnostics to be collected from the Solr pods of a SolrCloud.
+	// Diagnostics are collected once for every new value of the annotation, such as a timestamp or a support ticket id.
+	SolrCollectDiagnosticsAnnotation = "solr.apache.org/collectDiagnostics"
+
+	// SolrDiagnosticsPodsAnnotation limits the Solr pods that diagnostics are collected from, to a comma-separated list of pod names.
+	// Diagnostics are collected from all Solr pods by default.
+	SolrDiagnosticsPodsAnnotation = "solr.apache.org/diagnosticsPods"
+
+	// SolrDiagnosticsRequestAnnotation is set on the diagnostics ConfigMap, to the request that the diagnostics were collected for
+	SolrDiagnosticsRequestAnnotation = "solr.apache.org/diagnosticsRequest"
+
+	// DiagnosticsLogTailLines is the number of the most recent log lines that are collected from each Solr pod
+	DiagnosticsLogTailLines = int64(1000)
+
+	// MaxDiagnosticsSize is the maximum size of all collected files combined.
+	// ConfigMaps are limited to 1MiB, which leaves room for the metadata of the ConfigMap.
+	MaxDiagnosticsSize = 1000 * 1000
+
+	DiagnosticsThreadDumpSuffix = ".threads.json"
+	DiagnosticsMetricsSuffix    = ".metrics.json"
+	DiagnosticsLogsSuffix       = ".log"
+
+	diagnosticsTruncatedMarker = "\n... truncated by the Solr Operator, collect diagnostics from fewer pods to keep more of each file ...\n"
+)
+
+// PendingDiagnosticsRequest returns the diagnostics request of the SolrCloud, and whether diagnostics have not yet been collected for it
+func PendingDiagnosticsRequest(solrCloud *solr.SolrCloud) (requestId string, pending bool) {
+	requestId = solrCloud.GetAnnotations()[SolrCollectDiagnosticsAnnotation]
+	pending = requestId != "" && (solrCloud.Status.Diagnostics == nil || solrCloud.Status.Diagnostics.RequestId != requestId)
+	return requestId, pending
+}
+
+// DiagnosticsNodes returns the Solr nodes to collect diagnostics from, as selected by the "solr.apache.org/diagnosticsPods" annotation,
+// as well as the selected pods that are not Solr nodes of the SolrCloud.
+func DiagnosticsNodes(solrCloud *solr.SolrCloud, solrNodes []solr.SolrNodeStatus) (selectedNodes []solr.SolrNodeStatus, unknownPods []string) {
+	selectedPods := map[string]bool{}
+	for _, pod := range strings.Split(solrCloud.GetAnnotations()[SolrDiagnosticsPodsAnnotation], ",") {
+		if pod = strings.TrimSpace(pod); pod != "" {
+			selectedPods[pod] = true
+		}
+	}
+
+	for _, node := range solrNodes {
+		if len(selectedPods) == 0 || selectedPods[node.Name] {
+			selectedNodes = append(selectedNodes, node)
+			delete(selectedPods, node.Name)
+		}
+	}
+	for pod := range selectedPods {
+		unknownPods = append(unknownPods, pod)
+	}
+	sort.Strings(unknownPods)
+	return selectedNodes, unknownPods
+}
+
+// CollectSolrNodeDiagnostics fetches a thread dump and a snapshot of the JVM and node metrics from a Solr node
+func CollectSolrNodeDiagnostics(solrCloud *solr.SolrCloud, node string, httpHeaders map[string]string) (threadDump []byte, metrics []byte, err error) {
+	nodeUrl := solrNodeUrl(solrCloud, node)
+	if threadDump, err = solr_api.CallNodeAdminApi(solrCloud, nodeUrl, "/solr/admin/info/threads", url.Values{}, httpHeaders); err != nil {
+		return nil, nil, fmt.Errorf("could not fetch the thread dump of %s: %w", node, err)
+	}
+
+	metricsParams := url.Values{}
+	metricsParams.Set("group", "jvm,node")
+	if metrics, err = solr_api.CallNodeAdminApi(solrCloud, nodeUrl, "/solr/admin/metrics", metricsParams, httpHeaders); err != nil {
+		return threadDump, nil, fmt.Errorf("could not fetch the metrics of %s: %w", node, err)
+	}
+	return threadDump, metrics, nil
+}
+
+// GetSolrPodLogs returns the most recent log lines of the Solr container in the given pod
+func GetSolrPodLogs(podName string, namespace string, tailLines int64, config rest.Config) (logs []byte, err error) {
+	client := &kubernetes.Clientset{}
+	if client, err = kubernetes.NewForConfig(&config); err != nil {
+		return nil, err
+	}
+	return client.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: SolrNodeContainer,
+		TailLines: &tailLines,
+	}).DoRaw(context.Background())
+}
+
+// GenerateDiagnosticsConfigMap returns a new corev1.ConfigMap containing the diagnostics files collected for the given request.
+// Files are truncated so that all of them fit into the ConfigMap. Logs keep their most recent lines, other files keep their beginning.
+func GenerateDiagnosticsConfigMap(solrCloud *solr.SolrCloud, requestId string, files map[string][]byte) *corev1.ConfigMap {
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
+	annotations := map[string]string{
+		SolrDiagnosticsRequestAnnotation: requestId,
+	}
+
+	data := make(map[string]string, len(files))
+	if len(files) > 0 {
+		maxFileSize := MaxDiagnosticsSize / len(files)
+		for name, content := range files {
+			data[name] = truncateDiagnosticsFile(string(content), maxFileSize, strings.HasSuffix(name, DiagnosticsLogsSuffix))
+		}
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        solrCloud.DiagnosticsConfigMapName(),
+			Namespace:   solrCloud.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: data,
+	}
+}
+
+func truncateDiagnosticsFile(content string, maxSize int, keepEnd bool) string {
+	if len(content) <= maxSize {
+		return content
+	}
+	keep := maxSize - len(diagnosticsTruncatedMarker)
+	if keep <= 0 {
+		return diagnosticsTruncatedMarker
+	}
+	if keepEnd {
+		return diagnosticsTruncatedMarker + content[len(content)-keep:]
+	}
+	return content[:keep] + diagnosticsTruncatedMarker
+}
@@ -0,0 +1,99 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+)
+
+func TestPendingDiagnosticsRequest(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+
+	_, pending := PendingDiagnosticsRequest(solrCloud)
+	assert.False(t, pending, "Diagnostics should not be collected without the collectDiagnostics annotation")
+
+	solrCloud.Annotations = map[string]string{SolrCollectDiagnosticsAnnotation: "ticket-1234"}
+	requestId, pending := PendingDiagnosticsRequest(solrCloud)
+	assert.True(t, pending, "Diagnostics should be collected for a new request")
+	assert.Equal(t, "ticket-1234", requestId, "Wrong diagnostics request id")
+
+	solrCloud.Status.Diagnostics = &solr.SolrDiagnosticsStatus{RequestId: "ticket-1234"}
+	_, pending = PendingDiagnosticsRequest(solrCloud)
+	assert.False(t, pending, "Diagnostics should only be collected once for each request")
+
+	solrCloud.Annotations[SolrCollectDiagnosticsAnnotation] = "ticket-5678"
+	requestId, pending = PendingDiagnosticsRequest(solrCloud)
+	assert.True(t, pending, "Diagnostics should be collected again when the request changes")
+	assert.Equal(t, "ticket-5678", requestId, "Wrong diagnostics request id")
+}
+
+func TestDiagnosticsNodes(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	solrNodes := []solr.SolrNodeStatus{
+		{Name: "foo-solrcloud-0", Ready: true},
+		{Name: "foo-solrcloud-1", Ready: false},
+		{Name: "foo-solrcloud-2", Ready: true},
+	}
+
+	selectedNodes, unknownPods := DiagnosticsNodes(solrCloud, solrNodes)
+	assert.Equal(t, solrNodes, selectedNodes, "All Solr nodes should be selected when no pods are given")
+	assert.Empty(t, unknownPods, "There should be no unknown pods when no pods are given")
+
+	solrCloud.Annotations = map[string]string{SolrDiagnosticsPodsAnnotation: "foo-solrcloud-2, other-pod,foo-solrcloud-1"}
+	selectedNodes, unknownPods = DiagnosticsNodes(solrCloud, solrNodes)
+	assert.Equal(t, []solr.SolrNodeStatus{solrNodes[1], solrNodes[2]}, selectedNodes, "Only the given pods should be selected")
+	assert.Equal(t, []string{"other-pod"}, unknownPods, "Pods that are not Solr nodes of the SolrCloud should be reported")
+}
+
+func TestGenerateDiagnosticsConfigMap(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+
+	configMap := GenerateDiagnosticsConfigMap(solrCloud, "ticket-1234", map[string][]byte{
+		"foo-solrcloud-0" + DiagnosticsThreadDumpSuffix: []byte("{\"system\":{}}"),
+		"foo-solrcloud-0" + DiagnosticsLogsSuffix:       []byte("first line\nlast line\n"),
+	})
+	assert.Equal(t, "foo-solrcloud-diagnostics", configMap.Name, "Wrong diagnostics ConfigMap name")
+	assert.Equal(t, "ticket-1234", configMap.Annotations[SolrDiagnosticsRequestAnnotation], "The ConfigMap should reference the diagnostics request")
+	assert.Equal(t, map[string]string{
+		"foo-solrcloud-0.threads.json": "{\"system\":{}}",
+		"foo-solrcloud-0.log":          "first line\nlast line\n",
+	}, configMap.Data, "Files that fit into the ConfigMap should not be truncated")
+
+	largeFile := strings.Repeat("a", MaxDiagnosticsSize) + "end"
+	configMap = GenerateDiagnosticsConfigMap(solrCloud, "ticket-1234", map[string][]byte{
+		"foo-solrcloud-0" + DiagnosticsMetricsSuffix: []byte("start" + largeFile),
+		"foo-solrcloud-0" + DiagnosticsLogsSuffix:    []byte("start" + largeFile),
+	})
+	size := 0
+	for _, content := range configMap.Data {
+		size += len(content)
+	}
+	assert.LessOrEqual(t, size, MaxDiagnosticsSize, "The files should be truncated to fit into the ConfigMap")
+	assert.True(t, strings.HasPrefix(configMap.Data["foo-solrcloud-0.metrics.json"], "start"), "Truncated files should keep their beginning")
+	assert.True(t, strings.HasSuffix(configMap.Data["foo-solrcloud-0.log"], "end"), "Truncated logs should keep their most recent lines")
+}
@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxSolrCloudHistory is the number of operations that are kept in the history of a SolrCloud
+const MaxSolrCloudHistory = 20
+
+// AddSolrCloudOperation adds the operation to the history in the SolrCloud status, keeping only the latest MaxSolrCloudHistory operations
+func AddSolrCloudOperation(status *solr.SolrCloudStatus, operation solr.SolrCloudOperation) {
+	status.History = append(status.History, operation)
+	if len(status.History) > MaxSolrCloudHistory {
+		status.History = status.History[len(status.History)-MaxSolrCloudHistory:]
+	}
+}
+
+// RecordSolrCloudOperation adds the operation to the history of the SolrCloud with the given name, retrying if the SolrCloud is updated concurrently.
+// Operations on SolrClouds that no longer exist are not recorded.
+func RecordSolrCloudOperation(ctx context.Context, c client.Client, namespace string, solrCloudName string, operation solr.SolrCloudOperation) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		solrCloud := &solr.SolrCloud{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: solrCloudName}, solrCloud); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		AddSolrCloudOperation(&solrCloud.Status, operation)
+		return c.Status().Update(ctx, solrCloud)
+	})
+}
+
+// RestartOperation returns the history entry for a restart of the Solr pods, for the given reasons
+func RestartOperation(reasons []string) solr.SolrCloudOperation {
+	return solr.SolrCloudOperation{
+		Type:    solr.SolrCloudRestartOperation,
+		Time:    metav1.Now(),
+		Outcome: solr.SolrCloudOperationStarted,
+		Reasons: reasons,
+	}
+}
+
+// ScaleOperation returns the history entry for a change in the number of Solr pods
+func ScaleOperation(fromReplicas int32, toReplicas int32) solr.SolrCloudOperation {
+	return solr.SolrCloudOperation{
+		Type:    solr.SolrCloudScaleOperation,
+		Time:    metav1.Now(),
+		Outcome: solr.SolrCloudOperationStarted,
+		Message: fmt.Sprintf("Scaling from %d to %d Solr pods", fromReplicas, toReplicas),
+	}
+}
+
+// BackupOperation returns the history entry for a SolrBackup that has finished
+func BackupOperation(backup *solr.SolrBackup) solr.SolrCloudOperation {
+	operation := solr.SolrCloudOperation{
+		Type:    solr.SolrCloudBackupOperation,
+		Time:    metav1.Now(),
+		Outcome: finishedOperationOutcome(backup.Status.Successful),
+		Message: "SolrBackup " + backup.Name,
+	}
+	if backup.Status.FinishTime != nil {
+		operation.Time = *backup.Status.FinishTime
+	}
+	return operation
+}
+
+// RestoreOperation returns the history entry for a SolrRestore that has finished
+func RestoreOperation(restore *solr.SolrRestore) solr.SolrCloudOperation {
+	operation := solr.SolrCloudOperation{
+		Type:    solr.SolrCloudRestoreOperation,
+		Time:    metav1.Now(),
+		Outcome: finishedOperationOutcome(restore.Status.Successful),
+		Message: "SolrRestore " + restore.Name,
+	}
+	if restore.Spec.SolrBackup != "" {
+		operation.Message += ", from SolrBackup " + restore.Spec.SolrBackup
+	} else if restore.Spec.BackupName != "" {
+		operation.Message += ", from backup " + restore.Spec.BackupName
+	}
+	if restore.Status.FinishTime != nil {
+		operation.Time = *restore.Status.FinishTime
+	}
+	return operation
+}
+
+func finishedOperationOutcome(successful *bool) string {
+	if successful != nil && *successful {
+		return solr.SolrCloudOperationSucceeded
+	}
+	return solr.SolrCloudOperationFailed
+}
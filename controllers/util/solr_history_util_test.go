@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"strconv"
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddSolrCloudOperation(t *testing.T) {
+	status := &solr.SolrCloudStatus{}
+	for i := 0; i < MaxSolrCloudHistory+2; i++ {
+		AddSolrCloudOperation(status, ScaleOperation(int32(i), int32(i+1)))
+	}
+	assert.Len(t, status.History, MaxSolrCloudHistory, "Only the latest operations should be kept")
+	assert.Equal(t, "Scaling from 2 to 3 Solr pods", status.History[0].Message, "The oldest operations should be dropped")
+	assert.Equal(t, "Scaling from "+strconv.Itoa(MaxSolrCloudHistory+1)+" to "+strconv.Itoa(MaxSolrCloudHistory+2)+" Solr pods", status.History[MaxSolrCloudHistory-1].Message, "The latest operation should be last")
+}
+
+func TestFinishedOperations(t *testing.T) {
+	tru := true
+	finishTime := metav1.Now()
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+		Status:     solr.SolrBackupStatus{Finished: true, Successful: &tru, FinishTime: &finishTime},
+	}
+	assert.Equal(t, solr.SolrCloudOperation{
+		Type:    solr.SolrCloudBackupOperation,
+		Time:    finishTime,
+		Outcome: solr.SolrCloudOperationSucceeded,
+		Message: "SolrBackup nightly",
+	}, BackupOperation(backup), "A backup should be recorded at its finish time")
+
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore"},
+		Spec:       solr.SolrRestoreSpec{SolrBackup: "nightly"},
+		Status:     solr.SolrRestoreStatus{Finished: true, FinishTime: &finishTime},
+	}
+	operation := RestoreOperation(restore)
+	assert.Equal(t, solr.SolrCloudOperationFailed, operation.Outcome, "A restore that was not successful has failed")
+	assert.Equal(t, "SolrRestore restore, from SolrBackup nightly", operation.Message)
+
+	restart := RestartOperation([]string{RestartReasonImage})
+	assert.Equal(t, solr.SolrCloudRestartOperation, restart.Type)
+	assert.Equal(t, solr.SolrCloudOperationStarted, restart.Outcome, "Restarts are carried out by the update strategy")
+	assert.Equal(t, []string{RestartReasonImage}, restart.Reasons)
+}
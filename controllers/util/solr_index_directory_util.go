@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	SolrMaxMapCountCheckContainer = "check-max-map-count"
+)
+
+// The classes of the DirectoryFactories that can be chosen for a SolrCloud
+var directoryFactoryClasses = map[solr.SolrDirectoryFactory]string{
+	solr.MMapDirectoryFactory:       "solr.MMapDirectoryFactory",
+	solr.NIOFSDirectoryFactory:      "solr.NIOFSDirectoryFactory",
+	solr.NRTCachingDirectoryFactory: "solr.NRTCachingDirectoryFactory",
+}
+
+// ValidateIndexDirectoryOptions returns an error if the index directory options of the SolrCloud cannot be used together
+func ValidateIndexDirectoryOptions(cloud *solr.SolrCloud) error {
+	opts := cloud.Spec.IndexDirectory
+	if opts == nil {
+		return nil
+	}
+	if opts.Preload && opts.DirectoryFactory != solr.MMapDirectoryFactory {
+		return TerminalErrorf(InvalidSpecReason, "'indexDirectory.preload' requires the %s 'indexDirectory.directoryFactory'", solr.MMapDirectoryFactory)
+	}
+	return nil
+}
+
+// indexDirectorySolrOpts returns the system properties that configure how Solr accesses its index files
+func indexDirectorySolrOpts(cloud *solr.SolrCloud) (opts []string) {
+	indexDirectory := cloud.Spec.IndexDirectory
+	if indexDirectory == nil {
+		return nil
+	}
+	if class, hasClass := directoryFactoryClasses[indexDirectory.DirectoryFactory]; hasClass {
+		opts = append(opts, "-Dsolr.directoryFactory="+class)
+	}
+	if indexDirectory.Preload {
+		opts = append(opts, "-Dsolr.mmap.preload=true")
+	}
+	return opts
+}
+
+// generateMaxMapCountCheckInitContainer returns the init container that fails if the vm.max_map_count of the node is lower than the SolrCloud requires.
+// The vm.max_map_count is not namespaced, so the value of the node can be read from within the container.
+func generateMaxMapCountCheckInitContainer(cloud *solr.SolrCloud) (bool, corev1.Container) {
+	if cloud.Spec.IndexDirectory == nil || cloud.Spec.IndexDirectory.MinMaxMapCount == nil {
+		return false, corev1.Container{}
+	}
+	minMaxMapCount := *cloud.Spec.IndexDirectory.MinMaxMapCount
+	cmd := fmt.Sprintf("max_map_count=$(cat /proc/sys/vm/max_map_count); "+
+		"if [ \"${max_map_count}\" -lt %d ]; then "+
+		"echo \"vm.max_map_count of node ${NODE_NAME} is ${max_map_count}, but at least %d is required\" | tee /dev/termination-log; exit 1; fi", minMaxMapCount, minMaxMapCount)
+
+	return true, corev1.Container{
+		Name:                     SolrMaxMapCountCheckContainer,
+		Image:                    cloud.Spec.BusyBoxImage.ToImageName(),
+		ImagePullPolicy:          cloud.Spec.BusyBoxImage.PullPolicy,
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
+		Command:                  []string{"sh", "-c", cmd},
+		Env: []corev1.EnvVar{
+			{
+				Name: "NODE_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath:  "spec.nodeName",
+						APIVersion: "v1",
+					},
+				},
+			},
+		},
+	}
+}
+
+// MaxMapCountCheckFailure returns the reason that the vm.max_map_count check of the given pod failed, if it has.
+func MaxMapCountCheckFailure(pod *corev1.Pod) (failed bool, reason string) {
+	return initContainerFailure(pod, SolrMaxMapCountCheckContainer)
+}
+
+// MaxMapCountCheckFailureKey identifies the current run of the vm.max_map_count check of the given pod, so that each failure is only reported once.
+// The check is run again whenever the init container is restarted, or the pod is recreated.
+func MaxMapCountCheckFailureKey(pod *corev1.Pod) string {
+	var restartCount int32
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		if containerStatus.Name == SolrMaxMapCountCheckContainer {
+			restartCount = containerStatus.RestartCount
+		}
+	}
+	return fmt.Sprintf("%s/%d", pod.UID, restartCount)
+}
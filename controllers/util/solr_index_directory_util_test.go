@@ -0,0 +1,119 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func indexDirectoryTestSolrCloud() *solr.SolrCloud {
+	minMaxMapCount := int64(262144)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			SolrOpts: "-Dsolr.directoryFactory=solr.StandardDirectoryFactory",
+			IndexDirectory: &solr.SolrIndexDirectoryOptions{
+				DirectoryFactory: solr.MMapDirectoryFactory,
+				Preload:          true,
+				MinMaxMapCount:   &minMaxMapCount,
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	return solrCloud
+}
+
+func TestValidateIndexDirectoryOptions(t *testing.T) {
+	solrCloud := indexDirectoryTestSolrCloud()
+	assert.NoError(t, ValidateIndexDirectoryOptions(solrCloud))
+
+	solrCloud.Spec.IndexDirectory.DirectoryFactory = solr.NRTCachingDirectoryFactory
+	assert.Error(t, ValidateIndexDirectoryOptions(solrCloud), "Only the MMap directoryFactory can preload the index files")
+
+	solrCloud.Spec.IndexDirectory.Preload = false
+	assert.NoError(t, ValidateIndexDirectoryOptions(solrCloud))
+
+	solrCloud.Spec.IndexDirectory = nil
+	assert.NoError(t, ValidateIndexDirectoryOptions(solrCloud))
+}
+
+func TestIndexDirectoryOnStatefulSet(t *testing.T) {
+	solrCloud := indexDirectoryTestSolrCloud()
+	solrCloudStatus := &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	podSpec := statefulSet.Spec.Template.Spec
+
+	var solrOpts string
+	for _, envVar := range podSpec.Containers[0].Env {
+		if envVar.Name == "SOLR_OPTS" {
+			solrOpts = envVar.Value
+		}
+	}
+	assert.Contains(t, solrOpts, "-Dsolr.directoryFactory=solr.MMapDirectoryFactory -Dsolr.mmap.preload=true -Dsolr.directoryFactory=solr.StandardDirectoryFactory",
+		"The index directory options should be passed to Solr before the user-provided SOLR_OPTS, so that they can be overridden")
+
+	assert.Equal(t, SolrMaxMapCountCheckContainer, podSpec.InitContainers[0].Name, "The vm.max_map_count of the node should be checked first")
+	assert.Contains(t, podSpec.InitContainers[0].Command[2], "-lt 262144")
+
+	solrCloud.Spec.IndexDirectory = &solr.SolrIndexDirectoryOptions{}
+	assert.Empty(t, indexDirectorySolrOpts(solrCloud), "No system properties should be set when the configset defaults are used")
+	hasCheck, _ := generateMaxMapCountCheckInitContainer(solrCloud)
+	assert.False(t, hasCheck, "The vm.max_map_count should not be checked when no minimum is given")
+}
+
+func TestMaxMapCountCheckFailure(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: SolrMaxMapCountCheckContainer,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "vm.max_map_count of node node-1 is 65530, but at least 262144 is required\n"},
+					},
+				},
+			},
+		},
+	}
+	failed, reason := MaxMapCountCheckFailure(pod)
+	assert.True(t, failed)
+	assert.Equal(t, "vm.max_map_count of node node-1 is 65530, but at least 262144 is required", reason)
+
+	failed, _ = ZkSetupFailure(pod)
+	assert.False(t, failed, "The failure of the vm.max_map_count check should not be reported as a Zookeeper failure")
+
+	pod.UID = "pod-uid"
+	failureKey := MaxMapCountCheckFailureKey(pod)
+	assert.Equal(t, failureKey, MaxMapCountCheckFailureKey(pod), "The same failure should have the same key")
+	pod.Status.InitContainerStatuses[0].RestartCount = 1
+	assert.NotEqual(t, failureKey, MaxMapCountCheckFailureKey(pod), "Each restart of the check should have a different key")
+	pod.Status.InitContainerStatuses[0].RestartCount = 0
+	pod.UID = "recreated-pod-uid"
+	assert.NotEqual(t, failureKey, MaxMapCountCheckFailureKey(pod), "A recreated pod should have a different key")
+}
@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	InventoryFile = "inventory.json"
+)
+
+// SolrCloudInventory is the machine-readable inventory of the collections, shards and replicas in a SolrCloud
+type SolrCloudInventory struct {
+	Collections map[string]SolrCollectionInventory `json:"collections"`
+}
+
+type SolrCollectionInventory struct {
+	ConfigName string                        `json:"configName,omitempty"`
+	Shards     map[string]SolrShardInventory `json:"shards"`
+}
+
+type SolrShardInventory struct {
+	State    solr_api.SolrShardState         `json:"state,omitempty"`
+	Range    string                          `json:"range,omitempty"`
+	Replicas map[string]SolrReplicaInventory `json:"replicas"`
+}
+
+type SolrReplicaInventory struct {
+	Core     string                    `json:"core"`
+	Type     solr_api.SolrReplicaType  `json:"type,omitempty"`
+	State    solr_api.SolrReplicaState `json:"state"`
+	Leader   bool                      `json:"leader"`
+	NodeName string                    `json:"nodeName"`
+
+	// The pod and data PVC hosting the replica, omitted if the replica's node is not a pod of the SolrCloud
+	Pod string `json:"pod,omitempty"`
+	PVC string `json:"pvc,omitempty"`
+}
+
+// FetchSolrCloudInventory builds the inventory of the SolrCloud, using its current cluster state and the given Solr pods
+func FetchSolrCloudInventory(cloud *solr.SolrCloud, pods []corev1.Pod, clusterState *SolrClusterState) (*SolrCloudInventory, error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return nil, err
+	}
+	return buildSolrCloudInventory(cloud, clusterStatus, pods), nil
+}
+
+func buildSolrCloudInventory(cloud *solr.SolrCloud, clusterStatus solr_api.SolrClusterStatus, pods []corev1.Pod) *SolrCloudInventory {
+	podsByNodeName := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		podsByNodeName[SolrNodeName(cloud, pod)] = pod.Name
+	}
+
+	inventory := &SolrCloudInventory{Collections: make(map[string]SolrCollectionInventory, len(clusterStatus.Collections))}
+	for collectionName, collection := range clusterStatus.Collections {
+		collectionInventory := SolrCollectionInventory{
+			ConfigName: collection.ConfigName,
+			Shards:     make(map[string]SolrShardInventory, len(collection.Shards)),
+		}
+		for shardName, shard := range collection.Shards {
+			shardInventory := SolrShardInventory{
+				State:    shard.State,
+				Range:    shard.Range,
+				Replicas: make(map[string]SolrReplicaInventory, len(shard.Replicas)),
+			}
+			for replicaName, replica := range shard.Replicas {
+				replicaInventory := SolrReplicaInventory{
+					Core:     replica.Core,
+					Type:     replica.Type,
+					State:    replica.State,
+					Leader:   replica.Leader,
+					NodeName: replica.NodeName,
+				}
+				if podName, isSolrPod := podsByNodeName[replica.NodeName]; isSolrPod {
+					replicaInventory.Pod = podName
+					replicaInventory.PVC = dataPVCName(cloud, podName)
+				}
+				shardInventory.Replicas[replicaName] = replicaInventory
+			}
+			collectionInventory.Shards[shardName] = shardInventory
+		}
+		inventory.Collections[collectionName] = collectionInventory
+	}
+	return inventory
+}
+
+// dataPVCName returns the name of the PVC holding the data of the given Solr pod, or an empty string if ephemeral storage is used
+func dataPVCName(cloud *solr.SolrCloud, podName string) string {
+	if !cloud.UsesPersistentStorage() {
+		return ""
+	}
+	claimName := cloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name
+	if claimName == "" {
+		// the default name of the data volumeClaimTemplate in the StatefulSet
+		claimName = solrDataVolumeName
+	}
+	return claimName + "-" + podName
+}
+
+// GenerateInventoryConfigMap returns a new corev1.ConfigMap containing the inventory of the SolrCloud
+func GenerateInventoryConfigMap(solrCloud *solr.SolrCloud, inventory *SolrCloudInventory) (*corev1.ConfigMap, error) {
+	// Maps are marshalled with sorted keys, so the ConfigMap only changes when the inventory does
+	inventoryJson, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
+	var annotations map[string]string
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        solrCloud.InventoryConfigMapName(),
+			Namespace:   solrCloud.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: map[string]string{
+			InventoryFile: string(inventoryJson),
+		},
+	}, nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSolrCloudInventory(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			StorageOptions: solr.SolrDataStorageOptions{
+				PersistentStorage: &solr.SolrPersistentDataStorageOptions{},
+			},
+			Inventory: &solr.SolrInventoryOptions{},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, solr.DefaultInventoryRefreshIntervalSeconds, solrCloud.Spec.Inventory.RefreshIntervalSeconds, "The inventory refresh interval was not defaulted")
+
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-0"}}
+	nodeName := SolrNodeName(solrCloud, pod)
+	clusterStatus := solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col": {
+				ConfigName: "_default",
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						State: solr_api.ShardActive,
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node1": {Core: "col_shard1_replica_n1", NodeName: nodeName, State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+							"core_node2": {Core: "col_shard1_replica_n2", NodeName: "other:8983_solr", State: solr_api.ReplicaDown, Type: solr_api.PULL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	inventory := buildSolrCloudInventory(solrCloud, clusterStatus, []corev1.Pod{pod})
+	replicas := inventory.Collections["col"].Shards["shard1"].Replicas
+	assert.Equal(t, "foo-solrcloud-0", replicas["core_node1"].Pod, "The replica should be mapped to the pod hosting its node")
+	assert.Equal(t, "data-foo-solrcloud-0", replicas["core_node1"].PVC, "The replica should be mapped to the data PVC of its pod")
+	assert.True(t, replicas["core_node1"].Leader, "The leader replica should be marked as leader")
+	assert.Empty(t, replicas["core_node2"].Pod, "A replica on an unknown node should not be mapped to a pod")
+	assert.Empty(t, replicas["core_node2"].PVC, "A replica on an unknown node should not be mapped to a PVC")
+
+	configMap, err := GenerateInventoryConfigMap(solrCloud, inventory)
+	assert.NoError(t, err, "Could not generate the inventory ConfigMap")
+	assert.Equal(t, solrCloud.InventoryConfigMapName(), configMap.Name, "Wrong name for the inventory ConfigMap")
+	parsedInventory := &SolrCloudInventory{}
+	assert.NoError(t, json.Unmarshal([]byte(configMap.Data[InventoryFile]), parsedInventory), "The inventory is not valid JSON")
+	assert.Equal(t, inventory, parsedInventory, "The inventory in the ConfigMap does not match the generated inventory")
+}
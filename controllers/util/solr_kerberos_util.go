@@ -0,0 +1,217 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	SolrKerberosKeytabVolumeName = "solr-kerberos-keytab"
+	SolrKerberosKeytabMountPath  = "/etc/solr/kerberos/keytab"
+	SolrKerberosKeytabFile       = "solr.keytab"
+	SolrKerberosKrb5VolumeName   = "solr-kerberos-krb5"
+	SolrKerberosKrb5MountPath    = "/etc/solr/kerberos/krb5"
+	SolrKerberosKrb5File         = "krb5.conf"
+
+	// SolrKerberosJaasFile is written to the data directory with only the Kerberos section of the generated JAAS config, for the probes.
+	// The probes cannot load the full JAAS config, since they do not have the system properties that the Zookeeper section references.
+	SolrKerberosJaasFile = "kerberos-jaas.conf"
+
+	// SolrKerberosJaasAppName is the section of the JAAS config that Solr, and the probes, log in with for SPNEGO requests.
+	// This is separate from the "Client" section, which is used to authenticate to Zookeeper.
+	SolrKerberosJaasAppName = "SolrClient"
+
+	DefaultKerberosNameRules = "DEFAULT"
+
+	// kerberosHostPlaceholder is replaced with the host name of each Solr pod in the Kerberos principals
+	kerberosHostPlaceholder = "_HOST"
+)
+
+// ValidateKerberosOptions checks that the options needed for Kerberos authentication are provided
+func ValidateKerberosOptions(solrCloud *solr.SolrCloud) error {
+	kerberos := solrCloud.Spec.SolrSecurity.Kerberos
+	if kerberos == nil {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.kerberos' must be provided when the authenticationType is %s", solr.Kerberos)
+	}
+	if kerberos.KeytabSecret.Name == "" || kerberos.KeytabSecret.Key == "" {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.kerberos.keytabSecret' must provide both the name and key of the keytab secret")
+	}
+	if kerberos.ServerPrincipal == "" || kerberos.ClientPrincipal == "" {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.kerberos' must provide both a serverPrincipal and a clientPrincipal")
+	}
+	if solrCloud.Spec.SolrSecurity.BasicAuthSecret != "" || solrCloud.Spec.SolrSecurity.ProbesRequireAuth {
+		return TerminalErrorf(InvalidSecurityConfigReason, "'solrSecurity.basicAuthSecret' and 'solrSecurity.probesRequireAuth' cannot be used with %s authentication, probes always authenticate through Kerberos", solr.Kerberos)
+	}
+	if fields := operatorRequestFields(solrCloud); len(fields) > 0 {
+		return TerminalErrorf(InvalidSecurityConfigReason, "%s cannot be used with %s authentication, because the operator cannot authenticate its requests to Solr through Kerberos", strings.Join(fields, ", "), solr.Kerberos)
+	}
+	return nil
+}
+
+// operatorRequestFields lists the SolrCloud options that are set and that need the operator to send requests to Solr
+func operatorRequestFields(solrCloud *solr.SolrCloud) (fields []string) {
+	spec := solrCloud.Spec
+	if spec.UpdateStrategy.Method == solr.ManagedUpdate {
+		fields = append(fields, "'updateStrategy.method="+string(solr.ManagedUpdate)+"'")
+	}
+	if spec.Scaling.PodDeletionCost {
+		fields = append(fields, "'scaling.podDeletionCost'")
+	}
+	if spec.ReadOnly {
+		fields = append(fields, "'readOnly'")
+	}
+	if spec.StandbyOf != nil {
+		fields = append(fields, "'standbyOf'")
+	}
+	if len(spec.ConfigSetFiles) > 0 {
+		fields = append(fields, "'configSetFiles'")
+	}
+	if spec.Inventory != nil {
+		fields = append(fields, "'inventory'")
+	}
+	if spec.CollectionMetrics != nil {
+		fields = append(fields, "'collectionMetrics'")
+	}
+	if spec.NodeMetrics != nil {
+		fields = append(fields, "'nodeMetrics'")
+	}
+	if spec.NodeInterruption != nil {
+		fields = append(fields, "'nodeInterruption'")
+	}
+	return fields
+}
+
+// ValidateOperatorCanCallSolr returns a terminal error if the operator cannot authenticate its requests to the SolrCloud,
+// which is the case for Kerberos authentication.
+// Controllers of resources that act through the Solr APIs, such as SolrBackups and SolrRestores, use this before calling Solr.
+func ValidateOperatorCanCallSolr(solrCloud *solr.SolrCloud) error {
+	if solrCloud.UsesKerberos() {
+		return TerminalErrorf(InvalidSecurityConfigReason, "SolrCloud %s uses %s authentication, the operator cannot authenticate its requests to Solr through Kerberos", solrCloud.Name, solr.Kerberos)
+	}
+	return nil
+}
+
+// kerberosVolumes returns the volumes, and their mounts, that contain the keytab and krb5.conf that Solr uses for Kerberos authentication
+func kerberosVolumes(solrCloud *solr.SolrCloud) (volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) {
+	if !solrCloud.UsesKerberos() || solrCloud.Spec.SolrSecurity.Kerberos == nil {
+		return nil, nil
+	}
+	kerberos := solrCloud.Spec.SolrSecurity.Kerberos
+	volumes = append(volumes, corev1.Volume{
+		Name: SolrKerberosKeytabVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  kerberos.KeytabSecret.Name,
+				Items:       []corev1.KeyToPath{{Key: kerberos.KeytabSecret.Key, Path: SolrKerberosKeytabFile}},
+				DefaultMode: &SecretReadOnlyPermissions,
+			},
+		},
+	})
+	volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: SolrKerberosKeytabVolumeName, MountPath: SolrKerberosKeytabMountPath, ReadOnly: true})
+	if kerberos.Krb5Config != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: SolrKerberosKrb5VolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: kerberos.Krb5Config.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: kerberos.Krb5Config.Key, Path: SolrKerberosKrb5File}},
+					DefaultMode:          &PublicReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: SolrKerberosKrb5VolumeName, MountPath: SolrKerberosKrb5MountPath, ReadOnly: true})
+	}
+	return volumes, volumeMounts
+}
+
+// kerberosJaasConfig returns the JAAS config section that Solr logs in with, as the client principal, for SPNEGO requests.
+// The principal is not included, instead it is read from a system property when the JAAS config is loaded, since it differs for each pod.
+// An empty string is returned if Kerberos is not used, or if the user has provided their own JAAS config.
+func kerberosJaasConfig(solrCloud *solr.SolrCloud) string {
+	if !solrCloud.UsesKerberos() || solrCloud.Spec.SolrSecurity.JaasConfigSecret != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s {\n"+
+		"  com.sun.security.auth.module.Krb5LoginModule required\n"+
+		"  useKeyTab=true\n"+
+		"  keyTab=\"%s/%s\"\n"+
+		"  storeKey=true\n"+
+		"  useTicketCache=false\n"+
+		"  principal=\"${solrKerberosClientPrincipal}\";\n"+
+		"};", SolrKerberosJaasAppName, SolrKerberosKeytabMountPath, SolrKerberosKeytabFile)
+}
+
+// generatedJaasConfig returns the JAAS config that the operator generates, with a section for each of Zookeeper SASL and Kerberos authentication that is used.
+// Both share a single file, since the JVM only loads one JAAS config.
+func generatedJaasConfig(solrCloud *solr.SolrCloud) string {
+	var sections []string
+	for _, section := range []string{zkSASLJaasConfig(solrCloud), kerberosJaasConfig(solrCloud)} {
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	return strings.Join(sections, "\n")
+}
+
+// kerberosClientOpts returns the system properties that Solr, and the probes, need to make SPNEGO requests as the client principal.
+// The hostRef is substituted for "_HOST" in the principal, and must reference the host name of the pod, either as an env var reference or a shell variable.
+// The generatedJaasFile, in the data directory, is used unless the user has provided their own JAAS config.
+func kerberosClientOpts(solrCloud *solr.SolrCloud, hostRef string, generatedJaasFile string) []string {
+	kerberos := solrCloud.Spec.SolrSecurity.Kerberos
+	_, _, jaasSolrOpt := jaasConfigVolume(solrCloud)
+	if jaasSolrOpt == "" {
+		jaasSolrOpt = "-Djava.security.auth.login.config=/var/solr/data/" + generatedJaasFile
+	}
+	opts := []string{
+		jaasSolrOpt,
+		"-Dsolr.kerberos.jaas.appname=" + SolrKerberosJaasAppName,
+		"-DsolrKerberosClientPrincipal=" + strings.ReplaceAll(kerberos.ClientPrincipal, kerberosHostPlaceholder, hostRef),
+	}
+	if kerberos.Krb5Config != nil {
+		opts = append(opts, fmt.Sprintf("-Djava.security.krb5.conf=%s/%s", SolrKerberosKrb5MountPath, SolrKerberosKrb5File))
+	}
+	return opts
+}
+
+// kerberosEnvVars returns the environment variables that the Solr start script uses to configure Kerberos authentication.
+// The SOLR_HOST env var must be defined before these, since the principals reference it.
+func kerberosEnvVars(solrCloud *solr.SolrCloud) []corev1.EnvVar {
+	if !solrCloud.UsesKerberos() || solrCloud.Spec.SolrSecurity.Kerberos == nil {
+		return nil
+	}
+	kerberos := solrCloud.Spec.SolrSecurity.Kerberos
+	nameRules := kerberos.NameRules
+	if nameRules == "" {
+		nameRules = DefaultKerberosNameRules
+	}
+	opts := append(kerberosClientOpts(solrCloud, "$(SOLR_HOST)", ZkSASLJaasFile),
+		"-Dsolr.kerberos.principal="+strings.ReplaceAll(kerberos.ServerPrincipal, kerberosHostPlaceholder, "$(SOLR_HOST)"),
+		fmt.Sprintf("-Dsolr.kerberos.keytab=%s/%s", SolrKerberosKeytabMountPath, SolrKerberosKeytabFile),
+		"-Dsolr.kerberos.cookie.domain=$(SOLR_HOST)",
+		"-Dsolr.kerberos.name.rules="+nameRules,
+	)
+	return []corev1.EnvVar{
+		{Name: "SOLR_AUTH_TYPE", Value: "kerberos"},
+		{Name: "SOLR_AUTHENTICATION_OPTS", Value: strings.Join(opts, " ")},
+	}
+}
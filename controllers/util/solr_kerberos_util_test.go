@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func kerberosTestSolrCloud() *solr.SolrCloud {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{
+				AuthenticationType: solr.Kerberos,
+				Kerberos: &solr.SolrKerberosOptions{
+					KeytabSecret:    corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "solr-keytab"}, Key: "keytab"},
+					Krb5Config:      &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "krb5"}, Key: "krb5.conf"},
+					ServerPrincipal: "HTTP/_HOST@EXAMPLE.COM",
+					ClientPrincipal: "solr/_HOST@EXAMPLE.COM",
+				},
+			},
+			UpdateStrategy: solr.SolrUpdateStrategy{Method: solr.StatefulSetUpdate},
+		},
+	}
+	solrCloud.WithDefaults()
+	return solrCloud
+}
+
+func TestValidateKerberosOptions(t *testing.T) {
+	solrCloud := kerberosTestSolrCloud()
+	assert.NoError(t, ValidateKerberosOptions(solrCloud))
+	assert.False(t, solrCloud.UsesBasicAuth(), "The operator should not look for basic auth credentials")
+
+	solrCloud.Spec.SolrSecurity.ProbesRequireAuth = true
+	assert.Error(t, ValidateKerberosOptions(solrCloud), "Basic auth options cannot be used with Kerberos")
+	solrCloud.Spec.SolrSecurity.ProbesRequireAuth = false
+
+	solrCloud.Spec.UpdateStrategy.Method = solr.ManagedUpdate
+	solrCloud.Spec.CollectionMetrics = &solr.SolrCollectionMetricsOptions{}
+	err := ValidateKerberosOptions(solrCloud)
+	if assert.Error(t, err, "Features that need the operator to call Solr cannot be used with Kerberos") {
+		assert.Contains(t, err.Error(), "'updateStrategy.method=Managed', 'collectionMetrics'", "The error should list every incompatible option")
+	}
+	assert.Error(t, ValidateOperatorCanCallSolr(solrCloud), "The operator cannot call a Kerberized SolrCloud")
+	solrCloud.Spec.UpdateStrategy.Method = solr.ManualUpdate
+	solrCloud.Spec.CollectionMetrics = nil
+	assert.NoError(t, ValidateKerberosOptions(solrCloud))
+
+	solrCloud.Spec.SolrSecurity = &solr.SolrSecurityOptions{AuthenticationType: solr.Kerberos}
+	assert.Error(t, ValidateKerberosOptions(solrCloud), "The kerberos options are required")
+}
+
+func TestKerberosEnvVars(t *testing.T) {
+	solrCloud := kerberosTestSolrCloud()
+	envVars := kerberosEnvVars(solrCloud)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "SOLR_AUTH_TYPE", Value: "kerberos"},
+		{Name: "SOLR_AUTHENTICATION_OPTS", Value: "-Djava.security.auth.login.config=/var/solr/data/zk-jaas.conf -Dsolr.kerberos.jaas.appname=SolrClient " +
+			"-DsolrKerberosClientPrincipal=solr/$(SOLR_HOST)@EXAMPLE.COM -Djava.security.krb5.conf=/etc/solr/kerberos/krb5/krb5.conf " +
+			"-Dsolr.kerberos.principal=HTTP/$(SOLR_HOST)@EXAMPLE.COM -Dsolr.kerberos.keytab=/etc/solr/kerberos/keytab/solr.keytab " +
+			"-Dsolr.kerberos.cookie.domain=$(SOLR_HOST) -Dsolr.kerberos.name.rules=DEFAULT"},
+	}, envVars, "Wrong Kerberos env vars")
+
+	volumes, volumeMounts := kerberosVolumes(solrCloud)
+	assert.Len(t, volumes, 2, "The keytab and krb5.conf should be mounted")
+	assert.Len(t, volumeMounts, 2, "The keytab and krb5.conf should be mounted")
+
+	assert.Contains(t, generatedJaasConfig(solrCloud), "SolrClient {", "The JAAS config should have a section for Solr's SPNEGO requests")
+	solrCloud.Spec.SolrSecurity.JaasConfigSecret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "jaas"}, Key: "jaas.conf"}
+	assert.Empty(t, generatedJaasConfig(solrCloud), "No JAAS config should be generated when the user provides one")
+}
+
+func TestKerberosProbes(t *testing.T) {
+	probes, volume, _ := GenerateSolrProbes(kerberosTestSolrCloud(), nil)
+	assert.Nil(t, volume, "The Kerberos files are already mounted for Solr")
+	if assert.NotNil(t, probes.Readiness.Exec, "The probes should use a command when Solr requires Kerberos authentication") {
+		assert.Contains(t, probes.Readiness.Exec.Command[2], "-Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.Krb5HttpClientBuilder")
+		assert.Contains(t, probes.Readiness.Exec.Command[2], "-Djava.security.auth.login.config=/var/solr/data/kerberos-jaas.conf")
+		assert.Contains(t, probes.Readiness.Exec.Command[2], "-DsolrKerberosClientPrincipal=solr/${SOLR_HOST}@EXAMPLE.COM")
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"net/url"
+	"sort"
+)
+
+// IsNodeInterrupted determines whether the Kubernetes Node has any of the taints that signal an upcoming interruption
+func IsNodeInterrupted(node *corev1.Node, taintKeys []string) bool {
+	for _, taint := range node.Spec.Taints {
+		for _, key := range taintKeys {
+			if taint.Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preferredLeader is a replica that should take over the leadership of its shard
+type preferredLeader struct {
+	collection string
+	shard      string
+	replica    string
+}
+
+// MoveLeadersOffSolrNodes asks Solr to move the leaders of all shards that are led by replicas on the given Solr nodes
+// to replicas on other live nodes, and returns the number of shards whose leaders are being moved.
+// Leader elections happen asynchronously, so this should be called until no shards are returned.
+// Shards without an active replica on another live node cannot be moved, and are not included.
+func MoveLeadersOffSolrNodes(cloud *solr.SolrCloud, solrNodes map[string]bool, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (movingShards int, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return 0, err
+	}
+
+	preferredLeaders := findPreferredLeadersOffSolrNodes(clusterStatus, solrNodes)
+	collectionsToRebalance := make([]string, 0)
+	for _, leader := range preferredLeaders {
+		logger.Info("Moving shard leader off of interrupted Solr node", "collection", leader.collection, "shard", leader.shard, "newLeader", leader.replica)
+		if err = setPreferredLeader(cloud, leader, httpHeaders); err != nil {
+			return 0, err
+		}
+		if len(collectionsToRebalance) == 0 || collectionsToRebalance[len(collectionsToRebalance)-1] != leader.collection {
+			collectionsToRebalance = append(collectionsToRebalance, leader.collection)
+		}
+	}
+	for _, collection := range collectionsToRebalance {
+		if err = rebalanceLeaders(cloud, collection, httpHeaders); err != nil {
+			return 0, err
+		}
+	}
+	return len(preferredLeaders), nil
+}
+
+// findPreferredLeadersOffSolrNodes chooses, for each shard led by a replica on one of the given Solr nodes, an active replica on
+// another live node to take over the leadership.
+func findPreferredLeadersOffSolrNodes(clusterStatus solr_api.SolrClusterStatus, solrNodes map[string]bool) (preferredLeaders []preferredLeader) {
+	liveNodes := make(map[string]bool, len(clusterStatus.LiveNodes))
+	for _, node := range clusterStatus.LiveNodes {
+		liveNodes[node] = true
+	}
+
+	collectionNames := make([]string, 0, len(clusterStatus.Collections))
+	for collectionName := range clusterStatus.Collections {
+		collectionNames = append(collectionNames, collectionName)
+	}
+	sort.Strings(collectionNames)
+
+	for _, collectionName := range collectionNames {
+		shards := clusterStatus.Collections[collectionName].Shards
+		shardNames := make([]string, 0, len(shards))
+		for shardName := range shards {
+			shardNames = append(shardNames, shardName)
+		}
+		sort.Strings(shardNames)
+
+		for _, shardName := range shardNames {
+			replicas := shards[shardName].Replicas
+			replicaNames := make([]string, 0, len(replicas))
+			ledByInterruptedNode := false
+			for replicaName, replica := range replicas {
+				replicaNames = append(replicaNames, replicaName)
+				if replica.Leader && solrNodes[replica.NodeName] {
+					ledByInterruptedNode = true
+				}
+			}
+			if !ledByInterruptedNode {
+				continue
+			}
+
+			sort.Strings(replicaNames)
+			for _, replicaName := range replicaNames {
+				replica := replicas[replicaName]
+				// PULL replicas can never become leaders
+				if replica.Type != solr_api.PULL && replica.State == solr_api.ReplicaActive && liveNodes[replica.NodeName] && !solrNodes[replica.NodeName] {
+					preferredLeaders = append(preferredLeaders, preferredLeader{collection: collectionName, shard: shardName, replica: replicaName})
+					break
+				}
+			}
+		}
+	}
+	return preferredLeaders
+}
+
+// replicaEvacuation is a replica to add to a shard, so that the shard is not lost along with the interrupted Solr nodes
+type replicaEvacuation struct {
+	shard       SingleReplicaShard
+	node        string
+	replicaType solr_api.SolrReplicaType
+}
+
+// EvacuateReplicasOffSolrNodes asks Solr to add replicas on other live nodes for the shards that have no replica, or no replica that can become
+// the leader, outside of the given Solr nodes. It returns the number of replicas that were added.
+// The replicas on the given Solr nodes are not deleted, since the nodes return when their pods are rescheduled.
+func EvacuateReplicasOffSolrNodes(cloud *solr.SolrCloud, solrNodes map[string]bool, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (addedReplicas int, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return 0, err
+	}
+	overseerLeader, err := clusterState.OverseerLeader()
+	if err != nil {
+		return 0, err
+	}
+	nodeContents, _, _ := findSolrNodeContents(clusterStatus, overseerLeader)
+
+	for _, evacuation := range findEvacuationsOffSolrNodes(clusterStatus, solrNodes, nodeContents) {
+		logger.Info("Adding replica to evacuate shard off of interrupted Solr nodes", "collection", evacuation.shard.Collection, "shard", evacuation.shard.Shard, "node", evacuation.node, "type", evacuation.replicaType)
+		if err = addReplica(cloud, evacuation.shard, evacuation.node, evacuation.replicaType, httpHeaders); err != nil {
+			return addedReplicas, err
+		}
+		addedReplicas++
+	}
+	return addedReplicas, nil
+}
+
+// findEvacuationsOffSolrNodes chooses the replicas to add for the shards that would be left without a replica, or without a replica that can
+// become the leader, when the given Solr nodes go away. Each replica is placed on the live node outside of the given Solr nodes
+// with the fewest replicas, and has the same type as a replica that is being lost.
+func findEvacuationsOffSolrNodes(clusterStatus solr_api.SolrClusterStatus, solrNodes map[string]bool, nodeContents map[string]*SolrNodeContents) (evacuations []replicaEvacuation) {
+	liveNodes := make(map[string]bool, len(clusterStatus.LiveNodes))
+	candidateNodes := make([]string, 0, len(clusterStatus.LiveNodes))
+	for _, node := range clusterStatus.LiveNodes {
+		liveNodes[node] = true
+		if !solrNodes[node] {
+			candidateNodes = append(candidateNodes, node)
+		}
+	}
+	if len(candidateNodes) == 0 {
+		return nil
+	}
+
+	collectionNames := make([]string, 0, len(clusterStatus.Collections))
+	for collectionName := range clusterStatus.Collections {
+		collectionNames = append(collectionNames, collectionName)
+	}
+	sort.Strings(collectionNames)
+
+	for _, collectionName := range collectionNames {
+		shards := clusterStatus.Collections[collectionName].Shards
+		shardNames := make([]string, 0, len(shards))
+		for shardName := range shards {
+			shardNames = append(shardNames, shardName)
+		}
+		sort.Strings(shardNames)
+
+		for _, shardName := range shardNames {
+			replicas := shards[shardName].Replicas
+			replicaNames := make([]string, 0, len(replicas))
+			for replicaName := range replicas {
+				replicaNames = append(replicaNames, replicaName)
+			}
+			sort.Strings(replicaNames)
+
+			var lostReplicaType, lostLeaderEligibleType solr_api.SolrReplicaType
+			hasReplicaElsewhere, hasLeaderEligibleElsewhere := false, false
+			for _, replicaName := range replicaNames {
+				replica := replicas[replicaName]
+				if solrNodes[replica.NodeName] {
+					if lostReplicaType == "" {
+						lostReplicaType = replica.Type
+					}
+					// PULL replicas can never become leaders
+					if lostLeaderEligibleType == "" && replica.Type != solr_api.PULL {
+						lostLeaderEligibleType = replica.Type
+					}
+				} else if liveNodes[replica.NodeName] {
+					hasReplicaElsewhere = true
+					if replica.Type != solr_api.PULL {
+						hasLeaderEligibleElsewhere = true
+					}
+				}
+			}
+
+			var replicaType solr_api.SolrReplicaType
+			switch {
+			case lostLeaderEligibleType != "" && !hasLeaderEligibleElsewhere:
+				replicaType = lostLeaderEligibleType
+			case lostReplicaType != "" && !hasReplicaElsewhere:
+				replicaType = lostReplicaType
+			default:
+				continue
+			}
+
+			node := chooseTemporaryReplicaNode(candidateNodes, nodeContents, "", nil)
+			evacuations = append(evacuations, replicaEvacuation{
+				shard:       SingleReplicaShard{Collection: collectionName, Shard: shardName},
+				node:        node,
+				replicaType: replicaType,
+			})
+			// Spread the added replicas across the remaining nodes
+			if contents, hasContents := nodeContents[node]; hasContents {
+				contents.replicas++
+			}
+		}
+	}
+	return evacuations
+}
+
+func setPreferredLeader(cloud *solr.SolrCloud, leader preferredLeader, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "ADDREPLICAPROP")
+	queryParams.Add("collection", leader.collection)
+	queryParams.Add("shard", leader.shard)
+	queryParams.Add("replica", leader.replica)
+	queryParams.Add("property", "preferredLeader")
+	queryParams.Add("property.value", "true")
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("ADDREPLICAPROP", resp.ResponseHeader)
+	}
+	return err
+}
+
+func rebalanceLeaders(cloud *solr.SolrCloud, collection string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "REBALANCELEADERS")
+	queryParams.Add("collection", collection)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("REBALANCELEADERS", resp.ResponseHeader)
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestIsNodeInterrupted(t *testing.T) {
+	opts := &solr.SolrNodeInterruptionOptions{}
+	solrCloud := &solr.SolrCloud{Spec: solr.SolrCloudSpec{NodeInterruption: opts}}
+	solrCloud.WithDefaults()
+	assert.Equal(t, solr.DefaultNodeInterruptionTaintKeys, opts.TaintKeys, "The default interruption taints were not set")
+
+	node := &corev1.Node{}
+	assert.False(t, IsNodeInterrupted(node, opts.TaintKeys), "A Node without taints is not interrupted")
+	node.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "solr", Effect: corev1.TaintEffectNoSchedule}}
+	assert.False(t, IsNodeInterrupted(node, opts.TaintKeys), "A Node with unrelated taints is not interrupted")
+	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoSchedule})
+	assert.True(t, IsNodeInterrupted(node, opts.TaintKeys), "A Node with a spot interruption taint is interrupted")
+}
+
+func TestFindPreferredLeadersOffSolrNodes(t *testing.T) {
+	clusterStatus := solr_api.SolrClusterStatus{
+		LiveNodes: []string{"node-1", "node-2", "node-3"},
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node1": {NodeName: "node-1", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+							"core_node2": {NodeName: "node-2", State: solr_api.ReplicaActive, Type: solr_api.PULL},
+							"core_node3": {NodeName: "node-3", State: solr_api.ReplicaRecovering, Type: solr_api.NRT},
+							"core_node4": {NodeName: "node-3", State: solr_api.ReplicaActive, Type: solr_api.TLOG},
+						},
+					},
+					"shard2": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node5": {NodeName: "node-2", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+							"core_node6": {NodeName: "node-1", State: solr_api.ReplicaActive, Type: solr_api.NRT},
+						},
+					},
+					"shard3": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node7": {NodeName: "node-1", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	preferredLeaders := findPreferredLeadersOffSolrNodes(clusterStatus, map[string]bool{"node-1": true})
+	assert.Equal(t, []preferredLeader{{collection: "col", shard: "shard1", replica: "core_node4"}}, preferredLeaders,
+		"Only the shards led by the interrupted node should be moved, to an active non-PULL replica on another node")
+
+	assert.Empty(t, findPreferredLeadersOffSolrNodes(clusterStatus, map[string]bool{"node-4": true}), "No leaders should move when no leaders are on the interrupted nodes")
+}
+
+func TestFindEvacuationsOffSolrNodes(t *testing.T) {
+	clusterStatus := solr_api.SolrClusterStatus{
+		LiveNodes: []string{"node-1", "node-2", "node-3", "node-4"},
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node1": {NodeName: "node-1", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.TLOG},
+							"core_node2": {NodeName: "node-3", State: solr_api.ReplicaActive, Type: solr_api.PULL},
+						},
+					},
+					"shard2": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node3": {NodeName: "node-1", State: solr_api.ReplicaActive, Type: solr_api.PULL},
+							"core_node4": {NodeName: "node-2", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+						},
+					},
+					"shard3": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node5": {NodeName: "node-1", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+							"core_node6": {NodeName: "node-3", State: solr_api.ReplicaActive, Type: solr_api.NRT},
+						},
+					},
+					"shard4": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node7": {NodeName: "node-2", State: solr_api.ReplicaActive, Leader: true, Type: solr_api.NRT},
+						},
+					},
+				},
+			},
+		},
+	}
+	nodeContents, _, _ := findSolrNodeContents(clusterStatus, "")
+
+	evacuations := findEvacuationsOffSolrNodes(clusterStatus, map[string]bool{"node-1": true, "node-2": true}, nodeContents)
+	assert.Equal(t, []replicaEvacuation{
+		{shard: SingleReplicaShard{Collection: "col", Shard: "shard1"}, node: "node-4", replicaType: solr_api.TLOG},
+		{shard: SingleReplicaShard{Collection: "col", Shard: "shard2"}, node: "node-4", replicaType: solr_api.NRT},
+		{shard: SingleReplicaShard{Collection: "col", Shard: "shard4"}, node: "node-3", replicaType: solr_api.NRT},
+	}, evacuations, "Replicas should only be added for the shards left without a leader-eligible replica, on the least loaded remaining nodes")
+
+	assert.Empty(t, findEvacuationsOffSolrNodes(clusterStatus, map[string]bool{"node-1": true, "node-2": true, "node-3": true, "node-4": true}, nodeContents),
+		"No replicas can be added when every live node is interrupted")
+}
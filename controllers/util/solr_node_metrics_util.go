@@ -0,0 +1,252 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The keys of the Solr metrics that are relayed, in the "registry:metric[:property]" form of the Metrics API's key parameter
+const (
+	heapUsedMetricKey    = "solr.jvm:memory.heap.used"
+	heapMaxMetricKey     = "solr.jvm:memory.heap.max"
+	requestsMetricKey    = "solr.jetty:org.eclipse.jetty.server.handler.DefaultHandler.requests:count"
+	coresLoadedMetricKey = "solr.node:CONTAINER.cores.loaded"
+)
+
+var (
+	nodeMetricLabels = []string{"namespace", "solrcloud", "pod"}
+
+	solrNodeUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solr_node_up",
+			Help: "Whether the metrics of the Solr pod could be scraped at the last refresh.",
+		},
+		nodeMetricLabels,
+	)
+
+	// relayedNodeMetrics are the metrics for the relayed Solr metrics, by their Metrics API key
+	relayedNodeMetrics = map[string]relayedMetric{
+		heapUsedMetricKey: relayedGauge{prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solr_node_heap_used_bytes",
+				Help: "The heap memory used by the Solr JVM.",
+			},
+			nodeMetricLabels,
+		)},
+		heapMaxMetricKey: relayedGauge{prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solr_node_heap_max_bytes",
+				Help: "The maximum heap memory of the Solr JVM.",
+			},
+			nodeMetricLabels,
+		)},
+		requestsMetricKey: newRelayedCounter(
+			"solr_node_requests_total",
+			"The number of HTTP requests handled by the Solr pod since Solr started.",
+			nodeMetricLabels,
+		),
+		coresLoadedMetricKey: relayedGauge{prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solr_node_cores_loaded",
+				Help: "The number of cores loaded by the Solr pod.",
+			},
+			nodeMetricLabels,
+		)},
+	}
+
+	// exportedNodes keeps track of the Solr pods that metrics have been relayed for, by SolrCloud,
+	// so that the metrics of removed pods can be removed.
+	exportedNodes = newMetricSeries(deleteNodeMetrics)
+)
+
+func init() {
+	// The controller-runtime registry is served on the operator's metrics endpoint
+	metrics.Registry.MustRegister(solrNodeUp)
+	for _, relayed := range relayedNodeMetrics {
+		metrics.Registry.MustRegister(relayed)
+	}
+}
+
+// relayedMetric is a metric whose values are scraped from Solr and exported as they are
+type relayedMetric interface {
+	prometheus.Collector
+	set(labels prometheus.Labels, value float64)
+	delete(labels prometheus.Labels)
+}
+
+type relayedGauge struct {
+	*prometheus.GaugeVec
+}
+
+func (g relayedGauge) set(labels prometheus.Labels, value float64) {
+	g.With(labels).Set(value)
+}
+
+func (g relayedGauge) delete(labels prometheus.Labels) {
+	g.Delete(labels)
+}
+
+// relayedCounter exports the values of a Solr counter with the counter type.
+// A prometheus.Counter cannot be set to a value, and the value is counted by Solr, so the last scraped values are exported as constant metrics.
+type relayedCounter struct {
+	desc       *prometheus.Desc
+	labelNames []string
+	lock       sync.Mutex
+	values     map[string]relayedCounterValue
+}
+
+type relayedCounterValue struct {
+	labelValues []string
+	value       float64
+}
+
+func newRelayedCounter(name string, help string, labelNames []string) *relayedCounter {
+	return &relayedCounter{
+		desc:       prometheus.NewDesc(name, help, labelNames, nil),
+		labelNames: labelNames,
+		values:     map[string]relayedCounterValue{},
+	}
+}
+
+func (c *relayedCounter) labelValues(labels prometheus.Labels) []string {
+	labelValues := make([]string, len(c.labelNames))
+	for i, labelName := range c.labelNames {
+		labelValues[i] = labels[labelName]
+	}
+	return labelValues
+}
+
+func (c *relayedCounter) set(labels prometheus.Labels, value float64) {
+	labelValues := c.labelValues(labels)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values[strings.Join(labelValues, "/")] = relayedCounterValue{labelValues: labelValues, value: value}
+}
+
+func (c *relayedCounter) delete(labels prometheus.Labels) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.values, strings.Join(c.labelValues(labels), "/"))
+}
+
+func (c *relayedCounter) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c *relayedCounter) Collect(metrics chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, counterValue := range c.values {
+		metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, counterValue.value, counterValue.labelValues...)
+	}
+}
+
+// UpdateSolrNodeMetrics scrapes the relayed metrics from each of the given Solr nodes, and removes the metrics of pods that no longer exist.
+// The metrics are scraped at most once every refreshIntervalSeconds, and the time until the next scrape is due is returned.
+// Nodes that are not ready, or cannot be scraped, are reported as down. The first scrape error is returned.
+func UpdateSolrNodeMetrics(cloud *solr.SolrCloud, nodes []solr.SolrNodeStatus, httpHeaders map[string]string) (nextRefresh time.Duration, err error) {
+	refreshInterval := time.Second * time.Duration(cloud.Spec.NodeMetrics.RefreshIntervalSeconds)
+	if untilRefresh := exportedNodes.untilRefresh(cloud.Namespace, cloud.Name, refreshInterval); untilRefresh > 0 {
+		return untilRefresh, nil
+	}
+
+	pods := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		pods[node.Name] = true
+		labels := prometheus.Labels{"namespace": cloud.Namespace, "solrcloud": cloud.Name, "pod": node.Name}
+
+		var values map[string]float64
+		if node.Ready {
+			var scrapeErr error
+			if values, scrapeErr = scrapeSolrNodeMetrics(cloud, node.Name, httpHeaders); scrapeErr != nil && err == nil {
+				err = fmt.Errorf("error scraping the metrics of Solr pod %s: %w", node.Name, scrapeErr)
+			}
+		}
+		if values == nil {
+			solrNodeUp.With(labels).Set(0)
+		} else {
+			solrNodeUp.With(labels).Set(1)
+		}
+		// Metrics that were not returned are removed, rather than left at a stale value
+		for key, relayed := range relayedNodeMetrics {
+			if value, hasValue := values[key]; hasValue {
+				relayed.set(labels, value)
+			} else {
+				relayed.delete(labels)
+			}
+		}
+	}
+
+	exportedNodes.refreshed(cloud.Namespace, cloud.Name, pods)
+	return refreshInterval, err
+}
+
+// RemoveSolrNodeMetrics removes the relayed metrics of every Solr pod in the SolrCloud, for SolrClouds that are deleted or no longer relay node metrics
+func RemoveSolrNodeMetrics(namespace string, solrCloudName string) {
+	exportedNodes.remove(namespace, solrCloudName)
+}
+
+func deleteNodeMetrics(namespace string, solrCloudName string, pod string) {
+	labels := prometheus.Labels{"namespace": namespace, "solrcloud": solrCloudName, "pod": pod}
+	solrNodeUp.Delete(labels)
+	for _, relayed := range relayedNodeMetrics {
+		relayed.delete(labels)
+	}
+}
+
+// scrapeSolrNodeMetrics fetches the relayed metrics from a single Solr node, through one request to the Metrics API
+func scrapeSolrNodeMetrics(cloud *solr.SolrCloud, node string, httpHeaders map[string]string) (map[string]float64, error) {
+	queryParams := url.Values{}
+	for key := range relayedNodeMetrics {
+		queryParams.Add("key", key)
+	}
+	body, err := solr_api.CallNodeAdminApi(cloud, solrNodeUrl(cloud, node), "/solr/admin/metrics", queryParams, httpHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return parseSolrNodeMetrics(body)
+}
+
+// parseSolrNodeMetrics reads the numeric values of the relayed metrics from a Metrics API response.
+// Keys that Solr does not know, for example because of its version, are not included.
+func parseSolrNodeMetrics(body []byte) (map[string]float64, error) {
+	metricsResponse := &struct {
+		Metrics map[string]interface{} `json:"metrics"`
+	}{}
+	if err := json.Unmarshal(body, metricsResponse); err != nil {
+		return nil, fmt.Errorf("could not parse the metrics response: %w", err)
+	}
+	values := make(map[string]float64, len(relayedNodeMetrics))
+	for key := range relayedNodeMetrics {
+		if value, isNumber := metricsResponse.Metrics[key].(float64); isNumber {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSolrNodeMetrics(t *testing.T) {
+	body := []byte(`{
+  "responseHeader": {"status": 0, "QTime": 1},
+  "metrics": {
+    "solr.jvm:memory.heap.used": 268435456,
+    "solr.jvm:memory.heap.max": 536870912,
+    "solr.node:CONTAINER.cores.loaded": 3
+  },
+  "errors": {"solr.jetty:org.eclipse.jetty.server.handler.DefaultHandler.requests:count": "not found"}
+}`)
+	values, err := parseSolrNodeMetrics(body)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		heapUsedMetricKey:    268435456,
+		heapMaxMetricKey:     536870912,
+		coresLoadedMetricKey: 3,
+	}, values, "Metrics that Solr did not return should not be included")
+
+	_, err = parseSolrNodeMetrics([]byte("<html>"))
+	assert.Error(t, err, "A response that is not JSON should return an error")
+}
+
+func TestRelayedCounter(t *testing.T) {
+	counter := newRelayedCounter("solr_test_requests_total", "Test counter.", nodeMetricLabels)
+	labels := prometheus.Labels{"namespace": "default", "solrcloud": "foo", "pod": "foo-solrcloud-0"}
+	counter.set(labels, 42)
+
+	metrics := make(chan prometheus.Metric, 1)
+	counter.Collect(metrics)
+	close(metrics)
+	metric := &dto.Metric{}
+	assert.NoError(t, (<-metrics).Write(metric))
+	if assert.NotNil(t, metric.Counter, "The relayed requests should be exported as a counter") {
+		assert.Equal(t, float64(42), metric.Counter.GetValue())
+	}
+
+	counter.delete(labels)
+	metrics = make(chan prometheus.Metric, 1)
+	counter.Collect(metrics)
+	close(metrics)
+	assert.Empty(t, metrics, "A deleted series should no longer be exported")
+}
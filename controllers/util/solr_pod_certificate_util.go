@@ -0,0 +1,160 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+)
+
+const (
+	CertManagerAPIGroup = "cert-manager.io"
+
+	// The cert-manager CSI driver issues a certificate into each volume that uses it, when the pod of the volume starts
+	CertManagerCSIDriver = "csi.cert-manager.io"
+
+	// The path where the certificate of a Solr pod is mounted into the initContainer that generates the keystore of the pod
+	PodCertificatePath = "/var/solr/pod-certificate"
+
+	// The lifetime of the certificates that the cert-manager CSI driver requests, when no duration is given
+	DefaultPodCertificateDuration = 720 * time.Hour
+)
+
+// CertManagerCSIAttribute returns the name of a volume attribute of the cert-manager CSI driver
+func CertManagerCSIAttribute(name string) string {
+	return CertManagerCSIDriver + "/" + name
+}
+
+// ValidatePerPodCertificateOptions returns an error if the per-pod certificates cannot be combined with the rest of the TLS config of the SolrCloud
+func ValidatePerPodCertificateOptions(solrCloud *solr.SolrCloud) error {
+	opts := solrCloud.Spec.SolrTLS
+	if opts.PKCS12Secret != nil || opts.MountedTLSDir != nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, 'solrTLS.perPodCertificates' cannot be combined with 'solrTLS.pkcs12Secret' or 'solrTLS.mountedTLSDir'")
+	}
+	if opts.KeyStorePasswordSecret == nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, 'solrTLS.keyStorePasswordSecret' is required to protect the keystores of the per-pod certificates")
+	}
+	if solrCloud.Spec.SolrClientTLS != nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, 'solrClientTLS' cannot be used with 'solrTLS.perPodCertificates', since each pod uses its own certificate as its client certificate")
+	}
+	if opts.PerPodCertificates.IssuerRef.Name == "" {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, 'solrTLS.perPodCertificates.issuerRef.name' is required")
+	}
+	if _, err := PodCertificateRestartInterval(opts.PerPodCertificates); err != nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, %s", err.Error())
+	}
+	return nil
+}
+
+// PodCertificateHostnames returns the hostnames that the certificate of a Solr pod is issued for.
+// These are the hostnames that the pod is addressed by, within and outside of the Kubernetes cluster, and not those of any other pod.
+// The hostname of the common service is included as well, since requests to the common service may be routed to any pod.
+func PodCertificateHostnames(solrCloud *solr.SolrCloud, nodeName string) (hostnames []string) {
+	addHostname := func(hostname string) {
+		// Hosts that depend on the IP of the pod are not known until the pod is scheduled, so they cannot be added to the certificate
+		if hostname == "" || strings.Contains(hostname, "$(") {
+			return
+		}
+		for _, existing := range hostnames {
+			if existing == hostname {
+				return
+			}
+		}
+		hostnames = append(hostnames, hostname)
+	}
+
+	addHostname(solrCloud.InternalNodeUrl(nodeName, false))
+	if external := solrCloud.Spec.SolrAddressability.External; external != nil && !external.HideNodes && external.DomainName != "" {
+		addHostname(solrCloud.ExternalNodeUrl(nodeName, external.DomainName, false))
+		for _, domainName := range external.AdditionalDomainNames {
+			addHostname(solrCloud.ExternalNodeUrl(nodeName, domainName, false))
+		}
+	}
+	addHostname(solrCloud.AdvertisedNodeHost(nodeName))
+	addHostname(solrCloud.InternalCommonUrl(false))
+	return hostnames
+}
+
+// PodCertificateAttributes returns the attributes of the cert-manager CSI driver volume that the certificate of each Solr pod is issued into.
+// Every pod uses the same StatefulSet template, so the hostnames are given for the ${POD_NAME} that the CSI driver replaces with the name of the pod.
+func PodCertificateAttributes(solrCloud *solr.SolrCloud) map[string]string {
+	opts := solrCloud.Spec.SolrTLS.PerPodCertificates
+	issuerKind := opts.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = "Issuer"
+	}
+	issuerGroup := opts.IssuerRef.Group
+	if issuerGroup == "" {
+		issuerGroup = CertManagerAPIGroup
+	}
+
+	attributes := map[string]string{
+		CertManagerCSIAttribute("issuer-name"):  opts.IssuerRef.Name,
+		CertManagerCSIAttribute("issuer-kind"):  issuerKind,
+		CertManagerCSIAttribute("issuer-group"): issuerGroup,
+		CertManagerCSIAttribute("dns-names"):    strings.Join(PodCertificateHostnames(solrCloud, "${POD_NAME}"), ","),
+		// Each pod uses its certificate as its client certificate as well, when making requests to the other pods
+		CertManagerCSIAttribute("key-usages"): "digital signature,key encipherment,server auth,client auth",
+	}
+	if opts.Duration != "" {
+		attributes[CertManagerCSIAttribute("duration")] = opts.Duration
+	}
+	if opts.RenewBefore != "" {
+		attributes[CertManagerCSIAttribute("renew-before")] = opts.RenewBefore
+	}
+	return attributes
+}
+
+// PodCertificateRestartInterval returns how often the Solr pods need to be restarted, so that they start using a new certificate before their certificate expires.
+// The CSI driver renews a certificate in the volume of its pod, but Solr only reads its keystore when it starts,
+// so a pod has to be restarted between the renewal and the expiry of its certificate.
+func PodCertificateRestartInterval(opts *solr.PerPodCertificateOptions) (interval time.Duration, err error) {
+	duration := DefaultPodCertificateDuration
+	if opts.Duration != "" {
+		if duration, err = time.ParseDuration(opts.Duration); err != nil {
+			return 0, fmt.Errorf("'solrTLS.perPodCertificates.duration' is not a valid duration: %w", err)
+		}
+	}
+	// By default, the CSI driver renews a certificate once two thirds of its lifetime have passed
+	renewBefore := duration / 3
+	if opts.RenewBefore != "" {
+		if renewBefore, err = time.ParseDuration(opts.RenewBefore); err != nil {
+			return 0, fmt.Errorf("'solrTLS.perPodCertificates.renewBefore' is not a valid duration: %w", err)
+		}
+	}
+	if renewBefore <= 0 || renewBefore >= duration {
+		return 0, fmt.Errorf("'solrTLS.perPodCertificates.renewBefore' must be positive and shorter than the duration of the certificates")
+	}
+	return duration - renewBefore, nil
+}
+
+// PodCertificateRestartSchedule returns the schedule that restarts the Solr pods before the per-pod certificates that they use expire,
+// when the SolrCloud does not have a restartSchedule of its own.
+func PodCertificateRestartSchedule(solrCloud *solr.SolrCloud) string {
+	if solrCloud.Spec.UpdateStrategy.RestartSchedule != "" || solrCloud.Spec.SolrTLS == nil || solrCloud.Spec.SolrTLS.PerPodCertificates == nil {
+		return solrCloud.Spec.UpdateStrategy.RestartSchedule
+	}
+	if interval, err := PodCertificateRestartInterval(solrCloud.Spec.SolrTLS.PerPodCertificates); err == nil {
+		return "@every " + interval.String()
+	}
+	// Invalid durations are reported by ValidatePerPodCertificateOptions()
+	return ""
+}
@@ -0,0 +1,175 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podCertificateTestSolrCloud() *solr.SolrCloud {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			SolrTLS: &solr.SolrTLSOptions{
+				KeyStorePasswordSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "keystore-password"},
+					Key:                  "password",
+				},
+				ClientAuth:    solr.Need,
+				CheckPeerName: true,
+				PerPodCertificates: &solr.PerPodCertificateOptions{
+					IssuerRef: solr.CertificateIssuerReference{Name: "solr-ca", Kind: "ClusterIssuer"},
+					Duration:  "720h",
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	return solrCloud
+}
+
+func TestValidatePerPodCertificateOptions(t *testing.T) {
+	solrCloud := podCertificateTestSolrCloud()
+	assert.NoError(t, ValidatePerPodCertificateOptions(solrCloud))
+
+	solrCloud.Spec.SolrTLS.PKCS12Secret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls"}, Key: "keystore.p12"}
+	assert.Error(t, ValidatePerPodCertificateOptions(solrCloud), "A shared certificate cannot be used with per-pod certificates")
+	solrCloud.Spec.SolrTLS.PKCS12Secret = nil
+
+	solrCloud.Spec.SolrClientTLS = &solr.SolrTLSOptions{}
+	assert.Error(t, ValidatePerPodCertificateOptions(solrCloud), "The per-pod certificates are used as client certificates")
+	solrCloud.Spec.SolrClientTLS = nil
+
+	solrCloud.Spec.SolrTLS.KeyStoreType = solr.BCFKSKeyStore
+	assert.NoError(t, ValidatePerPodCertificateOptions(solrCloud), "The generated keystores can be converted to any keystore type")
+	solrCloud.Spec.SolrTLS.KeyStoreType = solr.PKCS12KeyStore
+
+	solrCloud.Spec.SolrTLS.PerPodCertificates.RenewBefore = "720h"
+	assert.Error(t, ValidatePerPodCertificateOptions(solrCloud), "The certificates must be renewed before they expire")
+	solrCloud.Spec.SolrTLS.PerPodCertificates.RenewBefore = "30 days"
+	assert.Error(t, ValidatePerPodCertificateOptions(solrCloud), "The renewBefore must be a Go duration")
+	solrCloud.Spec.SolrTLS.PerPodCertificates.RenewBefore = ""
+
+	solrCloud.Spec.SolrTLS.KeyStorePasswordSecret = nil
+	assert.Error(t, ValidatePerPodCertificateOptions(solrCloud), "The generated keystores must be protected by a password")
+}
+
+func TestPodCertificateAttributes(t *testing.T) {
+	solrCloud := podCertificateTestSolrCloud()
+	nodeNames := solrCloud.GetAllSolrNodeNames()
+
+	assert.Equal(t, []string{"foo-solrcloud-0.foo-solrcloud-headless.default", "foo-solrcloud-common.default"}, PodCertificateHostnames(solrCloud, nodeNames[0]),
+		"The certificate should only contain the hostnames of its own pod, and the common service")
+
+	solrCloud.Spec.SolrAddressability.External = &solr.ExternalAddressability{
+		Method:                solr.ExternalDNS,
+		DomainName:            "example.com",
+		AdditionalDomainNames: []string{"example.org"},
+		UseExternalAddress:    true,
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, []string{"foo-solrcloud-1.foo-solrcloud-headless.default", "foo-solrcloud-1.default.example.com", "foo-solrcloud-1.default.example.org", "foo-solrcloud-common.default"},
+		PodCertificateHostnames(solrCloud, nodeNames[1]), "The external hostnames of the pod should be included")
+
+	attributes := PodCertificateAttributes(solrCloud)
+	assert.Equal(t, "solr-ca", attributes["csi.cert-manager.io/issuer-name"])
+	assert.Equal(t, "ClusterIssuer", attributes["csi.cert-manager.io/issuer-kind"])
+	assert.Equal(t, CertManagerAPIGroup, attributes["csi.cert-manager.io/issuer-group"])
+	assert.Equal(t, strings.Join(PodCertificateHostnames(solrCloud, "${POD_NAME}"), ","), attributes["csi.cert-manager.io/dns-names"],
+		"The CSI driver should issue the certificate for the hostnames of the pod it is mounted into")
+	assert.Contains(t, attributes["csi.cert-manager.io/dns-names"], "${POD_NAME}.foo-solrcloud-headless.default")
+	assert.Contains(t, attributes["csi.cert-manager.io/key-usages"], "client auth", "The pods use their certificates as client certificates")
+	assert.Equal(t, "720h", attributes["csi.cert-manager.io/duration"])
+	_, hasRenewBefore := attributes["csi.cert-manager.io/renew-before"]
+	assert.False(t, hasRenewBefore, "The renewBefore should be left to the CSI driver")
+}
+
+func TestPodCertificateRestartSchedule(t *testing.T) {
+	opts := &solr.PerPodCertificateOptions{}
+	interval, err := PodCertificateRestartInterval(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 480*time.Hour, interval, "The pods should be restarted once the default certificates of the CSI driver are renewed")
+
+	opts.Duration = "2160h"
+	opts.RenewBefore = "360h"
+	interval, err = PodCertificateRestartInterval(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1800*time.Hour, interval)
+
+	solrCloud := podCertificateTestSolrCloud()
+	assert.Equal(t, "@every 480h0m0s", PodCertificateRestartSchedule(solrCloud), "The pods should be restarted before their certificates expire")
+	_, _, err = ScheduleNextRestart(PodCertificateRestartSchedule(solrCloud), map[string]string{})
+	assert.NoError(t, err, "The restart schedule should be understood by the scheduled restarts")
+
+	solrCloud.Spec.UpdateStrategy.RestartSchedule = "@weekly"
+	assert.Equal(t, "@weekly", PodCertificateRestartSchedule(solrCloud), "A restartSchedule of the SolrCloud should be used instead")
+}
+
+func TestPerPodCertificatesOnStatefulSet(t *testing.T) {
+	solrCloud := podCertificateTestSolrCloud()
+	solrCloudStatus := &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, TLSCertsForSolrCloud(solrCloud))
+	podSpec := statefulSet.Spec.Template.Spec
+
+	envVars := map[string]string{}
+	for _, envVar := range podSpec.Containers[0].Env {
+		envVars[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "/var/solr/tls/keystore.p12", envVars["SOLR_SSL_KEY_STORE"])
+	assert.Equal(t, "/var/solr/tls/truststore.p12", envVars["SOLR_SSL_TRUST_STORE"], "The truststore generated from the CA of the issuer should be used")
+	assert.Equal(t, "true", envVars["SOLR_SSL_NEED_CLIENT_AUTH"])
+
+	var podCertificateVolume *corev1.Volume
+	for i, volume := range podSpec.Volumes {
+		assert.Nil(t, volume.Secret, "No Secret should be mounted for the per-pod certificates")
+		if volume.CSI != nil && volume.CSI.Driver == CertManagerCSIDriver {
+			podCertificateVolume = &podSpec.Volumes[i]
+		}
+	}
+	if assert.NotNil(t, podCertificateVolume, "The certificate of each pod should be issued by the cert-manager CSI driver") {
+		assert.Equal(t, "8983", podCertificateVolume.CSI.VolumeAttributes["csi.cert-manager.io/fs-group"], "The issued files should be readable by the Solr user")
+		for _, mount := range podSpec.Containers[0].VolumeMounts {
+			assert.NotEqual(t, podCertificateVolume.Name, mount.Name, "The certificate should only be mounted into the initContainer that generates the keystore")
+		}
+	}
+
+	var genKeystore *corev1.Container
+	for i, container := range podSpec.InitContainers {
+		if container.Name == "gen-pod-keystore" {
+			genKeystore = &podSpec.InitContainers[i]
+		}
+	}
+	if assert.NotNil(t, genKeystore, "An initContainer should generate the keystore of the pod") {
+		assert.Contains(t, genKeystore.Command[2], "-inkey /var/solr/pod-certificate/tls.key -out /var/solr/tls/keystore.p12")
+		assert.Contains(t, genKeystore.Command[2], "-keystore /var/solr/tls/truststore.p12", "The truststore should be generated from the CA of the issuer")
+		assert.Equal(t, "keystore-password", genKeystore.Env[0].ValueFrom.SecretKeyRef.Name)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// ReconcileCollectionsReadOnly puts every collection of the SolrCloud into, or takes it out of, read-only mode.
+// Only the collections that are not already in the desired mode are modified.
+func ReconcileCollectionsReadOnly(cloud *solr.SolrCloud, readOnly bool, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collectionsToMakeReadOnly(clusterStatus, readOnly) {
+		logger.Info("Setting read-only mode of collection", "collection", collection, "readOnly", readOnly)
+		if err = setCollectionReadOnly(cloud, collection, readOnly, httpHeaders); err != nil {
+			logger.Error(err, "Error setting read-only mode of collection", "collection", collection, "readOnly", readOnly)
+			return err
+		}
+	}
+	return nil
+}
+
+// collectionsToMakeReadOnly returns the collections, in order, whose read-only mode does not match the given mode
+func collectionsToMakeReadOnly(clusterStatus solr_api.SolrClusterStatus, readOnly bool) (collections []string) {
+	for name, collection := range clusterStatus.Collections {
+		// An unset or unparsable property means that the collection is read-write
+		isReadOnly, _ := strconv.ParseBool(collection.ReadOnly)
+		if isReadOnly != readOnly {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+func setCollectionReadOnly(cloud *solr.SolrCloud, collection string, readOnly bool, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "MODIFYCOLLECTION")
+	queryParams.Add("collection", collection)
+	queryParams.Add("readOnly", strconv.FormatBool(readOnly))
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("MODIFYCOLLECTION", resp.ResponseHeader)
+	}
+	return err
+}
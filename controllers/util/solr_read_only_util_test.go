@@ -0,0 +1,37 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectionsToMakeReadOnly(t *testing.T) {
+	clusterStatus := solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col-b": {ReadOnly: "true"},
+			"col-a": {},
+			"col-c": {ReadOnly: "false"},
+		},
+	}
+	assert.Equal(t, []string{"col-a", "col-c"}, collectionsToMakeReadOnly(clusterStatus, true), "Only the read-write collections should be made read-only")
+	assert.Equal(t, []string{"col-b"}, collectionsToMakeReadOnly(clusterStatus, false), "Only the read-only collections should be made read-write")
+	assert.Empty(t, collectionsToMakeReadOnly(solr_api.SolrClusterStatus{}, true), "No collections should be modified when there are none")
+}
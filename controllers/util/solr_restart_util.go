@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SolrRestartReasonAnnotation is set on the Solr pod template, and therefore on each Solr pod, with the reasons for the last restart of the Solr pods
+	SolrRestartReasonAnnotation = "solr.apache.org/restartReason"
+
+	RestartReasonSolrXml             = "SolrXmlChanged"
+	RestartReasonLogXml              = "LogXmlChanged"
+	RestartReasonConfigFile          = "ConfigFileChanged"
+	RestartReasonTLSRotation         = "TLSRotated"
+	RestartReasonCredentialsRotation = "CredentialsRotated"
+	RestartReasonZookeeper           = "ZookeeperConnectionChanged"
+	RestartReasonImage               = "ImageChanged"
+	RestartReasonScheduled           = "ScheduledRestart"
+	RestartReasonUserRequested       = "UserRequested"
+	RestartReasonPodSpec             = "PodSpecChanged"
+)
+
+// restartReasonsByAnnotation maps the pod annotations that the Solr Operator manages to the restart that a change in their value stands for
+var restartReasonsByAnnotation = map[string]string{
+	SolrXmlMd5Annotation:                  RestartReasonSolrXml,
+	LogXmlMd5Annotation:                   RestartReasonLogXml,
+	SolrTlsCertMd5Annotation:              RestartReasonTLSRotation,
+	SolrClientTlsCertMd5Annotation:        RestartReasonTLSRotation,
+	SolrTlsTrustBundleMd5Annotation:       RestartReasonTLSRotation,
+	SolrClientTlsTrustBundleMd5Annotation: RestartReasonTLSRotation,
+	BasicAuthMd5Annotation:                RestartReasonCredentialsRotation,
+	BackupRepoCredentialsAnnotation:       RestartReasonCredentialsRotation,
+	SolrZKConnectionStringAnnotation:      RestartReasonZookeeper,
+	SolrScheduledRestartAnnotation:        RestartReasonScheduled,
+}
+
+// SetPodRestartReason determines why the Solr pods will be restarted when the existing pod template is replaced with the updated one,
+// and records the reasons in the SolrRestartReasonAnnotation of the updated pod template.
+// If the pod template has not changed, the existing annotation is kept, so that the pods are not restarted because of it, and no reasons are returned.
+//
+// Changes to the annotations that the Solr Operator does not manage, such as "kubectl.kubernetes.io/restartedAt" in the custom pod annotations,
+// are requested by the user. Changes that are not otherwise explained are reported as changes to the pod spec.
+func SetPodRestartReason(existing, updated *corev1.PodTemplateSpec) (reasons []string) {
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string, 1)
+	}
+	PreserveLegacyContentHashes(updated.Annotations, existing.Annotations)
+	if lastReason, hasLastReason := existing.Annotations[SolrRestartReasonAnnotation]; hasLastReason {
+		updated.Annotations[SolrRestartReasonAnnotation] = lastReason
+	} else {
+		delete(updated.Annotations, SolrRestartReasonAnnotation)
+	}
+
+	// Any difference that the Solr Operator would apply to the pod template restarts the pods
+	if !CopyPodTemplates(updated, existing.DeepCopy(), "", logr.Discard()) {
+		return nil
+	}
+
+	reasonSet := map[string]bool{}
+	for key := range mergeKeys(existing.Annotations, updated.Annotations) {
+		if key == SolrRestartReasonAnnotation || existing.Annotations[key] == updated.Annotations[key] {
+			continue
+		}
+		if reason, isManaged := restartReasonsByAnnotation[key]; isManaged {
+			reasonSet[reason] = true
+		} else if strings.HasPrefix(key, ConfigMapFileMd5AnnotationPrefix) {
+			reasonSet[RestartReasonConfigFile] = true
+		} else {
+			reasonSet[RestartReasonUserRequested] = true
+		}
+	}
+
+	// Find out whether anything other than the annotations and images changed
+	remaining := existing.DeepCopy()
+	remaining.Annotations = updated.Annotations
+	if copyContainerImages(updated.Spec.Containers, remaining.Spec.Containers) || copyContainerImages(updated.Spec.InitContainers, remaining.Spec.InitContainers) {
+		reasonSet[RestartReasonImage] = true
+	}
+	if CopyPodTemplates(updated, remaining, "", logr.Discard()) {
+		reasonSet[RestartReasonPodSpec] = true
+	}
+
+	for reason := range reasonSet {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	updated.Annotations[SolrRestartReasonAnnotation] = strings.Join(reasons, ",")
+	return reasons
+}
+
+// copyContainerImages copies the images of the given containers onto the containers with the same names, and returns whether any image changed
+func copyContainerImages(from []corev1.Container, to []corev1.Container) (changed bool) {
+	for _, fromContainer := range from {
+		for i := range to {
+			if to[i].Name == fromContainer.Name && to[i].Image != fromContainer.Image {
+				to[i].Image = fromContainer.Image
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func mergeKeys(maps ...map[string]string) map[string]bool {
+	keys := map[string]bool{}
+	for _, m := range maps {
+		for key := range m {
+			keys[key] = true
+		}
+	}
+	return keys
+}
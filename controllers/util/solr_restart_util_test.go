@@ -0,0 +1,69 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func restartTestPodTemplate() *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				SolrXmlMd5Annotation:        "solr-xml-1",
+				SolrTlsCertMd5Annotation:    "cert-1",
+				SolrRestartReasonAnnotation: RestartReasonSolrXml,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "solrcloud-node", Image: "solr:8.10"}},
+		},
+	}
+}
+
+func TestSetPodRestartReasonWithoutChanges(t *testing.T) {
+	existing := restartTestPodTemplate()
+	updated := restartTestPodTemplate()
+	delete(updated.Annotations, SolrRestartReasonAnnotation)
+
+	assert.Empty(t, SetPodRestartReason(existing, updated), "The pods are not restarted if the pod template has not changed")
+	assert.Equal(t, RestartReasonSolrXml, updated.Annotations[SolrRestartReasonAnnotation], "The reason of the last restart should be kept, so that it does not restart the pods")
+}
+
+func TestSetPodRestartReason(t *testing.T) {
+	existing := restartTestPodTemplate()
+	updated := restartTestPodTemplate()
+	updated.Annotations[SolrTlsCertMd5Annotation] = "cert-2"
+	assert.Equal(t, []string{RestartReasonTLSRotation}, SetPodRestartReason(existing, updated), "A new TLS cert should be reported as a rotation")
+	assert.Equal(t, RestartReasonTLSRotation, updated.Annotations[SolrRestartReasonAnnotation])
+
+	updated = restartTestPodTemplate()
+	updated.Spec.Containers[0].Image = "solr:8.11"
+	updated.Annotations[ConfigMapFileMd5AnnotationPrefix+"synonyms"] = "synonyms-1"
+	updated.Annotations["kubectl.kubernetes.io/restartedAt"] = "2021-09-17T03:00:00Z"
+	assert.Equal(t, []string{RestartReasonConfigFile, RestartReasonImage, RestartReasonUserRequested}, SetPodRestartReason(existing, updated), "Every reason for the restart should be reported")
+	assert.Equal(t, "ConfigFileChanged,ImageChanged,UserRequested", updated.Annotations[SolrRestartReasonAnnotation])
+
+	updated = restartTestPodTemplate()
+	updated.Spec.Containers[0].Args = []string{"-verbose"}
+	assert.Equal(t, []string{RestartReasonPodSpec}, SetPodRestartReason(existing, updated), "Other changes should be reported as pod spec changes")
+}
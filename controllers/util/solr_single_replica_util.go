@@ -0,0 +1,264 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SingleReplicaShard is a shard that has only a single replica
+type SingleReplicaShard struct {
+	Collection string
+	Shard      string
+}
+
+func (shard SingleReplicaShard) String() string {
+	return shard.Collection + "/" + shard.Shard
+}
+
+// SingleReplicaUpdateState keeps track of the pods, chosen for or held back from an update, that host the only replica of shards
+type SingleReplicaUpdateState struct {
+	// Pods that are updated even though they host the only replica of shards, mapped to those shards
+	UpdatedPods map[string][]SingleReplicaShard
+
+	// Pods that are held back from the update because they host the only replica of shards, mapped to those shards
+	BlockedPods map[string][]SingleReplicaShard
+}
+
+func NewSingleReplicaUpdateState() *SingleReplicaUpdateState {
+	return &SingleReplicaUpdateState{
+		UpdatedPods: map[string][]SingleReplicaShard{},
+		BlockedPods: map[string][]SingleReplicaShard{},
+	}
+}
+
+func (state *SingleReplicaUpdateState) addUpdatedPod(podName string, shards []SingleReplicaShard) {
+	if state != nil {
+		state.UpdatedPods[podName] = shards
+	}
+}
+
+func (state *SingleReplicaUpdateState) addBlockedPod(podName string, shards []SingleReplicaShard) {
+	if state != nil {
+		state.BlockedPods[podName] = shards
+	}
+}
+
+// singleReplicaShardsFromNode returns the shards, sorted, whose only replica is an active replica on the Solr node.
+// The unique shard names are those used by SolrNodeContents, "collection|shard".
+func singleReplicaShardsFromNode(nodeContent *SolrNodeContents, totalShardReplicas map[string]int) (shards []SingleReplicaShard) {
+	for uniqueShard, activeReplicas := range nodeContent.activeReplicasPerShard {
+		if activeReplicas > 0 && totalShardReplicas[uniqueShard] == 1 {
+			collection, shard := splitUniqueShard(uniqueShard)
+			shards = append(shards, SingleReplicaShard{Collection: collection, Shard: shard})
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].String() < shards[j].String()
+	})
+	return shards
+}
+
+func splitUniqueShard(uniqueShard string) (collection string, shard string) {
+	separator := strings.LastIndex(uniqueShard, "|")
+	return uniqueShard[:separator], uniqueShard[separator+1:]
+}
+
+// AddTemporaryReplicas adds a replica, on another live Solr node, to each of the single-replica shards hosted by the pod.
+// Nodes that are not in the given set of out-of-date Solr nodes are preferred, so that the temporary replicas do not need to be restarted as well.
+// Shards that already have a temporary replica are skipped, the new temporary replicas are returned.
+func AddTemporaryReplicas(cloud *solr.SolrCloud, podName string, shards []SingleReplicaShard, outOfDateNodes map[string]bool, existing []solr.TemporaryReplicaStatus, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (added []solr.TemporaryReplicaStatus, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return nil, err
+	}
+	overseerLeader, _ := clusterState.OverseerLeader()
+	nodeContents, _, _ := findSolrNodeContents(clusterStatus, overseerLeader)
+
+	for _, shard := range shards {
+		alreadyAdded := false
+		for _, temporaryReplica := range existing {
+			if temporaryReplica.Collection == shard.Collection && temporaryReplica.Shard == shard.Shard {
+				alreadyAdded = true
+				break
+			}
+		}
+		if alreadyAdded {
+			continue
+		}
+		replica, hasReplica := onlyReplicaOfShard(clusterStatus, shard)
+		if !hasReplica {
+			continue
+		}
+		node := chooseTemporaryReplicaNode(clusterStatus.LiveNodes, nodeContents, replica.NodeName, outOfDateNodes)
+		if node == "" {
+			logger.Info("No other live Solr node to add a temporary replica to", "collection", shard.Collection, "shard", shard.Shard, "pod", podName)
+			continue
+		}
+		logger.Info("Adding temporary replica to shard with a single replica", "collection", shard.Collection, "shard", shard.Shard, "node", node, "pod", podName)
+		if err = addReplica(cloud, shard, node, replica.Type, httpHeaders); err != nil {
+			return added, err
+		}
+		added = append(added, solr.TemporaryReplicaStatus{
+			Collection: shard.Collection,
+			Shard:      shard.Shard,
+			Node:       node,
+			Pod:        podName,
+		})
+	}
+	return added, nil
+}
+
+// onlyReplicaOfShard returns the replica of the shard, if the shard still exists and has a single replica
+func onlyReplicaOfShard(clusterStatus solr_api.SolrClusterStatus, shard SingleReplicaShard) (replica solr_api.SolrReplicaStatus, found bool) {
+	collection, hasCollection := clusterStatus.Collections[shard.Collection]
+	if !hasCollection {
+		return replica, false
+	}
+	shardStatus, hasShard := collection.Shards[shard.Shard]
+	if !hasShard || len(shardStatus.Replicas) != 1 {
+		return replica, false
+	}
+	for _, replica = range shardStatus.Replicas {
+		found = true
+	}
+	return replica, found
+}
+
+// chooseTemporaryReplicaNode picks the live Solr node, other than the given one, with the fewest replicas.
+// Nodes that are up to date are preferred over out-of-date nodes, ties are broken by the name of the node.
+func chooseTemporaryReplicaNode(liveNodes []string, nodeContents map[string]*SolrNodeContents, excludeNode string, outOfDateNodes map[string]bool) (node string) {
+	candidates := make([]string, 0, len(liveNodes))
+	for _, liveNode := range liveNodes {
+		if liveNode != excludeNode {
+			candidates = append(candidates, liveNode)
+		}
+	}
+	replicas := func(node string) int {
+		if contents, hasContents := nodeContents[node]; hasContents {
+			return contents.replicas
+		}
+		return 0
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if outOfDateNodes[candidates[i]] != outOfDateNodes[candidates[j]] {
+			return !outOfDateNodes[candidates[i]]
+		}
+		if replicas(candidates[i]) != replicas(candidates[j]) {
+			return replicas(candidates[i]) < replicas(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
+
+// RemoveTemporaryReplicas deletes the temporary replicas whose pods are up to date, once the other replicas of their shards are active again.
+// The temporary replicas that must be kept are returned.
+// Temporary replicas that no longer exist, because their shard or collection was deleted, are forgotten.
+func RemoveTemporaryReplicas(cloud *solr.SolrCloud, temporaryReplicas []solr.TemporaryReplicaStatus, outOfDatePods map[string]bool, clusterState *SolrClusterState, httpHeaders map[string]string, logger logr.Logger) (remaining []solr.TemporaryReplicaStatus, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return temporaryReplicas, err
+	}
+	liveNodes := make(map[string]bool, len(clusterStatus.LiveNodes))
+	for _, node := range clusterStatus.LiveNodes {
+		liveNodes[node] = true
+	}
+
+	for i, temporaryReplica := range temporaryReplicas {
+		replicaName, otherReplicasActive := findTemporaryReplica(clusterStatus, temporaryReplica, liveNodes)
+		if replicaName == "" {
+			logger.Info("Temporary replica no longer exists", "collection", temporaryReplica.Collection, "shard", temporaryReplica.Shard, "node", temporaryReplica.Node)
+			continue
+		}
+		if outOfDatePods[temporaryReplica.Pod] || !otherReplicasActive {
+			remaining = append(remaining, temporaryReplica)
+			continue
+		}
+		logger.Info("Deleting temporary replica, since the pod hosting the shard has been updated", "collection", temporaryReplica.Collection, "shard", temporaryReplica.Shard, "replica", replicaName, "pod", temporaryReplica.Pod)
+		if err = deleteReplica(cloud, temporaryReplica.Collection, temporaryReplica.Shard, replicaName, httpHeaders); err != nil {
+			return append(remaining, temporaryReplicas[i:]...), err
+		}
+	}
+	return remaining, nil
+}
+
+// findTemporaryReplica returns the name of the temporary replica in the cluster state, if it still exists,
+// and whether all other replicas of its shard are active on live nodes.
+func findTemporaryReplica(clusterStatus solr_api.SolrClusterStatus, temporaryReplica solr.TemporaryReplicaStatus, liveNodes map[string]bool) (replicaName string, otherReplicasActive bool) {
+	collection, hasCollection := clusterStatus.Collections[temporaryReplica.Collection]
+	if !hasCollection {
+		return "", false
+	}
+	shard, hasShard := collection.Shards[temporaryReplica.Shard]
+	if !hasShard {
+		return "", false
+	}
+	otherReplicas := 0
+	otherReplicasActive = true
+	for name, replica := range shard.Replicas {
+		if replica.NodeName == temporaryReplica.Node && replicaName == "" {
+			replicaName = name
+			continue
+		}
+		otherReplicas += 1
+		if replica.State != solr_api.ReplicaActive || !liveNodes[replica.NodeName] {
+			otherReplicasActive = false
+		}
+	}
+	return replicaName, otherReplicasActive && otherReplicas > 0
+}
+
+func addReplica(cloud *solr.SolrCloud, shard SingleReplicaShard, node string, replicaType solr_api.SolrReplicaType, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "ADDREPLICA")
+	queryParams.Add("collection", shard.Collection)
+	queryParams.Add("shard", shard.Shard)
+	queryParams.Add("node", node)
+	if replicaType != "" {
+		queryParams.Add("type", string(replicaType))
+	}
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("ADDREPLICA", resp.ResponseHeader)
+	}
+	return err
+}
+
+func deleteReplica(cloud *solr.SolrCloud, collection string, shard string, replica string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETEREPLICA")
+	queryParams.Add("collection", collection)
+	queryParams.Add("shard", shard)
+	queryParams.Add("replica", replica)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETEREPLICA", resp.ResponseHeader)
+	}
+	return err
+}
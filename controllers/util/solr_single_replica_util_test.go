@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+)
+
+var testSingleReplicaClusterStatus = solr_api.SolrClusterStatus{
+	LiveNodes: []string{
+		"pod-0.foo-solrcloud-headless.default:2000_solr",
+		"pod-1.foo-solrcloud-headless.default:2000_solr",
+		"pod-2.foo-solrcloud-headless.default:2000_solr",
+	},
+	Collections: map[string]solr_api.SolrCollectionStatus{
+		"logs": {
+			Shards: map[string]solr_api.SolrShardStatus{
+				"shard1": {
+					Replicas: map[string]solr_api.SolrReplicaStatus{
+						"core_node1": {State: solr_api.ReplicaActive, NodeName: "pod-1.foo-solrcloud-headless.default:2000_solr", Leader: true, Type: solr_api.NRT},
+					},
+				},
+				"shard2": {
+					Replicas: map[string]solr_api.SolrReplicaStatus{
+						"core_node2": {State: solr_api.ReplicaActive, NodeName: "pod-1.foo-solrcloud-headless.default:2000_solr", Leader: true, Type: solr_api.NRT},
+					},
+				},
+			},
+		},
+		"products": {
+			Shards: map[string]solr_api.SolrShardStatus{
+				"shard1": {
+					Replicas: map[string]solr_api.SolrReplicaStatus{
+						"core_node3": {State: solr_api.ReplicaActive, NodeName: "pod-0.foo-solrcloud-headless.default:2000_solr", Leader: true, Type: solr_api.NRT},
+						"core_node4": {State: solr_api.ReplicaActive, NodeName: "pod-2.foo-solrcloud-headless.default:2000_solr", Leader: false, Type: solr_api.NRT},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestPickPodsToUpdateWithSingleReplicaShards(t *testing.T) {
+	log := ctrl.Log
+
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort: 2000,
+			},
+			UpdateStrategy: solr.SolrUpdateStrategy{
+				Method: solr.ManagedUpdate,
+				ManagedUpdateOptions: solr.ManagedUpdateOptions{
+					SingleReplicaShards: solr.WarnSingleReplicaShards,
+				},
+			},
+		},
+	}
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}, Spec: corev1.PodSpec{}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}, Spec: corev1.PodSpec{}},
+	}
+	logsShards := []SingleReplicaShard{{Collection: "logs", Shard: "shard1"}, {Collection: "logs", Shard: "shard2"}}
+
+	state := NewSingleReplicaUpdateState()
+	podsToUpdate := getPodNames(pickPodsToUpdate(solrCloud, pods, testSingleReplicaClusterStatus, "", 3, 3, nil, state, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2"}, podsToUpdate, "Pods with single-replica shards should be updated when the policy is Warn")
+	assert.Equal(t, map[string][]SingleReplicaShard{"pod-1": logsShards}, state.UpdatedPods, "The updated pod with single-replica shards should be recorded")
+	assert.Empty(t, state.BlockedPods, "No pods should be blocked when the policy is Warn")
+
+	solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.SingleReplicaShards = solr.BlockSingleReplicaShards
+	state = NewSingleReplicaUpdateState()
+	podsToUpdate = getPodNames(pickPodsToUpdate(solrCloud, pods, testSingleReplicaClusterStatus, "", 3, 3, nil, state, log))
+	assert.ElementsMatch(t, []string{"pod-2"}, podsToUpdate, "Pods with single-replica shards should not be updated when the policy is Block")
+	assert.Equal(t, map[string][]SingleReplicaShard{"pod-1": logsShards}, state.BlockedPods, "The blocked pod with single-replica shards should be recorded")
+	assert.Empty(t, state.UpdatedPods, "No pods with single-replica shards should be updated when the policy is Block")
+
+	solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.SingleReplicaShards = solr.AddReplicaForSingleReplicaShards
+	state = NewSingleReplicaUpdateState()
+	podsToUpdate = getPodNames(pickPodsToUpdate(solrCloud, pods, testSingleReplicaClusterStatus, "", 3, 3, nil, state, log))
+	assert.ElementsMatch(t, []string{"pod-2"}, podsToUpdate, "Pods with single-replica shards should wait for temporary replicas when the policy is AddReplica")
+	assert.Equal(t, map[string][]SingleReplicaShard{"pod-1": logsShards}, state.BlockedPods, "The pod waiting for temporary replicas should be recorded")
+
+	// Without a state to record the pods in, the policy must still be applied
+	podsToUpdate = getPodNames(pickPodsToUpdate(solrCloud, pods, testSingleReplicaClusterStatus, "", 3, 3, nil, nil, log))
+	assert.ElementsMatch(t, []string{"pod-2"}, podsToUpdate, "The policy should be applied without a SingleReplicaUpdateState")
+}
+
+func TestChooseTemporaryReplicaNode(t *testing.T) {
+	nodeContents, _, _ := findSolrNodeContents(testSingleReplicaClusterStatus, "")
+	liveNodes := testSingleReplicaClusterStatus.LiveNodes
+
+	assert.Equal(t, "pod-0.foo-solrcloud-headless.default:2000_solr", chooseTemporaryReplicaNode(liveNodes, nodeContents, "pod-1.foo-solrcloud-headless.default:2000_solr", nil),
+		"The node with the fewest replicas, then the lowest name, should be chosen")
+	assert.Equal(t, "pod-2.foo-solrcloud-headless.default:2000_solr", chooseTemporaryReplicaNode(liveNodes, nodeContents, "pod-1.foo-solrcloud-headless.default:2000_solr", map[string]bool{"pod-0.foo-solrcloud-headless.default:2000_solr": true}),
+		"Up to date nodes should be preferred over out of date nodes")
+	assert.Equal(t, "", chooseTemporaryReplicaNode([]string{"pod-1.foo-solrcloud-headless.default:2000_solr"}, nodeContents, "pod-1.foo-solrcloud-headless.default:2000_solr", nil),
+		"No node should be chosen if the excluded node is the only live node")
+}
+
+func TestFindTemporaryReplica(t *testing.T) {
+	liveNodes := map[string]bool{
+		"pod-0.foo-solrcloud-headless.default:2000_solr": true,
+		"pod-2.foo-solrcloud-headless.default:2000_solr": true,
+	}
+	temporaryReplica := solr.TemporaryReplicaStatus{Collection: "products", Shard: "shard1", Node: "pod-2.foo-solrcloud-headless.default:2000_solr", Pod: "pod-0"}
+
+	replicaName, otherReplicasActive := findTemporaryReplica(testSingleReplicaClusterStatus, temporaryReplica, liveNodes)
+	assert.Equal(t, "core_node4", replicaName, "Wrong temporary replica found")
+	assert.True(t, otherReplicasActive, "The other replica of the shard is active on a live node")
+
+	delete(liveNodes, "pod-0.foo-solrcloud-headless.default:2000_solr")
+	_, otherReplicasActive = findTemporaryReplica(testSingleReplicaClusterStatus, temporaryReplica, liveNodes)
+	assert.False(t, otherReplicasActive, "The other replica of the shard is not on a live node")
+
+	temporaryReplica.Collection = "deleted"
+	replicaName, _ = findTemporaryReplica(testSingleReplicaClusterStatus, temporaryReplica, liveNodes)
+	assert.Empty(t, replicaName, "The temporary replica of a deleted collection should not be found")
+}
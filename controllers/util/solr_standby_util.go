@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	cron "github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateStandbyOptions checks that the backup repository and schedule of a standby SolrCloud are usable
+func ValidateStandbyOptions(cloud *solr.SolrCloud) error {
+	standby := cloud.Spec.StandbyOf
+	if standby == nil {
+		return nil
+	}
+	if GetBackupRepositoryByName(cloud.Spec.BackupRepositories, standby.RepositoryName) == nil {
+		return TerminalErrorf(InvalidSpecReason, "'standbyOf.repositoryName' must name one of the backupRepositories (or the SolrCloud must have only 1 repository defined) to restore the backups of SolrCloud %s from", standby.SolrCloud)
+	}
+	if _, err := cron.ParseStandard(standby.Schedule); err != nil {
+		return TerminalErrorf(InvalidSpecReason, "invalid 'standbyOf.schedule' %q: %s", standby.Schedule, err)
+	}
+	return nil
+}
+
+// NextStandbyRestoreTime returns when the next restore of a standby SolrCloud should start, after the given time
+func NextStandbyRestoreTime(standby *solr.SolrStandbyOptions, after time.Time) (next time.Time, err error) {
+	schedule, err := cron.ParseStandard(standby.Schedule)
+	if err != nil {
+		return next, TerminalErrorf(InvalidSpecReason, "invalid 'standbyOf.schedule' %q: %s", standby.Schedule, err)
+	}
+	return schedule.Next(after), nil
+}
+
+// StandbyCollectionName returns the name of the collection that a generation of a standby collection is restored into.
+// The alias named after the collection points to the latest generation that was restored successfully.
+func StandbyCollectionName(collection string, generation int32) string {
+	return fmt.Sprintf("%s-standby-%d", collection, generation)
+}
+
+// StandbyRestoreName returns the name of the SolrRestore that restores a generation of the collections of a standby SolrCloud
+func StandbyRestoreName(cloud *solr.SolrCloud, generation int32) string {
+	return fmt.Sprintf("%s-standby-%d", cloud.Name, generation)
+}
+
+// GenerateStandbyRestore returns the SolrRestore that restores the latest backup of the primary's collections into a new generation of standby collections
+func GenerateStandbyRestore(cloud *solr.SolrCloud, generation int32) *solr.SolrRestore {
+	standby := cloud.Spec.StandbyOf
+	restore := &solr.SolrRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      StandbyRestoreName(cloud, generation),
+			Namespace: cloud.Namespace,
+			Labels:    cloud.SharedLabelsWith(cloud.PropagatedLabels()),
+		},
+		Spec: solr.SolrRestoreSpec{
+			SolrCloud:      cloud.Name,
+			RepositoryName: GetBackupRepositoryByName(cloud.Spec.BackupRepositories, standby.RepositoryName).Name,
+			BackupName:     standby.BackupName,
+		},
+	}
+	for _, collection := range standby.Collections {
+		restore.Spec.Collections = append(restore.Spec.Collections, solr.SolrRestoreCollection{
+			Name:   collection,
+			Target: StandbyCollectionName(collection, generation),
+		})
+	}
+	return restore
+}
+
+// StandbyCollectionsToDelete returns the collections of the given generation of a standby SolrCloud that exist in the cluster.
+// Collections that an alias points to are never returned, since they are in use, even if the status of the SolrCloud does not say so.
+func StandbyCollectionsToDelete(standby *solr.SolrStandbyOptions, generation int32, clusterStatus solr_api.SolrClusterStatus) (collections []string) {
+	aliased := map[string]bool{}
+	for _, targets := range clusterStatus.Aliases {
+		for _, target := range strings.Split(targets, ",") {
+			aliased[target] = true
+		}
+	}
+	for _, collection := range standby.Collections {
+		name := StandbyCollectionName(collection, generation)
+		if _, exists := clusterStatus.Collections[name]; exists && !aliased[name] {
+			collections = append(collections, name)
+		}
+	}
+	return collections
+}
+
+// StandbyGenerationInUse returns the latest generation of the standby collections that the aliases named after the collections point to, or 0 if there is none.
+// The aliases are cut over before the new generation is recorded in the status of the SolrCloud, so this may be newer than the generation in the status.
+func StandbyGenerationInUse(standby *solr.SolrStandbyOptions, clusterStatus solr_api.SolrClusterStatus) (generation int32) {
+	for _, collection := range standby.Collections {
+		prefix := collection + "-standby-"
+		for _, target := range strings.Split(clusterStatus.Aliases[collection], ",") {
+			if !strings.HasPrefix(target, prefix) {
+				continue
+			}
+			if targetGeneration, err := strconv.ParseInt(strings.TrimPrefix(target, prefix), 10, 32); err == nil && int32(targetGeneration) > generation {
+				generation = int32(targetGeneration)
+			}
+		}
+	}
+	return generation
+}
+
+// DeleteCollection deletes a collection from the SolrCloud
+func DeleteCollection(cloud *solr.SolrCloud, collection string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETE")
+	queryParams.Add("name", collection)
+
+	resp := &solr_api.SolrAsyncResponse{}
+	logger.Info("Calling to delete collection", "solrCloud", cloud.Name, "collection", collection)
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp); err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETE", resp.ResponseHeader)
+	}
+	if err != nil {
+		logger.Error(err, "Error deleting collection", "solrCloud", cloud.Name, "collection", collection)
+	}
+
+	return err
+}
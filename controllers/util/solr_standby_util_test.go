@@ -0,0 +1,108 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func standbyTestSolrCloud() *solr.SolrCloud {
+	return &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "dr", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "dr-backups", GCS: &solr.GcsRepository{Bucket: "solr-backups", BaseLocation: "/prod"}},
+			},
+			StandbyOf: &solr.SolrStandbyOptions{
+				SolrCloud:   "prod",
+				BackupName:  "nightly",
+				Collections: []string{"books", "techproducts"},
+				Schedule:    "@every 6h",
+			},
+		},
+	}
+}
+
+func TestValidateStandbyOptions(t *testing.T) {
+	solrCloud := standbyTestSolrCloud()
+	assert.NoError(t, ValidateStandbyOptions(solrCloud), "The only repository of the SolrCloud should be used by default")
+	assert.True(t, solrCloud.CollectionsReadOnly(), "The collections of a standby should be read-only")
+
+	solrCloud.Spec.StandbyOf.Schedule = "not a schedule"
+	assert.Error(t, ValidateStandbyOptions(solrCloud), "The schedule must be valid")
+
+	solrCloud.Spec.StandbyOf.Schedule = "@every 6h"
+	solrCloud.Spec.StandbyOf.RepositoryName = "missing"
+	assert.Error(t, ValidateStandbyOptions(solrCloud), "The repository must be defined by the SolrCloud")
+
+	solrCloud.Spec.StandbyOf = nil
+	assert.NoError(t, ValidateStandbyOptions(solrCloud), "A promoted SolrCloud needs no standby options")
+	assert.False(t, solrCloud.CollectionsReadOnly(), "The collections of a promoted SolrCloud should be read-write")
+}
+
+func TestGenerateStandbyRestore(t *testing.T) {
+	solrCloud := standbyTestSolrCloud()
+	restore := GenerateStandbyRestore(solrCloud, 3)
+
+	assert.Equal(t, "dr-standby-3", restore.Name)
+	assert.Equal(t, "dr", restore.Spec.SolrCloud)
+	assert.Equal(t, "dr-backups", restore.Spec.RepositoryName, "The defaulted repository should be used")
+	assert.Equal(t, "nightly", restore.Spec.BackupName)
+	assert.Nil(t, restore.Spec.BackupId, "The latest backup point should be restored")
+	assert.Equal(t, []solr.SolrRestoreCollection{
+		{Name: "books", Target: "books-standby-3"},
+		{Name: "techproducts", Target: "techproducts-standby-3"},
+	}, restore.Spec.Collections)
+
+	next, err := NextStandbyRestoreTime(solrCloud.Spec.StandbyOf, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 1, 1, 6, 0, 0, 0, time.UTC), next, "Wrong next restore time")
+}
+
+func TestStandbyCollectionsToDelete(t *testing.T) {
+	solrCloud := standbyTestSolrCloud()
+	clusterStatus := solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"books-standby-1":        {},
+			"books-standby-2":        {},
+			"techproducts-standby-2": {},
+		},
+	}
+
+	assert.Equal(t, []string{"books-standby-1"}, StandbyCollectionsToDelete(solrCloud.Spec.StandbyOf, 1, clusterStatus), "Only existing collections of the generation should be deleted")
+	assert.Equal(t, []string{"books-standby-2", "techproducts-standby-2"}, StandbyCollectionsToDelete(solrCloud.Spec.StandbyOf, 2, clusterStatus))
+	assert.Empty(t, StandbyCollectionsToDelete(solrCloud.Spec.StandbyOf, 3, clusterStatus))
+
+	clusterStatus.Aliases = map[string]string{"books": "books-standby-2"}
+	assert.Equal(t, []string{"techproducts-standby-2"}, StandbyCollectionsToDelete(solrCloud.Spec.StandbyOf, 2, clusterStatus), "Collections that an alias points to should never be deleted")
+}
+
+func TestStandbyGenerationInUse(t *testing.T) {
+	solrCloud := standbyTestSolrCloud()
+	clusterStatus := solr_api.SolrClusterStatus{}
+	assert.Equal(t, int32(0), StandbyGenerationInUse(solrCloud.Spec.StandbyOf, clusterStatus), "No generation is in use before the first restore")
+
+	clusterStatus.Aliases = map[string]string{"books": "books-standby-3", "techproducts": "techproducts-standby-4", "other": "books-standby-7"}
+	assert.Equal(t, int32(4), StandbyGenerationInUse(solrCloud.Spec.StandbyOf, clusterStatus), "The latest generation that the aliases of the collections point to should be used")
+}
@@ -19,7 +19,6 @@ package util
 
 import (
 	"context"
-	"crypto/md5"
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -43,6 +42,10 @@ const (
 	DefaultPkcs12KeystoreFile      = "keystore.p12"
 	DefaultPkcs12TruststoreFile    = "truststore.p12"
 	DefaultKeystorePasswordFile    = "keystore-password"
+	TrustBundleFile                = "ca-bundle.pem"
+
+	SolrTlsTrustBundleMd5Annotation       = "solr.apache.org/tlsTrustBundleMd5"
+	SolrClientTlsTrustBundleMd5Annotation = "solr.apache.org/tlsClientTrustBundleMd5"
 )
 
 // Helper struct for holding server and/or client cert config
@@ -67,11 +70,17 @@ type TLSConfig struct {
 	CertMd5 string
 	// The annotation varies based on the cert type (client or server)
 	CertMd5Annotation string
+	// The MD5 hash of the CA bundle from the trustBundleConfigMap, used for restarting pods after the bundle updates if so desired
+	TrustBundleMd5 string
+	// The annotation varies based on the cert type (client or server)
+	TrustBundleMd5Annotation string
 	// The paths vary based on whether this config is for a client or server cert
 	KeystorePath   string
 	TruststorePath string
 	VolumePrefix   string
 	Namespace      string
+	// The attributes of the cert-manager CSI driver volume that the certificate of each Solr pod is issued into, when each pod is issued its own certificate
+	PodCertificateAttributes map[string]string
 }
 
 // Get a TLSCerts struct for reconciling TLS on a SolrCloud
@@ -83,9 +92,14 @@ func TLSCertsForSolrCloud(instance *solr.SolrCloud) *TLSCerts {
 			TruststorePath:    DefaultTrustStorePath,
 			CertMd5Annotation: SolrTlsCertMd5Annotation,
 			Namespace:         instance.Namespace,
+
+			TrustBundleMd5Annotation: SolrTlsTrustBundleMd5Annotation,
 		},
 		InitContainerImage: instance.Spec.BusyBoxImage,
 	}
+	if instance.Spec.SolrTLS.PerPodCertificates != nil {
+		tls.ServerConfig.PodCertificateAttributes = PodCertificateAttributes(instance)
+	}
 	if instance.Spec.SolrClientTLS != nil {
 		tls.ClientConfig = &TLSConfig{
 			Options:           instance.Spec.SolrClientTLS.DeepCopy(),
@@ -94,6 +108,8 @@ func TLSCertsForSolrCloud(instance *solr.SolrCloud) *TLSCerts {
 			VolumePrefix:      "client-",
 			CertMd5Annotation: SolrClientTlsCertMd5Annotation,
 			Namespace:         instance.Namespace,
+
+			TrustBundleMd5Annotation: SolrClientTlsTrustBundleMd5Annotation,
 		}
 	}
 	return tls
@@ -117,11 +133,26 @@ func TLSCertsForExporter(prometheusExporter *solr.SolrPrometheusExporter) *TLSCe
 			TruststorePath:    DefaultTrustStorePath,
 			CertMd5Annotation: SolrClientTlsCertMd5Annotation,
 			Namespace:         prometheusExporter.Namespace,
+
+			TrustBundleMd5Annotation: SolrClientTlsTrustBundleMd5Annotation,
 		},
 		InitContainerImage: bbImage,
 	}
 }
 
+// Get a TLSCerts struct for reconciling TLS on an Indexing Bridge
+func TLSCertsForIndexingBridge(bridge *solr.SolrIndexingBridge) *TLSCerts {
+	return &TLSCerts{
+		ClientConfig: &TLSConfig{
+			Options:           bridge.Spec.SolrReference.SolrTLS.DeepCopy(),
+			KeystorePath:      DefaultKeyStorePath,
+			TruststorePath:    DefaultTrustStorePath,
+			CertMd5Annotation: SolrClientTlsCertMd5Annotation,
+			Namespace:         bridge.Namespace,
+		},
+	}
+}
+
 // Enrich the config for a SolrCloud StatefulSet to enable TLS, either loaded from a secret or
 // a directory on the main pod containing per-pod specific TLS files. In the latter case, the "mounted dir"
 // typically comes from an external agent (such as a cert-manager extension) or CSI driver that injects the
@@ -150,6 +181,9 @@ func (tls *TLSCerts) enableTLSOnSolrCloudStatefulSet(stateful *appsv1.StatefulSe
 		mountInitDbIfNeeded(stateful)
 		// use an initContainer to create the wrapper script in the initdb
 		stateful.Spec.Template.Spec.InitContainers = append(stateful.Spec.Template.Spec.InitContainers, tls.generateTLSInitdbScriptInitContainer())
+	} else if serverCert.Options.PerPodCertificates != nil {
+		// each pod is issued its own certificate, by the cert-manager CSI driver
+		serverCert.mountPodCertificateOnPodTemplate(&stateful.Spec.Template)
 	}
 }
 
@@ -165,7 +199,7 @@ func (tls *TLSCerts) enableTLSOnExporterDeployment(deployment *appsv1.Deployment
 	// the exporter process doesn't read the SOLR_SSL_* env vars, so we need to pass them via JAVA_OPTS
 	appendJavaOptsToEnv(mainContainer, clientCert.clientJavaOpts())
 
-	if clientCert.Options.PKCS12Secret != nil || clientCert.Options.TrustStoreSecret != nil {
+	if clientCert.Options.PKCS12Secret != nil || clientCert.Options.TrustStoreSecret != nil || clientCert.Options.TrustBundleConfigMap != nil {
 		// Cert comes from a secret, so setup the pod template to mount the secret
 		clientCert.mountTLSSecretOnPodTemplate(&deployment.Spec.Template)
 	} else if clientCert.Options.MountedTLSDir != nil {
@@ -174,6 +208,24 @@ func (tls *TLSCerts) enableTLSOnExporterDeployment(deployment *appsv1.Deployment
 	}
 }
 
+// Enrich the config for an Indexing Bridge Deployment to allow the bridge to make requests to TLS enabled Solr pods
+// Only TLS secrets are supported, since the bridge image is provided by the user and cannot be wrapped with a launch script
+func (tls *TLSCerts) enableTLSOnIndexingBridgeDeployment(deployment *appsv1.Deployment) {
+	clientCert := tls.ClientConfig
+
+	// Add the SOLR_SSL_* vars to the main container's environment
+	mainContainer := &deployment.Spec.Template.Spec.Containers[0]
+	mainContainer.Env = append(mainContainer.Env, clientCert.clientEnvVars()...)
+	mainContainer.Env = append(mainContainer.Env, corev1.EnvVar{Name: "SOLR_SSL_CHECK_PEER_NAME", Value: strconv.FormatBool(clientCert.Options.CheckPeerName)})
+
+	// not every bridge will read the SOLR_SSL_* env vars, so pass them via JAVA_OPTS as well
+	appendJavaOptsToEnv(mainContainer, clientCert.clientJavaOpts())
+
+	if clientCert.Options.PKCS12Secret != nil || clientCert.Options.TrustStoreSecret != nil {
+		clientCert.mountTLSSecretOnPodTemplate(&deployment.Spec.Template)
+	}
+}
+
 // Configures a pod template (either StatefulSet or Deployment) to mount the TLS files from a secret
 func (tls *TLSConfig) mountTLSSecretOnPodTemplate(template *corev1.PodTemplateSpec) *corev1.Container {
 	mainContainer := &template.Spec.Containers[0]
@@ -190,6 +242,10 @@ func (tls *TLSConfig) mountTLSSecretOnPodTemplate(template *corev1.PodTemplateSp
 		pkcs12InitContainer := tls.generatePkcs12InitContainer(mainContainer.Image, mainContainer.ImagePullPolicy, mounts)
 		template.Spec.InitContainers = append(template.Spec.InitContainers, pkcs12InitContainer)
 	}
+	// Java cannot read a PEM bundle as a truststore, so an initContainer imports the CA certs into a pkcs12 truststore (using keytool)
+	if tls.Options.TrustBundleConfigMap != nil {
+		template.Spec.InitContainers = append(template.Spec.InitContainers, tls.generateTrustBundleInitContainer(mainContainer.Image, mainContainer.ImagePullPolicy, mounts))
+	}
 	template.Spec.Volumes = append(template.Spec.Volumes, vols...)
 	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, mounts...)
 
@@ -201,9 +257,79 @@ func (tls *TLSConfig) mountTLSSecretOnPodTemplate(template *corev1.PodTemplateSp
 		template.Annotations[tls.CertMd5Annotation] = tls.CertMd5
 	}
 
+	// likewise for the CA bundle, since the truststore is only generated when the pod starts
+	if tls.Options.RestartOnTLSSecretUpdate && tls.TrustBundleMd5 != "" {
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string, 1)
+		}
+		template.Annotations[tls.TrustBundleMd5Annotation] = tls.TrustBundleMd5
+	}
+
 	return mainContainer
 }
 
+// Configures the pod template of a SolrCloud StatefulSet to use the certificate issued to each pod.
+// The certificate is issued by the cert-manager CSI driver when the pod starts, into a volume of its own pod, so no pod ever sees the key of another pod.
+// That volume is only mounted into an initContainer, which generates the keystore, and the truststore if none is provided, into an empty dir that is mounted into the main container.
+func (tls *TLSConfig) mountPodCertificateOnPodTemplate(template *corev1.PodTemplateSpec) {
+	// the truststore may still come from a secret or a CA bundle
+	mainContainer := tls.mountTLSSecretOnPodTemplate(template)
+
+	attributes := make(map[string]string, len(tls.PodCertificateAttributes)+1)
+	for key, value := range tls.PodCertificateAttributes {
+		attributes[key] = value
+	}
+	// the issued files are only readable by the owner, unless they are given to the group that the pod's volumes belong to
+	if securityContext := template.Spec.SecurityContext; securityContext != nil && securityContext.FSGroup != nil {
+		attributes[CertManagerCSIAttribute("fs-group")] = strconv.FormatInt(*securityContext.FSGroup, 10)
+	}
+
+	readOnly := true
+	podCertificateVolName := tls.volumeName("pod-certificate")
+	keystoreVolName := tls.volumeName("keystore")
+	template.Spec.Volumes = append(template.Spec.Volumes,
+		corev1.Volume{
+			Name: podCertificateVolName,
+			VolumeSource: corev1.VolumeSource{
+				CSI: &corev1.CSIVolumeSource{
+					Driver:           CertManagerCSIDriver,
+					ReadOnly:         &readOnly,
+					VolumeAttributes: attributes,
+				},
+			},
+		},
+		corev1.Volume{Name: keystoreVolName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	)
+	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, corev1.VolumeMount{Name: keystoreVolName, ReadOnly: true, MountPath: tls.KeystorePath})
+
+	cmd := tls.generateKeystoreCommand(PodCertificatePath, tls.KeystorePath)
+	if tls.Options.TrustStoreSecret == nil && tls.Options.TrustBundleConfigMap == nil {
+		// the truststore contains the CA of the issuer, which the CSI driver writes next to the certificate
+		cmd += fmt.Sprintf(" && keytool -importcert -noprompt -storetype %s -keystore %s/%s -storepass \"${SOLR_SSL_KEY_STORE_PASSWORD}\" -alias ca -file %s/ca.crt",
+			tls.Options.StoreType(), tls.KeystorePath, DefaultPkcs12TruststoreFile, PodCertificatePath)
+	}
+
+	// the main container's image is used, so that the generated files are owned by the same user as the Solr process
+	template.Spec.InitContainers = append(template.Spec.InitContainers, corev1.Container{
+		Name:                     "gen-pod-keystore",
+		Image:                    mainContainer.Image,
+		ImagePullPolicy:          mainContainer.ImagePullPolicy,
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
+		Command:                  []string{"sh", "-c", cmd},
+		Env: []corev1.EnvVar{
+			{
+				Name:      "SOLR_SSL_KEY_STORE_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: tls.Options.KeyStorePasswordSecret},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: podCertificateVolName, ReadOnly: true, MountPath: PodCertificatePath},
+			{Name: keystoreVolName, MountPath: tls.KeystorePath},
+		},
+	})
+}
+
 // Make sure the secret containing the keystore and corresponding password secret exist and have the expected keys
 // Also, set up to watch for updates if desired
 // Also, verifies the configured truststore if provided
@@ -227,7 +353,11 @@ func (tls *TLSConfig) VerifyKeystoreAndTruststoreSecretConfig(client *client.Cli
 	}
 
 	// verify the truststore config is valid too
-	if opts.TrustStoreSecret != nil {
+	if opts.TrustBundleConfigMap != nil {
+		if err = tls.VerifyTrustBundleConfig(client); err != nil {
+			return nil, err
+		}
+	} else if opts.TrustStoreSecret != nil {
 		// verify the TrustStore secret is configured correctly
 		passwordSecret := opts.TrustStorePasswordSecret
 		if passwordSecret == nil {
@@ -262,28 +392,66 @@ func (tls *TLSConfig) VerifyTruststoreOnly(client *client.Client) error {
 
 	// make sure truststore.p12 is actually in the supplied secret
 	if _, ok := truststoreSecret.Data[secret.Key]; !ok {
-		return fmt.Errorf("%s key not found in truststore password secret %s", secret.Key, secret.Name)
+		return TerminalErrorf(InvalidTLSConfigReason, "%s key not found in truststore password secret %s", secret.Key, secret.Name)
 	}
 
 	// If we have a watch on secrets, then get notified when the secret changes (such as after cert renewal)
 	// capture the hash of the truststore and stash in an annotation so that pods get restarted if the cert changes
 	// If watch = false, then we may be watching the keystore instead
 	if tls.Options.RestartOnTLSSecretUpdate {
-		tls.CertMd5 = fmt.Sprintf("%x", md5.Sum(truststoreSecret.Data[secret.Key]))
+		tls.CertMd5 = ContentHash(truststoreSecret, secret.Key, func() []byte { return truststoreSecret.Data[secret.Key] })
 	}
 
 	return nil
 }
 
+// Make sure the ConfigMap containing the CA bundle, and the password secret for the generated truststore, exist and have the expected keys
+func (tls *TLSConfig) VerifyTrustBundleConfig(client *client.Client) error {
+	opts := tls.Options
+	if opts.TrustStoreSecret != nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, either supply 'trustStoreSecret' or 'trustBundleConfigMap' but not both")
+	}
+	if opts.MountedTLSDir != nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, the 'trustBundleConfigMap' option cannot be used with 'mountedTLSDir'")
+	}
+	passwordSecret := opts.TrustStorePasswordSecret
+	if passwordSecret == nil {
+		passwordSecret = opts.KeyStorePasswordSecret
+	}
+	if passwordSecret == nil {
+		return TerminalErrorf(InvalidTLSConfigReason, "invalid TLS config, the 'trustStorePasswordSecret' option is required when using 'trustBundleConfigMap' without a keystore")
+	}
+
+	bundle := opts.TrustBundleConfigMap
+	foundConfigMap := &corev1.ConfigMap{}
+	if err := (*client).Get(context.TODO(), types.NamespacedName{Name: bundle.Name, Namespace: tls.Namespace}, foundConfigMap); err != nil {
+		return err
+	}
+	caBundle, ok := foundConfigMap.Data[bundle.Key]
+	if !ok {
+		return TerminalErrorf(InvalidTLSConfigReason, "%s key not found in trust bundle ConfigMap %s", bundle.Key, bundle.Name)
+	}
+	// Verify the password secret for the generated truststore
+	if _, err := verifyTLSSecretConfig(client, passwordSecret.Name, tls.Namespace, passwordSecret); err != nil {
+		return err
+	}
+
+	// capture the hash of the bundle so that pods get restarted, and the truststore regenerated, if the bundle changes
+	if opts.RestartOnTLSSecretUpdate {
+		tls.TrustBundleMd5 = ContentHash(foundConfigMap, bundle.Key, func() []byte { return []byte(caBundle) })
+	}
+	return nil
+}
+
 func (tls *TLSConfig) saveCertMd5(tlsSecret *corev1.Secret) error {
 	// We have a watch on secrets, so will get notified when the secret changes (such as after cert renewal)
 	// capture the hash of the secret and stash in an annotation so that pods get restarted if the cert changes
 	if tlsCertBytes, ok := tlsSecret.Data[TLSCertKey]; ok {
-		tls.CertMd5 = fmt.Sprintf("%x", md5.Sum(tlsCertBytes))
+		tls.CertMd5 = ContentHash(tlsSecret, TLSCertKey, func() []byte { return tlsCertBytes })
 		return nil
 	}
 
-	return fmt.Errorf("%s key not found in TLS secret %s, cannot watch for updates to the cert without this data but 'restartOnTLSSecretUpdate' is enabled", TLSCertKey, tlsSecret.Name)
+	return TerminalErrorf(InvalidTLSConfigReason, "%s key not found in TLS secret %s, cannot watch for updates to the cert without this data but 'restartOnTLSSecretUpdate' is enabled", TLSCertKey, tlsSecret.Name)
 }
 
 func (tls *TLSConfig) volumeName(baseName string) string {
@@ -331,9 +499,34 @@ func (tls *TLSConfig) volumesAndMounts() ([]corev1.Volume, []corev1.VolumeMount)
 		mounts = append(mounts, corev1.VolumeMount{Name: volName, ReadOnly: true, MountPath: tls.TruststorePath})
 	}
 
+	// the CA bundle is mounted from the ConfigMap, and the truststore generated from it is written to an empty dir
+	if opts.TrustBundleConfigMap != nil {
+		bundleVolName := tls.volumeName("trust-bundle")
+		vols = append(vols, corev1.Volume{
+			Name: bundleVolName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: opts.TrustBundleConfigMap.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: opts.TrustBundleConfigMap.Key, Path: TrustBundleFile}},
+					Optional:             &optional,
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: bundleVolName, ReadOnly: true, MountPath: tls.trustBundlePath()})
+
+		volName := tls.volumeName("truststore")
+		vols = append(vols, corev1.Volume{Name: volName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+		mounts = append(mounts, corev1.VolumeMount{Name: volName, ReadOnly: false, MountPath: tls.TruststorePath})
+	}
+
 	return vols, mounts
 }
 
+// The CA bundle is mounted next to the truststore that is generated from it
+func (tls *TLSConfig) trustBundlePath() string {
+	return tls.TruststorePath + "-bundle"
+}
+
 // Get the SOLR_SSL_* env vars for enabling TLS on Solr pods
 func (tls *TLSConfig) serverEnvVars() []corev1.EnvVar {
 	opts := tls.Options
@@ -383,6 +576,12 @@ func (tls *TLSConfig) serverEnvVars() []corev1.EnvVar {
 		envVars = append(envVars, tls.truststoreEnvVars("SOLR_SSL_TRUST_STORE")...)
 	}
 
+	// bin/solr assumes PKCS12 stores, so the store types only need to be passed for the other types
+	if storeType := opts.StoreType(); storeType != solr.PKCS12KeyStore {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_SSL_KEY_STORE_TYPE", Value: string(storeType)})
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_SSL_TRUST_STORE_TYPE", Value: string(storeType)})
+	}
+
 	return envVars
 }
 
@@ -403,16 +602,21 @@ func (tls *TLSConfig) clientEnvVars() []corev1.EnvVar {
 
 	if opts.PKCS12Secret != nil {
 		envVars = append(envVars, tls.keystoreEnvVars("SOLR_SSL_CLIENT_KEY_STORE")...)
-		// if no additional truststore secret provided, just use the keystore for both
-		if opts.TrustStoreSecret == nil {
+		// if no additional truststore provided, just use the keystore for both
+		if opts.TrustStoreSecret == nil && opts.TrustBundleConfigMap == nil {
 			envVars = append(envVars, tls.keystoreEnvVars("SOLR_SSL_CLIENT_TRUST_STORE")...)
 		}
 	}
 
-	if opts.TrustStoreSecret != nil {
+	if opts.TrustStoreSecret != nil || opts.TrustBundleConfigMap != nil {
 		envVars = append(envVars, tls.truststoreEnvVars("SOLR_SSL_CLIENT_TRUST_STORE")...)
 	}
 
+	if storeType := opts.StoreType(); storeType != solr.PKCS12KeyStore {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_SSL_CLIENT_KEY_STORE_TYPE", Value: string(storeType)})
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_SSL_CLIENT_TRUST_STORE_TYPE", Value: string(storeType)})
+	}
+
 	return envVars
 }
 
@@ -450,7 +654,10 @@ func (tls *TLSConfig) truststoreEnvVars(varName string) []corev1.EnvVar {
 	}
 
 	var truststoreFile string
-	if opts.TrustStoreSecret != nil {
+	if opts.TrustBundleConfigMap != nil {
+		// trust store is generated from the CA bundle by an initContainer
+		truststoreFile = tls.TruststorePath + "/" + DefaultPkcs12TruststoreFile
+	} else if opts.TrustStoreSecret != nil {
 		if opts.TrustStoreSecret.Name != keystoreSecretName {
 			// trust store is in a different secret, so will be mounted in a different dir
 			truststoreFile = tls.TruststorePath + "/" + opts.TrustStoreSecret.Key
@@ -458,6 +665,9 @@ func (tls *TLSConfig) truststoreEnvVars(varName string) []corev1.EnvVar {
 			// trust store is a different key in the same secret as the keystore
 			truststoreFile = tls.KeystorePath + "/" + DefaultPkcs12TruststoreFile
 		}
+	} else if opts.PerPodCertificates != nil {
+		// truststore is generated from the CA of the issuer, next to the keystore of the pod
+		truststoreFile = tls.KeystorePath + "/" + DefaultPkcs12TruststoreFile
 	} else {
 		// truststore is the same as the keystore
 		truststoreFile = tls.keystoreFile()
@@ -585,12 +795,13 @@ func exportVarFromFileInInitdbWrapperScript(varName string, varValue string) str
 
 // Returns an array of Java system properties to configure the TLS certificate used by client applications to call mTLS enabled Solr pods
 func (tls *TLSConfig) clientJavaOpts() []string {
+	storeType := string(tls.Options.StoreType())
 
 	// for clients, we should always have a truststore but the keystore is optional
 	javaOpts := []string{
 		"-Dsolr.ssl.checkPeerName=$(SOLR_SSL_CHECK_PEER_NAME)",
 		"-Djavax.net.ssl.trustStore=$(SOLR_SSL_CLIENT_TRUST_STORE)",
-		"-Djavax.net.ssl.trustStoreType=PKCS12",
+		"-Djavax.net.ssl.trustStoreType=" + storeType,
 	}
 
 	if tls.Options.VerifyClientHostname {
@@ -599,14 +810,14 @@ func (tls *TLSConfig) clientJavaOpts() []string {
 
 	if tls.Options.PKCS12Secret != nil || (tls.Options.MountedTLSDir != nil && tls.Options.MountedTLSDir.KeystoreFile != "") {
 		javaOpts = append(javaOpts, "-Djavax.net.ssl.keyStore=$(SOLR_SSL_CLIENT_KEY_STORE)")
-		javaOpts = append(javaOpts, "-Djavax.net.ssl.keyStoreType=PKCS12")
+		javaOpts = append(javaOpts, "-Djavax.net.ssl.keyStoreType="+storeType)
 	}
 
 	if tls.Options.PKCS12Secret != nil {
 		javaOpts = append(javaOpts, "-Djavax.net.ssl.keyStorePassword=$(SOLR_SSL_CLIENT_KEY_STORE_PASSWORD)")
 	} // else for mounted dir option, the password comes from the wrapper script
 
-	if tls.Options.PKCS12Secret != nil || tls.Options.TrustStoreSecret != nil {
+	if tls.Options.PKCS12Secret != nil || tls.Options.TrustStoreSecret != nil || tls.Options.TrustBundleConfigMap != nil {
 		javaOpts = append(javaOpts, "-Djavax.net.ssl.trustStorePassword=$(SOLR_SSL_CLIENT_TRUST_STORE_PASSWORD)")
 	} // else for mounted dir option, the password comes from the wrapper script
 
@@ -622,16 +833,69 @@ func (tls *TLSConfig) generatePkcs12InitContainer(imageName string, imagePullPol
 		},
 	}
 
-	cmd := "openssl pkcs12 -export -in " + DefaultKeyStorePath + "/" + TLSCertKey + " -in " + DefaultKeyStorePath +
-		"/ca.crt -inkey " + DefaultKeyStorePath + "/tls.key -out " + DefaultKeyStorePath +
-		"/pkcs12/" + DefaultPkcs12KeystoreFile + " -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
-
 	return corev1.Container{
 		Name:                     "gen-pkcs12-keystore",
 		Image:                    imageName,
 		ImagePullPolicy:          imagePullPolicy,
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: "File",
+		Command:                  []string{"sh", "-c", tls.generateKeystoreCommand(DefaultKeyStorePath, DefaultWritableKeyStorePath)},
+		VolumeMounts:             mounts,
+		Env:                      envVars,
+	}
+}
+
+// Build the command that generates a keystore of the configured type in keystoreDir, from the tls.crt, tls.key and ca.crt in certDir.
+// The keystore password is read from the SOLR_SSL_KEY_STORE_PASSWORD env var.
+func (tls *TLSConfig) generateKeystoreCommand(certDir string, keystoreDir string) string {
+	keystoreFile := keystoreDir + "/" + DefaultPkcs12KeystoreFile
+	storeType := tls.Options.StoreType()
+	opensslOut := keystoreFile
+	if storeType != solr.PKCS12KeyStore {
+		// openssl can only write pkcs12 keystores, so keytool converts it to the requested type afterwards
+		opensslOut = keystoreDir + "/openssl-" + DefaultPkcs12KeystoreFile
+	}
+
+	cmd := "openssl pkcs12 -export -in " + certDir + "/" + TLSCertKey + " -in " + certDir +
+		"/ca.crt -inkey " + certDir + "/tls.key -out " + opensslOut + " -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
+	if FIPSMode() {
+		// The default openssl PBE algorithms (RC2 & 3DES) are not FIPS-approved
+		cmd += " -keypbe AES-256-CBC -certpbe AES-256-CBC -macalg sha256"
+	}
+	if storeType != solr.PKCS12KeyStore {
+		cmd += fmt.Sprintf(" && rm -f %s && keytool -importkeystore -noprompt -srckeystore %s -srcstoretype PKCS12 -srcstorepass \"${SOLR_SSL_KEY_STORE_PASSWORD}\" "+
+			"-destkeystore %s -deststoretype %s -deststorepass \"${SOLR_SSL_KEY_STORE_PASSWORD}\" && rm -f %s",
+			keystoreFile, opensslOut, keystoreFile, storeType, opensslOut)
+	}
+	return cmd
+}
+
+// Create an initContainer that imports each CA cert in the trust bundle into a truststore of the configured type, using the keytool of the main container's image
+func (tls *TLSConfig) generateTrustBundleInitContainer(imageName string, imagePullPolicy corev1.PullPolicy, mounts []corev1.VolumeMount) corev1.Container {
+	passwordSecret := tls.Options.TrustStorePasswordSecret
+	if passwordSecret == nil {
+		passwordSecret = tls.Options.KeyStorePasswordSecret
+	}
+	envVars := []corev1.EnvVar{
+		{
+			Name:      "TRUST_STORE_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordSecret},
+		},
+	}
+
+	// keytool only imports the first certificate of a file, so split the bundle into a file per certificate
+	truststoreFile := tls.TruststorePath + "/" + DefaultPkcs12TruststoreFile
+	cmd := fmt.Sprintf("rm -f %s && cd $(mktemp -d) && "+
+		"awk '/-----BEGIN CERTIFICATE-----/{n++} n>0{print > (\"ca-\" n \".pem\")}' %s && "+
+		"for cert in ca-*.pem; do keytool -importcert -noprompt -storetype %s -keystore %s -storepass \"${TRUST_STORE_PASSWORD}\" -alias \"${cert%%.pem}\" -file \"${cert}\" || exit 1; done",
+		truststoreFile, tls.trustBundlePath()+"/"+TrustBundleFile, tls.Options.StoreType(), truststoreFile)
+
+	return corev1.Container{
+		Name:                     tls.VolumePrefix + "gen-pkcs12-truststore",
+		Image:                    imageName,
+		ImagePullPolicy:          imagePullPolicy,
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
 		Command:                  []string{"sh", "-c", cmd},
 		VolumeMounts:             mounts,
 		Env:                      envVars,
@@ -666,6 +930,7 @@ func secureProbeTLSJavaSysProps(solrCloud *solr.SolrCloud) string {
 
 	// prefer the client cert for probes if available
 	if solrCloud.Spec.SolrClientTLS != nil {
+		tlsJavaSysProps += storeTypeJavaSysProps(solrCloud.Spec.SolrClientTLS)
 		tlsJavaSysProps += " -Djavax.net.ssl.trustStore=$SOLR_SSL_CLIENT_TRUST_STORE"
 		if solrCloud.Spec.SolrClientTLS.MountedTLSDir != nil {
 			// may not always have a keystore with mountedTLSDir
@@ -679,6 +944,7 @@ func secureProbeTLSJavaSysProps(solrCloud *solr.SolrCloud) string {
 		}
 	} else {
 		// use the server cert, either from the mounted dir or from envVars sourced from a secret
+		tlsJavaSysProps += storeTypeJavaSysProps(solrCloud.Spec.SolrTLS)
 		tlsJavaSysProps += " -Djavax.net.ssl.trustStore=$SOLR_SSL_TRUST_STORE"
 		tlsJavaSysProps += " -Djavax.net.ssl.keyStore=$SOLR_SSL_KEY_STORE"
 
@@ -691,6 +957,16 @@ func secureProbeTLSJavaSysProps(solrCloud *solr.SolrCloud) string {
 	return tlsJavaSysProps
 }
 
+// Get the Java system properties for the types of the keystore and truststore used by the probe command.
+// PKCS12 is the default type of the JVM, so these are left out for PKCS12 stores and the probes of existing SolrClouds do not change
+func storeTypeJavaSysProps(opts *solr.SolrTLSOptions) string {
+	storeType := opts.StoreType()
+	if storeType == solr.PKCS12KeyStore {
+		return ""
+	}
+	return fmt.Sprintf(" -Djavax.net.ssl.keyStoreType=%s -Djavax.net.ssl.trustStoreType=%s", storeType, storeType)
+}
+
 func mountedTLSKeystorePath(tlsDir *solr.MountedTLSDirectory) string {
 	return mountedTLSPath(tlsDir, tlsDir.KeystoreFile, DefaultPkcs12KeystoreFile)
 }
@@ -790,7 +1066,7 @@ func verifyTLSSecretConfig(client *client.Client, secretName string, secretNames
 		return nil, lookupErr
 	} else {
 		if passwordSecret == nil {
-			return nil, fmt.Errorf("no password secret configured for %s", secretName)
+			return nil, TerminalErrorf(InvalidTLSConfigReason, "no password secret configured for %s", secretName)
 		}
 
 		// Make sure the secret containing the keystore password exists as well
@@ -802,7 +1078,7 @@ func verifyTLSSecretConfig(client *client.Client, secretName string, secretNames
 
 		// we found the keystore secret, but does it have the key we expect?
 		if _, ok := keyStorePasswordSecret.Data[passwordSecret.Key]; !ok {
-			return nil, fmt.Errorf("%s key not found in password secret %s", passwordSecret.Key, keyStorePasswordSecret.Name)
+			return nil, TerminalErrorf(InvalidTLSConfigReason, "%s key not found in password secret %s", passwordSecret.Key, keyStorePasswordSecret.Name)
 		}
 	}
 
@@ -18,15 +18,20 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers/util/solr_api"
 	"github.com/go-logr/logr"
 	cron "github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"net/url"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,6 +40,19 @@ const (
 	DefaultMaxShardReplicasUnavailable = 1
 
 	SolrScheduledRestartAnnotation = "solr.apache.org/nextScheduledRestart"
+
+	// SolrServingCondition is the pod condition for the readiness gate that is used to take Solr pods out of service before they are updated
+	SolrServingCondition corev1.PodConditionType = "solr.apache.org/serving"
+
+	// PodDeletionCostAnnotation is used by Kubernetes scale-down mechanisms to prefer deleting pods with a lower cost
+	PodDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+	podDeletionCostPerLeader      = 100
+	podDeletionCostPerReplica     = 1
+	podDeletionCostOverseerLeader = 1000000
+
+	// maxConcurrentClusterStatusRequests is the maximum number of CLUSTERSTATUS requests sent at the same time, for SolrClouds that fetch their cluster state per collection
+	maxConcurrentClusterStatusRequests = 4
 )
 
 func ScheduleNextRestart(restartSchedule string, podTemplateAnnotations map[string]string) (nextRestart string, reconcileWaitDuration *time.Duration, err error) {
@@ -84,40 +102,223 @@ func scheduleNextRestartWithTime(restartSchedule string, podTemplateAnnotations
 	return
 }
 
+// SolrClusterState fetches the cluster state and overseer status of a SolrCloud at most once, the first time that each is needed.
+// A single SolrClusterState is shared by all decisions made within a reconcile, so that they are based on the same state,
+// and so that large SolrClouds are not sent a CLUSTERSTATUS request for every feature that needs one.
+// Errors are remembered as well, so that an unreachable SolrCloud is not retried within the same reconcile.
+type SolrClusterState struct {
+	cloud       *solr.SolrCloud
+	httpHeaders map[string]string
+
+	clusterStatus      *solr_api.SolrClusterStatus
+	clusterStatusErr   error
+	collectionNames    []string
+	collectionNamesErr error
+	overseerLeader     *string
+	overseerLeaderErr  error
+}
+
+// NewSolrClusterState creates a SolrClusterState for the SolrCloud, which will be fetched using the given headers
+func NewSolrClusterState(cloud *solr.SolrCloud, httpHeaders map[string]string) *SolrClusterState {
+	return &SolrClusterState{
+		cloud:       cloud,
+		httpHeaders: httpHeaders,
+	}
+}
+
+// ClusterStatus returns the response of the CLUSTERSTATUS action, fetching it if it has not yet been fetched.
+// SolrClouds with more collections than their clusterStateOptions.perCollectionThreshold have their cluster state fetched one collection at a time.
+func (state *SolrClusterState) ClusterStatus() (solr_api.SolrClusterStatus, error) {
+	if state.clusterStatus == nil && state.clusterStatusErr == nil {
+		var perCollection bool
+		if opts := state.cloud.Spec.ClusterStateOptions; opts != nil && opts.PerCollectionThreshold != nil {
+			var collections []string
+			if collections, state.clusterStatusErr = state.CollectionNames(); state.clusterStatusErr == nil && len(collections) > int(*opts.PerCollectionThreshold) {
+				perCollection = true
+				state.clusterStatus, state.clusterStatusErr = state.fetchClusterStatusPerCollection(collections)
+			}
+		}
+		if !perCollection && state.clusterStatusErr == nil {
+			state.clusterStatus, state.clusterStatusErr = state.fetchClusterStatus("")
+		}
+	}
+	if state.clusterStatusErr != nil {
+		return solr_api.SolrClusterStatus{}, state.clusterStatusErr
+	}
+	return *state.clusterStatus, nil
+}
+
+// CollectionNames returns the names of the collections in the SolrCloud, sorted.
+// If the cluster state has not been fetched, only the names are fetched, using the LIST action, which is much cheaper for large SolrClouds.
+func (state *SolrClusterState) CollectionNames() ([]string, error) {
+	if state.clusterStatus != nil {
+		collections := make([]string, 0, len(state.clusterStatus.Collections))
+		for collection := range state.clusterStatus.Collections {
+			collections = append(collections, collection)
+		}
+		sort.Strings(collections)
+		return collections, nil
+	}
+	if state.collectionNames == nil && state.collectionNamesErr == nil {
+		listResp := &solr_api.SolrCollectionsListResponse{}
+		queryParams := url.Values{}
+		queryParams.Add("action", "LIST")
+		if err := solr_api.CallCollectionsApi(state.cloud, queryParams, state.httpHeaders, listResp); err != nil {
+			state.collectionNamesErr = err
+		} else if hasError, apiErr := solr_api.CheckForCollectionsApiError("LIST", listResp.ResponseHeader); hasError {
+			state.collectionNamesErr = apiErr
+		} else {
+			state.collectionNames = append([]string{}, listResp.Collections...)
+			sort.Strings(state.collectionNames)
+		}
+	}
+	return state.collectionNames, state.collectionNamesErr
+}
+
+// fetchClusterStatus calls the CLUSTERSTATUS action, for the whole cluster or just the given collection
+func (state *SolrClusterState) fetchClusterStatus(collection string) (*solr_api.SolrClusterStatus, error) {
+	clusterResp := &solr_api.SolrClusterStatusResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "CLUSTERSTATUS")
+	if collection != "" {
+		queryParams.Add("collection", collection)
+	}
+	if err := solr_api.CallCollectionsApi(state.cloud, queryParams, state.httpHeaders, clusterResp); err != nil {
+		return nil, err
+	} else if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
+		return nil, apiErr
+	}
+	return &clusterResp.ClusterStatus, nil
+}
+
+// fetchClusterStatusPerCollection builds the cluster state from a CLUSTERSTATUS request for each of the given collections,
+// with at most maxConcurrentClusterStatusRequests requests at a time.
+// The live nodes, aliases and roles are returned with every response, so they are taken from the last one.
+// Collections that were deleted since they were listed are left out.
+func (state *SolrClusterState) fetchClusterStatusPerCollection(collections []string) (*solr_api.SolrClusterStatus, error) {
+	clusterStatus := &solr_api.SolrClusterStatus{
+		Collections: make(map[string]solr_api.SolrCollectionStatus, len(collections)),
+	}
+	var fetchErr error
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	requests := make(chan struct{}, maxConcurrentClusterStatusRequests)
+	for _, collection := range collections {
+		wg.Add(1)
+		requests <- struct{}{}
+		go func(collection string) {
+			defer func() {
+				<-requests
+				wg.Done()
+			}()
+			collectionStatus, err := state.fetchClusterStatus(collection)
+			lock.Lock()
+			defer lock.Unlock()
+			if solr_api.IsCollectionNotFound(err, collection) {
+				return
+			} else if err != nil {
+				if fetchErr == nil {
+					fetchErr = fmt.Errorf("error fetching the cluster state of collection %s: %w", collection, err)
+				}
+				return
+			}
+			mergeClusterStatus(clusterStatus, collectionStatus)
+		}(collection)
+	}
+	wg.Wait()
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	return clusterStatus, nil
+}
+
+// CollectionStatuses returns the cluster state of the given collections, leaving out the collections that do not exist.
+// The whole cluster state is used if it has been fetched, or if it would be fetched with a single CLUSTERSTATUS request.
+// Otherwise, for SolrClouds that fetch their cluster state per collection, only the given collections are fetched.
+func (state *SolrClusterState) CollectionStatuses(collections []string) (map[string]solr_api.SolrCollectionStatus, error) {
+	if state.clusterStatus == nil && state.clusterStatusErr == nil {
+		if opts := state.cloud.Spec.ClusterStateOptions; opts != nil && opts.PerCollectionThreshold != nil {
+			allCollections, err := state.CollectionNames()
+			if err != nil {
+				return nil, err
+			}
+			if len(allCollections) > int(*opts.PerCollectionThreshold) {
+				partialStatus, err := state.fetchClusterStatusPerCollection(collections)
+				if err != nil {
+					return nil, err
+				}
+				return partialStatus.Collections, nil
+			}
+		}
+	}
+	clusterStatus, err := state.ClusterStatus()
+	if err != nil {
+		return nil, err
+	}
+	collectionStatuses := make(map[string]solr_api.SolrCollectionStatus, len(collections))
+	for _, collection := range collections {
+		if collectionStatus, found := clusterStatus.Collections[collection]; found {
+			collectionStatuses[collection] = collectionStatus
+		}
+	}
+	return collectionStatuses, nil
+}
+
+// mergeClusterStatus adds the collections of a partial cluster state to the given cluster state, and replaces its cluster-wide information
+func mergeClusterStatus(clusterStatus *solr_api.SolrClusterStatus, partial *solr_api.SolrClusterStatus) {
+	for name, collection := range partial.Collections {
+		clusterStatus.Collections[name] = collection
+	}
+	clusterStatus.LiveNodes = partial.LiveNodes
+	clusterStatus.Aliases = partial.Aliases
+	clusterStatus.Roles = partial.Roles
+}
+
+// OverseerLeader returns the Solr node that is the overseer leader, fetching the OVERSEERSTATUS if it has not yet been fetched
+func (state *SolrClusterState) OverseerLeader() (string, error) {
+	if state.overseerLeader == nil && state.overseerLeaderErr == nil {
+		overseerResp := &solr_api.SolrOverseerStatusResponse{}
+		queryParams := url.Values{}
+		queryParams.Add("action", "OVERSEERSTATUS")
+		if err := solr_api.CallCollectionsApi(state.cloud, queryParams, state.httpHeaders, overseerResp); err != nil {
+			state.overseerLeaderErr = err
+		} else if hasError, apiErr := solr_api.CheckForCollectionsApiError("OVERSEERSTATUS", overseerResp.ResponseHeader); hasError {
+			state.overseerLeaderErr = apiErr
+		} else {
+			state.overseerLeader = &overseerResp.Leader
+		}
+	}
+	if state.overseerLeaderErr != nil {
+		return "", state.overseerLeaderErr
+	}
+	return *state.overseerLeader, nil
+}
+
 // DeterminePodsSafeToUpdate takes a list of solr Pods and returns a list of pods that are safe to upgrade now.
 // This function MUST be idempotent and return the same list of pods given the same kubernetes/solr state.
 //
 // NOTE: It is assumed that the list of pods provided are all started.
 // If an out of date pod has a solr container that is not started, it should be accounted for in outOfDatePodsNotStartedCount not outOfDatePods.
 //
-// TODO:
-//  - Think about caching this for ~250 ms? Not a huge need to send these requests milliseconds apart.
-//    - Might be too much complexity for very little gain.
-func DeterminePodsSafeToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, totalPods int, readyPods int, availableUpdatedPodCount int, outOfDatePodsNotStartedCount int, logger logr.Logger, httpHeaders map[string]string) (podsToUpdate []corev1.Pod, retryLater bool) {
+// The cluster state is only fetched, through the given SolrClusterState, if there is room to update pods and Solr is ready.
+// If a SingleReplicaUpdateState is given, it records the pods that host the only replica of shards.
+func DeterminePodsSafeToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, totalPods int, readyPods int, availableUpdatedPodCount int, outOfDatePodsNotStartedCount int, zoneState *ZoneUpdateState, singleReplicaState *SingleReplicaUpdateState, logger logr.Logger, clusterState *SolrClusterState) (podsToUpdate []corev1.Pod, retryLater bool) {
 	// Before fetching the cluster state, be sure that there is room to update at least 1 pod
 	maxPodsUnavailable, unavailableUpdatedPodCount, maxPodsToUpdate := calculateMaxPodsToUpdate(cloud, totalPods, len(outOfDatePods), outOfDatePodsNotStartedCount, availableUpdatedPodCount)
 	if maxPodsToUpdate <= 0 {
 		logger.Info("Pod update selection canceled. The number of updated pods unavailable equals or exceeds the calculated maxPodsUnavailable.",
 			"unavailableUpdatedPods", unavailableUpdatedPodCount, "outOfDatePodsNotStarted", outOfDatePodsNotStartedCount, "maxPodsUnavailable", maxPodsUnavailable)
 	} else {
-		clusterResp := &solr_api.SolrClusterStatusResponse{}
-		overseerResp := &solr_api.SolrOverseerStatusResponse{}
+		var clusterStatus solr_api.SolrClusterStatus
+		var overseerLeader string
 
 		if readyPods > 0 {
-			queryParams := url.Values{}
-			queryParams.Add("action", "CLUSTERSTATUS")
-			err := solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, clusterResp)
+			var err error
+			clusterStatus, err = clusterState.ClusterStatus()
 			if err == nil {
-				if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
-					err = apiErr
-				} else {
-					queryParams.Set("action", "OVERSEERSTATUS")
-					err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, overseerResp)
-					if hasError, apiErr := solr_api.CheckForCollectionsApiError("OVERSEERSTATUS", clusterResp.ResponseHeader); hasError {
-						err = apiErr
-					}
-				}
-			} else {
+				overseerLeader, err = clusterState.OverseerLeader()
+			}
+			if err != nil {
 				logger.Error(err, "Error retrieving cluster status, delaying pod update selection")
 				// If there is an error fetching the clusterState, retry later.
 				retryLater = true
@@ -126,7 +327,7 @@ func DeterminePodsSafeToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod
 		// If the update logic already wants to retry later, then do not pick any pods
 		if !retryLater {
 			logger.Info("Pod update selection started.", "outOfDatePods", len(outOfDatePods), "maxPodsUnavailable", maxPodsUnavailable, "unavailableUpdatedPods", unavailableUpdatedPodCount, "outOfDatePodsNotStarted", outOfDatePodsNotStartedCount, "maxPodsToUpdate", maxPodsToUpdate)
-			podsToUpdate = pickPodsToUpdate(cloud, outOfDatePods, clusterResp.ClusterStatus, overseerResp.Leader, totalPods, maxPodsToUpdate, logger)
+			podsToUpdate = pickPodsToUpdate(cloud, outOfDatePods, clusterStatus, overseerLeader, totalPods, maxPodsToUpdate, zoneState, singleReplicaState, logger)
 
 			// If there are no pods to upgrade, even though the maxPodsToUpdate is >0, then retry later because the issue stems from cluster state
 			// and clusterState changes will not call the reconciler.
@@ -152,7 +353,7 @@ func calculateMaxPodsToUpdate(cloud *solr.SolrCloud, totalPods int, outOfDatePod
 }
 
 func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, clusterStatus solr_api.SolrClusterStatus,
-	overseer string, totalPods int, maxPodsToUpdate int, logger logr.Logger) (podsToUpdate []corev1.Pod) {
+	overseer string, totalPods int, maxPodsToUpdate int, zoneState *ZoneUpdateState, singleReplicaState *SingleReplicaUpdateState, logger logr.Logger) (podsToUpdate []corev1.Pod) {
 
 	nodeContents, totalShardReplicas, shardReplicasNotActive := findSolrNodeContents(clusterStatus, overseer)
 	sortNodePodsBySafety(outOfDatePods, nodeContents, cloud)
@@ -171,8 +372,11 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 		nodeName := SolrNodeName(cloud, pod)
 		nodeContent, isInClusterState := nodeContents[nodeName]
 		var reason string
-		// The overseerLeader can only be upgraded by itself
-		if !isInClusterState || !nodeContent.InClusterState() {
+		if zoneFull, zoneReason := zoneState.zoneLimitReached(pod.Name, updateOptions.MaxPodsUnavailablePerZone); zoneFull {
+			// No more pods can be taken down in this zone, regardless of the replicas they host
+			isSafeToUpdate = false
+			reason = zoneReason
+		} else if !isInClusterState || !nodeContent.InClusterState() {
 			// All pods not in the cluster state are safe to upgrade
 			isSafeToUpdate = true
 			reason = "Pod not in represented in the cluster state"
@@ -221,6 +425,24 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 					if reason == "" {
 						reason = "Pod's replicas are safe to take down, adhering to the minimum active replicas per shard."
 					}
+
+					// Shards with a single replica are unavailable while the pod restarts, regardless of maxShardReplicasUnavailable
+					if isSafeToUpdate {
+						if singleReplicaShards := singleReplicaShardsFromNode(nodeContent, totalShardReplicas); len(singleReplicaShards) > 0 {
+							switch updateOptions.SingleReplicaShards {
+							case solr.BlockSingleReplicaShards:
+								isSafeToUpdate = false
+								reason = fmt.Sprintf("Pod hosts the only replica of %d shards, and singleReplicaShards is set to Block.", len(singleReplicaShards))
+								singleReplicaState.addBlockedPod(pod.Name, singleReplicaShards)
+							case solr.AddReplicaForSingleReplicaShards:
+								isSafeToUpdate = false
+								reason = fmt.Sprintf("Pod hosts the only replica of %d shards, which must be given temporary replicas first.", len(singleReplicaShards))
+								singleReplicaState.addBlockedPod(pod.Name, singleReplicaShards)
+							default:
+								singleReplicaState.addUpdatedPod(pod.Name, singleReplicaShards)
+							}
+						}
+					}
 				}
 			}
 		}
@@ -232,6 +454,7 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 					shardReplicasNotActive[shard] += additionalReplicaCount
 				}
 			}
+			zoneState.markUnavailable(pod.Name)
 			logger.Info("Pod killed for update.", "pod", pod.Name, "reason", reason)
 			podsToUpdate = append(podsToUpdate, pod)
 
@@ -306,6 +529,134 @@ func ResolveMaxPodsUnavailable(maxPodsUnavailable *intstr.IntOrString, desiredPo
 	return podsUnavailable, nil
 }
 
+// HasServingReadinessGate returns whether the pod was created with the readiness gate used to drain it before updates
+func HasServingReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == SolrServingCondition {
+			return true
+		}
+	}
+	return false
+}
+
+func podServingCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == SolrServingCondition {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetPodServing sets the serving condition on the pod, so that the pod is added to or removed from Service endpoints.
+// Returns true if the pod status was changed, and therefore needs to be updated.
+func SetPodServing(pod *corev1.Pod, serving bool, reason string, now time.Time) (changed bool) {
+	status := corev1.ConditionFalse
+	if serving {
+		status = corev1.ConditionTrue
+	}
+	condition := podServingCondition(pod)
+	if condition == nil {
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{Type: SolrServingCondition})
+		condition = &pod.Status.Conditions[len(pod.Status.Conditions)-1]
+	} else if condition.Status == status {
+		return false
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.LastTransitionTime = metav1.NewTime(now)
+	return true
+}
+
+// NeedsServingCondition returns whether the pod has the serving readiness gate, but the Solr Operator has not yet set its condition.
+// Until the condition is set, the pod cannot become ready.
+func NeedsServingCondition(pod *corev1.Pod) bool {
+	return HasServingReadinessGate(pod) && podServingCondition(pod) == nil
+}
+
+// PodWarmUpTimeRemaining determines whether the containers of a pod are ready, and how long the pod still needs to warm up before it is put into service.
+func PodWarmUpTimeRemaining(pod *corev1.Pod, warmUpPeriod time.Duration, now time.Time) (containersReady bool, remaining time.Duration) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.ContainersReady {
+			if condition.Status != corev1.ConditionTrue {
+				return false, 0
+			}
+			remaining = condition.LastTransitionTime.Add(warmUpPeriod).Sub(now)
+			if remaining < 0 {
+				remaining = 0
+			}
+			return true, remaining
+		}
+	}
+	return false, 0
+}
+
+// PodDrainTimeRemaining determines whether a pod has been taken out of service to be updated, and how long it still needs to drain connections before it can be deleted.
+func PodDrainTimeRemaining(pod *corev1.Pod, drainPeriod time.Duration, now time.Time) (draining bool, remaining time.Duration) {
+	condition := podServingCondition(pod)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return false, 0
+	}
+	remaining = condition.LastTransitionTime.Add(drainPeriod).Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return true, remaining
+}
+
+// ZoneUpdateState holds the zone of each Solr pod, and how many pods in each zone are unavailable.
+// It is used to limit the number of pods taken down for an update within a single zone.
+type ZoneUpdateState struct {
+	// Map from pod name to the zone that the pod is running in
+	PodZones map[string]string
+
+	// Map from zone to the number of pods in that zone that are unavailable
+	UnavailablePodsPerZone map[string]int
+}
+
+// NewZoneUpdateState creates an empty ZoneUpdateState
+func NewZoneUpdateState() *ZoneUpdateState {
+	return &ZoneUpdateState{
+		PodZones:               map[string]string{},
+		UnavailablePodsPerZone: map[string]int{},
+	}
+}
+
+// AddPod records the zone of a pod, and whether it is currently unavailable
+func (state *ZoneUpdateState) AddPod(podName string, zone string, available bool) {
+	state.PodZones[podName] = zone
+	if !available {
+		state.UnavailablePodsPerZone[zone] += 1
+	}
+}
+
+// zoneLimitReached determines whether taking down the given pod would exceed the maximum number of unavailable pods in its zone.
+// A nil ZoneUpdateState imposes no limits.
+func (state *ZoneUpdateState) zoneLimitReached(podName string, maxPodsUnavailablePerZone *intstr.IntOrString) (limitReached bool, reason string) {
+	if state == nil || maxPodsUnavailablePerZone == nil {
+		return false, ""
+	}
+	zone := state.PodZones[podName]
+	podsInZone := 0
+	for _, podZone := range state.PodZones {
+		if podZone == zone {
+			podsInZone += 1
+		}
+	}
+	maxUnavailable, _ := ResolveMaxPodsUnavailable(maxPodsUnavailablePerZone, podsInZone)
+	if state.UnavailablePodsPerZone[zone] >= maxUnavailable {
+		return true, fmt.Sprintf("Zone %q already has %d pods unavailable, which is the maximum allowed: %d", zone, state.UnavailablePodsPerZone[zone], maxUnavailable)
+	}
+	return false, ""
+}
+
+// markUnavailable records that the given pod will be taken down
+func (state *ZoneUpdateState) markUnavailable(podName string) {
+	if state != nil {
+		state.UnavailablePodsPerZone[state.PodZones[podName]] += 1
+	}
+}
+
 // ResolveMaxShardReplicasUnavailable resolves the maximum number of replicas that are allowed to be unavailable for a given shard, when choosing pods to update.
 func ResolveMaxShardReplicasUnavailable(maxShardReplicasUnavailable *intstr.IntOrString, shard string, totalShardReplicas map[string]int, cache map[string]int) (int, error) {
 	maxUnavailable, isCached := cache[shard]
@@ -330,9 +681,9 @@ func ResolveMaxShardReplicasUnavailable(maxShardReplicasUnavailable *intstr.IntO
 /*
 findSolrNodeContents will take a cluster and overseerLeader response from the SolrCloud Collections API, and aggregate the information.
 This aggregated info is returned as:
-	- A map from Solr nodeName to SolrNodeContents, with the information from the clusterState and overseerLeader
-    - A map from unique shard name (collection+shard) to the count of replicas that are not active for that shard.
-      - If a node is not live, then all shards that live on that node will be considered "not active"
+  - A map from Solr nodeName to SolrNodeContents, with the information from the clusterState and overseerLeader
+  - A map from unique shard name (collection+shard) to the count of replicas that are not active for that shard.
+  - If a node is not live, then all shards that live on that node will be considered "not active"
 */
 func findSolrNodeContents(cluster solr_api.SolrClusterStatus, overseerLeader string) (nodeContents map[string]*SolrNodeContents, totalShardReplicas map[string]int, shardReplicasNotActive map[string]int) {
 	nodeContents = map[string]*SolrNodeContents{}
@@ -458,5 +809,132 @@ func (nodeContents *SolrNodeContents) InClusterState() bool {
 
 // SolrNodeName takes a cloud and a pod and returns the Solr nodeName for that pod
 func SolrNodeName(solrCloud *solr.SolrCloud, pod corev1.Pod) string {
-	return fmt.Sprintf("%s:%d_solr", solrCloud.AdvertisedNodeHost(pod.Name), solrCloud.NodePort())
+	host := solrCloud.AdvertisedPodHost(pod.Name, pod.Status.PodIP)
+	if solrCloud.UsesHostNetwork() {
+		if solrCloud.Spec.SolrAddressability.HostNetwork.AdvertisedAddress == solr.HostNetworkNodeName {
+			host = pod.Spec.NodeName
+		} else {
+			host = pod.Status.HostIP
+		}
+	}
+	return fmt.Sprintf("%s:%d_solr", host, solrCloud.AdvertisedNodePort())
+}
+
+// CalculatePodDeletionCosts determines the deletion cost of each of the given Solr pods, using the cluster state of the SolrCloud.
+// Pods hosting more shard leaders and replicas are more costly to remove, and the overseer leader is the most costly.
+func CalculatePodDeletionCosts(cloud *solr.SolrCloud, pods []corev1.Pod, clusterState *SolrClusterState) (podCosts map[string]int, err error) {
+	clusterStatus, err := clusterState.ClusterStatus()
+	if err != nil {
+		return nil, err
+	}
+	overseerLeader, err := clusterState.OverseerLeader()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeContents, _, _ := findSolrNodeContents(clusterStatus, overseerLeader)
+	podCosts = make(map[string]int, len(pods))
+	for _, pod := range pods {
+		podCosts[pod.Name] = podDeletionCost(nodeContents[SolrNodeName(cloud, pod)])
+	}
+	return podCosts, nil
+}
+
+func podDeletionCost(nodeContents *SolrNodeContents) (cost int) {
+	if nodeContents == nil {
+		return 0
+	}
+	if nodeContents.overseerLeader {
+		return podDeletionCostOverseerLeader
+	}
+	return nodeContents.leaders*podDeletionCostPerLeader + nodeContents.replicas*podDeletionCostPerReplica
+}
+
+// SetPodDeletionCost sets the deletion cost annotation on the pod, returning whether the pod was changed.
+func SetPodDeletionCost(pod *corev1.Pod, cost int) (changed bool) {
+	costStr := strconv.Itoa(cost)
+	if pod.Annotations[PodDeletionCostAnnotation] == costStr {
+		return false
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[PodDeletionCostAnnotation] = costStr
+	return true
+}
+
+// UpdateVerificationFailure is a verification check of a Managed update that failed on a Solr node
+type UpdateVerificationFailure struct {
+	Check   string
+	Node    string
+	Problem string
+}
+
+func (f UpdateVerificationFailure) String() string {
+	return fmt.Sprintf("check %q failed on %s: %s", f.Check, f.Node, f.Problem)
+}
+
+// RunUpdateVerificationChecks runs the verification checks of a Managed update against each of the given Solr nodes, and returns every check that failed.
+// Only nodes that are up to date are verified. Those that are not ready cannot be verified, so they are returned as unverified instead.
+func RunUpdateVerificationChecks(cloud *solr.SolrCloud, nodes []solr.SolrNodeStatus, checks []solr.UpdateVerificationCheck, httpHeaders map[string]string) (failures []UpdateVerificationFailure, unverifiedNodes []string) {
+	for _, node := range nodes {
+		if !node.SpecUpToDate {
+			continue
+		}
+		if !node.Ready {
+			unverifiedNodes = append(unverifiedNodes, node.Name)
+			continue
+		}
+		for _, check := range checks {
+			if problem := runUpdateVerificationCheck(cloud, node.Name, check, httpHeaders); problem != "" {
+				failures = append(failures, UpdateVerificationFailure{Check: check.Name, Node: node.Name, Problem: problem})
+			}
+		}
+	}
+	return failures, unverifiedNodes
+}
+
+// UpdateVerificationFailuresKey identifies the checks that failed, and the nodes that they failed on, regardless of the problems reported.
+// The problems can change between runs of the same failing check, such as the number of documents found.
+func UpdateVerificationFailuresKey(failures []UpdateVerificationFailure) string {
+	keys := make([]string, len(failures))
+	for i, failure := range failures {
+		keys[i] = failure.Check + "@" + failure.Node
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func runUpdateVerificationCheck(cloud *solr.SolrCloud, node string, check solr.UpdateVerificationCheck, httpHeaders map[string]string) (problem string) {
+	checkUrl, err := url.Parse(check.Path)
+	if err != nil {
+		return fmt.Sprintf("invalid path: %s", err)
+	}
+	body, err := solr_api.CallNodeAdminApi(cloud, solrNodeUrl(cloud, node), "/solr"+checkUrl.Path, checkUrl.Query(), httpHeaders)
+	if err != nil {
+		return err.Error()
+	}
+	return verificationCheckResponseProblem(check, body)
+}
+
+// verificationCheckResponseProblem checks a successful response to a verification check against the check's expectations
+func verificationCheckResponseProblem(check solr.UpdateVerificationCheck, body []byte) (problem string) {
+	if check.MinHits == nil {
+		return ""
+	}
+	queryResponse := &struct {
+		Response *struct {
+			NumFound int64 `json:"numFound"`
+		} `json:"response"`
+	}{}
+	if err := json.Unmarshal(body, queryResponse); err != nil {
+		return fmt.Sprintf("could not parse the query response: %s", err)
+	}
+	if queryResponse.Response == nil {
+		return "the response is not a query response"
+	}
+	if queryResponse.Response.NumFound < *check.MinHits {
+		return fmt.Sprintf("found %d documents, expected at least %d", queryResponse.Response.NumFound, *check.MinHits)
+	}
+	return ""
 }
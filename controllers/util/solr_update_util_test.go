@@ -79,17 +79,17 @@ func TestPickPodsToUpgrade(t *testing.T) {
 
 	// Normal inputs
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade := getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade := getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-2", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Do to the down/non-live replicas, only the node without replicas and one more can be upgraded.")
 
 	// Test the maxBatchNodeUpgradeSpec
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 1, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 1, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Only 1 node should be upgraded when maxBatchNodeUpgradeSpec=1")
 
 	// Test the maxShardReplicasDownSpec
 	maxshardReplicasUnavailable = intstr.FromInt(2)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-2", "pod-3", "pod-4", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade.")
 
 	/*
@@ -98,22 +98,22 @@ func TestPickPodsToUpgrade(t *testing.T) {
 
 	// Normal inputs
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-4", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Do to the recovering/down/non-live replicas, only the non-live node and node without replicas can be upgraded.")
 
 	// Test the maxBatchNodeUpgradeSpec
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 1, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 1, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-4"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Only 1 node should be upgraded when maxBatchNodeUpgradeSpec=1, and it should be the non-live node.")
 
 	// Test the maxShardReplicasDownSpec
 	maxshardReplicasUnavailable = intstr.FromInt(2)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testRecoveringClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-2", "pod-3", "pod-4", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade. More nodes should be upgraded when maxShardReplicasDown=2")
 
 	// The overseer should be upgraded when given enough leeway
 	maxshardReplicasUnavailable = intstr.FromString("50%")
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 2, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 2, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-0"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The last pod, the overseer, should be chosen because it has been given enough leeway.")
 
 	/*
@@ -122,17 +122,17 @@ func TestPickPodsToUpgrade(t *testing.T) {
 
 	// Normal inputs
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, halfPods, testHealthyClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, halfPods, testHealthyClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-1"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Do to replica placement, only the node with the least leaders can be upgraded and replicas.")
 
 	// Test the maxShardReplicasDownSpec
 	maxshardReplicasUnavailable = intstr.FromInt(2)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, halfPods, testHealthyClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, halfPods, testHealthyClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-1", "pod-5"}, podsToUpgrade, "Incorrect set of next pods to upgrade. More nodes should be upgraded when maxShardReplicasDown=2")
 
 	// The overseer should be upgraded when given enough leeway
 	maxshardReplicasUnavailable = intstr.FromString("50%")
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 2, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 2, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-0"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The last pod, the overseer, should be chosen because it has been given enough leeway.")
 
 	/*
@@ -141,24 +141,96 @@ func TestPickPodsToUpgrade(t *testing.T) {
 
 	// The overseer should be not be upgraded if the clusterstate is not healthy enough
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testRecoveringClusterStatus, overseerLeader, 6, 3, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testRecoveringClusterStatus, overseerLeader, 6, 3, nil, nil, log))
 	assert.ElementsMatch(t, []string{}, podsToUpgrade, "Incorrect set of next pods to upgrade. The overseer should be not be upgraded if the clusterstate is not healthy enough.")
 
 	// The overseer should be not be upgraded if the clusterstate is not healthy enough
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testRecoveringClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testRecoveringClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{}, podsToUpgrade, "Incorrect set of next pods to upgrade. The overseer should be not be upgraded if there are other non-live nodes.")
 
 	// The overseer should be upgraded when given enough leeway
 	maxshardReplicasUnavailable = intstr.FromInt(2)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testDownClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-0"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The overseer should be upgraded when given enough leeway.")
 
 	// The overseer should be upgraded when everything is healthy and it is the last node
 	maxshardReplicasUnavailable = intstr.FromInt(1)
-	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testHealthyClusterStatus, overseerLeader, 6, 6, log))
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, lastPod, testHealthyClusterStatus, overseerLeader, 6, 6, nil, nil, log))
 	assert.ElementsMatch(t, []string{"pod-0"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The overseer should be upgraded when everything is healthy and it is the last node")
 }
 
+func TestPickPodsToUpgradeWithZoneLimits(t *testing.T) {
+	log := ctrl.Log
+
+	overseerLeader := "pod-0.foo-solrcloud-headless.default:2000_solr"
+
+	maxShardReplicasUnavailable := intstr.FromInt(2)
+	maxPodsUnavailablePerZone := intstr.FromInt(1)
+
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort: 2000,
+			},
+			UpdateStrategy: solr.SolrUpdateStrategy{
+				Method: solr.ManagedUpdate,
+				ManagedUpdateOptions: solr.ManagedUpdateOptions{
+					MaxShardReplicasUnavailable: &maxShardReplicasUnavailable,
+					MaxPodsUnavailablePerZone:   &maxPodsUnavailablePerZone,
+				},
+			},
+		},
+	}
+
+	var allPods []corev1.Pod
+	podZones := map[string]string{}
+	for i := 0; i < 7; i++ {
+		podName := "pod-" + strconv.Itoa(i)
+		allPods = append(allPods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName}, Spec: corev1.PodSpec{}})
+		if i < 4 {
+			podZones[podName] = "zone-a"
+		} else {
+			podZones[podName] = "zone-b"
+		}
+	}
+	newZoneState := func(unavailablePods ...string) *ZoneUpdateState {
+		zoneState := NewZoneUpdateState()
+		for _, pod := range allPods {
+			isUnavailable := false
+			for _, unavailablePod := range unavailablePods {
+				isUnavailable = isUnavailable || unavailablePod == pod.Name
+			}
+			zoneState.AddPod(pod.Name, podZones[pod.Name], !isUnavailable)
+		}
+		return zoneState
+	}
+
+	// Without zone limits, pods in the same zone can be updated together
+	podsToUpgrade := getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 7, 7, nil, nil, log))
+	assert.ElementsMatch(t, []string{"pod-2", "pod-3", "pod-4", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade without zone limits.")
+
+	// Only 1 pod per zone can be updated
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 7, 7, newZoneState(), nil, log))
+	assert.Len(t, podsToUpgrade, 2, "Only 1 pod per zone should be upgraded when maxPodsUnavailablePerZone=1")
+	assert.NotEqual(t, podZones[podsToUpgrade[0]], podZones[podsToUpgrade[1]], "The pods to upgrade should be in different zones when maxPodsUnavailablePerZone=1")
+
+	// Zones that already have unavailable pods cannot have more pods updated
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 7, 7, newZoneState("pod-5"), nil, log))
+	assert.Len(t, podsToUpgrade, 1, "Only 1 pod should be upgraded when another zone already has the maximum number of pods unavailable")
+	assert.Equal(t, "zone-a", podZones[podsToUpgrade[0]], "The pod to upgrade should not be in a zone that already has the maximum number of pods unavailable")
+
+	// The overall limit still applies when zones have room
+	maxPodsUnavailablePerZone = intstr.FromString("50%")
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 7, 1, newZoneState(), nil, log))
+	assert.Len(t, podsToUpgrade, 1, "The maxPodsToUpdate limit should still be respected when using zone limits")
+
+	// A limit of 0 allows all pods in a zone to be updated together
+	maxPodsUnavailablePerZone = intstr.FromInt(0)
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, allPods, testDownClusterStatus, overseerLeader, 7, 7, newZoneState(), nil, log))
+	assert.ElementsMatch(t, []string{"pod-2", "pod-3", "pod-4", "pod-6"}, podsToUpgrade, "Incorrect set of next pods to upgrade when there is no zone limit.")
+}
+
 func TestPodUpgradeOrdering(t *testing.T) {
 	solrCloud := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
@@ -476,6 +548,80 @@ func TestCalculateMaxPodsToUpgrade(t *testing.T) {
 	assert.Equal(t, -3, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
 }
 
+func TestPodServingDrain(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-0"},
+		Spec: corev1.PodSpec{
+			ReadinessGates: []corev1.PodReadinessGate{{ConditionType: SolrServingCondition}},
+		},
+	}
+	now := time.Now()
+	drainPeriod := time.Second * 30
+
+	assert.True(t, NeedsServingCondition(pod), "A pod with the readiness gate but no condition needs the condition to be set")
+	draining, _ := PodDrainTimeRemaining(pod, drainPeriod, now)
+	assert.False(t, draining, "A pod without the serving condition should not be draining")
+
+	assert.True(t, SetPodServing(pod, true, "PodStarted", now), "Setting the serving condition for the first time should change the pod")
+	assert.False(t, NeedsServingCondition(pod), "The serving condition has been set")
+	assert.False(t, SetPodServing(pod, true, "PodStarted", now), "Setting the same serving condition should not change the pod")
+	draining, _ = PodDrainTimeRemaining(pod, drainPeriod, now)
+	assert.False(t, draining, "A serving pod should not be draining")
+
+	assert.True(t, SetPodServing(pod, false, "PodUpdating", now), "Taking the pod out of service should change the pod")
+	assert.Len(t, pod.Status.Conditions, 1, "The serving condition should be updated in place")
+	draining, remaining := PodDrainTimeRemaining(pod, drainPeriod, now.Add(time.Second*10))
+	assert.True(t, draining, "A pod taken out of service should be draining")
+	assert.Equal(t, time.Second*20, remaining, "Wrong remaining drain time")
+
+	draining, remaining = PodDrainTimeRemaining(pod, drainPeriod, now.Add(time.Minute))
+	assert.True(t, draining, "A pod taken out of service should be draining")
+	assert.Equal(t, time.Duration(0), remaining, "The pod should be done draining")
+
+	assert.False(t, NeedsServingCondition(&corev1.Pod{}), "A pod without the readiness gate never needs the serving condition")
+}
+
+func TestPodServingWarmUp(t *testing.T) {
+	now := time.Now()
+	warmUpPeriod := time.Second * 60
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-0"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionFalse}},
+		},
+	}
+
+	containersReady, _ := PodWarmUpTimeRemaining(pod, warmUpPeriod, now)
+	assert.False(t, containersReady, "A pod whose containers are not ready cannot start warming up")
+
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	pod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now)
+	containersReady, remaining := PodWarmUpTimeRemaining(pod, warmUpPeriod, now.Add(time.Second*15))
+	assert.True(t, containersReady, "The containers of the pod are ready")
+	assert.Equal(t, time.Second*45, remaining, "Wrong remaining warm-up time")
+
+	containersReady, remaining = PodWarmUpTimeRemaining(pod, warmUpPeriod, now.Add(time.Minute*2))
+	assert.True(t, containersReady, "The containers of the pod are ready")
+	assert.Equal(t, time.Duration(0), remaining, "The pod should be done warming up")
+
+	containersReady, _ = PodWarmUpTimeRemaining(&corev1.Pod{}, warmUpPeriod, now)
+	assert.False(t, containersReady, "A pod without a ContainersReady condition cannot start warming up")
+}
+
+func TestPodDeletionCost(t *testing.T) {
+	nodeContents, _, _ := findSolrNodeContents(testRecoveringClusterStatus, "pod-0.foo-solrcloud-headless.default:2000_solr")
+
+	assert.Equal(t, podDeletionCostOverseerLeader, podDeletionCost(nodeContents["pod-0.foo-solrcloud-headless.default:2000_solr"]), "The overseer leader should have the highest deletion cost")
+	assert.Equal(t, 102, podDeletionCost(nodeContents["pod-1.foo-solrcloud-headless.default:2000_solr"]), "Wrong deletion cost for a pod with 1 leader and 2 replicas")
+	assert.Equal(t, 0, podDeletionCost(nodeContents["pod-9.foo-solrcloud-headless.default:2000_solr"]), "A pod that is not in the cluster state should have no deletion cost")
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-1"}}
+	assert.True(t, SetPodDeletionCost(pod, 102), "Setting the deletion cost for the first time should change the pod")
+	assert.Equal(t, "102", pod.Annotations[PodDeletionCostAnnotation], "Wrong deletion cost annotation")
+	assert.False(t, SetPodDeletionCost(pod, 102), "Setting the same deletion cost should not change the pod")
+	assert.True(t, SetPodDeletionCost(pod, 0), "Changing the deletion cost should change the pod")
+}
+
 func TestSolrNodeName(t *testing.T) {
 	solrCloud := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
@@ -496,6 +642,19 @@ func TestSolrNodeName(t *testing.T) {
 
 	solrCloud.Spec.SolrAddressability.PodPort = 3000
 	assert.Equal(t, "pod-0.foo-solrcloud-headless.default:3000_solr", SolrNodeName(solrCloud, pod), "Incorrect generation of Solr nodeName")
+
+	pod.Spec.NodeName = "node-a"
+	pod.Status.HostIP = "10.0.0.1"
+	solrCloud.Spec.SolrAddressability.HostNetwork = &solr.SolrHostNetworkOptions{AdvertisedAddress: solr.HostNetworkNodeIP}
+	assert.Equal(t, "10.0.0.1:3000_solr", SolrNodeName(solrCloud, pod), "Incorrect generation of Solr nodeName when using the host network")
+
+	solrCloud.Spec.SolrAddressability.HostNetwork.AdvertisedAddress = solr.HostNetworkNodeName
+	assert.Equal(t, "node-a:3000_solr", SolrNodeName(solrCloud, pod), "Incorrect generation of Solr nodeName when using the host network with node names")
+
+	pod.Status.PodIP = "10.1.0.5"
+	solrCloud.Spec.SolrAddressability.HostNetwork = nil
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodIP}}"
+	assert.Equal(t, "10.1.0.5:3000_solr", SolrNodeName(solrCloud, pod), "Incorrect generation of Solr nodeName when advertising the pod IP")
 }
 
 var (
@@ -999,3 +1158,80 @@ func TestScheduleNextRestart(t *testing.T) {
 	}
 	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", schedule)
 }
+
+func TestVerificationCheckResponseProblem(t *testing.T) {
+	healthCheck := solr.UpdateVerificationCheck{Name: "health", Path: "/admin/info/health"}
+	assert.Empty(t, verificationCheckResponseProblem(healthCheck, []byte(`{"status":"OK"}`)), "A check without minHits only requires a successful response")
+
+	minHits := int64(10)
+	queryCheck := solr.UpdateVerificationCheck{Name: "products", Path: "/techproducts/select?q=*:*&rows=0", MinHits: &minHits}
+	assert.Empty(t, verificationCheckResponseProblem(queryCheck, []byte(`{"response":{"numFound":10,"docs":[]}}`)), "The query found enough documents")
+	assert.Contains(t, verificationCheckResponseProblem(queryCheck, []byte(`{"response":{"numFound":9,"docs":[]}}`)), "found 9 documents", "The query did not find enough documents")
+	assert.NotEmpty(t, verificationCheckResponseProblem(queryCheck, []byte(`{"status":"OK"}`)), "A response that is not a query response cannot be checked for hits")
+	assert.NotEmpty(t, verificationCheckResponseProblem(queryCheck, []byte(`not json`)), "A response that cannot be parsed cannot be checked for hits")
+}
+
+func TestRunUpdateVerificationChecksSkipsUnverifiableNodes(t *testing.T) {
+	nodes := []solr.SolrNodeStatus{
+		{Name: "outdated", Ready: true, SpecUpToDate: false},
+		{Name: "restarting", Ready: false, SpecUpToDate: true},
+	}
+	failures, unverifiedNodes := RunUpdateVerificationChecks(&solr.SolrCloud{}, nodes, []solr.UpdateVerificationCheck{{Name: "health", Path: "/admin/info/health"}}, nil)
+	assert.Empty(t, failures, "Nodes that are not up to date, or not ready, should not be checked")
+	assert.Equal(t, []string{"restarting"}, unverifiedNodes, "Updated nodes that are not ready cannot be verified")
+}
+
+func TestUpdateVerificationFailuresKey(t *testing.T) {
+	failures := []UpdateVerificationFailure{
+		{Check: "products", Node: "node-1", Problem: "found 9 documents, expected at least 10"},
+		{Check: "health", Node: "node-0", Problem: "connection refused"},
+	}
+	sameFailures := []UpdateVerificationFailure{
+		{Check: "health", Node: "node-0", Problem: "connection reset"},
+		{Check: "products", Node: "node-1", Problem: "found 8 documents, expected at least 10"},
+	}
+	assert.Equal(t, UpdateVerificationFailuresKey(failures), UpdateVerificationFailuresKey(sameFailures), "The same checks failing on the same nodes should have the same key, regardless of the problems")
+	assert.NotEqual(t, UpdateVerificationFailuresKey(failures), UpdateVerificationFailuresKey(failures[:1]), "Different failures should have different keys")
+	assert.Empty(t, UpdateVerificationFailuresKey(nil), "No failures should have an empty key")
+}
+
+func TestMergeClusterStatus(t *testing.T) {
+	clusterStatus := &solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{},
+	}
+	mergeClusterStatus(clusterStatus, &solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{"col1": {ConfigName: "conf1"}},
+		LiveNodes:   []string{"node1:8983_solr"},
+	})
+	mergeClusterStatus(clusterStatus, &solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{"col2": {ConfigName: "conf2"}},
+		LiveNodes:   []string{"node1:8983_solr", "node2:8983_solr"},
+		Aliases:     map[string]string{"alias": "col2"},
+	})
+
+	assert.Equal(t, map[string]solr_api.SolrCollectionStatus{"col1": {ConfigName: "conf1"}, "col2": {ConfigName: "conf2"}}, clusterStatus.Collections, "The collections of every partial cluster state should be merged")
+	assert.Equal(t, []string{"node1:8983_solr", "node2:8983_solr"}, clusterStatus.LiveNodes, "The live nodes should be taken from the latest partial cluster state")
+	assert.Equal(t, map[string]string{"alias": "col2"}, clusterStatus.Aliases, "The aliases should be taken from the latest partial cluster state")
+}
+
+func TestCollectionNamesFromFetchedClusterStatus(t *testing.T) {
+	clusterState := NewSolrClusterState(&solr.SolrCloud{}, nil)
+	clusterState.clusterStatus = &solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{"col2": {}, "col1": {}, "col3": {}},
+	}
+
+	collections, err := clusterState.CollectionNames()
+	assert.NoError(t, err, "The collection names should not be fetched once the cluster state has been fetched")
+	assert.Equal(t, []string{"col1", "col2", "col3"}, collections, "The collection names should be taken from the fetched cluster state, sorted")
+}
+
+func TestCollectionStatusesFromFetchedClusterStatus(t *testing.T) {
+	clusterState := NewSolrClusterState(&solr.SolrCloud{}, nil)
+	clusterState.clusterStatus = &solr_api.SolrClusterStatus{
+		Collections: map[string]solr_api.SolrCollectionStatus{"col1": {ConfigName: "conf1"}, "col2": {ConfigName: "conf2"}},
+	}
+
+	collectionStatuses, err := clusterState.CollectionStatuses([]string{"col2", "missing"})
+	assert.NoError(t, err, "The collections should not be fetched again once the cluster state has been fetched")
+	assert.Equal(t, map[string]solr_api.SolrCollectionStatus{"col2": {ConfigName: "conf2"}}, collectionStatuses, "Only the requested collections that exist should be returned")
+}
@@ -18,11 +18,14 @@
 package util
 
 import (
+	cryptorand "crypto/rand"
 	"crypto/sha256"
 	b64 "encoding/base64"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"math/big"
+	"net"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
@@ -35,6 +38,7 @@ import (
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -42,6 +46,23 @@ const (
 
 	SolrNodeContainer = "solrcloud-node"
 
+	SolrZkSetupContainer = "setup-zk"
+
+	// The name of the volume, and the default name of the volumeClaimTemplate, holding the data of each Solr Node
+	solrDataVolumeName = "data"
+
+	ConnectionInfoZkConnectionStringKey = "zkConnectionString"
+	ConnectionInfoInternalUrlKey        = "internalUrl"
+	ConnectionInfoExternalUrlKey        = "externalUrl"
+	ConnectionInfoCACertKey             = "ca.crt"
+
+	// Entries required by the Service Binding specification (servicebinding.io)
+	ServiceBindingTypeKey     = "type"
+	ServiceBindingProviderKey = "provider"
+	ServiceBindingUriKey      = "uri"
+	ServiceBindingType        = "solr"
+	ServiceBindingProvider    = "apache-solr-operator"
+
 	DefaultSolrUser  = 8983
 	DefaultSolrGroup = 8983
 
@@ -52,42 +73,111 @@ const (
 	SolrPVCStorageLabel              = "solr.apache.org/storage"
 	SolrCloudPVCDataStorage          = "data"
 	SolrPVCInstanceLabel             = "solr.apache.org/instance"
+	SolrClusterFormationLabel        = "solr.apache.org/clusterFormation"
 	SolrXmlMd5Annotation             = "solr.apache.org/solrXmlMd5"
 	SolrXmlFile                      = "solr.xml"
 	LogXmlMd5Annotation              = "solr.apache.org/logXmlMd5"
 	LogXmlFile                       = "log4j2.xml"
+	ConfigMapFileMd5AnnotationPrefix = "solr.apache.org/configFileMd5-"
+	ConfigMapFileVolumePrefix        = "config-file-"
 	SecurityJsonFile                 = "security.json"
 	BasicAuthMd5Annotation           = "solr.apache.org/basicAuthMd5"
+	BackupRepoCredentialsAnnotation  = "solr.apache.org/backupRepositoryCredentialsMd5"
 	DefaultProbePath                 = "/admin/info/system"
+	JaasConfigVolumeName             = "jaas-config"
+	JaasConfigMountPath              = "/etc/solr/jaas"
+	JaasConfigFile                   = "jaas.conf"
+	ZkSASLJaasFile                   = "zk-jaas.conf"
+	ZkSASLKeytabVolumeName           = "zk-sasl-keytab"
+	ZkSASLKeytabMountPath            = "/etc/solr/zk-sasl/keytab"
+	ZkSASLKeytabFile                 = "zk.keytab"
+	ZkSASLKrb5VolumeName             = "zk-sasl-krb5"
+	ZkSASLKrb5MountPath              = "/etc/solr/zk-sasl/krb5"
+	ZkSASLKrb5File                   = "krb5.conf"
+	ZkTLSTruststoreVolumeName        = "zk-tls-truststore"
+	ZkTLSTruststoreMountPath         = "/etc/solr/zk-tls/truststore"
+	ZkTLSTruststoreFile              = "truststore.p12"
+	ZkTLSKeystoreVolumeName          = "zk-tls-keystore"
+	ZkTLSKeystoreMountPath           = "/etc/solr/zk-tls/keystore"
+	ZkTLSKeystoreFile                = "keystore.p12"
+
+	// The placeholders for the Zookeeper SASL credentials in the generated JAAS config, rendered by the setup initContainer
+	zkSaslUsernamePlaceholder = "${zkSaslUsername}"
+	zkSaslPasswordPlaceholder = "${zkSaslPassword}"
+
+	// The emptyDir volumes that are mounted when the Solr containers have a read-only root filesystem
+	SolrTmpVolumeName        = "tmp"
+	SolrLogsVolumeName       = "solr-logs"
+	SolrServerLogsVolumeName = "solr-server-logs"
+	SolrServerTmpVolumeName  = "solr-server-tmp"
+	SolrLogsDir              = "/var/solr/logs"
+	DefaultLogXmlPath        = "/opt/solr/server/resources/log4j2.xml"
 
 	DefaultStatefulSetPodManagementPolicy = appsv1.ParallelPodManagement
+
+	// Variables that can be used in the custom labels and annotations of Solr pods, e.g. "$(POD_NAME).example.com"
+	PodTemplateVarSolrCloudName = "SOLR_CLOUD_NAME"
+	PodTemplateVarNamespace     = "NAMESPACE"
+	PodTemplateVarPodName       = "POD_NAME"
+	PodTemplateVarPodOrdinal    = "POD_ORDINAL"
+	PodTemplateVarZone          = "ZONE"
+)
+
+const (
+	// The number of seconds between SOLR_STOP_WAIT and the terminationGracePeriodSeconds of Solr pods.
+	// Solr can take longer than SOLR_STOP_WAIT to stop, so Kubernetes must wait a bit longer before killing the pod.
+	solrStopWaitBuffer = int64(5)
 )
 
+var (
+	podTemplateVarRegex = regexp.MustCompile(`\$\(([A-Z_]+)\)`)
+
+	// These variables have a different value for each pod, so they cannot be resolved in the StatefulSet pod template
+	perPodTemplateVars = []string{PodTemplateVarPodName, PodTemplateVarPodOrdinal, PodTemplateVarZone}
+)
+
+// StatefulSetInputsHash returns a hash of all inputs that the SolrCloud's StatefulSet is generated from.
+// If the hash has not changed, then GenerateStatefulSet will produce the same StatefulSet, and it does not need to be re-generated.
+func StatefulSetInputsHash(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, hostNameIPs map[string]string, reconcileConfigInfo map[string]string, tls *TLSCerts) (string, error) {
+	inputs := struct {
+		Labels              map[string]string
+		Annotations         map[string]string
+		Spec                solr.SolrCloudSpec
+		ZookeeperConnection solr.ZookeeperConnectionInfo
+		HostNameIPs         map[string]string
+		ReconcileConfigInfo map[string]string
+		TLS                 *TLSCerts
+		FIPSMode            bool
+		ImagePullSecrets    []corev1.LocalObjectReference
+	}{
+		Labels:              solrCloud.Labels,
+		Annotations:         solrCloud.Annotations,
+		Spec:                solrCloud.Spec,
+		ZookeeperConnection: solrCloudStatus.ZookeeperConnectionInfo,
+		HostNameIPs:         hostNameIPs,
+		ReconcileConfigInfo: reconcileConfigInfo,
+		TLS:                 tls,
+		FIPSMode:            fipsMode,
+		ImagePullSecrets:    defaultImagePullSecrets,
+	}
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
 // GenerateStatefulSet returns a new appsv1.StatefulSet pointer generated for the SolrCloud instance
 // object: SolrCloud instance
 // replicas: the number of replicas for the SolrCloud instance
 // storage: the size of the storage for the SolrCloud instance (e.g. 100Gi)
 // zkConnectionString: the connectionString of the ZK instance to connect to
 func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, hostNameIPs map[string]string, reconcileConfigInfo map[string]string, tls *TLSCerts) *appsv1.StatefulSet {
-	terminationGracePeriod := int64(60)
+	terminationGracePeriod := SolrTerminationGracePeriod(solrCloud)
 	solrPodPort := solrCloud.Spec.SolrAddressability.PodPort
 	fsGroup := int64(DefaultSolrGroup)
 
-	probeScheme := corev1.URISchemeHTTP
-	if tls != nil {
-		probeScheme = corev1.URISchemeHTTPS
-	}
-
-	defaultProbeTimeout := int32(1)
-	defaultHandler := corev1.Handler{
-		HTTPGet: &corev1.HTTPGetAction{
-			Scheme: probeScheme,
-			Path:   "/solr" + DefaultProbePath,
-			Port:   intstr.FromInt(solrPodPort),
-		},
-	}
-
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	selectorLabels := solrCloud.SharedLabels()
 
 	labels["technology"] = solr.SolrTechnologyLabel
@@ -104,22 +194,16 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		labels = MergeLabelsOrAnnotations(labels, customSSOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customSSOptions.Annotations)
 	}
+	annotations = withPropagatedAnnotations(solrCloud, annotations)
 
 	customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
 	var podAnnotations map[string]string
 	if nil != customPodOptions {
-		podLabels = MergeLabelsOrAnnotations(podLabels, customPodOptions.Labels)
-		podAnnotations = customPodOptions.Annotations
-
-		if customPodOptions.TerminationGracePeriodSeconds != nil {
-			terminationGracePeriod = *customPodOptions.TerminationGracePeriodSeconds
-		}
+		// Labels and annotations with per-pod variables are set on each pod by the Solr Operator, rather than in the template
+		podLabels = MergeLabelsOrAnnotations(podLabels, resolveSolrCloudTemplateVars(solrCloud, customPodOptions.Labels))
+		podAnnotations = resolveSolrCloudTemplateVars(solrCloud, customPodOptions.Annotations)
 	}
 
-	// Keep track of the SolrOpts that the Solr Operator needs to set
-	// These will be added to the SolrOpts given by the user.
-	allSolrOpts := []string{"-DhostPort=$(SOLR_NODE_PORT)"}
-
 	// Volumes & Mounts
 	solrVolumes := []corev1.Volume{
 		{
@@ -141,47 +225,11 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		},
 	}
 
-	solrDataVolumeName := "data"
 	volumeMounts := []corev1.VolumeMount{{Name: solrDataVolumeName, MountPath: "/var/solr/data"}}
 
 	var pvcs []corev1.PersistentVolumeClaim
 	if solrCloud.UsesPersistentStorage() {
-		pvc := solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.DeepCopy()
-
-		// Set the default name of the pvc
-		if pvc.ObjectMeta.Name == "" {
-			pvc.ObjectMeta.Name = solrDataVolumeName
-		}
-
-		// Set some defaults in the PVC Spec
-		if len(pvc.Spec.AccessModes) == 0 {
-			pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
-			}
-		}
-		if pvc.Spec.VolumeMode == nil {
-			temp := corev1.PersistentVolumeFilesystem
-			pvc.Spec.VolumeMode = &temp
-		}
-
-		//  Add internally-used labels.
-		internalLabels := map[string]string{
-			SolrPVCTechnologyLabel: SolrCloudPVCTechnology,
-			SolrPVCStorageLabel:    SolrCloudPVCDataStorage,
-			SolrPVCInstanceLabel:   solrCloud.Name,
-		}
-		pvc.ObjectMeta.Labels = MergeLabelsOrAnnotations(internalLabels, pvc.ObjectMeta.Labels)
-
-		pvcs = []corev1.PersistentVolumeClaim{
-			{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        pvc.ObjectMeta.Name,
-					Labels:      pvc.ObjectMeta.Labels,
-					Annotations: pvc.ObjectMeta.Annotations,
-				},
-				Spec: pvc.Spec,
-			},
-		}
+		pvcs = []corev1.PersistentVolumeClaim{GenerateDataPVCTemplate(solrCloud)}
 	} else {
 		ephemeralVolume := corev1.Volume{
 			Name:         solrDataVolumeName,
@@ -253,90 +301,20 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		}
 	}
 
-	solrHostName := solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)")
-	solrAdressingPort := solrCloud.NodePort()
-
-	// Solr can take longer than SOLR_STOP_WAIT to run solr stop, give it a few extra seconds before forcefully killing the pod.
-	solrStopWait := terminationGracePeriod - 5
-	if solrStopWait < 0 {
-		solrStopWait = 0
-	}
-
-	// Environment Variables
-	envVars := []corev1.EnvVar{
-		{
-			Name:  "SOLR_JAVA_MEM",
-			Value: solrCloud.Spec.SolrJavaMem,
-		},
-		{
-			Name:  "SOLR_HOME",
-			Value: "/var/solr/data",
-		},
-		{
-			// This is the port that jetty will listen on
-			Name:  "SOLR_PORT",
-			Value: strconv.Itoa(solrPodPort),
-		},
-		{
-			// This is the port that the Solr Node will advertise itself as listening on in live_nodes
-			Name:  "SOLR_NODE_PORT",
-			Value: strconv.Itoa(solrAdressingPort),
-		},
-		{
-			Name: "POD_HOSTNAME",
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath:  "metadata.name",
-					APIVersion: "v1",
-				},
-			},
-		},
-		{
-			Name:  "SOLR_HOST",
-			Value: solrHostName,
-		},
-		{
-			Name:  "SOLR_LOG_LEVEL",
-			Value: solrCloud.Spec.SolrLogLevel,
-		},
-		{
-			Name:  "GC_TUNE",
-			Value: solrCloud.Spec.SolrGCTune,
-		},
-		{
-			Name:  "SOLR_STOP_WAIT",
-			Value: strconv.FormatInt(solrStopWait, 10),
-		},
-	}
-
-	// Add all necessary information for connection to Zookeeper
-	zkEnvVars, zkSolrOpt, hasChroot := createZkConnectionEnvVars(solrCloud, solrCloudStatus)
-	if zkSolrOpt != "" {
-		allSolrOpts = append(allSolrOpts, zkSolrOpt)
-	}
-	envVars = append(envVars, zkEnvVars...)
-
-	// Only have a postStart command to create the chRoot, if it is not '/' (which does not need to be created)
-	var postStart *corev1.Handler
-	if hasChroot {
-		postStart = &corev1.Handler{
-			Exec: &corev1.ExecAction{
-				Command: []string{"sh", "-c", "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}"},
-			},
-		}
-	}
-
-	// Default preStop hook
-	preStop := &corev1.Handler{
-		Exec: &corev1.ExecAction{
-			Command: []string{"solr", "stop", "-p", strconv.Itoa(solrPodPort)},
-		},
-	}
-
-	// Add Custom EnvironmentVariables to the solr container
-	if nil != customPodOptions {
-		envVars = append(envVars, customPodOptions.EnvVariables...)
+	// Mount the JAAS config, if provided, so that Solr can authenticate to Zookeeper via SASL
+	if jaasVolume, jaasMount, _ := jaasConfigVolume(solrCloud); jaasVolume != nil {
+		solrVolumes = append(solrVolumes, *jaasVolume)
+		volumeMounts = append(volumeMounts, *jaasMount)
 	}
+	saslVolumes, saslVolumeMounts := zkSASLVolumes(solrCloud)
+	solrVolumes = append(solrVolumes, saslVolumes...)
+	volumeMounts = append(volumeMounts, saslVolumeMounts...)
+	zkTLSVols, zkTLSVolumeMounts := zkTLSVolumes(solrCloud)
+	solrVolumes = append(solrVolumes, zkTLSVols...)
+	volumeMounts = append(volumeMounts, zkTLSVolumeMounts...)
+	kerberosVols, kerberosVolumeMounts := kerberosVolumes(solrCloud)
+	solrVolumes = append(solrVolumes, kerberosVols...)
+	volumeMounts = append(volumeMounts, kerberosVolumeMounts...)
 
 	// Did the user provide a custom log config?
 	if reconcileConfigInfo[LogXmlFile] != "" {
@@ -349,25 +327,45 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 
 		// cannot use /var/solr as a mountPath, so mount the custom log config
 		// in a sub-dir named after the user-provided ConfigMap
-		volMount, envVar, newVolume := setupVolumeMountForUserProvidedConfigMapEntry(reconcileConfigInfo, LogXmlFile, solrVolumes, "LOG4J_PROPS")
+		volMount, _, newVolume := setupVolumeMountForUserProvidedConfigMapEntry(reconcileConfigInfo, LogXmlFile, solrVolumes, "LOG4J_PROPS")
 		volumeMounts = append(volumeMounts, *volMount)
-		envVars = append(envVars, *envVar)
 		if newVolume != nil {
 			solrVolumes = append(solrVolumes, *newVolume)
 		}
 	}
 
-	if (tls != nil && tls.ServerConfig != nil && tls.ServerConfig.Options.ClientAuth != solr.None) || (solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.ProbesRequireAuth) {
-		probeCommand, vol, volMount := configureSecureProbeCommand(solrCloud, defaultHandler.HTTPGet)
-		if vol != nil {
-			solrVolumes = append(solrVolumes, *vol)
-		}
-		if volMount != nil {
-			volumeMounts = append(volumeMounts, *volMount)
+	// Mount the additional files from user-provided ConfigMaps, and track their content so that the pods restart when they change
+	for _, file := range solrCloud.Spec.CustomSolrKubeOptions.ConfigMapFiles {
+		if fileMd5 := reconcileConfigInfo[ConfigMapFileMd5AnnotationPrefix+file.Name]; fileMd5 != "" {
+			if podAnnotations == nil {
+				podAnnotations = make(map[string]string, 1)
+			}
+			podAnnotations[ConfigMapFileMd5AnnotationPrefix+file.Name] = fileMd5
 		}
-		// reset the defaultHandler for the probes to invoke the SolrCLI api action instead of HTTP
-		defaultHandler = corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"sh", "-c", probeCommand}}}
-		defaultProbeTimeout = 5
+		solrVolumes = append(solrVolumes, corev1.Volume{
+			Name: ConfigMapFileVolumePrefix + file.Name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: file.ConfigMap},
+					Items:                []corev1.KeyToPath{{Key: file.Key, Path: file.Key}},
+					DefaultMode:          &PublicReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      ConfigMapFileVolumePrefix + file.Name,
+			MountPath: file.MountPath,
+			SubPath:   file.Key,
+			ReadOnly:  true,
+		})
+	}
+
+	probes, probeVolume, probeVolumeMount := GenerateSolrProbes(solrCloud, tls)
+	if probeVolume != nil {
+		solrVolumes = append(solrVolumes, *probeVolume)
+	}
+	if probeVolumeMount != nil {
+		volumeMounts = append(volumeMounts, *probeVolumeMount)
 	}
 
 	// track the MD5 of the custom solr.xml in the pod spec annotations,
@@ -379,16 +377,14 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		podAnnotations[SolrXmlMd5Annotation] = reconcileConfigInfo[SolrXmlMd5Annotation]
 	}
 
-	if solrCloud.Spec.SolrOpts != "" {
-		allSolrOpts = append(allSolrOpts, solrCloud.Spec.SolrOpts)
+	// track the hash of the backup repository credentials, since Solr only reads them on startup
+	if reconcileConfigInfo[BackupRepoCredentialsAnnotation] != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[BackupRepoCredentialsAnnotation] = reconcileConfigInfo[BackupRepoCredentialsAnnotation]
 	}
 
-	// Add SOLR_OPTS last, so that it can use values from all of the other ENV_VARS
-	envVars = append(envVars, corev1.EnvVar{
-		Name:  "SOLR_OPTS",
-		Value: strings.Join(allSolrOpts, " "),
-	})
-
 	initContainers := generateSolrSetupInitContainers(solrCloud, solrCloudStatus, solrDataVolumeName, reconcileConfigInfo)
 
 	// Add user defined additional init containers
@@ -408,31 +404,26 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 					Protocol:      "TCP",
 				},
 			},
-			LivenessProbe: &corev1.Probe{
-				InitialDelaySeconds: 20,
-				TimeoutSeconds:      defaultProbeTimeout,
-				SuccessThreshold:    1,
-				FailureThreshold:    3,
-				PeriodSeconds:       10,
-				Handler:             defaultHandler,
-			},
-			ReadinessProbe: &corev1.Probe{
-				InitialDelaySeconds: 15,
-				TimeoutSeconds:      defaultProbeTimeout,
-				SuccessThreshold:    1,
-				FailureThreshold:    3,
-				PeriodSeconds:       5,
-				Handler:             defaultHandler,
-			},
-			VolumeMounts: volumeMounts,
-			Env:          envVars,
-			Lifecycle: &corev1.Lifecycle{
-				PostStart: postStart,
-				PreStop:   preStop,
-			},
+			LivenessProbe:  probes.Liveness,
+			StartupProbe:   probes.Startup,
+			ReadinessProbe: probes.Readiness,
+			VolumeMounts:   volumeMounts,
+			Env:            GenerateSolrEnvVars(solrCloud, solrCloudStatus, reconcileConfigInfo),
+			Lifecycle:      GenerateSolrLifecycle(solrCloud, solrCloudStatus),
 		},
 	}
 
+	// Request the podPort on the Kubernetes node, so that Solr pods with conflicting ports are not scheduled on the same node
+	if solrCloud.UsesHostNetwork() {
+		containers[0].Ports[0].HostPort = int32(solrPodPort)
+	}
+
+	// Use a custom entrypoint for Solr, which still gets the environment variables and lifecycle hooks set above
+	if containerOptions := solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions; containerOptions != nil {
+		containers[0].Command = containerOptions.Command
+		containers[0].Args = containerOptions.Args
+	}
+
 	// Add user defined additional sidecar containers
 	if customPodOptions != nil && len(customPodOptions.SidecarContainers) > 0 {
 		containers = append(containers, customPodOptions.SidecarContainers...)
@@ -472,7 +463,7 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      podLabels,
-					Annotations: podAnnotations,
+					Annotations: withPropagatedAnnotations(solrCloud, podAnnotations),
 				},
 
 				Spec: corev1.PodSpec{
@@ -503,7 +494,26 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		)
 	}
 
-	stateful.Spec.Template.Spec.ImagePullSecrets = imagePullSecrets
+	// The BusyBox and ZK setup images are used by the init containers
+	stateful.Spec.Template.Spec.ImagePullSecrets = withImagePullSecrets(imagePullSecrets, solrCloud.Spec.BusyBoxImage, solrCloud.Spec.ZkSetupImage)
+
+	// The Solr Operator takes pods out of service through this readiness gate before deleting them for updates
+	if solrCloud.Spec.UpdateStrategy.UsesServingReadinessGate() {
+		stateful.Spec.Template.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: SolrServingCondition}}
+	}
+
+	stateful.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	if solrCloud.UsesHostNetwork() {
+		stateful.Spec.Template.Spec.HostNetwork = true
+		// Pods in the host network need this policy to resolve Kubernetes service addresses
+		stateful.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+
+	// Keep the Solr pods apart, as requested through the availability presets. A custom affinity replaces the generated one.
+	if solrCloud.Spec.Availability != nil {
+		stateful.Spec.Template.Spec.Affinity = generateAvailabilityAffinity(solrCloud.Spec.Availability, selectorLabels)
+		stateful.Spec.Template.Spec.TopologySpreadConstraints = generateAvailabilityTopologySpreadConstraints(solrCloud.Spec.Availability, selectorLabels)
+	}
 
 	if nil != customPodOptions {
 		solrContainer := &stateful.Spec.Template.Spec.Containers[0]
@@ -524,10 +534,6 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			stateful.Spec.Template.Spec.SecurityContext = customPodOptions.PodSecurityContext
 		}
 
-		if customPodOptions.Lifecycle != nil {
-			solrContainer.Lifecycle = customPodOptions.Lifecycle
-		}
-
 		if customPodOptions.Tolerations != nil {
 			stateful.Spec.Template.Spec.Tolerations = customPodOptions.Tolerations
 		}
@@ -536,109 +542,557 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			stateful.Spec.Template.Spec.NodeSelector = customPodOptions.NodeSelector
 		}
 
-		if customPodOptions.StartupProbe != nil {
-			// Default Solr container does not contain a startupProbe, so copy the livenessProbe
-			baseProbe := solrContainer.LivenessProbe.DeepCopy()
-			// Two options are different by default from the livenessProbe
-			baseProbe.TimeoutSeconds = 30
-			baseProbe.FailureThreshold = 15
-			solrContainer.StartupProbe = customizeProbe(baseProbe, *customPodOptions.StartupProbe)
-		}
-
-		if customPodOptions.LivenessProbe != nil {
-			solrContainer.LivenessProbe = customizeProbe(solrContainer.LivenessProbe, *customPodOptions.LivenessProbe)
-		}
-
-		if customPodOptions.ReadinessProbe != nil {
-			solrContainer.ReadinessProbe = customizeProbe(solrContainer.ReadinessProbe, *customPodOptions.ReadinessProbe)
-		}
-
 		if customPodOptions.PriorityClassName != "" {
 			stateful.Spec.Template.Spec.PriorityClassName = customPodOptions.PriorityClassName
 		}
 	}
 
+	// Every Solr pod in the host network listens on the same podPort, which can only be bound once per node.
+	// The hostPort alone does not stop the scheduler from placing a second Solr pod on a node, if that pod is created before the first one binds the port,
+	// so the pods of the SolrCloud are always kept on separate nodes, even when a custom affinity is given.
+	if solrCloud.UsesHostNetwork() {
+		stateful.Spec.Template.Spec.Affinity = withRequiredNodeAntiAffinity(stateful.Spec.Template.Spec.Affinity, selectorLabels)
+	}
+
+	// Spot Node scheduling is added on top of the custom or generated affinity and tolerations
+	if solrCloud.Spec.SpotNodes != nil {
+		stateful.Spec.Template.Spec.Affinity = withSpotNodeAffinity(stateful.Spec.Template.Spec.Affinity, solrCloud.Spec.SpotNodes)
+		stateful.Spec.Template.Spec.Tolerations = append(append([]corev1.Toleration{}, stateful.Spec.Template.Spec.Tolerations...), solrCloud.Spec.SpotNodes.Tolerations...)
+	}
+
 	// Enrich the StatefulSet config to enable TLS on Solr pods if needed
 	if tls != nil {
 		tls.enableTLSOnSolrCloudStatefulSet(stateful)
 	}
 
+	// Set after TLS is enabled, since that can add more init containers
+	setDefaultInitContainerResources(&stateful.Spec.Template.Spec, customPodOptions)
+	if solrCloud.Spec.PodSecurityProfile == solr.RestrictedPodSecurityProfile {
+		applyRestrictedPodSecurity(&stateful.Spec.Template.Spec, customPodOptions)
+	}
+	if solrCloud.Spec.ReadOnlyRootFilesystem {
+		applyReadOnlyRootFilesystem(&stateful.Spec.Template.Spec, customPodOptions)
+	}
+
 	return stateful
 }
 
-func generateSolrSetupInitContainers(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, solrDataVolumeName string, reconcileConfigInfo map[string]string) (containers []corev1.Container) {
-	// The setup of the solr.xml will always be necessary
-	volumeMounts := []corev1.VolumeMount{
-		{
-			Name:      "solr-xml",
-			MountPath: "/tmp",
-		},
-		{
-			Name:      solrDataVolumeName,
-			MountPath: "/tmp-config",
-		},
-	}
-	setupCommands := []string{"cp /tmp/solr.xml /tmp-config/solr.xml"}
+// applyRestrictedPodSecurity makes the Solr pod conform to the restricted Pod Security Standard.
+// Settings that are missing from a custom podSecurityContext are added, and the containers provided by the user are left as they are.
+func applyRestrictedPodSecurity(podSpec *corev1.PodSpec, customPodOptions *solr.PodOptions) {
+	solrUser := int64(DefaultSolrUser)
+	solrGroup := int64(DefaultSolrGroup)
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
 
-	// Add prep for backup-restore Repositories
-	// This entails setting the correct permissions for the directory
-	for _, repo := range solrCloud.Spec.BackupRepositories {
-		if IsRepoManaged(&repo) {
-			_, volumeMount := RepoVolumeSourceAndMount(&repo, solrCloud.Name)
-			volumeMounts = append(volumeMounts, *volumeMount)
+	// The podSecurityContext may be shared with the SolrCloud spec, so it is copied before it is changed
+	podSecurityContext := podSpec.SecurityContext.DeepCopy()
+	if podSecurityContext == nil {
+		podSecurityContext = &corev1.PodSecurityContext{}
+	}
+	if podSecurityContext.RunAsNonRoot == nil {
+		podSecurityContext.RunAsNonRoot = &runAsNonRoot
+	}
+	if podSecurityContext.RunAsUser == nil {
+		podSecurityContext.RunAsUser = &solrUser
+	}
+	if podSecurityContext.RunAsGroup == nil {
+		podSecurityContext.RunAsGroup = &solrGroup
+	}
+	if podSecurityContext.FSGroup == nil {
+		podSecurityContext.FSGroup = &solrGroup
+	}
+	if podSecurityContext.FSGroupChangePolicy == nil {
+		// Only change the ownership of large data volumes when needed, since this happens every time the volume is mounted
+		fsGroupChangePolicy := corev1.FSGroupChangeOnRootMismatch
+		podSecurityContext.FSGroupChangePolicy = &fsGroupChangePolicy
+	}
+	if len(podSecurityContext.SupplementalGroups) == 0 {
+		// Volumes that do not support an fsGroup, such as NFS, can be made writable for this group
+		podSecurityContext.SupplementalGroups = []int64{solrGroup}
+	}
+	if podSecurityContext.SeccompProfile == nil {
+		podSecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	podSpec.SecurityContext = podSecurityContext
 
-			setupCommands = append(setupCommands, fmt.Sprintf(
-				"chown -R %d:%d %s",
-				DefaultSolrUser,
-				DefaultSolrGroup,
-				volumeMount.MountPath))
+	for _, container := range generatedContainers(podSpec, customPodOptions) {
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		if container.SecurityContext.RunAsNonRoot == nil {
+			container.SecurityContext.RunAsNonRoot = &runAsNonRoot
+		}
+		if container.SecurityContext.AllowPrivilegeEscalation == nil {
+			container.SecurityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+		}
+		if container.SecurityContext.Capabilities == nil {
+			container.SecurityContext.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
 		}
 	}
+}
 
-	volumePrepInitContainer := corev1.Container{
-		Name:            "cp-solr-xml",
-		Image:           solrCloud.Spec.BusyBoxImage.ToImageName(),
-		ImagePullPolicy: solrCloud.Spec.BusyBoxImage.PullPolicy,
-		Command:         []string{"sh", "-c", strings.Join(setupCommands, " && ")},
-		VolumeMounts:    volumeMounts,
+// applyReadOnlyRootFilesystem runs the containers that the Solr Operator generates with a read-only root filesystem.
+// An emptyDir volume is mounted for each of the paths that Solr writes to outside of its data directory,
+// and /tmp is mounted into the init containers as well. The containers provided by the user are left as they are.
+func applyReadOnlyRootFilesystem(podSpec *corev1.PodSpec, customPodOptions *solr.PodOptions) {
+	readOnlyRootFilesystem := true
+	for _, name := range []string{SolrTmpVolumeName, SolrLogsVolumeName, SolrServerLogsVolumeName, SolrServerTmpVolumeName} {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
 	}
 
-	containers = append(containers, volumePrepInitContainer)
+	for _, container := range generatedContainers(podSpec, customPodOptions) {
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		if container.SecurityContext.ReadOnlyRootFilesystem == nil {
+			container.SecurityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+		}
 
-	if hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, reconcileConfigInfo); hasZKSetupContainer {
-		containers = append(containers, zkSetupContainer)
+		// Some init containers already mount a volume at /tmp, such as the one that copies the solr.xml
+		hasTmp := false
+		for _, mount := range container.VolumeMounts {
+			hasTmp = hasTmp || mount.MountPath == "/tmp"
+		}
+		if !hasTmp {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: SolrTmpVolumeName, MountPath: "/tmp"})
+		}
 	}
 
-	return containers
+	// The Solr container is always the first container
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: SolrLogsVolumeName, MountPath: SolrLogsDir},
+		corev1.VolumeMount{Name: SolrServerLogsVolumeName, MountPath: "/opt/solr/server/logs"},
+		corev1.VolumeMount{Name: SolrServerTmpVolumeName, MountPath: "/opt/solr/server/tmp"},
+	)
 }
 
-func GenerateBackupRepositoriesForSolrXml(backupRepos []solr.SolrBackupRepository) string {
-	if len(backupRepos) == 0 {
-		return ""
+// generatedContainers returns the containers and init containers of the pod that were generated by the Solr Operator,
+// leaving out the sidecarContainers and initContainers provided in the podOptions.
+func generatedContainers(podSpec *corev1.PodSpec, customPodOptions *solr.PodOptions) (containers []*corev1.Container) {
+	customContainers := map[string]bool{}
+	if customPodOptions != nil {
+		for _, container := range customPodOptions.InitContainers {
+			customContainers[container.Name] = true
+		}
+		for _, container := range customPodOptions.SidecarContainers {
+			customContainers[container.Name] = true
+		}
 	}
-	libs := make(map[string]bool, 0)
-	repoXMLs := make([]string, len(backupRepos))
-
-	for i, repo := range backupRepos {
-		for _, lib := range AdditionalRepoLibs(&repo) {
-			libs[lib] = true
+	for i := range podSpec.InitContainers {
+		if !customContainers[podSpec.InitContainers[i].Name] {
+			containers = append(containers, &podSpec.InitContainers[i])
 		}
-		repoXMLs[i] = RepoXML(&repo)
 	}
-	sort.Strings(repoXMLs)
-
-	libXml := ""
-	if len(libs) > 0 {
-		libList := make([]string, 0)
-		for lib := range libs {
-			libList = append(libList, lib)
+	for i := range podSpec.Containers {
+		if !customContainers[podSpec.Containers[i].Name] {
+			containers = append(containers, &podSpec.Containers[i])
 		}
-		sort.Strings(libList)
-		libXml = fmt.Sprintf("<str name=\"sharedLib\">%s</str>", strings.Join(libList, ","))
 	}
+	return containers
+}
 
-	return fmt.Sprintf(
-		`%s 
+// SolrTerminationGracePeriod returns the terminationGracePeriodSeconds of the Solr pods.
+// Unless one is provided in the podOptions, it gives Solr enough time to stop gracefully with a custom SOLR_STOP_WAIT.
+// The lameduck period is added to the time that Solr is given to stop.
+func SolrTerminationGracePeriod(solrCloud *solr.SolrCloud) int64 {
+	terminationGracePeriod := int64(60) + solrLameduckSeconds(solrCloud)
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions {
+		if customPodOptions.TerminationGracePeriodSeconds != nil {
+			terminationGracePeriod = *customPodOptions.TerminationGracePeriodSeconds
+		} else if solrStopWait, hasStopWait, err := customSolrStopWait(solrCloud); hasStopWait && err == nil {
+			// Give Kubernetes enough time to let Solr stop gracefully, using the time that the user has given Solr
+			terminationGracePeriod = solrStopWait + solrStopWaitBuffer + solrLameduckSeconds(solrCloud)
+		}
+	}
+	return terminationGracePeriod
+}
+
+// solrLameduckSeconds returns the number of seconds that the preStop hook waits before stopping Solr.
+// There is no lameduck period when a custom lifecycle is provided, since the preStop hook is not generated.
+func solrLameduckSeconds(solrCloud *solr.SolrCloud) int64 {
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions && customPodOptions.Lifecycle != nil {
+		return 0
+	}
+	return int64(solrCloud.Spec.LameduckSeconds)
+}
+
+// SolrProbes are the probes of the Solr container
+type SolrProbes struct {
+	Startup   *corev1.Probe
+	Liveness  *corev1.Probe
+	Readiness *corev1.Probe
+}
+
+// GenerateSolrProbes returns the probes of the Solr container, with the customizations from the podOptions applied.
+// If the probes cannot use plain HTTP requests, because Solr requires client certificates or authentication,
+// then the volume and mount that the probe command needs are returned as well.
+func GenerateSolrProbes(solrCloud *solr.SolrCloud, tls *TLSCerts) (probes SolrProbes, volume *corev1.Volume, volumeMount *corev1.VolumeMount) {
+	probeScheme := corev1.URISchemeHTTP
+	if tls != nil {
+		probeScheme = corev1.URISchemeHTTPS
+	}
+
+	defaultProbeTimeout := int32(1)
+	defaultHandler := corev1.Handler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Scheme: probeScheme,
+			Path:   "/solr" + DefaultProbePath,
+			Port:   intstr.FromInt(solrCloud.Spec.SolrAddressability.PodPort),
+		},
+	}
+
+	if (tls != nil && tls.ServerConfig != nil && tls.ServerConfig.Options.ClientAuth != solr.None) || (solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.ProbesRequireAuth) || solrCloud.UsesKerberos() {
+		var probeCommand string
+		probeCommand, volume, volumeMount = configureSecureProbeCommand(solrCloud, defaultHandler.HTTPGet)
+		// reset the defaultHandler for the probes to invoke the SolrCLI api action instead of HTTP
+		defaultHandler = corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"sh", "-c", probeCommand}}}
+		defaultProbeTimeout = 5
+	}
+
+	probes = SolrProbes{
+		// Holds off the liveness and readiness probes until Solr has loaded its cores, which can take a long time for large indexes
+		Startup: &corev1.Probe{
+			InitialDelaySeconds: 20,
+			TimeoutSeconds:      solrCloud.Spec.Probes.Startup.TimeoutSeconds,
+			SuccessThreshold:    1,
+			FailureThreshold:    solrCloud.Spec.Probes.Startup.FailureThreshold,
+			PeriodSeconds:       solrCloud.Spec.Probes.Startup.PeriodSeconds,
+			Handler:             defaultHandler,
+		},
+		Liveness: &corev1.Probe{
+			InitialDelaySeconds: 20,
+			TimeoutSeconds:      defaultProbeTimeout,
+			SuccessThreshold:    1,
+			FailureThreshold:    3,
+			PeriodSeconds:       10,
+			Handler:             defaultHandler,
+		},
+		Readiness: &corev1.Probe{
+			InitialDelaySeconds: 15,
+			TimeoutSeconds:      defaultProbeTimeout,
+			SuccessThreshold:    1,
+			FailureThreshold:    3,
+			PeriodSeconds:       5,
+			Handler:             defaultHandler,
+		},
+	}
+
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions {
+		if customPodOptions.StartupProbe != nil {
+			probes.Startup = customizeProbe(probes.Startup, *customPodOptions.StartupProbe)
+		}
+
+		if customPodOptions.LivenessProbe != nil {
+			probes.Liveness = customizeProbe(probes.Liveness, *customPodOptions.LivenessProbe)
+		}
+
+		if customPodOptions.ReadinessProbe != nil {
+			probes.Readiness = customizeProbe(probes.Readiness, *customPodOptions.ReadinessProbe)
+		}
+	}
+	return probes, volume, volumeMount
+}
+
+// GenerateSolrLifecycle returns the lifecycle hooks of the Solr container, unless custom hooks are provided in the podOptions.
+// The postStart hook creates the ZK chRoot, and the preStop hook stops Solr gracefully.
+func GenerateSolrLifecycle(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus) *corev1.Lifecycle {
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions && customPodOptions.Lifecycle != nil {
+		return customPodOptions.Lifecycle
+	}
+
+	// Only have a postStart command to create the chRoot, if it is not '/' (which does not need to be created)
+	var postStart *corev1.Handler
+	if _, _, hasChroot := createZkConnectionEnvVars(solrCloud, solrCloudStatus); hasChroot {
+		postStart = &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sh", "-c", "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}"},
+			},
+		}
+	}
+
+	// Default preStop hook
+	preStop := &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"solr", "stop", "-p", strconv.Itoa(solrCloud.Spec.SolrAddressability.PodPort)},
+		},
+	}
+	// Keep serving requests while the removal of the pod from the service endpoints reaches the ingress controllers and kube-proxy
+	if lameduckSeconds := solrLameduckSeconds(solrCloud); lameduckSeconds > 0 {
+		preStop.Exec.Command = []string{"sh", "-c", fmt.Sprintf("sleep %d; solr stop -p %d", lameduckSeconds, solrCloud.Spec.SolrAddressability.PodPort)}
+	}
+
+	return &corev1.Lifecycle{
+		PostStart: postStart,
+		PreStop:   preStop,
+	}
+}
+
+// GenerateSolrEnvVars returns the environment variables of the Solr container, ending with the SOLR_OPTS.
+// The environment variables for TLS are not included, these are added when TLS is enabled on the StatefulSet.
+func GenerateSolrEnvVars(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, reconcileConfigInfo map[string]string) (envVars []corev1.EnvVar) {
+	// Keep track of the SolrOpts that the Solr Operator needs to set
+	// These will be added to the SolrOpts given by the user.
+	allSolrOpts := []string{"-DhostPort=$(SOLR_NODE_PORT)"}
+
+	solrHostName := solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)")
+	solrAdressingPort := solrCloud.AdvertisedNodePort()
+
+	// When using the host network, Solr nodes advertise the address of the Kubernetes node they are running on
+	var nodeAddressEnvVars []corev1.EnvVar
+	if solrCloud.UsesHostNetwork() {
+		nodeAddressField := "status.hostIP"
+		if solrCloud.Spec.SolrAddressability.HostNetwork.AdvertisedAddress == solr.HostNetworkNodeName {
+			nodeAddressField = "spec.nodeName"
+		}
+		nodeAddressEnvVars = append(nodeAddressEnvVars, corev1.EnvVar{
+			Name: "NODE_ADDRESS",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath:  nodeAddressField,
+					APIVersion: "v1",
+				},
+			},
+		})
+		solrHostName = "$(NODE_ADDRESS)"
+	} else if solrCloud.UsesAdvertisedHostTemplate() {
+		// The advertisedHostTemplate can use the IP of the pod
+		nodeAddressEnvVars = append(nodeAddressEnvVars, corev1.EnvVar{
+			Name: "POD_IP",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath:  "status.podIP",
+					APIVersion: "v1",
+				},
+			},
+		})
+	}
+
+	// Solr can take longer than SOLR_STOP_WAIT to run solr stop, give it a few extra seconds before forcefully killing the pod.
+	// The lameduck period, which is spent before solr stop is run, is not part of the time Solr has to stop.
+	solrStopWait := SolrTerminationGracePeriod(solrCloud) - solrStopWaitBuffer - solrLameduckSeconds(solrCloud)
+	if solrStopWait < 0 {
+		solrStopWait = 0
+	}
+
+	// Environment Variables
+	envVars = []corev1.EnvVar{
+		{
+			Name:  "SOLR_JAVA_MEM",
+			Value: solrCloud.Spec.SolrJavaMem,
+		},
+		{
+			Name:  "SOLR_HOME",
+			Value: "/var/solr/data",
+		},
+		{
+			// This is the port that jetty will listen on
+			Name:  "SOLR_PORT",
+			Value: strconv.Itoa(solrCloud.Spec.SolrAddressability.PodPort),
+		},
+		{
+			// This is the port that the Solr Node will advertise itself as listening on in live_nodes
+			Name:  "SOLR_NODE_PORT",
+			Value: strconv.Itoa(solrAdressingPort),
+		},
+		{
+			Name: "POD_HOSTNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath:  "metadata.name",
+					APIVersion: "v1",
+				},
+			},
+		},
+	}
+	envVars = append(envVars, nodeAddressEnvVars...)
+	envVars = append(envVars,
+		corev1.EnvVar{
+			Name:  "SOLR_HOST",
+			Value: solrHostName,
+		},
+		corev1.EnvVar{
+			Name:  "SOLR_LOG_LEVEL",
+			Value: solrCloud.Spec.SolrLogLevel,
+		},
+		corev1.EnvVar{
+			Name:  "GC_TUNE",
+			Value: solrCloud.Spec.SolrGCTune,
+		},
+		corev1.EnvVar{
+			Name:  "SOLR_STOP_WAIT",
+			Value: strconv.FormatInt(solrStopWait, 10),
+		},
+	)
+
+	// Add all necessary information for connection to Zookeeper
+	zkEnvVars, zkSolrOpt, _ := createZkConnectionEnvVars(solrCloud, solrCloudStatus)
+	if zkSolrOpt != "" {
+		allSolrOpts = append(allSolrOpts, zkSolrOpt)
+	}
+	envVars = append(envVars, zkEnvVars...)
+
+	if _, _, jaasSolrOpt := jaasConfigVolume(solrCloud); jaasSolrOpt != "" {
+		allSolrOpts = append(allSolrOpts, jaasSolrOpt)
+	}
+	envVars = append(envVars, kerberosEnvVars(solrCloud)...)
+
+	// Add the environment variables that the cloud SDKs of backup repositories need, such as for workload identity.
+	// These are process-wide, so only the first repository to set a given variable will take effect.
+	repoEnvVarNames := map[string]bool{}
+	for _, repo := range solrCloud.Spec.BackupRepositories {
+		for _, envVar := range RepoEnvVars(&repo) {
+			if !repoEnvVarNames[envVar.Name] {
+				repoEnvVarNames[envVar.Name] = true
+				envVars = append(envVars, envVar)
+			}
+		}
+	}
+
+	// Set the Java Security Manager and the paths and URLs that Solr may access, if given.
+	// These are validated to not also be set by the user, through the SOLR_OPTS or custom environment variables.
+	if restrictions := solrCloud.Spec.RuntimeRestrictions; restrictions != nil {
+		if restrictions.SecurityManager != nil {
+			envVars = append(envVars, corev1.EnvVar{Name: "SOLR_SECURITY_MANAGER_ENABLED", Value: strconv.FormatBool(*restrictions.SecurityManager)})
+		}
+		if len(restrictions.AllowPaths) > 0 {
+			allSolrOpts = append(allSolrOpts, "-Dsolr.allowPaths="+strings.Join(restrictions.AllowPaths, ","))
+		}
+		if len(restrictions.AllowUrls) > 0 {
+			allSolrOpts = append(allSolrOpts, "-Dsolr.allowUrls="+strings.Join(restrictions.AllowUrls, ","))
+		}
+		if restrictions.DisableAllowUrls {
+			allSolrOpts = append(allSolrOpts, "-Dsolr.disable.allowUrls=true")
+		}
+	}
+
+	// The Solr image copies its default log config into /var/solr on startup, which is not writable with a read-only root filesystem.
+	// The default log config is then used from the Solr distribution instead.
+	if solrCloud.Spec.ReadOnlyRootFilesystem {
+		envVars = append(envVars, corev1.EnvVar{Name: "NO_INIT_VAR_SOLR", Value: "1"})
+		if reconcileConfigInfo[LogXmlFile] == "" {
+			envVars = append(envVars, corev1.EnvVar{Name: "LOG4J_PROPS", Value: DefaultLogXmlPath})
+		}
+	}
+
+	// Add Custom EnvironmentVariables to the solr container
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions {
+		envVars = append(envVars, customPodOptions.EnvVariables...)
+	}
+
+	// Did the user provide a custom log config?
+	if reconcileConfigInfo[LogXmlFile] != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "LOG4J_PROPS", Value: userProvidedConfigMapEntryPath(reconcileConfigInfo, LogXmlFile)})
+	}
+
+	// Add the index directory options before the user-provided SOLR_OPTS, so that they can be overridden
+	allSolrOpts = append(allSolrOpts, indexDirectorySolrOpts(solrCloud)...)
+
+	if solrCloud.Spec.SolrOpts != "" {
+		allSolrOpts = append(allSolrOpts, solrCloud.Spec.SolrOpts)
+	}
+
+	// Add SOLR_OPTS last, so that it can use values from all of the other ENV_VARS
+	envVars = append(envVars, corev1.EnvVar{
+		Name:  "SOLR_OPTS",
+		Value: strings.Join(allSolrOpts, " "),
+	})
+	return envVars
+}
+
+func generateSolrSetupInitContainers(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, solrDataVolumeName string, reconcileConfigInfo map[string]string) (containers []corev1.Container) {
+	// Check the node before anything else, since Solr cannot run reliably on it if the check fails
+	if hasMaxMapCountCheck, maxMapCountCheck := generateMaxMapCountCheckInitContainer(solrCloud); hasMaxMapCountCheck {
+		containers = append(containers, maxMapCountCheck)
+	}
+
+	// The setup of the solr.xml will always be necessary
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "solr-xml",
+			MountPath: "/tmp",
+		},
+		{
+			Name:      solrDataVolumeName,
+			MountPath: "/tmp-config",
+		},
+	}
+	setupCommands := []string{"cp /tmp/solr.xml /tmp-config/solr.xml"}
+	var setupEnvVars []corev1.EnvVar
+
+	// Write the generated JAAS config for SASL authentication to Zookeeper and Kerberos authentication, if one was not provided by the user
+	if jaasConfig := generatedJaasConfig(solrCloud); jaasConfig != "" {
+		setupCommands = append(setupCommands, writeJaasConfigCommand(jaasConfig, "/tmp-config/"+ZkSASLJaasFile))
+		setupEnvVars = append(setupEnvVars, zkSASLCredentialsEnvVars(solrCloud)...)
+	}
+	if jaasConfig := kerberosJaasConfig(solrCloud); jaasConfig != "" {
+		setupCommands = append(setupCommands, fmt.Sprintf("echo '%s' > /tmp-config/%s", jaasConfig, SolrKerberosJaasFile))
+	}
+
+	// Add prep for backup-restore Repositories
+	// This entails setting the correct permissions for the directory.
+	// Non-root containers cannot change the ownership, so the restricted profile relies on the fsGroup and supplementalGroups of the pod instead.
+	for _, repo := range solrCloud.Spec.BackupRepositories {
+		if IsRepoManaged(&repo) && solrCloud.Spec.PodSecurityProfile != solr.RestrictedPodSecurityProfile {
+			_, volumeMount := RepoVolumeSourceAndMount(&repo, solrCloud.Name)
+			volumeMounts = append(volumeMounts, *volumeMount)
+
+			setupCommands = append(setupCommands, fmt.Sprintf(
+				"chown -R %d:%d %s",
+				DefaultSolrUser,
+				DefaultSolrGroup,
+				volumeMount.MountPath))
+		}
+	}
+
+	volumePrepInitContainer := corev1.Container{
+		Name:            "cp-solr-xml",
+		Image:           solrCloud.Spec.BusyBoxImage.ToImageName(),
+		ImagePullPolicy: solrCloud.Spec.BusyBoxImage.PullPolicy,
+		Command:         []string{"sh", "-c", strings.Join(setupCommands, " && ")},
+		Env:             setupEnvVars,
+		VolumeMounts:    volumeMounts,
+	}
+
+	containers = append(containers, volumePrepInitContainer)
+
+	if hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, solrDataVolumeName, reconcileConfigInfo); hasZKSetupContainer {
+		containers = append(containers, zkSetupContainer)
+	}
+
+	return containers
+}
+
+func GenerateBackupRepositoriesForSolrXml(backupRepos []solr.SolrBackupRepository) string {
+	if len(backupRepos) == 0 {
+		return ""
+	}
+	libs := make(map[string]bool, 0)
+	repoXMLs := make([]string, len(backupRepos))
+
+	for i, repo := range backupRepos {
+		for _, lib := range AdditionalRepoLibs(&repo) {
+			libs[lib] = true
+		}
+		repoXMLs[i] = RepoXML(&repo)
+	}
+	sort.Strings(repoXMLs)
+
+	libXml := ""
+	if len(libs) > 0 {
+		libList := make([]string, 0)
+		for lib := range libs {
+			libList = append(libList, lib)
+		}
+		sort.Strings(libList)
+		libXml = fmt.Sprintf("<str name=\"sharedLib\">%s</str>", strings.Join(libList, ","))
+	}
+
+	return fmt.Sprintf(
+		`%s 
 		<backup>
 		%s
 		</backup>`, libXml, strings.Join(repoXMLs, `
@@ -670,7 +1124,7 @@ const DefaultSolrXML = `<?xml version="1.0" encoding="UTF-8" ?>
 // GenerateConfigMap returns a new corev1.ConfigMap pointer generated for the SolrCloud instance solr.xml
 // solrCloud: SolrCloud instance
 func GenerateConfigMap(solrCloud *solr.SolrCloud) *corev1.ConfigMap {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	var annotations map[string]string
 
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions
@@ -702,7 +1156,7 @@ func GenerateSolrXMLString(backupSection string) string {
 // GenerateCommonService returns a new corev1.Service pointer generated for the entire SolrCloud instance
 // solrCloud: SolrCloud instance
 func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	labels["service-type"] = "common"
 
 	selectorLabels := solrCloud.SharedLabels()
@@ -712,7 +1166,7 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 
 	// Add externalDNS annotation if necessary
 	extOpts := solrCloud.Spec.SolrAddressability.External
-	if extOpts != nil && extOpts.Method == solr.ExternalDNS && !extOpts.HideCommon {
+	if extOpts.IsManaged() && extOpts.Method == solr.ExternalDNS && !extOpts.HideCommon {
 		annotations = make(map[string]string, 1)
 		urls := []string{solrCloud.ExternalDnsDomain(extOpts.DomainName)}
 		for _, domain := range extOpts.AdditionalDomainNames {
@@ -726,6 +1180,7 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	annotations = withPropagatedAnnotations(solrCloud, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -744,11 +1199,46 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 	return service
 }
 
+// ExcludeAllPodsFromService removes all endpoints from the given service, by selecting a label that no Solr pod has.
+// This is used to keep client traffic away from a SolrCloud that has not been formed yet.
+func ExcludeAllPodsFromService(service *corev1.Service) {
+	service.Spec.Selector[SolrClusterFormationLabel] = "pending"
+}
+
+// ClusterFormationCondition returns the ClusterFormed condition of the SolrCloud, given the number of Solr nodes that are ready.
+// The SolrCloud is formed once the minReadyNodesForReady are ready, and stays formed until none of its nodes are ready.
+// Nil is returned if the SolrCloud does not require a minimum number of ready nodes.
+func ClusterFormationCondition(solrCloud *solr.SolrCloud, readyReplicas int32) *metav1.Condition {
+	minReady := solrCloud.MinReadyNodesForReady()
+	if minReady <= 0 {
+		return nil
+	}
+	formed := solrCloud.Status.IsClusterFormed()
+	if readyReplicas >= minReady {
+		formed = true
+	} else if readyReplicas == 0 {
+		formed = false
+	}
+	condition := &metav1.Condition{
+		Type:               solr.SolrCloudClusterFormed,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MinReadyNodesReached",
+		Message:            fmt.Sprintf("The %d Solr nodes required to form the cluster have been ready", minReady),
+		ObservedGeneration: solrCloud.Generation,
+	}
+	if !formed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WaitingForMinReadyNodes"
+		condition.Message = fmt.Sprintf("%d of the %d Solr nodes required to form the cluster are ready", readyReplicas, minReady)
+	}
+	return condition
+}
+
 // GenerateHeadlessService returns a new Headless corev1.Service pointer generated for the SolrCloud instance
-// The PublishNotReadyAddresses option is set as true, because we want each pod to be reachable no matter the readiness of the pod.
+// The PublishNotReadyAddresses option defaults to true, because we want each pod to be reachable no matter the readiness of the pod.
 // solrCloud: SolrCloud instance
 func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	labels["service-type"] = "headless"
 
 	selectorLabels := solrCloud.SharedLabels()
@@ -758,7 +1248,7 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 
 	// Add externalDNS annotation if necessary
 	extOpts := solrCloud.Spec.SolrAddressability.External
-	if extOpts != nil && extOpts.Method == solr.ExternalDNS && !extOpts.HideNodes {
+	if extOpts.IsManaged() && extOpts.Method == solr.ExternalDNS && !extOpts.HideNodes {
 		annotations = make(map[string]string, 1)
 		urls := []string{solrCloud.ExternalDnsDomain(extOpts.DomainName)}
 		for _, domain := range extOpts.AdditionalDomainNames {
@@ -772,6 +1262,7 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	annotations = withPropagatedAnnotations(solrCloud, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -786,18 +1277,18 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 			},
 			Selector:                 selectorLabels,
 			ClusterIP:                corev1.ClusterIPNone,
-			PublishNotReadyAddresses: true,
+			PublishNotReadyAddresses: publishNotReadyAddresses(customOptions),
 		},
 	}
 	return service
 }
 
 // GenerateNodeService returns a new External corev1.Service pointer generated for the given Solr Node.
-// The PublishNotReadyAddresses option is set as true, because we want each pod to be reachable no matter the readiness of the pod.
+// The PublishNotReadyAddresses option defaults to true, because we want each pod to be reachable no matter the readiness of the pod.
 // solrCloud: SolrCloud instance
 // nodeName: string node
 func GenerateNodeService(solrCloud *solr.SolrCloud, nodeName string) *corev1.Service {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	labels["service-type"] = "external"
 
 	selectorLabels := solrCloud.SharedLabels()
@@ -811,6 +1302,7 @@ func GenerateNodeService(solrCloud *solr.SolrCloud, nodeName string) *corev1.Ser
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	annotations = withPropagatedAnnotations(solrCloud, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -824,17 +1316,25 @@ func GenerateNodeService(solrCloud *solr.SolrCloud, nodeName string) *corev1.Ser
 			Ports: []corev1.ServicePort{
 				{Name: SolrClientPortName, Port: int32(solrCloud.NodePort()), Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString(SolrClientPortName)},
 			},
-			PublishNotReadyAddresses: true,
+			PublishNotReadyAddresses: publishNotReadyAddresses(customOptions),
 		},
 	}
 	return service
 }
 
+// publishNotReadyAddresses returns whether a headless or node service should publish the addresses of pods that are not ready, true unless overridden by the custom options
+func publishNotReadyAddresses(customOptions *solr.ServiceOptions) bool {
+	if customOptions == nil || customOptions.PublishNotReadyAddresses == nil {
+		return true
+	}
+	return *customOptions.PublishNotReadyAddresses
+}
+
 // GenerateIngress returns a new Ingress pointer generated for the entire SolrCloud, pointing to all instances
 // solrCloud: SolrCloud instance
 // nodeStatuses: []SolrNodeStatus the nodeStatuses
 func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *netv1.Ingress) {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	var annotations map[string]string
 
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.IngressOptions
@@ -842,6 +1342,7 @@ func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *ne
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	annotations = withPropagatedAnnotations(solrCloud, annotations)
 
 	extOpts := solrCloud.Spec.SolrAddressability.External
 
@@ -910,7 +1411,10 @@ func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *ne
 // nodeNames: the names for each of the solr pods
 // domainName: string Domain for the ingress rule to use
 func CreateSolrIngressRules(solrCloud *solr.SolrCloud, nodeNames []string, domainNames []string) (ingressRules []netv1.IngressRule, allHosts []string) {
-	if !solrCloud.Spec.SolrAddressability.External.HideCommon {
+	// Endpoints removed for a maintenance window are not included in the rules
+	maintenance := solrCloud.Spec.SolrAddressability.External.Maintenance
+	now := time.Now()
+	if !solrCloud.Spec.SolrAddressability.External.HideCommon && !(maintenance.IsActive(now) && maintenance.HideCommon) {
 		for _, domainName := range domainNames {
 			rule := CreateCommonIngressRule(solrCloud, domainName)
 			ingressRules = append(ingressRules, rule)
@@ -919,6 +1423,9 @@ func CreateSolrIngressRules(solrCloud *solr.SolrCloud, nodeNames []string, domai
 	}
 	if !solrCloud.Spec.SolrAddressability.External.HideNodes {
 		for _, nodeName := range nodeNames {
+			if maintenance.HidesNode(nodeName, now) {
+				continue
+			}
 			for _, domainName := range domainNames {
 				rule := CreateNodeIngressRule(solrCloud, nodeName, domainName)
 				ingressRules = append(ingressRules, rule)
@@ -987,7 +1494,7 @@ func CreateNodeIngressRule(solrCloud *solr.SolrCloud, nodeName string, domainNam
 }
 
 // TODO: Have this replace the postStart hook for creating the chroot
-func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, reconcileConfigInfo map[string]string) (bool, corev1.Container) {
+func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, solrDataVolumeName string, reconcileConfigInfo map[string]string) (bool, corev1.Container) {
 	allSolrOpts := make([]string, 0)
 
 	// Add all necessary ZK Info
@@ -996,6 +1503,26 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 		allSolrOpts = append(allSolrOpts, zkSolrOpt)
 	}
 
+	// zkcli.sh does not read SOLR_OPTS, so the JAAS config and Zookeeper client settings must also be passed through its own JVM flags
+	zkcliJvmFlags := zkClientSystemProperties(solrCloud)
+	var volumeMounts []corev1.VolumeMount
+	if _, jaasMount, jaasSolrOpt := jaasConfigVolume(solrCloud); jaasMount != nil {
+		volumeMounts = append(volumeMounts, *jaasMount)
+		allSolrOpts = append(allSolrOpts, jaasSolrOpt)
+		zkcliJvmFlags = append([]string{jaasSolrOpt}, zkcliJvmFlags...)
+	}
+	if len(zkcliJvmFlags) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "ZKCLI_JVM_FLAGS", Value: strings.Join(zkcliJvmFlags, " ")})
+	}
+	if solrCloud.Spec.ZookeeperRef.SASL != nil {
+		_, saslVolumeMounts := zkSASLVolumes(solrCloud)
+		volumeMounts = append(volumeMounts, saslVolumeMounts...)
+		// The generated JAAS config is written to the data directory
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: solrDataVolumeName, MountPath: "/var/solr/data"})
+	}
+	_, zkTLSVolumeMounts := zkTLSVolumes(solrCloud)
+	volumeMounts = append(volumeMounts, zkTLSVolumeMounts...)
+
 	if solrCloud.Spec.SolrOpts != "" {
 		allSolrOpts = append(allSolrOpts, solrCloud.Spec.SolrOpts)
 	}
@@ -1003,72 +1530,408 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 	// Add SOLR_OPTS last, so that it can use values from all of the other ENV_VARS
 	if len(allSolrOpts) > 0 {
 		envVars = append(envVars, corev1.EnvVar{
-			Name:  "SOLR_OPTS",
-			Value: strings.Join(allSolrOpts, " "),
+			Name:  "SOLR_OPTS",
+			Value: strings.Join(allSolrOpts, " "),
+		})
+	}
+
+	cmd := ""
+
+	// Solr Nodes use the urlScheme clusterprop to address each other
+	if solrCloud.AdvertisedUrlScheme() == "https" {
+		cmd = setUrlSchemeClusterPropCmd()
+	}
+
+	if reconcileConfigInfo[SecurityJsonFile] != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "SECURITY_JSON", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: solrCloud.SecurityBootstrapSecretName()},
+				Key:                  SecurityJsonFile}}})
+
+		if cmd == "" {
+			cmd += "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}; "
+		}
+		cmd += "ZK_SECURITY_JSON=$(/opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd get /security.json); "
+		cmd += "if [ ${#ZK_SECURITY_JSON} -lt 3 ]; then echo $SECURITY_JSON > /tmp/security.json; /opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd putfile /security.json /tmp/security.json; echo \"put security.json in ZK\"; fi"
+	}
+
+	if cmd != "" {
+		zkSetupImage := solrCloud.ZkSetupContainerImage()
+
+		// Wait for Zookeeper to be available first, so that the reason for a failure is clear
+		cmd = waitForZkCmd() + cmd
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "ZK_CONNECTION_TIMEOUT",
+			Value: strconv.Itoa(int(*solrCloud.Spec.ZookeeperRef.ConnectionTimeoutSeconds)),
+		})
+
+		return true, corev1.Container{
+			Name:                     SolrZkSetupContainer,
+			Image:                    zkSetupImage.ToImageName(),
+			ImagePullPolicy:          zkSetupImage.PullPolicy,
+			TerminationMessagePath:   "/dev/termination-log",
+			TerminationMessagePolicy: "File",
+			Command:                  []string{"sh", "-c", cmd},
+			Env:                      envVars,
+			VolumeMounts:             volumeMounts,
+		}
+	}
+
+	return false, corev1.Container{}
+}
+
+// waitForZkCmd returns a shell command that waits up to $ZK_CONNECTION_TIMEOUT seconds for Zookeeper to be available.
+// If Zookeeper is not available in time, the reason is written to the termination log so that the operator can report it.
+func waitForZkCmd() string {
+	return "start=$(date +%s); until solr zk ls / -z ${ZK_SERVER} > /dev/null 2>&1; do " +
+		"if [ $(( $(date +%s) - start )) -ge ${ZK_CONNECTION_TIMEOUT} ]; then " +
+		"echo \"Zookeeper at ${ZK_SERVER} was not available within ${ZK_CONNECTION_TIMEOUT} seconds\" | tee /dev/termination-log; exit 1; fi; " +
+		"echo \"Waiting for Zookeeper at ${ZK_SERVER}\"; sleep 5; done; "
+}
+
+// ZkSetupFailure returns the reason that the setup-zk init container of the given pod failed, if it has.
+func ZkSetupFailure(pod *corev1.Pod) (failed bool, reason string) {
+	return initContainerFailure(pod, SolrZkSetupContainer)
+}
+
+// initContainerFailure returns the reason that the given init container of the pod failed, if it has.
+// The reason is read from the termination log of the container, if it wrote one.
+func initContainerFailure(pod *corev1.Pod, containerName string) (failed bool, reason string) {
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		if containerStatus.Name != containerName {
+			continue
+		}
+		terminated := containerStatus.State.Terminated
+		if terminated == nil && containerStatus.State.Waiting != nil {
+			// The container is waiting to be restarted after failing
+			terminated = containerStatus.LastTerminationState.Terminated
+		}
+		if terminated != nil && terminated.ExitCode != 0 {
+			reason = strings.TrimSpace(terminated.Message)
+			if reason == "" {
+				reason = fmt.Sprintf("%s init container exited with code %d", containerName, terminated.ExitCode)
+			}
+			return true, reason
+		}
+	}
+	return false, ""
+}
+
+func createZkConnectionEnvVars(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus) (envVars []corev1.EnvVar, solrOpt string, hasChroot bool) {
+	zkConnectionStr, zkServer, zkChroot := solrCloudStatus.DissectZkInfo()
+	envVars = []corev1.EnvVar{
+		{
+			Name:  "ZK_HOST",
+			Value: zkConnectionStr,
+		},
+		{
+			Name:  "ZK_CHROOT",
+			Value: zkChroot,
+		},
+		{
+			Name:  "ZK_SERVER",
+			Value: zkServer,
+		},
+	}
+
+	// Add ACL information, if given, through Env Vars
+	allACL, readOnlyACL := solrCloud.Spec.ZookeeperRef.GetACLs()
+	hasACLs, aclEnvs := AddACLsToEnv(allACL, readOnlyACL)
+
+	// Add SASL and TLS information, if given. These options are added to $SOLR_ZK_CREDS_AND_ACLS, so that the Solr CLI and zkcli.sh use them as well.
+	var zkClientOpts []string
+	if solrCloud.Spec.ZookeeperRef.SASL != nil {
+		if solrCloud.Spec.ZookeeperRef.SASL.SASLACLs {
+			zkClientOpts = append(zkClientOpts, "-DzkACLProvider=org.apache.solr.common.cloud.SaslZkACLProvider")
+		}
+		zkClientOpts = append(zkClientOpts, zkSASLOpts(solrCloud))
+	}
+	if solrCloud.Spec.ZookeeperRef.TLS != nil {
+		tlsEnvs, tlsOpts := createZkTLSEnvVarsAndOpts(solrCloud)
+		envVars = append(envVars, tlsEnvs...)
+		zkClientOpts = append(zkClientOpts, tlsOpts)
+	}
+	if len(zkClientOpts) > 0 {
+		if hasACLs {
+			for i := range aclEnvs {
+				if aclEnvs[i].Name == "SOLR_ZK_CREDS_AND_ACLS" {
+					aclEnvs[i].Value += " " + strings.Join(zkClientOpts, " ")
+				}
+			}
+		} else {
+			hasACLs = true
+			aclEnvs = append(aclEnvs, corev1.EnvVar{
+				Name:  "SOLR_ZK_CREDS_AND_ACLS",
+				Value: strings.Join(zkClientOpts, " "),
+			})
+		}
+	}
+
+	if hasACLs {
+		envVars = append(envVars, aclEnvs...)
+
+		// The $SOLR_ZK_CREDS_AND_ACLS parameter does not get picked up when running solr, it must be added to the SOLR_OPTS.
+		solrOpt = "$(SOLR_ZK_CREDS_AND_ACLS)"
+	}
+
+	if zkClientOpts := zkClientSystemProperties(solrCloud); len(zkClientOpts) > 0 {
+		solrOpt = strings.TrimSpace(solrOpt + " " + strings.Join(zkClientOpts, " "))
+	}
+
+	return envVars, solrOpt, len(zkChroot) > 1
+}
+
+// zkClientSystemProperties returns the system properties for the Zookeeper client settings given in the SolrCloud spec
+func zkClientSystemProperties(solrCloud *solr.SolrCloud) (opts []string) {
+	clientOptions := solrCloud.Spec.ZookeeperRef.ClientOptions
+	if clientOptions == nil {
+		return nil
+	}
+	if clientOptions.ClientTimeoutMillis != nil {
+		opts = append(opts, fmt.Sprintf("-DzkClientTimeout=%d", *clientOptions.ClientTimeoutMillis))
+	}
+	if clientOptions.JuteMaxBufferBytes != nil {
+		opts = append(opts, fmt.Sprintf("-Djute.maxbuffer=%d", *clientOptions.JuteMaxBufferBytes))
+	}
+	return opts
+}
+
+// ValidateZookeeperSASLOptions returns an error if the SASL options of the zookeeperRef cannot be used together with its ACLs
+func ValidateZookeeperSASLOptions(solrCloud *solr.SolrCloud) error {
+	sasl := solrCloud.Spec.ZookeeperRef.SASL
+	if sasl == nil || !sasl.SASLACLs {
+		return nil
+	}
+	if allACL, readOnlyACL := solrCloud.Spec.ZookeeperRef.GetACLs(); allACL != nil || readOnlyACL != nil {
+		return TerminalErrorf(InvalidSpecReason, "'zookeeperRef.sasl.saslACLs' cannot be combined with the digest ACLs of the zookeeperRef")
+	}
+	return nil
+}
+
+// zkSASLOpts returns the system properties needed to authenticate to Zookeeper via SASL.
+// The DIGEST-MD5 credentials are not passed as system properties, since those are visible to anyone who can list the processes of the pod or open the Solr Admin UI.
+// They are rendered into the generated JAAS config by the setup initContainer instead.
+func zkSASLOpts(solrCloud *solr.SolrCloud) string {
+	sasl := solrCloud.Spec.ZookeeperRef.SASL
+	_, _, jaasSolrOpt := jaasConfigVolume(solrCloud)
+	generateJaas := jaasSolrOpt == ""
+	if generateJaas {
+		jaasSolrOpt = "-Djava.security.auth.login.config=/var/solr/data/" + ZkSASLJaasFile
+	}
+	opts := []string{"-Dzookeeper.sasl.client=true", jaasSolrOpt}
+
+	if sasl.Mechanism == solr.ZookeeperSASLKerberos {
+		opts = append(opts, "-Dzookeeper.sasl.client.username="+sasl.ServerPrincipalName)
+		if sasl.Krb5Config != nil {
+			opts = append(opts, fmt.Sprintf("-Djava.security.krb5.conf=%s/%s", ZkSASLKrb5MountPath, ZkSASLKrb5File))
+		}
+		if generateJaas {
+			opts = append(opts, "-DzkSaslPrincipal="+sasl.Principal)
+		}
+	}
+	return strings.Join(opts, " ")
+}
+
+// zkSASLCredentialsEnvVars returns the ZK_SASL_USERNAME and ZK_SASL_PASSWORD environment variables, read from the credentials secret,
+// if the generated JAAS config authenticates to Zookeeper via DIGEST-MD5.
+func zkSASLCredentialsEnvVars(solrCloud *solr.SolrCloud) (envVars []corev1.EnvVar) {
+	if !strings.Contains(zkSASLJaasConfig(solrCloud), zkSaslPasswordPlaceholder) {
+		return nil
+	}
+	sasl := solrCloud.Spec.ZookeeperRef.SASL
+	f := false
+	return []corev1.EnvVar{
+		{
+			Name: "ZK_SASL_USERNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: sasl.CredentialsSecret},
+					Key:                  corev1.BasicAuthUsernameKey,
+					Optional:             &f,
+				},
+			},
+		},
+		{
+			Name: "ZK_SASL_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: sasl.CredentialsSecret},
+					Key:                  corev1.BasicAuthPasswordKey,
+					Optional:             &f,
+				},
+			},
+		},
+	}
+}
+
+// writeJaasConfigCommand returns the shell command that writes the given JAAS config to the given path.
+// The Zookeeper SASL username and password placeholders are rendered from the ZK_SASL_USERNAME and ZK_SASL_PASSWORD environment variables,
+// escaping the characters that are special in quoted JAAS values.
+func writeJaasConfigCommand(jaasConfig string, path string) string {
+	if !strings.Contains(jaasConfig, zkSaslPasswordPlaceholder) {
+		return fmt.Sprintf("echo '%s' > %s", jaasConfig, path)
+	}
+	format := strings.ReplaceAll(jaasConfig, "%", "%%")
+	format = strings.ReplaceAll(format, zkSaslUsernamePlaceholder, "%s")
+	format = strings.ReplaceAll(format, zkSaslPasswordPlaceholder, "%s")
+	escaped := func(envVar string) string {
+		return fmt.Sprintf(`"$(printf '%%s' "$%s" | sed 's/[\\"]/\\&/g')"`, envVar)
+	}
+	return fmt.Sprintf(`printf '%s\n' %s %s > %s`, format, escaped("ZK_SASL_USERNAME"), escaped("ZK_SASL_PASSWORD"), path)
+}
+
+// zkSASLJaasConfig returns the JAAS config that Solr uses to authenticate to Zookeeper via SASL.
+// The DIGEST-MD5 credentials are placeholders, that are rendered by the setup initContainer, see writeJaasConfigCommand.
+// The Kerberos principal is read from a system property when the JAAS config is loaded.
+// An empty string is returned if SASL is not used, or if the user has provided their own JAAS config.
+func zkSASLJaasConfig(solrCloud *solr.SolrCloud) string {
+	sasl := solrCloud.Spec.ZookeeperRef.SASL
+	if sasl == nil || (solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.JaasConfigSecret != nil) {
+		return ""
+	}
+	if sasl.Mechanism == solr.ZookeeperSASLKerberos {
+		return fmt.Sprintf("Client {\n"+
+			"  com.sun.security.auth.module.Krb5LoginModule required\n"+
+			"  useKeyTab=true\n"+
+			"  keyTab=\"%s/%s\"\n"+
+			"  storeKey=true\n"+
+			"  useTicketCache=false\n"+
+			"  principal=\"${zkSaslPrincipal}\";\n"+
+			"};", ZkSASLKeytabMountPath, ZkSASLKeytabFile)
+	}
+	return "Client {\n" +
+		"  org.apache.zookeeper.server.auth.DigestLoginModule required\n" +
+		"  username=\"" + zkSaslUsernamePlaceholder + "\"\n" +
+		"  password=\"" + zkSaslPasswordPlaceholder + "\";\n" +
+		"};"
+}
+
+// zkSASLVolumes returns the volumes, and their mounts, that contain the Kerberos files needed to authenticate to Zookeeper via SASL.
+func zkSASLVolumes(solrCloud *solr.SolrCloud) (volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) {
+	sasl := solrCloud.Spec.ZookeeperRef.SASL
+	if sasl == nil || sasl.Mechanism != solr.ZookeeperSASLKerberos {
+		return nil, nil
+	}
+	if sasl.KeytabSecret != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: ZkSASLKeytabVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  sasl.KeytabSecret.Name,
+					Items:       []corev1.KeyToPath{{Key: sasl.KeytabSecret.Key, Path: ZkSASLKeytabFile}},
+					DefaultMode: &SecretReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: ZkSASLKeytabVolumeName, MountPath: ZkSASLKeytabMountPath, ReadOnly: true})
+	}
+	if sasl.Krb5Config != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: ZkSASLKrb5VolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: sasl.Krb5Config.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: sasl.Krb5Config.Key, Path: ZkSASLKrb5File}},
+					DefaultMode:          &PublicReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: ZkSASLKrb5VolumeName, MountPath: ZkSASLKrb5MountPath, ReadOnly: true})
+	}
+	return volumes, volumeMounts
+}
+
+// createZkTLSEnvVarsAndOpts returns the environment variables and system properties needed to connect to the secure client port of Zookeeper.
+// The ZK client only supports TLS through the Netty client socket.
+func createZkTLSEnvVarsAndOpts(solrCloud *solr.SolrCloud) (envVars []corev1.EnvVar, solrOpts string) {
+	tls := solrCloud.Spec.ZookeeperRef.TLS
+	opts := []string{
+		"-Dzookeeper.client.secure=true",
+		"-Dzookeeper.clientCnxnSocket=org.apache.zookeeper.ClientCnxnSocketNetty",
+		fmt.Sprintf("-Dzookeeper.ssl.trustStore.location=%s/%s", ZkTLSTruststoreMountPath, ZkTLSTruststoreFile),
+		"-Dzookeeper.ssl.trustStore.type=PKCS12",
+	}
+	if tls.TrustStorePasswordSecret != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      "ZK_SSL_TRUST_STORE_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: tls.TrustStorePasswordSecret},
 		})
+		opts = append(opts, "-Dzookeeper.ssl.trustStore.password=$(ZK_SSL_TRUST_STORE_PASSWORD)")
+	}
+	if tls.KeyStoreSecret != nil {
+		opts = append(opts,
+			fmt.Sprintf("-Dzookeeper.ssl.keyStore.location=%s/%s", ZkTLSKeystoreMountPath, ZkTLSKeystoreFile),
+			"-Dzookeeper.ssl.keyStore.type=PKCS12")
+		if tls.KeyStorePasswordSecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      "ZK_SSL_KEY_STORE_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: tls.KeyStorePasswordSecret},
+			})
+			opts = append(opts, "-Dzookeeper.ssl.keyStore.password=$(ZK_SSL_KEY_STORE_PASSWORD)")
+		}
 	}
-
-	cmd := ""
-
-	if solrCloud.Spec.SolrTLS != nil {
-		cmd = setUrlSchemeClusterPropCmd()
+	if tls.DisableHostnameVerification {
+		opts = append(opts, "-Dzookeeper.ssl.hostnameVerification=false")
 	}
+	return envVars, strings.Join(opts, " ")
+}
 
-	if reconcileConfigInfo[SecurityJsonFile] != "" {
-		envVars = append(envVars, corev1.EnvVar{Name: "SECURITY_JSON", ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{Name: solrCloud.SecurityBootstrapSecretName()},
-				Key:                  SecurityJsonFile}}})
-
-		if cmd == "" {
-			cmd += "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}; "
-		}
-		cmd += "ZK_SECURITY_JSON=$(/opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd get /security.json); "
-		cmd += "if [ ${#ZK_SECURITY_JSON} -lt 3 ]; then echo $SECURITY_JSON > /tmp/security.json; /opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd putfile /security.json /tmp/security.json; echo \"put security.json in ZK\"; fi"
+// zkTLSVolumes returns the volumes, and their mounts, that contain the truststore and keystore used to connect to the secure client port of Zookeeper.
+func zkTLSVolumes(solrCloud *solr.SolrCloud) (volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) {
+	tls := solrCloud.Spec.ZookeeperRef.TLS
+	if tls == nil {
+		return nil, nil
 	}
-
-	if cmd != "" {
-		return true, corev1.Container{
-			Name:                     "setup-zk",
-			Image:                    solrCloud.Spec.SolrImage.ToImageName(),
-			ImagePullPolicy:          solrCloud.Spec.SolrImage.PullPolicy,
-			TerminationMessagePath:   "/dev/termination-log",
-			TerminationMessagePolicy: "File",
-			Command:                  []string{"sh", "-c", cmd},
-			Env:                      envVars,
-		}
+	if tls.TrustStoreSecret != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: ZkTLSTruststoreVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  tls.TrustStoreSecret.Name,
+					Items:       []corev1.KeyToPath{{Key: tls.TrustStoreSecret.Key, Path: ZkTLSTruststoreFile}},
+					DefaultMode: &SecretReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: ZkTLSTruststoreVolumeName, MountPath: ZkTLSTruststoreMountPath, ReadOnly: true})
 	}
-
-	return false, corev1.Container{}
+	if tls.KeyStoreSecret != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: ZkTLSKeystoreVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  tls.KeyStoreSecret.Name,
+					Items:       []corev1.KeyToPath{{Key: tls.KeyStoreSecret.Key, Path: ZkTLSKeystoreFile}},
+					DefaultMode: &SecretReadOnlyPermissions,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: ZkTLSKeystoreVolumeName, MountPath: ZkTLSKeystoreMountPath, ReadOnly: true})
+	}
+	return volumes, volumeMounts
 }
 
-func createZkConnectionEnvVars(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus) (envVars []corev1.EnvVar, solrOpt string, hasChroot bool) {
-	zkConnectionStr, zkServer, zkChroot := solrCloudStatus.DissectZkInfo()
-	envVars = []corev1.EnvVar{
-		{
-			Name:  "ZK_HOST",
-			Value: zkConnectionStr,
-		},
-		{
-			Name:  "ZK_CHROOT",
-			Value: zkChroot,
-		},
-		{
-			Name:  "ZK_SERVER",
-			Value: zkServer,
+// jaasConfigVolume returns the volume and mount for the user-provided JAAS config secret, as well as the Solr option that points the JVM to it.
+// Nil is returned for the volume and mount if no JAAS config secret is provided.
+func jaasConfigVolume(solrCloud *solr.SolrCloud) (volume *corev1.Volume, volumeMount *corev1.VolumeMount, solrOpt string) {
+	if solrCloud.Spec.SolrSecurity == nil || solrCloud.Spec.SolrSecurity.JaasConfigSecret == nil {
+		return nil, nil, ""
+	}
+	jaasSecret := solrCloud.Spec.SolrSecurity.JaasConfigSecret
+	volume = &corev1.Volume{
+		Name: JaasConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  jaasSecret.Name,
+				Items:       []corev1.KeyToPath{{Key: jaasSecret.Key, Path: JaasConfigFile}},
+				DefaultMode: &SecretReadOnlyPermissions,
+				Optional:    jaasSecret.Optional,
+			},
 		},
 	}
-
-	// Add ACL information, if given, through Env Vars
-	allACL, readOnlyACL := solrCloud.Spec.ZookeeperRef.GetACLs()
-	if hasACLs, aclEnvs := AddACLsToEnv(allACL, readOnlyACL); hasACLs {
-		envVars = append(envVars, aclEnvs...)
-
-		// The $SOLR_ZK_CREDS_AND_ACLS parameter does not get picked up when running solr, it must be added to the SOLR_OPTS.
-		solrOpt = "$(SOLR_ZK_CREDS_AND_ACLS)"
-	}
-
-	return envVars, solrOpt, len(zkChroot) > 1
+	volumeMount = &corev1.VolumeMount{Name: JaasConfigVolumeName, MountPath: JaasConfigMountPath, ReadOnly: true}
+	solrOpt = fmt.Sprintf("-Djava.security.auth.login.config=%s/%s", JaasConfigMountPath, JaasConfigFile)
+	return volume, volumeMount, solrOpt
 }
 
 func setupVolumeMountForUserProvidedConfigMapEntry(reconcileConfigInfo map[string]string, fileKey string, solrVolumes []corev1.Volume, envVar string) (*corev1.VolumeMount, *corev1.EnvVar, *corev1.Volume) {
@@ -1100,9 +1963,12 @@ func setupVolumeMountForUserProvidedConfigMapEntry(reconcileConfigInfo map[strin
 			},
 		}
 	}
-	pathToFile := fmt.Sprintf("%s/%s", mountPath, fileKey)
+	return &corev1.VolumeMount{Name: volName, MountPath: mountPath}, &corev1.EnvVar{Name: envVar, Value: userProvidedConfigMapEntryPath(reconcileConfigInfo, fileKey)}, vol
+}
 
-	return &corev1.VolumeMount{Name: volName, MountPath: mountPath}, &corev1.EnvVar{Name: envVar, Value: pathToFile}, vol
+// userProvidedConfigMapEntryPath returns the path that a file from the user-provided ConfigMap is mounted at
+func userProvidedConfigMapEntryPath(reconcileConfigInfo map[string]string, fileKey string) string {
+	return fmt.Sprintf("/var/solr/%s/%s", reconcileConfigInfo[fileKey], fileKey)
 }
 
 func BasicAuthHeader(basicAuthSecret *corev1.Secret) string {
@@ -1112,28 +1978,41 @@ func BasicAuthHeader(basicAuthSecret *corev1.Secret) string {
 
 func ValidateBasicAuthSecret(basicAuthSecret *corev1.Secret) error {
 	if basicAuthSecret.Type != corev1.SecretTypeBasicAuth {
-		return fmt.Errorf("invalid secret type %v; user-provided secret %s must be of type: %v",
+		return TerminalErrorf(InvalidSecurityConfigReason, "invalid secret type %v; user-provided secret %s must be of type: %v",
 			basicAuthSecret.Type, basicAuthSecret.Name, corev1.SecretTypeBasicAuth)
 	}
 
 	if _, ok := basicAuthSecret.Data[corev1.BasicAuthUsernameKey]; !ok {
-		return fmt.Errorf("%s key not found in user-provided basic-auth secret %s",
+		return TerminalErrorf(InvalidSecurityConfigReason, "%s key not found in user-provided basic-auth secret %s",
 			corev1.BasicAuthUsernameKey, basicAuthSecret.Name)
 	}
 
 	if _, ok := basicAuthSecret.Data[corev1.BasicAuthPasswordKey]; !ok {
-		return fmt.Errorf("%s key not found in user-provided basic-auth secret %s",
+		return TerminalErrorf(InvalidSecurityConfigReason, "%s key not found in user-provided basic-auth secret %s",
 			corev1.BasicAuthPasswordKey, basicAuthSecret.Name)
 	}
 
 	return nil
 }
 
-func GenerateBasicAuthSecretWithBootstrap(solrCloud *solr.SolrCloud) (*corev1.Secret, *corev1.Secret) {
+// ValidateBootstrapCredentialsSecret checks that the user-provided bootstrapCredentialsSecret has a password for every bootstrapped user
+func ValidateBootstrapCredentialsSecret(solrCloud *solr.SolrCloud, credentialsSecret *corev1.Secret) error {
+	for _, user := range bootstrappedUsers(solrCloud) {
+		if len(credentialsSecret.Data[user]) == 0 {
+			return TerminalErrorf(InvalidSecurityConfigReason, "%s key not found in user-provided bootstrap credentials secret %s, a password is required for every bootstrapped user",
+				user, credentialsSecret.Name)
+		}
+	}
+	return nil
+}
+
+// GenerateBasicAuthSecretWithBootstrap returns the basic-auth secret for the operator's user, and the secret with the security.json to bootstrap.
+// The passwords of the bootstrapped users are taken from the credentialsSecret, if one is provided, otherwise they are random.
+func GenerateBasicAuthSecretWithBootstrap(solrCloud *solr.SolrCloud, credentialsSecret *corev1.Secret) (*corev1.Secret, *corev1.Secret) {
 
-	securityBootstrapInfo := generateSecurityJson(solrCloud)
+	securityBootstrapInfo := generateSecurityJson(solrCloud, credentialsSecret)
 
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	var annotations map[string]string
 	basicAuthSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1143,8 +2022,8 @@ func GenerateBasicAuthSecretWithBootstrap(solrCloud *solr.SolrCloud) (*corev1.Se
 			Annotations: annotations,
 		},
 		Data: map[string][]byte{
-			corev1.BasicAuthUsernameKey: []byte(solr.DefaultBasicAuthUsername),
-			corev1.BasicAuthPasswordKey: securityBootstrapInfo[solr.DefaultBasicAuthUsername],
+			corev1.BasicAuthUsernameKey: []byte(solrCloud.OperatorUsername()),
+			corev1.BasicAuthPasswordKey: securityBootstrapInfo[solrCloud.OperatorUsername()],
 		},
 		Type: corev1.SecretTypeBasicAuth,
 	}
@@ -1169,7 +2048,48 @@ func GenerateBasicAuthSecretWithBootstrap(solrCloud *solr.SolrCloud) (*corev1.Se
 	return basicAuthSecret, boostrapSecuritySecret
 }
 
-func generateSecurityJson(solrCloud *solr.SolrCloud) map[string][]byte {
+// GenerateConnectionInfoSecret returns a new corev1.Secret containing the information that client applications need to connect to the SolrCloud.
+// caCert and appUserSecret are optional.
+func GenerateConnectionInfoSecret(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, caCert []byte, appUserSecret *corev1.Secret) *corev1.Secret {
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
+	var annotations map[string]string
+
+	data := map[string][]byte{
+		ConnectionInfoZkConnectionStringKey: []byte(solrCloudStatus.ZkConnectionString()),
+		ConnectionInfoInternalUrlKey:        []byte(solrCloudStatus.InternalCommonAddress),
+		ServiceBindingTypeKey:               []byte(ServiceBindingType),
+		ServiceBindingProviderKey:           []byte(ServiceBindingProvider),
+		ServiceBindingUriKey:                []byte(solrCloudStatus.InternalCommonAddress + "/solr"),
+	}
+	if solrCloudStatus.ExternalCommonAddress != nil {
+		data[ConnectionInfoExternalUrlKey] = []byte(*solrCloudStatus.ExternalCommonAddress)
+	}
+	if len(caCert) > 0 {
+		data[ConnectionInfoCACertKey] = caCert
+	}
+	if appUserSecret != nil {
+		data[corev1.BasicAuthUsernameKey] = appUserSecret.Data[corev1.BasicAuthUsernameKey]
+		data[corev1.BasicAuthPasswordKey] = appUserSecret.Data[corev1.BasicAuthPasswordKey]
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        solrCloud.ConnectionInfoSecretName(),
+			Namespace:   solrCloud.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: data,
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+// bootstrappedUsers returns the users that are created in the bootstrapped security.json
+func bootstrappedUsers(solrCloud *solr.SolrCloud) []string {
+	return []string{"admin", solrCloud.OperatorUsername(), "solr"}
+}
+
+func generateSecurityJson(solrCloud *solr.SolrCloud, credentialsSecret *corev1.Secret) map[string][]byte {
 	blockUnknown := true
 
 	probeRole := "\"k8s\"" // probe endpoints are secures
@@ -1190,14 +2110,18 @@ func generateSecurityJson(solrCloud *solr.SolrCloud) map[string][]byte {
 		probeAuthz += fmt.Sprintf("{ \"name\": \"k8s-probe-%d\", \"role\":%s, \"collection\": null, \"path\":\"%s\" }", i, probeRole, p)
 	}
 
-	// Create the user accounts for security.json with random passwords
+	// Create the user accounts for security.json with random passwords, unless the user provided them,
 	// hashed with random salt, just as Solr's hashing works
-	username := solr.DefaultBasicAuthUsername
-	users := []string{"admin", username, "solr"}
+	username := solrCloud.OperatorUsername()
+	users := bootstrappedUsers(solrCloud)
 	secretData := make(map[string][]byte, len(users))
 	credentials := make(map[string]string, len(users))
 	for _, u := range users {
-		secretData[u] = randomPassword()
+		if credentialsSecret != nil {
+			secretData[u] = credentialsSecret.Data[u]
+		} else {
+			secretData[u] = randomPassword()
+		}
 		credentials[u] = solrPasswordHash(secretData[u])
 	}
 	credentialsJson, _ := json.Marshal(credentials)
@@ -1271,16 +2195,15 @@ func getProbePaths(solrCloud *solr.SolrCloud) []string {
 }
 
 func randomPassword() []byte {
-	rand.Seed(time.Now().UnixNano())
 	lower := "abcdefghijklmnpqrstuvwxyz" // no 'o'
 	upper := strings.ToUpper(lower)
 	digits := "0123456789"
 	chars := lower + upper + digits + "()[]%#@-()[]%#@-"
 	pass := make([]byte, 16)
 	// start with a lower char and end with an upper
-	pass[0] = lower[rand.Intn(len(lower))]
-	pass[len(pass)-1] = upper[rand.Intn(len(upper))]
-	perm := rand.Perm(len(chars))
+	pass[0] = lower[randomInt(len(lower))]
+	pass[len(pass)-1] = upper[randomInt(len(upper))]
+	perm := randomPerm(len(chars))
 	for i := 1; i < len(pass)-1; i++ {
 		pass[i] = chars[perm[i]]
 	}
@@ -1289,11 +2212,35 @@ func randomPassword() []byte {
 
 func randomSaltHash() []byte {
 	b := make([]byte, 32)
-	rand.Read(b)
+	if _, err := cryptorand.Read(b); err != nil {
+		panic(err)
+	}
 	salt := sha256.Sum256(b)
 	return salt[:]
 }
 
+// randomInt returns a uniformly distributed random int in [0,n), using a cryptographically secure (and FIPS-approved) source
+func randomInt(n int) int {
+	i, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(err)
+	}
+	return int(i.Int64())
+}
+
+// randomPerm returns a random permutation of the ints [0,n), using a Fisher-Yates shuffle
+func randomPerm(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := randomInt(i + 1)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
 // this mimics the password hash generation approach used by Solr
 func solrPasswordHash(passBytes []byte) string {
 	// combine password with salt to create the hash
@@ -1321,8 +2268,8 @@ func uniqueProbePaths(paths []string) []string {
 func configureSecureProbeCommand(solrCloud *solr.SolrCloud, defaultProbeGetAction *corev1.HTTPGetAction) (string, *corev1.Volume, *corev1.VolumeMount) {
 	// mount the secret in a file so it gets updated; env vars do not see:
 	// https://kubernetes.io/docs/concepts/configuration/secret/#environment-variables-are-not-updated-after-a-secret-update
-	basicAuthOption := ""
-	enableBasicAuth := ""
+	authJavaToolOpts := ""
+	enableAuth := ""
 	var volMount *corev1.VolumeMount
 	var vol *corev1.Volume
 	if solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.ProbesRequireAuth {
@@ -1340,13 +2287,17 @@ func configureSecureProbeCommand(solrCloud *solr.SolrCloud, defaultProbeGetActio
 		volMount = &corev1.VolumeMount{Name: vol.Name, MountPath: mountPath}
 		usernameFile := fmt.Sprintf("%s/%s", mountPath, corev1.BasicAuthUsernameKey)
 		passwordFile := fmt.Sprintf("%s/%s", mountPath, corev1.BasicAuthPasswordKey)
-		basicAuthOption = fmt.Sprintf("-Dbasicauth=$(cat %s):$(cat %s)", usernameFile, passwordFile)
-		enableBasicAuth = " -Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.PreemptiveBasicAuthClientBuilderFactory "
+		authJavaToolOpts = fmt.Sprintf("-Dbasicauth=$(cat %s):$(cat %s)", usernameFile, passwordFile)
+		enableAuth = " -Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.PreemptiveBasicAuthClientBuilderFactory "
+	} else if solrCloud.UsesKerberos() {
+		// the probe logs in as the client principal of the pod, through the same keytab and JAAS config as Solr
+		authJavaToolOpts = strings.Join(kerberosClientOpts(solrCloud, "${SOLR_HOST}", SolrKerberosJaasFile), " ")
+		enableAuth = " -Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.Krb5HttpClientBuilder "
 	}
 
 	// Is TLS enabled? If so we need some additional SSL related props
 	tlsJavaToolOpts, tlsJavaSysProps := secureProbeTLSJavaToolOpts(solrCloud)
-	javaToolOptions := strings.TrimSpace(basicAuthOption + " " + tlsJavaToolOpts)
+	javaToolOptions := strings.TrimSpace(authJavaToolOpts + " " + tlsJavaToolOpts)
 
 	// construct the probe command to invoke the SolrCLI "api" action
 	//
@@ -1356,8 +2307,464 @@ func configureSecureProbeCommand(solrCloud *solr.SolrCloud, defaultProbeGetActio
 		"-Dsolr.install.dir=\"/opt/solr\" -Dlog4j.configurationFile=\"/opt/solr/server/resources/log4j2-console.xml\" "+
 		"-classpath \"/opt/solr/server/solr-webapp/webapp/WEB-INF/lib/*:/opt/solr/server/lib/ext/*:/opt/solr/server/lib/*\" "+
 		"org.apache.solr.util.SolrCLI api -get %s://localhost:%d%s",
-		javaToolOptions, tlsJavaSysProps, enableBasicAuth, solrCloud.UrlScheme(false), defaultProbeGetAction.Port.IntVal, defaultProbeGetAction.Path)
+		javaToolOptions, tlsJavaSysProps, enableAuth, solrCloud.UrlScheme(false), defaultProbeGetAction.Port.IntVal, defaultProbeGetAction.Path)
 	probeCommand = regexp.MustCompile(`\s+`).ReplaceAllString(strings.TrimSpace(probeCommand), " ")
 
 	return probeCommand, vol, volMount
 }
+
+// ResolvePodTemplateVars replaces the $(VAR) references in the value with the given variables.
+// References to unknown variables are left as-is.
+func ResolvePodTemplateVars(value string, vars map[string]string) string {
+	return podTemplateVarRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		if resolved, known := vars[podTemplateVarRegex.FindStringSubmatch(ref)[1]]; known {
+			return resolved
+		}
+		return ref
+	})
+}
+
+func usesPerPodTemplateVars(value string) bool {
+	for _, match := range podTemplateVarRegex.FindAllStringSubmatch(value, -1) {
+		if ContainsString(perPodTemplateVars, match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSolrCloudTemplateVars resolves the variables that are the same for every pod of the SolrCloud.
+// Entries that use per-pod variables are left out.
+func resolveSolrCloudTemplateVars(solrCloud *solr.SolrCloud, values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	vars := map[string]string{
+		PodTemplateVarSolrCloudName: solrCloud.Name,
+		PodTemplateVarNamespace:     solrCloud.Namespace,
+	}
+	resolved := make(map[string]string, len(values))
+	for k, v := range values {
+		if !usesPerPodTemplateVars(v) {
+			resolved[k] = ResolvePodTemplateVars(v, vars)
+		}
+	}
+	return resolved
+}
+
+// UsesZonePodTemplateVar returns whether any custom pod label or annotation needs the zone of the pod to be resolved.
+func UsesZonePodTemplateVar(solrCloud *solr.SolrCloud) bool {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return false
+	}
+	zoneRef := "$(" + PodTemplateVarZone + ")"
+	for _, values := range []map[string]string{podOptions.Labels, podOptions.Annotations} {
+		for _, v := range values {
+			if strings.Contains(v, zoneRef) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GeneratePerPodMetadata returns the custom pod labels and annotations that use per-pod variables, resolved for the given pod.
+// These cannot be set in the StatefulSet pod template, so the Solr Operator sets them on each pod once it has been created.
+func GeneratePerPodMetadata(solrCloud *solr.SolrCloud, podName string, zone string) (labels map[string]string, annotations map[string]string) {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil, nil
+	}
+	vars := map[string]string{
+		PodTemplateVarSolrCloudName: solrCloud.Name,
+		PodTemplateVarNamespace:     solrCloud.Namespace,
+		PodTemplateVarPodName:       podName,
+		PodTemplateVarPodOrdinal:    podName[strings.LastIndex(podName, "-")+1:],
+		PodTemplateVarZone:          zone,
+	}
+	resolvePerPod := func(values map[string]string) map[string]string {
+		var resolved map[string]string
+		for k, v := range values {
+			if usesPerPodTemplateVars(v) {
+				if resolved == nil {
+					resolved = map[string]string{}
+				}
+				resolved[k] = ResolvePodTemplateVars(v, vars)
+			}
+		}
+		return resolved
+	}
+	return resolvePerPod(podOptions.Labels), resolvePerPod(podOptions.Annotations)
+}
+
+// customSolrStopWait returns the SOLR_STOP_WAIT provided by the user through the custom environment variables of the SolrCloud, if any.
+func customSolrStopWait(solrCloud *solr.SolrCloud) (solrStopWait int64, found bool, err error) {
+	customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if customPodOptions == nil {
+		return 0, false, nil
+	}
+	for _, envVar := range customPodOptions.EnvVariables {
+		// The last definition of an environment variable takes precedence
+		if envVar.Name == "SOLR_STOP_WAIT" && envVar.ValueFrom == nil {
+			found = true
+			solrStopWait, err = strconv.ParseInt(envVar.Value, 10, 64)
+		}
+	}
+	if found && err != nil {
+		err = fmt.Errorf("the custom SOLR_STOP_WAIT environment variable must be a number of seconds: %s", err)
+	}
+	return solrStopWait, found, err
+}
+
+// ValidateSolrStopWait returns an error if a custom SOLR_STOP_WAIT does not leave Solr enough time to stop gracefully
+// within the terminationGracePeriodSeconds of the Solr pods, so Kubernetes would kill Solr while it is still stopping.
+// The lameduck period must fit within the terminationGracePeriodSeconds as well.
+func ValidateSolrStopWait(solrCloud *solr.SolrCloud) error {
+	lameduckSeconds := solrLameduckSeconds(solrCloud)
+	var gracePeriod *int64
+	if customPodOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; nil != customPodOptions {
+		gracePeriod = customPodOptions.TerminationGracePeriodSeconds
+	}
+	if gracePeriod != nil && lameduckSeconds+solrStopWaitBuffer > *gracePeriod {
+		return fmt.Errorf("the lameduckSeconds of %d must be at least %d seconds less than the terminationGracePeriodSeconds of %d seconds, otherwise Solr will be killed before it is stopped",
+			lameduckSeconds, solrStopWaitBuffer, *gracePeriod)
+	}
+
+	solrStopWait, found, err := customSolrStopWait(solrCloud)
+	if !found || err != nil {
+		return err
+	}
+	if gracePeriod != nil && solrStopWait+solrStopWaitBuffer+lameduckSeconds > *gracePeriod {
+		if lameduckSeconds == 0 {
+			return fmt.Errorf("the custom SOLR_STOP_WAIT of %d seconds must be at least %d seconds less than the terminationGracePeriodSeconds of %d seconds, otherwise Solr will be killed before it has stopped gracefully",
+				solrStopWait, solrStopWaitBuffer, *gracePeriod)
+		}
+		return fmt.Errorf("the custom SOLR_STOP_WAIT of %d seconds, plus the lameduckSeconds of %d, must be at least %d seconds less than the terminationGracePeriodSeconds of %d seconds, otherwise Solr will be killed before it has stopped gracefully",
+			solrStopWait, lameduckSeconds, solrStopWaitBuffer, *gracePeriod)
+	}
+	return nil
+}
+
+// ValidateNodeNameTemplate returns an error if the external.nodeNameTemplate cannot be used to generate the hostnames of the Solr Nodes.
+func ValidateNodeNameTemplate(solrCloud *solr.SolrCloud) error {
+	external := solrCloud.Spec.SolrAddressability.External
+	if external == nil || external.NodeNameTemplate == "" {
+		return nil
+	}
+	if external.Method != solr.Ingress {
+		return TerminalErrorf(InvalidSpecReason, "external.nodeNameTemplate is only supported with the %s method", solr.Ingress)
+	}
+	// The hostname of each Solr Node is generated in the pod, by substituting the pod name into the rendered template
+	const podNameVar = "$(POD_HOSTNAME)"
+	host, err := solrCloud.TemplatedNodeHost(podNameVar, external.DomainName)
+	if err != nil {
+		return TerminalErrorf(InvalidSpecReason, "invalid external.nodeNameTemplate: %s", err)
+	}
+	if strings.Count(host, podNameVar) != 1 {
+		return TerminalErrorf(InvalidSpecReason, "external.nodeNameTemplate must contain the PodName exactly once and unmodified")
+	}
+	for _, nodeName := range solrCloud.GetAllSolrNodeNames() {
+		nodeHost := strings.Replace(host, podNameVar, nodeName, 1)
+		if errs := validation.IsDNS1123Subdomain(nodeHost); len(errs) > 0 {
+			return TerminalErrorf(InvalidSpecReason, "external.nodeNameTemplate generates an invalid hostname %s: %s", nodeHost, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateAddressabilityPorts returns an error if the ports of the solrAddressability would produce a SolrCloud
+// whose advertised or external addresses cannot be reached, even though the Solr Nodes are able to start.
+func ValidateAddressabilityPorts(solrCloud *solr.SolrCloud) error {
+	external := solrCloud.Spec.SolrAddressability.External
+	if external == nil || !external.UseExternalAddress || solrCloud.UsesHostNetwork() {
+		return nil
+	}
+	advertisedPort := solrCloud.AdvertisedNodePort()
+	// ExternalDNS hostnames resolve to the Solr pods directly, through the headless service, so only the podPort can be reached
+	if external.Method == solr.ExternalDNS && advertisedPort != solrCloud.Spec.SolrAddressability.PodPort {
+		return TerminalErrorf(InvalidSpecReason, "external.advertisedPort %d must equal the podPort %d with the %s method, since its hostnames resolve to the Solr pods", advertisedPort, solrCloud.Spec.SolrAddressability.PodPort, solr.ExternalDNS)
+	}
+	// An ingress controller that terminates TLS serves http on port 80, so https cannot be advertised on the default nodePortOverride
+	if external.IngressTLSTerminationSecret != "" && solrCloud.AdvertisedUrlScheme() == "https" && advertisedPort == 80 {
+		return TerminalErrorf(InvalidSpecReason, "Solr Nodes cannot advertise https on port 80 with ingress TLS termination, set external.advertisedPort to the port that the ingress serves https on")
+	}
+	return nil
+}
+
+// ValidateAdvertisedHostTemplate returns an error if the advertisedHostTemplate cannot be used to generate the advertised hosts of the Solr Nodes.
+func ValidateAdvertisedHostTemplate(solrCloud *solr.SolrCloud) error {
+	if !solrCloud.UsesAdvertisedHostTemplate() {
+		return nil
+	}
+	if solrCloud.UsesHostNetwork() {
+		return TerminalErrorf(InvalidSpecReason, "advertisedHostTemplate cannot be used with hostNetwork")
+	}
+	// The PodName and PodIP are substituted in the pod at runtime, so they must appear unmodified in the rendered host
+	const podNameVar, podIPVar = "$(POD_HOSTNAME)", "$(POD_IP)"
+	host, err := solrCloud.TemplatedAdvertisedHost(podNameVar, podIPVar)
+	if err != nil {
+		return TerminalErrorf(InvalidSpecReason, "invalid advertisedHostTemplate: %s", err)
+	}
+	if strings.Count(host, podNameVar) > 1 || strings.Count(host, podIPVar) > 1 {
+		return TerminalErrorf(InvalidSpecReason, "advertisedHostTemplate must contain the PodName and PodIP at most once and unmodified")
+	}
+	if !strings.Contains(host, podNameVar) && !strings.Contains(host, podIPVar) {
+		return TerminalErrorf(InvalidSpecReason, "advertisedHostTemplate must contain either the PodName or the PodIP, so that each Solr Node advertises a distinct host")
+	}
+	for _, nodeName := range solrCloud.GetAllSolrNodeNames() {
+		// The IP family of the pods is not known, so the host is validated with an example IPv4 address
+		nodeHost := strings.Replace(strings.Replace(host, podNameVar, nodeName, 1), podIPVar, "10.0.0.1", 1)
+		if !isValidAdvertisedHost(nodeHost) {
+			return TerminalErrorf(InvalidSpecReason, "advertisedHostTemplate generates an invalid host %s, it must be a hostname, an IP address or a bracketed IP address", nodeHost)
+		}
+	}
+	return nil
+}
+
+// isValidAdvertisedHost returns whether Solr can advertise the given host in its node name, which is suffixed with ":<port>_solr"
+func isValidAdvertisedHost(host string) bool {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")) != nil
+	}
+	return net.ParseIP(host) != nil || len(validation.IsDNS1123Subdomain(host)) == 0
+}
+
+// solrOptionsManagedByOperator are the options of the "solr" start script, and the system properties they map to,
+// that the Solr Operator sets through environment variables. These cannot be overridden by a custom command or args.
+var solrOptionsManagedByOperator = []string{
+	"-p", "--port", "-Djetty.port=",
+	"-s", "--solr-home", "-Dsolr.solr.home=",
+	"-z", "--zk-host", "-DzkHost=",
+	"-h", "--host", "-Dhost=",
+}
+
+// ValidateSolrContainerOptions returns an error if the custom command or args of the Solr container override the options that the Solr Operator manages.
+func ValidateSolrContainerOptions(solrCloud *solr.SolrCloud) error {
+	containerOptions := solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions
+	if containerOptions == nil {
+		return nil
+	}
+	for _, arg := range append(append([]string{}, containerOptions.Command...), containerOptions.Args...) {
+		// Commands are often passed to a shell, so check each word of each argument
+		for _, word := range strings.Fields(arg) {
+			for _, option := range solrOptionsManagedByOperator {
+				if word == option || (strings.HasSuffix(option, "=") && strings.HasPrefix(word, option)) || strings.HasPrefix(word, option+"=") {
+					return TerminalErrorf(InvalidSpecReason, "invalid solrContainerOptions, the Solr option %s is managed by the Solr Operator and cannot be overridden", strings.TrimSuffix(option, "="))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// runtimeRestrictionSolrOpts are the system properties that are set through the runtimeRestrictions of the SolrCloud
+var runtimeRestrictionSolrOpts = []string{"-Dsolr.allowPaths=", "-Dsolr.allowUrls=", "-Dsolr.disable.allowUrls="}
+
+// ValidateRuntimeRestrictions returns an error if the runtimeRestrictions cannot be used together,
+// or if they are also set through the solrOpts or the envVars of the podOptions
+func ValidateRuntimeRestrictions(solrCloud *solr.SolrCloud) error {
+	restrictions := solrCloud.Spec.RuntimeRestrictions
+	if restrictions == nil {
+		return nil
+	}
+	if restrictions.DisableAllowUrls && len(restrictions.AllowUrls) > 0 {
+		return TerminalErrorf(InvalidSpecReason, "invalid runtimeRestrictions, 'allowUrls' cannot be used with 'disableAllowUrls'")
+	}
+	for _, value := range append(append([]string{}, restrictions.AllowPaths...), restrictions.AllowUrls...) {
+		if value == "" || strings.ContainsAny(value, ", ") {
+			return TerminalErrorf(InvalidSpecReason, "invalid runtimeRestrictions, the allowed path or URL %q must not be empty, or contain commas or spaces", value)
+		}
+	}
+	for _, word := range strings.Fields(solrCloud.Spec.SolrOpts) {
+		for _, option := range runtimeRestrictionSolrOpts {
+			if strings.HasPrefix(word, option) {
+				return TerminalErrorf(InvalidSpecReason, "invalid solrOpts, the Solr option %s is set through the runtimeRestrictions", strings.TrimSuffix(option, "="))
+			}
+		}
+	}
+	if restrictions.SecurityManager != nil && solrCloud.Spec.CustomSolrKubeOptions.PodOptions != nil {
+		for _, envVar := range solrCloud.Spec.CustomSolrKubeOptions.PodOptions.EnvVariables {
+			if envVar.Name == "SOLR_SECURITY_MANAGER_ENABLED" {
+				return TerminalErrorf(InvalidSpecReason, "invalid podOptions.envVars, SOLR_SECURITY_MANAGER_ENABLED is set through 'runtimeRestrictions.securityManager'")
+			}
+		}
+	}
+	return nil
+}
+
+// ValidatePodSecurityProfile returns an error if the SolrCloud uses options that the restricted Pod Security Standard does not allow,
+// when the Restricted podSecurityProfile is used
+func ValidatePodSecurityProfile(solrCloud *solr.SolrCloud) error {
+	if solrCloud.Spec.PodSecurityProfile != solr.RestrictedPodSecurityProfile {
+		return nil
+	}
+	if solrCloud.UsesHostNetwork() {
+		return TerminalErrorf(InvalidSpecReason, "invalid podSecurityProfile, 'solrAddressability.hostNetwork' cannot be used with the %s podSecurityProfile", solr.RestrictedPodSecurityProfile)
+	}
+	return nil
+}
+
+// generateAvailabilityAffinity generates the pod anti-affinity that keeps Solr pods of the SolrCloud on different Nodes
+func generateAvailabilityAffinity(availability *solr.SolrAvailabilityOptions, selectorLabels map[string]string) *corev1.Affinity {
+	podAffinityTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+		TopologyKey:   corev1.LabelHostname,
+	}
+	switch availability.PodAntiAffinity {
+	case solr.RequiredPodAntiAffinity:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{podAffinityTerm},
+			},
+		}
+	case solr.PreferredPodAntiAffinity:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: podAffinityTerm}},
+			},
+		}
+	}
+	return nil
+}
+
+// withRequiredNodeAntiAffinity returns a copy of the given affinity, that requires the pods matching the selectorLabels to run on different Nodes.
+// The given affinity may be shared with the SolrCloud spec, so it is never changed.
+func withRequiredNodeAntiAffinity(affinity *corev1.Affinity, selectorLabels map[string]string) *corev1.Affinity {
+	podAffinityTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+		TopologyKey:   corev1.LabelHostname,
+	}
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if reflect.DeepEqual(term, podAffinityTerm) {
+			return affinity
+		}
+	}
+	affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, podAffinityTerm)
+	return affinity
+}
+
+// withSpotNodeAffinity returns a copy of the given affinity, that requires or prefers the pods to run on Nodes with the spot Node labels.
+// Required labels are added to every existing required Node selector term, since the terms are ORed together.
+func withSpotNodeAffinity(affinity *corev1.Affinity, spotNodes *solr.SolrSpotNodeOptions) *corev1.Affinity {
+	labelKeys := make([]string, 0, len(spotNodes.NodeLabels))
+	for key := range spotNodes.NodeLabels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	matchExpressions := make([]corev1.NodeSelectorRequirement, len(labelKeys))
+	for i, key := range labelKeys {
+		matchExpressions[i] = corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{spotNodes.NodeLabels[key]},
+		}
+	}
+
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if spotNodes.Scheduling == solr.RequiredSpotNodeScheduling {
+		if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil || len(nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+			nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{}},
+			}
+		}
+		terms := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		for i := range terms {
+			terms[i].MatchExpressions = append(terms[i].MatchExpressions, matchExpressions...)
+		}
+	} else {
+		nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+			Weight:     100,
+			Preference: corev1.NodeSelectorTerm{MatchExpressions: matchExpressions},
+		})
+	}
+	return affinity
+}
+
+// generateAvailabilityTopologySpreadConstraints generates the constraints that spread Solr pods of the SolrCloud evenly across zones.
+// Pods are still scheduled when the zones cannot be kept even, such as while a zone is unavailable.
+func generateAvailabilityTopologySpreadConstraints(availability *solr.SolrAvailabilityOptions, selectorLabels map[string]string) []corev1.TopologySpreadConstraint {
+	if !availability.SpreadAcrossZones {
+		return nil
+	}
+	zoneTopologyKey := availability.ZoneTopologyKey
+	if zoneTopologyKey == "" {
+		zoneTopologyKey = solr.DefaultZoneTopologyKey
+	}
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       zoneTopologyKey,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: selectorLabels},
+		},
+	}
+}
+
+// withPropagatedAnnotations adds the annotations of the SolrCloud that are selected by the propagateAnnotations policy,
+// without overriding the annotations that are already set
+func withPropagatedAnnotations(solrCloud *solr.SolrCloud, annotations map[string]string) map[string]string {
+	propagated := solrCloud.PropagatedAnnotations()
+	if len(propagated) == 0 {
+		return annotations
+	}
+	return MergeLabelsOrAnnotations(annotations, propagated)
+}
+
+// GenerateDataPVCTemplate returns the volumeClaimTemplate of the StatefulSet for the data of each Solr Node.
+// The SolrCloud must use persistent storage.
+func GenerateDataPVCTemplate(solrCloud *solr.SolrCloud) corev1.PersistentVolumeClaim {
+	pvc := solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.DeepCopy()
+
+	// Set the default name of the pvc
+	if pvc.ObjectMeta.Name == "" {
+		pvc.ObjectMeta.Name = solrDataVolumeName
+	}
+
+	// Set some defaults in the PVC Spec
+	if len(pvc.Spec.AccessModes) == 0 {
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{
+			corev1.ReadWriteOnce,
+		}
+	}
+	if pvc.Spec.VolumeMode == nil {
+		temp := corev1.PersistentVolumeFilesystem
+		pvc.Spec.VolumeMode = &temp
+	}
+
+	//  Add internally-used labels.
+	internalLabels := map[string]string{
+		SolrPVCTechnologyLabel: SolrCloudPVCTechnology,
+		SolrPVCStorageLabel:    SolrCloudPVCDataStorage,
+		SolrPVCInstanceLabel:   solrCloud.Name,
+	}
+	pvc.ObjectMeta.Labels = MergeLabelsOrAnnotations(MergeLabelsOrAnnotations(internalLabels, pvc.ObjectMeta.Labels), solrCloud.PropagatedPVCLabels())
+	if propagated := solrCloud.PropagatedPVCAnnotations(); len(propagated) > 0 {
+		pvc.ObjectMeta.Annotations = MergeLabelsOrAnnotations(pvc.ObjectMeta.Annotations, propagated)
+	}
+
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pvc.ObjectMeta.Name,
+			Labels:      pvc.ObjectMeta.Labels,
+			Annotations: pvc.ObjectMeta.Annotations,
+		},
+		Spec: pvc.Spec,
+	}
+}
@@ -18,10 +18,16 @@
 package util
 
 import (
+	"encoding/json"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNoRepositoryXmlGeneratedWhenNoRepositoriesExist(t *testing.T) {
@@ -76,3 +82,1536 @@ func TestGeneratedSolrXmlContainsEntryForEachRepository(t *testing.T) {
 	// Since GCS repositories are defined, make sure the contrib is on the classpath
 	assert.Contains(t, xmlString, "<str name=\"sharedLib\">/opt/solr/contrib/gcs-repository/lib,/opt/solr/dist</str>")
 }
+
+func TestJaasConfigMountedInStatefulSet(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{
+				AuthenticationType: solr.Basic,
+				JaasConfigSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "jaas-secret"},
+					Key:                  "my-jaas.conf",
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	reconcileConfigInfo := map[string]string{SecurityJsonFile: "{}"}
+
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, reconcileConfigInfo, nil)
+	podSpec := statefulSet.Spec.Template.Spec
+	expectedSolrOpt := "-Djava.security.auth.login.config=" + JaasConfigMountPath + "/" + JaasConfigFile
+
+	var jaasVolume *corev1.Volume
+	for i, volume := range podSpec.Volumes {
+		if volume.Name == JaasConfigVolumeName {
+			jaasVolume = &podSpec.Volumes[i]
+		}
+	}
+	if assert.NotNil(t, jaasVolume, "The JAAS config volume was not added to the pod") && assert.NotNil(t, jaasVolume.Secret, "The JAAS config volume should be a secret") {
+		assert.Equal(t, "jaas-secret", jaasVolume.Secret.SecretName, "Wrong secret used for the JAAS config volume")
+		assert.Equal(t, []corev1.KeyToPath{{Key: "my-jaas.conf", Path: JaasConfigFile}}, jaasVolume.Secret.Items, "Wrong items for the JAAS config volume")
+	}
+
+	for _, container := range []corev1.Container{podSpec.Containers[0], podSpec.InitContainers[len(podSpec.InitContainers)-1]} {
+		assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: JaasConfigVolumeName, MountPath: JaasConfigMountPath, ReadOnly: true}, "The JAAS config is not mounted in the %s container", container.Name)
+		var solrOpts string
+		for _, envVar := range container.Env {
+			if envVar.Name == "SOLR_OPTS" {
+				solrOpts = envVar.Value
+			}
+		}
+		assert.Contains(t, solrOpts, expectedSolrOpt, "The JAAS config is not passed to Solr in the %s container", container.Name)
+	}
+}
+
+func TestZkSASLOptions(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181"},
+				SASL:           &solr.ZookeeperSASLOptions{CredentialsSecret: "zk-sasl"},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloud.Status.ZookeeperConnectionInfo = *solrCloud.Spec.ZookeeperRef.ConnectionInfo
+	assert.Equal(t, solr.ZookeeperSASLDigestMD5, solrCloud.Spec.ZookeeperRef.SASL.Mechanism, "The default SASL mechanism should be DIGEST-MD5")
+
+	envVars, solrOpt, _ := createZkConnectionEnvVars(solrCloud, &solrCloud.Status)
+	assert.Equal(t, "$(SOLR_ZK_CREDS_AND_ACLS)", solrOpt, "The ZK creds and ACLs should be passed to Solr when SASL is used")
+	credsAndAcls := envVars[len(envVars)-1]
+	assert.Equal(t, "SOLR_ZK_CREDS_AND_ACLS", credsAndAcls.Name, "The ZK creds and ACLs should be the last ZK env var")
+	assert.NotContains(t, credsAndAcls.Value, "-DzkACLProvider", "The ACL provider should not be changed unless SASL ACLs are requested")
+	assert.Contains(t, credsAndAcls.Value, "-Djava.security.auth.login.config=/var/solr/data/"+ZkSASLJaasFile, "The generated JAAS config should be used")
+	assert.NotContains(t, credsAndAcls.Value, "zkSaslPassword", "The SASL password should never be passed as a system property")
+	assert.NotContains(t, credsAndAcls.Value, "ZK_SASL_PASSWORD", "The SASL password should never be passed as a system property")
+	assert.Contains(t, zkSASLJaasConfig(solrCloud), "DigestLoginModule", "The generated JAAS config should use the digest login module")
+
+	// The credentials are rendered into the generated JAAS config by the setup initContainer, the only container that reads them
+	podSpec := GenerateStatefulSet(solrCloud, &solrCloud.Status, nil, map[string]string{SecurityJsonFile: "{}"}, nil).Spec.Template.Spec
+	for _, container := range append(podSpec.Containers, podSpec.InitContainers...) {
+		var envVarNames []string
+		for _, envVar := range container.Env {
+			envVarNames = append(envVarNames, envVar.Name)
+		}
+		if container.Name == "cp-solr-xml" {
+			assert.Contains(t, envVarNames, "ZK_SASL_PASSWORD", "The setup initContainer should read the SASL password from the credentials secret")
+			assert.Contains(t, container.Command[2], "password=\"%s\";", "The setup initContainer should render the SASL password into the JAAS config")
+			assert.Contains(t, container.Command[2], "> /tmp-config/"+ZkSASLJaasFile, "The setup initContainer should write the JAAS config to the data directory")
+		} else {
+			assert.NotContains(t, envVarNames, "ZK_SASL_PASSWORD", "The SASL password should not be given to the %s container", container.Name)
+		}
+	}
+	assert.Equal(t, "printf 'Client {\n  username=\"%s\"\n  password=\"%s\";\n};\\n' "+
+		`"$(printf '%s' "$ZK_SASL_USERNAME" | sed 's/[\\"]/\\&/g')" "$(printf '%s' "$ZK_SASL_PASSWORD" | sed 's/[\\"]/\\&/g')" > /tmp/jaas.conf`,
+		writeJaasConfigCommand("Client {\n  username=\""+zkSaslUsernamePlaceholder+"\"\n  password=\""+zkSaslPasswordPlaceholder+"\";\n};", "/tmp/jaas.conf"),
+		"The credentials should be escaped for the quoted JAAS values")
+
+	solrCloud.Spec.ZookeeperRef.SASL.SASLACLs = true
+	envVars, _, _ = createZkConnectionEnvVars(solrCloud, &solrCloud.Status)
+	credsAndAcls = envVars[len(envVars)-1]
+	assert.Contains(t, credsAndAcls.Value, "-DzkACLProvider=org.apache.solr.common.cloud.SaslZkACLProvider", "The SASL ACL provider should be used when SASL ACLs are requested")
+	assert.NoError(t, ValidateZookeeperSASLOptions(solrCloud))
+
+	// Digest ACLs should be kept when using SASL
+	solrCloud.Spec.ZookeeperRef.ConnectionInfo.AllACL = &solr.ZookeeperACL{SecretRef: "acl", UsernameKey: "user", PasswordKey: "pass"}
+	assert.Error(t, ValidateZookeeperSASLOptions(solrCloud), "SASL ACLs cannot be combined with digest ACLs")
+	solrCloud.Spec.ZookeeperRef.SASL.SASLACLs = false
+	envVars, _, _ = createZkConnectionEnvVars(solrCloud, &solrCloud.Status)
+	credsAndAcls = envVars[len(envVars)-1]
+	assert.Contains(t, credsAndAcls.Value, "VMParamsAllAndReadonlyDigestZkACLProvider", "The digest ACL provider should be used when digest ACLs are given")
+	assert.Contains(t, credsAndAcls.Value, "-Dzookeeper.sasl.client=true", "The SASL options should be added to the digest ACL options")
+
+	// A user-provided JAAS config replaces the generated one
+	solrCloud.Spec.SolrSecurity = &solr.SolrSecurityOptions{
+		JaasConfigSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "jaas"}, Key: "jaas.conf"},
+	}
+	solrCloud.Spec.ZookeeperRef.SASL = &solr.ZookeeperSASLOptions{
+		Mechanism:    solr.ZookeeperSASLKerberos,
+		Principal:    "solr@EXAMPLE.COM",
+		KeytabSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "keytab"}, Key: "solr.keytab"},
+	}
+	solrCloud.WithDefaults()
+	assert.Empty(t, zkSASLJaasConfig(solrCloud), "No JAAS config should be generated when one is provided by the user")
+	envVars, _, _ = createZkConnectionEnvVars(solrCloud, &solrCloud.Status)
+	credsAndAcls = envVars[len(envVars)-1]
+	assert.Contains(t, credsAndAcls.Value, "-Djava.security.auth.login.config="+JaasConfigMountPath+"/"+JaasConfigFile, "The user-provided JAAS config should be used")
+	assert.Contains(t, credsAndAcls.Value, "-Dzookeeper.sasl.client.username=zookeeper", "The default ZK server principal name should be used")
+	volumes, volumeMounts := zkSASLVolumes(solrCloud)
+	assert.Len(t, volumes, 1, "Only the keytab volume should be created when no krb5.conf is given")
+	assert.Len(t, volumeMounts, 1, "Only the keytab should be mounted when no krb5.conf is given")
+}
+
+func TestZkClientOptions(t *testing.T) {
+	clientTimeout := int32(60000)
+	juteMaxBuffer := int32(10485760)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+				ClientOptions: &solr.ZookeeperClientOptions{
+					ClientTimeoutMillis: &clientTimeout,
+					JuteMaxBufferBytes:  &juteMaxBuffer,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+
+	// The setup-zk init container is created to bootstrap the security.json
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{SecurityJsonFile: "{}"}, nil)
+	podSpec := statefulSet.Spec.Template.Spec
+	expectedOpts := "-DzkClientTimeout=60000 -Djute.maxbuffer=10485760"
+
+	containers := []corev1.Container{podSpec.Containers[0]}
+	for _, container := range podSpec.InitContainers {
+		if container.Name == SolrZkSetupContainer {
+			containers = append(containers, container)
+		}
+	}
+	assert.Len(t, containers, 2, "The setup-zk init container should be created when a security.json is bootstrapped")
+
+	for _, container := range containers {
+		envVars := map[string]string{}
+		for _, envVar := range container.Env {
+			envVars[envVar.Name] = envVar.Value
+		}
+		assert.Contains(t, envVars["SOLR_OPTS"], expectedOpts, "The ZK client options are not passed to Solr in the %s container", container.Name)
+		if container.Name != SolrNodeContainer {
+			assert.Equal(t, expectedOpts, envVars["ZKCLI_JVM_FLAGS"], "The ZK client options are not passed to zkcli.sh in the %s container", container.Name)
+		}
+	}
+
+	solrCloud.Spec.ZookeeperRef.ClientOptions = nil
+	_, solrOpt, _ := createZkConnectionEnvVars(solrCloud, solrCloudStatus)
+	assert.Empty(t, solrOpt, "No ZK options should be passed to Solr when no ZK client options, ACLs or SASL are given")
+}
+
+func TestZkTLSOptions(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2281", ChRoot: "/solr"},
+				TLS: &solr.ZookeeperTLSOptions{
+					TrustStoreSecret:         &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "zk-tls"}, Key: "truststore.p12"},
+					TrustStorePasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "zk-tls"}, Key: "password"},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+
+	envVars, solrOpt, _ := createZkConnectionEnvVars(solrCloud, solrCloudStatus)
+	assert.Equal(t, "$(SOLR_ZK_CREDS_AND_ACLS)", solrOpt, "The ZK TLS options should be passed to Solr")
+	credsAndAcls := envVars[len(envVars)-1]
+	assert.Equal(t, "SOLR_ZK_CREDS_AND_ACLS", credsAndAcls.Name, "The ZK creds and ACLs should be the last ZK env var")
+	assert.Contains(t, credsAndAcls.Value, "-Dzookeeper.client.secure=true -Dzookeeper.clientCnxnSocket=org.apache.zookeeper.ClientCnxnSocketNetty")
+	assert.Contains(t, credsAndAcls.Value, "-Dzookeeper.ssl.trustStore.location="+ZkTLSTruststoreMountPath+"/"+ZkTLSTruststoreFile)
+	assert.Contains(t, credsAndAcls.Value, "-Dzookeeper.ssl.trustStore.password=$(ZK_SSL_TRUST_STORE_PASSWORD)")
+	assert.NotContains(t, credsAndAcls.Value, "zookeeper.ssl.keyStore", "No keystore should be used when none is given")
+	assert.NotContains(t, credsAndAcls.Value, "SaslZkACLProvider", "The SASL ACL provider should not be used without SASL")
+
+	// Mutual TLS, without hostname verification
+	solrCloud.Spec.ZookeeperRef.TLS.KeyStoreSecret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "zk-client-tls"}, Key: "keystore.p12"}
+	solrCloud.Spec.ZookeeperRef.TLS.KeyStorePasswordSecret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "zk-client-tls"}, Key: "password"}
+	solrCloud.Spec.ZookeeperRef.TLS.DisableHostnameVerification = true
+
+	// The setup-zk init container is created to bootstrap the security.json
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{SecurityJsonFile: "{}"}, nil)
+	podSpec := statefulSet.Spec.Template.Spec
+
+	volumes, _ := zkTLSVolumes(solrCloud)
+	assert.Len(t, volumes, 2, "The truststore and keystore should be mounted")
+	for _, volume := range volumes {
+		assert.Contains(t, podSpec.Volumes, volume, "The %s volume should be added to the pod", volume.Name)
+	}
+
+	containers := []corev1.Container{podSpec.Containers[0]}
+	for _, container := range podSpec.InitContainers {
+		if container.Name == SolrZkSetupContainer {
+			containers = append(containers, container)
+		}
+	}
+	assert.Len(t, containers, 2, "The setup-zk init container should be created when a security.json is bootstrapped")
+
+	for _, container := range containers {
+		envVars := map[string]string{}
+		envVarIndexes := map[string]int{}
+		for i, envVar := range container.Env {
+			envVars[envVar.Name] = envVar.Value
+			envVarIndexes[envVar.Name] = i
+		}
+		assert.Contains(t, envVars["SOLR_OPTS"], "$(SOLR_ZK_CREDS_AND_ACLS)", "The ZK TLS options are not passed to Solr in the %s container", container.Name)
+		assert.Contains(t, envVars["SOLR_ZK_CREDS_AND_ACLS"], "-Dzookeeper.ssl.keyStore.password=$(ZK_SSL_KEY_STORE_PASSWORD)", "The keystore is not used in the %s container", container.Name)
+		assert.Contains(t, envVars["SOLR_ZK_CREDS_AND_ACLS"], "-Dzookeeper.ssl.hostnameVerification=false", "Hostname verification is not disabled in the %s container", container.Name)
+		assert.Less(t, envVarIndexes["ZK_SSL_KEY_STORE_PASSWORD"], envVarIndexes["SOLR_ZK_CREDS_AND_ACLS"], "The keystore password must be defined before it is referenced in the %s container", container.Name)
+
+		mountPaths := map[string]bool{}
+		for _, mount := range container.VolumeMounts {
+			mountPaths[mount.MountPath] = true
+		}
+		assert.True(t, mountPaths[ZkTLSTruststoreMountPath], "The truststore is not mounted in the %s container", container.Name)
+		assert.True(t, mountPaths[ZkTLSKeystoreMountPath], "The keystore is not mounted in the %s container", container.Name)
+	}
+}
+
+func TestRestrictedPodSecurityProfile(t *testing.T) {
+	fsGroup := int64(1000)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			BackupRepositories: []solr.SolrBackupRepository{
+				{Name: "local", Managed: &solr.ManagedRepository{Volume: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			},
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					PodSecurityContext: &corev1.PodSecurityContext{FSGroup: &fsGroup},
+					SidecarContainers:  []corev1.Container{{Name: "sidecar"}},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+	assert.Equal(t, solr.DefaultPodSecurityProfile, solrCloud.Spec.PodSecurityProfile, "Wrong default podSecurityProfile")
+
+	findInitContainer := func(podSpec corev1.PodSpec, name string) *corev1.Container {
+		for i := range podSpec.InitContainers {
+			if podSpec.InitContainers[i].Name == name {
+				return &podSpec.InitContainers[i]
+			}
+		}
+		return nil
+	}
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{SecurityJsonFile: "{}"}, nil).Spec.Template.Spec
+	assert.Contains(t, findInitContainer(podSpec, "cp-solr-xml").Command[2], "chown", "The ownership of managed repositories should be set by the init container by default")
+	assert.Nil(t, podSpec.Containers[0].SecurityContext, "No container security context should be set by default")
+
+	solrCloud.Spec.PodSecurityProfile = solr.RestrictedPodSecurityProfile
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{SecurityJsonFile: "{}"}, nil).Spec.Template.Spec
+	assert.NotContains(t, findInitContainer(podSpec, "cp-solr-xml").Command[2], "chown", "Non-root init containers cannot change the ownership of volumes")
+
+	podSecurityContext := podSpec.SecurityContext
+	assert.Equal(t, fsGroup, *podSecurityContext.FSGroup, "The custom fsGroup should be kept")
+	assert.True(t, *podSecurityContext.RunAsNonRoot)
+	assert.Equal(t, int64(DefaultSolrUser), *podSecurityContext.RunAsUser)
+	assert.Equal(t, corev1.FSGroupChangeOnRootMismatch, *podSecurityContext.FSGroupChangePolicy)
+	assert.Equal(t, []int64{DefaultSolrGroup}, podSecurityContext.SupplementalGroups)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, podSecurityContext.SeccompProfile.Type)
+	assert.Nil(t, solrCloud.Spec.CustomSolrKubeOptions.PodOptions.PodSecurityContext.RunAsNonRoot, "The podSecurityContext of the SolrCloud spec should not be changed")
+
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		if container.Name == "sidecar" {
+			assert.Nil(t, container.SecurityContext, "The security context of custom containers should not be changed")
+			continue
+		}
+		if assert.NotNil(t, container.SecurityContext, "No security context is set for the %s container", container.Name) {
+			assert.True(t, *container.SecurityContext.RunAsNonRoot, "The %s container should run as non-root", container.Name)
+			assert.False(t, *container.SecurityContext.AllowPrivilegeEscalation, "The %s container should not allow privilege escalation", container.Name)
+			assert.Equal(t, []corev1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop, "The %s container should drop all capabilities", container.Name)
+		}
+	}
+
+	solrCloud.Spec.SolrAddressability.HostNetwork = &solr.SolrHostNetworkOptions{}
+	assertInvalidSpec(t, ValidatePodSecurityProfile(solrCloud), "The restricted profile does not allow the host network")
+}
+
+func TestHostNetworkAntiAffinity(t *testing.T) {
+	customAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"solr"}}}}},
+			},
+		},
+	}
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				HostNetwork: &solr.SolrHostNetworkOptions{AdvertisedAddress: solr.HostNetworkNodeIP},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	podSpec := statefulSet.Spec.Template.Spec
+	expectedTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: statefulSet.Spec.Selector.MatchLabels},
+		TopologyKey:   corev1.LabelHostname,
+	}
+	assert.Equal(t, int32(solrCloud.Spec.SolrAddressability.PodPort), podSpec.Containers[0].Ports[0].HostPort, "The podPort should be requested as a hostPort")
+	if assert.NotNil(t, podSpec.Affinity, "Solr pods in the host network should have an affinity") && assert.NotNil(t, podSpec.Affinity.PodAntiAffinity) {
+		assert.Equal(t, []corev1.PodAffinityTerm{expectedTerm}, podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, "Solr pods in the host network should be required to run on separate nodes")
+	}
+
+	// The required anti-affinity should not be duplicated when it is also requested through the availability presets
+	solrCloud.Spec.Availability = &solr.SolrAvailabilityOptions{PodAntiAffinity: solr.RequiredPodAntiAffinity}
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, []corev1.PodAffinityTerm{expectedTerm}, podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, "The required anti-affinity should only be added once")
+
+	// A custom affinity is kept, with the required anti-affinity added to it
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{Affinity: customAffinity}
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, customAffinity.NodeAffinity, podSpec.Affinity.NodeAffinity, "The custom node affinity should be kept")
+	assert.Equal(t, []corev1.PodAffinityTerm{expectedTerm}, podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, "The required anti-affinity should be added to a custom affinity")
+	assert.Nil(t, customAffinity.PodAntiAffinity, "The custom affinity of the SolrCloud spec should not be changed")
+
+	// Without the host network, the custom affinity is used as-is
+	solrCloud.Spec.SolrAddressability.HostNetwork = nil
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, customAffinity, podSpec.Affinity, "The custom affinity should be used as-is outside of the host network")
+}
+
+func TestSpotNodeScheduling(t *testing.T) {
+	customTolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "solr", Effect: corev1.TaintEffectNoSchedule}}
+	customAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"solr"}}}}},
+			},
+		},
+	}
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			SpotNodes: &solr.SolrSpotNodeOptions{
+				NodeLabels: map[string]string{"eks.amazonaws.com/capacityType": "SPOT", "cloud.google.com/gke-spot": "true"},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, solr.PreferredSpotNodeScheduling, solrCloud.Spec.SpotNodes.Scheduling, "Spot Nodes should be preferred by default")
+	assert.Equal(t, solr.DefaultSpotNodeTolerations, solrCloud.Spec.SpotNodes.Tolerations, "The default spot Node tolerations were not set")
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+	spotExpressions := []corev1.NodeSelectorRequirement{
+		{Key: "cloud.google.com/gke-spot", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+		{Key: "eks.amazonaws.com/capacityType", Operator: corev1.NodeSelectorOpIn, Values: []string{"SPOT"}},
+	}
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, solr.DefaultSpotNodeTolerations, podSpec.Tolerations, "The spot Node tolerations should be added to the pods")
+	if assert.NotNil(t, podSpec.Affinity, "Solr pods preferring spot Nodes should have an affinity") && assert.NotNil(t, podSpec.Affinity.NodeAffinity) {
+		assert.Equal(t, []corev1.PreferredSchedulingTerm{{Weight: 100, Preference: corev1.NodeSelectorTerm{MatchExpressions: spotExpressions}}},
+			podSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, "The spot Node labels should be preferred")
+		assert.Nil(t, podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, "Spot Nodes should not be required")
+	}
+
+	// Required spot Nodes are added to the custom required node selector terms, and the tolerations to the custom tolerations
+	solrCloud.Spec.SpotNodes.Scheduling = solr.RequiredSpotNodeScheduling
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{Affinity: customAffinity, Tolerations: customTolerations}
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, append(append([]corev1.Toleration{}, customTolerations...), solr.DefaultSpotNodeTolerations...), podSpec.Tolerations, "The spot Node tolerations should be added to the custom tolerations")
+	assert.Equal(t, []corev1.NodeSelectorTerm{{MatchExpressions: append(append([]corev1.NodeSelectorRequirement{}, customAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions...), spotExpressions...)}},
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, "The spot Node labels should be required in every node selector term")
+	assert.Len(t, customAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions, 1, "The custom affinity of the SolrCloud spec should not be changed")
+	assert.Len(t, customTolerations, 1, "The custom tolerations of the SolrCloud spec should not be changed")
+}
+
+func TestReadOnlyRootFilesystem(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			ReadOnlyRootFilesystem: true,
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					SidecarContainers: []corev1.Container{{Name: "sidecar"}},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	emptyDirs := map[string]bool{}
+	for _, volume := range podSpec.Volumes {
+		if volume.EmptyDir != nil {
+			emptyDirs[volume.Name] = true
+		}
+	}
+	mountPaths := func(container corev1.Container) map[string]string {
+		paths := map[string]string{}
+		for _, mount := range container.VolumeMounts {
+			paths[mount.MountPath] = mount.Name
+		}
+		return paths
+	}
+
+	solrMounts := mountPaths(podSpec.Containers[0])
+	for _, path := range []string{"/tmp", "/var/solr/logs", "/opt/solr/server/logs", "/opt/solr/server/tmp"} {
+		if assert.Contains(t, solrMounts, path, "The Solr container should have a writable volume at %s", path) {
+			assert.True(t, emptyDirs[solrMounts[path]], "The volume mounted at %s should be an emptyDir", path)
+		}
+	}
+
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		if container.Name == "sidecar" {
+			assert.Nil(t, container.SecurityContext, "The security context of custom containers should not be changed")
+			assert.Empty(t, container.VolumeMounts, "No volumes should be mounted into custom containers")
+			continue
+		}
+		if assert.NotNil(t, container.SecurityContext, "No security context is set for the %s container", container.Name) {
+			assert.True(t, *container.SecurityContext.ReadOnlyRootFilesystem, "The %s container should have a read-only root filesystem", container.Name)
+		}
+		assert.Contains(t, mountPaths(container), "/tmp", "The %s container should have a writable /tmp", container.Name)
+	}
+
+	envVars := map[string]string{}
+	for _, envVar := range podSpec.Containers[0].Env {
+		envVars[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "1", envVars["NO_INIT_VAR_SOLR"], "The Solr image should not copy files into /var/solr")
+	assert.Equal(t, DefaultLogXmlPath, envVars["LOG4J_PROPS"], "The default log config should be used from the Solr distribution")
+
+	solrCloud.Spec.ReadOnlyRootFilesystem = false
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Nil(t, podSpec.Containers[0].SecurityContext, "No container security context should be set by default")
+	assert.NotContains(t, mountPaths(podSpec.Containers[0]), "/tmp", "No emptyDir should be mounted at /tmp by default")
+}
+
+func TestRuntimeRestrictionEnvVars(t *testing.T) {
+	securityManager := false
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+			SolrOpts: "-Dsolr.autoSoftCommit.maxTime=10000",
+			RuntimeRestrictions: &solr.SolrRuntimeRestrictionOptions{
+				SecurityManager:  &securityManager,
+				AllowPaths:       []string{"/mnt/backups", "/mnt/cores"},
+				DisableAllowUrls: true,
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo,
+	}
+
+	envVars := map[string]string{}
+	for _, envVar := range GenerateSolrEnvVars(solrCloud, solrCloudStatus, map[string]string{}) {
+		envVars[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "false", envVars["SOLR_SECURITY_MANAGER_ENABLED"])
+	assert.Contains(t, envVars["SOLR_OPTS"], "-Dsolr.allowPaths=/mnt/backups,/mnt/cores -Dsolr.disable.allowUrls=true")
+	assert.NotContains(t, envVars["SOLR_OPTS"], "-Dsolr.allowUrls", "No allowUrls are given")
+	assert.True(t, strings.HasSuffix(envVars["SOLR_OPTS"], solrCloud.Spec.SolrOpts), "The user-provided solrOpts should be added last")
+
+	solrCloud.Spec.RuntimeRestrictions = &solr.SolrRuntimeRestrictionOptions{AllowUrls: []string{"http://solr-a:8983/solr", "https://solr-b:8983/solr"}}
+	envVars = map[string]string{}
+	for _, envVar := range GenerateSolrEnvVars(solrCloud, solrCloudStatus, map[string]string{}) {
+		envVars[envVar.Name] = envVar.Value
+	}
+	_, hasSecurityManager := envVars["SOLR_SECURITY_MANAGER_ENABLED"]
+	assert.False(t, hasSecurityManager, "The default security manager setting of the Solr version should be used")
+	assert.Contains(t, envVars["SOLR_OPTS"], "-Dsolr.allowUrls=http://solr-a:8983/solr,https://solr-b:8983/solr")
+}
+
+func TestIngressMaintenanceWindow(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "example.com",
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	nodeNames := []string{"foo-solrcloud-0", "foo-solrcloud-1"}
+	domains := []string{"example.com"}
+
+	rules, _ := CreateSolrIngressRules(solrCloud, nodeNames, domains)
+	assert.Len(t, rules, 3, "The common endpoint and every node should be in the ingress rules without a maintenance window")
+
+	solrCloud.Spec.SolrAddressability.External.Maintenance = &solr.ExternalMaintenanceWindow{
+		HideCommon: true,
+		HideNodes:  []string{"foo-solrcloud-1"},
+	}
+	rules, _ = CreateSolrIngressRules(solrCloud, nodeNames, domains)
+	if assert.Len(t, rules, 1, "The hidden endpoints should be removed from the ingress rules during a maintenance window") {
+		assert.Equal(t, solrCloud.ExternalNodeUrl("foo-solrcloud-0", "example.com", false), rules[0].Host, "The wrong endpoint was kept during the maintenance window")
+	}
+
+	solrCloud.Spec.SolrAddressability.External.Maintenance.Until = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	rules, _ = CreateSolrIngressRules(solrCloud, nodeNames, domains)
+	assert.Len(t, rules, 3, "Every endpoint should be restored after the maintenance window ends")
+}
+
+func TestUnmanagedExternalAddressability(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:             solr.ExternalDNS,
+					DomainName:         "example.com",
+					UseExternalAddress: true,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Contains(t, GenerateCommonService(solrCloud).Annotations, "external-dns.alpha.kubernetes.io/hostname", "The common service should be annotated for a managed ExternalDNS")
+
+	solrCloud.Spec.SolrAddressability.External.Unmanaged = true
+	assert.NotContains(t, GenerateCommonService(solrCloud).Annotations, "external-dns.alpha.kubernetes.io/hostname", "The common service should not be annotated for an unmanaged ExternalDNS")
+	assert.NotContains(t, GenerateHeadlessService(solrCloud).Annotations, "external-dns.alpha.kubernetes.io/hostname", "The headless service should not be annotated for an unmanaged ExternalDNS")
+
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	solrContainer := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec.Containers[0]
+	for _, envVar := range solrContainer.Env {
+		if envVar.Name == "SOLR_HOST" {
+			assert.Equal(t, "$(POD_HOSTNAME).default.example.com", envVar.Value, "Solr should still advertise the external address when it is unmanaged")
+		}
+	}
+}
+
+func TestZkSetupFailure(t *testing.T) {
+	pod := &corev1.Pod{}
+	failed, _ := ZkSetupFailure(pod)
+	assert.False(t, failed, "A pod without init container statuses has not failed")
+
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:  "cp-solr-xml",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "other"}},
+		},
+		{
+			Name:  SolrZkSetupContainer,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		},
+	}
+	failed, _ = ZkSetupFailure(pod)
+	assert.False(t, failed, "Failures of other init containers should not be reported as Zookeeper failures")
+
+	pod.Status.InitContainerStatuses[1].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	pod.Status.InitContainerStatuses[1].LastTerminationState = corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "Zookeeper at zk:2181 was not available within 300 seconds\n"},
+	}
+	failed, reason := ZkSetupFailure(pod)
+	assert.True(t, failed, "A setup-zk container waiting to restart after failing should be reported")
+	assert.Equal(t, "Zookeeper at zk:2181 was not available within 300 seconds", reason, "The termination message should be used as the reason")
+
+	pod.Status.InitContainerStatuses[1].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}
+	failed, reason = ZkSetupFailure(pod)
+	assert.True(t, failed, "A terminated setup-zk container should be reported")
+	assert.Equal(t, "setup-zk init container exited with code 2", reason, "A default reason should be given when there is no termination message")
+}
+
+func TestConnectionInfoSecret(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ConnectionInfo: &solr.SolrConnectionInfoOptions{},
+		},
+	}
+	status := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		InternalCommonAddress:   "http://foo-solrcloud-common.default",
+	}
+
+	secret := GenerateConnectionInfoSecret(solrCloud, status, nil, nil)
+	assert.Equal(t, "foo-solrcloud-connection-info", secret.Name, "Wrong default name for the connection info secret")
+	assert.Equal(t, "zk:2181/solr", string(secret.Data[ConnectionInfoZkConnectionStringKey]), "Wrong ZK connection string in the connection info secret")
+	assert.Equal(t, "http://foo-solrcloud-common.default", string(secret.Data[ConnectionInfoInternalUrlKey]), "Wrong internal URL in the connection info secret")
+	assert.NotContains(t, secret.Data, ConnectionInfoExternalUrlKey, "No external URL should be given when the SolrCloud is not exposed externally")
+	assert.NotContains(t, secret.Data, ConnectionInfoCACertKey, "No CA cert should be given when none is available")
+	assert.NotContains(t, secret.Data, corev1.BasicAuthUsernameKey, "No credentials should be given when no app user secret is provided")
+	assert.Equal(t, ServiceBindingType, string(secret.Data[ServiceBindingTypeKey]), "The connection info secret must contain the service binding type")
+	assert.Equal(t, "http://foo-solrcloud-common.default/solr", string(secret.Data[ServiceBindingUriKey]), "Wrong service binding URI in the connection info secret")
+
+	externalAddress := "https://default-foo-solrcloud.example.com"
+	status.ExternalCommonAddress = &externalAddress
+	solrCloud.Spec.ConnectionInfo.SecretName = "foo-connection"
+	appUserSecret := &corev1.Secret{
+		Data: map[string][]byte{corev1.BasicAuthUsernameKey: []byte("app"), corev1.BasicAuthPasswordKey: []byte("secret")},
+	}
+	secret = GenerateConnectionInfoSecret(solrCloud, status, []byte("ca"), appUserSecret)
+	assert.Equal(t, "foo-connection", secret.Name, "The provided name for the connection info secret should be used")
+	assert.Equal(t, externalAddress, string(secret.Data[ConnectionInfoExternalUrlKey]), "Wrong external URL in the connection info secret")
+	assert.Equal(t, "ca", string(secret.Data[ConnectionInfoCACertKey]), "Wrong CA cert in the connection info secret")
+	assert.Equal(t, "app", string(secret.Data[corev1.BasicAuthUsernameKey]), "Wrong username in the connection info secret")
+	assert.Equal(t, "secret", string(secret.Data[corev1.BasicAuthPasswordKey]), "Wrong password in the connection info secret")
+}
+
+func TestPodTemplateVarsInCustomPodMetadata(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					Labels: map[string]string{
+						"cloud": "$(SOLR_CLOUD_NAME)",
+						"zone":  "$(ZONE)",
+					},
+					Annotations: map[string]string{
+						"static": "$(UNKNOWN)",
+						"owner":  "$(NAMESPACE)/$(SOLR_CLOUD_NAME)",
+						"external-dns.alpha.kubernetes.io/hostname": "solr-$(POD_ORDINAL).$(SOLR_CLOUD_NAME).example.com",
+					},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	podTemplate := statefulSet.Spec.Template
+	assert.Equal(t, "foo", podTemplate.Labels["cloud"], "Cloud variables should be resolved in the pod template")
+	assert.NotContains(t, podTemplate.Labels, "zone", "Labels with per-pod variables cannot be set in the pod template")
+	assert.Equal(t, "solr/foo", podTemplate.Annotations["owner"], "Cloud variables should be resolved in the pod template")
+	assert.Equal(t, "$(UNKNOWN)", podTemplate.Annotations["static"], "Unknown variables should be left as-is")
+	assert.NotContains(t, podTemplate.Annotations, "external-dns.alpha.kubernetes.io/hostname", "Annotations with per-pod variables cannot be set in the pod template")
+	assert.Equal(t, "$(NAMESPACE)/$(SOLR_CLOUD_NAME)", solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Annotations["owner"], "The SolrCloud spec should not be modified")
+
+	assert.True(t, UsesZonePodTemplateVar(solrCloud), "The zone variable is used in the pod labels")
+	podLabels, podAnnotations := GeneratePerPodMetadata(solrCloud, "foo-solrcloud-2", "us-east-1a")
+	assert.Equal(t, map[string]string{"zone": "us-east-1a"}, podLabels, "Wrong per-pod labels")
+	assert.Equal(t, map[string]string{"external-dns.alpha.kubernetes.io/hostname": "solr-2.foo.example.com"}, podAnnotations, "Wrong per-pod annotations")
+}
+
+func TestStatefulSetInputsHash(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	configInfo := map[string]string{SolrXmlMd5Annotation: "abc"}
+
+	hash, err := StatefulSetInputsHash(solrCloud, solrCloudStatus, nil, configInfo, nil)
+	assert.NoError(t, err)
+	sameHash, _ := StatefulSetInputsHash(solrCloud.DeepCopy(), solrCloudStatus.DeepCopy(), nil, map[string]string{SolrXmlMd5Annotation: "abc"}, nil)
+	assert.Equal(t, hash, sameHash, "The hash should be the same for the same inputs")
+
+	// Changes to the status that the StatefulSet does not use should not change the hash
+	solrCloudStatus.ReadyReplicas = 3
+	sameHash, _ = StatefulSetInputsHash(solrCloud, solrCloudStatus, nil, configInfo, nil)
+	assert.Equal(t, hash, sameHash, "Status fields that are not used in the StatefulSet should not change the hash")
+
+	configInfo[SolrXmlMd5Annotation] = "def"
+	newHash, _ := StatefulSetInputsHash(solrCloud, solrCloudStatus, nil, configInfo, nil)
+	assert.NotEqual(t, hash, newHash, "A config hash change should change the hash")
+	configInfo[SolrXmlMd5Annotation] = "abc"
+
+	replicas := int32(5)
+	changedCloud := solrCloud.DeepCopy()
+	changedCloud.Spec.Replicas = &replicas
+	newHash, _ = StatefulSetInputsHash(changedCloud, solrCloudStatus, nil, configInfo, nil)
+	assert.NotEqual(t, hash, newHash, "A spec change should change the hash")
+
+	tls := &TLSCerts{ServerConfig: &TLSConfig{CertMd5: "123"}}
+	tlsHash, _ := StatefulSetInputsHash(solrCloud, solrCloudStatus, nil, configInfo, tls)
+	tls.ServerConfig.CertMd5 = "456"
+	newHash, _ = StatefulSetInputsHash(solrCloud, solrCloudStatus, nil, configInfo, tls)
+	assert.NotEqual(t, tlsHash, newHash, "A TLS cert change should change the hash")
+}
+
+func TestCustomSolrContainerCommand(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				SolrContainerOptions: &solr.SolrContainerOptions{
+					Command: []string{"/opt/profiler/run.sh"},
+					Args:    []string{"solr-foreground"},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	solrContainer := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"/opt/profiler/run.sh"}, solrContainer.Command, "The custom command was not used")
+	assert.Equal(t, []string{"solr-foreground"}, solrContainer.Args, "The custom args were not used")
+	assert.NotNil(t, solrContainer.Lifecycle.PreStop, "The Solr Operator's lifecycle hooks should still be set")
+	assert.NotEmpty(t, solrContainer.Env, "The Solr Operator's environment variables should still be set")
+}
+
+func TestDefaultStartupProbe(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			Probes: solr.SolrProbesOptions{
+				Startup: solr.SolrStartupProbeOptions{FailureThreshold: 180},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	solrContainer := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec.Containers[0]
+	if assert.NotNil(t, solrContainer.StartupProbe, "A startupProbe should be generated by default") {
+		assert.Equal(t, solr.DefaultStartupProbePeriodSeconds, solrContainer.StartupProbe.PeriodSeconds, "Wrong default startupProbe periodSeconds")
+		assert.Equal(t, solr.DefaultStartupProbeTimeoutSeconds, solrContainer.StartupProbe.TimeoutSeconds, "Wrong default startupProbe timeoutSeconds")
+		assert.Equal(t, int32(180), solrContainer.StartupProbe.FailureThreshold, "The startupProbe failureThreshold from the probes options was not used")
+		assert.Equal(t, solrContainer.LivenessProbe.Handler, solrContainer.StartupProbe.Handler, "The startupProbe should use the same handler as the livenessProbe")
+	}
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		StartupProbe: &corev1.Probe{PeriodSeconds: 5},
+	}
+	solrContainer = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, int32(5), solrContainer.StartupProbe.PeriodSeconds, "The custom startupProbe periodSeconds should take precedence")
+	assert.Equal(t, int32(180), solrContainer.StartupProbe.FailureThreshold, "Options missing from the custom startupProbe should use the probes options")
+}
+
+func TestConfigMapFiles(t *testing.T) {
+	restartOnUpdate := false
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				ConfigMapFiles: []solr.ConfigMapFile{
+					{Name: "jetty-include", ConfigMap: "jetty-config", Key: "jetty-custom.xml", MountPath: "/opt/solr/server/etc/jetty-custom.xml"},
+					{Name: "synonyms", ConfigMap: "analysis", Key: "synonyms.txt", MountPath: "/var/solr/analysis/synonyms.txt", RestartOnUpdate: &restartOnUpdate},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.True(t, *solrCloud.Spec.CustomSolrKubeOptions.ConfigMapFiles[0].RestartOnUpdate, "ConfigMap files should restart pods on updates by default")
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	reconcileConfigInfo := map[string]string{ConfigMapFileMd5AnnotationPrefix + "jetty-include": "abc123"}
+	podTemplate := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, reconcileConfigInfo, nil).Spec.Template
+	assert.Equal(t, "abc123", podTemplate.Annotations[ConfigMapFileMd5AnnotationPrefix+"jetty-include"], "The hash of the file content should be tracked in the pod annotations")
+	assert.NotContains(t, podTemplate.Annotations, ConfigMapFileMd5AnnotationPrefix+"synonyms", "Files that do not restart pods on updates should not be tracked")
+
+	for _, file := range solrCloud.Spec.CustomSolrKubeOptions.ConfigMapFiles {
+		var volume *corev1.Volume
+		for i := range podTemplate.Spec.Volumes {
+			if podTemplate.Spec.Volumes[i].Name == ConfigMapFileVolumePrefix+file.Name {
+				volume = &podTemplate.Spec.Volumes[i]
+			}
+		}
+		if assert.NotNil(t, volume, "No volume for the ConfigMap file %s", file.Name) && assert.NotNil(t, volume.ConfigMap, "The volume for the ConfigMap file %s should use the ConfigMap", file.Name) {
+			assert.Equal(t, file.ConfigMap, volume.ConfigMap.Name, "Wrong ConfigMap for the file %s", file.Name)
+			assert.Equal(t, []corev1.KeyToPath{{Key: file.Key, Path: file.Key}}, volume.ConfigMap.Items, "Wrong ConfigMap items for the file %s", file.Name)
+		}
+		assert.Contains(t, podTemplate.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: ConfigMapFileVolumePrefix + file.Name, MountPath: file.MountPath, SubPath: file.Key, ReadOnly: true}, "Wrong volume mount for the file %s", file.Name)
+	}
+}
+
+func TestBackupRepoCredentialsAnnotation(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	podTemplate := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template
+	assert.NotContains(t, podTemplate.Annotations, BackupRepoCredentialsAnnotation, "Clouds without repository credentials should not track them")
+
+	podTemplate = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{BackupRepoCredentialsAnnotation: "abc123"}, nil).Spec.Template
+	assert.Equal(t, "abc123", podTemplate.Annotations[BackupRepoCredentialsAnnotation], "The hash of the repository credentials should be tracked in the pod annotations, so that rotating them restarts the pods")
+}
+
+func TestTerminationGracePeriodFromSolrStopWait(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					EnvVariables: []corev1.EnvVar{{Name: "SOLR_STOP_WAIT", Value: "300"}},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	assert.Equal(t, int64(305), *statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, "The terminationGracePeriodSeconds should be derived from the custom SOLR_STOP_WAIT")
+	assert.NoError(t, ValidateSolrStopWait(solrCloud), "A SOLR_STOP_WAIT without a terminationGracePeriodSeconds is valid")
+
+	gracePeriod := int64(120)
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.TerminationGracePeriodSeconds = &gracePeriod
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	assert.Equal(t, int64(120), *statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, "An explicit terminationGracePeriodSeconds should be used")
+	assert.Error(t, ValidateSolrStopWait(solrCloud), "A SOLR_STOP_WAIT longer than the terminationGracePeriodSeconds should be rejected")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.EnvVariables[0].Value = "115"
+	assert.NoError(t, ValidateSolrStopWait(solrCloud), "A SOLR_STOP_WAIT that leaves enough time to stop Solr should be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.EnvVariables[0].Value = "2m"
+	assert.Error(t, ValidateSolrStopWait(solrCloud), "A SOLR_STOP_WAIT that is not a number should be rejected")
+}
+
+func TestLameduckPeriod(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			LameduckSeconds: 15,
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, int64(75), *podSpec.TerminationGracePeriodSeconds, "The lameduck period should be added to the terminationGracePeriodSeconds")
+	assert.Equal(t, []string{"sh", "-c", "sleep 15; solr stop -p 8983"}, podSpec.Containers[0].Lifecycle.PreStop.Exec.Command, "Solr should keep serving during the lameduck period before it is stopped")
+	for _, envVar := range podSpec.Containers[0].Env {
+		if envVar.Name == "SOLR_STOP_WAIT" {
+			assert.Equal(t, "55", envVar.Value, "The lameduck period should not be part of the time Solr has to stop")
+		}
+	}
+	assert.NoError(t, ValidateSolrStopWait(solrCloud))
+
+	gracePeriod := int64(18)
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{TerminationGracePeriodSeconds: &gracePeriod}
+	assert.Error(t, ValidateSolrStopWait(solrCloud), "A lameduck period that does not fit in the terminationGracePeriodSeconds should be rejected")
+
+	gracePeriod = int64(120)
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.EnvVariables = []corev1.EnvVar{{Name: "SOLR_STOP_WAIT", Value: "100"}}
+	assert.NoError(t, ValidateSolrStopWait(solrCloud))
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.EnvVariables[0].Value = "101"
+	assert.Error(t, ValidateSolrStopWait(solrCloud), "The SOLR_STOP_WAIT and the lameduck period together should fit in the terminationGracePeriodSeconds")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{Lifecycle: &corev1.Lifecycle{}}
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, int64(60), *podSpec.TerminationGracePeriodSeconds, "There is no lameduck period with a custom lifecycle")
+}
+
+func TestTrustBundleConfigMapTruststore(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			SolrTLS: &solr.SolrTLSOptions{
+				PKCS12Secret:             &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "foo-tls"}, Key: "keystore.p12"},
+				KeyStorePasswordSecret:   &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "foo-tls"}, Key: "password"},
+				TrustBundleConfigMap:     &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "org-ca"}, Key: "ca.crt"},
+				RestartOnTLSSecretUpdate: true,
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	tls := TLSCertsForSolrCloud(solrCloud)
+	tls.ServerConfig.TrustBundleMd5 = "bundle-md5"
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, tls).Spec.Template
+	assert.Equal(t, "bundle-md5", podSpec.Annotations[SolrTlsTrustBundleMd5Annotation], "The CA bundle hash should be tracked to restart pods when the bundle changes")
+
+	var initContainer *corev1.Container
+	for i, container := range podSpec.Spec.InitContainers {
+		if container.Name == "gen-pkcs12-truststore" {
+			initContainer = &podSpec.Spec.InitContainers[i]
+		}
+	}
+	if assert.NotNil(t, initContainer, "An initContainer should generate the truststore from the CA bundle") {
+		assert.Equal(t, podSpec.Spec.Containers[0].Image, initContainer.Image, "The truststore should be generated with the keytool from the Solr image")
+		assert.Equal(t, solrCloud.Spec.SolrTLS.KeyStorePasswordSecret, initContainer.Env[0].ValueFrom.SecretKeyRef, "The keystore password should be used when no truststore password is given")
+	}
+
+	var bundleVolume *corev1.Volume
+	for i, volume := range podSpec.Spec.Volumes {
+		if volume.Name == "trust-bundle" {
+			bundleVolume = &podSpec.Spec.Volumes[i]
+		}
+	}
+	if assert.NotNil(t, bundleVolume, "The CA bundle ConfigMap should be mounted") && assert.NotNil(t, bundleVolume.ConfigMap) {
+		assert.Equal(t, "org-ca", bundleVolume.ConfigMap.Name, "The wrong ConfigMap is mounted for the CA bundle")
+	}
+
+	for _, envVar := range podSpec.Spec.Containers[0].Env {
+		if envVar.Name == "SOLR_SSL_TRUST_STORE" {
+			assert.Equal(t, DefaultTrustStorePath+"/"+DefaultPkcs12TruststoreFile, envVar.Value, "Solr should use the truststore generated from the CA bundle")
+		}
+	}
+}
+
+func TestKeyStoreType(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			SolrTLS: &solr.SolrTLSOptions{
+				PKCS12Secret:           &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "foo-tls"}, Key: "keystore.p12"},
+				KeyStorePasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "foo-tls"}, Key: "password"},
+				TrustBundleConfigMap:   &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "org-ca"}, Key: "ca.crt"},
+				KeyStoreType:           solr.BCFKSKeyStore,
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	tls := TLSCertsForSolrCloud(solrCloud)
+	tls.ServerConfig.NeedsPkcs12InitContainer = true
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, tls).Spec.Template.Spec
+	envVars := map[string]string{}
+	for _, envVar := range podSpec.Containers[0].Env {
+		envVars[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "BCFKS", envVars["SOLR_SSL_KEY_STORE_TYPE"])
+	assert.Equal(t, "BCFKS", envVars["SOLR_SSL_TRUST_STORE_TYPE"])
+
+	initContainers := map[string]corev1.Container{}
+	for _, container := range podSpec.InitContainers {
+		initContainers[container.Name] = container
+	}
+	if assert.Contains(t, initContainers, "gen-pkcs12-keystore", "An initContainer should generate the keystore from the TLS cert") {
+		cmd := initContainers["gen-pkcs12-keystore"].Command[2]
+		assert.Contains(t, cmd, "-out "+DefaultWritableKeyStorePath+"/openssl-"+DefaultPkcs12KeystoreFile, "openssl can only generate a pkcs12 keystore")
+		assert.Contains(t, cmd, "keytool -importkeystore", "The pkcs12 keystore of openssl should be converted")
+		assert.Contains(t, cmd, "-destkeystore "+DefaultWritableKeyStorePath+"/"+DefaultPkcs12KeystoreFile+" -deststoretype BCFKS")
+	}
+	if assert.Contains(t, initContainers, "gen-pkcs12-truststore", "An initContainer should generate the truststore from the CA bundle") {
+		assert.Contains(t, initContainers["gen-pkcs12-truststore"].Command[2], "-storetype BCFKS")
+	}
+	assert.Contains(t, secureProbeTLSJavaSysProps(solrCloud), "-Djavax.net.ssl.keyStoreType=BCFKS -Djavax.net.ssl.trustStoreType=BCFKS")
+
+	solrCloud.Spec.SolrTLS.KeyStoreType = ""
+	serverConfig := TLSCertsForSolrCloud(solrCloud).ServerConfig
+	assert.NotContains(t, serverConfig.generatePkcs12InitContainer("solr", corev1.PullIfNotPresent, nil).Command[2], "keytool", "The keystore generated by openssl should be used as is")
+	assert.NotContains(t, secureProbeTLSJavaSysProps(solrCloud), "StoreType", "The store types should not be passed to the probe for PKCS12 stores")
+	for _, envVar := range serverConfig.serverEnvVars() {
+		assert.NotEqual(t, "SOLR_SSL_KEY_STORE_TYPE", envVar.Name, "The store types should not be passed to bin/solr for PKCS12 stores")
+	}
+
+	clientTLS := &TLSConfig{Options: &solr.SolrTLSOptions{
+		PKCS12Secret:           solrCloud.Spec.SolrTLS.PKCS12Secret,
+		KeyStorePasswordSecret: solrCloud.Spec.SolrTLS.KeyStorePasswordSecret,
+		KeyStoreType:           solr.JKSKeyStore,
+	}}
+	assert.Contains(t, clientTLS.clientJavaOpts(), "-Djavax.net.ssl.trustStoreType=JKS")
+	assert.Contains(t, clientTLS.clientJavaOpts(), "-Djavax.net.ssl.keyStoreType=JKS")
+	assert.Contains(t, clientTLS.clientEnvVars(), corev1.EnvVar{Name: "SOLR_SSL_CLIENT_KEY_STORE_TYPE", Value: "JKS"})
+}
+
+func TestBootstrapSecurityOperatorUser(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{AuthenticationType: solr.Basic, OperatorUsername: "k8s-operator"},
+		},
+	}
+	solrCloud.WithDefaults()
+
+	basicAuthSecret, bootstrapSecret := GenerateBasicAuthSecretWithBootstrap(solrCloud, nil)
+	assert.Equal(t, "k8s-operator", string(basicAuthSecret.Data[corev1.BasicAuthUsernameKey]), "The operator should use the configured username")
+	assert.NotEmpty(t, basicAuthSecret.Data[corev1.BasicAuthPasswordKey], "The operator's user should have a password")
+
+	securityJson := map[string]map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(bootstrapSecret.Data[SecurityJsonFile], &securityJson), "The bootstrapped security.json is not valid JSON")
+	assert.Contains(t, securityJson["authentication"]["credentials"], "k8s-operator", "The operator's user should be created in the security.json")
+	assert.NotContains(t, securityJson["authentication"]["credentials"], solr.DefaultBasicAuthUsername, "The default operator user should not be created")
+	userRoles := securityJson["authorization"]["user-role"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"k8s"}, userRoles["k8s-operator"], "The operator's user should only have the k8s role")
+	assert.Equal(t, []interface{}{"users", "k8s"}, userRoles["solr"], "The solr user should keep the k8s role, which existing clients of the bootstrapped security.json rely on")
+}
+
+func TestBootstrapSecurityCredentialsSecret(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "solr"},
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{AuthenticationType: solr.Basic, BootstrapCredentialsSecret: "vault-solr-users"},
+		},
+	}
+	solrCloud.WithDefaults()
+	credentialsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-solr-users", Namespace: "solr"},
+		Data: map[string][]byte{
+			"admin": []byte("admin-password"),
+			"solr":  []byte("solr-password"),
+		},
+	}
+	assert.Error(t, ValidateBootstrapCredentialsSecret(solrCloud, credentialsSecret), "A password is required for the operator's user")
+
+	credentialsSecret.Data[solr.DefaultBasicAuthUsername] = []byte("operator-password")
+	assert.NoError(t, ValidateBootstrapCredentialsSecret(solrCloud, credentialsSecret))
+
+	basicAuthSecret, bootstrapSecret := GenerateBasicAuthSecretWithBootstrap(solrCloud, credentialsSecret)
+	assert.Equal(t, "operator-password", string(basicAuthSecret.Data[corev1.BasicAuthPasswordKey]), "The operator should use the provided password")
+	assert.Equal(t, "admin-password", string(bootstrapSecret.Data["admin"]), "The provided admin password should be used")
+	assert.Equal(t, "solr-password", string(bootstrapSecret.Data["solr"]), "The provided solr password should be used")
+
+	securityJson := map[string]map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(bootstrapSecret.Data[SecurityJsonFile], &securityJson), "The bootstrapped security.json is not valid JSON")
+	credentials := securityJson["authentication"]["credentials"].(map[string]interface{})
+	assert.Len(t, credentials, 3, "All three users should be bootstrapped")
+	assert.NotContains(t, string(bootstrapSecret.Data[SecurityJsonFile]), "admin-password", "The passwords should only be stored as hashes in the security.json")
+}
+
+func TestGenerateSolrProbes(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					LivenessProbe: &corev1.Probe{PeriodSeconds: 30},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+
+	probes, volume, volumeMount := GenerateSolrProbes(solrCloud, nil)
+	assert.Nil(t, volume, "No volume is needed for plain HTTP probes")
+	assert.Nil(t, volumeMount, "No volume mount is needed for plain HTTP probes")
+	if assert.NotNil(t, probes.Readiness.HTTPGet, "The probes should use HTTP when Solr does not require authentication") {
+		assert.Equal(t, "/solr"+DefaultProbePath, probes.Readiness.HTTPGet.Path, "Wrong probe path")
+	}
+	assert.EqualValues(t, 30, probes.Liveness.PeriodSeconds, "The custom liveness probe period was not used")
+	assert.EqualValues(t, 20, probes.Liveness.InitialDelaySeconds, "Fields missing from the custom liveness probe should keep their defaults")
+
+	solrCloud.Spec.SolrSecurity = &solr.SolrSecurityOptions{AuthenticationType: solr.Basic, ProbesRequireAuth: true}
+	probes, _, _ = GenerateSolrProbes(solrCloud, nil)
+	assert.NotNil(t, probes.Readiness.Exec, "The probes should use a command when Solr requires authentication")
+	assert.EqualValues(t, 5, probes.Readiness.TimeoutSeconds, "Probe commands should have a longer timeout")
+}
+
+func TestDefaultInitContainerResources(t *testing.T) {
+	defaultResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+	}
+	customInitContainer := corev1.Container{Name: "custom-init", Image: "busybox"}
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					InitContainers:                []corev1.Container{customInitContainer},
+					DefaultInitContainerResources: defaultResources,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+	}
+
+	initContainers := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec.InitContainers
+	if assert.Len(t, initContainers, 2, "Wrong number of init containers") {
+		assert.Equal(t, "cp-solr-xml", initContainers[0].Name, "The solr.xml init container should be first")
+		assert.Equal(t, defaultResources, initContainers[0].Resources, "The default init container resources were not used for the solr.xml init container")
+		assert.Equal(t, customInitContainer, initContainers[1], "The custom init container should not be changed")
+	}
+}
+
+func TestImagePullSecrets(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrImage:    &solr.ContainerImage{ImagePullSecret: "solr-registry"},
+			BusyBoxImage: &solr.ContainerImage{ImagePullSecret: "busybox-registry"},
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "sidecar-registry"}},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+	}
+
+	SetDefaultImagePullSecrets([]string{"private-registry", "solr-registry"})
+	defer SetDefaultImagePullSecrets(nil)
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	assert.Equal(t, []corev1.LocalObjectReference{
+		{Name: "sidecar-registry"},
+		{Name: "solr-registry"},
+		{Name: "busybox-registry"},
+		{Name: "private-registry"},
+	}, statefulSet.Spec.Template.Spec.ImagePullSecrets, "The pull secrets of the pod, the images and the operator should each be used once")
+}
+
+func TestZkSetupImage(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrImage: &solr.ContainerImage{Repository: "my-registry/solr-with-plugins", Tag: "8.11"},
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181"},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+	}
+	reconcileConfigInfo := map[string]string{SecurityJsonFile: "security.json"}
+
+	_, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, "data", reconcileConfigInfo)
+	assert.Equal(t, "my-registry/solr-with-plugins:8.11", zkSetupContainer.Image, "The Solr image should be used to set up Zookeeper by default")
+
+	solrCloud.Spec.ZkSetupImage = &solr.ContainerImage{Repository: "my-registry/solr-zkcli"}
+	solrCloud.WithDefaults()
+	_, zkSetupContainer = generateZKInteractionInitContainer(solrCloud, solrCloudStatus, "data", reconcileConfigInfo)
+	assert.Equal(t, "my-registry/solr-zkcli:8.11", zkSetupContainer.Image, "The zkSetupImage should be used, with the tag of the Solr image by default")
+	assert.Equal(t, solrCloud.Spec.SolrImage.PullPolicy, zkSetupContainer.ImagePullPolicy, "The pull policy of the Solr image should be used by default")
+	assert.Empty(t, solrCloud.Spec.ZkSetupImage.Tag, "The tag of the Solr image should not be written into the zkSetupImage of the spec")
+
+	solrCloud.Spec.SolrImage.Tag = "9.0"
+	_, zkSetupContainer = generateZKInteractionInitContainer(solrCloud, solrCloudStatus, "data", reconcileConfigInfo)
+	assert.Equal(t, "my-registry/solr-zkcli:9.0", zkSetupContainer.Image, "The zkSetupImage should follow upgrades of the Solr image, when it has no tag")
+}
+
+func TestAvailabilityPresets(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Availability: &solr.SolrAvailabilityOptions{},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+	}
+	assert.Equal(t, solr.NoPodAntiAffinity, solrCloud.Spec.Availability.PodAntiAffinity, "The pod anti-affinity should default to none")
+	assert.Empty(t, solrCloud.Spec.Availability.ZoneTopologyKey, "The zone topology key should only be defaulted when spreading across zones")
+
+	podSpec := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Nil(t, podSpec.Affinity, "No affinity should be generated when no presets are used")
+	assert.Empty(t, podSpec.TopologySpreadConstraints, "No topology spread constraints should be generated when no presets are used")
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"solr-cloud": "foo", "technology": "solr-cloud"}}
+	solrCloud.Spec.Availability.PodAntiAffinity = solr.RequiredPodAntiAffinity
+	solrCloud.Spec.Availability.SpreadAcrossZones = true
+	solrCloud.WithDefaults()
+	assert.Equal(t, solr.DefaultZoneTopologyKey, solrCloud.Spec.Availability.ZoneTopologyKey, "The zone topology key should be defaulted when spreading across zones")
+
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	if assert.NotNil(t, podSpec.Affinity, "An affinity should be generated") && assert.NotNil(t, podSpec.Affinity.PodAntiAffinity, "A pod anti-affinity should be generated") {
+		assert.Equal(t, []corev1.PodAffinityTerm{{LabelSelector: selector, TopologyKey: "kubernetes.io/hostname"}},
+			podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, "Solr pods should be required to run on different Nodes")
+		assert.Empty(t, podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, "No preferred anti-affinity should be generated")
+	}
+	assert.Equal(t, []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: solr.DefaultZoneTopologyKey, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector}},
+		podSpec.TopologySpreadConstraints, "Solr pods should be spread across zones")
+
+	solrCloud.Spec.Availability.PodAntiAffinity = solr.PreferredPodAntiAffinity
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	if assert.NotNil(t, podSpec.Affinity, "An affinity should be generated") && assert.NotNil(t, podSpec.Affinity.PodAntiAffinity, "A pod anti-affinity should be generated") {
+		assert.Equal(t, []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{LabelSelector: selector, TopologyKey: "kubernetes.io/hostname"}}},
+			podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, "Solr pods should preferably run on different Nodes")
+	}
+
+	customAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{Affinity: customAffinity}
+	podSpec = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Spec.Template.Spec
+	assert.Equal(t, customAffinity, podSpec.Affinity, "A custom affinity should take precedence over the generated one")
+	assert.Len(t, podSpec.TopologySpreadConstraints, 1, "Solr pods should still be spread across zones with a custom affinity")
+}
+
+func TestServicePublishNotReadyAddresses(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	solrCloud.WithDefaults()
+	assert.True(t, GenerateHeadlessService(solrCloud).Spec.PublishNotReadyAddresses, "The headless service should publish not ready addresses by default")
+	assert.True(t, GenerateNodeService(solrCloud, "foo-solrcloud-0").Spec.PublishNotReadyAddresses, "The node services should publish not ready addresses by default")
+
+	publish := false
+	solrCloud.Spec.CustomSolrKubeOptions.HeadlessServiceOptions = &solr.ServiceOptions{PublishNotReadyAddresses: &publish}
+	solrCloud.Spec.CustomSolrKubeOptions.NodeServiceOptions = &solr.ServiceOptions{PublishNotReadyAddresses: &publish}
+	assert.False(t, GenerateHeadlessService(solrCloud).Spec.PublishNotReadyAddresses, "The headless service option should be used")
+	assert.False(t, GenerateNodeService(solrCloud, "foo-solrcloud-0").Spec.PublishNotReadyAddresses, "The node service option should be used")
+}
+
+func TestMetadataPropagation(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Labels:      map[string]string{"cost-center": "search", "team": "relevance"},
+			Annotations: map[string]string{"policy.example.com/tier": "gold", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		Spec: solr.SolrCloudSpec{
+			StorageOptions: solr.SolrDataStorageOptions{
+				PersistentStorage: &solr.SolrPersistentDataStorageOptions{},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	assert.Equal(t, "relevance", statefulSet.Spec.Template.Labels["team"], "Every label should be propagated by default")
+	assert.NotContains(t, statefulSet.Spec.VolumeClaimTemplates[0].Labels, "team", "Labels should only be propagated to the PVCs when opted in")
+	assert.NotContains(t, statefulSet.Spec.Template.Annotations, "policy.example.com/tier", "No annotations should be propagated by default")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PropagateLabels = &solr.MetadataPropagationPolicy{Prefixes: []string{"cost-"}}
+	solrCloud.Spec.CustomSolrKubeOptions.PropagateAnnotations = &solr.MetadataPropagationPolicy{Prefixes: []string{"policy.example.com/"}}
+	solrCloud.Spec.CustomSolrKubeOptions.CommonServiceOptions = &solr.ServiceOptions{Annotations: map[string]string{"policy.example.com/tier": "silver"}}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	assert.NotContains(t, statefulSet.Spec.VolumeClaimTemplates[0].Labels, "cost-center", "Labels should only be propagated to the PVCs when opted in")
+	assert.NotContains(t, statefulSet.Spec.VolumeClaimTemplates[0].Annotations, "policy.example.com/tier", "Annotations should only be propagated to the PVCs when opted in")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PropagateLabels.PersistentVolumeClaims = true
+	solrCloud.Spec.CustomSolrKubeOptions.PropagateAnnotations.PersistentVolumeClaims = true
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil)
+	for _, labels := range []map[string]string{statefulSet.Labels, statefulSet.Spec.Template.Labels, statefulSet.Spec.VolumeClaimTemplates[0].Labels, GenerateHeadlessService(solrCloud).Labels} {
+		assert.Equal(t, "search", labels["cost-center"], "Labels matching a prefix should be propagated")
+		assert.NotContains(t, labels, "team", "Labels not matching a prefix should not be propagated")
+	}
+	for _, annotations := range []map[string]string{statefulSet.Annotations, statefulSet.Spec.Template.Annotations, statefulSet.Spec.VolumeClaimTemplates[0].Annotations, GenerateHeadlessService(solrCloud).Annotations} {
+		assert.Equal(t, "gold", annotations["policy.example.com/tier"], "Annotations matching a prefix should be propagated")
+		assert.NotContains(t, annotations, "kubectl.kubernetes.io/last-applied-configuration", "Annotations not matching a prefix should not be propagated")
+	}
+	assert.Equal(t, "silver", GenerateCommonService(solrCloud).Annotations["policy.example.com/tier"], "Custom annotations should take precedence over propagated annotations")
+}
+
+func TestPodNamePrefix(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, []string{"foo-solrcloud-0", "foo-solrcloud-1"}, solrCloud.GetAllSolrNodeNames(), "The pods should be named after the SolrCloud by default")
+
+	solrCloud.Spec.CustomSolrKubeOptions.StatefulSetOptions = &solr.StatefulSetOptions{PodNamePrefix: "search-blue"}
+	solrCloudStatus := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+	assert.Equal(t, "search-blue", GenerateStatefulSet(solrCloud, solrCloudStatus, nil, map[string]string{}, nil).Name, "The StatefulSet should be named after the pod name prefix")
+	assert.Equal(t, []string{"search-blue-0", "search-blue-1"}, solrCloud.GetAllSolrNodeNames(), "The pods should be named after the pod name prefix")
+}
+
+func TestClusterFormationCondition(t *testing.T) {
+	replicas := int32(4)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       solr.SolrCloudSpec{Replicas: &replicas},
+	}
+	assert.Nil(t, ClusterFormationCondition(solrCloud, 0), "No condition should be reported without minReadyNodesForReady")
+
+	minReady := intstr.FromString("50%")
+	solrCloud.Spec.Availability = &solr.SolrAvailabilityOptions{MinReadyNodesForReady: &minReady}
+	condition := ClusterFormationCondition(solrCloud, 1)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status, "The cluster should not be formed with 1 of the 2 required nodes ready")
+
+	condition = ClusterFormationCondition(solrCloud, 2)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status, "The cluster should be formed once 2 nodes are ready")
+	solrCloud.Status.Conditions = []metav1.Condition{*condition}
+
+	assert.Equal(t, metav1.ConditionTrue, ClusterFormationCondition(solrCloud, 1).Status, "A formed cluster should stay formed while any node is ready")
+	assert.Equal(t, metav1.ConditionFalse, ClusterFormationCondition(solrCloud, 0).Status, "The cluster should have to form again after all nodes are down")
+
+	service := GenerateCommonService(solrCloud)
+	ExcludeAllPodsFromService(service)
+	assert.Equal(t, "pending", service.Spec.Selector[SolrClusterFormationLabel], "The common service should select no pods before the cluster is formed")
+}
+
+func TestSolrContainerBuildingBlocks(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrOpts: "-Dsolr.autoSoftCommit.maxTime=10000",
+		},
+	}
+	solrCloud.WithDefaults()
+	status := &solr.SolrCloudStatus{
+		ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+	}
+
+	solrContainer := GenerateStatefulSet(solrCloud, status, nil, map[string]string{}, nil).Spec.Template.Spec.Containers[0]
+	probes, _, _ := GenerateSolrProbes(solrCloud, nil)
+	assert.Equal(t, probes.Startup, solrContainer.StartupProbe, "The StatefulSet should use the generated startup probe")
+	assert.Equal(t, probes.Liveness, solrContainer.LivenessProbe, "The StatefulSet should use the generated liveness probe")
+	assert.Equal(t, probes.Readiness, solrContainer.ReadinessProbe, "The StatefulSet should use the generated readiness probe")
+	assert.Equal(t, GenerateSolrLifecycle(solrCloud, status), solrContainer.Lifecycle, "The StatefulSet should use the generated lifecycle hooks")
+	assert.Equal(t, GenerateSolrEnvVars(solrCloud, status, map[string]string{}), solrContainer.Env, "The StatefulSet should use the generated environment variables")
+
+	envVars := GenerateSolrEnvVars(solrCloud, status, map[string]string{})
+	solrOpts := envVars[len(envVars)-1]
+	assert.Equal(t, "SOLR_OPTS", solrOpts.Name, "The SOLR_OPTS should be the last environment variable, so that it can use all others")
+	assert.Contains(t, solrOpts.Value, solrCloud.Spec.SolrOpts, "The user-provided SolrOpts should be included")
+	assert.NotNil(t, GenerateSolrLifecycle(solrCloud, status).PostStart, "The ZK chRoot should be created in the postStart hook")
+}
+
+// assertInvalidSpec asserts that the error is a terminal error caused by an invalid SolrCloud spec
+func assertInvalidSpec(t *testing.T, err error, msgAndArgs ...interface{}) {
+	terminalErr, isTerminal := AsTerminalError(err)
+	if assert.True(t, isTerminal, msgAndArgs...) {
+		assert.Equal(t, InvalidSpecReason, terminalErr.Reason, msgAndArgs...)
+	}
+}
+
+func TestValidateAddressabilityPorts(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:                      solr.Ingress,
+					DomainName:                  "example.com",
+					UseExternalAddress:          true,
+					IngressTLSTerminationSecret: "ingress-tls",
+					AdvertisedScheme:            "https",
+				},
+			},
+		},
+	}
+
+	solrCloudTest := solrCloud.DeepCopy()
+	solrCloudTest.WithDefaults()
+	assertInvalidSpec(t, ValidateAddressabilityPorts(solrCloudTest), "https cannot be advertised on the http port of an ingress that terminates TLS")
+
+	solrCloudTest = solrCloud.DeepCopy()
+	solrCloudTest.Spec.SolrAddressability.External.AdvertisedPort = 8443
+	solrCloudTest.WithDefaults()
+	assert.NoError(t, ValidateAddressabilityPorts(solrCloudTest), "A valid advertisedPort was rejected")
+
+	solrCloudTest = solrCloud.DeepCopy()
+	solrCloudTest.Spec.SolrAddressability.External = &solr.ExternalAddressability{
+		Method:             solr.ExternalDNS,
+		DomainName:         "example.com",
+		UseExternalAddress: true,
+		AdvertisedPort:     443,
+	}
+	solrCloudTest.WithDefaults()
+	assertInvalidSpec(t, ValidateAddressabilityPorts(solrCloudTest), "ExternalDNS addresses can only be reached through the podPort")
+
+	solrCloudTest.Spec.SolrAddressability.External.AdvertisedPort = 0
+	assert.NoError(t, ValidateAddressabilityPorts(solrCloudTest), "ExternalDNS should advertise the podPort by default")
+}
+
+func TestValidateNodeNameTemplate(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:             solr.Ingress,
+					DomainName:         "example.com",
+					UseExternalAddress: true,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.NoError(t, ValidateNodeNameTemplate(solrCloud), "No nodeNameTemplate should be valid")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{.PodName}}.search.{{.Domain}}"
+	assert.NoError(t, ValidateNodeNameTemplate(solrCloud), "A valid nodeNameTemplate was rejected")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{printf \"%.3s\" .PodName}}.{{.Domain}}"
+	assertInvalidSpec(t, ValidateNodeNameTemplate(solrCloud), "A nodeNameTemplate that modifies the PodName should be rejected")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{.PodName}}_{{.Domain}}"
+	assertInvalidSpec(t, ValidateNodeNameTemplate(solrCloud), "A nodeNameTemplate that generates invalid hostnames should be rejected")
+
+	solrCloud.Spec.SolrAddressability.External.NodeNameTemplate = "{{.PodName}.{{.Domain}}"
+	assertInvalidSpec(t, ValidateNodeNameTemplate(solrCloud), "A nodeNameTemplate that cannot be parsed should be rejected")
+}
+
+func TestValidateAdvertisedHostTemplate(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.NoError(t, ValidateAdvertisedHostTemplate(solrCloud), "No advertisedHostTemplate should be valid")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodIP}}"
+	assert.NoError(t, ValidateAdvertisedHostTemplate(solrCloud), "A valid advertisedHostTemplate was rejected")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "[{{.PodIP}}]"
+	assert.NoError(t, ValidateAdvertisedHostTemplate(solrCloud), "A bracketed PodIP should be valid")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodName}}.{{.Namespace}}.nat.example.com"
+	assert.NoError(t, ValidateAdvertisedHostTemplate(solrCloud), "A valid advertisedHostTemplate was rejected")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "solr.example.com"
+	assertInvalidSpec(t, ValidateAdvertisedHostTemplate(solrCloud), "An advertisedHostTemplate without the PodName or PodIP should be rejected")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodName}}_{{.CloudName}}"
+	assertInvalidSpec(t, ValidateAdvertisedHostTemplate(solrCloud), "An advertisedHostTemplate that generates invalid hosts should be rejected")
+
+	solrCloud.Spec.SolrAddressability.AdvertisedHostTemplate = "{{.PodIP}}"
+	solrCloud.Spec.SolrAddressability.HostNetwork = &solr.SolrHostNetworkOptions{}
+	assertInvalidSpec(t, ValidateAdvertisedHostTemplate(solrCloud), "An advertisedHostTemplate cannot be used with the host network")
+}
+
+func TestValidateSolrContainerOptions(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	assert.NoError(t, ValidateSolrContainerOptions(solrCloud), "No solrContainerOptions should be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions = &solr.SolrContainerOptions{
+		Command: []string{"sh", "-c"},
+		Args:    []string{"exec /opt/profiler/run.sh solr-foreground -Dsolr.jetty.request.header.size=65535"},
+	}
+	assert.NoError(t, ValidateSolrContainerOptions(solrCloud), "A wrapped solr-foreground command should be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions.Args = []string{"solr-foreground", "-p", "8080"}
+	assertInvalidSpec(t, ValidateSolrContainerOptions(solrCloud), "Overriding the Solr port should be rejected")
+
+	solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions.Args = []string{"exec solr-foreground -Dsolr.solr.home=/tmp/solr"}
+	assertInvalidSpec(t, ValidateSolrContainerOptions(solrCloud), "Overriding the Solr home within a shell command should be rejected")
+
+	solrCloud.Spec.CustomSolrKubeOptions.SolrContainerOptions.Args = []string{"solr-foreground", "--zk-host=zk:2181"}
+	assertInvalidSpec(t, ValidateSolrContainerOptions(solrCloud), "Overriding the Zookeeper connection should be rejected")
+}
+
+func TestValidateRuntimeRestrictions(t *testing.T) {
+	securityManager := true
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrOpts: "-Dsolr.autoSoftCommit.maxTime=10000",
+			RuntimeRestrictions: &solr.SolrRuntimeRestrictionOptions{
+				SecurityManager: &securityManager,
+				AllowPaths:      []string{"/mnt/backups", "/mnt/cores"},
+				AllowUrls:       []string{"http://solr-a:8983/solr"},
+			},
+		},
+	}
+	assert.NoError(t, ValidateRuntimeRestrictions(solrCloud))
+
+	solrCloud.Spec.RuntimeRestrictions.DisableAllowUrls = true
+	assertInvalidSpec(t, ValidateRuntimeRestrictions(solrCloud), "allowUrls cannot be used when they are disabled")
+	solrCloud.Spec.RuntimeRestrictions.DisableAllowUrls = false
+
+	solrCloud.Spec.RuntimeRestrictions.AllowPaths = []string{"/mnt/backups,/mnt/cores"}
+	assertInvalidSpec(t, ValidateRuntimeRestrictions(solrCloud), "Allowed paths cannot contain the separator")
+	solrCloud.Spec.RuntimeRestrictions.AllowPaths = []string{"*"}
+
+	solrCloud.Spec.SolrOpts = "-Dsolr.allowPaths=/tmp"
+	assertInvalidSpec(t, ValidateRuntimeRestrictions(solrCloud), "The allowed paths cannot also be set through the solrOpts")
+	solrCloud.Spec.SolrOpts = ""
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{EnvVariables: []corev1.EnvVar{{Name: "SOLR_SECURITY_MANAGER_ENABLED", Value: "false"}}}
+	assertInvalidSpec(t, ValidateRuntimeRestrictions(solrCloud), "The security manager cannot also be set through the envVars")
+	solrCloud.Spec.RuntimeRestrictions.SecurityManager = nil
+	assert.NoError(t, ValidateRuntimeRestrictions(solrCloud), "The security manager can be set through the envVars when it is not set through the runtimeRestrictions")
+}
@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SolrStreamingDaemonFinalizer makes sure that the daemon is killed in Solr before the SolrStreamingDaemon is deleted
+	SolrStreamingDaemonFinalizer = "streamingdaemon.finalizers.solr.apache.org"
+
+	// The state that Solr reports for daemons whose thread has stopped
+	streamingDaemonTerminatedState = "TERMINATED"
+)
+
+// StreamingDaemonExpression wraps the expression of the SolrStreamingDaemon in the daemon() function
+func StreamingDaemonExpression(daemon *solr.SolrStreamingDaemon) string {
+	return fmt.Sprintf("daemon(id=%s, runInterval=%s, terminate=false, %s)",
+		strconv.Quote(daemon.DaemonId()), strconv.Quote(strconv.Itoa(int(daemon.Spec.RunIntervalMillis))), strings.TrimSpace(daemon.Spec.Expression))
+}
+
+// SolrNodeForStreamingDaemon picks the Solr pod to run the daemon on.
+// The current pod is kept while it is ready, otherwise the first ready pod, by name, is chosen.
+// An empty string is returned if no Solr pods are ready.
+func SolrNodeForStreamingDaemon(solrCloud *solr.SolrCloud, currentNode string) string {
+	var readyNodes []string
+	for _, node := range solrCloud.Status.SolrNodes {
+		if node.Ready {
+			if node.Name == currentNode {
+				return currentNode
+			}
+			readyNodes = append(readyNodes, node.Name)
+		}
+	}
+	if len(readyNodes) == 0 {
+		return ""
+	}
+	sort.Strings(readyNodes)
+	return readyNodes[0]
+}
+
+func solrNodeUrl(solrCloud *solr.SolrCloud, node string) string {
+	return solrCloud.UrlScheme(false) + "://" + solrCloud.InternalNodeUrl(node, true)
+}
+
+// GetStreamingDaemonState returns whether the daemon is running on the given Solr pod, and how many times it has run its expression
+func GetStreamingDaemonState(solrCloud *solr.SolrCloud, node string, daemon *solr.SolrStreamingDaemon, httpHeaders map[string]string) (running bool, iterations int64, err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "list")
+
+	resp := &solr_api.SolrStreamResponse{}
+	if err = solr_api.CallStreamHandler(solrCloud, solrNodeUrl(solrCloud, node), daemon.Spec.Collection, queryParams, httpHeaders, resp); err == nil {
+		err = solr_api.CheckForStreamError("action=list", resp)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	for _, tuple := range resp.ResultSet.Docs {
+		if tuple.Id == daemon.DaemonId() {
+			return tuple.State != streamingDaemonTerminatedState, tuple.Iterations, nil
+		}
+	}
+	return false, 0, nil
+}
+
+// StartStreamingDaemon submits the daemon to the given Solr pod. A daemon with the same id on that pod is replaced.
+func StartStreamingDaemon(solrCloud *solr.SolrCloud, node string, daemon *solr.SolrStreamingDaemon, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("expr", StreamingDaemonExpression(daemon))
+
+	resp := &solr_api.SolrStreamResponse{}
+	if err = solr_api.CallStreamHandler(solrCloud, solrNodeUrl(solrCloud, node), daemon.Spec.Collection, queryParams, httpHeaders, resp); err == nil {
+		err = solr_api.CheckForStreamError("daemon "+daemon.DaemonId(), resp)
+	}
+	return err
+}
+
+// KillStreamingDaemon stops the daemon on the given Solr pod, and removes it from the pod's list of daemons
+func KillStreamingDaemon(solrCloud *solr.SolrCloud, node string, daemon *solr.SolrStreamingDaemon, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "kill")
+	queryParams.Add("id", daemon.DaemonId())
+
+	resp := &solr_api.SolrStreamResponse{}
+	if err = solr_api.CallStreamHandler(solrCloud, solrNodeUrl(solrCloud, node), daemon.Spec.Collection, queryParams, httpHeaders, resp); err == nil {
+		err = solr_api.CheckForStreamError("action=kill", resp)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestStreamingDaemonExpression(t *testing.T) {
+	daemon := &solr.SolrStreamingDaemon{
+		ObjectMeta: metav1.ObjectMeta{Name: "products-sync"},
+		Spec: solr.SolrStreamingDaemonSpec{
+			SolrCloud:  "foo",
+			Collection: "products",
+			Expression: "\n  update(products-v2, batchSize=100, topic(checkpoints, products, q=\"*:*\", fl=\"id\", id=\"sync\"))\n",
+		},
+	}
+	daemon.WithDefaults()
+	assert.Equal(t, "daemon(id=\"products-sync\", runInterval=\"2000\", terminate=false, update(products-v2, batchSize=100, topic(checkpoints, products, q=\"*:*\", fl=\"id\", id=\"sync\")))",
+		StreamingDaemonExpression(daemon), "Wrong daemon expression")
+}
+
+func TestSolrNodeForStreamingDaemon(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		Status: solr.SolrCloudStatus{
+			SolrNodes: []solr.SolrNodeStatus{
+				{Name: "foo-solrcloud-2", Ready: true},
+				{Name: "foo-solrcloud-0", Ready: false},
+				{Name: "foo-solrcloud-1", Ready: true},
+			},
+		},
+	}
+	assert.Equal(t, "foo-solrcloud-2", SolrNodeForStreamingDaemon(solrCloud, "foo-solrcloud-2"), "The daemon should stay on its pod while the pod is ready")
+	assert.Equal(t, "foo-solrcloud-1", SolrNodeForStreamingDaemon(solrCloud, "foo-solrcloud-0"), "The daemon should move to the first ready pod when its pod is not ready")
+	assert.Equal(t, "foo-solrcloud-1", SolrNodeForStreamingDaemon(solrCloud, ""), "A new daemon should use the first ready pod")
+
+	solrCloud.Status.SolrNodes = []solr.SolrNodeStatus{{Name: "foo-solrcloud-0", Ready: false}}
+	assert.Equal(t, "", SolrNodeForStreamingDaemon(solrCloud, "foo-solrcloud-0"), "No pod should be chosen when none are ready")
+}
@@ -0,0 +1,225 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	VolumeSnapshotAPIGroup = "snapshot.storage.k8s.io"
+	VolumeSnapshotKind     = "VolumeSnapshot"
+
+	// SolrBackupReadOnlyCollectionsFinalizer makes sure that the collections that a VolumeSnapshot backup makes read-only are made writable again when the SolrBackup is deleted
+	SolrBackupReadOnlyCollectionsFinalizer = "readonlycollections.finalizers.solr.apache.org"
+)
+
+var (
+	// The VolumeSnapshot CRDs are installed with the CSI snapshot controller, so they are managed as unstructured objects
+	VolumeSnapshotGVK        = schema.GroupVersionKind{Group: VolumeSnapshotAPIGroup, Version: "v1", Kind: VolumeSnapshotKind}
+	VolumeSnapshotContentGVK = schema.GroupVersionKind{Group: VolumeSnapshotAPIGroup, Version: "v1", Kind: "VolumeSnapshotContent"}
+)
+
+// ValidateVolumeSnapshotBackup returns an error if the SolrBackup cannot be taken as VolumeSnapshots of the given SolrCloud
+func ValidateVolumeSnapshotBackup(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) error {
+	if len(backup.Spec.AdditionalRepositoryNames) > 0 || backup.Spec.Persistence != nil || backup.Spec.Recurrence != nil || backup.Spec.Verify || backup.Spec.IncludeClusterState || backup.Spec.Throttling != nil {
+		return TerminalErrorf(InvalidSpecReason, "volumeSnapshot backups cannot be combined with additionalRepositoryNames, persistence, recurrence, verify, includeClusterState or throttling")
+	}
+	if solrCloud != nil && !solrCloud.UsesPersistentStorage() {
+		return TerminalErrorf(InvalidSpecReason, "SolrCloud %s must use persistent storage to be backed up with VolumeSnapshots", solrCloud.Name)
+	}
+	return nil
+}
+
+// VolumeSnapshotBackupStatuses returns the initial status of the VolumeSnapshot of each Solr Node's data PersistentVolumeClaim
+func VolumeSnapshotBackupStatuses(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) (statuses []solr.VolumeSnapshotBackupStatus) {
+	for _, podName := range solrCloud.GetAllSolrNodeNames() {
+		statuses = append(statuses, solr.VolumeSnapshotBackupStatus{
+			PodName:               podName,
+			PersistentVolumeClaim: dataPVCName(solrCloud, podName),
+			Name:                  backup.VolumeSnapshotName(podName),
+		})
+	}
+	return statuses
+}
+
+// GenerateVolumeSnapshot returns the VolumeSnapshot of a Solr Node's data PersistentVolumeClaim.
+// The VolumeSnapshot is not owned by the SolrBackup, so that the backup is kept when the SolrBackup is deleted.
+func GenerateVolumeSnapshot(backup *solr.SolrBackup, snapshotStatus *solr.VolumeSnapshotBackupStatus) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	snapshot.SetName(snapshotStatus.Name)
+	snapshot.SetNamespace(backup.Namespace)
+	snapshot.SetLabels(backup.SharedLabelsWith(backup.GetLabels()))
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": snapshotStatus.PersistentVolumeClaim,
+		},
+	}
+	if backup.Spec.VolumeSnapshot.VolumeSnapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = backup.Spec.VolumeSnapshot.VolumeSnapshotClassName
+	}
+	snapshot.Object["spec"] = spec
+	return snapshot
+}
+
+// UpdateVolumeSnapshotBackupStatus records the progress of the VolumeSnapshot in its status.
+// The name of the VolumeSnapshotContent that the snapshot is bound to is returned, once it is known.
+func UpdateVolumeSnapshotBackupStatus(snapshotStatus *solr.VolumeSnapshotBackupStatus, snapshot *unstructured.Unstructured, takeTimeout time.Duration, now time.Time) (contentName string) {
+	contentName, _, _ = unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+	snapshotStatus.ReadyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	if creationTime, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); found && snapshotStatus.CreationTime == nil {
+		if parsed, err := time.Parse(time.RFC3339, creationTime); err == nil {
+			taken := metav1.NewTime(parsed)
+			snapshotStatus.CreationTime = &taken
+		}
+	}
+	if errorMessage, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		snapshotStatus.Error = errorMessage
+	} else if _, found, _ = unstructured.NestedMap(snapshot.Object, "status", "error"); found {
+		snapshotStatus.Error = "The VolumeSnapshot failed without a message"
+	} else if snapshotStatus.CreationTime == nil && now.After(snapshot.GetCreationTimestamp().Add(takeTimeout)) {
+		// Fail the snapshot, so that the collections do not stay read-only forever
+		snapshotStatus.Error = fmt.Sprintf("The VolumeSnapshot was not taken within %s", takeTimeout)
+	}
+	return contentName
+}
+
+// VolumeSnapshotHandle returns the handle of the snapshot in the storage system, from its VolumeSnapshotContent
+func VolumeSnapshotHandle(content *unstructured.Unstructured) string {
+	handle, _, _ := unstructured.NestedString(content.Object, "status", "snapshotHandle")
+	return handle
+}
+
+// CheckStatusOfVolumeSnapshots returns whether every VolumeSnapshot of the SolrBackup has been taken, so that the collections no longer need to be read-only,
+// and whether every VolumeSnapshot has finished, either because it is ready to use or because it failed.
+func CheckStatusOfVolumeSnapshots(backup *solr.SolrBackup) (allTaken bool, allFinished bool, allSuccessful bool) {
+	allTaken, allFinished, allSuccessful = len(backup.Status.VolumeSnapshots) > 0, len(backup.Status.VolumeSnapshots) > 0, true
+	for _, snapshotStatus := range backup.Status.VolumeSnapshots {
+		failed := snapshotStatus.Error != ""
+		allTaken = allTaken && (snapshotStatus.CreationTime != nil || failed)
+		allFinished = allFinished && (snapshotStatus.ReadyToUse || failed)
+		allSuccessful = allSuccessful && snapshotStatus.ReadyToUse && !failed
+	}
+	return allTaken, allFinished, allSuccessful
+}
+
+// CollectionsToMakeReadOnlyForSnapshot returns the given collections that are not read-only yet, and therefore need to be made read-only, and later writable again, by the backup.
+// Collections that are already read-only are left as they are.
+func CollectionsToMakeReadOnlyForSnapshot(collections []string, clusterState *SolrClusterState) (toMakeReadOnly []string, err error) {
+	collectionStatuses, err := clusterState.CollectionStatuses(collections)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		collectionStatus, found := collectionStatuses[collection]
+		if !found {
+			continue
+		}
+		if isReadOnly, _ := strconv.ParseBool(collectionStatus.ReadOnly); !isReadOnly {
+			toMakeReadOnly = append(toMakeReadOnly, collection)
+		}
+	}
+	return toMakeReadOnly, nil
+}
+
+// SetCollectionsReadOnlyForSnapshot puts the given collections into read-only mode, which commits any in-flight updates, so that their data is consistent on disk.
+// The collections must already be recorded in the status of the SolrBackup, since they must be made writable again after the snapshots are taken.
+func SetCollectionsReadOnlyForSnapshot(cloud *solr.SolrCloud, collections []string, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	for _, collection := range collections {
+		logger.Info("Setting collection read-only for the VolumeSnapshots", "collection", collection)
+		if err = setCollectionReadOnly(cloud, collection, true, httpHeaders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCollectionsWritableAfterSnapshot takes the given collections out of read-only mode.
+// The collections that are still read-only are returned, so that they can be retried.
+func SetCollectionsWritableAfterSnapshot(cloud *solr.SolrCloud, collections []string, httpHeaders map[string]string, logger logr.Logger) (stillReadOnly []string, err error) {
+	for i, collection := range collections {
+		logger.Info("Setting collection writable after the VolumeSnapshots", "collection", collection)
+		if err = setCollectionReadOnly(cloud, collection, false, httpHeaders); err != nil {
+			return collections[i:], err
+		}
+	}
+	return nil, nil
+}
+
+// VolumeRestoreStatuses maps the VolumeSnapshots of a SolrBackup onto the data PersistentVolumeClaims of the Solr Nodes of the SolrCloud to restore.
+// The snapshot of each Solr Node is restored into the Solr Node with the same ordinal.
+func VolumeRestoreStatuses(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) (statuses []solr.VolumeRestoreStatus, err error) {
+	for _, snapshotStatus := range backup.Status.VolumeSnapshots {
+		ordinalIndex := strings.LastIndex(snapshotStatus.PodName, "-")
+		if ordinalIndex < 0 {
+			return nil, fmt.Errorf("cannot determine the ordinal of the snapshotted pod %s", snapshotStatus.PodName)
+		}
+		podName := solrCloud.StatefulSetName() + snapshotStatus.PodName[ordinalIndex:]
+		statuses = append(statuses, solr.VolumeRestoreStatus{
+			PersistentVolumeClaim: dataPVCName(solrCloud, podName),
+			VolumeSnapshot:        snapshotStatus.Name,
+		})
+	}
+	return statuses, nil
+}
+
+// GenerateRestoredDataPVC returns the data PersistentVolumeClaim of a Solr Node, pre-populated from the given VolumeSnapshot.
+// The StatefulSet uses the PersistentVolumeClaim instead of creating it from its volumeClaimTemplate, since it has the same name.
+func GenerateRestoredDataPVC(solrCloud *solr.SolrCloud, restoreStatus *solr.VolumeRestoreStatus) *corev1.PersistentVolumeClaim {
+	pvcTemplate := GenerateDataPVCTemplate(solrCloud)
+	apiGroup := VolumeSnapshotAPIGroup
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        restoreStatus.PersistentVolumeClaim,
+			Namespace:   solrCloud.Namespace,
+			Labels:      pvcTemplate.Labels,
+			Annotations: pvcTemplate.Annotations,
+		},
+		Spec: pvcTemplate.Spec,
+	}
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     VolumeSnapshotKind,
+		Name:     restoreStatus.VolumeSnapshot,
+	}
+	return pvc
+}
+
+// CheckStatusOfVolumeRestores returns whether every data PersistentVolumeClaim has been handled, and whether they were all restored
+func CheckStatusOfVolumeRestores(restore *solr.SolrRestore) (allFinished bool, allSuccessful bool) {
+	allFinished, allSuccessful = true, true
+	for _, restoreStatus := range restore.Status.VolumeRestoreStatuses {
+		allFinished = allFinished && (restoreStatus.Restored || restoreStatus.Message != "")
+		allSuccessful = allSuccessful && restoreStatus.Restored
+	}
+	return allFinished, allSuccessful
+}
@@ -0,0 +1,175 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func volumeSnapshotTestCloud(name string, replicas int32) *solr.SolrCloud {
+	return &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			StorageOptions: solr.SolrDataStorageOptions{
+				PersistentStorage: &solr.SolrPersistentDataStorageOptions{},
+			},
+		},
+	}
+}
+
+func TestValidateVolumeSnapshotBackup(t *testing.T) {
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: "default"},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:      "foo",
+			VolumeSnapshot: &solr.VolumeSnapshotBackupOptions{},
+		},
+	}
+	cloud := volumeSnapshotTestCloud("foo", 2)
+	assert.NoError(t, ValidateVolumeSnapshotBackup(backup, cloud), "A snapshot backup of a cloud with persistent storage is valid")
+
+	backup.Spec.Verify = true
+	_, isTerminal := AsTerminalError(ValidateVolumeSnapshotBackup(backup, cloud))
+	assert.True(t, isTerminal, "Snapshot backups cannot be verified")
+	backup.Spec.Verify = false
+
+	cloud.Spec.StorageOptions.PersistentStorage = nil
+	_, isTerminal = AsTerminalError(ValidateVolumeSnapshotBackup(backup, cloud))
+	assert.True(t, isTerminal, "Clouds with ephemeral storage cannot be snapshotted")
+}
+
+func TestGenerateVolumeSnapshot(t *testing.T) {
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: "default"},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:      "foo",
+			VolumeSnapshot: &solr.VolumeSnapshotBackupOptions{VolumeSnapshotClassName: "csi-snapclass"},
+		},
+	}
+	cloud := volumeSnapshotTestCloud("foo", 2)
+	cloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name = "solr-data"
+
+	statuses := VolumeSnapshotBackupStatuses(backup, cloud)
+	assert.Equal(t, []solr.VolumeSnapshotBackupStatus{
+		{PodName: "foo-solrcloud-0", PersistentVolumeClaim: "solr-data-foo-solrcloud-0", Name: "snapshot-foo-solrcloud-0"},
+		{PodName: "foo-solrcloud-1", PersistentVolumeClaim: "solr-data-foo-solrcloud-1", Name: "snapshot-foo-solrcloud-1"},
+	}, statuses, "Each Solr Node's data PVC should be snapshotted")
+
+	snapshot := GenerateVolumeSnapshot(backup, &statuses[1])
+	assert.Equal(t, VolumeSnapshotGVK, snapshot.GroupVersionKind())
+	assert.Equal(t, "snapshot-foo-solrcloud-1", snapshot.GetName())
+	assert.Empty(t, snapshot.GetOwnerReferences(), "VolumeSnapshots must outlive the SolrBackup")
+	pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "solr-data-foo-solrcloud-1", pvcName)
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapclass", className)
+}
+
+func TestVolumeSnapshotBackupStatus(t *testing.T) {
+	backup := &solr.SolrBackup{
+		Status: solr.SolrBackupStatus{
+			VolumeSnapshots: []solr.VolumeSnapshotBackupStatus{{Name: "snap-0"}, {Name: "snap-1"}},
+		},
+	}
+	allTaken, allFinished, _ := CheckStatusOfVolumeSnapshots(backup)
+	assert.False(t, allTaken, "No snapshots have been taken yet")
+	assert.False(t, allFinished, "No snapshots have finished yet")
+
+	taken := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"boundVolumeSnapshotContentName": "snapcontent-0",
+			"creationTime":                   "2021-09-17T02:00:00Z",
+			"readyToUse":                     true,
+		},
+	}}
+	now := time.Now()
+	assert.Equal(t, "snapcontent-0", UpdateVolumeSnapshotBackupStatus(&backup.Status.VolumeSnapshots[0], taken, time.Minute, now))
+	assert.NotNil(t, backup.Status.VolumeSnapshots[0].CreationTime, "The creation time should be recorded")
+	assert.True(t, backup.Status.VolumeSnapshots[0].ReadyToUse)
+
+	failed := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"error": map[string]interface{}{"message": "snapshot failed"},
+		},
+	}}
+	assert.Equal(t, "", UpdateVolumeSnapshotBackupStatus(&backup.Status.VolumeSnapshots[1], failed, time.Minute, now))
+	assert.Equal(t, "snapshot failed", backup.Status.VolumeSnapshots[1].Error)
+
+	pending := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	pending.SetCreationTimestamp(metav1.NewTime(now.Add(-2 * time.Minute)))
+	pendingStatus := &solr.VolumeSnapshotBackupStatus{Name: "snap-2"}
+	UpdateVolumeSnapshotBackupStatus(pendingStatus, pending, 5*time.Minute, now)
+	assert.Empty(t, pendingStatus.Error, "The VolumeSnapshot should not fail before the take timeout")
+	UpdateVolumeSnapshotBackupStatus(pendingStatus, pending, time.Minute, now)
+	assert.Equal(t, "The VolumeSnapshot was not taken within 1m0s", pendingStatus.Error, "A VolumeSnapshot that is not taken in time should fail, so that the collections are made writable again")
+
+	allTaken, allFinished, allSuccessful := CheckStatusOfVolumeSnapshots(backup)
+	assert.True(t, allTaken, "Failed snapshots will never be taken, so the collections can be made writable")
+	assert.True(t, allFinished, "Every snapshot is either ready or failed")
+	assert.False(t, allSuccessful, "A failed snapshot fails the backup")
+
+	content := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"snapshotHandle": "projects/p/global/snapshots/s"},
+	}}
+	assert.Equal(t, "projects/p/global/snapshots/s", VolumeSnapshotHandle(content))
+}
+
+func TestGenerateRestoredDataPVC(t *testing.T) {
+	backup := &solr.SolrBackup{
+		Status: solr.SolrBackupStatus{
+			VolumeSnapshots: []solr.VolumeSnapshotBackupStatus{
+				{PodName: "old-solrcloud-0", PersistentVolumeClaim: "data-old-solrcloud-0", Name: "snapshot-old-solrcloud-0"},
+				{PodName: "old-solrcloud-1", PersistentVolumeClaim: "data-old-solrcloud-1", Name: "snapshot-old-solrcloud-1"},
+			},
+		},
+	}
+	cloud := volumeSnapshotTestCloud("foo", 0)
+
+	statuses, err := VolumeRestoreStatuses(backup, cloud)
+	assert.NoError(t, err)
+	assert.Equal(t, []solr.VolumeRestoreStatus{
+		{PersistentVolumeClaim: "data-foo-solrcloud-0", VolumeSnapshot: "snapshot-old-solrcloud-0"},
+		{PersistentVolumeClaim: "data-foo-solrcloud-1", VolumeSnapshot: "snapshot-old-solrcloud-1"},
+	}, statuses, "Snapshots should be restored into the Solr Node with the same ordinal")
+
+	pvc := GenerateRestoredDataPVC(cloud, &statuses[1])
+	assert.Equal(t, "data-foo-solrcloud-1", pvc.Name)
+	assert.Equal(t, "default", pvc.Namespace)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, pvc.Spec.AccessModes, "The PVC should match the volumeClaimTemplate of the StatefulSet")
+	if assert.NotNil(t, pvc.Spec.DataSource) {
+		assert.Equal(t, VolumeSnapshotAPIGroup, *pvc.Spec.DataSource.APIGroup)
+		assert.Equal(t, VolumeSnapshotKind, pvc.Spec.DataSource.Kind)
+		assert.Equal(t, "snapshot-old-solrcloud-1", pvc.Spec.DataSource.Name)
+	}
+
+	restore := &solr.SolrRestore{Status: solr.SolrRestoreStatus{VolumeRestoreStatuses: statuses}}
+	allFinished, _ := CheckStatusOfVolumeRestores(restore)
+	assert.False(t, allFinished, "No PVCs have been restored yet")
+	restore.Status.VolumeRestoreStatuses[0].Restored = true
+	restore.Status.VolumeRestoreStatuses[1].Message = "The PersistentVolumeClaim already exists"
+	allFinished, allSuccessful := CheckStatusOfVolumeRestores(restore)
+	assert.True(t, allFinished)
+	assert.False(t, allSuccessful, "Existing PVCs cannot be restored")
+}
@@ -18,11 +18,13 @@
 package util
 
 import (
+	"fmt"
 	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers/zk_api"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
 	"strings"
 )
 
@@ -30,7 +32,7 @@ import (
 // object: SolrCloud instance
 // zkSpec: the spec of the ZookeeperCluster to generate
 func GenerateZookeeperCluster(solrCloud *solrv1beta1.SolrCloud, zkSpec *solrv1beta1.ZookeeperSpec) *zk_api.ZookeeperCluster {
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels := solrCloud.SharedLabelsWith(solrCloud.PropagatedLabels())
 	labels["technology"] = solrv1beta1.ZookeeperTechnologyLabel
 
 	zkCluster := &zk_api.ZookeeperCluster{
@@ -123,9 +125,7 @@ func GenerateZookeeperCluster(solrCloud *solrv1beta1.SolrCloud, zkSpec *solrv1be
 		zkCluster.Spec.Pod.ServiceAccountName = zkSpec.ZookeeperPod.ServiceAccountName
 	}
 
-	if zkSpec.Image.ImagePullSecret != "" {
-		zkCluster.Spec.Pod.ImagePullSecrets = []corev1.LocalObjectReference{{Name: zkSpec.Image.ImagePullSecret}}
-	}
+	zkCluster.Spec.Pod.ImagePullSecrets = withImagePullSecrets(nil, zkSpec.Image)
 
 	// Add defaults that the ZK Operator should set itself, otherwise we will have problems with reconcile loops.
 	// Also it will default the spec.Probes object which cannot be set to null.
@@ -389,3 +389,75 @@ func AddACLsToEnv(allACL *solrv1beta1.ZookeeperACL, readOnlyACL *solrv1beta1.Zoo
 
 	return true, envVars
 }
+
+// ZookeeperConnectionStringFromService resolves the connection string of a zookeeper ensemble from the Service in front of it.
+// For headless Services, every member of the ensemble listed in the Service's Endpoints is added to the connection string,
+// so that the connection string follows the ensemble as it scales. Otherwise the address of the Service is used.
+// kubeDomain: the Kubernetes cluster domain, an empty string uses the short name of the Service
+func ZookeeperConnectionStringFromService(service *corev1.Service, endpoints *corev1.Endpoints, portName string, kubeDomain string) (string, error) {
+	servicePort, err := zookeeperClientPort(service.Spec.Ports, portName)
+	if err != nil {
+		return "", fmt.Errorf("service %s/%s: %s", service.Namespace, service.Name, err)
+	}
+	domainSuffix := ""
+	if kubeDomain != "" {
+		domainSuffix = ".svc." + kubeDomain
+	}
+
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		return fmt.Sprintf("%s.%s%s:%d", service.Name, service.Namespace, domainSuffix, servicePort.Port), nil
+	}
+
+	var hosts []string
+	if endpoints != nil {
+		for _, subset := range endpoints.Subsets {
+			// Endpoint ports share the name of the Service port they belong to
+			var port int32
+			for _, p := range subset.Ports {
+				if p.Name == servicePort.Name {
+					port = p.Port
+					break
+				}
+			}
+			if port == 0 {
+				continue
+			}
+			// Members that are not ready must still be included, otherwise the ensemble could never form a quorum
+			addresses := append(append([]corev1.EndpointAddress{}, subset.Addresses...), subset.NotReadyAddresses...)
+			for _, address := range addresses {
+				host := address.IP
+				if address.Hostname != "" {
+					host = fmt.Sprintf("%s.%s.%s%s", address.Hostname, service.Name, service.Namespace, domainSuffix)
+				}
+				hosts = append(hosts, fmt.Sprintf("%s:%d", host, port))
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return "", fmt.Errorf("the headless service %s/%s has no endpoints", service.Namespace, service.Name)
+	}
+	sort.Strings(hosts)
+	return strings.Join(hosts, ","), nil
+}
+
+// zookeeperClientPort picks the zookeeper client port out of the ports of a Service.
+// The named port is used if given, otherwise the default zookeeper client port 2181, or the only port of the Service.
+func zookeeperClientPort(ports []corev1.ServicePort, portName string) (*corev1.ServicePort, error) {
+	if portName != "" {
+		for i := range ports {
+			if ports[i].Name == portName {
+				return &ports[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no port named %s", portName)
+	}
+	for i := range ports {
+		if ports[i].Port == 2181 {
+			return &ports[i], nil
+		}
+	}
+	if len(ports) == 1 {
+		return &ports[0], nil
+	}
+	return nil, fmt.Errorf("cannot determine the zookeeper client port, the portName must be provided")
+}
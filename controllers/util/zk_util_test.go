@@ -22,6 +22,7 @@ import (
 	"github.com/apache/solr-operator/controllers/zk_api"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"testing"
 )
@@ -58,3 +59,52 @@ func TestDefaultStorageOptions(t *testing.T) {
 	assert.Equal(t, "ephemeral", zkCluster.Spec.StorageType, "By default when Solr is using ephemeral storage, zk should as well. Wrong storageType")
 	assert.Nil(t, zkCluster.Spec.Persistence, "By default when Solr is using ephemeral storage, zk should as well. Therefore 'persistence' should be nil")
 }
+
+func TestZookeeperConnectionStringFromService(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk-headless", Namespace: "zk"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{Name: "client", Port: 2181},
+				{Name: "quorum", Port: 2888},
+			},
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk-headless", Namespace: "zk"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.2", Hostname: "zk-1"},
+				{IP: "10.0.0.1", Hostname: "zk-0"},
+			},
+			NotReadyAddresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.3"},
+			},
+			Ports: []corev1.EndpointPort{
+				{Name: "client", Port: 2181},
+				{Name: "quorum", Port: 2888},
+			},
+		}},
+	}
+
+	connectionString, err := ZookeeperConnectionStringFromService(service, endpoints, "", "")
+	assert.NoError(t, err, "The connection string of a headless service should be resolved")
+	assert.Equal(t, "10.0.0.3:2181,zk-0.zk-headless.zk:2181,zk-1.zk-headless.zk:2181", connectionString, "Wrong connection string for a headless service")
+
+	connectionString, err = ZookeeperConnectionStringFromService(service, endpoints, "client", "cluster.local")
+	assert.NoError(t, err, "The connection string of a headless service should be resolved")
+	assert.Equal(t, "10.0.0.3:2181,zk-0.zk-headless.zk.svc.cluster.local:2181,zk-1.zk-headless.zk.svc.cluster.local:2181", connectionString, "Wrong connection string for a headless service with a kube domain")
+
+	_, err = ZookeeperConnectionStringFromService(service, &corev1.Endpoints{}, "", "")
+	assert.Error(t, err, "A headless service without endpoints cannot be resolved")
+
+	_, err = ZookeeperConnectionStringFromService(service, endpoints, "admin", "")
+	assert.Error(t, err, "A missing port name cannot be resolved")
+
+	service.Spec.ClusterIP = "10.1.0.1"
+	service.Spec.Ports = []corev1.ServicePort{{Name: "zk", Port: 12181}}
+	connectionString, err = ZookeeperConnectionStringFromService(service, nil, "", "")
+	assert.NoError(t, err, "The connection string of a ClusterIP service should be resolved")
+	assert.Equal(t, "zk-headless.zk:12181", connectionString, "Wrong connection string for a ClusterIP service")
+}
@@ -21,9 +21,9 @@ set -o pipefail
 # error on unset variables
 set -u
 
-echo "Add headers to CRDs and Role files"
+echo "Add headers to CRDs, Role and Webhook files"
 
-files=("${CONFIG_DIRECTORY:-config}"/crd/bases/* "${CONFIG_DIRECTORY:-config}"/rbac/role.yaml)
+files=("${CONFIG_DIRECTORY:-config}"/crd/bases/* "${CONFIG_DIRECTORY:-config}"/rbac/role.yaml "${CONFIG_DIRECTORY:-config}"/webhook/manifests.yaml)
 
 # Copy and package CRDs
 for file in "${files[@]}"; do
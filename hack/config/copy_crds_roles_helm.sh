@@ -32,7 +32,13 @@ HELM_DIRECTORY="${HELM_DIRECTORY:-helm}"
   printf "\n"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrbackups.yaml"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrclouds.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrconfigsets.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrindexingbridges.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrmigrations.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solroperatorconfigs.yaml"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrprometheusexporters.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrrestores.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrstreamingdaemons.yaml"
 } > "${HELM_DIRECTORY}/solr-operator/crds/crds.yaml"
 
 # Copy Kube Role for Solr Operator permissions to Helm
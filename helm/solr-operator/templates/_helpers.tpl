@@ -100,6 +100,13 @@ mTLS vars
 rootSolrCert.pem
 {{- end -}}
 
+{{/*
+The name of the Secret that cert-manager stores the serving certificate of the webhooks in
+*/}}
+{{- define "solr-operator.webhooks.certSecret" -}}
+{{ include "solr-operator.fullname" . }}-webhook-cert
+{{- end -}}
+
 {{- define "solr-operator.mTLS.volumeMounts" -}}
 {{- if .Values.mTLS.clientCertSecret -}}
 - name: tls-client-cert
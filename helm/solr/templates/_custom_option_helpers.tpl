@@ -33,6 +33,10 @@ annotations:
 resources:
   {{- toYaml .Values.podOptions.resources | nindent 2 }}
 {{ end }}
+{{- if .Values.podOptions.defaultInitContainerResources -}}
+defaultInitContainerResources:
+  {{- toYaml .Values.podOptions.defaultInitContainerResources | nindent 2 }}
+{{ end }}
 {{- if (include "solr.serviceAccountName.solr" .) -}}
 serviceAccountName: {{ include "solr.serviceAccountName.solr" . }}
 {{ end }}
@@ -18,10 +18,12 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"flag"
 	"fmt"
+	"github.com/apache/solr-operator/controllers/util"
 	"github.com/apache/solr-operator/controllers/util/solr_api"
 	zk_api "github.com/apache/solr-operator/controllers/zk_api"
 	"github.com/apache/solr-operator/version"
@@ -33,6 +35,7 @@ import (
 	"runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -42,8 +45,10 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers"
@@ -63,6 +68,37 @@ var (
 	// External Operator dependencies
 	useZookeeperCRD bool
 
+	// Upgrade safety
+	strictVersionChecks bool
+
+	// Only use FIPS-approved cryptography
+	fipsMode bool
+
+	// Hash config contents with SHA-256 instead of MD5
+	sha256ConfigHashes bool
+
+	// Publish lifecycle events as CloudEvents
+	cloudEventsSink string
+
+	// Timeout for requests to Solr
+	solrRequestTimeout time.Duration
+
+	// How long to wait before reconciling resources again, per reason
+	requeueDurations string
+
+	// Pull secrets for all generated workloads
+	defaultImagePullSecrets string
+
+	// Serve the admission webhooks
+	enableWebhooks bool
+
+	// The name of the SolrOperatorConfig in each namespace
+	operatorConfigName string
+
+	// Enable or disable gated features
+	featureGates          string
+	featureGatesConfigMap string
+
 	// mTLS information
 	clientSkipVerify  bool
 	clientCertPath    string
@@ -97,6 +133,18 @@ func init() {
 	flag.StringVar(&caCertPath, "tls-ca-cert-path", "", "Path where a Certificate Authority (CA) cert in PEM format can be found")
 	flag.BoolVar(&clientCertWatch, "tls-watch-cert", true, "Controls whether the operator performs a hot reload of the mTLS when it gets updated; set to false to disable watching for updates to the TLS cert.")
 
+	flag.BoolVar(&fipsMode, "fips-mode", false, "The operator will only use FIPS-approved cryptography for TLS connections to Solr and the resources it generates, and will require TLS for all SolrClouds. Use an operator image built with BoringCrypto for a FIPS-validated crypto module.")
+	flag.BoolVar(&sha256ConfigHashes, "sha256-config-hashes", false, "The operator will use SHA-256, instead of MD5, to hash the configuration contents that pods are restarted for when they change. This is always enabled in FIPS mode. Existing pods are not restarted when this is enabled, until their configuration changes.")
+	flag.StringVar(&cloudEventsSink, "cloud-events-sink", "", "An HTTP endpoint, such as a Knative Broker or Kafka Sink, that lifecycle events for Solr resources will be published to as CloudEvents. If an empty string (default) is provided, no CloudEvents are published.")
+	flag.DurationVar(&solrRequestTimeout, "solr-request-timeout", solr_api.DefaultRequestTimeout, "The timeout for requests that the operator sends to Solr, such as for managed updates and backups. SolrClouds can override this with spec.operatorClient.timeoutSeconds.")
+	flag.StringVar(&requeueDurations, "requeue-durations", "", "A comma-separated list of <reason>=<duration> pairs, that override how long the operator waits before reconciling resources again, such as \"managed-update=30s,steady-state=5m\". Reasons: "+strings.Join(util.RequeueReasons(), ", ")+".")
+	flag.StringVar(&defaultImagePullSecrets, "default-image-pull-secrets", "", "The comma-separated list of image pull secrets that are added to all workloads the operator generates, such as Solr pods and Prometheus Exporters. The secrets must exist in the namespace of each workload.")
+	flag.StringVar(&featureGates, "feature-gates", "", "A comma-separated list of <Feature>=<true|false> pairs, that enable or disable gated features of the operator. These override the feature gates provided through the "+util.FeatureGatesEnvVar+" env var and the feature gates ConfigMap.")
+	flag.StringVar(&featureGatesConfigMap, "feature-gates-config-map", "", "The name of a ConfigMap, in the namespace of the operator, with the key \""+util.FeatureGatesConfigMapKey+"\" containing a list of <Feature>=<true|false> pairs. The ConfigMap is only read when the operator starts.")
+	flag.StringVar(&operatorConfigName, "operator-config-name", solrv1beta1.DefaultSolrOperatorConfigName, "The name of the SolrOperatorConfig that the operator reads in the namespace of each Solr resource. SolrOperatorConfigs with any other name are ignored. If an empty string is provided, SolrOperatorConfigs are not used.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the admission webhooks of the operator on port 9443, such as the one that rejects the deletion of SolrClouds while SolrBackups or SolrRestores of them are in progress. The serving certificate must be provided in /tmp/k8s-webhook-server/serving-certs.")
+	flag.BoolVar(&strictVersionChecks, "strict-version-checks", false, "The operator will refuse to start if the installed CRDs are out of date, or another Solr Operator of a different version is running. Otherwise these problems are only logged as warnings.")
+
 }
 
 func main() {
@@ -134,6 +182,7 @@ func main() {
 	setupLog.Info(fmt.Sprintf("solr-operator Build Time: %s", version.BuildTime))
 	setupLog.Info(fmt.Sprintf("Go Version: %v", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s / %s", runtime.GOOS, runtime.GOARCH))
+	setupLog.Info(fmt.Sprintf("FIPS mode: %t, BoringCrypto: %t", fipsMode, boringCryptoEnabled))
 
 	// When the operator is started to watch resources in a specific set of namespaces, we use the MultiNamespacedCacheBuilder cache.
 	// In this scenario, it is also suggested to restrict the provided authorization to this namespace by replacing the default
@@ -141,9 +190,10 @@ func main() {
 	// For further information see the kubernetes documentation about
 	// Using [RBAC Authorization](https://kubernetes.io/docs/reference/access-authn-authz/rbac/).
 	var managerWatchCache cache.NewCacheFunc
+	var ns []string
 	if watchNamespaces != "" {
 		setupLog.Info(fmt.Sprintf("Managing for Namespaces: %s", watchNamespaces))
-		ns := strings.Split(watchNamespaces, ",")
+		ns = strings.Split(watchNamespaces, ",")
 		for i := range ns {
 			ns[i] = strings.TrimSpace(ns[i])
 		}
@@ -167,7 +217,44 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = util.SetupContentHashCache(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the content hash cache")
+		os.Exit(1)
+	}
+
 	controllers.UseZkCRD(useZookeeperCRD)
+	util.SetCloudEventsSink(cloudEventsSink)
+	util.SetSolrOperatorConfigName(operatorConfigName)
+	util.SetFIPSMode(fipsMode)
+	util.SetSHA256ContentHashes(sha256ConfigHashes)
+	if defaultImagePullSecrets != "" {
+		pullSecrets := strings.Split(defaultImagePullSecrets, ",")
+		for i := range pullSecrets {
+			pullSecrets[i] = strings.TrimSpace(pullSecrets[i])
+		}
+		util.SetDefaultImagePullSecrets(pullSecrets)
+	}
+	solr_api.SetRequestTimeout(solrRequestTimeout)
+	if err = util.SetRequeueDurations(requeueDurations); err != nil {
+		setupLog.Error(err, "invalid requeue durations")
+		os.Exit(1)
+	}
+	if err = setupFeatureGates(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the feature gates")
+		os.Exit(1)
+	}
+	if fipsMode {
+		// Replace the default client for Solr, which does not verify server certs, with one restricted to FIPS-approved TLS settings
+		noVerifyTransport := http.DefaultTransport.(*http.Transport).Clone()
+		noVerifyTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		util.RestrictTLSConfigForFIPS(noVerifyTransport.TLSClientConfig)
+		solr_api.SetNoVerifyTLSHttpClient(&http.Client{Transport: noVerifyTransport})
+	}
+
+	if err = checkVersionSkew(mgr, fullVersion, ns); err != nil && strictVersionChecks {
+		setupLog.Error(err, "refusing to start solr operator, since strict version checks are enabled")
+		os.Exit(1)
+	}
 
 	// watch TLS files for update
 	if clientCertPath != "" {
@@ -187,28 +274,80 @@ func main() {
 	}
 
 	if err = (&controllers.SolrCloudReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrcloud-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrCloud")
 		os.Exit(1)
 	}
 	if err = (&controllers.SolrPrometheusExporterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrprometheusexporter-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrPrometheusExporter")
 		os.Exit(1)
 	}
 	if err = (&controllers.SolrBackupReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrbackup-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrBackup")
 		os.Exit(1)
 	}
+	if err = (&controllers.SolrRestoreReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrrestore-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrRestore")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrMigrationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrmigration-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrMigration")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrIndexingBridgeReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrindexingbridge-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrIndexingBridge")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrStreamingDaemonReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrstreamingdaemon-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrStreamingDaemon")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrConfigSetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("solrconfigset-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrConfigSet")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
+	if enableWebhooks {
+		mgr.GetWebhookServer().Register(controllers.SolrCloudProtectionWebhookPath, &webhook.Admission{Handler: &controllers.SolrCloudProtectionWebhook{Reader: mgr.GetClient()}})
+	}
+
+	if err := mgr.AddMetricsExtraHandler(controllers.FleetStatusPath, &controllers.FleetStatusHandler{Reader: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to set up fleet status endpoint")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -225,6 +364,73 @@ func main() {
 	}
 }
 
+// Enable or disable the gated features of the operator, from the least to the most specific source:
+// the feature gates ConfigMap, the feature gates env var, and finally the feature gates flag.
+func setupFeatureGates(mgr ctrl.Manager) error {
+	if featureGatesConfigMap != "" {
+		if namespace == "" {
+			setupLog.Info("Ignoring the feature gates ConfigMap, since the namespace of the operator is unknown", "configMap", featureGatesConfigMap, "env", EnvOperatorPodNamespace)
+		} else if err := util.LoadFeatureGatesConfigMap(context.Background(), mgr.GetAPIReader(), namespace, featureGatesConfigMap); err != nil {
+			return err
+		}
+	}
+	if err := util.SetFeatureGates(os.Getenv(util.FeatureGatesEnvVar)); err != nil {
+		return fmt.Errorf("invalid feature gates in the %s env var: %w", util.FeatureGatesEnvVar, err)
+	}
+	if err := util.SetFeatureGates(featureGates); err != nil {
+		return fmt.Errorf("invalid feature gates flag: %w", err)
+	}
+	if summary := util.FeatureGatesSummary(); summary != "" {
+		setupLog.Info(fmt.Sprintf("Feature gates: %s", summary))
+	}
+	return nil
+}
+
+// Make sure that the installed CRDs match this version of the operator, and that no other version of the operator is running.
+// An error is returned if any problems are found, after they have been logged.
+func checkVersionSkew(mgr ctrl.Manager, operatorVersion string, watchNamespaces []string) error {
+	ctx := context.Background()
+	var problems []string
+
+	crdProblems, err := util.CheckCustomResourceDefinitions(ctx, mgr.GetAPIReader(), setupLog)
+	if err != nil {
+		// CRDs that cannot be read cannot be verified
+		problems = append(problems, fmt.Sprintf("Unable to check the installed CRDs: %s", err))
+	}
+	problems = append(problems, crdProblems...)
+
+	if namespace != "" && name != "" {
+		// The manager's cache is not started yet, so the pod must be read and patched without it
+		directClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err == nil {
+			err = util.RecordOperatorVersion(ctx, directClient, namespace, name, operatorVersion)
+		}
+		if err != nil {
+			setupLog.Error(err, "Unable to record the operator version on the operator pod", "namespace", namespace, "pod", name)
+		}
+		var searchNamespaces []string
+		if len(watchNamespaces) > 0 {
+			searchNamespaces = append(append(searchNamespaces, watchNamespaces...), namespace)
+		}
+		skewedOperators, err := util.FindOperatorVersionSkew(ctx, mgr.GetAPIReader(), searchNamespaces, namespace, name, operatorVersion)
+		if err != nil {
+			// Other versions of the operator cannot be ruled out
+			problems = append(problems, fmt.Sprintf("Unable to search for other running Solr Operators: %s", err))
+		}
+		for _, skewed := range skewedOperators {
+			problems = append(problems, fmt.Sprintf("Solr Operator %s is running with a different version than %s", skewed, operatorVersion))
+		}
+	}
+
+	for _, problem := range problems {
+		setupLog.Info("WARNING: "+problem, "strictVersionChecks", strictVersionChecks)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d version skew problems", len(problems))
+	}
+	return nil
+}
+
 // Setup for mTLS with Solr pods with hot reload support using the fsnotify Watcher
 func initMTLSConfig(watcher *fsnotify.Watcher) error {
 	setupLog.Info("mTLS config", "clientSkipVerify", clientSkipVerify, "clientCertPath", clientCertPath,
@@ -312,6 +518,9 @@ func initMTLSConfig(watcher *fsnotify.Watcher) error {
 func buildTLSTransport() (*http.Transport, error) {
 	mTLSTransport := http.DefaultTransport.(*http.Transport).Clone()
 	mTLSTransport.TLSClientConfig = &tls.Config{GetClientCertificate: getClientCertificate, InsecureSkipVerify: clientSkipVerify}
+	if fipsMode {
+		util.RestrictTLSConfigForFIPS(mTLSTransport.TLSClientConfig)
+	}
 
 	// Add the rootCA if one is provided
 	if caCertPath != "" {